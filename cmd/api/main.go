@@ -51,13 +51,16 @@ func main() {
 	showVersion := flag.Bool("version", false, "Show version information")
 	configPath := flag.String("config", "config.yaml", "Path to the configuration file")
 	showInfo := flag.Bool("info", false, "Show parsed config")
+	infoFormat := flag.String("format", server.InfoFormatText, "Output format of --version and --info: text or json")
 	clean := flag.Bool("clean", false, "Clean up the cache directory and exit")
+	strictConfig := flag.Bool("strict-config", false, "Exit if the configuration file does not exist, is empty or invalid")
 	flag.Parse()
 
 	// load server config
 	s := server.NewServer().
 		WithVersionInfo(Version, Commit, BuildTime).
 		WithConfig(*configPath).
+		WithStrictConfig(*strictConfig).
 		WithLogger().
 		WithDomainService()
 
@@ -66,10 +69,10 @@ func main() {
 		os.Exit(0)
 	}
 
-	s.PrintInfo(*showVersion, *showInfo)
+	s.PrintInfo(*showVersion, *showInfo, *infoFormat)
 
 	// start the server
-	s.Start()
+	s.WithConfigWatcher().Start()
 	defer s.Shutdown()
 
 	// Wait for the interrupt signal
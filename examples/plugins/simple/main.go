@@ -4,10 +4,13 @@ import (
 	"context"
 	"fmt"
 	"os"
+	"strings"
 
 	"github.com/hashicorp/go-hclog"
 	"github.com/schumann-it/dehydrated-api-go/plugin/proto"
 	"github.com/schumann-it/dehydrated-api-go/plugin/server"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
 )
 
 // ExamplePlugin is a simple plugin implementation
@@ -21,7 +24,14 @@ type ExamplePlugin struct {
 func (p *ExamplePlugin) Initialize(_ context.Context, req *proto.InitializeRequest) (*proto.InitializeResponse, error) {
 	p.logger.Debug("Initialize called")
 	p.config.FromProto(req.Config)
-	return &proto.InitializeResponse{}, nil
+
+	// Reject a missing name right away, so the API reports the plugin as misconfigured
+	if _, err := p.config.GetString("name"); err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "invalid config: %v", err)
+	}
+
+	// Advertise the optional RPCs the plugin implements, so the API calls them
+	return &proto.InitializeResponse{Capabilities: []string{proto.CapabilityMetadataBatch, proto.CapabilityValidate}}, nil
 }
 
 // GetMetadata implements the plugin.Plugin interface
@@ -31,7 +41,9 @@ func (p *ExamplePlugin) GetMetadata(_ context.Context, req *proto.GetMetadataReq
 	// Create a new Metadata for the response
 	metadata := proto.NewMetadata()
 
-	if req.GetDomainEntry().GetEnabled() {
+	// Disabled entries are only enriched with enrichDisabled: true
+	enrichDisabled, _ := p.config.GetBool("enrichDisabled")
+	if req.GetDomainEntry().GetEnabled() || enrichDisabled {
 		// Get the name from config
 		name, err := p.config.GetString("name")
 		if err != nil {
@@ -51,6 +63,39 @@ func (p *ExamplePlugin) GetMetadata(_ context.Context, req *proto.GetMetadataReq
 	return metadata.ToGetMetadataResponse()
 }
 
+// GetMetadataBatch implements the plugin.Plugin interface.
+// The metadata is computed per entry, a plugin backed by an external service would look up all entries at once.
+func (p *ExamplePlugin) GetMetadataBatch(ctx context.Context, req *proto.GetMetadataBatchRequest) (*proto.GetMetadataBatchResponse, error) {
+	p.logger.Debug("GetMetadataBatch called", "entries", len(req.GetRequests()))
+	return proto.GetMetadataBatch(ctx, req, p.GetMetadata)
+}
+
+// Validate implements the plugin.Plugin interface.
+// It rejects entries with a domain or alternative name ending in one of the configured deniedSuffixes.
+func (p *ExamplePlugin) Validate(_ context.Context, req *proto.ValidateRequest) (*proto.ValidateResponse, error) {
+	p.logger.Debug("Validate called", "domain", req.GetDomainEntry().GetDomain())
+
+	// Accept all entries if no suffixes are configured
+	suffixes, err := p.config.GetStringSlice("deniedSuffixes")
+	if err != nil {
+		return &proto.ValidateResponse{Valid: true}, nil
+	}
+
+	names := append([]string{req.GetDomainEntry().GetDomain()}, req.GetDomainEntry().GetAlternativeNames()...)
+	for _, name := range names {
+		for _, suffix := range suffixes {
+			if strings.HasSuffix(name, suffix) {
+				return &proto.ValidateResponse{
+					Valid:  false,
+					Reason: fmt.Sprintf("%s is not allowed (denied suffix %s)", name, suffix),
+				}, nil
+			}
+		}
+	}
+
+	return &proto.ValidateResponse{Valid: true}, nil
+}
+
 // Close implements the plugin.Plugin interface
 func (p *ExamplePlugin) Close(_ context.Context, _ *proto.CloseRequest) (*proto.CloseResponse, error) {
 	p.logger.Debug("Close called")
@@ -30,6 +30,10 @@ type Config struct {
 	// KeyCacheTTL is the time-to-live for the public key cache (e.g., "24h", "1h")
 	// Defaults to 24 hours if not specified
 	KeyCacheTTL string `yaml:"keyCacheTTL"`
+
+	// RequireWriterRole makes creating, updating, renaming and deleting single domain entries
+	// require the writer role. Disabled by default, since tokens without roles could modify entries before.
+	RequireWriterRole bool `yaml:"requireWriterRole"`
 }
 
 // NewConfig creates a new Config instance with default values
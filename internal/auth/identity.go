@@ -0,0 +1,62 @@
+package auth
+
+import (
+	"context"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/golang-jwt/jwt/v5"
+	"go.uber.org/zap"
+)
+
+// Anonymous is the subject of requests if authentication is not configured.
+const Anonymous = "anonymous"
+
+// Identity is the caller of a request, as logged in the access log and by handlers and the service.
+type Identity struct {
+	// Subject is the actor of the token, see Actor, or Anonymous.
+	Subject string
+
+	// Roles are the roles of the "roles" claim of the token.
+	Roles []string
+}
+
+// identityKey is the context key of the Identity.
+type identityKey struct{}
+
+// WithIdentity returns a copy of ctx carrying id.
+func WithIdentity(ctx context.Context, id Identity) context.Context {
+	return context.WithValue(ctx, identityKey{}, id)
+}
+
+// IdentityFromContext returns the Identity carried by ctx, the Anonymous identity if there is none.
+// The middleware sets it on the user context of authenticated requests, see fiber.Ctx.UserContext.
+func IdentityFromContext(ctx context.Context) Identity {
+	if id, ok := ctx.Value(identityKey{}).(Identity); ok {
+		return id
+	}
+	return Identity{Subject: Anonymous}
+}
+
+// RequestIdentity returns the Identity of the caller of the request, the Anonymous identity
+// if authentication is not configured.
+func RequestIdentity(c *fiber.Ctx) Identity {
+	claims, ok := c.Locals("claims").(jwt.MapClaims)
+	if !ok {
+		return Identity{Subject: Anonymous}
+	}
+	return identify(claims)
+}
+
+// identify returns the Identity of the token with claims.
+func identify(claims jwt.MapClaims) Identity {
+	id := Identity{Subject: actor(claims), Roles: tokenRoles(claims)}
+	if id.Subject == "" {
+		id.Subject = Anonymous
+	}
+	return id
+}
+
+// Fields returns the log fields of the identity, "actor" and "roles".
+func (id Identity) Fields() []zap.Field {
+	return []zap.Field{zap.String("actor", id.Subject), zap.Strings("roles", id.Roles)}
+}
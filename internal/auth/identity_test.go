@@ -0,0 +1,74 @@
+package auth
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+// testToken returns a token the middleware accepts with signature validation disabled.
+func testToken(t *testing.T, claims jwt.MapClaims) string {
+	t.Helper()
+
+	claims["iss"] = "https://sts.windows.net/tenant/"
+	claims["aud"] = "api://dehydrated"
+	claims["exp"] = float64(time.Now().Add(time.Hour).Unix())
+	token, err := jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString([]byte("secret"))
+	require.NoError(t, err)
+	return token
+}
+
+func TestIdentity(t *testing.T) {
+	t.Run("AuthenticationDisabled", func(t *testing.T) {
+		var request, context Identity
+		app := fiber.New()
+		app.Get("/", func(c *fiber.Ctx) error {
+			request, context = RequestIdentity(c), IdentityFromContext(c.UserContext())
+			return c.SendStatus(fiber.StatusOK)
+		})
+
+		resp, err := app.Test(httptest.NewRequest("GET", "/", http.NoBody))
+		require.NoError(t, err)
+		defer resp.Body.Close()
+		require.Equal(t, fiber.StatusOK, resp.StatusCode)
+		require.Equal(t, Identity{Subject: Anonymous}, request)
+		require.Equal(t, Identity{Subject: Anonymous}, context)
+	})
+
+	tests := []struct {
+		name     string
+		claims   jwt.MapClaims
+		expected Identity
+	}{
+		{"Subject", jwt.MapClaims{"sub": "user", "roles": []any{RoleWriter}}, Identity{Subject: "user", Roles: []string{RoleWriter}}},
+		{"ObjectID", jwt.MapClaims{"oid": "object", "sub": "user"}, Identity{Subject: "object"}},
+		{"NoSubject", jwt.MapClaims{}, Identity{Subject: Anonymous}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := &Config{TenantID: "tenant", AllowedAudiences: []string{"api://dehydrated"}}
+			var request, context Identity
+			app := fiber.New()
+			app.Get("/", Middleware(cfg, zap.NewNop()), func(c *fiber.Ctx) error {
+				request, context = RequestIdentity(c), IdentityFromContext(c.UserContext())
+				return c.SendStatus(fiber.StatusOK)
+			})
+
+			req := httptest.NewRequest("GET", "/", http.NoBody)
+			req.Header.Set("Authorization", "Bearer "+testToken(t, tt.claims))
+			resp, err := app.Test(req)
+			require.NoError(t, err)
+			defer resp.Body.Close()
+			require.Equal(t, fiber.StatusOK, resp.StatusCode)
+			require.Equal(t, tt.expected, request)
+			require.Equal(t, tt.expected, context)
+		})
+	}
+}
@@ -64,8 +64,10 @@ func Middleware(cfg *Config, logger *zap.Logger) fiber.Handler {
 			return err4
 		}
 
-		// Store the validated token in the context for later use
+		// Store the validated token and its claims in the context for later use
 		c.Locals("token", token)
+		c.Locals("claims", claims)
+		c.SetUserContext(WithIdentity(c.UserContext(), identify(claims)))
 
 		return c.Next()
 	}
@@ -0,0 +1,73 @@
+package auth
+
+import (
+	"slices"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// Application roles checked against the "roles" claim of the token
+const (
+	// RoleWriter allows modifying domain entries.
+	RoleWriter = "writer"
+	// RoleAdmin allows administrative operations and implies RoleWriter.
+	RoleAdmin = "admin"
+)
+
+// RequireRole creates middleware that only allows requests whose token carries the given role.
+// Tokens carrying RoleAdmin are granted every role. If authentication is not configured,
+// no claims are available and all requests are allowed.
+func RequireRole(role string) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		claims, ok := c.Locals("claims").(jwt.MapClaims)
+		if !ok {
+			return c.Next()
+		}
+
+		roles := tokenRoles(claims)
+		if !slices.Contains(roles, role) && !slices.Contains(roles, RoleAdmin) {
+			return fiber.NewError(fiber.StatusForbidden, "missing required role: "+role)
+		}
+
+		return c.Next()
+	}
+}
+
+// tokenRoles returns the roles of the "roles" claim.
+func tokenRoles(claims jwt.MapClaims) []string {
+	raw, ok := claims["roles"].([]any)
+	if !ok {
+		return nil
+	}
+
+	roles := make([]string, 0, len(raw))
+	for _, r := range raw {
+		if s, ok := r.(string); ok {
+			roles = append(roles, s)
+		}
+	}
+
+	return roles
+}
+
+// Actor returns the identity of the caller, the object ID of the token or its subject if the object ID
+// is missing. It returns an empty string if authentication is not configured.
+func Actor(c *fiber.Ctx) string {
+	claims, ok := c.Locals("claims").(jwt.MapClaims)
+	if !ok {
+		return ""
+	}
+	return actor(claims)
+}
+
+// actor returns the object ID of the token with claims, or its subject if the object ID is missing.
+func actor(claims jwt.MapClaims) string {
+	for _, claim := range []string{"oid", "sub"} {
+		if actor, ok := claims[claim].(string); ok && actor != "" {
+			return actor
+		}
+	}
+
+	return ""
+}
@@ -0,0 +1,81 @@
+package auth
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRequireRole(t *testing.T) {
+	newApp := func(claims jwt.MapClaims) *fiber.App {
+		app := fiber.New()
+		if claims != nil {
+			app.Use(func(c *fiber.Ctx) error {
+				c.Locals("claims", claims)
+				return c.Next()
+			})
+		}
+		app.Get("/", RequireRole(RoleWriter), func(c *fiber.Ctx) error {
+			return c.SendStatus(fiber.StatusOK)
+		})
+		return app
+	}
+
+	tests := []struct {
+		name   string
+		claims jwt.MapClaims
+		status int
+	}{
+		{"authentication disabled", nil, fiber.StatusOK},
+		{"matching role", jwt.MapClaims{"roles": []any{RoleWriter}}, fiber.StatusOK},
+		{"admin role", jwt.MapClaims{"roles": []any{RoleAdmin}}, fiber.StatusOK},
+		{"other role", jwt.MapClaims{"roles": []any{"reader"}}, fiber.StatusForbidden},
+		{"no roles", jwt.MapClaims{}, fiber.StatusForbidden},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp, err := newApp(tt.claims).Test(httptest.NewRequest("GET", "/", http.NoBody))
+			require.NoError(t, err)
+			defer resp.Body.Close()
+			require.Equal(t, tt.status, resp.StatusCode)
+		})
+	}
+}
+
+func TestActor(t *testing.T) {
+	tests := []struct {
+		name   string
+		claims jwt.MapClaims
+		actor  string
+	}{
+		{"authentication disabled", nil, ""},
+		{"object id", jwt.MapClaims{"oid": "object", "sub": "subject"}, "object"},
+		{"subject", jwt.MapClaims{"sub": "subject"}, "subject"},
+		{"anonymous token", jwt.MapClaims{}, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			app := fiber.New()
+			app.Get("/", func(c *fiber.Ctx) error {
+				if tt.claims != nil {
+					c.Locals("claims", tt.claims)
+				}
+				return c.SendString(Actor(c))
+			})
+
+			resp, err := app.Test(httptest.NewRequest("GET", "/", http.NoBody))
+			require.NoError(t, err)
+			defer resp.Body.Close()
+			body, err := io.ReadAll(resp.Body)
+			require.NoError(t, err)
+			require.Equal(t, tt.actor, string(body))
+		})
+	}
+}
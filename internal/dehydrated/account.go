@@ -0,0 +1,119 @@
+package dehydrated
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// ErrAccountNotFound is returned when dehydrated has not registered an ACME account yet.
+var ErrAccountNotFound = errors.New("account not found")
+
+const (
+	// accountRegistrationFile holds the account object returned by the CA on registration.
+	accountRegistrationFile = "registration_info.json"
+	// accountIDFile holds the account URL assigned by the CA.
+	accountIDFile = "account_id.json"
+)
+
+// Account represents the ACME account dehydrated registered with the CA.
+// @Description ACME account registration information
+type Account struct {
+	// URL is the account URL assigned by the CA.
+	// @Description Account URL assigned by the CA
+	URL string `json:"url,omitempty" example:"https://acme-v02.api.letsencrypt.org/acme/acct/123456"`
+
+	// Contact is the list of contact URIs registered with the account.
+	// @Description Contact URIs registered with the account
+	Contact []string `json:"contact" example:"mailto:admin@example.com"`
+
+	// Status is the account status reported by the CA (e.g., valid, deactivated, revoked).
+	// @Description Account status reported by the CA
+	Status string `json:"status,omitempty" example:"valid"`
+
+	// CreatedAt is the account creation timestamp reported by the CA.
+	// @Description Account creation timestamp reported by the CA
+	CreatedAt string `json:"created_at,omitempty" example:"2024-01-01T00:00:00Z"`
+}
+
+// knownCAs maps the CA presets supported by dehydrated to their directory URLs.
+var knownCAs = map[string]string{
+	"letsencrypt":      "https://acme-v02.api.letsencrypt.org/directory",
+	"letsencrypt-test": "https://acme-staging-v02.api.letsencrypt.org/directory",
+	"zerossl":          "https://acme.zerossl.com/v2/DV90",
+	"buypass":          "https://api.buypass.com/acme/directory",
+	"buypass-test":     "https://api.test4.buypass.no/acme/directory",
+	"google":           "https://dv.acme-v02.api.pki.goog/directory",
+	"google-test":      "https://dv.acme-v02.test-api.pki.goog/directory",
+}
+
+// CaURL returns the ACME directory URL of the configured CA, resolving dehydrated's CA presets.
+func (c *Config) CaURL() string {
+	if u, ok := knownCAs[c.Ca]; ok {
+		return u
+	}
+
+	return c.Ca
+}
+
+// AccountDir returns the directory holding the ACME account files of the configured CA.
+// Like dehydrated, accounts are nested per CA in a subdirectory of AccountsDir named
+// after the unpadded URL-safe base64 encoding of the CA URL (including the trailing
+// newline dehydrated's echo adds).
+func (c *Config) AccountDir() string {
+	return filepath.Join(c.AccountsDir, base64.RawURLEncoding.EncodeToString([]byte(c.CaURL()+"\n")))
+}
+
+// Account reads the ACME account registration from the account directory.
+// It returns ErrAccountNotFound if dehydrated has not registered an account yet.
+func (c *Config) Account() (*Account, error) {
+	dir := c.AccountDir()
+
+	data, err := os.ReadFile(filepath.Join(dir, accountRegistrationFile))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, ErrAccountNotFound
+		}
+		return nil, err
+	}
+
+	var registration struct {
+		Contact   []string `json:"contact"`
+		Status    string   `json:"status"`
+		CreatedAt string   `json:"createdAt"`
+	}
+	if err := json.Unmarshal(data, &registration); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", accountRegistrationFile, err)
+	}
+
+	account := &Account{
+		Contact:   registration.Contact,
+		Status:    registration.Status,
+		CreatedAt: registration.CreatedAt,
+	}
+	if account.Contact == nil {
+		account.Contact = []string{}
+	}
+
+	// The account URL is only persisted by dehydrated versions supporting ACME v2
+	data, err = os.ReadFile(filepath.Join(dir, accountIDFile))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return account, nil
+		}
+		return nil, err
+	}
+
+	var id struct {
+		URL string `json:"url"`
+	}
+	if err := json.Unmarshal(data, &id); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", accountIDFile, err)
+	}
+	account.URL = id.URL
+
+	return account, nil
+}
@@ -0,0 +1,119 @@
+package dehydrated
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// writeAccountFixture creates a dehydrated account directory with the given files.
+func writeAccountFixture(t *testing.T, dir string, files map[string]string) {
+	t.Helper()
+
+	require.NoError(t, os.MkdirAll(dir, 0755))
+	for name, content := range files {
+		require.NoError(t, os.WriteFile(filepath.Join(dir, name), []byte(content), 0600))
+	}
+}
+
+// TestAccountDir verifies that the per-CA account directory is resolved like dehydrated does.
+func TestAccountDir(t *testing.T) {
+	tests := []struct {
+		name     string
+		ca       string
+		expected string
+	}{
+		{
+			name:     "LetsEncryptPreset",
+			ca:       "letsencrypt",
+			expected: "aHR0cHM6Ly9hY21lLXYwMi5hcGkubGV0c2VuY3J5cHQub3JnL2RpcmVjdG9yeQo",
+		},
+		{
+			name:     "LetsEncryptURL",
+			ca:       "https://acme-v02.api.letsencrypt.org/directory",
+			expected: "aHR0cHM6Ly9hY21lLXYwMi5hcGkubGV0c2VuY3J5cHQub3JnL2RpcmVjdG9yeQo",
+		},
+		{
+			name:     "StagingPreset",
+			ca:       "letsencrypt-test",
+			expected: "aHR0cHM6Ly9hY21lLXN0YWdpbmctdjAyLmFwaS5sZXRzZW5jcnlwdC5vcmcvZGlyZWN0b3J5Cg",
+		},
+		{
+			name:     "StagingURL",
+			ca:       "https://acme-staging-v02.api.letsencrypt.org/directory",
+			expected: "aHR0cHM6Ly9hY21lLXN0YWdpbmctdjAyLmFwaS5sZXRzZW5jcnlwdC5vcmcvZGlyZWN0b3J5Cg",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := NewConfig().WithBaseDir("/etc/dehydrated").Load()
+			cfg.Ca = tt.ca
+
+			require.Equal(t, filepath.Join("/etc/dehydrated", "accounts", tt.expected), cfg.AccountDir())
+		})
+	}
+}
+
+// TestAccount verifies that the ACME account registration is read from the account directory.
+func TestAccount(t *testing.T) {
+	t.Run("RegisteredAccount", func(t *testing.T) {
+		cfg := NewConfig().WithBaseDir(t.TempDir()).Load()
+		writeAccountFixture(t, cfg.AccountDir(), map[string]string{
+			"registration_info.json": `{"key":{"kty":"RSA"},"contact":["mailto:admin@example.com"],"status":"valid","createdAt":"2024-01-01T00:00:00Z"}`,
+			"account_id.json":        `{"url":"https://acme-v02.api.letsencrypt.org/acme/acct/123456"}`,
+		})
+
+		account, err := cfg.Account()
+		require.NoError(t, err)
+		require.Equal(t, "https://acme-v02.api.letsencrypt.org/acme/acct/123456", account.URL)
+		require.Equal(t, []string{"mailto:admin@example.com"}, account.Contact)
+		require.Equal(t, "valid", account.Status)
+		require.Equal(t, "2024-01-01T00:00:00Z", account.CreatedAt)
+	})
+
+	t.Run("WithoutAccountID", func(t *testing.T) {
+		cfg := NewConfig().WithBaseDir(t.TempDir()).Load()
+		writeAccountFixture(t, cfg.AccountDir(), map[string]string{
+			"registration_info.json": `{"status":"valid"}`,
+		})
+
+		account, err := cfg.Account()
+		require.NoError(t, err)
+		require.Empty(t, account.URL)
+		require.Equal(t, []string{}, account.Contact)
+		require.Equal(t, "valid", account.Status)
+	})
+
+	t.Run("OtherCA", func(t *testing.T) {
+		cfg := NewConfig().WithBaseDir(t.TempDir()).Load()
+		writeAccountFixture(t, cfg.AccountDir(), map[string]string{
+			"registration_info.json": `{"status":"valid"}`,
+		})
+
+		// An account registered with a different CA must not be reported
+		cfg.Ca = "letsencrypt-test"
+		_, err := cfg.Account()
+		require.ErrorIs(t, err, ErrAccountNotFound)
+	})
+
+	t.Run("NoAccount", func(t *testing.T) {
+		cfg := NewConfig().WithBaseDir(t.TempDir()).Load()
+
+		_, err := cfg.Account()
+		require.ErrorIs(t, err, ErrAccountNotFound)
+	})
+
+	t.Run("InvalidRegistration", func(t *testing.T) {
+		cfg := NewConfig().WithBaseDir(t.TempDir()).Load()
+		writeAccountFixture(t, cfg.AccountDir(), map[string]string{
+			"registration_info.json": `not json`,
+		})
+
+		_, err := cfg.Account()
+		require.Error(t, err)
+		require.NotErrorIs(t, err, ErrAccountNotFound)
+	})
+}
@@ -0,0 +1,159 @@
+package dehydrated
+
+import (
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+)
+
+// ErrCertNotFound is returned when dehydrated has not issued a certificate for a domain yet.
+var ErrCertNotFound = errors.New("certificate not found")
+
+// certFile is the file dehydrated links to the current certificate of a domain.
+const certFile = "cert.pem"
+
+// Certificate status of a domain entry.
+const (
+	// CertStatusValid indicates a certificate that does not expire within the threshold.
+	CertStatusValid = "valid"
+	// CertStatusExpiring indicates a certificate that expires within the threshold.
+	CertStatusExpiring = "expiring"
+	// CertStatusExpired indicates a certificate that has expired.
+	CertStatusExpired = "expired"
+	// CertStatusMissing indicates that no (readable) certificate exists.
+	CertStatusMissing = "missing"
+)
+
+// CertInfo holds information about the certificate of a domain entry.
+// @Description Certificate information
+type CertInfo struct {
+	// Subject is the common name of the certificate.
+	// @Description Common name of the certificate
+	Subject string `json:"subject" example:"example.com"`
+
+	// Issuer is the common name of the issuing CA.
+	// @Description Common name of the issuing CA
+	Issuer string `json:"issuer" example:"R3"`
+
+	// DNSNames are the subject alternative names of the certificate.
+	// @Description Subject alternative names of the certificate
+	DNSNames []string `json:"dns_names" example:"example.com,www.example.com"`
+
+	// NotBefore is the start of the validity period.
+	// @Description Start of the validity period
+	NotBefore time.Time `json:"not_before" example:"2024-01-01T00:00:00Z"`
+
+	// NotAfter is the end of the validity period.
+	// @Description End of the validity period
+	NotAfter time.Time `json:"not_after" example:"2024-04-01T00:00:00Z"`
+}
+
+// Status returns the certificate status at now, considering certificates expiring within threshold
+// as expiring. A nil CertInfo is missing.
+func (i *CertInfo) Status(now time.Time, threshold time.Duration) string {
+	switch {
+	case i == nil:
+		return CertStatusMissing
+	case !now.Before(i.NotAfter):
+		return CertStatusExpired
+	case now.Add(threshold).After(i.NotAfter):
+		return CertStatusExpiring
+	default:
+		return CertStatusValid
+	}
+}
+
+// CertFile returns the path of the current certificate of the domain entry with the given path name
+// (its alias or domain). It returns ErrInvalidPath if the path would be outside of CertDir.
+func (c *Config) CertFile(pathName string) (string, error) {
+	return c.CertPath(pathName, certFile)
+}
+
+// ReadCertInfo reads the information of the first certificate in the PEM file.
+// It returns ErrCertNotFound if the file does not exist.
+func ReadCertInfo(file string) (*CertInfo, error) {
+	data, err := os.ReadFile(file)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, ErrCertNotFound
+		}
+		return nil, err
+	}
+
+	block, _ := pem.Decode(data)
+	if block == nil || block.Type != "CERTIFICATE" {
+		return nil, fmt.Errorf("no certificate found in %s", file)
+	}
+
+	cert, err := x509.ParseCertificate(block.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", file, err)
+	}
+
+	return &CertInfo{
+		Subject:   cert.Subject.CommonName,
+		Issuer:    cert.Issuer.CommonName,
+		DNSNames:  cert.DNSNames,
+		NotBefore: cert.NotBefore,
+		NotAfter:  cert.NotAfter,
+	}, nil
+}
+
+// CertInfoCache caches certificate information by file. An entry is read again
+// when the modification time or size of its file changes, e.g., after a renewal.
+type CertInfoCache struct {
+	mu      sync.Mutex
+	entries map[string]certInfoCacheEntry
+}
+
+type certInfoCacheEntry struct {
+	modTime time.Time
+	size    int64
+	info    *CertInfo
+}
+
+// NewCertInfoCache creates an empty CertInfoCache.
+func NewCertInfoCache() *CertInfoCache {
+	return &CertInfoCache{
+		entries: make(map[string]certInfoCacheEntry),
+	}
+}
+
+// Get returns the certificate information of file, reading it only if it changed since the last call.
+// It returns ErrCertNotFound if the file does not exist.
+func (cc *CertInfoCache) Get(file string) (*CertInfo, error) {
+	// Stat follows the symlink dehydrated maintains, so a renewal changes the result
+	fi, err := os.Stat(file)
+	if err != nil {
+		cc.mu.Lock()
+		delete(cc.entries, file)
+		cc.mu.Unlock()
+
+		if os.IsNotExist(err) {
+			return nil, ErrCertNotFound
+		}
+		return nil, err
+	}
+
+	cc.mu.Lock()
+	entry, ok := cc.entries[file]
+	cc.mu.Unlock()
+	if ok && entry.modTime.Equal(fi.ModTime()) && entry.size == fi.Size() {
+		return entry.info, nil
+	}
+
+	info, err := ReadCertInfo(file)
+	if err != nil {
+		return nil, err
+	}
+
+	cc.mu.Lock()
+	cc.entries[file] = certInfoCacheEntry{modTime: fi.ModTime(), size: fi.Size(), info: info}
+	cc.mu.Unlock()
+
+	return info, nil
+}
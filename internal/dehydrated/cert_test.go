@@ -0,0 +1,87 @@
+package dehydrated
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/schumann-it/dehydrated-api-go/internal/testutil"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCertInfo(t *testing.T) {
+	cfg := NewConfig().WithBaseDir(t.TempDir()).Load()
+	notAfter := time.Now().Add(30 * 24 * time.Hour).Truncate(time.Second)
+
+	file, err := cfg.CertFile("example.com")
+	require.NoError(t, err)
+	require.Equal(t, filepath.Join(cfg.CertDir, "example.com", "cert.pem"), file)
+
+	_, err = ReadCertInfo(file)
+	require.ErrorIs(t, err, ErrCertNotFound)
+
+	testutil.WriteCert(t, file, "example.com", notAfter)
+	info, err := ReadCertInfo(file)
+	require.NoError(t, err)
+	require.Equal(t, "example.com", info.Subject)
+	require.Equal(t, []string{"example.com"}, info.DNSNames)
+	require.True(t, notAfter.Equal(info.NotAfter))
+
+	require.NoError(t, os.WriteFile(file, []byte("garbage"), 0o600))
+	_, err = ReadCertInfo(file)
+	require.ErrorContains(t, err, "no certificate found")
+}
+
+func TestCertInfoStatus(t *testing.T) {
+	now := time.Now()
+	threshold := 14 * 24 * time.Hour
+
+	tests := []struct {
+		name     string
+		info     *CertInfo
+		expected string
+	}{
+		{name: "Missing", info: nil, expected: CertStatusMissing},
+		{name: "Valid", info: &CertInfo{NotAfter: now.Add(30 * 24 * time.Hour)}, expected: CertStatusValid},
+		{name: "Expiring", info: &CertInfo{NotAfter: now.Add(7 * 24 * time.Hour)}, expected: CertStatusExpiring},
+		{name: "Expired", info: &CertInfo{NotAfter: now.Add(-time.Hour)}, expected: CertStatusExpired},
+		{name: "ExpiresNow", info: &CertInfo{NotAfter: now}, expected: CertStatusExpired},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require.Equal(t, tt.expected, tt.info.Status(now, threshold))
+		})
+	}
+}
+
+func TestCertInfoCache(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "example.com", "cert.pem")
+	cache := NewCertInfoCache()
+
+	_, err := cache.Get(file)
+	require.ErrorIs(t, err, ErrCertNotFound)
+
+	first := time.Now().Add(24 * time.Hour).Truncate(time.Second)
+	testutil.WriteCert(t, file, "example.com", first)
+	info, err := cache.Get(file)
+	require.NoError(t, err)
+	require.True(t, first.Equal(info.NotAfter))
+
+	cached, err := cache.Get(file)
+	require.NoError(t, err)
+	require.Same(t, info, cached)
+
+	// A renewal changes the file, so it is read again
+	renewed := first.Add(60 * 24 * time.Hour)
+	testutil.WriteCert(t, file, "example.com", renewed)
+	require.NoError(t, os.Chtimes(file, time.Now(), time.Now().Add(time.Minute)))
+	info, err = cache.Get(file)
+	require.NoError(t, err)
+	require.True(t, renewed.Equal(info.NotAfter))
+
+	require.NoError(t, os.Remove(file))
+	_, err = cache.Get(file)
+	require.ErrorIs(t, err, ErrCertNotFound)
+}
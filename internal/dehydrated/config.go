@@ -13,19 +13,25 @@ import (
 	"strconv"
 	"strings"
 
+	"google.golang.org/protobuf/proto"
+
 	pb "github.com/schumann-it/dehydrated-api-go/plugin/proto"
 )
 
 // Config represents the dehydrated configuration
 type Config struct {
 	pb.DehydratedConfig
+
+	validate   bool     // Whether Load validates the configuration
+	keySizeSet bool     // Whether KEY_SIZE was set explicitly in a config file
+	warnings   []string // Validation warnings collected by Load
 }
 
 // NewConfig creates a new Config with default values.
 // It initializes all fields with sensible defaults for the dehydrated ACME client.
 func NewConfig() *Config {
 	return &Config{
-		pb.DehydratedConfig{
+		DehydratedConfig: pb.DehydratedConfig{
 			BaseDir:         ".",
 			CertDir:         "certs",
 			DomainsDir:      "domains",
@@ -54,7 +60,7 @@ func NewConfig() *Config {
 // It sets up the configuration for Let's Encrypt v2 API with standard settings.
 func DefaultConfig() *Config {
 	return &Config{
-		pb.DehydratedConfig{
+		DehydratedConfig: pb.DehydratedConfig{
 			Group:    "www-data",
 			Ca:       "https://acme-v02.api.letsencrypt.org/directory",
 			Openssl:  "openssl",
@@ -97,9 +103,25 @@ func (c *Config) Load() *Config {
 	c.load()
 	c.resolvePaths()
 
+	if c.validate {
+		c.warnings = c.Validate()
+	}
+
+	return c
+}
+
+// WithValidation enables validation of the configuration during Load.
+// The validation results are available via Warnings.
+func (c *Config) WithValidation() *Config {
+	c.validate = true
 	return c
 }
 
+// Warnings returns the validation warnings collected during Load.
+func (c *Config) Warnings() []string {
+	return c.warnings
+}
+
 // findAndSetConfigFile searches for a config file in the base directory.
 // It looks for files named "config" or "config.sh" and sets the ConfigFile field
 // if one is found.
@@ -176,6 +198,7 @@ func (c *Config) SetValue(key, value string) {
 			break
 		}
 		c.KeySize = val
+		c.keySizeSet = true
 	case "RENEW_DAYS":
 		val, err := toInt32(value)
 		if err != nil {
@@ -294,22 +317,50 @@ func (c *Config) resolvePaths() {
 func (c *Config) String() string {
 	var lines []string
 
-	//nolint: govet // We use reflection to iterate over fields
-	t := reflect.TypeOf(*c)
+	c.settings(func(name string, value any) {
+		lines = append(lines, fmt.Sprintf("%s=%v", name, value))
+	})
+
+	return strings.Join(lines, "\n")
+}
+
+// Values returns the settings of the Config by their upper-case names, as listed by String.
+func (c *Config) Values() map[string]any {
+	values := make(map[string]any)
+
+	c.settings(func(name string, value any) {
+		values[name] = value
+	})
+
+	return values
+}
+
+// settings calls fn with the upper-case name and the value of each setting that is not empty, in the order of the fields.
+// The settings are the fields of the embedded DehydratedConfig.
+func (c *Config) settings(fn func(name string, value any)) {
+	v := reflect.ValueOf(&c.DehydratedConfig).Elem()
+	t := v.Type()
 	for i := 0; i < t.NumField(); i++ {
-		//nolint: govet // We use reflection to iterate over fields
-		value := reflect.ValueOf(*c).Field(i)
+		if !t.Field(i).IsExported() {
+			continue
+		}
+		value := v.Field(i)
 		if value.String() != "" {
-			//nolint: govet // We use reflection to iterate over fields
-			lines = append(lines, fmt.Sprintf("%s=%v", strings.ToUpper(t.Field(i).Name), reflect.ValueOf(*c).Field(i).Interface()))
+			fn(strings.ToUpper(t.Field(i).Name), value.Interface())
 		}
 	}
-
-	return strings.Join(lines, "\n")
 }
 
+// DomainSpecificConfig returns the effective configuration of the certificate stored in path below
+// CertDir: the configuration with KEY_ALGO, KEY_SIZE, CHALLENGETYPE and CA overridden by the certificate's
+// config file, if any. The configuration itself is not modified, it is returned as is without overrides.
+// The config file is read on every call, so edits are picked up without reloading. Paths outside of
+// CertDir, see CertPath, have no overrides.
 func (c *Config) DomainSpecificConfig(path string) *Config {
-	cfgFile := filepath.Join(c.CertDir, path, "config")
+	cfgFile, err := c.CertPath(path, "config")
+	if err != nil {
+		return c
+	}
 	if _, err := os.Stat(cfgFile); err != nil {
 		return c
 	}
@@ -317,17 +368,86 @@ func (c *Config) DomainSpecificConfig(path string) *Config {
 	domainSpecificConfig := &Config{}
 	domainSpecificConfig.parse(cfgFile)
 
+	effective := &Config{}
+	proto.Merge(&effective.DehydratedConfig, &c.DehydratedConfig)
+
 	if domainSpecificConfig.KeyAlgo != "" {
-		c.KeyAlgo = domainSpecificConfig.KeyAlgo
+		effective.KeyAlgo = domainSpecificConfig.KeyAlgo
 	}
 	if domainSpecificConfig.KeySize > 0 {
-		c.KeySize = domainSpecificConfig.KeySize
+		effective.KeySize = domainSpecificConfig.KeySize
 	}
 	if domainSpecificConfig.ChallengeType != "" {
-		c.ChallengeType = domainSpecificConfig.ChallengeType
+		effective.ChallengeType = domainSpecificConfig.ChallengeType
+	}
+	if domainSpecificConfig.Ca != "" {
+		effective.Ca = domainSpecificConfig.Ca
 	}
 
-	return c
+	return effective
+}
+
+// WithCA returns a copy of the configuration with CA set to ca.
+func (c *Config) WithCA(ca string) *Config {
+	cfg := &Config{}
+	proto.Merge(&cfg.DehydratedConfig, &c.DehydratedConfig)
+	cfg.Ca = ca
+	return cfg
+}
+
+// DomainSpecificCA returns the CA set in the config file of the certificate stored in path below CertDir,
+// or an empty string if the certificate does not override the CA or path is outside of CertDir.
+func (c *Config) DomainSpecificCA(path string) string {
+	cfgFile, err := c.CertPath(path, "config")
+	if err != nil {
+		return ""
+	}
+
+	domainSpecificConfig := &Config{}
+	domainSpecificConfig.parse(cfgFile)
+	return domainSpecificConfig.Ca
+}
+
+// SetDomainSpecificCA sets CA in the config file of the certificate stored in path below CertDir.
+// Other lines of the file are kept. An empty ca removes the override, and the file if nothing else is left.
+// It returns ErrInvalidPath if path is outside of CertDir.
+func (c *Config) SetDomainSpecificCA(path, ca string) error {
+	cfgFile, err := c.CertPath(path, "config")
+	if err != nil {
+		return err
+	}
+
+	data, err := os.ReadFile(cfgFile)
+	if err != nil && !os.IsNotExist(err) {
+		return err
+	}
+
+	var lines []string
+	for _, line := range strings.Split(string(data), "\n") {
+		if key, _, err := trimLine(line); err == nil && key == "CA" {
+			continue
+		}
+		lines = append(lines, line)
+	}
+	for len(lines) > 0 && strings.TrimSpace(lines[len(lines)-1]) == "" {
+		lines = lines[:len(lines)-1]
+	}
+	if ca != "" {
+		lines = append(lines, fmt.Sprintf("CA=%q", ca))
+	}
+
+	if len(lines) == 0 {
+		if err := os.Remove(cfgFile); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+		return nil
+	}
+
+	if err := os.MkdirAll(filepath.Dir(cfgFile), 0755); err != nil {
+		return err
+	}
+	//nolint:gosec // the certificate config is read by dehydrated, which may run as another user
+	return os.WriteFile(cfgFile, []byte(strings.Join(lines, "\n")+"\n"), 0644)
 }
 
 func (c *Config) ToProto() *pb.DehydratedConfig {
@@ -625,3 +625,59 @@ func TestConfig_MarshalJSON(t *testing.T) {
 		})
 	}
 }
+
+// TestDomainSpecificConfig verifies that the config file of a certificate overrides the global
+// configuration for that certificate only.
+func TestDomainSpecificConfig(t *testing.T) {
+	cfg := NewConfig().WithBaseDir(t.TempDir()).Load()
+	certDir := filepath.Join(cfg.CertDir, "example")
+	require.NoError(t, os.MkdirAll(certDir, 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(certDir, "config"), []byte("KEY_ALGO=secp384r1\nCHALLENGETYPE=\"dns-01\"\n"), 0644))
+
+	effective := cfg.DomainSpecificConfig("example")
+	require.Equal(t, "secp384r1", effective.KeyAlgo)
+	require.Equal(t, "dns-01", effective.ChallengeType)
+	require.Equal(t, cfg.KeySize, effective.KeySize)
+	require.Equal(t, cfg.DomainsFile, effective.DomainsFile)
+
+	// The global configuration and other certificates are not affected
+	require.Equal(t, "rsa", cfg.KeyAlgo)
+	require.Equal(t, "http-01", cfg.ChallengeType)
+	require.Same(t, cfg, cfg.DomainSpecificConfig("other"))
+}
+
+// TestDomainSpecificCA verifies that the CA of a certificate is set in its config file without touching other settings.
+func TestDomainSpecificCA(t *testing.T) {
+	cfg := NewConfig().WithBaseDir(t.TempDir()).Load()
+	cfgFile := filepath.Join(cfg.CertDir, "example", "config")
+
+	// The config file and its directory are created if missing
+	require.Empty(t, cfg.DomainSpecificCA("example"))
+	require.NoError(t, cfg.SetDomainSpecificCA("example", "letsencrypt-test"))
+	require.Equal(t, "letsencrypt-test", cfg.DomainSpecificCA("example"))
+	require.Equal(t, "letsencrypt-test", cfg.DomainSpecificConfig("example").Ca)
+	require.Equal(t, "letsencrypt", cfg.Ca)
+
+	// Other settings are kept, an existing CA is replaced
+	require.NoError(t, os.WriteFile(cfgFile, []byte("# staging\nKEY_ALGO=secp384r1\nexport CA='zerossl'\n\n"), 0644))
+	require.NoError(t, cfg.SetDomainSpecificCA("example", "letsencrypt-test"))
+	data, err := os.ReadFile(cfgFile)
+	require.NoError(t, err)
+	require.Equal(t, "# staging\nKEY_ALGO=secp384r1\nCA=\"letsencrypt-test\"\n", string(data))
+
+	// Removing the CA keeps the other settings
+	require.NoError(t, cfg.SetDomainSpecificCA("example", ""))
+	data, err = os.ReadFile(cfgFile)
+	require.NoError(t, err)
+	require.Equal(t, "# staging\nKEY_ALGO=secp384r1\n", string(data))
+	require.Equal(t, "letsencrypt", cfg.DomainSpecificConfig("example").Ca)
+
+	// A config file with nothing but the CA is removed with it
+	require.NoError(t, cfg.SetDomainSpecificCA("other", "zerossl"))
+	require.NoError(t, cfg.SetDomainSpecificCA("other", ""))
+	require.NoFileExists(t, filepath.Join(cfg.CertDir, "other", "config"))
+
+	// WithCA returns a copy
+	require.Equal(t, "zerossl", cfg.WithCA("zerossl").Ca)
+	require.Equal(t, "letsencrypt", cfg.Ca)
+}
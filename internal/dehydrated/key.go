@@ -0,0 +1,153 @@
+package dehydrated
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/hex"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"os"
+)
+
+// ErrKeyNotFound is returned when neither a private key nor a certificate exists for a domain.
+var ErrKeyNotFound = errors.New("key not found")
+
+// keyFile is the file dehydrated links to the current private key of a domain.
+const keyFile = "privkey.pem"
+
+// KeyInfo holds the fingerprint of the public key of a domain's certificate, never the key material itself.
+// @Description Public key fingerprint
+type KeyInfo struct {
+	// Algorithm is the public key algorithm (RSA, ECDSA or Ed25519).
+	// @Description Public key algorithm
+	Algorithm string `json:"algorithm" example:"ECDSA" enums:"RSA,ECDSA,Ed25519"`
+
+	// Size is the key size in bits, the curve size for ECDSA.
+	// @Description Key size in bits, the curve size for ECDSA
+	Size int `json:"size" example:"384"`
+
+	// SHA256 is the hex-encoded SHA-256 hash of the DER-encoded public key (SubjectPublicKeyInfo).
+	// @Description Hex-encoded SHA-256 hash of the DER-encoded public key
+	SHA256 string `json:"sha256" example:"9f86d081884c7d659a2feaa0c55ad015a3bf4f1b2b0b822cd15d6c15b0f00a08"`
+
+	// Source is the file the public key was read from, privkey.pem or cert.pem.
+	// @Description File the public key was read from
+	Source string `json:"source" example:"privkey.pem" enums:"privkey.pem,cert.pem"`
+}
+
+// KeyFile returns the path of the current private key of the domain entry with the given path name
+// (its alias or domain). It returns ErrInvalidPath if the path would be outside of CertDir.
+func (c *Config) KeyFile(pathName string) (string, error) {
+	return c.CertPath(pathName, keyFile)
+}
+
+// KeyInfo returns the fingerprint of the public key of the domain entry with the given path name. The key is
+// read from its private key, or from its certificate if the private key does not exist or is not readable by
+// the API. It returns ErrKeyNotFound if neither exists.
+func (c *Config) KeyInfo(pathName string) (*KeyInfo, error) {
+	file, err := c.KeyFile(pathName)
+	if err != nil {
+		return nil, err
+	}
+	info, err := readPrivateKeyInfo(file)
+	if !errors.Is(err, os.ErrNotExist) && !errors.Is(err, os.ErrPermission) {
+		return info, err
+	}
+
+	if file, err = c.CertFile(pathName); err != nil {
+		return nil, err
+	}
+	info, err = readCertKeyInfo(file)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, ErrKeyNotFound
+	}
+	return info, err
+}
+
+// readPrivateKeyInfo returns the fingerprint of the public key of the PEM-encoded private key in file,
+// encoded as PKCS #1, SEC 1 or PKCS #8.
+func readPrivateKeyInfo(file string) (*KeyInfo, error) {
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return nil, err
+	}
+
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, fmt.Errorf("no private key found in %s", file)
+	}
+
+	var key any
+	switch block.Type {
+	case "RSA PRIVATE KEY":
+		key, err = x509.ParsePKCS1PrivateKey(block.Bytes)
+	case "EC PRIVATE KEY":
+		key, err = x509.ParseECPrivateKey(block.Bytes)
+	case "PRIVATE KEY":
+		key, err = x509.ParsePKCS8PrivateKey(block.Bytes)
+	default:
+		return nil, fmt.Errorf("no private key found in %s", file)
+	}
+	if err != nil {
+		// The error of the parser does not contain key material
+		return nil, fmt.Errorf("failed to parse %s: %w", file, err)
+	}
+
+	signer, ok := key.(crypto.Signer)
+	if !ok {
+		return nil, fmt.Errorf("unsupported private key type %T in %s", key, file)
+	}
+
+	return newKeyInfo(signer.Public(), keyFile)
+}
+
+// readCertKeyInfo returns the fingerprint of the public key of the first certificate in the PEM file.
+func readCertKeyInfo(file string) (*KeyInfo, error) {
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return nil, err
+	}
+
+	block, _ := pem.Decode(data)
+	if block == nil || block.Type != "CERTIFICATE" {
+		return nil, fmt.Errorf("no certificate found in %s", file)
+	}
+
+	cert, err := x509.ParseCertificate(block.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", file, err)
+	}
+
+	return newKeyInfo(cert.PublicKey, certFile)
+}
+
+// newKeyInfo returns the fingerprint, algorithm and size of pub read from source.
+func newKeyInfo(pub any, source string) (*KeyInfo, error) {
+	der, err := x509.MarshalPKIXPublicKey(pub)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode public key: %w", err)
+	}
+	sum := sha256.Sum256(der)
+
+	info := &KeyInfo{SHA256: hex.EncodeToString(sum[:]), Source: source}
+	switch k := pub.(type) {
+	case *rsa.PublicKey:
+		info.Algorithm = "RSA"
+		info.Size = k.N.BitLen()
+	case *ecdsa.PublicKey:
+		info.Algorithm = "ECDSA"
+		info.Size = k.Curve.Params().BitSize
+	case ed25519.PublicKey:
+		info.Algorithm = "Ed25519"
+		info.Size = 256
+	default:
+		return nil, fmt.Errorf("unsupported public key type %T", pub)
+	}
+
+	return info, nil
+}
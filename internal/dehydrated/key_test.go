@@ -0,0 +1,130 @@
+package dehydrated
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/hex"
+	"encoding/json"
+	"math/big"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/schumann-it/dehydrated-api-go/internal/testutil"
+	"github.com/stretchr/testify/require"
+)
+
+// fingerprint returns the expected fingerprint of pub.
+func fingerprint(t *testing.T, pub crypto.PublicKey) string {
+	t.Helper()
+
+	der, err := x509.MarshalPKIXPublicKey(pub)
+	require.NoError(t, err)
+	sum := sha256.Sum256(der)
+	return hex.EncodeToString(sum[:])
+}
+
+func TestKeyInfo(t *testing.T) {
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	ecKey, err := ecdsa.GenerateKey(elliptic.P384(), rand.Reader)
+	require.NoError(t, err)
+	_, edKey, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+
+	pkcs8 := func(key any) []byte {
+		der, err := x509.MarshalPKCS8PrivateKey(key)
+		require.NoError(t, err)
+		return der
+	}
+	sec1, err := x509.MarshalECPrivateKey(ecKey)
+	require.NoError(t, err)
+
+	tests := []struct {
+		name      string
+		blockType string
+		der       []byte
+		pub       crypto.PublicKey
+		algorithm string
+		size      int
+	}{
+		{"RSA PKCS1", "RSA PRIVATE KEY", x509.MarshalPKCS1PrivateKey(rsaKey), &rsaKey.PublicKey, "RSA", 2048},
+		{"RSA PKCS8", "PRIVATE KEY", pkcs8(rsaKey), &rsaKey.PublicKey, "RSA", 2048},
+		{"ECDSA SEC1", "EC PRIVATE KEY", sec1, &ecKey.PublicKey, "ECDSA", 384},
+		{"ECDSA PKCS8", "PRIVATE KEY", pkcs8(ecKey), &ecKey.PublicKey, "ECDSA", 384},
+		{"Ed25519", "PRIVATE KEY", pkcs8(edKey), edKey.Public(), "Ed25519", 256},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := NewConfig().WithBaseDir(t.TempDir()).Load()
+			file, err := cfg.KeyFile("example.com")
+			require.NoError(t, err)
+			require.Equal(t, filepath.Join(cfg.CertDir, "example.com", "privkey.pem"), file)
+			testutil.WritePEM(t, file, tt.blockType, tt.der)
+
+			info, err := cfg.KeyInfo("example.com")
+			require.NoError(t, err)
+			require.Equal(t, tt.algorithm, info.Algorithm)
+			require.Equal(t, tt.size, info.Size)
+			require.Equal(t, fingerprint(t, tt.pub), info.SHA256)
+			require.Equal(t, "privkey.pem", info.Source)
+
+			// The key material is never part of the result
+			b, err := json.Marshal(info)
+			require.NoError(t, err)
+			require.NotContains(t, string(b), "PRIVATE")
+			require.Len(t, info.SHA256, 64)
+		})
+	}
+
+	t.Run("CertFallback", func(t *testing.T) {
+		cfg := NewConfig().WithBaseDir(t.TempDir()).Load()
+		certFile, err := cfg.CertFile("example.com")
+		require.NoError(t, err)
+
+		tmpl := &x509.Certificate{
+			SerialNumber: big.NewInt(1),
+			Subject:      pkix.Name{CommonName: "example.com"},
+			NotBefore:    time.Now(),
+			NotAfter:     time.Now().Add(time.Hour),
+		}
+		der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, &ecKey.PublicKey, ecKey)
+		require.NoError(t, err)
+		testutil.WritePEM(t, certFile, "CERTIFICATE", der)
+
+		info, err := cfg.KeyInfo("example.com")
+		require.NoError(t, err)
+		require.Equal(t, "ECDSA", info.Algorithm)
+		require.Equal(t, fingerprint(t, &ecKey.PublicKey), info.SHA256)
+		require.Equal(t, "cert.pem", info.Source)
+	})
+
+	t.Run("NotFound", func(t *testing.T) {
+		cfg := NewConfig().WithBaseDir(t.TempDir()).Load()
+		_, err := cfg.KeyInfo("example.com")
+		require.ErrorIs(t, err, ErrKeyNotFound)
+	})
+
+	t.Run("InvalidKey", func(t *testing.T) {
+		cfg := NewConfig().WithBaseDir(t.TempDir()).Load()
+		file, err := cfg.KeyFile("example.com")
+		require.NoError(t, err)
+		testutil.WritePEM(t, file, "CERTIFICATE", []byte("garbage"))
+
+		_, err = cfg.KeyInfo("example.com")
+		require.ErrorContains(t, err, "no private key found")
+	})
+
+	t.Run("InvalidPath", func(t *testing.T) {
+		cfg := NewConfig().WithBaseDir(t.TempDir()).Load()
+		_, err := cfg.KeyInfo("../outside")
+		require.ErrorIs(t, err, ErrInvalidPath)
+	})
+}
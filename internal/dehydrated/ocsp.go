@@ -0,0 +1,141 @@
+package dehydrated
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+	"time"
+
+	"golang.org/x/crypto/ocsp"
+)
+
+// ErrOCSPNotFound is returned when no OCSP response has been fetched for a domain, e.g., because OCSP_FETCH is disabled.
+var ErrOCSPNotFound = errors.New("ocsp response not found")
+
+// ocspFile is the file dehydrated links to the current OCSP response of a domain.
+const ocspFile = "ocsp.der"
+
+// OCSPInfo holds information about the OCSP response stapled for the certificate of a domain entry.
+// @Description OCSP response information
+type OCSPInfo struct {
+	// Status is the certificate status reported by the OCSP responder (good, revoked or unknown).
+	// @Description Certificate status reported by the OCSP responder
+	Status string `json:"status" example:"good" enums:"good,revoked,unknown"`
+
+	// ProducedAt is the time the OCSP responder signed the response.
+	// @Description Time the OCSP responder signed the response
+	ProducedAt time.Time `json:"produced_at" example:"2024-01-01T00:00:00Z"`
+
+	// ThisUpdate is the time the reported status is known to be correct.
+	// @Description Time the reported status is known to be correct
+	ThisUpdate time.Time `json:"this_update" example:"2024-01-01T00:00:00Z"`
+
+	// NextUpdate is the time newer information about the status will be available.
+	// @Description Time newer information about the status will be available
+	NextUpdate time.Time `json:"next_update" example:"2024-01-08T00:00:00Z"`
+}
+
+// OCSPFile returns the path of the current OCSP response of the domain entry with the given path name
+// (its alias or domain). It returns ErrInvalidPath if the path would be outside of CertDir.
+func (c *Config) OCSPFile(pathName string) (string, error) {
+	return c.CertPath(pathName, ocspFile)
+}
+
+// ReadOCSPInfo reads the information of the DER encoded OCSP response in file.
+// The signature of the response is not verified. It returns ErrOCSPNotFound if the file does not exist.
+func ReadOCSPInfo(file string) (*OCSPInfo, error) {
+	data, err := os.ReadFile(file)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, ErrOCSPNotFound
+		}
+		return nil, err
+	}
+
+	resp, err := ocsp.ParseResponse(data, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", file, err)
+	}
+
+	status := "unknown"
+	switch resp.Status {
+	case ocsp.Good:
+		status = "good"
+	case ocsp.Revoked:
+		status = "revoked"
+	}
+
+	return &OCSPInfo{
+		Status:     status,
+		ProducedAt: resp.ProducedAt,
+		ThisUpdate: resp.ThisUpdate,
+		NextUpdate: resp.NextUpdate,
+	}, nil
+}
+
+// ErrForceValidationWithoutForce is returned when CronOptions request a revalidation without a renewal.
+var ErrForceValidationWithoutForce = errors.New("force validation requires force renew")
+
+// CronOptions override the behavior of a single dehydrated run without changing its configuration.
+type CronOptions struct {
+	// ForceRenew renews the certificate even if it does not expire soon (--force).
+	ForceRenew bool
+
+	// ForceValidation revalidates the domain names even if valid authorizations exist
+	// (--force-validation). dehydrated only honors it together with ForceRenew.
+	ForceValidation bool
+}
+
+// Validate checks that the options can be combined.
+func (o CronOptions) Validate() error {
+	if o.ForceValidation && !o.ForceRenew {
+		return ErrForceValidationWithoutForce
+	}
+	return nil
+}
+
+// cronArgs returns the arguments of a "dehydrated --cron" run for a single certificate.
+func (c *Config) cronArgs(domains []string, alias string, opts CronOptions) []string {
+	args := []string{"--cron", "--config", c.ConfigFile}
+	for _, d := range domains {
+		args = append(args, "--domain", d)
+	}
+	if alias != "" {
+		args = append(args, "--alias", alias)
+	}
+	if opts.ForceRenew {
+		args = append(args, "--force")
+	}
+	if opts.ForceValidation {
+		args = append(args, "--force-validation")
+	}
+	return args
+}
+
+// RunCron runs "dehydrated --cron" with the given script for a single certificate with the given
+// domain names, stored under alias if set. Besides renewing the certificate if due, dehydrated
+// fetches a new OCSP response if OCSP_FETCH is enabled and the current one is older than OCSP_DAYS.
+// opts apply to this run only. The output of dehydrated is returned in the error if it fails.
+func (c *Config) RunCron(ctx context.Context, script string, domains []string, alias string, opts CronOptions) error {
+	if err := opts.Validate(); err != nil {
+		return err
+	}
+
+	//nolint:gosec // the script is configured by the operator, the arguments are validated domain names
+	cmd := exec.CommandContext(ctx, script, c.cronArgs(domains, alias, opts)...)
+	cmd.Dir = c.BaseDir
+
+	var output bytes.Buffer
+	cmd.Stdout = &output
+	cmd.Stderr = &output
+
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("dehydrated failed: %w: %s", err, strings.TrimSpace(output.String()))
+	}
+
+	return nil
+}
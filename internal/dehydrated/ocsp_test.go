@@ -0,0 +1,90 @@
+package dehydrated
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/schumann-it/dehydrated-api-go/internal/testutil"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/ocsp"
+)
+
+func TestReadOCSPInfo(t *testing.T) {
+	cfg := NewConfig().WithBaseDir(t.TempDir()).Load()
+	file, err := cfg.OCSPFile("example.com")
+	require.NoError(t, err)
+	require.Equal(t, filepath.Join(cfg.CertDir, "example.com", "ocsp.der"), file)
+
+	_, err = ReadOCSPInfo(file)
+	require.ErrorIs(t, err, ErrOCSPNotFound)
+
+	nextUpdate := time.Now().Add(3 * 24 * time.Hour).UTC().Truncate(time.Second)
+	require.NoError(t, os.MkdirAll(filepath.Dir(file), 0o755))
+
+	for status, name := range map[int]string{ocsp.Good: "good", ocsp.Revoked: "revoked", ocsp.Unknown: "unknown"} {
+		require.NoError(t, os.WriteFile(file, testutil.OCSPResponse(t, status, nextUpdate), 0o600))
+		info, err := ReadOCSPInfo(file)
+		require.NoError(t, err)
+		require.Equal(t, name, info.Status)
+		require.True(t, nextUpdate.Equal(info.NextUpdate))
+	}
+
+	require.NoError(t, os.WriteFile(file, []byte("garbage"), 0o600))
+	_, err = ReadOCSPInfo(file)
+	require.ErrorContains(t, err, "failed to parse")
+}
+
+func TestRunCron(t *testing.T) {
+	cfg := NewConfig().WithBaseDir(t.TempDir()).Load()
+
+	// The stub records its arguments in the working directory
+	script := filepath.Join(t.TempDir(), "dehydrated")
+	//nolint:gosec // the stub has to be executable
+	require.NoError(t, os.WriteFile(script, []byte("#!/bin/sh\necho \"$@\" > args\n"), 0o755))
+
+	require.NoError(t, cfg.RunCron(context.Background(), script, []string{"example.com", "www.example.com"}, "example-rsa", CronOptions{}))
+	args, err := os.ReadFile(filepath.Join(cfg.BaseDir, "args"))
+	require.NoError(t, err)
+	require.Equal(t, "--cron --config "+cfg.ConfigFile+" --domain example.com --domain www.example.com --alias example-rsa",
+		strings.TrimSpace(string(args)))
+
+	// The output of a failing run is returned in the error
+	//nolint:gosec // the stub has to be executable
+	require.NoError(t, os.WriteFile(script, []byte("#!/bin/sh\necho 'ERROR: Challenge is invalid!' >&2\nexit 1\n"), 0o755))
+	err = cfg.RunCron(context.Background(), script, []string{"example.com"}, "", CronOptions{})
+	require.ErrorContains(t, err, "Challenge is invalid")
+}
+
+func TestCronArgs(t *testing.T) {
+	cfg := NewConfig().WithBaseDir(t.TempDir()).Load()
+	base := []string{"--cron", "--config", cfg.ConfigFile, "--domain", "example.com"}
+
+	tests := []struct {
+		name string
+		opts CronOptions
+		want []string
+	}{
+		{name: "default", opts: CronOptions{}, want: base},
+		{name: "force renew", opts: CronOptions{ForceRenew: true}, want: append(base[:len(base):len(base)], "--force")},
+		{
+			name: "force validation",
+			opts: CronOptions{ForceRenew: true, ForceValidation: true},
+			want: append(base[:len(base):len(base)], "--force", "--force-validation"),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require.NoError(t, tt.opts.Validate())
+			require.Equal(t, tt.want, cfg.cronArgs([]string{"example.com"}, "", tt.opts))
+		})
+	}
+
+	// A revalidation without renewal is ignored by dehydrated and rejected before running it
+	err := cfg.RunCron(context.Background(), "false", []string{"example.com"}, "", CronOptions{ForceValidation: true})
+	require.ErrorIs(t, err, ErrForceValidationWithoutForce)
+}
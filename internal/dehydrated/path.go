@@ -0,0 +1,28 @@
+package dehydrated
+
+import (
+	"errors"
+	"fmt"
+	"path/filepath"
+	"strings"
+)
+
+// ErrInvalidPath is returned when the path name of a certificate would resolve outside of CertDir.
+var ErrInvalidPath = errors.New("invalid certificate path")
+
+// CertPath returns the path of elem in the directory of the certificate with the given path name (the alias
+// or domain of an entry) below CertDir. The path names of entries written manually to the domains file are
+// not validated, so it returns ErrInvalidPath if the directory would not be a subdirectory of CertDir,
+// e.g., for path names containing "..", instead of accessing files outside of CertDir.
+func (c *Config) CertPath(pathName string, elem ...string) (string, error) {
+	base := filepath.Clean(c.CertDir)
+	dir := filepath.Join(base, pathName)
+
+	rel, err := filepath.Rel(base, dir)
+	if err != nil || rel == "." || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) ||
+		strings.ContainsRune(pathName, 0) {
+		return "", fmt.Errorf("%w: %q is outside of %s", ErrInvalidPath, pathName, base)
+	}
+
+	return filepath.Join(append([]string{dir}, elem...)...), nil
+}
@@ -0,0 +1,31 @@
+package dehydrated
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// TestCertPath verifies that paths of certificates are confined to CertDir.
+func TestCertPath(t *testing.T) {
+	cfg := NewConfig().WithBaseDir(t.TempDir()).Load()
+
+	file, err := cfg.CertPath("example.com", "config")
+	require.NoError(t, err)
+	require.Equal(t, filepath.Join(cfg.CertDir, "example.com", "config"), file)
+
+	dir, err := cfg.CertPath("example.com")
+	require.NoError(t, err)
+	require.Equal(t, filepath.Join(cfg.CertDir, "example.com"), dir)
+
+	// Absolute path names are joined below CertDir as well
+	file, err = cfg.CertPath("/etc", "config")
+	require.NoError(t, err)
+	require.Equal(t, filepath.Join(cfg.CertDir, "etc", "config"), file)
+
+	for _, pathName := range []string{"", ".", "..", "../etc", "../../etc", "a/../..", "a/../../certs-other", "a\x00b"} {
+		_, err := cfg.CertPath(pathName, "config")
+		require.ErrorIs(t, err, ErrInvalidPath, pathName)
+	}
+}
@@ -0,0 +1,55 @@
+package dehydrated
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+)
+
+// KeyAlgoRSA is the RSA key algorithm, the only one where KEY_SIZE applies.
+const KeyAlgoRSA = "rsa"
+
+// rsaKeySizes are the RSA key sizes supported by dehydrated.
+var rsaKeySizes = []int32{2048, 3072, 4096}
+
+// ecKeyAlgos are the elliptic curve key algorithms supported by dehydrated.
+var ecKeyAlgos = []string{"prime256v1", "secp384r1"}
+
+// ChallengeTypes are the challenge types supported by dehydrated.
+var ChallengeTypes = []string{"http-01", "dns-01", "tls-alpn-01"}
+
+// ErrChallengeTypeNotAllowed is returned by CheckChallengeType for a challenge type that is not allowed.
+var ErrChallengeTypeNotAllowed = errors.New("challenge type not allowed")
+
+// Validate checks the configuration for nonsensical settings and returns a list of warnings.
+// Currently, it checks the compatibility of KEY_ALGO and KEY_SIZE:
+// RSA requires one of the supported key sizes, while elliptic curve algorithms ignore KEY_SIZE.
+func (c *Config) Validate() []string {
+	var warnings []string
+
+	switch {
+	case c.KeyAlgo == KeyAlgoRSA:
+		if !slices.Contains(rsaKeySizes, c.KeySize) {
+			warnings = append(warnings, fmt.Sprintf("KEY_SIZE %d is not supported for KEY_ALGO %s, use one of %v",
+				c.KeySize, c.KeyAlgo, rsaKeySizes))
+		}
+	case slices.Contains(ecKeyAlgos, c.KeyAlgo):
+		if c.keySizeSet {
+			warnings = append(warnings, fmt.Sprintf("KEY_SIZE %d is ignored for KEY_ALGO %s", c.KeySize, c.KeyAlgo))
+		}
+	default:
+		warnings = append(warnings, fmt.Sprintf("KEY_ALGO %q is not supported, use one of %v",
+			c.KeyAlgo, append([]string{KeyAlgoRSA}, ecKeyAlgos...)))
+	}
+
+	return warnings
+}
+
+// CheckChallengeType returns an error wrapping ErrChallengeTypeNotAllowed if CHALLENGETYPE is not one of allowed.
+// An empty allowlist allows all challenge types.
+func (c *Config) CheckChallengeType(allowed []string) error {
+	if len(allowed) == 0 || slices.Contains(allowed, c.ChallengeType) {
+		return nil
+	}
+	return fmt.Errorf("%w: CHALLENGETYPE %s, use one of %v", ErrChallengeTypeNotAllowed, c.ChallengeType, allowed)
+}
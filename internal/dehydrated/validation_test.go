@@ -0,0 +1,64 @@
+package dehydrated
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// TestValidate verifies the KEY_ALGO/KEY_SIZE compatibility checks.
+func TestValidate(t *testing.T) {
+	tests := []struct {
+		name     string
+		config   string
+		warnings int
+		contains string
+	}{
+		{"DefaultRSA", "", 0, ""},
+		{"RSA2048", "KEY_ALGO=rsa\nKEY_SIZE=2048\n", 0, ""},
+		{"RSA3072", "KEY_ALGO=rsa\nKEY_SIZE=3072\n", 0, ""},
+		{"RSAInvalidSize", "KEY_ALGO=rsa\nKEY_SIZE=1024\n", 1, "KEY_SIZE 1024 is not supported"},
+		{"ECWithoutSize", "KEY_ALGO=prime256v1\n", 0, ""},
+		{"ECWithSize", "KEY_ALGO=prime256v1\nKEY_SIZE=2048\n", 1, "KEY_SIZE 2048 is ignored for KEY_ALGO prime256v1"},
+		{"Secp384r1", "KEY_ALGO=secp384r1\n", 0, ""},
+		{"UnknownAlgo", "KEY_ALGO=dsa\n", 1, `KEY_ALGO "dsa" is not supported`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tmpDir := t.TempDir()
+			err := os.WriteFile(filepath.Join(tmpDir, "config"), []byte(tt.config), 0644)
+			require.NoError(t, err)
+
+			cfg := NewConfig().WithBaseDir(tmpDir).WithValidation().Load()
+			require.Len(t, cfg.Warnings(), tt.warnings)
+			if tt.contains != "" {
+				require.Contains(t, cfg.Warnings()[0], tt.contains)
+			}
+		})
+	}
+
+	t.Run("OptIn", func(t *testing.T) {
+		tmpDir := t.TempDir()
+		err := os.WriteFile(filepath.Join(tmpDir, "config"), []byte("KEY_ALGO=rsa\nKEY_SIZE=1024\n"), 0644)
+		require.NoError(t, err)
+
+		cfg := NewConfig().WithBaseDir(tmpDir).Load()
+		require.Empty(t, cfg.Warnings())
+	})
+}
+
+// TestCheckChallengeType verifies the challenge type allowlist.
+func TestCheckChallengeType(t *testing.T) {
+	cfg := NewConfig()
+	cfg.ChallengeType = "http-01"
+
+	require.NoError(t, cfg.CheckChallengeType(nil))
+	require.NoError(t, cfg.CheckChallengeType([]string{"dns-01", "http-01"}))
+
+	err := cfg.CheckChallengeType([]string{"dns-01"})
+	require.ErrorIs(t, err, ErrChallengeTypeNotAllowed)
+	require.ErrorContains(t, err, "CHALLENGETYPE http-01, use one of [dns-01]")
+}
@@ -0,0 +1,57 @@
+package handler
+
+import (
+	"errors"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/schumann-it/dehydrated-api-go/internal/dehydrated"
+	"github.com/schumann-it/dehydrated-api-go/internal/model"
+)
+
+// AccountHandler handles HTTP requests for ACME account operations
+type AccountHandler struct {
+	cfg *dehydrated.Config
+}
+
+// NewAccountHandler creates a new AccountHandler instance
+func NewAccountHandler(cfg *dehydrated.Config) *AccountHandler {
+	return &AccountHandler{
+		cfg: cfg,
+	}
+}
+
+// RegisterRoutes registers all account-related routes
+func (h *AccountHandler) RegisterRoutes(app fiber.Router) {
+	app.Get("account", h.Account)
+}
+
+// @Summary Get ACME account
+// @Description Retrieve the ACME account dehydrated registered with the configured CA
+// @Tags account
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} model.AccountResponse "Account retrieved successfully"
+// @Failure 401 {object} model.AccountResponse "Unauthorized - Invalid or missing authentication token"
+// @Failure 404 {object} model.AccountResponse "Not Found - No account registered yet"
+// @Failure 500 {object} model.AccountResponse "Internal Server Error - Failed to read account"
+// @Router /api/v1/account [get]
+// Account handles GET /api/v1/account
+func (h *AccountHandler) Account(c *fiber.Ctx) error {
+	account, err := h.cfg.Account()
+	if err != nil {
+		status := fiber.StatusInternalServerError
+		if errors.Is(err, dehydrated.ErrAccountNotFound) {
+			status = fiber.StatusNotFound
+		}
+		return c.Status(status).JSON(model.AccountResponse{
+			Success: false,
+			Error:   err.Error(),
+			Code:    errorCode(err, status),
+		})
+	}
+
+	return c.JSON(model.AccountResponse{
+		Success: true,
+		Data:    account,
+	})
+}
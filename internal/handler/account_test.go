@@ -0,0 +1,59 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/schumann-it/dehydrated-api-go/internal/dehydrated"
+	"github.com/schumann-it/dehydrated-api-go/internal/model"
+	"github.com/stretchr/testify/require"
+)
+
+// TestAccountHandler verifies the account endpoint for registered and missing accounts.
+func TestAccountHandler(t *testing.T) {
+	t.Run("Registered", func(t *testing.T) {
+		dc := dehydrated.NewConfig().WithBaseDir(t.TempDir()).Load()
+		require.NoError(t, os.MkdirAll(dc.AccountDir(), 0755))
+		require.NoError(t, os.WriteFile(filepath.Join(dc.AccountDir(), "registration_info.json"),
+			[]byte(`{"contact":["mailto:admin@example.com"],"status":"valid"}`), 0600))
+		require.NoError(t, os.WriteFile(filepath.Join(dc.AccountDir(), "account_id.json"),
+			[]byte(`{"url":"https://acme.example.com/acct/1"}`), 0600))
+
+		app := fiber.New()
+		NewAccountHandler(dc).RegisterRoutes(app.Group("/api/v1"))
+
+		result, err := app.Test(httptest.NewRequest("GET", "/api/v1/account", http.NoBody))
+		require.NoError(t, err)
+		defer result.Body.Close()
+		require.Equal(t, fiber.StatusOK, result.StatusCode)
+
+		var response model.AccountResponse
+		require.NoError(t, json.NewDecoder(result.Body).Decode(&response))
+		require.True(t, response.Success)
+		require.Equal(t, "https://acme.example.com/acct/1", response.Data.URL)
+		require.Equal(t, []string{"mailto:admin@example.com"}, response.Data.Contact)
+		require.Equal(t, "valid", response.Data.Status)
+	})
+
+	t.Run("NotRegistered", func(t *testing.T) {
+		dc := dehydrated.NewConfig().WithBaseDir(t.TempDir()).Load()
+
+		app := fiber.New()
+		NewAccountHandler(dc).RegisterRoutes(app.Group("/api/v1"))
+
+		result, err := app.Test(httptest.NewRequest("GET", "/api/v1/account", http.NoBody))
+		require.NoError(t, err)
+		defer result.Body.Close()
+		require.Equal(t, fiber.StatusNotFound, result.StatusCode)
+
+		var response model.AccountResponse
+		require.NoError(t, json.NewDecoder(result.Body).Decode(&response))
+		require.False(t, response.Success)
+		require.Equal(t, dehydrated.ErrAccountNotFound.Error(), response.Error)
+	})
+}
@@ -0,0 +1,171 @@
+package handler
+
+import (
+	"github.com/gofiber/fiber/v2"
+	"github.com/schumann-it/dehydrated-api-go/internal/auth"
+	"github.com/schumann-it/dehydrated-api-go/internal/model"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// allowedLogLevels are the log levels that can be set at runtime
+var allowedLogLevels = map[zapcore.Level]bool{
+	zapcore.DebugLevel: true,
+	zapcore.InfoLevel:  true,
+	zapcore.WarnLevel:  true,
+	zapcore.ErrorLevel: true,
+}
+
+// Watcher is a file watcher operators can pause and resume, see service.FileWatcher.
+type Watcher interface {
+	Pause()
+	Resume()
+	Paused() bool
+}
+
+// AdminHandler handles HTTP requests for administrative operations
+type AdminHandler struct {
+	level   zap.AtomicLevel
+	logger  *zap.Logger
+	watcher Watcher
+}
+
+// NewAdminHandler creates a new AdminHandler instance operating on the given log level
+func NewAdminHandler(level zap.AtomicLevel, logger *zap.Logger) *AdminHandler {
+	return &AdminHandler{
+		level:  level,
+		logger: logger,
+	}
+}
+
+// WithWatcher enables the endpoints to pause and resume the file watcher of the domains file.
+func (h *AdminHandler) WithWatcher(watcher Watcher) *AdminHandler {
+	h.watcher = watcher
+	return h
+}
+
+// RegisterRoutes registers all admin-related routes
+func (h *AdminHandler) RegisterRoutes(app fiber.Router) {
+	app.Get("loglevel", h.GetLogLevel)
+	app.Put("loglevel", h.SetLogLevel)
+	if h.watcher != nil {
+		app.Get("watcher", h.GetWatcher)
+		app.Post("watcher", h.SetWatcher)
+	}
+}
+
+// @Summary Get log level
+// @Description Get the current log level
+// @Tags admin
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} model.LogLevelResponse
+// @Failure 401 {object} model.LogLevelResponse "Unauthorized - Invalid or missing authentication token"
+// @Failure 403 {object} model.LogLevelResponse "Forbidden - Missing admin role"
+// @Router /api/v1/admin/loglevel [get]
+// GetLogLevel handles GET /api/v1/admin/loglevel
+func (h *AdminHandler) GetLogLevel(c *fiber.Ctx) error {
+	return c.JSON(model.LogLevelResponse{
+		Success: true,
+		Level:   h.level.Level().String(),
+	})
+}
+
+// @Summary Set log level
+// @Description Change the log level at runtime without restarting the server
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body model.LogLevelRequest true "Log level request"
+// @Success 200 {object} model.LogLevelResponse
+// @Failure 400 {object} model.LogLevelResponse "Bad Request - Invalid request body or log level"
+// @Failure 401 {object} model.LogLevelResponse "Unauthorized - Invalid or missing authentication token"
+// @Failure 403 {object} model.LogLevelResponse "Forbidden - Missing admin role"
+// @Router /api/v1/admin/loglevel [put]
+// SetLogLevel handles PUT /api/v1/admin/loglevel
+func (h *AdminHandler) SetLogLevel(c *fiber.Ctx) error {
+	var req model.LogLevelRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(model.LogLevelResponse{
+			Success: false,
+			Error:   "invalid request body",
+			Code:    model.CodeValidationFailed,
+		})
+	}
+
+	// ParseLevel treats an empty level as info, so it is rejected explicitly
+	level, err := zapcore.ParseLevel(req.Level)
+	if req.Level == "" || err != nil || !allowedLogLevels[level] {
+		return c.Status(fiber.StatusBadRequest).JSON(model.LogLevelResponse{
+			Success: false,
+			Error:   "level must be one of debug, info, warn, error",
+			Code:    model.CodeValidationFailed,
+		})
+	}
+
+	h.level.SetLevel(level)
+	h.logger.Info("Log level changed", append([]zap.Field{zap.String("level", level.String())},
+		auth.RequestIdentity(c).Fields()...)...)
+
+	return c.JSON(model.LogLevelResponse{
+		Success: true,
+		Level:   level.String(),
+	})
+}
+
+// @Summary Get file watcher state
+// @Description Get whether the file watcher applies external changes of domains.txt or is paused.
+// @Description Only available if the file watcher is enabled in the server configuration.
+// @Tags admin
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} model.WatcherResponse
+// @Failure 401 {object} model.WatcherResponse "Unauthorized - Invalid or missing authentication token"
+// @Failure 403 {object} model.WatcherResponse "Forbidden - Missing admin role"
+// @Router /api/v1/admin/watcher [get]
+// GetWatcher handles GET /api/v1/admin/watcher
+func (h *AdminHandler) GetWatcher(c *fiber.Ctx) error {
+	return c.JSON(model.WatcherResponse{
+		Success: true,
+		Enabled: !h.watcher.Paused(),
+	})
+}
+
+// @Summary Pause or resume the file watcher
+// @Description Pause the file watcher to ignore external changes of domains.txt, e.g., during bulk maintenance,
+// @Description until it is resumed. Resuming reloads domains.txt, which applies the changes made while paused.
+// @Description Only available if the file watcher is enabled in the server configuration.
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body model.WatcherRequest true "Watcher state request"
+// @Success 200 {object} model.WatcherResponse
+// @Failure 400 {object} model.WatcherResponse "Bad Request - Invalid request body"
+// @Failure 401 {object} model.WatcherResponse "Unauthorized - Invalid or missing authentication token"
+// @Failure 403 {object} model.WatcherResponse "Forbidden - Missing admin role"
+// @Router /api/v1/admin/watcher [post]
+// SetWatcher handles POST /api/v1/admin/watcher
+func (h *AdminHandler) SetWatcher(c *fiber.Ctx) error {
+	var req model.WatcherRequest
+	if err := c.BodyParser(&req); err != nil || req.Enabled == nil {
+		return c.Status(fiber.StatusBadRequest).JSON(model.WatcherResponse{
+			Success: false,
+			Enabled: !h.watcher.Paused(),
+			Error:   "enabled is required",
+			Code:    model.CodeValidationFailed,
+		})
+	}
+
+	if *req.Enabled {
+		h.watcher.Resume()
+	} else {
+		h.watcher.Pause()
+	}
+
+	return c.JSON(model.WatcherResponse{
+		Success: true,
+		Enabled: !h.watcher.Paused(),
+	})
+}
@@ -0,0 +1,135 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/schumann-it/dehydrated-api-go/internal/model"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+// TestAdminHandler verifies that the log level can be changed at runtime.
+func TestAdminHandler(t *testing.T) {
+	level := zap.NewAtomicLevelAt(zapcore.InfoLevel)
+	core, logs := observer.New(level)
+	logger := zap.New(core)
+
+	app := fiber.New()
+	NewAdminHandler(level, logger).RegisterRoutes(app.Group("/api/v1/admin"))
+
+	setLevel := func(body string) (int, model.LogLevelResponse) {
+		req := httptest.NewRequest("PUT", "/api/v1/admin/loglevel", strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		result, err := app.Test(req)
+		require.NoError(t, err)
+		defer result.Body.Close()
+
+		var response model.LogLevelResponse
+		require.NoError(t, json.NewDecoder(result.Body).Decode(&response))
+		return result.StatusCode, response
+	}
+
+	t.Run("EnableDebug", func(t *testing.T) {
+		logger.Debug("before")
+		require.Empty(t, logs.FilterMessage("before").All())
+
+		status, response := setLevel(`{"level":"debug"}`)
+		require.Equal(t, fiber.StatusOK, status)
+		require.True(t, response.Success)
+		require.Equal(t, "debug", response.Level)
+
+		logger.Debug("after")
+		require.Len(t, logs.FilterMessage("after").All(), 1)
+	})
+
+	t.Run("GetLevel", func(t *testing.T) {
+		result, err := app.Test(httptest.NewRequest("GET", "/api/v1/admin/loglevel", http.NoBody))
+		require.NoError(t, err)
+		defer result.Body.Close()
+
+		var response model.LogLevelResponse
+		require.NoError(t, json.NewDecoder(result.Body).Decode(&response))
+		require.Equal(t, "debug", response.Level)
+	})
+
+	t.Run("InvalidLevel", func(t *testing.T) {
+		for _, body := range []string{`{"level":"verbose"}`, `{"level":"fatal"}`, `{}`, `invalid`} {
+			status, response := setLevel(body)
+			require.Equal(t, fiber.StatusBadRequest, status, body)
+			require.False(t, response.Success)
+		}
+		require.Equal(t, zapcore.DebugLevel, level.Level())
+	})
+}
+
+// fakeWatcher records the pause state set via the admin endpoints.
+type fakeWatcher struct {
+	paused  bool
+	resumes int
+}
+
+func (w *fakeWatcher) Pause()       { w.paused = true }
+func (w *fakeWatcher) Resume()      { w.paused = false; w.resumes++ }
+func (w *fakeWatcher) Paused() bool { return w.paused }
+
+// TestWatcherEndpoints verifies that the file watcher can be paused and resumed, and that the
+// endpoints only exist if the watcher is enabled.
+func TestWatcherEndpoints(t *testing.T) {
+	watcher := &fakeWatcher{}
+	app := fiber.New()
+	NewAdminHandler(zap.NewAtomicLevel(), zap.NewNop()).WithWatcher(watcher).RegisterRoutes(app.Group("/api/v1/admin"))
+
+	request := func(t *testing.T, method, body string) (int, model.WatcherResponse) {
+		t.Helper()
+		req := httptest.NewRequest(method, "/api/v1/admin/watcher", strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		result, err := app.Test(req)
+		require.NoError(t, err)
+		defer result.Body.Close()
+
+		var response model.WatcherResponse
+		require.NoError(t, json.NewDecoder(result.Body).Decode(&response))
+		return result.StatusCode, response
+	}
+
+	status, response := request(t, "GET", "")
+	require.Equal(t, fiber.StatusOK, status)
+	require.True(t, response.Enabled)
+
+	status, response = request(t, "POST", `{"enabled": false}`)
+	require.Equal(t, fiber.StatusOK, status)
+	require.False(t, response.Enabled)
+	require.True(t, watcher.paused)
+
+	// The pause persists until the watcher is enabled again
+	_, response = request(t, "GET", "")
+	require.False(t, response.Enabled)
+
+	status, response = request(t, "POST", `{"enabled": true}`)
+	require.Equal(t, fiber.StatusOK, status)
+	require.True(t, response.Enabled)
+	require.Equal(t, 1, watcher.resumes)
+
+	for _, body := range []string{`{}`, `{"enabled": "no"}`, `{`} {
+		status, response = request(t, "POST", body)
+		require.Equal(t, fiber.StatusBadRequest, status, body)
+		require.Equal(t, model.CodeValidationFailed, response.Code, body)
+	}
+
+	t.Run("WithoutWatcher", func(t *testing.T) {
+		app := fiber.New()
+		NewAdminHandler(zap.NewAtomicLevel(), zap.NewNop()).RegisterRoutes(app.Group("/api/v1/admin"))
+
+		result, err := app.Test(httptest.NewRequest("GET", "/api/v1/admin/watcher", http.NoBody))
+		require.NoError(t, err)
+		defer result.Body.Close()
+		require.Equal(t, fiber.StatusNotFound, result.StatusCode)
+	})
+}
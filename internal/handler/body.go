@@ -0,0 +1,43 @@
+package handler
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// errInvalidBody is the error of request bodies that cannot be parsed.
+var errInvalidBody = errors.New("invalid request body")
+
+// parseBody parses the request body into out. With WithRejectUnknownFields, JSON bodies are decoded strictly
+// and fields out does not have are rejected, naming the field, e.g., a misspelled "domian". Other content
+// types and JSON bodies without the option are parsed by fiber.Ctx.BodyParser, which ignores unknown fields.
+func (h *DomainHandler) parseBody(c *fiber.Ctx, out any) error {
+	contentType := strings.ToLower(string(c.Request().Header.ContentType()))
+	if !h.rejectUnknownFields || !strings.HasPrefix(contentType, fiber.MIMEApplicationJSON) {
+		if err := c.BodyParser(out); err != nil {
+			return errInvalidBody
+		}
+		return nil
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(c.Body()))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(out); err != nil {
+		// The decoder reports unknown fields as `json: unknown field "name"`
+		if field, ok := strings.CutPrefix(err.Error(), "json: unknown field "); ok {
+			return fmt.Errorf("%w: unknown field %s", errInvalidBody, field)
+		}
+		return errInvalidBody
+	}
+	if _, err := decoder.Token(); !errors.Is(err, io.EOF) {
+		return errInvalidBody
+	}
+
+	return nil
+}
@@ -0,0 +1,48 @@
+package handler
+
+import (
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/schumann-it/dehydrated-api-go/internal/auth"
+	"github.com/schumann-it/dehydrated-api-go/internal/model"
+)
+
+// WithCommentTemplate sets the template of the comment of entries created without one, e.g.,
+// "created {date} by {actor} via api". See the model.CommentPlaceholder constants for the placeholders;
+// the actor is the subject of auth.RequestIdentity. The template is expected to be valid, see
+// model.ValidateCommentTemplate. Disabled if empty.
+func (h *DomainHandler) WithCommentTemplate(tmpl string) *DomainHandler {
+	h.commentTemplate = tmpl
+	return h
+}
+
+// applyCommentTemplate sets the comment of req to the rendered comment template if it has none.
+func (h *DomainHandler) applyCommentTemplate(c *fiber.Ctx, req *model.CreateDomainRequest) {
+	if h.commentTemplate == "" || strings.TrimSpace(req.Comment) != "" {
+		return
+	}
+	req.Comment = renderComment(h.commentTemplate, req.Domain, auth.RequestIdentity(c).Subject, time.Now())
+}
+
+// renderComment replaces the placeholders of tmpl. Control characters are removed from the values,
+// so the comment stays on a single line whatever the caller sends.
+func renderComment(tmpl, domain, actor string, now time.Time) string {
+	return strings.NewReplacer(
+		model.CommentPlaceholderDate, now.UTC().Format(time.DateOnly),
+		model.CommentPlaceholderActor, stripControl(actor),
+		model.CommentPlaceholderDomain, stripControl(domain),
+	).Replace(tmpl)
+}
+
+// stripControl removes control characters from s.
+func stripControl(s string) string {
+	return strings.Map(func(r rune) rune {
+		if unicode.IsControl(r) {
+			return -1
+		}
+		return r
+	}, s)
+}
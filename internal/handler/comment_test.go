@@ -0,0 +1,16 @@
+package handler
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestRenderComment(t *testing.T) {
+	now := time.Date(2024, 1, 2, 23, 30, 0, 0, time.FixedZone("UTC-2", -2*60*60))
+	require.Equal(t, "created 2024-01-03 by alice via api for example.com",
+		renderComment("created {date} by {actor} via api for {domain}", "example.com", "alice", now))
+	require.Equal(t, "alice alice", renderComment("{actor} {actor}", "example.com", "alice", now))
+	require.Equal(t, "by alice", renderComment("by {actor}", "example.com", "al\tice\r\n", now))
+}
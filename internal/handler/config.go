@@ -35,7 +35,8 @@ func (h *ConfigHandler) RegisterRoutes(app *fiber.App) {
 // @Router /config [get]
 func (h *ConfigHandler) Config(c *fiber.Ctx) error {
 	return c.JSON(model.ConfigResponse{
-		Success: true,
-		Data:    h.cfg,
+		Success:  true,
+		Data:     h.cfg,
+		Warnings: h.cfg.Warnings(),
 	})
 }
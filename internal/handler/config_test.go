@@ -0,0 +1,37 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/schumann-it/dehydrated-api-go/internal/dehydrated"
+	"github.com/schumann-it/dehydrated-api-go/internal/model"
+	"github.com/stretchr/testify/require"
+)
+
+// TestConfigHandlerWarnings verifies that validation warnings are part of the config response.
+func TestConfigHandlerWarnings(t *testing.T) {
+	tmpDir := t.TempDir()
+	err := os.WriteFile(filepath.Join(tmpDir, "config"), []byte("KEY_ALGO=prime256v1\nKEY_SIZE=2048\n"), 0644)
+	require.NoError(t, err)
+
+	dc := dehydrated.NewConfig().WithBaseDir(tmpDir).WithValidation().Load()
+
+	app := fiber.New()
+	NewConfigHandler(dc).RegisterRoutes(app)
+
+	result, err := app.Test(httptest.NewRequest("GET", "/config", http.NoBody))
+	require.NoError(t, err)
+	defer result.Body.Close()
+	require.Equal(t, fiber.StatusOK, result.StatusCode)
+
+	var response model.ConfigResponse
+	require.NoError(t, json.NewDecoder(result.Body).Decode(&response))
+	require.True(t, response.Success)
+	require.Equal(t, []string{"KEY_SIZE 2048 is ignored for KEY_ALGO prime256v1"}, response.Warnings)
+}
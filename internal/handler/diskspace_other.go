@@ -0,0 +1,14 @@
+//go:build !unix
+
+package handler
+
+import (
+	"errors"
+	"fmt"
+	"runtime"
+)
+
+// freeDiskSpace is not supported on this platform, so the disk space check has to be disabled.
+func freeDiskSpace(_ string) (uint64, error) {
+	return 0, fmt.Errorf("%w on %s, set minFreeDiskSpaceMB to 0", errors.ErrUnsupported, runtime.GOOS)
+}
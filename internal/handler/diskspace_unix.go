@@ -0,0 +1,16 @@
+//go:build unix
+
+package handler
+
+import "syscall"
+
+// freeDiskSpace returns the number of bytes available to unprivileged users on the filesystem holding dir.
+func freeDiskSpace(dir string) (uint64, error) {
+	var stat syscall.Statfs_t
+	if err := syscall.Statfs(dir, &stat); err != nil {
+		return 0, err
+	}
+
+	//nolint:gosec,unconvert // field types differ between platforms, block size is never negative
+	return uint64(stat.Bavail) * uint64(stat.Bsize), nil
+}
@@ -3,33 +3,248 @@
 package handler
 
 import (
+	"encoding/json"
+	"errors"
 	"fmt"
+	"net/url"
+	"strconv"
 	"strings"
+	"time"
 
 	"github.com/gofiber/fiber/v2"
+	"github.com/gofiber/fiber/v2/middleware/etag"
+	"github.com/schumann-it/dehydrated-api-go/internal/auth"
+	"github.com/schumann-it/dehydrated-api-go/internal/dehydrated"
 	"github.com/schumann-it/dehydrated-api-go/internal/model"
 	serviceinterface "github.com/schumann-it/dehydrated-api-go/internal/service/interface"
 )
 
 // DomainHandler handles HTTP requests for domain operations
 type DomainHandler struct {
-	service serviceinterface.DomainService
+	service        serviceinterface.DomainService
+	responseFormat string
+	metadataFormat string
+	ocspRefresh    bool
+	writerRole     bool
+	strictPlugins  bool
+	skipDisabled   bool
+	timeBudget     time.Duration
+	baseURL        string // Public URL of the server used for links, the URL of the request if empty
+
+	rejectUnknownFields bool   // Whether JSON request bodies with unknown fields are rejected, see parseBody
+	commentTemplate     string // Template of the comment of entries created without one, see WithCommentTemplate
 }
 
 // NewDomainHandler creates a new DomainHandler instance
 func NewDomainHandler(service serviceinterface.DomainService) *DomainHandler {
 	return &DomainHandler{
-		service: service,
+		service:        service,
+		responseFormat: model.ResponseFormatEnveloped,
+		metadataFormat: model.MetadataFormatNested,
+	}
+}
+
+// WithResponseFormat sets the default format of successful responses (enveloped or bare).
+// Clients can override it per request with the envelope parameter of the Accept header.
+func (h *DomainHandler) WithResponseFormat(format string) *DomainHandler {
+	if format != "" {
+		h.responseFormat = format
+	}
+	return h
+}
+
+// WithBaseURL sets the public URL of the server (e.g., "https://acme.example.com" or "https://example.com/acme"
+// if a reverse proxy serves it under a path prefix) used for pagination links instead of the URL of the request.
+func (h *DomainHandler) WithBaseURL(baseURL string) *DomainHandler {
+	h.baseURL = strings.TrimSuffix(baseURL, "/")
+	return h
+}
+
+// WithMetadataFormat sets the default format of the metadata of domain entries (nested or flat).
+// Clients can override it per request with the metadata query parameter.
+func (h *DomainHandler) WithMetadataFormat(format string) *DomainHandler {
+	if format != "" {
+		h.metadataFormat = format
+	}
+	return h
+}
+
+// WithOCSPRefresh enables the endpoint to refresh the OCSP response of a domain by running dehydrated.
+func (h *DomainHandler) WithOCSPRefresh(enabled bool) *DomainHandler {
+	h.ocspRefresh = enabled
+	return h
+}
+
+// WithWriterRole makes the routes creating, updating, renaming and deleting single domain entries require
+// the writer role. By default, only imports, bulk operations, normalization and the OCSP refresh require it.
+func (h *DomainHandler) WithWriterRole(required bool) *DomainHandler {
+	h.writerRole = required
+	return h
+}
+
+// WithStrictPlugins makes GET requests for domains fail with 502 Bad Gateway if any plugin fails to
+// provide metadata, instead of embedding the error. Clients can override it per request with the strict parameter.
+func (h *DomainHandler) WithStrictPlugins(strict bool) *DomainHandler {
+	h.strictPlugins = strict
+	return h
+}
+
+// WithSkipDisabledMetadata makes GET requests for domains skip the metadata enrichment by plugins for disabled
+// entries. Clients can override it per request with the enrich_disabled parameter.
+func (h *DomainHandler) WithSkipDisabledMetadata(skip bool) *DomainHandler {
+	h.skipDisabled = skip
+	return h
+}
+
+// WithTimeBudget sets the default time budget of listing domains. Once exceeded, no more plugins are called and
+// the entries are returned with partial metadata. Clients can shorten it per request with the X-Timeout header.
+// Disabled if zero.
+func (h *DomainHandler) WithTimeBudget(budget time.Duration) *DomainHandler {
+	h.timeBudget = budget
+	return h
+}
+
+// WithRejectUnknownFields makes requests creating, previewing, updating, renaming or deleting domain entries
+// fail with 400 Bad Request if their JSON body has unknown fields, e.g., a misspelled "domian". By default,
+// unknown fields are ignored.
+func (h *DomainHandler) WithRejectUnknownFields(reject bool) *DomainHandler {
+	h.rejectUnknownFields = reject
+	return h
+}
+
+// deadlineOptions returns the query options for the time budget of the request, the X-Timeout header
+// (a duration like "2s") or the configured default. The header is capped at the configured time budget,
+// so clients cannot lift the bound of the server.
+func (h *DomainHandler) deadlineOptions(c *fiber.Ctx) ([]serviceinterface.QueryOption, error) {
+	budget := h.timeBudget
+	if header := c.Get("X-Timeout"); header != "" {
+		timeout, err := time.ParseDuration(header)
+		if err != nil || timeout <= 0 {
+			return nil, fmt.Errorf("invalid X-Timeout: %s", header)
+		}
+		if budget == 0 || timeout < budget {
+			budget = timeout
+		}
+	}
+
+	if budget > 0 {
+		return []serviceinterface.QueryOption{serviceinterface.WithDeadline(time.Now().Add(budget))}, nil
+	}
+	return nil, nil
+}
+
+// strictOptions returns the query options for the strict parameter, defaulting to the configured mode.
+func (h *DomainHandler) strictOptions(c *fiber.Ctx) ([]serviceinterface.QueryOption, error) {
+	strict := h.strictPlugins
+	if param := c.Query("strict"); param != "" {
+		var err error
+		if strict, err = strconv.ParseBool(param); err != nil {
+			return nil, fmt.Errorf("invalid strict: %s", param)
+		}
 	}
+
+	if strict {
+		return []serviceinterface.QueryOption{serviceinterface.WithStrictPlugins()}, nil
+	}
+	return nil, nil
+}
+
+// disabledMetadataOptions returns the query options for the enrich_disabled parameter, defaulting to the configured mode.
+func (h *DomainHandler) disabledMetadataOptions(c *fiber.Ctx) ([]serviceinterface.QueryOption, error) {
+	enrich := !h.skipDisabled
+	if param := c.Query("enrich_disabled"); param != "" {
+		var err error
+		if enrich, err = strconv.ParseBool(param); err != nil {
+			return nil, fmt.Errorf("invalid enrich_disabled: %s", param)
+		}
+	}
+
+	if !enrich {
+		return []serviceinterface.QueryOption{serviceinterface.WithoutDisabledMetadata()}, nil
+	}
+	return nil, nil
 }
 
-// RegisterRoutes registers all domain-related routes
+// invalidField returns the field of a FieldError in err, if any.
+func invalidField(err error) string {
+	var fieldErr *serviceinterface.FieldError
+	if errors.As(err, &fieldErr) {
+		return fieldErr.Field
+	}
+	return ""
+}
+
+// pluginErrors returns the plugin errors of a PluginFailureError in err, if any.
+func pluginErrors(err error) []*model.PluginError {
+	var failure *serviceinterface.PluginFailureError
+	if errors.As(err, &failure) {
+		return failure.Errors
+	}
+	return nil
+}
+
+// RegisterRoutes registers all domain-related routes.
+// GET routes also answer HEAD requests and carry an ETag computed from the response body.
 func (h *DomainHandler) RegisterRoutes(app fiber.Router) {
-	app.Get("domains", h.ListDomains)
-	app.Get("domains/:domain", h.GetDomain)
-	app.Post("domains", h.CreateDomain)
-	app.Put("domains/:domain", h.UpdateDomain)
-	app.Delete("domains/:domain", h.DeleteDomain)
+	entryWriter := func(c *fiber.Ctx) error { return c.Next() }
+	if h.writerRole {
+		entryWriter = auth.RequireRole(auth.RoleWriter)
+	}
+
+	app.Get("domains", etag.New(), h.ListDomains)
+	app.Get("domains/export", h.ExportDomains)
+	app.Get("domains/:domain", etag.New(), h.GetDomain)
+	app.Post("domains", entryWriter, h.CreateDomain)
+	app.Post("domains/import", auth.RequireRole(auth.RoleWriter), h.ImportDomains)
+	app.Post("domains/preview", h.PreviewDomain)
+	app.Post("domains/bulk-enable", auth.RequireRole(auth.RoleWriter), h.BulkEnable)
+	app.Post("domains/bulk-disable", auth.RequireRole(auth.RoleWriter), h.BulkDisable)
+	app.Post("domains/bulk-delete", auth.RequireRole(auth.RoleWriter), h.BulkDelete)
+	app.Post("domains/normalize", auth.RequireRole(auth.RoleWriter), h.NormalizeDomains)
+	app.Put("domains/:domain", entryWriter, h.UpdateDomain)
+	app.Delete("domains/:domain", entryWriter, h.DeleteDomain)
+	app.Put("domains/:domain/rename", entryWriter, h.RenameDomain)
+	app.Get("domains/:domain/aliases/:alias", etag.New(), h.GetDomainAlias)
+	app.Put("domains/:domain/aliases/:alias", entryWriter, h.UpdateDomainAlias)
+	app.Delete("domains/:domain/aliases/:alias", entryWriter, h.DeleteDomainAlias)
+	app.Get("domains/:domain/effective-config", etag.New(), h.EffectiveConfig)
+	app.Get("domains/:domain/raw", etag.New(), h.RawDomainLine)
+	app.Get("domains/:domain/key/fingerprint", h.KeyFingerprint)
+	app.Get("summary", etag.New(), h.Summary)
+	app.Get("reconcile", h.Reconcile)
+	app.Get("plugins", h.ListPlugins)
+	app.Get("plugins/errors", h.PluginErrors)
+	if h.ocspRefresh {
+		app.Post("domains/:domain/ocsp/refresh", auth.RequireRole(auth.RoleWriter), h.RefreshOCSP)
+		app.Options("domains/:domain/ocsp/refresh", allowMethods(fiber.MethodPost, fiber.MethodOptions))
+	}
+	app.Options("domains", allowMethods(fiber.MethodGet, fiber.MethodHead, fiber.MethodPost, fiber.MethodOptions))
+	app.Options("domains/export", allowMethods(fiber.MethodGet, fiber.MethodHead, fiber.MethodOptions))
+	app.Options("domains/import", allowMethods(fiber.MethodPost, fiber.MethodOptions))
+	app.Options("domains/preview", allowMethods(fiber.MethodPost, fiber.MethodOptions))
+	app.Options("domains/bulk-enable", allowMethods(fiber.MethodPost, fiber.MethodOptions))
+	app.Options("domains/bulk-disable", allowMethods(fiber.MethodPost, fiber.MethodOptions))
+	app.Options("domains/bulk-delete", allowMethods(fiber.MethodPost, fiber.MethodOptions))
+	app.Options("domains/normalize", allowMethods(fiber.MethodPost, fiber.MethodOptions))
+	app.Options("domains/:domain", allowMethods(fiber.MethodGet, fiber.MethodHead, fiber.MethodPut, fiber.MethodDelete, fiber.MethodOptions))
+	app.Options("domains/:domain/rename", allowMethods(fiber.MethodPut, fiber.MethodOptions))
+	app.Options("domains/:domain/effective-config", allowMethods(fiber.MethodGet, fiber.MethodHead, fiber.MethodOptions))
+	app.Options("domains/:domain/raw", allowMethods(fiber.MethodGet, fiber.MethodHead, fiber.MethodOptions))
+	app.Options("domains/:domain/key/fingerprint", allowMethods(fiber.MethodGet, fiber.MethodHead, fiber.MethodOptions))
+	app.Options("summary", allowMethods(fiber.MethodGet, fiber.MethodHead, fiber.MethodOptions))
+	app.Options("reconcile", allowMethods(fiber.MethodGet, fiber.MethodHead, fiber.MethodOptions))
+	app.Options("plugins", allowMethods(fiber.MethodGet, fiber.MethodHead, fiber.MethodOptions))
+	app.Options("plugins/errors", allowMethods(fiber.MethodGet, fiber.MethodHead, fiber.MethodOptions))
+	app.Options("domains/:domain/aliases/:alias", allowMethods(fiber.MethodGet, fiber.MethodHead, fiber.MethodPut, fiber.MethodDelete, fiber.MethodOptions))
+}
+
+// allowMethods returns a handler answering OPTIONS requests with an Allow header listing the given methods
+func allowMethods(methods ...string) fiber.Handler {
+	allow := strings.Join(methods, ", ")
+	return func(c *fiber.Ctx) error {
+		c.Set(fiber.HeaderAllow, allow)
+		return c.SendStatus(fiber.StatusNoContent)
+	}
 }
 
 // @Summary List all domains
@@ -40,118 +255,178 @@ func (h *DomainHandler) RegisterRoutes(app fiber.Router) {
 // @Security BearerAuth
 // @Param page query int false "Page number (1-based, defaults to 1)" minimum(1)
 // @Param per_page query int false "Number of items per page (defaults to 100, max 1000)" minimum(1) maximum(1000)
-// @Param sort query string false "Sort order for domain field (asc or desc, optional - defaults to alphabetical order)" Enums(asc, desc)
+// @Param sort query string false "Comma-separated sort fields (domain, alias, enabled, comment), each prefixed by '-' for descending order, e.g., '-enabled,domain'; 'asc' and 'desc' sort by domain (optional - defaults to the order of domains.txt)"
 // @Param search query string false "Search term to filter domains by domain field (case-insensitive contains)"
+// @Param fields query string false "Comma-separated list of fields to include (domain, alternative_names, alias, enabled, comment, metadata)"
+// @Param enabled query bool false "Filter domains by enabled state"
+// @Param cert_status query string false "Filter domains by certificate status" Enums(valid, expiring, expired, missing)
+// @Param expiry_days query int false "Threshold in days within which certificates are considered expiring (defaults to 14)" minimum(0)
+// @Param strict query bool false "Fail with 502 if any plugin fails to provide metadata (defaults to the server configuration)"
+// @Param enrich_disabled query bool false "Enrich disabled entries with metadata from plugins (defaults to the server configuration, skipDisabledMetadata)"
+// @Param metadata query string false "Format of the metadata: nested by plugin, or flat with dot-joined keys like plugin.key (defaults to the server configuration)" Enums(nested, flat)
+// @Param Accept header string false "Set 'application/json; envelope=false' to receive a plain array with pagination in X-* headers"
+// @Param X-Timeout header string false "Time budget of the request, e.g., '2s' (defaults to and is capped at the server configuration); once exceeded, the entries are returned with partial metadata"
 // @Success 200 {object} model.PaginatedDomainsResponse
-// @Failure 400 {object} model.PaginatedDomainsResponse "Bad Request - Invalid pagination parameters"
+// @Failure 400 {object} model.PaginatedDomainsResponse "Bad Request - Invalid pagination or filter parameters"
 // @Failure 401 {object} model.PaginatedDomainsResponse "Unauthorized - Invalid or missing authentication token"
 // @Failure 500 {object} model.PaginatedDomainsResponse "Internal Server Error"
+// @Failure 502 {object} model.PaginatedDomainsResponse "Bad Gateway - A plugin failed in strict mode"
+// @Failure 503 {object} model.PaginatedDomainsResponse "Service Unavailable - domains file cannot be read"
+// @Header 200 {string} ETag "Entity tag of the response body"
+// @Header 200 {string} X-Timed-Out "Set to true for bare responses with partial metadata because the time budget was exceeded"
+// @Header 200 {string} Link "RFC 5988 links to the next, prev, first and last page"
+// @Header 200 {integer} X-Change-Seq "Change sequence of the domain entries, increasing on every change"
 // @Router /api/v1/domains [get]
-// ListDomains handles GET /api/v1/domains
+// @Router /api/v1/domains [head]
+// ListDomains handles GET and HEAD /api/v1/domains
 func (h *DomainHandler) ListDomains(c *fiber.Ctx) error {
 	// Parse and validate pagination parameters
-	page := c.QueryInt("page", 1)
-	perPage := c.QueryInt("per_page", model.DefaultPerPage)
+	page, perPage, err := parsePagination(c)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(model.PaginatedDomainsResponse{
+			Success: false,
+			Error:   err.Error(),
+			Code:    errorCode(err, fiber.StatusBadRequest),
+		})
+	}
 
 	// Parse sort and search parameters
 	sortOrder := c.Query("sort", "")
 	search := c.Query("search", "")
 
-	// Validate page parameter
-	if page < model.MinPage {
+	// Validate sort parameter (only if provided)
+	if _, err := model.ParseSort(sortOrder); err != nil {
 		return c.Status(fiber.StatusBadRequest).JSON(model.PaginatedDomainsResponse{
 			Success: false,
-			Error:   "page parameter must be at least 1",
+			Error:   err.Error(),
+			Code:    errorCode(err, fiber.StatusBadRequest),
 		})
 	}
 
-	// Validate and cap per_page parameter
-	if perPage < model.MinPerPage {
-		perPage = model.MinPerPage
-	} else if perPage > model.MaxPerPage {
-		perPage = model.MaxPerPage
+	fields, err := parseFields(c)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(model.PaginatedDomainsResponse{
+			Success: false,
+			Error:   err.Error(),
+			Code:    errorCode(err, fiber.StatusBadRequest),
+		})
 	}
 
-	// Validate sort parameter (only if provided)
-	if sortOrder != "" && sortOrder != "asc" && sortOrder != "desc" {
+	filters, err := listFilterOptions(c)
+	if err != nil {
 		return c.Status(fiber.StatusBadRequest).JSON(model.PaginatedDomainsResponse{
 			Success: false,
-			Error:   "sort parameter must be either 'asc' or 'desc'",
+			Error:   err.Error(),
+			Code:    errorCode(err, fiber.StatusBadRequest),
 		})
 	}
 
-	// Get paginated domains from service
-	entries, pagination, err := h.service.ListDomains(page, perPage, sortOrder, search)
+	strict, err := h.strictOptions(c)
 	if err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(model.PaginatedDomainsResponse{
+		return c.Status(fiber.StatusBadRequest).JSON(model.PaginatedDomainsResponse{
 			Success: false,
 			Error:   err.Error(),
+			Code:    errorCode(err, fiber.StatusBadRequest),
 		})
 	}
+	filters = append(filters, strict...)
 
-	// Generate pagination URLs
-	if pagination != nil {
-		h.generatePaginationURLs(c, pagination)
+	disabled, err := h.disabledMetadataOptions(c)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(model.PaginatedDomainsResponse{
+			Success: false,
+			Error:   err.Error(),
+			Code:    errorCode(err, fiber.StatusBadRequest),
+		})
 	}
+	filters = append(filters, disabled...)
 
-	return c.JSON(model.PaginatedDomainsResponse{
-		Success:    true,
-		Data:       entries,
-		Pagination: pagination,
-	})
-}
+	deadline, err := h.deadlineOptions(c)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(model.PaginatedDomainsResponse{
+			Success: false,
+			Error:   err.Error(),
+			Code:    errorCode(err, fiber.StatusBadRequest),
+		})
+	}
+	filters = append(filters, deadline...)
 
-// generatePaginationURLs generates the next and previous URLs for pagination
-func (h *DomainHandler) generatePaginationURLs(c *fiber.Ctx, pagination *model.PaginationInfo) {
-	baseURL := c.BaseURL() + c.Path()
+	flat, err := wantsFlatMetadata(c, h.metadataFormat)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(model.PaginatedDomainsResponse{
+			Success: false,
+			Error:   err.Error(),
+			Code:    errorCode(err, fiber.StatusBadRequest),
+		})
+	}
 
-	// Build query parameters
-	queryParams := make(map[string]string)
+	h.setChangeSeq(c)
 
-	// Add existing query parameters (except pagination ones)
-	c.Context().QueryArgs().VisitAll(func(key, value []byte) {
-		keyStr := string(key)
-		if keyStr != "page" && keyStr != "per_page" {
-			queryParams[keyStr] = string(value)
+	// Get paginated domains from service
+	entries, pagination, err := h.service.ListDomains(page, perPage, sortOrder, search, append(fieldsQueryOptions(fields), filters...)...)
+	timedOut := errors.Is(err, serviceinterface.ErrTimeBudgetExceeded)
+	if err != nil && !timedOut {
+		status := fiber.StatusInternalServerError
+		switch {
+		case errors.Is(err, serviceinterface.ErrPluginFailed):
+			status = fiber.StatusBadGateway
+		case errors.Is(err, serviceinterface.ErrCacheUnavailable):
+			status = fiber.StatusServiceUnavailable
 		}
-	})
-
-	// Always include per_page in URLs
-	queryParams["per_page"] = fmt.Sprintf("%d", pagination.PerPage)
+		return c.Status(status).JSON(model.PaginatedDomainsResponse{
+			Success:      false,
+			Error:        err.Error(),
+			Code:         errorCode(err, status),
+			PluginErrors: pluginErrors(err),
+		})
+	}
 
-	// Generate next URL
-	if pagination.HasNext {
-		nextParams := make(map[string]string)
-		for k, v := range queryParams {
-			nextParams[k] = v
+	if fields != nil || flat {
+		selected := make([]*model.DomainEntry, len(entries))
+		for i, entry := range entries {
+			selected[i] = entry.Select(fields)
+			if flat {
+				selected[i] = selected[i].WithFlatMetadata()
+			}
 		}
-		nextParams["page"] = fmt.Sprintf("%d", pagination.CurrentPage+1)
-		pagination.NextURL = h.buildURL(baseURL, nextParams)
+		entries = selected
 	}
 
-	// Generate previous URL
-	if pagination.HasPrev {
-		prevParams := make(map[string]string)
-		for k, v := range queryParams {
-			prevParams[k] = v
+	h.setPagination(c, pagination)
+
+	if wantsBare(c, h.responseFormat) {
+		if entries == nil {
+			entries = model.DomainEntries{}
+		}
+		if timedOut {
+			c.Set("X-Timed-Out", "true")
 		}
-		prevParams["page"] = fmt.Sprintf("%d", pagination.CurrentPage-1)
-		pagination.PrevURL = h.buildURL(baseURL, prevParams)
+		return c.JSON(entries)
 	}
+
+	return c.JSON(model.PaginatedDomainsResponse{
+		Success:    true,
+		Data:       entries,
+		Pagination: pagination,
+		Partial:    timedOut,
+		TimedOut:   timedOut,
+	})
 }
 
-// buildURL constructs a URL with query parameters
-func (h *DomainHandler) buildURL(baseURL string, params map[string]string) string {
-	if len(params) == 0 {
-		return baseURL
+// explainOptions returns the query options for the explain parameter.
+func explainOptions(c *fiber.Ctx) ([]serviceinterface.QueryOption, error) {
+	param := c.Query("explain")
+	if param == "" {
+		return nil, nil
 	}
-
-	var queryParts []string
-	for key, value := range params {
-		queryParts = append(queryParts, fmt.Sprintf("%s=%s", key, value))
+	explain, err := strconv.ParseBool(param)
+	if err != nil {
+		return nil, fmt.Errorf("invalid explain: %s", param)
 	}
-
-	return baseURL + "?" + strings.Join(queryParts, "&")
+	if explain {
+		return []serviceinterface.QueryOption{serviceinterface.WithExplain()}, nil
+	}
+	return nil, nil
 }
 
 // @Summary Get a domain
@@ -161,113 +436,619 @@ func (h *DomainHandler) buildURL(baseURL string, params map[string]string) strin
 // @Security BearerAuth
 // @Param domain path string true "Domain name"
 // @Param alias query string false "Optional alias to uniquely identify the domain entry"
+// @Param fields query string false "Comma-separated list of fields to include (domain, alternative_names, alias, enabled, comment, metadata)"
+// @Param strict query bool false "Fail with 502 if any plugin fails to provide metadata (defaults to the server configuration)"
+// @Param enrich_disabled query bool false "Enrich disabled entries with metadata from plugins (defaults to the server configuration, skipDisabledMetadata)"
+// @Param metadata query string false "Format of the metadata: nested by plugin, or flat with dot-joined keys like plugin.key (defaults to the server configuration)" Enums(nested, flat)
+// @Param explain query bool false "Include the plugin that produced each top-level metadata value and the time it took as metadata_provenance"
 // @Success 200 {object} model.DomainResponse
 // @Failure 400 {object} model.DomainResponse "Bad Request - Invalid domain parameter"
 // @Failure 401 {object} model.DomainResponse "Unauthorized - Invalid or missing authentication token"
 // @Failure 404 {object} model.DomainResponse "Not Found - Domain not found"
+// @Failure 502 {object} model.DomainResponse "Bad Gateway - A plugin failed in strict mode"
+// @Failure 503 {object} model.DomainResponse "Service Unavailable - domains file cannot be read"
+// @Header 200 {string} ETag "Entity tag of the response body"
+// @Header 200 {integer} X-Change-Seq "Change sequence of the domain entries, increasing on every change"
 // @Router /api/v1/domains/{domain} [get]
-// GetDomain handles GET /api/v1/domains/:domain
+// @Router /api/v1/domains/{domain} [head]
+// GetDomain handles GET and HEAD /api/v1/domains/:domain
 func (h *DomainHandler) GetDomain(c *fiber.Ctx) error {
+	return h.getDomain(c, c.Query("alias"))
+}
+
+// @Summary Get an aliased domain
+// @Description Get details of the domain entry with the given alias
+// @Tags domains
+// @Produce json
+// @Security BearerAuth
+// @Param domain path string true "Domain name"
+// @Param alias path string true "Alias of the domain entry"
+// @Param fields query string false "Comma-separated list of fields to include (domain, alternative_names, alias, enabled, comment, metadata)"
+// @Param strict query bool false "Fail with 502 if any plugin fails to provide metadata (defaults to the server configuration)"
+// @Param enrich_disabled query bool false "Enrich disabled entries with metadata from plugins (defaults to the server configuration, skipDisabledMetadata)"
+// @Param metadata query string false "Format of the metadata: nested by plugin, or flat with dot-joined keys like plugin.key (defaults to the server configuration)" Enums(nested, flat)
+// @Param explain query bool false "Include the plugin that produced each top-level metadata value and the time it took as metadata_provenance"
+// @Success 200 {object} model.DomainResponse
+// @Failure 400 {object} model.DomainResponse "Bad Request - Invalid domain parameter"
+// @Failure 401 {object} model.DomainResponse "Unauthorized - Invalid or missing authentication token"
+// @Failure 404 {object} model.DomainResponse "Not Found - Domain not found"
+// @Failure 502 {object} model.DomainResponse "Bad Gateway - A plugin failed in strict mode"
+// @Failure 503 {object} model.DomainResponse "Service Unavailable - domains file cannot be read"
+// @Header 200 {string} ETag "Entity tag of the response body"
+// @Header 200 {integer} X-Change-Seq "Change sequence of the domain entries, increasing on every change"
+// @Router /api/v1/domains/{domain}/aliases/{alias} [get]
+// @Router /api/v1/domains/{domain}/aliases/{alias} [head]
+// GetDomainAlias handles GET and HEAD /api/v1/domains/:domain/aliases/:alias
+func (h *DomainHandler) GetDomainAlias(c *fiber.Ctx) error {
+	return h.getDomain(c, c.Params("alias"))
+}
+
+// setChangeSeq sets the X-Change-Seq header to the change sequence of the domain entries. It must be called before
+// the entries are read, so the header never claims a newer state than the response contains.
+func (h *DomainHandler) setChangeSeq(c *fiber.Ctx) {
+	c.Set("X-Change-Seq", strconv.FormatUint(h.service.ChangeSeq(), 10))
+}
+
+// getDomain responds with the domain entry of the domain path parameter and the given alias
+func (h *DomainHandler) getDomain(c *fiber.Ctx, alias string) error {
 	domain := c.Params("domain")
 	if domain == "" {
 		return c.Status(fiber.StatusBadRequest).JSON(model.DomainResponse{
 			Success: false,
 			Error:   "domain parameter is required",
+			Code:    model.CodeValidationFailed,
+		})
+	}
+
+	fields, err := parseFields(c)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(model.DomainResponse{
+			Success: false,
+			Error:   err.Error(),
+			Code:    errorCode(err, fiber.StatusBadRequest),
+		})
+	}
+
+	strict, err := h.strictOptions(c)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(model.DomainResponse{
+			Success: false,
+			Error:   err.Error(),
+			Code:    errorCode(err, fiber.StatusBadRequest),
+		})
+	}
+
+	disabled, err := h.disabledMetadataOptions(c)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(model.DomainResponse{
+			Success: false,
+			Error:   err.Error(),
+			Code:    errorCode(err, fiber.StatusBadRequest),
 		})
 	}
 
-	entry, err := h.service.GetDomain(domain, c.Query("alias"))
+	flat, err := wantsFlatMetadata(c, h.metadataFormat)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(model.DomainResponse{
+			Success: false,
+			Error:   err.Error(),
+			Code:    errorCode(err, fiber.StatusBadRequest),
+		})
+	}
 
+	explain, err := explainOptions(c)
 	if err != nil {
-		return c.Status(fiber.StatusNotFound).JSON(model.DomainResponse{
+		return c.Status(fiber.StatusBadRequest).JSON(model.DomainResponse{
 			Success: false,
 			Error:   err.Error(),
+			Code:    errorCode(err, fiber.StatusBadRequest),
 		})
 	}
 
+	h.setChangeSeq(c)
+	opts := append(fieldsQueryOptions(fields), strict...)
+	opts = append(opts, explain...)
+	entry, err := h.service.GetDomain(domain, alias, append(opts, disabled...)...)
+
+	if err != nil {
+		status := fiber.StatusNotFound
+		switch {
+		case errors.Is(err, serviceinterface.ErrPluginFailed):
+			status = fiber.StatusBadGateway
+		case errors.Is(err, serviceinterface.ErrCacheUnavailable):
+			status = fiber.StatusServiceUnavailable
+		}
+		return c.Status(status).JSON(model.DomainResponse{
+			Success:      false,
+			Error:        err.Error(),
+			Code:         errorCode(err, status),
+			PluginErrors: pluginErrors(err),
+		})
+	}
+
+	if fields != nil {
+		entry = entry.Select(fields)
+	}
+	if flat {
+		entry = entry.WithFlatMetadata()
+	}
+
+	if wantsBare(c, h.responseFormat) {
+		return c.JSON(entry)
+	}
+
 	return c.JSON(model.DomainResponse{
 		Success: true,
 		Data:    entry,
 	})
 }
 
+// includePosition parses the include_position parameter of mutations returning an entry.
+func includePosition(c *fiber.Ctx) (bool, error) {
+	param := c.Query("include_position")
+	if param == "" {
+		return false, nil
+	}
+	include, err := strconv.ParseBool(param)
+	if err != nil {
+		return false, fmt.Errorf("invalid include_position: %s", param)
+	}
+	return include, nil
+}
+
+// respondEntry responds with the entry returned by a mutation and the given status. If withPosition is set,
+// the position of the entry in the sorted domains file is included, or set as X-Position header for bare responses.
+func (h *DomainHandler) respondEntry(c *fiber.Ctx, status int, entry *model.DomainEntry, withPosition bool) error {
+	var position *int
+	if withPosition {
+		// The entry may have been changed concurrently meanwhile, it is returned without position then
+		if p, err := h.service.Position(entry.Domain, entry.Alias); err == nil {
+			position = &p
+		}
+	}
+
+	if wantsBare(c, h.responseFormat) {
+		if position != nil {
+			c.Set("X-Position", strconv.Itoa(*position))
+		}
+		return c.Status(status).JSON(entry)
+	}
+
+	return c.Status(status).JSON(model.DomainResponse{
+		Success:  true,
+		Data:     entry,
+		Position: position,
+	})
+}
+
 // @Summary Create a domain
-// @Description Create a new domain entry
+// @Description Create a new domain entry. Without a comment, the configured comment template applies, if any.
 // @Tags domains
 // @Accept json
 // @Produce json
 // @Security BearerAuth
 // @Param request body model.CreateDomainRequest true "Domain creation request"
+// @Param include_position query bool false "Include the zero-based position of the entry in the sorted domains.txt"
 // @Success 201 {object} model.DomainResponse
-// @Failure 400 {object} model.DomainResponse "Bad Request - Invalid request body or domain already exists"
+// @Header 201 {string} Location "URL of the created domain, with the alias as query parameter if set"
+// @Failure 400 {object} model.DomainResponse "Bad Request - Invalid request body or parameter"
 // @Failure 401 {object} model.DomainResponse "Unauthorized - Invalid or missing authentication token"
+// @Failure 403 {object} model.DomainResponse "Forbidden - Missing writer role (if required)"
+// @Failure 409 {object} model.DomainResponse "Conflict - Domain with the same alias already exists"
+// @Failure 422 {object} model.DomainResponse "Unprocessable Entity - Invalid domain entry"
+// @Failure 502 {object} model.DomainResponse "Bad Gateway - A validating plugin failed"
+// @Failure 507 {object} model.DomainResponse "Insufficient Storage - Maximum number of entries reached"
+// @Failure 503 {object} model.DomainResponse "Service Unavailable - domains file cannot be read"
 // @Router /api/v1/domains [post]
 // CreateDomain handles POST /api/v1/domains
 func (h *DomainHandler) CreateDomain(c *fiber.Ctx) error {
+	withPosition, err := includePosition(c)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(model.DomainResponse{
+			Success: false,
+			Error:   err.Error(),
+			Code:    model.CodeValidationFailed,
+		})
+	}
+
 	var req model.CreateDomainRequest
-	if err := c.BodyParser(&req); err != nil {
+	if err := h.parseBody(c, &req); err != nil {
 		return c.Status(fiber.StatusBadRequest).JSON(model.DomainResponse{
 			Success: false,
-			Error:   "invalid request body",
+			Error:   err.Error(),
+			Code:    model.CodeValidationFailed,
 		})
 	}
 
+	h.applyCommentTemplate(c, &req)
 	entry, err := h.service.CreateDomain(&req)
 	if err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(model.DomainResponse{
+		status := fiber.StatusBadRequest
+		switch {
+		case errors.Is(err, serviceinterface.ErrInvalidDomainEntry):
+			status = fiber.StatusUnprocessableEntity
+		case errors.Is(err, serviceinterface.ErrDomainExists):
+			status = fiber.StatusConflict
+		case errors.Is(err, serviceinterface.ErrPluginFailed):
+			status = fiber.StatusBadGateway
+		case errors.Is(err, serviceinterface.ErrTooManyEntries):
+			status = fiber.StatusInsufficientStorage
+		case errors.Is(err, serviceinterface.ErrCacheUnavailable):
+			status = fiber.StatusServiceUnavailable
+		}
+		return c.Status(status).JSON(model.DomainResponse{
+			Success: false,
+			Error:   err.Error(),
+			Code:    errorCode(err, status),
+			Field:   invalidField(err),
+		})
+	}
+
+	c.Location(h.entryURL(c, entry))
+	return h.respondEntry(c, fiber.StatusCreated, entry, withPosition)
+}
+
+// entryURL returns the URL to get entry from, with the alias as query parameter if set.
+// The request must be one to the domains collection, like for creating entries.
+func (h *DomainHandler) entryURL(c *fiber.Ctx, entry *model.DomainEntry) string {
+	params := url.Values{}
+	if entry.Alias != "" {
+		params.Set("alias", entry.Alias)
+	}
+	return h.buildURL(strings.TrimSuffix(h.requestURL(c), "/")+"/"+url.PathEscape(entry.Domain), params)
+}
+
+// @Summary Preview a domain
+// @Description Render the line of domains.txt a domain entry would be written as, without creating it.
+// @Description The entry is validated like on creation; the comment template and marker are applied.
+// @Tags domains
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body model.CreateDomainRequest true "Domain creation request"
+// @Success 200 {object} model.DomainPreviewResponse
+// @Failure 400 {object} model.DomainPreviewResponse "Bad Request - Invalid request body"
+// @Failure 401 {object} model.DomainPreviewResponse "Unauthorized - Invalid or missing authentication token"
+// @Failure 422 {object} model.DomainPreviewResponse "Unprocessable Entity - Invalid domain entry"
+// @Failure 502 {object} model.DomainPreviewResponse "Bad Gateway - A validating plugin failed"
+// @Failure 503 {object} model.DomainPreviewResponse "Service Unavailable - domains file cannot be read"
+// @Router /api/v1/domains/preview [post]
+// PreviewDomain handles POST /api/v1/domains/preview
+func (h *DomainHandler) PreviewDomain(c *fiber.Ctx) error {
+	var req model.CreateDomainRequest
+	if err := h.parseBody(c, &req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(model.DomainPreviewResponse{
+			Success: false,
+			Error:   err.Error(),
+			Code:    model.CodeValidationFailed,
+		})
+	}
+
+	h.applyCommentTemplate(c, &req)
+	line, err := h.service.PreviewDomain(&req)
+	if err != nil {
+		status := fiber.StatusBadRequest
+		switch {
+		case errors.Is(err, serviceinterface.ErrInvalidDomainEntry):
+			status = fiber.StatusUnprocessableEntity
+		case errors.Is(err, serviceinterface.ErrPluginFailed):
+			status = fiber.StatusBadGateway
+		case errors.Is(err, serviceinterface.ErrCacheUnavailable):
+			status = fiber.StatusServiceUnavailable
+		}
+		return c.Status(status).JSON(model.DomainPreviewResponse{
 			Success: false,
 			Error:   err.Error(),
+			Code:    errorCode(err, status),
+			Field:   invalidField(err),
 		})
 	}
 
-	return c.Status(fiber.StatusCreated).JSON(model.DomainResponse{
+	preview := &model.DomainPreview{Line: line}
+	if wantsBare(c, h.responseFormat) {
+		return c.JSON(preview)
+	}
+
+	return c.JSON(model.DomainPreviewResponse{
 		Success: true,
-		Data:    entry,
+		Data:    preview,
 	})
 }
 
 // @Summary Update a domain
-// @Description Update an existing domain entry
+// @Description Update an existing domain entry. With Content-Type application/json-patch+json the body is
+// @Description a JSON Patch (RFC 6902) of alternative_names, enabled, comment and alias; the entry is selected by the alias query parameter.
+// @Description With upsert=true a missing entry is created instead (201), atomically with the existence check.
 // @Tags domains
 // @Accept json
+// @Accept json-patch+json
 // @Produce json
 // @Security BearerAuth
 // @Param domain path string true "Domain name"
+// @Param alias query string false "Alias of the domain entry to patch (JSON Patch only)"
+// @Param upsert query bool false "Create the entry if it does not exist (not supported with JSON Patch)"
+// @Param include_position query bool false "Include the zero-based position of the entry in the sorted domains.txt"
 // @Param request body model.UpdateDomainRequest true "Domain update request"
 // @Success 200 {object} model.DomainResponse
+// @Success 201 {object} model.DomainResponse "Created - Entry did not exist (upsert only)"
 // @Failure 400 {object} model.DomainResponse "Bad Request - Invalid request body or domain parameter"
 // @Failure 401 {object} model.DomainResponse "Unauthorized - Invalid or missing authentication token"
+// @Failure 403 {object} model.DomainResponse "Forbidden - Missing writer role (if required)"
 // @Failure 404 {object} model.DomainResponse "Not Found - Domain not found"
+// @Failure 409 {object} model.DomainResponse "Conflict - Patched alias collides with another entry"
+// @Failure 422 {object} model.DomainResponse "Unprocessable Entity - Invalid domain entry or missing JSON Patch target"
+// @Failure 502 {object} model.DomainResponse "Bad Gateway - A validating plugin failed"
+// @Failure 507 {object} model.DomainResponse "Insufficient Storage - Maximum number of entries reached (upsert only)"
+// @Failure 503 {object} model.DomainResponse "Service Unavailable - domains file cannot be read"
 // @Router /api/v1/domains/{domain} [put]
 // UpdateDomain handles PUT /api/v1/domains/:domain
 func (h *DomainHandler) UpdateDomain(c *fiber.Ctx) error {
+	return h.updateDomain(c, nil)
+}
+
+// @Summary Update an aliased domain
+// @Description Update the domain entry with the given alias. Accepts JSON Patch (RFC 6902) and upsert like PUT /api/v1/domains/{domain}.
+// @Tags domains
+// @Accept json
+// @Accept json-patch+json
+// @Produce json
+// @Security BearerAuth
+// @Param domain path string true "Domain name"
+// @Param alias path string true "Alias of the domain entry"
+// @Param upsert query bool false "Create the entry if it does not exist (not supported with JSON Patch)"
+// @Param include_position query bool false "Include the zero-based position of the entry in the sorted domains.txt"
+// @Param request body model.UpdateDomainRequest true "Domain update request"
+// @Success 200 {object} model.DomainResponse
+// @Success 201 {object} model.DomainResponse "Created - Entry did not exist (upsert only)"
+// @Failure 400 {object} model.DomainResponse "Bad Request - Invalid request body or domain parameter"
+// @Failure 401 {object} model.DomainResponse "Unauthorized - Invalid or missing authentication token"
+// @Failure 403 {object} model.DomainResponse "Forbidden - Missing writer role (if required)"
+// @Failure 404 {object} model.DomainResponse "Not Found - Domain not found"
+// @Failure 409 {object} model.DomainResponse "Conflict - Patched alias collides with another entry"
+// @Failure 422 {object} model.DomainResponse "Unprocessable Entity - Invalid domain entry or missing JSON Patch target"
+// @Failure 502 {object} model.DomainResponse "Bad Gateway - A validating plugin failed"
+// @Failure 507 {object} model.DomainResponse "Insufficient Storage - Maximum number of entries reached (upsert only)"
+// @Failure 503 {object} model.DomainResponse "Service Unavailable - domains file cannot be read"
+// @Router /api/v1/domains/{domain}/aliases/{alias} [put]
+// UpdateDomainAlias handles PUT /api/v1/domains/:domain/aliases/:alias
+func (h *DomainHandler) UpdateDomainAlias(c *fiber.Ctx) error {
+	alias := c.Params("alias")
+	return h.updateDomain(c, &alias)
+}
+
+// updateDomain updates the domain entry of the domain path parameter.
+// If alias is set, it takes precedence over the alias of the request body.
+func (h *DomainHandler) updateDomain(c *fiber.Ctx, alias *string) error {
 	domain := c.Params("domain")
 	if domain == "" {
 		return c.Status(fiber.StatusBadRequest).JSON(model.DomainResponse{
 			Success: false,
 			Error:   "domain parameter is required",
+			Code:    model.CodeValidationFailed,
+		})
+	}
+
+	upsert := false
+	if param := c.Query("upsert"); param != "" {
+		var err error
+		if upsert, err = strconv.ParseBool(param); err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(model.DomainResponse{
+				Success: false,
+				Error:   "invalid upsert: " + param,
+				Code:    model.CodeValidationFailed,
+			})
+		}
+	}
+
+	withPosition, err := includePosition(c)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(model.DomainResponse{
+			Success: false,
+			Error:   err.Error(),
+			Code:    model.CodeValidationFailed,
 		})
 	}
 
+	if strings.HasPrefix(c.Get(fiber.HeaderContentType), MIMEApplicationJSONPatch) {
+		if upsert {
+			return c.Status(fiber.StatusBadRequest).JSON(model.DomainResponse{
+				Success: false,
+				Error:   "upsert is not supported with JSON Patch",
+				Code:    model.CodeValidationFailed,
+			})
+		}
+		if alias == nil {
+			a := c.Query("alias")
+			alias = &a
+		}
+		return h.patchDomain(c, domain, *alias, withPosition)
+	}
+
 	var req model.UpdateDomainRequest
-	if err := c.BodyParser(&req); err != nil {
+	if err := h.parseBody(c, &req); err != nil {
 		return c.Status(fiber.StatusBadRequest).JSON(model.DomainResponse{
 			Success: false,
-			Error:   "invalid request body",
+			Error:   err.Error(),
+			Code:    model.CodeValidationFailed,
 		})
 	}
 
-	var entry *model.DomainEntry
-	var err error
+	if alias != nil {
+		req.Alias = alias
+	}
+
+	if upsert {
+		return h.upsertDomain(c, domain, req, withPosition)
+	}
 
-	entry, err = h.service.UpdateDomain(domain, req)
+	entry, err := h.service.UpdateDomain(domain, req)
 	if err != nil {
-		return c.Status(fiber.StatusNotFound).JSON(model.DomainResponse{
+		status := fiber.StatusNotFound
+		switch {
+		case errors.Is(err, serviceinterface.ErrInvalidDomainEntry):
+			status = fiber.StatusUnprocessableEntity
+		case errors.Is(err, serviceinterface.ErrPluginFailed):
+			status = fiber.StatusBadGateway
+		case errors.Is(err, serviceinterface.ErrCacheUnavailable):
+			status = fiber.StatusServiceUnavailable
+		}
+		return c.Status(status).JSON(model.DomainResponse{
 			Success: false,
 			Error:   err.Error(),
+			Code:    errorCode(err, status),
+			Field:   invalidField(err),
 		})
 	}
 
+	return h.respondEntry(c, fiber.StatusOK, entry, withPosition)
+}
+
+// upsertDomain updates the entry identified by domain and the alias of req or creates it if it does not exist
+func (h *DomainHandler) upsertDomain(c *fiber.Ctx, domain string, req model.UpdateDomainRequest, withPosition bool) error {
+	entry, created, err := h.service.UpsertDomain(domain, req)
+	if err != nil {
+		status := fiber.StatusBadRequest
+		switch {
+		case errors.Is(err, serviceinterface.ErrInvalidDomainEntry):
+			status = fiber.StatusUnprocessableEntity
+		case errors.Is(err, serviceinterface.ErrPluginFailed):
+			status = fiber.StatusBadGateway
+		case errors.Is(err, serviceinterface.ErrTooManyEntries):
+			status = fiber.StatusInsufficientStorage
+		case errors.Is(err, serviceinterface.ErrCacheUnavailable):
+			status = fiber.StatusServiceUnavailable
+		}
+		return c.Status(status).JSON(model.DomainResponse{
+			Success: false,
+			Error:   err.Error(),
+			Code:    errorCode(err, status),
+			Field:   invalidField(err),
+		})
+	}
+
+	status := fiber.StatusOK
+	if created {
+		status = fiber.StatusCreated
+	}
+
+	return h.respondEntry(c, status, entry, withPosition)
+}
+
+// patchDomain applies the JSON Patch of the request body to the entry identified by domain and alias
+func (h *DomainHandler) patchDomain(c *fiber.Ctx, domain, alias string, withPosition bool) error {
+	var ops []PatchOperation
+	if err := json.Unmarshal(c.Body(), &ops); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(model.DomainResponse{
+			Success: false,
+			Error:   "invalid request body",
+			Code:    model.CodeValidationFailed,
+		})
+	}
+
+	// The patch is applied by the service while holding its lock, so concurrent changes are not lost
+	var patchErr error
+	entry, err := h.service.PatchDomain(domain, alias, func(entry *model.DomainEntry) error {
+		patchErr = applyPatch(entry, ops)
+		return patchErr
+	})
+	if patchErr != nil {
+		status := fiber.StatusBadRequest
+		if errors.Is(patchErr, errPatchPathNotFound) {
+			status = fiber.StatusUnprocessableEntity
+		}
+		return c.Status(status).JSON(model.DomainResponse{
+			Success: false,
+			Error:   patchErr.Error(),
+			Code:    errorCode(patchErr, fiber.StatusBadRequest),
+		})
+	}
+	if err != nil {
+		status := fiber.StatusBadRequest
+		switch {
+		case errors.Is(err, serviceinterface.ErrInvalidDomainEntry):
+			status = fiber.StatusUnprocessableEntity
+		case errors.Is(err, serviceinterface.ErrDomainNotFound):
+			status = fiber.StatusNotFound
+		case errors.Is(err, serviceinterface.ErrDomainExists):
+			status = fiber.StatusConflict
+		case errors.Is(err, serviceinterface.ErrPluginFailed):
+			status = fiber.StatusBadGateway
+		case errors.Is(err, serviceinterface.ErrCacheUnavailable):
+			status = fiber.StatusServiceUnavailable
+		}
+		return c.Status(status).JSON(model.DomainResponse{
+			Success: false,
+			Error:   err.Error(),
+			Code:    errorCode(err, status),
+			Field:   invalidField(err),
+		})
+	}
+
+	return h.respondEntry(c, fiber.StatusOK, entry, withPosition)
+}
+
+// @Summary Rename a domain
+// @Description Change the primary domain name, and optionally the alias, of a domain entry. The entry keeps its
+// @Description alternative names, enabled state and comment; its line is placed according to the sort order of domains.txt.
+// @Tags domains
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param domain path string true "Domain name"
+// @Param alias query string false "Alias of the domain entry"
+// @Param request body model.RenameDomainRequest true "Domain rename request"
+// @Success 200 {object} model.DomainResponse
+// @Failure 400 {object} model.DomainResponse "Bad Request - Invalid request body"
+// @Failure 401 {object} model.DomainResponse "Unauthorized - Invalid or missing authentication token"
+// @Failure 403 {object} model.DomainResponse "Forbidden - Missing writer role (if required)"
+// @Failure 404 {object} model.DomainResponse "Not Found - Domain not found"
+// @Failure 409 {object} model.DomainResponse "Conflict - New name collides with another entry"
+// @Failure 422 {object} model.DomainResponse "Unprocessable Entity - Invalid new domain name or alias"
+// @Failure 502 {object} model.DomainResponse "Bad Gateway - A validating plugin failed"
+// @Failure 503 {object} model.DomainResponse "Service Unavailable - domains file cannot be read"
+// @Router /api/v1/domains/{domain}/rename [put]
+// RenameDomain handles PUT /api/v1/domains/:domain/rename
+func (h *DomainHandler) RenameDomain(c *fiber.Ctx) error {
+	var req model.RenameDomainRequest
+	err := h.parseBody(c, &req)
+	if err == nil && req.Domain == "" {
+		err = errInvalidBody
+	}
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(model.DomainResponse{
+			Success: false,
+			Error:   err.Error(),
+			Code:    model.CodeValidationFailed,
+		})
+	}
+
+	entry, err := h.service.RenameDomain(c.Params("domain"), c.Query("alias"), req)
+	if err != nil {
+		status := fiber.StatusBadRequest
+		switch {
+		case errors.Is(err, serviceinterface.ErrInvalidDomainEntry):
+			status = fiber.StatusUnprocessableEntity
+		case errors.Is(err, serviceinterface.ErrDomainNotFound):
+			status = fiber.StatusNotFound
+		case errors.Is(err, serviceinterface.ErrDomainExists):
+			status = fiber.StatusConflict
+		case errors.Is(err, serviceinterface.ErrPluginFailed):
+			status = fiber.StatusBadGateway
+		case errors.Is(err, serviceinterface.ErrCacheUnavailable):
+			status = fiber.StatusServiceUnavailable
+		}
+		return c.Status(status).JSON(model.DomainResponse{
+			Success: false,
+			Error:   err.Error(),
+			Code:    errorCode(err, status),
+			Field:   invalidField(err),
+		})
+	}
+
+	if wantsBare(c, h.responseFormat) {
+		return c.JSON(entry)
+	}
+
 	return c.JSON(model.DomainResponse{
 		Success: true,
 		Data:    entry,
@@ -285,24 +1066,54 @@ func (h *DomainHandler) UpdateDomain(c *fiber.Ctx) error {
 // @Success 204 "No Content"
 // @Failure 400 {object} model.DomainResponse "Bad Request - Invalid domain parameter"
 // @Failure 401 {object} model.DomainResponse "Unauthorized - Invalid or missing authentication token"
+// @Failure 403 {object} model.DomainResponse "Forbidden - Missing writer role (if required)"
 // @Failure 404 {object} model.DomainResponse "Not Found - Domain not found"
+// @Failure 503 {object} model.DomainResponse "Service Unavailable - domains file cannot be read"
 // @Router /api/v1/domains/{domain} [delete]
 // DeleteDomain handles DELETE /api/v1/domains/:domain
 func (h *DomainHandler) DeleteDomain(c *fiber.Ctx) error {
+	return h.deleteDomain(c, nil)
+}
+
+// @Summary Delete an aliased domain
+// @Description Delete the domain entry with the given alias
+// @Tags domains
+// @Produce json
+// @Security BearerAuth
+// @Param domain path string true "Domain name"
+// @Param alias path string true "Alias of the domain entry"
+// @Success 204 "No Content"
+// @Failure 400 {object} model.DomainResponse "Bad Request - Invalid domain parameter"
+// @Failure 401 {object} model.DomainResponse "Unauthorized - Invalid or missing authentication token"
+// @Failure 403 {object} model.DomainResponse "Forbidden - Missing writer role (if required)"
+// @Failure 404 {object} model.DomainResponse "Not Found - Domain not found"
+// @Failure 503 {object} model.DomainResponse "Service Unavailable - domains file cannot be read"
+// @Router /api/v1/domains/{domain}/aliases/{alias} [delete]
+// DeleteDomainAlias handles DELETE /api/v1/domains/:domain/aliases/:alias
+func (h *DomainHandler) DeleteDomainAlias(c *fiber.Ctx) error {
+	alias := c.Params("alias")
+	return h.deleteDomain(c, &alias)
+}
+
+// deleteDomain deletes the domain entry of the domain path parameter.
+// If alias is set, it takes precedence over the alias of the request body.
+func (h *DomainHandler) deleteDomain(c *fiber.Ctx, alias *string) error {
 	domain := c.Params("domain")
 	if domain == "" {
 		return c.Status(fiber.StatusBadRequest).JSON(model.DomainResponse{
 			Success: false,
 			Error:   "domain parameter is required",
+			Code:    model.CodeValidationFailed,
 		})
 	}
 
 	var req model.DeleteDomainRequest
 	if len(c.Body()) > 0 {
-		if err := c.BodyParser(&req); err != nil {
+		if err := h.parseBody(c, &req); err != nil {
 			return c.Status(fiber.StatusBadRequest).JSON(model.DomainResponse{
 				Success: false,
-				Error:   "invalid request body",
+				Error:   err.Error(),
+				Code:    model.CodeValidationFailed,
 			})
 		}
 	} else {
@@ -310,13 +1121,606 @@ func (h *DomainHandler) DeleteDomain(c *fiber.Ctx) error {
 		req = model.DeleteDomainRequest{}
 	}
 
+	if alias != nil {
+		req.Alias = alias
+	}
+
 	err := h.service.DeleteDomain(domain, req)
 	if err != nil {
-		return c.Status(fiber.StatusNotFound).JSON(model.DomainResponse{
+		status := fiber.StatusNotFound
+		if errors.Is(err, serviceinterface.ErrCacheUnavailable) {
+			status = fiber.StatusServiceUnavailable
+		}
+		return c.Status(status).JSON(model.DomainResponse{
 			Success: false,
 			Error:   err.Error(),
+			Code:    errorCode(err, status),
 		})
 	}
 
 	return c.SendStatus(fiber.StatusNoContent)
 }
+
+// @Summary Enable domains by filter
+// @Description Enable all domain entries matching the filter with a single write to domains.txt
+// @Tags domains
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body model.BulkUpdateRequest true "Bulk update filter"
+// @Success 200 {object} model.BulkUpdateResponse
+// @Failure 400 {object} model.BulkUpdateResponse "Bad Request - Invalid request body"
+// @Failure 401 {object} model.BulkUpdateResponse "Unauthorized - Invalid or missing authentication token"
+// @Failure 403 {object} model.BulkUpdateResponse "Forbidden - Missing writer role"
+// @Failure 500 {object} model.BulkUpdateResponse "Internal Server Error"
+// @Failure 503 {object} model.BulkUpdateResponse "Service Unavailable - domains file cannot be read"
+// @Router /api/v1/domains/bulk-enable [post]
+// BulkEnable handles POST /api/v1/domains/bulk-enable
+func (h *DomainHandler) BulkEnable(c *fiber.Ctx) error {
+	return h.setEnabled(c, true)
+}
+
+// @Summary Disable domains by filter
+// @Description Disable all domain entries matching the filter with a single write to domains.txt
+// @Tags domains
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body model.BulkUpdateRequest true "Bulk update filter"
+// @Success 200 {object} model.BulkUpdateResponse
+// @Failure 400 {object} model.BulkUpdateResponse "Bad Request - Invalid request body"
+// @Failure 401 {object} model.BulkUpdateResponse "Unauthorized - Invalid or missing authentication token"
+// @Failure 403 {object} model.BulkUpdateResponse "Forbidden - Missing writer role"
+// @Failure 500 {object} model.BulkUpdateResponse "Internal Server Error"
+// @Failure 503 {object} model.BulkUpdateResponse "Service Unavailable - domains file cannot be read"
+// @Router /api/v1/domains/bulk-disable [post]
+// BulkDisable handles POST /api/v1/domains/bulk-disable
+func (h *DomainHandler) BulkDisable(c *fiber.Ctx) error {
+	return h.setEnabled(c, false)
+}
+
+// setEnabled applies the enabled state to all domain entries matching the filter of the request body
+func (h *DomainHandler) setEnabled(c *fiber.Ctx, enabled bool) error {
+	var req model.BulkUpdateRequest
+	if len(c.Body()) > 0 {
+		if err := c.BodyParser(&req); err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(model.BulkUpdateResponse{
+				Success: false,
+				Error:   "invalid request body",
+				Code:    model.CodeValidationFailed,
+			})
+		}
+	}
+
+	count, err := h.service.SetEnabled(req.Search, req.Enabled, enabled)
+	if err != nil {
+		status := fiber.StatusInternalServerError
+		if errors.Is(err, serviceinterface.ErrCacheUnavailable) {
+			status = fiber.StatusServiceUnavailable
+		}
+		return c.Status(status).JSON(model.BulkUpdateResponse{
+			Success: false,
+			Error:   err.Error(),
+			Code:    errorCode(err, status),
+		})
+	}
+
+	return c.JSON(model.BulkUpdateResponse{
+		Success: true,
+		Count:   count,
+	})
+}
+
+// @Summary Delete domains by filter
+// @Description Delete all domain entries matching the filter with a single write to domains.txt.
+// @Description confirm must be true; an empty filter deletes all entries.
+// @Tags domains
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body model.BulkDeleteRequest true "Bulk delete filter"
+// @Success 200 {object} model.BulkDeleteResponse
+// @Failure 400 {object} model.BulkDeleteResponse "Bad Request - Invalid request body or missing confirmation"
+// @Failure 401 {object} model.BulkDeleteResponse "Unauthorized - Invalid or missing authentication token"
+// @Failure 403 {object} model.BulkDeleteResponse "Forbidden - Missing writer role"
+// @Failure 500 {object} model.BulkDeleteResponse "Internal Server Error"
+// @Failure 503 {object} model.BulkDeleteResponse "Service Unavailable - domains file cannot be read"
+// @Router /api/v1/domains/bulk-delete [post]
+// BulkDelete handles POST /api/v1/domains/bulk-delete
+func (h *DomainHandler) BulkDelete(c *fiber.Ctx) error {
+	var req model.BulkDeleteRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(model.BulkDeleteResponse{
+			Success: false,
+			Error:   "invalid request body",
+			Code:    model.CodeValidationFailed,
+		})
+	}
+
+	if !req.Confirm {
+		return c.Status(fiber.StatusBadRequest).JSON(model.BulkDeleteResponse{
+			Success: false,
+			Error:   "confirm must be true to delete entries",
+			Code:    model.CodeValidationFailed,
+		})
+	}
+
+	deleted, err := h.service.DeleteDomains(req.Search, req.Enabled)
+	if err != nil {
+		status := fiber.StatusInternalServerError
+		if errors.Is(err, serviceinterface.ErrCacheUnavailable) {
+			status = fiber.StatusServiceUnavailable
+		}
+		return c.Status(status).JSON(model.BulkDeleteResponse{
+			Success: false,
+			Error:   err.Error(),
+			Code:    errorCode(err, status),
+		})
+	}
+
+	return c.JSON(model.BulkDeleteResponse{
+		Success: true,
+		Count:   len(deleted),
+		Data:    deleted,
+	})
+}
+
+// @Summary Normalize domains.txt
+// @Description Rewrite domains.txt in canonical form: names are lowercased, duplicate alternative names and entries
+// @Description are removed and the entries are sorted and formatted. Comment lines, invalid lines and options are removed.
+// @Description The file is read again, so changes made by an editor are included. With dry_run, the file is left unchanged.
+// @Description Returns the lines changed or removed by the normalization.
+// @Tags domains
+// @Produce json
+// @Security BearerAuth
+// @Param dry_run query bool false "Report the changes without rewriting the file"
+// @Success 200 {object} model.NormalizeResponse
+// @Failure 400 {object} model.NormalizeResponse "Bad Request - Invalid dry_run"
+// @Failure 401 {object} model.NormalizeResponse "Unauthorized - Invalid or missing authentication token"
+// @Failure 403 {object} model.NormalizeResponse "Forbidden - Missing writer role"
+// @Failure 500 {object} model.NormalizeResponse "Internal Server Error"
+// @Router /api/v1/domains/normalize [post]
+// NormalizeDomains handles POST /api/v1/domains/normalize
+func (h *DomainHandler) NormalizeDomains(c *fiber.Ctx) error {
+	dryRun := false
+	if param := c.Query("dry_run"); param != "" {
+		var err error
+		if dryRun, err = strconv.ParseBool(param); err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(model.NormalizeResponse{
+				Success: false,
+				Error:   fmt.Sprintf("invalid dry_run: %s", param),
+				Code:    model.CodeValidationFailed,
+			})
+		}
+	}
+
+	result, err := h.service.NormalizeDomains(dryRun)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(model.NormalizeResponse{
+			Success: false,
+			Error:   err.Error(),
+			Code:    errorCode(err, fiber.StatusInternalServerError),
+		})
+	}
+
+	return c.JSON(model.NormalizeResponse{
+		Success: true,
+		Data:    result,
+	})
+}
+
+// DefaultExpiryDays is the default threshold in days within which certificates are considered expiring.
+const DefaultExpiryDays = 14
+
+// @Summary Get status summary
+// @Description Summarize all domain entries and the status of their certificates, e.g., for a dashboard
+// @Tags domains
+// @Produce json
+// @Security BearerAuth
+// @Param expiry_days query int false "Threshold in days within which certificates are considered expiring (defaults to 14)" minimum(0)
+// @Success 200 {object} model.SummaryResponse
+// @Failure 400 {object} model.SummaryResponse "Bad Request - Invalid expiry_days"
+// @Failure 401 {object} model.SummaryResponse "Unauthorized - Invalid or missing authentication token"
+// @Failure 500 {object} model.SummaryResponse "Internal Server Error"
+// @Failure 503 {object} model.SummaryResponse "Service Unavailable - domains file cannot be read"
+// @Header 200 {string} ETag "Entity tag of the response body"
+// @Router /api/v1/summary [get]
+// Summary handles GET /api/v1/summary
+func (h *DomainHandler) Summary(c *fiber.Ctx) error {
+	days, err := expiryDays(c)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(model.SummaryResponse{
+			Success: false,
+			Error:   err.Error(),
+			Code:    errorCode(err, fiber.StatusBadRequest),
+		})
+	}
+
+	summary, err := h.service.Summary(time.Duration(days) * 24 * time.Hour)
+	if err != nil {
+		status := fiber.StatusInternalServerError
+		if errors.Is(err, serviceinterface.ErrCacheUnavailable) {
+			status = fiber.StatusServiceUnavailable
+		}
+		return c.Status(status).JSON(model.SummaryResponse{
+			Success: false,
+			Error:   err.Error(),
+			Code:    errorCode(err, status),
+		})
+	}
+
+	return c.JSON(model.SummaryResponse{
+		Success: true,
+		Data:    summary,
+	})
+}
+
+// @Summary Reconcile domains and certificates
+// @Description Detect drift between the domain entries and the certificate directories below CertDir: enabled entries
+// @Description without an issued certificate and certificate directories no entry refers to
+// @Tags domains
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} model.ReconciliationResponse
+// @Failure 401 {object} model.ReconciliationResponse "Unauthorized - Invalid or missing authentication token"
+// @Failure 500 {object} model.ReconciliationResponse "Internal Server Error"
+// @Failure 503 {object} model.ReconciliationResponse "Service Unavailable - domains file cannot be read"
+// @Router /api/v1/reconcile [get]
+// Reconcile handles GET /api/v1/reconcile
+func (h *DomainHandler) Reconcile(c *fiber.Ctx) error {
+	result, err := h.service.Reconcile()
+	if err != nil {
+		status := fiber.StatusInternalServerError
+		if errors.Is(err, serviceinterface.ErrCacheUnavailable) {
+			status = fiber.StatusServiceUnavailable
+		}
+		return c.Status(status).JSON(model.ReconciliationResponse{
+			Success: false,
+			Error:   err.Error(),
+			Code:    errorCode(err, status),
+		})
+	}
+
+	return c.JSON(model.ReconciliationResponse{
+		Success: true,
+		Data:    result,
+	})
+}
+
+// @Summary List plugins
+// @Description Get a paginated list of the configured plugins, sorted by name
+// @Tags plugins
+// @Produce json
+// @Security BearerAuth
+// @Param page query int false "Page number (1-based, defaults to 1)" minimum(1)
+// @Param per_page query int false "Number of items per page (defaults to 100, max 1000)" minimum(1) maximum(1000)
+// @Param Accept header string false "Set 'application/json; envelope=false' to receive a plain array with pagination in X-* headers"
+// @Success 200 {object} model.PaginatedPluginsResponse
+// @Failure 400 {object} model.PaginatedPluginsResponse "Bad Request - Invalid pagination parameters"
+// @Failure 401 {object} model.PaginatedPluginsResponse "Unauthorized - Invalid or missing authentication token"
+// @Failure 500 {object} model.PaginatedPluginsResponse "Internal Server Error"
+// @Header 200 {string} Link "RFC 5988 links to the next, prev, first and last page"
+// @Router /api/v1/plugins [get]
+// ListPlugins handles GET /api/v1/plugins
+func (h *DomainHandler) ListPlugins(c *fiber.Ctx) error {
+	page, perPage, err := parsePagination(c)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(model.PaginatedPluginsResponse{
+			Success: false,
+			Error:   err.Error(),
+			Code:    errorCode(err, fiber.StatusBadRequest),
+		})
+	}
+
+	plugins, pagination, err := h.service.Plugins(page, perPage)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(model.PaginatedPluginsResponse{
+			Success: false,
+			Error:   err.Error(),
+			Code:    errorCode(err, fiber.StatusInternalServerError),
+		})
+	}
+
+	h.setPagination(c, pagination)
+
+	if wantsBare(c, h.responseFormat) {
+		if plugins == nil {
+			plugins = []*model.PluginInfo{}
+		}
+		return c.JSON(plugins)
+	}
+
+	return c.JSON(model.PaginatedPluginsResponse{
+		Success:    true,
+		Data:       plugins,
+		Pagination: pagination,
+	})
+}
+
+// @Summary List plugin errors
+// @Description Get a paginated list of the errors plugins returned recently while enriching domain metadata, newest first.
+// @Description Only a bounded number of errors is retained.
+// @Tags plugins
+// @Produce json
+// @Security BearerAuth
+// @Param page query int false "Page number (1-based, defaults to 1)" minimum(1)
+// @Param per_page query int false "Number of items per page (defaults to 100, max 1000)" minimum(1) maximum(1000)
+// @Param Accept header string false "Set 'application/json; envelope=false' to receive a plain array with pagination in X-* headers"
+// @Success 200 {object} model.PaginatedPluginErrorsResponse
+// @Failure 400 {object} model.PaginatedPluginErrorsResponse "Bad Request - Invalid pagination parameters"
+// @Failure 401 {object} model.PaginatedPluginErrorsResponse "Unauthorized - Invalid or missing authentication token"
+// @Failure 500 {object} model.PaginatedPluginErrorsResponse "Internal Server Error"
+// @Header 200 {string} Link "RFC 5988 links to the next, prev, first and last page"
+// @Router /api/v1/plugins/errors [get]
+// PluginErrors handles GET /api/v1/plugins/errors
+func (h *DomainHandler) PluginErrors(c *fiber.Ctx) error {
+	page, perPage, err := parsePagination(c)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(model.PaginatedPluginErrorsResponse{
+			Success: false,
+			Error:   err.Error(),
+			Code:    errorCode(err, fiber.StatusBadRequest),
+		})
+	}
+
+	errs, pagination, err := h.service.PluginErrors(page, perPage)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(model.PaginatedPluginErrorsResponse{
+			Success: false,
+			Error:   err.Error(),
+			Code:    errorCode(err, fiber.StatusInternalServerError),
+		})
+	}
+
+	h.setPagination(c, pagination)
+
+	if wantsBare(c, h.responseFormat) {
+		if errs == nil {
+			errs = []*model.PluginError{}
+		}
+		return c.JSON(errs)
+	}
+
+	return c.JSON(model.PaginatedPluginErrorsResponse{
+		Success:    true,
+		Data:       errs,
+		Pagination: pagination,
+	})
+}
+
+// expiryDays parses the expiry_days query parameter, defaulting to DefaultExpiryDays.
+func expiryDays(c *fiber.Ctx) (int, error) {
+	param := c.Query("expiry_days")
+	if param == "" {
+		return DefaultExpiryDays, nil
+	}
+
+	days, err := strconv.Atoi(param)
+	if err != nil || days < 0 {
+		return 0, fmt.Errorf("invalid expiry_days: %s", param)
+	}
+
+	return days, nil
+}
+
+// listFilterOptions returns the service query options for the enabled and cert_status filters of the list endpoint.
+func listFilterOptions(c *fiber.Ctx) ([]serviceinterface.QueryOption, error) {
+	var opts []serviceinterface.QueryOption
+
+	if param := c.Query("enabled"); param != "" {
+		enabled, err := strconv.ParseBool(param)
+		if err != nil {
+			return nil, fmt.Errorf("invalid enabled: %s", param)
+		}
+		opts = append(opts, serviceinterface.WithEnabled(enabled))
+	}
+
+	if status := c.Query("cert_status"); status != "" {
+		switch status {
+		case dehydrated.CertStatusValid, dehydrated.CertStatusExpiring, dehydrated.CertStatusExpired, dehydrated.CertStatusMissing:
+		default:
+			return nil, fmt.Errorf("invalid cert_status: %s", status)
+		}
+
+		days, err := expiryDays(c)
+		if err != nil {
+			return nil, err
+		}
+		opts = append(opts, serviceinterface.WithCertStatus(status, time.Duration(days)*24*time.Hour))
+	}
+
+	return opts, nil
+}
+
+// @Summary Get effective configuration of a domain
+// @Description Get the dehydrated configuration of a domain entry as passed to plugins: the global configuration
+// @Description with KEY_ALGO, KEY_SIZE and CHALLENGETYPE overridden by the config file of the entry's certificate,
+// @Description which is stored under the alias if set, or the domain name otherwise.
+// @Tags domains
+// @Produce json
+// @Security BearerAuth
+// @Param domain path string true "Domain name"
+// @Param alias query string false "Alias of the domain entry"
+// @Success 200 {object} model.ConfigResponse
+// @Failure 400 {object} model.ConfigResponse "Bad Request - Certificate directory outside of CERTDIR"
+// @Failure 401 {object} model.ConfigResponse "Unauthorized - Invalid or missing authentication token"
+// @Failure 404 {object} model.ConfigResponse "Not Found - Domain not found"
+// @Failure 500 {object} model.ConfigResponse "Internal Server Error"
+// @Failure 503 {object} model.ConfigResponse "Service Unavailable - domains file cannot be read"
+// @Router /api/v1/domains/{domain}/effective-config [get]
+// EffectiveConfig handles GET /api/v1/domains/:domain/effective-config
+func (h *DomainHandler) EffectiveConfig(c *fiber.Ctx) error {
+	cfg, err := h.service.EffectiveConfig(c.Params("domain"), c.Query("alias"))
+	if err != nil {
+		status := fiber.StatusInternalServerError
+		if errors.Is(err, serviceinterface.ErrDomainNotFound) {
+			status = fiber.StatusNotFound
+		} else if errors.Is(err, dehydrated.ErrInvalidPath) {
+			status = fiber.StatusBadRequest
+		} else if errors.Is(err, serviceinterface.ErrCacheUnavailable) {
+			status = fiber.StatusServiceUnavailable
+		}
+		return c.Status(status).JSON(model.ConfigResponse{
+			Success: false,
+			Error:   err.Error(),
+			Code:    errorCode(err, status),
+		})
+	}
+
+	return c.JSON(model.ConfigResponse{
+		Success: true,
+		Data:    cfg,
+	})
+}
+
+// @Summary Get the public key fingerprint of a domain
+// @Description Get the SHA-256 fingerprint, algorithm and size of the public key of a domain entry's certificate,
+// @Description e.g., to audit key rotations. The key is read from privkey.pem, or from cert.pem if the private key
+// @Description does not exist or is not readable. The key material is never returned.
+// @Tags domains
+// @Produce json
+// @Security BearerAuth
+// @Param domain path string true "Domain name"
+// @Param alias query string false "Alias of the domain entry"
+// @Success 200 {object} model.KeyFingerprintResponse
+// @Failure 400 {object} model.KeyFingerprintResponse "Bad Request - Certificate directory outside of CERTDIR"
+// @Failure 401 {object} model.KeyFingerprintResponse "Unauthorized - Invalid or missing authentication token"
+// @Failure 404 {object} model.KeyFingerprintResponse "Not Found - Domain, private key and certificate not found"
+// @Failure 500 {object} model.KeyFingerprintResponse "Internal Server Error - Key cannot be read"
+// @Failure 503 {object} model.KeyFingerprintResponse "Service Unavailable - domains file cannot be read"
+// @Router /api/v1/domains/{domain}/key/fingerprint [get]
+// KeyFingerprint handles GET /api/v1/domains/:domain/key/fingerprint
+func (h *DomainHandler) KeyFingerprint(c *fiber.Ctx) error {
+	info, err := h.service.KeyFingerprint(c.Params("domain"), c.Query("alias"))
+	if err != nil {
+		status := fiber.StatusInternalServerError
+		if errors.Is(err, serviceinterface.ErrDomainNotFound) || errors.Is(err, dehydrated.ErrKeyNotFound) {
+			status = fiber.StatusNotFound
+		} else if errors.Is(err, dehydrated.ErrInvalidPath) {
+			status = fiber.StatusBadRequest
+		} else if errors.Is(err, serviceinterface.ErrCacheUnavailable) {
+			status = fiber.StatusServiceUnavailable
+		}
+		return c.Status(status).JSON(model.KeyFingerprintResponse{
+			Success: false,
+			Error:   err.Error(),
+			Code:    errorCode(err, status),
+		})
+	}
+
+	return c.JSON(model.KeyFingerprintResponse{
+		Success: true,
+		Data:    info,
+	})
+}
+
+// @Summary Get the raw line of a domain
+// @Description Get the line of a domain entry exactly as written in domains.txt with its parsed components:
+// @Description primary domain, alternative names, alias, options following the alias and comment. Helps to
+// @Description reconcile the line with the entry the API understood. With write coalescing, recent changes may not be written yet.
+// @Tags domains
+// @Produce json
+// @Security BearerAuth
+// @Param domain path string true "Domain name"
+// @Param alias query string false "Alias of the domain entry"
+// @Success 200 {object} model.RawDomainLineResponse
+// @Failure 401 {object} model.RawDomainLineResponse "Unauthorized - Invalid or missing authentication token"
+// @Failure 404 {object} model.RawDomainLineResponse "Not Found - Domain not found in domains.txt"
+// @Failure 500 {object} model.RawDomainLineResponse "Internal Server Error"
+// @Header 200 {string} ETag "Entity tag of the response body"
+// @Router /api/v1/domains/{domain}/raw [get]
+// @Router /api/v1/domains/{domain}/raw [head]
+// RawDomainLine handles GET and HEAD /api/v1/domains/:domain/raw
+func (h *DomainHandler) RawDomainLine(c *fiber.Ctx) error {
+	line, err := h.service.RawDomainLine(c.Params("domain"), c.Query("alias"))
+	if err != nil {
+		status := fiber.StatusInternalServerError
+		if errors.Is(err, serviceinterface.ErrDomainNotFound) {
+			status = fiber.StatusNotFound
+		}
+		return c.Status(status).JSON(model.RawDomainLineResponse{
+			Success: false,
+			Error:   err.Error(),
+			Code:    errorCode(err, status),
+		})
+	}
+
+	if wantsBare(c, h.responseFormat) {
+		return c.JSON(line)
+	}
+
+	return c.JSON(model.RawDomainLineResponse{
+		Success: true,
+		Data:    line,
+	})
+}
+
+// @Summary Refresh OCSP response
+// @Description Run dehydrated for a domain entry to fetch a new OCSP response (if older than OCSP_DAYS) and return the current one.
+// @Description dehydrated also renews the certificate if due. Only available if enabled in the server configuration.
+// @Description Disabled entries are refused unless allow_disabled is set. force and force_validation apply to this run only.
+// @Tags domains
+// @Produce json
+// @Security BearerAuth
+// @Param domain path string true "Domain name"
+// @Param alias query string false "Alias of the domain entry"
+// @Param allow_disabled query bool false "Run dehydrated even if the entry is disabled"
+// @Param force query bool false "Renew the certificate even if it does not expire soon (dehydrated --force)"
+// @Param force_validation query bool false "Revalidate the domain names even if valid authorizations exist (dehydrated --force-validation), requires force"
+// @Success 200 {object} model.OCSPResponse
+// @Failure 400 {object} model.OCSPResponse "Bad Request - Invalid allow_disabled, force or force_validation, force_validation without force or certificate directory outside of CERTDIR"
+// @Failure 401 {object} model.OCSPResponse "Unauthorized - Invalid or missing authentication token"
+// @Failure 403 {object} model.OCSPResponse "Forbidden - Missing writer role"
+// @Failure 404 {object} model.OCSPResponse "Not Found - Domain or OCSP response not found"
+// @Failure 409 {object} model.OCSPResponse "Conflict - Domain is disabled"
+// @Failure 500 {object} model.OCSPResponse "Internal Server Error - dehydrated failed"
+// @Failure 503 {object} model.OCSPResponse "Service Unavailable - domains file cannot be read"
+// @Router /api/v1/domains/{domain}/ocsp/refresh [post]
+// RefreshOCSP handles POST /api/v1/domains/:domain/ocsp/refresh
+func (h *DomainHandler) RefreshOCSP(c *fiber.Ctx) error {
+	allowDisabled := false
+	var opts dehydrated.CronOptions
+	for _, flag := range []struct {
+		name  string
+		value *bool
+	}{
+		{"allow_disabled", &allowDisabled},
+		{"force", &opts.ForceRenew},
+		{"force_validation", &opts.ForceValidation},
+	} {
+		if param := c.Query(flag.name); param != "" {
+			var err error
+			if *flag.value, err = strconv.ParseBool(param); err != nil {
+				return c.Status(fiber.StatusBadRequest).JSON(model.OCSPResponse{
+					Success: false,
+					Error:   "invalid " + flag.name + ": " + param,
+					Code:    model.CodeValidationFailed,
+				})
+			}
+		}
+	}
+
+	info, err := h.service.RefreshOCSP(c.UserContext(), c.Params("domain"), c.Query("alias"), allowDisabled, opts)
+	if err != nil {
+		status := fiber.StatusInternalServerError
+		if errors.Is(err, serviceinterface.ErrDomainNotFound) || errors.Is(err, dehydrated.ErrOCSPNotFound) {
+			status = fiber.StatusNotFound
+		} else if errors.Is(err, dehydrated.ErrInvalidPath) || errors.Is(err, dehydrated.ErrForceValidationWithoutForce) {
+			status = fiber.StatusBadRequest
+		} else if errors.Is(err, serviceinterface.ErrDomainDisabled) {
+			status = fiber.StatusConflict
+		} else if errors.Is(err, serviceinterface.ErrCacheUnavailable) {
+			status = fiber.StatusServiceUnavailable
+		}
+		return c.Status(status).JSON(model.OCSPResponse{
+			Success: false,
+			Error:   err.Error(),
+			Code:    errorCode(err, status),
+		})
+	}
+
+	return c.JSON(model.OCSPResponse{
+		Success: true,
+		Data:    info,
+	})
+}
@@ -2,11 +2,23 @@ package handler
 
 import (
 	"bytes"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
 	"encoding/json"
+	"fmt"
+	"io"
 	"net/http"
 	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strconv"
 	"strings"
 	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
 
 	"github.com/schumann-it/dehydrated-api-go/internal/util"
 
@@ -16,8 +28,11 @@ import (
 	"github.com/schumann-it/dehydrated-api-go/internal/model"
 
 	"github.com/schumann-it/dehydrated-api-go/internal/service"
+	"github.com/schumann-it/dehydrated-api-go/internal/testutil"
 
 	"github.com/gofiber/fiber/v2"
+	"github.com/golang-jwt/jwt/v5"
+	"google.golang.org/protobuf/types/known/structpb"
 )
 
 // TestDomainHandler tests the complete domain handler functionality.
@@ -51,7 +66,7 @@ func TestDomainHandler(t *testing.T) {
 		req := model.CreateDomainRequest{
 			Domain:           "example-create.com",
 			AlternativeNames: []string{"www.example.com"},
-			Enabled:          true,
+			Enabled:          util.BoolPtr(true),
 		}
 		body, _ := json.Marshal(req)
 
@@ -120,8 +135,8 @@ func TestDomainHandler(t *testing.T) {
 		}
 		defer result.Body.Close()
 
-		if result.StatusCode != fiber.StatusBadRequest {
-			t.Errorf("Expected status %d, got %d", fiber.StatusBadRequest, result.StatusCode)
+		if result.StatusCode != fiber.StatusUnprocessableEntity {
+			t.Errorf("Expected status %d, got %d", fiber.StatusUnprocessableEntity, result.StatusCode)
 		}
 	})
 
@@ -154,7 +169,7 @@ func TestDomainHandler(t *testing.T) {
 		createReq := model.CreateDomainRequest{
 			Domain:           "example-get.com",
 			AlternativeNames: []string{"www.example.com"},
-			Enabled:          true,
+			Enabled:          util.BoolPtr(true),
 		}
 		createBody, _ := json.Marshal(createReq)
 
@@ -330,7 +345,7 @@ func TestDomainHandler(t *testing.T) {
 		for _, domain := range domains {
 			req := model.CreateDomainRequest{
 				Domain:  domain,
-				Enabled: true,
+				Enabled: util.BoolPtr(true),
 			}
 			body, _ := json.Marshal(req)
 
@@ -477,7 +492,7 @@ func TestDomainHandler(t *testing.T) {
 		for _, domain := range domains {
 			req := model.CreateDomainRequest{
 				Domain:  domain,
-				Enabled: true,
+				Enabled: util.BoolPtr(true),
 			}
 			body, _ := json.Marshal(req)
 
@@ -630,7 +645,7 @@ func TestDomainHandler(t *testing.T) {
 		for _, domain := range domains {
 			req := model.CreateDomainRequest{
 				Domain:  domain,
-				Enabled: true,
+				Enabled: util.BoolPtr(true),
 			}
 			body, _ := json.Marshal(req)
 
@@ -792,7 +807,7 @@ func TestDomainHandler(t *testing.T) {
 		createReq := model.CreateDomainRequest{
 			Domain:           "example-update.com",
 			AlternativeNames: []string{"www.example.com"},
-			Enabled:          true,
+			Enabled:          util.BoolPtr(true),
 		}
 		createBody, _ := json.Marshal(createReq)
 
@@ -875,7 +890,7 @@ func TestDomainHandler(t *testing.T) {
 		createReq := model.CreateDomainRequest{
 			Domain:           "example-update-empty.com",
 			AlternativeNames: []string{"www.example.com", "api.example.com"},
-			Enabled:          true,
+			Enabled:          util.BoolPtr(true),
 		}
 		createBody, _ := json.Marshal(createReq)
 
@@ -958,7 +973,7 @@ func TestDomainHandler(t *testing.T) {
 		createReq := model.CreateDomainRequest{
 			Domain:           "example-delete.com",
 			AlternativeNames: []string{"www.example.com"},
-			Enabled:          true,
+			Enabled:          util.BoolPtr(true),
 		}
 		createBody, _ := json.Marshal(createReq)
 
@@ -1008,9 +1023,19 @@ func TestRouteRegistration(t *testing.T) {
 	}{
 		{"GET", "/api/v1/domains"},
 		{"GET", "/api/v1/domains/example.com"},
+		{"HEAD", "/api/v1/domains"},
+		{"HEAD", "/api/v1/domains/example.com"},
 		{"POST", "/api/v1/domains"},
 		{"PUT", "/api/v1/domains/example.com"},
 		{"DELETE", "/api/v1/domains/example.com"},
+		{"OPTIONS", "/api/v1/domains"},
+		{"OPTIONS", "/api/v1/domains/example.com"},
+		{"GET", "/api/v1/domains/example.com/aliases/example"},
+		{"HEAD", "/api/v1/domains/example.com/aliases/example"},
+		{"PUT", "/api/v1/domains/example.com/aliases/example"},
+		{"DELETE", "/api/v1/domains/example.com/aliases/example"},
+		{"OPTIONS", "/api/v1/domains/example.com/aliases/example"},
+		{"GET", "/api/v1/summary"},
 	}
 
 	// Get the app's route stack
@@ -1025,8 +1050,11 @@ func TestRouteRegistration(t *testing.T) {
 		for _, route := range routes {
 			// Convert route pattern to a test path by replacing :param with a value
 			testPath := route.Path
-			if route.Path == "/api/v1/domains/:domain" {
+			switch route.Path {
+			case "/api/v1/domains/:domain":
 				testPath = "/api/v1/domains/example.com"
+			case "/api/v1/domains/:domain/aliases/:alias":
+				testPath = "/api/v1/domains/example.com/aliases/example"
 			}
 			key := route.Method + " " + testPath
 			registeredRoutes[key] = true
@@ -1044,6 +1072,93 @@ func TestRouteRegistration(t *testing.T) {
 	}
 }
 
+// TestHeadRequests verifies that HEAD requests on the list and get endpoints return
+// the same status and headers as GET, but no body.
+func TestHeadRequests(t *testing.T) {
+	app := newFormatTestApp(t, model.ResponseFormatEnveloped)
+
+	for _, path := range []string{"/api/v1/domains", "/api/v1/domains/a.example.com"} {
+		t.Run(path, func(t *testing.T) {
+			getResp, err := app.Test(httptest.NewRequest("GET", path, http.NoBody))
+			require.NoError(t, err)
+			defer getResp.Body.Close()
+			getBody, err := io.ReadAll(getResp.Body)
+			require.NoError(t, err)
+			require.NotEmpty(t, getResp.Header.Get("ETag"))
+
+			headResp, err := app.Test(httptest.NewRequest("HEAD", path, http.NoBody))
+			require.NoError(t, err)
+			defer headResp.Body.Close()
+			headBody, err := io.ReadAll(headResp.Body)
+			require.NoError(t, err)
+
+			require.Equal(t, fiber.StatusOK, headResp.StatusCode)
+			require.Empty(t, headBody)
+			require.Equal(t, getResp.Header.Get("ETag"), headResp.Header.Get("ETag"))
+			require.Equal(t, strconv.Itoa(len(getBody)), headResp.Header.Get("Content-Length"))
+			require.Equal(t, fiber.MIMEApplicationJSON, headResp.Header.Get("Content-Type"))
+		})
+	}
+
+	t.Run("NotFound", func(t *testing.T) {
+		resp, err := app.Test(httptest.NewRequest("HEAD", "/api/v1/domains/missing.example.com", http.NoBody))
+		require.NoError(t, err)
+		defer resp.Body.Close()
+		require.Equal(t, fiber.StatusNotFound, resp.StatusCode)
+	})
+}
+
+// TestOptionsRequests verifies that OPTIONS requests advertise the supported methods of each resource.
+func TestOptionsRequests(t *testing.T) {
+	app := fiber.New()
+	NewDomainHandler(&serviceinterface.MockDomainService{}).RegisterRoutes(app.Group("/api/v1"))
+
+	tests := []struct {
+		path  string
+		allow string
+	}{
+		{"/api/v1/domains", "GET, HEAD, POST, OPTIONS"},
+		{"/api/v1/domains/example.com", "GET, HEAD, PUT, DELETE, OPTIONS"},
+		{"/api/v1/domains/example.com/aliases/example", "GET, HEAD, PUT, DELETE, OPTIONS"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			resp, err := app.Test(httptest.NewRequest("OPTIONS", tt.path, http.NoBody))
+			require.NoError(t, err)
+			defer resp.Body.Close()
+
+			require.Equal(t, fiber.StatusNoContent, resp.StatusCode)
+			require.Equal(t, tt.allow, resp.Header.Get("Allow"))
+		})
+	}
+
+	// Every route answers OPTIONS with all of its methods, including the optional ones
+	t.Run("AllRoutes", func(t *testing.T) {
+		app := fiber.New()
+		NewDomainHandler(&serviceinterface.MockDomainService{}).WithOCSPRefresh(true).RegisterRoutes(app.Group("/api/v1"))
+
+		methods := make(map[string][]string)
+		for _, route := range app.GetRoutes(true) {
+			if route.Method != fiber.MethodOptions {
+				methods[route.Path] = append(methods[route.Path], route.Method)
+			}
+		}
+		for path, want := range methods {
+			resp, err := app.Test(httptest.NewRequest("OPTIONS", strings.NewReplacer(":domain", "example.com", ":alias", "example").Replace(path), http.NoBody))
+			require.NoError(t, err)
+			resp.Body.Close()
+
+			require.Equal(t, fiber.StatusNoContent, resp.StatusCode, path)
+			allow := strings.Split(resp.Header.Get("Allow"), ", ")
+			require.Contains(t, allow, fiber.MethodOptions, path)
+			for _, method := range want {
+				require.Contains(t, allow, method, path)
+			}
+		}
+	})
+}
+
 // TestServiceErrors verifies that the handler properly handles service errors.
 // It tests error responses for various error conditions that may occur during domain operations.
 func TestServiceErrors(t *testing.T) {
@@ -1128,3 +1243,1932 @@ func TestCacheHeaders(t *testing.T) {
 		})
 	}
 }
+
+// TestBulkSetEnabled verifies that bulk enable/disable only changes entries matching the search
+// and requires the writer role.
+func TestBulkSetEnabled(t *testing.T) {
+	dc := dehydrated.NewConfig().WithBaseDir(t.TempDir()).Load()
+	s := service.NewDomainService(dc, nil)
+	defer s.Close()
+
+	for _, domain := range []string{"staging.a.example.com", "staging.b.example.com", "prod.example.com"} {
+		_, err := s.CreateDomain(&model.CreateDomainRequest{Domain: domain, Enabled: util.BoolPtr(true)})
+		require.NoError(t, err)
+	}
+
+	app := fiber.New()
+	NewDomainHandler(s).RegisterRoutes(app.Group("/api/v1"))
+
+	bulk := func(t *testing.T, path, body string) model.BulkUpdateResponse {
+		req := httptest.NewRequest("POST", path, strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		resp, err := app.Test(req)
+		require.NoError(t, err)
+		defer resp.Body.Close()
+		require.Equal(t, fiber.StatusOK, resp.StatusCode)
+
+		var response model.BulkUpdateResponse
+		require.NoError(t, json.NewDecoder(resp.Body).Decode(&response))
+		require.True(t, response.Success)
+		return response
+	}
+
+	enabled := func(t *testing.T, domain string) bool {
+		entry, err := s.GetDomain(domain, "")
+		require.NoError(t, err)
+		return entry.Enabled
+	}
+
+	t.Run("Disable", func(t *testing.T) {
+		response := bulk(t, "/api/v1/domains/bulk-disable", `{"search":"staging."}`)
+		require.Equal(t, 2, response.Count)
+		require.False(t, enabled(t, "staging.a.example.com"))
+		require.False(t, enabled(t, "staging.b.example.com"))
+		require.True(t, enabled(t, "prod.example.com"))
+	})
+
+	t.Run("Enable", func(t *testing.T) {
+		response := bulk(t, "/api/v1/domains/bulk-enable", `{"search":"staging.a","enabled":false}`)
+		require.Equal(t, 1, response.Count)
+		require.True(t, enabled(t, "staging.a.example.com"))
+		require.False(t, enabled(t, "staging.b.example.com"))
+	})
+
+	t.Run("InvalidBody", func(t *testing.T) {
+		req := httptest.NewRequest("POST", "/api/v1/domains/bulk-enable", strings.NewReader(`{`))
+		req.Header.Set("Content-Type", "application/json")
+		resp, err := app.Test(req)
+		require.NoError(t, err)
+		defer resp.Body.Close()
+		require.Equal(t, fiber.StatusBadRequest, resp.StatusCode)
+	})
+
+	t.Run("MissingWriterRole", func(t *testing.T) {
+		guarded := fiber.New()
+		g := guarded.Group("/api/v1", func(c *fiber.Ctx) error {
+			c.Locals("claims", jwt.MapClaims{"roles": []any{"reader"}})
+			return c.Next()
+		})
+		NewDomainHandler(s).RegisterRoutes(g)
+
+		req := httptest.NewRequest("POST", "/api/v1/domains/bulk-disable", strings.NewReader(`{}`))
+		req.Header.Set("Content-Type", "application/json")
+		resp, err := guarded.Test(req)
+		require.NoError(t, err)
+		defer resp.Body.Close()
+		require.Equal(t, fiber.StatusForbidden, resp.StatusCode)
+		require.False(t, enabled(t, "staging.b.example.com"))
+		require.True(t, enabled(t, "prod.example.com"))
+	})
+}
+
+// TestBulkDelete verifies that bulk deletion only removes entries matching the filter,
+// requires an explicit confirmation and the writer role.
+func TestBulkDelete(t *testing.T) {
+	dc := dehydrated.NewConfig().WithBaseDir(t.TempDir()).Load()
+	s := service.NewDomainService(dc, nil)
+	defer s.Close()
+
+	for _, domain := range []string{"staging.a.example.com", "staging.b.example.com", "prod.example.com"} {
+		_, err := s.CreateDomain(&model.CreateDomainRequest{Domain: domain, Enabled: util.BoolPtr(true)})
+		require.NoError(t, err)
+	}
+
+	app := fiber.New()
+	NewDomainHandler(s).RegisterRoutes(app.Group("/api/v1"))
+
+	bulkDelete := func(t *testing.T, app *fiber.App, body string) (int, model.BulkDeleteResponse) {
+		req := httptest.NewRequest("POST", "/api/v1/domains/bulk-delete", strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		resp, err := app.Test(req)
+		require.NoError(t, err)
+		defer resp.Body.Close()
+
+		var response model.BulkDeleteResponse
+		require.NoError(t, json.NewDecoder(resp.Body).Decode(&response))
+		return resp.StatusCode, response
+	}
+
+	remaining := func(t *testing.T) int {
+		entries, _, err := s.ListDomains(1, 10, "", "")
+		require.NoError(t, err)
+		return len(entries)
+	}
+
+	t.Run("MissingConfirmation", func(t *testing.T) {
+		status, response := bulkDelete(t, app, `{"search":"staging."}`)
+		require.Equal(t, fiber.StatusBadRequest, status)
+		require.Equal(t, model.CodeValidationFailed, response.Code)
+		require.Equal(t, 3, remaining(t))
+	})
+
+	t.Run("InvalidBody", func(t *testing.T) {
+		status, _ := bulkDelete(t, app, `{`)
+		require.Equal(t, fiber.StatusBadRequest, status)
+	})
+
+	t.Run("MissingWriterRole", func(t *testing.T) {
+		guarded := fiber.New(fiber.Config{ErrorHandler: ErrorHandler})
+		g := guarded.Group("/api/v1", func(c *fiber.Ctx) error {
+			c.Locals("claims", jwt.MapClaims{"roles": []any{"reader"}})
+			return c.Next()
+		})
+		NewDomainHandler(s).RegisterRoutes(g)
+
+		status, _ := bulkDelete(t, guarded, `{"confirm":true}`)
+		require.Equal(t, fiber.StatusForbidden, status)
+		require.Equal(t, 3, remaining(t))
+	})
+
+	t.Run("Delete", func(t *testing.T) {
+		status, response := bulkDelete(t, app, `{"search":"staging.","confirm":true}`)
+		require.Equal(t, fiber.StatusOK, status)
+		require.True(t, response.Success)
+		require.Equal(t, 2, response.Count)
+		require.Equal(t, "staging.a.example.com", response.Data[0].Domain)
+		require.Equal(t, "staging.b.example.com", response.Data[1].Domain)
+
+		_, err := s.GetDomain("prod.example.com", "")
+		require.NoError(t, err)
+		require.Equal(t, 1, remaining(t))
+	})
+}
+
+func TestNormalizeDomains(t *testing.T) {
+	dc := dehydrated.NewConfig().WithBaseDir(t.TempDir()).Load()
+	require.NoError(t, os.WriteFile(dc.DomainsFile, []byte("example.org WWW.example.org www.example.org\n# comment\nExample.com\n"), 0644))
+	s := service.NewDomainService(dc, nil)
+	defer s.Close()
+	require.NoError(t, s.Reload())
+
+	app := fiber.New()
+	NewDomainHandler(s).RegisterRoutes(app.Group("/api/v1"))
+
+	normalize := func(t *testing.T, app *fiber.App, query string) (int, model.NormalizeResponse) {
+		resp, err := app.Test(httptest.NewRequest("POST", "/api/v1/domains/normalize"+query, nil))
+		require.NoError(t, err)
+		defer resp.Body.Close()
+
+		var response model.NormalizeResponse
+		require.NoError(t, json.NewDecoder(resp.Body).Decode(&response))
+		return resp.StatusCode, response
+	}
+
+	expectedChanges := []model.NormalizeChange{
+		{Line: 1, Before: "example.org WWW.example.org www.example.org", After: "example.org www.example.org"},
+		{Line: 2, Before: "# comment"},
+		{Line: 3, Before: "Example.com", After: "example.com"},
+	}
+
+	t.Run("InvalidDryRun", func(t *testing.T) {
+		status, response := normalize(t, app, "?dry_run=maybe")
+		require.Equal(t, fiber.StatusBadRequest, status)
+		require.Equal(t, model.CodeValidationFailed, response.Code)
+	})
+
+	t.Run("MissingWriterRole", func(t *testing.T) {
+		guarded := fiber.New(fiber.Config{ErrorHandler: ErrorHandler})
+		g := guarded.Group("/api/v1", func(c *fiber.Ctx) error {
+			c.Locals("claims", jwt.MapClaims{"roles": []any{"reader"}})
+			return c.Next()
+		})
+		NewDomainHandler(s).RegisterRoutes(g)
+
+		resp, err := guarded.Test(httptest.NewRequest("POST", "/api/v1/domains/normalize", nil))
+		require.NoError(t, err)
+		require.Equal(t, fiber.StatusForbidden, resp.StatusCode)
+	})
+
+	t.Run("DryRun", func(t *testing.T) {
+		status, response := normalize(t, app, "?dry_run=true")
+		require.Equal(t, fiber.StatusOK, status)
+		require.True(t, response.Data.DryRun)
+		require.True(t, response.Data.Changed)
+		require.True(t, response.Data.Reordered)
+		require.Equal(t, expectedChanges, response.Data.Changes)
+
+		data, err := os.ReadFile(dc.DomainsFile)
+		require.NoError(t, err)
+		require.Equal(t, "example.org WWW.example.org www.example.org\n# comment\nExample.com\n", string(data))
+	})
+
+	t.Run("Normalize", func(t *testing.T) {
+		status, response := normalize(t, app, "")
+		require.Equal(t, fiber.StatusOK, status)
+		require.True(t, response.Success)
+		require.False(t, response.Data.DryRun)
+		require.Equal(t, 2, response.Data.Entries)
+		require.Equal(t, expectedChanges, response.Data.Changes)
+
+		data, err := os.ReadFile(dc.DomainsFile)
+		require.NoError(t, err)
+		require.Equal(t, "example.com\nexample.org www.example.org\n", string(data))
+
+		status, response = normalize(t, app, "")
+		require.Equal(t, fiber.StatusOK, status)
+		require.False(t, response.Data.Changed)
+		require.Empty(t, response.Data.Changes)
+	})
+
+	t.Run("ServiceError", func(t *testing.T) {
+		app := fiber.New()
+		NewDomainHandler(&serviceinterface.MockErrDomainService{}).RegisterRoutes(app.Group("/api/v1"))
+
+		status, response := normalize(t, app, "")
+		require.Equal(t, fiber.StatusInternalServerError, status)
+		require.False(t, response.Success)
+	})
+}
+
+// TestAliasRoutes verifies that the path-based alias routes operate on the alias-qualified entry only.
+func TestAliasRoutes(t *testing.T) {
+	dc := dehydrated.NewConfig().WithBaseDir(t.TempDir()).Load()
+	s := service.NewDomainService(dc, nil)
+	defer s.Close()
+
+	for _, alias := range []string{"vpn.example.com-rsa", "vpn.example.com-ecdsa"} {
+		_, err := s.CreateDomain(&model.CreateDomainRequest{Domain: "vpn.example.com", Alias: alias, Enabled: util.BoolPtr(true)})
+		require.NoError(t, err)
+	}
+
+	app := fiber.New()
+	NewDomainHandler(s).RegisterRoutes(app.Group("/api/v1"))
+
+	t.Run("Get", func(t *testing.T) {
+		resp, err := app.Test(httptest.NewRequest("GET", "/api/v1/domains/vpn.example.com/aliases/vpn.example.com-ecdsa", http.NoBody))
+		require.NoError(t, err)
+		defer resp.Body.Close()
+		require.Equal(t, fiber.StatusOK, resp.StatusCode)
+
+		var response model.DomainResponse
+		require.NoError(t, json.NewDecoder(resp.Body).Decode(&response))
+		require.Equal(t, "vpn.example.com-ecdsa", response.Data.Alias)
+	})
+
+	t.Run("GetUnknownAlias", func(t *testing.T) {
+		resp, err := app.Test(httptest.NewRequest("GET", "/api/v1/domains/vpn.example.com/aliases/unknown", http.NoBody))
+		require.NoError(t, err)
+		defer resp.Body.Close()
+		require.Equal(t, fiber.StatusNotFound, resp.StatusCode)
+	})
+
+	t.Run("Update", func(t *testing.T) {
+		req := httptest.NewRequest("PUT", "/api/v1/domains/vpn.example.com/aliases/vpn.example.com-rsa",
+			strings.NewReader(`{"enabled":false,"comment":"rsa fallback"}`))
+		req.Header.Set("Content-Type", "application/json")
+		resp, err := app.Test(req)
+		require.NoError(t, err)
+		defer resp.Body.Close()
+		require.Equal(t, fiber.StatusOK, resp.StatusCode)
+
+		rsa, err := s.GetDomain("vpn.example.com", "vpn.example.com-rsa")
+		require.NoError(t, err)
+		require.False(t, rsa.Enabled)
+		require.Equal(t, "rsa fallback", rsa.Comment)
+
+		ecdsa, err := s.GetDomain("vpn.example.com", "vpn.example.com-ecdsa")
+		require.NoError(t, err)
+		require.True(t, ecdsa.Enabled)
+		require.Empty(t, ecdsa.Comment)
+	})
+
+	t.Run("Delete", func(t *testing.T) {
+		resp, err := app.Test(httptest.NewRequest("DELETE", "/api/v1/domains/vpn.example.com/aliases/vpn.example.com-rsa", http.NoBody))
+		require.NoError(t, err)
+		defer resp.Body.Close()
+		require.Equal(t, fiber.StatusNoContent, resp.StatusCode)
+
+		_, err = s.GetDomain("vpn.example.com", "vpn.example.com-rsa")
+		require.Error(t, err)
+		_, err = s.GetDomain("vpn.example.com", "vpn.example.com-ecdsa")
+		require.NoError(t, err)
+	})
+
+	t.Run("QueryParameterStillSupported", func(t *testing.T) {
+		resp, err := app.Test(httptest.NewRequest("GET", "/api/v1/domains/vpn.example.com?alias=vpn.example.com-ecdsa", http.NoBody))
+		require.NoError(t, err)
+		defer resp.Body.Close()
+		require.Equal(t, fiber.StatusOK, resp.StatusCode)
+	})
+}
+
+// TestRenameDomain verifies the rename route and the mapping of service errors to status codes.
+func TestRenameDomain(t *testing.T) {
+	dc := dehydrated.NewConfig().WithBaseDir(t.TempDir()).Load()
+	s := service.NewDomainService(dc, nil)
+	defer s.Close()
+
+	for _, domain := range []string{"a.example.com", "c.example.com"} {
+		_, err := s.CreateDomain(&model.CreateDomainRequest{Domain: domain, Alias: "cert", Enabled: util.BoolPtr(true), Comment: "keep"})
+		require.NoError(t, err)
+	}
+
+	app := fiber.New()
+	NewDomainHandler(s).RegisterRoutes(app.Group("/api/v1"))
+
+	rename := func(t *testing.T, path, body string) (int, model.DomainResponse) {
+		req := httptest.NewRequest("PUT", path, strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		resp, err := app.Test(req)
+		require.NoError(t, err)
+		defer resp.Body.Close()
+
+		var response model.DomainResponse
+		require.NoError(t, json.NewDecoder(resp.Body).Decode(&response))
+		return resp.StatusCode, response
+	}
+
+	status, response := rename(t, "/api/v1/domains/c.example.com/rename?alias=cert", `{"domain":"b.example.com"}`)
+	require.Equal(t, fiber.StatusOK, status)
+	require.Equal(t, "b.example.com", response.Data.Domain)
+	require.Equal(t, "cert", response.Data.Alias)
+	require.Equal(t, "keep", response.Data.Comment)
+
+	status, _ = rename(t, "/api/v1/domains/b.example.com/rename?alias=cert", `{"domain":"a.example.com"}`)
+	require.Equal(t, fiber.StatusConflict, status)
+
+	status, _ = rename(t, "/api/v1/domains/c.example.com/rename?alias=cert", `{"domain":"d.example.com"}`)
+	require.Equal(t, fiber.StatusNotFound, status)
+
+	status, _ = rename(t, "/api/v1/domains/b.example.com/rename?alias=cert", `{"alias":"other"}`)
+	require.Equal(t, fiber.StatusBadRequest, status)
+
+	status, _ = rename(t, "/api/v1/domains/b.example.com/rename?alias=cert", `{"domain":"not a domain"}`)
+	require.Equal(t, fiber.StatusUnprocessableEntity, status)
+}
+
+// TestUpsertDomain verifies that PUT with upsert=true creates missing entries with 201 and updates existing ones with 200.
+func TestUpsertDomain(t *testing.T) {
+	dc := dehydrated.NewConfig().WithBaseDir(t.TempDir()).Load()
+	s := service.NewDomainService(dc, nil)
+	defer s.Close()
+
+	app := fiber.New()
+	NewDomainHandler(s).RegisterRoutes(app.Group("/api/v1"))
+
+	put := func(t *testing.T, path, contentType, body string) (int, model.DomainResponse) {
+		req := httptest.NewRequest("PUT", path, strings.NewReader(body))
+		req.Header.Set("Content-Type", contentType)
+		resp, err := app.Test(req)
+		require.NoError(t, err)
+		defer resp.Body.Close()
+
+		var response model.DomainResponse
+		require.NoError(t, json.NewDecoder(resp.Body).Decode(&response))
+		return resp.StatusCode, response
+	}
+
+	status, response := put(t, "/api/v1/domains/example.com/aliases/cert?upsert=true", "application/json", `{"enabled":true,"comment":"web"}`)
+	require.Equal(t, fiber.StatusCreated, status)
+	require.Equal(t, "cert", response.Data.Alias)
+	require.True(t, response.Data.Enabled)
+
+	status, response = put(t, "/api/v1/domains/example.com?upsert=true", "application/json", `{"alias":"cert","enabled":false}`)
+	require.Equal(t, fiber.StatusOK, status)
+	require.False(t, response.Data.Enabled)
+	require.Equal(t, "web", response.Data.Comment)
+
+	entries, _, err := s.ListDomains(1, 100, "", "")
+	require.NoError(t, err)
+	require.Len(t, entries, 1)
+
+	// Without upsert, missing entries are still not found
+	status, _ = put(t, "/api/v1/domains/other.example.com", "application/json", `{"enabled":true}`)
+	require.Equal(t, fiber.StatusNotFound, status)
+
+	status, _ = put(t, "/api/v1/domains/example.com?upsert=maybe", "application/json", `{}`)
+	require.Equal(t, fiber.StatusBadRequest, status)
+
+	status, _ = put(t, "/api/v1/domains/example.com?upsert=true", MIMEApplicationJSONPatch, `[]`)
+	require.Equal(t, fiber.StatusBadRequest, status)
+}
+
+// TestIncludePosition verifies that mutations return the position of the entry in the sorted domains file if requested.
+func TestIncludePosition(t *testing.T) {
+	dc := dehydrated.NewConfig().WithBaseDir(t.TempDir()).Load()
+	s := service.NewDomainService(dc, nil)
+	defer s.Close()
+
+	app := fiber.New()
+	NewDomainHandler(s).RegisterRoutes(app.Group("/api/v1"))
+
+	request := func(t *testing.T, method, path, body string) (int, model.DomainResponse) {
+		t.Helper()
+		req := httptest.NewRequest(method, path, strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		resp, err := app.Test(req)
+		require.NoError(t, err)
+		defer resp.Body.Close()
+
+		var response model.DomainResponse
+		require.NoError(t, json.NewDecoder(resp.Body).Decode(&response))
+		return resp.StatusCode, response
+	}
+	line := func(t *testing.T, domain, alias string) int {
+		t.Helper()
+		raw, err := s.RawDomainLine(domain, alias)
+		require.NoError(t, err)
+		return raw.Line - 1
+	}
+
+	for _, entry := range []struct{ domain, alias string }{
+		{"m.example.com", ""},
+		{"z.example.com", ""},
+		{"a.example.com", ""},
+		{"m.example.com", "m-rsa"},
+		{"b.example.com", ""},
+	} {
+		status, response := request(t, "POST", "/api/v1/domains?include_position=true",
+			`{"domain":"`+entry.domain+`","alias":"`+entry.alias+`","enabled":true}`)
+		require.Equal(t, fiber.StatusCreated, status)
+		require.NotNil(t, response.Position, entry.domain)
+		require.Equal(t, line(t, entry.domain, entry.alias), *response.Position, entry.domain)
+	}
+
+	// a, b, m, m > m-rsa, z
+	status, response := request(t, "PUT", "/api/v1/domains/z.example.com?include_position=true", `{"enabled":false}`)
+	require.Equal(t, fiber.StatusOK, status)
+	require.NotNil(t, response.Position)
+	require.Equal(t, 4, *response.Position)
+
+	status, response = request(t, "PUT", "/api/v1/domains/m.example.com/aliases/m-rsa?include_position=true", `{"comment":"rsa"}`)
+	require.Equal(t, fiber.StatusOK, status)
+	require.NotNil(t, response.Position)
+	require.Equal(t, 3, *response.Position)
+
+	// Not included unless requested
+	status, response = request(t, "PUT", "/api/v1/domains/a.example.com", `{"enabled":true}`)
+	require.Equal(t, fiber.StatusOK, status)
+	require.Nil(t, response.Position)
+
+	t.Run("Bare", func(t *testing.T) {
+		req := httptest.NewRequest("PUT", "/api/v1/domains/b.example.com?include_position=true", strings.NewReader(`{"enabled":true}`))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set(fiber.HeaderAccept, "application/json; envelope=false")
+		resp, err := app.Test(req)
+		require.NoError(t, err)
+		defer resp.Body.Close()
+		require.Equal(t, fiber.StatusOK, resp.StatusCode)
+		require.Equal(t, "1", resp.Header.Get("X-Position"))
+	})
+
+	t.Run("InvalidParam", func(t *testing.T) {
+		status, response := request(t, "POST", "/api/v1/domains?include_position=maybe", `{"domain":"c.example.com","enabled":true}`)
+		require.Equal(t, fiber.StatusBadRequest, status)
+		require.Equal(t, model.CodeValidationFailed, response.Code)
+
+		// The entry is not created if the parameter is invalid
+		_, err := s.GetDomain("c.example.com", "")
+		require.ErrorIs(t, err, serviceinterface.ErrDomainNotFound)
+	})
+}
+
+// TestCreateDuplicateAlias verifies that creating an entry with an existing domain and alias is rejected with 409.
+func TestCreateDuplicateAlias(t *testing.T) {
+	dc := dehydrated.NewConfig().WithBaseDir(t.TempDir()).Load()
+	s := service.NewDomainService(dc, nil)
+	defer s.Close()
+
+	app := fiber.New()
+	NewDomainHandler(s).RegisterRoutes(app.Group("/api/v1"))
+
+	create := func(t *testing.T, alias string) int {
+		body := `{"domain":"vpn.example.com","alias":"` + alias + `","enabled":true}`
+		req := httptest.NewRequest("POST", "/api/v1/domains", strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		resp, err := app.Test(req)
+		require.NoError(t, err)
+		defer resp.Body.Close()
+		return resp.StatusCode
+	}
+
+	require.Equal(t, fiber.StatusCreated, create(t, "vpn.example.com-rsa"))
+	require.Equal(t, fiber.StatusCreated, create(t, "vpn.example.com-ecdsa"))
+	require.Equal(t, fiber.StatusConflict, create(t, "vpn.example.com-rsa"))
+
+	entries, _, err := s.ListDomains(1, 100, "", "")
+	require.NoError(t, err)
+	require.Len(t, entries, 2)
+}
+
+// TestPaginationLinkHeader verifies the RFC 5988 Link header on a middle page and the encoding of the URLs.
+func TestPaginationLinkHeader(t *testing.T) {
+	dc := dehydrated.NewConfig().WithBaseDir(t.TempDir()).Load()
+	s := service.NewDomainService(dc, nil)
+	defer s.Close()
+
+	for i := 1; i <= 5; i++ {
+		_, err := s.CreateDomain(&model.CreateDomainRequest{Domain: "d" + strconv.Itoa(i) + ".example.com", Enabled: util.BoolPtr(true)})
+		require.NoError(t, err)
+	}
+
+	app := fiber.New()
+	NewDomainHandler(s).RegisterRoutes(app.Group("/api/v1"))
+
+	resp, err := app.Test(httptest.NewRequest("GET", "/api/v1/domains?page=2&per_page=2&search=example&tag=a%26b+c", http.NoBody))
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	require.Equal(t, fiber.StatusOK, resp.StatusCode)
+
+	pageURL := func(page int) string {
+		return "http://example.com/api/v1/domains?page=" + strconv.Itoa(page) + "&per_page=2&search=example&tag=a%26b+c"
+	}
+	require.Equal(t,
+		`<`+pageURL(3)+`>; rel="next", `+
+			`<`+pageURL(1)+`>; rel="prev", `+
+			`<`+pageURL(1)+`>; rel="first", `+
+			`<`+pageURL(3)+`>; rel="last"`,
+		resp.Header.Get("Link"))
+
+	var response model.PaginatedDomainsResponse
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&response))
+	require.Equal(t, pageURL(3), response.Pagination.NextURL)
+	require.Equal(t, pageURL(1), response.Pagination.PrevURL)
+}
+
+// summaryDomainService records the expiry threshold of Summary calls.
+type summaryDomainService struct {
+	serviceinterface.MockDomainService
+	threshold time.Duration
+}
+
+func (m *summaryDomainService) Summary(expiryThreshold time.Duration) (*model.Summary, error) {
+	m.threshold = expiryThreshold
+	return &model.Summary{Total: 3, ExpiryDays: int(expiryThreshold / (24 * time.Hour))}, nil
+}
+
+// TestSummary verifies the summary endpoint and its expiry_days parameter.
+func TestSummary(t *testing.T) {
+	s := &summaryDomainService{}
+	app := fiber.New()
+	NewDomainHandler(s).RegisterRoutes(app.Group("/api/v1"))
+
+	tests := []struct {
+		path      string
+		status    int
+		threshold time.Duration
+	}{
+		{"/api/v1/summary", fiber.StatusOK, DefaultExpiryDays * 24 * time.Hour},
+		{"/api/v1/summary?expiry_days=30", fiber.StatusOK, 30 * 24 * time.Hour},
+		{"/api/v1/summary?expiry_days=0", fiber.StatusOK, 0},
+		{"/api/v1/summary?expiry_days=-1", fiber.StatusBadRequest, 0},
+		{"/api/v1/summary?expiry_days=soon", fiber.StatusBadRequest, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			s.threshold = -1
+			resp, err := app.Test(httptest.NewRequest("GET", tt.path, http.NoBody))
+			require.NoError(t, err)
+			defer resp.Body.Close()
+			require.Equal(t, tt.status, resp.StatusCode)
+
+			var response model.SummaryResponse
+			require.NoError(t, json.NewDecoder(resp.Body).Decode(&response))
+			if tt.status != fiber.StatusOK {
+				require.False(t, response.Success)
+				require.Contains(t, response.Error, "invalid expiry_days")
+				return
+			}
+			require.True(t, response.Success)
+			require.Equal(t, tt.threshold, s.threshold)
+			require.Equal(t, 3, response.Data.Total)
+		})
+	}
+
+	t.Run("ServiceError", func(t *testing.T) {
+		errApp := fiber.New()
+		NewDomainHandler(&serviceinterface.MockErrDomainService{}).RegisterRoutes(errApp.Group("/api/v1"))
+
+		resp, err := errApp.Test(httptest.NewRequest("GET", "/api/v1/summary", http.NoBody))
+		require.NoError(t, err)
+		defer resp.Body.Close()
+		require.Equal(t, fiber.StatusInternalServerError, resp.StatusCode)
+	})
+}
+
+// TestReconcile verifies the reconcile endpoint against a certificate directory with drift.
+func TestReconcile(t *testing.T) {
+	dc := dehydrated.NewConfig().WithBaseDir(t.TempDir()).Load()
+	s := service.NewDomainService(dc, nil)
+	defer s.Close()
+	app := fiber.New()
+	NewDomainHandler(s).RegisterRoutes(app.Group("/api/v1"))
+
+	for _, domain := range []string{"issued.example.com", "missing.example.com"} {
+		_, err := s.CreateDomain(&model.CreateDomainRequest{Domain: domain, Enabled: util.BoolPtr(true)})
+		require.NoError(t, err)
+	}
+	for _, name := range []string{"issued.example.com", "orphan.example.com"} {
+		file, err := dc.CertFile(name)
+		require.NoError(t, err)
+		require.NoError(t, os.MkdirAll(filepath.Dir(file), 0o755))
+		require.NoError(t, os.WriteFile(file, []byte("certificate"), 0o600))
+	}
+
+	resp, err := app.Test(httptest.NewRequest("GET", "/api/v1/reconcile", http.NoBody))
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	require.Equal(t, fiber.StatusOK, resp.StatusCode)
+
+	var response model.ReconciliationResponse
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&response))
+	require.True(t, response.Success)
+	require.Equal(t, &model.Reconciliation{
+		MissingCerts: []string{"missing.example.com"},
+		OrphanCerts:  []string{"orphan.example.com"},
+	}, response.Data)
+
+	t.Run("ServiceError", func(t *testing.T) {
+		errApp := fiber.New()
+		NewDomainHandler(&serviceinterface.MockErrDomainService{}).RegisterRoutes(errApp.Group("/api/v1"))
+
+		resp, err := errApp.Test(httptest.NewRequest("GET", "/api/v1/reconcile", http.NoBody))
+		require.NoError(t, err)
+		defer resp.Body.Close()
+		require.Equal(t, fiber.StatusInternalServerError, resp.StatusCode)
+	})
+}
+
+// TestCacheUnavailable verifies that requests fail with 503 if the domains file was never loaded and cannot be read.
+func TestCacheUnavailable(t *testing.T) {
+	dc := dehydrated.NewConfig().WithBaseDir(t.TempDir()).Load()
+	s := service.NewDomainService(dc, nil)
+	defer s.Close()
+	require.NoError(t, os.Remove(dc.DomainsFile))
+	require.NoError(t, os.Mkdir(dc.DomainsFile, 0o755))
+
+	app := fiber.New()
+	NewDomainHandler(s).RegisterRoutes(app.Group("/api/v1"))
+
+	for _, tt := range []struct{ method, path, body string }{
+		{"GET", "/api/v1/domains", ""},
+		{"GET", "/api/v1/domains/example.com", ""},
+		{"POST", "/api/v1/domains", `{"domain":"example.com"}`},
+		{"DELETE", "/api/v1/domains/example.com", ""},
+		{"GET", "/api/v1/summary", ""},
+	} {
+		req := httptest.NewRequest(tt.method, tt.path, strings.NewReader(tt.body))
+		req.Header.Set("Content-Type", "application/json")
+		resp, err := app.Test(req)
+		require.NoError(t, err)
+		require.Equal(t, fiber.StatusServiceUnavailable, resp.StatusCode, tt.path)
+
+		var response model.ErrorResponse
+		require.NoError(t, json.NewDecoder(resp.Body).Decode(&response))
+		resp.Body.Close()
+		require.False(t, response.Success)
+		require.Equal(t, model.CodeUnavailable, response.Code)
+	}
+}
+
+// TestListFilters verifies the parsing of the enabled and cert_status filters of the list endpoint.
+func TestListFilters(t *testing.T) {
+	s := &recordingDomainService{}
+	app := fiber.New()
+	NewDomainHandler(s).RegisterRoutes(app.Group("/api/v1"))
+
+	tests := []struct {
+		path     string
+		status   int
+		expected serviceinterface.QueryOptions
+	}{
+		{"/api/v1/domains", fiber.StatusOK, serviceinterface.QueryOptions{}},
+		{"/api/v1/domains?enabled=false", fiber.StatusOK, serviceinterface.QueryOptions{Enabled: util.BoolPtr(false)}},
+		{
+			"/api/v1/domains?cert_status=expiring", fiber.StatusOK,
+			serviceinterface.QueryOptions{CertStatus: "expiring", ExpiryThreshold: DefaultExpiryDays * 24 * time.Hour},
+		},
+		{
+			"/api/v1/domains?cert_status=expired&expiry_days=30&enabled=true", fiber.StatusOK,
+			serviceinterface.QueryOptions{Enabled: util.BoolPtr(true), CertStatus: "expired", ExpiryThreshold: 30 * 24 * time.Hour},
+		},
+		{"/api/v1/domains?cert_status=revoked", fiber.StatusBadRequest, serviceinterface.QueryOptions{}},
+		{"/api/v1/domains?cert_status=valid&expiry_days=-1", fiber.StatusBadRequest, serviceinterface.QueryOptions{}},
+		{"/api/v1/domains?enabled=maybe", fiber.StatusBadRequest, serviceinterface.QueryOptions{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			s.opts = serviceinterface.QueryOptions{}
+			resp, err := app.Test(httptest.NewRequest("GET", tt.path, http.NoBody))
+			require.NoError(t, err)
+			resp.Body.Close()
+			require.Equal(t, tt.status, resp.StatusCode)
+			require.Equal(t, tt.expected, s.opts)
+		})
+	}
+}
+
+// TestRefreshOCSP verifies that the OCSP refresh endpoint is only registered if enabled and requires the writer role.
+func TestRefreshOCSP(t *testing.T) {
+	refresh := func(t *testing.T, app *fiber.App) (int, model.OCSPResponse) {
+		t.Helper()
+		resp, err := app.Test(httptest.NewRequest("POST", "/api/v1/domains/example.com/ocsp/refresh", http.NoBody))
+		require.NoError(t, err)
+		defer resp.Body.Close()
+
+		var response model.OCSPResponse
+		_ = json.NewDecoder(resp.Body).Decode(&response)
+		return resp.StatusCode, response
+	}
+
+	t.Run("Disabled", func(t *testing.T) {
+		app := fiber.New()
+		NewDomainHandler(&serviceinterface.MockDomainService{}).RegisterRoutes(app.Group("/api/v1"))
+
+		status, _ := refresh(t, app)
+		require.Equal(t, fiber.StatusNotFound, status)
+	})
+
+	t.Run("Enabled", func(t *testing.T) {
+		app := fiber.New()
+		NewDomainHandler(&serviceinterface.MockDomainService{}).WithOCSPRefresh(true).RegisterRoutes(app.Group("/api/v1"))
+
+		status, response := refresh(t, app)
+		require.Equal(t, fiber.StatusOK, status)
+		require.True(t, response.Success)
+		require.Equal(t, "good", response.Data.Status)
+	})
+
+	t.Run("ServiceError", func(t *testing.T) {
+		app := fiber.New()
+		NewDomainHandler(&serviceinterface.MockErrDomainService{}).WithOCSPRefresh(true).RegisterRoutes(app.Group("/api/v1"))
+
+		status, response := refresh(t, app)
+		require.Equal(t, fiber.StatusInternalServerError, status)
+		require.False(t, response.Success)
+	})
+
+	t.Run("DomainNotFound", func(t *testing.T) {
+		dc := dehydrated.NewConfig().WithBaseDir(t.TempDir()).Load()
+		s := service.NewDomainService(dc, nil)
+		defer s.Close()
+
+		app := fiber.New()
+		NewDomainHandler(s).WithOCSPRefresh(true).RegisterRoutes(app.Group("/api/v1"))
+
+		status, _ := refresh(t, app)
+		require.Equal(t, fiber.StatusNotFound, status)
+	})
+
+	t.Run("DomainDisabled", func(t *testing.T) {
+		dc := dehydrated.NewConfig().WithBaseDir(t.TempDir()).Load()
+		s := service.NewDomainService(dc, nil).WithDehydratedScript("true")
+		defer s.Close()
+		_, err := s.CreateDomain(&model.CreateDomainRequest{Domain: "example.com", Enabled: util.BoolPtr(false)})
+		require.NoError(t, err)
+
+		app := fiber.New()
+		NewDomainHandler(s).WithOCSPRefresh(true).RegisterRoutes(app.Group("/api/v1"))
+
+		status, response := refresh(t, app)
+		require.Equal(t, fiber.StatusConflict, status)
+		require.Equal(t, model.CodeDomainDisabled, response.Code)
+		require.Contains(t, response.Error, "disabled")
+
+		// With the override dehydrated runs, there is no OCSP response afterwards though
+		resp, err := app.Test(httptest.NewRequest("POST", "/api/v1/domains/example.com/ocsp/refresh?allow_disabled=true", http.NoBody))
+		require.NoError(t, err)
+		resp.Body.Close()
+		require.Equal(t, fiber.StatusNotFound, resp.StatusCode)
+
+		resp, err = app.Test(httptest.NewRequest("POST", "/api/v1/domains/example.com/ocsp/refresh?allow_disabled=maybe", http.NoBody))
+		require.NoError(t, err)
+		resp.Body.Close()
+		require.Equal(t, fiber.StatusBadRequest, resp.StatusCode)
+	})
+
+	t.Run("Force", func(t *testing.T) {
+		dc := dehydrated.NewConfig().WithBaseDir(t.TempDir()).Load()
+		s := service.NewDomainService(dc, nil).WithDehydratedScript("true")
+		defer s.Close()
+		_, err := s.CreateDomain(&model.CreateDomainRequest{Domain: "example.com", Enabled: util.BoolPtr(true)})
+		require.NoError(t, err)
+
+		app := fiber.New()
+		NewDomainHandler(s).WithOCSPRefresh(true).RegisterRoutes(app.Group("/api/v1"))
+
+		for query, want := range map[string]int{
+			"force=true&force_validation=true": fiber.StatusNotFound, // dehydrated ran, but there is no OCSP response
+			"force_validation=true":            fiber.StatusBadRequest,
+			"force=maybe":                      fiber.StatusBadRequest,
+			"force_validation=maybe":           fiber.StatusBadRequest,
+		} {
+			resp, err := app.Test(httptest.NewRequest("POST", "/api/v1/domains/example.com/ocsp/refresh?"+query, http.NoBody))
+			require.NoError(t, err)
+			resp.Body.Close()
+			require.Equal(t, want, resp.StatusCode, query)
+		}
+	})
+
+	t.Run("MissingWriterRole", func(t *testing.T) {
+		app := fiber.New()
+		g := app.Group("/api/v1", func(c *fiber.Ctx) error {
+			c.Locals("claims", jwt.MapClaims{"roles": []any{"reader"}})
+			return c.Next()
+		})
+		NewDomainHandler(&serviceinterface.MockDomainService{}).WithOCSPRefresh(true).RegisterRoutes(g)
+
+		status, _ := refresh(t, app)
+		require.Equal(t, fiber.StatusForbidden, status)
+	})
+}
+
+// TestPluginErrors verifies the plugin errors report and its error handling.
+func TestPluginErrors(t *testing.T) {
+	get := func(t *testing.T, s serviceinterface.DomainService, query string) (int, model.PaginatedPluginErrorsResponse) {
+		app := fiber.New()
+		NewDomainHandler(s).RegisterRoutes(app.Group("/api/v1"))
+
+		resp, err := app.Test(httptest.NewRequest("GET", "/api/v1/plugins/errors"+query, http.NoBody))
+		require.NoError(t, err)
+		defer resp.Body.Close()
+
+		var response model.PaginatedPluginErrorsResponse
+		require.NoError(t, json.NewDecoder(resp.Body).Decode(&response))
+		return resp.StatusCode, response
+	}
+
+	status, response := get(t, &serviceinterface.MockDomainService{}, "?page=2&per_page=5000")
+	require.Equal(t, fiber.StatusOK, status)
+	require.True(t, response.Success)
+	require.Equal(t, 2, response.Pagination.CurrentPage)
+	require.Equal(t, model.MaxPerPage, response.Pagination.PerPage)
+
+	status, _ = get(t, &serviceinterface.MockDomainService{}, "?page=0")
+	require.Equal(t, fiber.StatusBadRequest, status)
+
+	status, response = get(t, &serviceinterface.MockErrDomainService{}, "")
+	require.Equal(t, fiber.StatusInternalServerError, status)
+	require.False(t, response.Success)
+}
+
+// strictDomainService fails strict ListDomains and GetDomain calls with a plugin failure.
+type strictDomainService struct {
+	serviceinterface.MockDomainService
+}
+
+func (m *strictDomainService) failure(opts []serviceinterface.QueryOption) error {
+	if !serviceinterface.NewQueryOptions(opts...).StrictPlugins {
+		return nil
+	}
+	return &serviceinterface.PluginFailureError{Errors: []*model.PluginError{
+		{Domain: "example.com", Plugin: "failing", Message: "lookup failed"},
+	}}
+}
+
+func (m *strictDomainService) ListDomains(page, perPage int, sortOrder, search string, opts ...serviceinterface.QueryOption) ([]*model.DomainEntry, *model.PaginationInfo, error) {
+	if err := m.failure(opts); err != nil {
+		return nil, nil, err
+	}
+	return m.MockDomainService.ListDomains(page, perPage, sortOrder, search)
+}
+
+func (m *strictDomainService) GetDomain(domain, alias string, opts ...serviceinterface.QueryOption) (*model.DomainEntry, error) {
+	if err := m.failure(opts); err != nil {
+		return nil, err
+	}
+	return m.MockDomainService.GetDomain(domain, alias)
+}
+
+// TestStrictPlugins verifies the strict parameter and its configured default.
+func TestStrictPlugins(t *testing.T) {
+	tests := []struct {
+		name   string
+		strict bool
+		query  string
+		status int
+	}{
+		{"Lenient", false, "", fiber.StatusOK},
+		{"StrictParam", false, "?strict=true", fiber.StatusBadGateway},
+		{"StrictDefault", true, "", fiber.StatusBadGateway},
+		{"LenientParam", true, "?strict=false", fiber.StatusOK},
+		{"InvalidParam", false, "?strict=maybe", fiber.StatusBadRequest},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			app := fiber.New()
+			NewDomainHandler(&strictDomainService{}).WithStrictPlugins(tt.strict).RegisterRoutes(app.Group("/api/v1"))
+
+			for _, path := range []string{"/api/v1/domains", "/api/v1/domains/example.com"} {
+				resp, err := app.Test(httptest.NewRequest("GET", path+tt.query, http.NoBody))
+				require.NoError(t, err)
+				defer resp.Body.Close()
+				require.Equal(t, tt.status, resp.StatusCode, path)
+
+				var response struct {
+					Success      bool                 `json:"success"`
+					Error        string               `json:"error"`
+					PluginErrors []*model.PluginError `json:"plugin_errors"`
+				}
+				require.NoError(t, json.NewDecoder(resp.Body).Decode(&response))
+				require.Equal(t, tt.status == fiber.StatusOK, response.Success)
+				if tt.status == fiber.StatusBadGateway {
+					require.Contains(t, response.Error, "plugin failed")
+					require.Len(t, response.PluginErrors, 1)
+					require.Equal(t, "failing", response.PluginErrors[0].Plugin)
+				}
+			}
+		})
+	}
+}
+
+// TestEnrichDisabled verifies the enrich_disabled parameter and its configured default.
+func TestEnrichDisabled(t *testing.T) {
+	tests := []struct {
+		name   string
+		skip   bool
+		query  string
+		status int
+		skips  bool
+	}{
+		{"Default", false, "", fiber.StatusOK, false},
+		{"SkipParam", false, "?enrich_disabled=false", fiber.StatusOK, true},
+		{"SkipDefault", true, "", fiber.StatusOK, true},
+		{"EnrichParam", true, "?enrich_disabled=true", fiber.StatusOK, false},
+		{"InvalidParam", false, "?enrich_disabled=maybe", fiber.StatusBadRequest, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := &recordingDomainService{}
+			app := fiber.New()
+			NewDomainHandler(s).WithSkipDisabledMetadata(tt.skip).RegisterRoutes(app.Group("/api/v1"))
+
+			for _, path := range []string{"/api/v1/domains", "/api/v1/domains/example.com"} {
+				s.opts = serviceinterface.QueryOptions{}
+				resp, err := app.Test(httptest.NewRequest("GET", path+tt.query, http.NoBody))
+				require.NoError(t, err)
+				resp.Body.Close()
+				require.Equal(t, tt.status, resp.StatusCode, path)
+				require.Equal(t, tt.skips, s.opts.SkipDisabledMetadata, path)
+			}
+		})
+	}
+}
+
+// TestExplain verifies that the explain parameter requests the provenance of the metadata.
+func TestExplain(t *testing.T) {
+	tests := []struct {
+		name    string
+		query   string
+		status  int
+		explain bool
+	}{
+		{"Default", "", fiber.StatusOK, false},
+		{"Explain", "?explain=true", fiber.StatusOK, true},
+		{"NoExplain", "?explain=false", fiber.StatusOK, false},
+		{"InvalidParam", "?explain=maybe", fiber.StatusBadRequest, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := &recordingDomainService{}
+			app := fiber.New()
+			NewDomainHandler(s).RegisterRoutes(app.Group("/api/v1"))
+
+			for _, path := range []string{"/api/v1/domains/example.com", "/api/v1/domains/example.com/aliases/rsa"} {
+				s.opts = serviceinterface.QueryOptions{}
+				resp, err := app.Test(httptest.NewRequest("GET", path+tt.query, http.NoBody))
+				require.NoError(t, err)
+				resp.Body.Close()
+				require.Equal(t, tt.status, resp.StatusCode, path)
+				require.Equal(t, tt.explain, s.opts.Explain, path)
+			}
+		})
+	}
+}
+
+// TestTimeBudget verifies that listing domains returns partial metadata once the time budget, configured or
+// from the X-Timeout header, is exceeded.
+func TestTimeBudget(t *testing.T) {
+	dc := dehydrated.NewConfig().WithBaseDir(t.TempDir()).Load()
+	s := service.NewDomainService(dc, &serviceinterface.MockPluginRegistry{
+		Clients: map[string]*serviceinterface.MockPlugin{"slow": {Delay: time.Second}},
+	})
+	t.Cleanup(func() { _ = s.Close() })
+	for _, domain := range []string{"example.com", "example.org"} {
+		_, err := s.CreateDomain(&model.CreateDomainRequest{Domain: domain, Enabled: util.BoolPtr(true)})
+		require.NoError(t, err)
+	}
+
+	tests := []struct {
+		name     string
+		budget   time.Duration
+		header   string
+		status   int
+		timedOut bool
+	}{
+		{"Default", 50 * time.Millisecond, "", fiber.StatusOK, true},
+		{"Header", 0, "50ms", fiber.StatusOK, true},
+		{"HeaderOverridesDefault", time.Minute, "50ms", fiber.StatusOK, true},
+		{"HeaderCappedAtDefault", 50 * time.Millisecond, "1000h", fiber.StatusOK, true},
+		{"InvalidHeader", 0, "soon", fiber.StatusBadRequest, false},
+		{"NegativeHeader", 0, "-1s", fiber.StatusBadRequest, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			app := fiber.New()
+			NewDomainHandler(s).WithTimeBudget(tt.budget).RegisterRoutes(app.Group("/api/v1"))
+
+			req := httptest.NewRequest("GET", "/api/v1/domains", http.NoBody)
+			if tt.header != "" {
+				req.Header.Set("X-Timeout", tt.header)
+			}
+			start := time.Now()
+			resp, err := app.Test(req, -1)
+			require.NoError(t, err)
+			defer resp.Body.Close()
+			require.Equal(t, tt.status, resp.StatusCode)
+			require.Less(t, time.Since(start), 500*time.Millisecond)
+
+			var response model.PaginatedDomainsResponse
+			require.NoError(t, json.NewDecoder(resp.Body).Decode(&response))
+			require.Equal(t, tt.timedOut, response.TimedOut)
+			require.Equal(t, tt.timedOut, response.Partial)
+			if tt.timedOut {
+				require.True(t, response.Success)
+				require.Len(t, response.Data, 2)
+			}
+		})
+	}
+
+	t.Run("Bare", func(t *testing.T) {
+		app := fiber.New()
+		NewDomainHandler(s).WithTimeBudget(50 * time.Millisecond).RegisterRoutes(app.Group("/api/v1"))
+
+		req := httptest.NewRequest("GET", "/api/v1/domains", http.NoBody)
+		req.Header.Set(fiber.HeaderAccept, "application/json; envelope=false")
+		resp, err := app.Test(req, -1)
+		require.NoError(t, err)
+		defer resp.Body.Close()
+		require.Equal(t, fiber.StatusOK, resp.StatusCode)
+		require.Equal(t, "true", resp.Header.Get("X-Timed-Out"))
+
+		var entries []*model.DomainEntry
+		require.NoError(t, json.NewDecoder(resp.Body).Decode(&entries))
+		require.Len(t, entries, 2)
+	})
+}
+
+// vetoDomainService fails mutations with err.
+type vetoDomainService struct {
+	serviceinterface.MockDomainService
+	err error
+}
+
+func (m *vetoDomainService) CreateDomain(_ *model.CreateDomainRequest) (*model.DomainEntry, error) {
+	return nil, m.err
+}
+
+func (m *vetoDomainService) UpdateDomain(_ string, _ model.UpdateDomainRequest) (*model.DomainEntry, error) {
+	return nil, m.err
+}
+
+// TestPluginValidationErrors verifies the status codes of mutations rejected by plugin validation.
+func TestPluginValidationErrors(t *testing.T) {
+	tests := []struct {
+		name   string
+		err    error
+		status int
+	}{
+		{"Rejected", fmt.Errorf("%w: rejected by plugin veto: not in inventory", serviceinterface.ErrInvalidDomainEntry), fiber.StatusUnprocessableEntity},
+		{"PluginFailed", fmt.Errorf("%w: veto for example.com: unavailable", serviceinterface.ErrPluginFailed), fiber.StatusBadGateway},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			app := fiber.New()
+			NewDomainHandler(&vetoDomainService{err: tt.err}).RegisterRoutes(app.Group("/api/v1"))
+
+			for _, method := range []string{"POST", "PUT"} {
+				path := "/api/v1/domains"
+				if method == "PUT" {
+					path += "/example.com"
+				}
+				req := httptest.NewRequest(method, path, strings.NewReader(`{"domain":"example.com"}`))
+				req.Header.Set("Content-Type", "application/json")
+				resp, err := app.Test(req)
+				require.NoError(t, err)
+				defer resp.Body.Close()
+				require.Equal(t, tt.status, resp.StatusCode, method)
+
+				var response model.DomainResponse
+				require.NoError(t, json.NewDecoder(resp.Body).Decode(&response))
+				require.Equal(t, tt.err.Error(), response.Error)
+			}
+		})
+	}
+}
+
+// TestPreviewDomain verifies the rendered domains file line and that nothing is written.
+func TestPreviewDomain(t *testing.T) {
+	dc := dehydrated.NewConfig().WithBaseDir(t.TempDir()).Load()
+	newApp := func(marker string) *fiber.App {
+		s := service.NewDomainService(dc, nil).WithCommentMarker(marker)
+		t.Cleanup(func() { _ = s.Close() })
+		app := fiber.New()
+		NewDomainHandler(s).RegisterRoutes(app.Group("/api/v1"))
+		return app
+	}
+
+	tests := []struct {
+		name     string
+		marker   string
+		req      model.CreateDomainRequest
+		status   int
+		expected string
+	}{
+		{"Domain", "", model.CreateDomainRequest{Domain: "example.com", Enabled: util.BoolPtr(true)}, fiber.StatusOK, "example.com"},
+		{
+			"AlternativeNames", "", model.CreateDomainRequest{Domain: "example.com", AlternativeNames: []string{"www.example.com", "api.example.com"}, Enabled: util.BoolPtr(true)},
+			fiber.StatusOK, "example.com www.example.com api.example.com",
+		},
+		{"Alias", "", model.CreateDomainRequest{Domain: "example.com", Alias: " cert ", Enabled: util.BoolPtr(true)}, fiber.StatusOK, "example.com > cert"},
+		{"Comment", "", model.CreateDomainRequest{Domain: "example.com", Comment: "Production", Enabled: util.BoolPtr(true)}, fiber.StatusOK, "example.com # Production"},
+		{"CommentMarker", "[api]", model.CreateDomainRequest{Domain: "example.com", Comment: "Production", Enabled: util.BoolPtr(true)}, fiber.StatusOK, "example.com # [api] Production"},
+		{
+			"Disabled", "", model.CreateDomainRequest{Domain: "example.com", AlternativeNames: []string{"www.example.com"}, Alias: "cert", Comment: "Staging"},
+			fiber.StatusOK, "# example.com www.example.com > cert # Staging",
+		},
+		{"InvalidAlias", "", model.CreateDomainRequest{Domain: "example.com", Alias: "a # b"}, fiber.StatusUnprocessableEntity, ""},
+		{"InvalidDomain", "", model.CreateDomainRequest{Domain: "not a domain"}, fiber.StatusUnprocessableEntity, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			body, err := json.Marshal(tt.req)
+			require.NoError(t, err)
+			req := httptest.NewRequest("POST", "/api/v1/domains/preview", bytes.NewReader(body))
+			req.Header.Set("Content-Type", "application/json")
+			resp, err := newApp(tt.marker).Test(req)
+			require.NoError(t, err)
+			defer resp.Body.Close()
+			require.Equal(t, tt.status, resp.StatusCode)
+
+			var response model.DomainPreviewResponse
+			require.NoError(t, json.NewDecoder(resp.Body).Decode(&response))
+			if tt.status != fiber.StatusOK {
+				require.False(t, response.Success)
+				require.NotEmpty(t, response.Error)
+				return
+			}
+			require.True(t, response.Success)
+			require.Equal(t, tt.expected, response.Data.Line)
+		})
+	}
+
+	// Nothing is written
+	entries, err := service.ReadDomainsFile(dc.DomainsFile)
+	require.NoError(t, err)
+	require.Empty(t, entries)
+}
+
+// TestCreateDomainDefaultEnabled verifies that a create request omitting enabled gets the
+// configured default, while an explicit enabled is kept.
+func TestCreateDomainDefaultEnabled(t *testing.T) {
+	tests := []struct {
+		name           string
+		defaultEnabled bool
+		body           string
+		expected       bool
+	}{
+		{"OmittedDefaultDisabled", false, `{"domain":"example.com"}`, false},
+		{"OmittedDefaultEnabled", true, `{"domain":"example.com"}`, true},
+		{"ExplicitEnabled", false, `{"domain":"example.com","enabled":true}`, true},
+		{"ExplicitDisabled", true, `{"domain":"example.com","enabled":false}`, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dc := dehydrated.NewConfig().WithBaseDir(t.TempDir()).Load()
+			s := service.NewDomainService(dc, nil).WithDefaultEnabled(tt.defaultEnabled)
+			defer s.Close()
+			app := fiber.New()
+			NewDomainHandler(s).RegisterRoutes(app.Group("/api/v1"))
+
+			req := httptest.NewRequest("POST", "/api/v1/domains", strings.NewReader(tt.body))
+			req.Header.Set("Content-Type", "application/json")
+			resp, err := app.Test(req)
+			require.NoError(t, err)
+			defer resp.Body.Close()
+			require.Equal(t, fiber.StatusCreated, resp.StatusCode)
+
+			var response model.DomainResponse
+			require.NoError(t, json.NewDecoder(resp.Body).Decode(&response))
+			require.True(t, response.Success)
+			require.Equal(t, tt.expected, response.Data.Enabled)
+
+			entries, err := service.ReadDomainsFile(dc.DomainsFile)
+			require.NoError(t, err)
+			require.Len(t, entries, 1)
+			require.Equal(t, tt.expected, entries[0].Enabled)
+		})
+	}
+}
+
+// TestCommentTemplate verifies that entries created without a comment get the rendered comment template,
+// with the actor from the token and the current date.
+func TestCommentTemplate(t *testing.T) {
+	tests := []struct {
+		name     string
+		claims   jwt.MapClaims
+		body     string
+		expected string
+	}{
+		{"Actor", jwt.MapClaims{"sub": "alice", "roles": []any{"writer"}}, `{"domain":"example.com"}`, "created {today} by alice via api for example.com"},
+		{"Anonymous", nil, `{"domain":"example.com"}`, "created {today} by anonymous via api for example.com"},
+		{"ControlCharacters", jwt.MapClaims{"sub": "eve\nexample.org", "roles": []any{"writer"}}, `{"domain":"example.com"}`, "created {today} by eveexample.org via api for example.com"},
+		{"ExplicitComment", jwt.MapClaims{"sub": "alice", "roles": []any{"writer"}}, `{"domain":"example.com","comment":"Production"}`, "Production"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dc := dehydrated.NewConfig().WithBaseDir(t.TempDir()).Load()
+			s := service.NewDomainService(dc, nil)
+			defer s.Close()
+			app := fiber.New()
+			app.Use(func(c *fiber.Ctx) error {
+				if tt.claims != nil {
+					c.Locals("claims", tt.claims)
+				}
+				return c.Next()
+			})
+			NewDomainHandler(s).WithCommentTemplate("created {date} by {actor} via api for {domain}").
+				RegisterRoutes(app.Group("/api/v1"))
+
+			today := time.Now().UTC().Format(time.DateOnly)
+			req := httptest.NewRequest("POST", "/api/v1/domains", strings.NewReader(tt.body))
+			req.Header.Set("Content-Type", "application/json")
+			resp, err := app.Test(req)
+			require.NoError(t, err)
+			defer resp.Body.Close()
+			require.Equal(t, fiber.StatusCreated, resp.StatusCode)
+
+			expected := strings.ReplaceAll(tt.expected, "{today}", today)
+			var response model.DomainResponse
+			require.NoError(t, json.NewDecoder(resp.Body).Decode(&response))
+			require.Equal(t, expected, response.Data.Comment)
+
+			entries, err := service.ReadDomainsFile(dc.DomainsFile)
+			require.NoError(t, err)
+			require.Len(t, entries, 1)
+			require.Equal(t, expected, entries[0].Comment)
+		})
+	}
+}
+
+// TestEffectiveConfig verifies that the config file of an entry's certificate, stored under its alias
+// if set, overrides the global configuration of that entry only.
+func TestEffectiveConfig(t *testing.T) {
+	dc := dehydrated.NewConfig().WithBaseDir(t.TempDir()).Load()
+	certDir := filepath.Join(dc.CertDir, "cert")
+	require.NoError(t, os.MkdirAll(certDir, 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(certDir, "config"), []byte("KEY_ALGO=prime256v1\n"), 0644))
+
+	s := service.NewDomainService(dc, nil)
+	t.Cleanup(func() { _ = s.Close() })
+	for _, req := range []*model.CreateDomainRequest{
+		{Domain: "example.com", Enabled: util.BoolPtr(true)},
+		{Domain: "example.com", Alias: "cert", Enabled: util.BoolPtr(true)},
+	} {
+		_, err := s.CreateDomain(req)
+		require.NoError(t, err)
+	}
+
+	app := fiber.New()
+	NewDomainHandler(s).RegisterRoutes(app.Group("/api/v1"))
+
+	tests := []struct {
+		name    string
+		url     string
+		status  int
+		keyAlgo string
+	}{
+		{"Global", "/api/v1/domains/example.com/effective-config", fiber.StatusOK, "rsa"},
+		{"Alias", "/api/v1/domains/example.com/effective-config?alias=cert", fiber.StatusOK, "prime256v1"},
+		{"NotFound", "/api/v1/domains/example.org/effective-config", fiber.StatusNotFound, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp, err := app.Test(httptest.NewRequest("GET", tt.url, http.NoBody))
+			require.NoError(t, err)
+			defer resp.Body.Close()
+			require.Equal(t, tt.status, resp.StatusCode)
+
+			var response model.ConfigResponse
+			require.NoError(t, json.NewDecoder(resp.Body).Decode(&response))
+			if tt.status != fiber.StatusOK {
+				require.False(t, response.Success)
+				require.NotEmpty(t, response.Error)
+				return
+			}
+			require.True(t, response.Success)
+			require.Equal(t, tt.keyAlgo, response.Data.KeyAlgo)
+			require.Equal(t, dc.DomainsFile, response.Data.DomainsFile)
+		})
+	}
+
+	// The override does not leak into the global configuration
+	require.Equal(t, "rsa", dc.KeyAlgo)
+}
+
+// TestChangeSeqHeader verifies that list and get responses expose the change sequence, which increases
+// with changes and is stable across reads.
+func TestChangeSeqHeader(t *testing.T) {
+	dc := dehydrated.NewConfig().WithBaseDir(t.TempDir()).Load()
+	s := service.NewDomainService(dc, nil)
+	t.Cleanup(func() { _ = s.Close() })
+	_, err := s.CreateDomain(&model.CreateDomainRequest{Domain: "example.com"})
+	require.NoError(t, err)
+
+	app := fiber.New()
+	NewDomainHandler(s).RegisterRoutes(app.Group("/api/v1"))
+
+	changeSeq := func(t *testing.T, path string) uint64 {
+		t.Helper()
+		resp, err := app.Test(httptest.NewRequest("GET", path, http.NoBody))
+		require.NoError(t, err)
+		defer resp.Body.Close()
+		require.Equal(t, fiber.StatusOK, resp.StatusCode)
+
+		seq, err := strconv.ParseUint(resp.Header.Get("X-Change-Seq"), 10, 64)
+		require.NoError(t, err)
+		return seq
+	}
+
+	list := changeSeq(t, "/api/v1/domains")
+	require.Positive(t, list)
+	require.Equal(t, list, changeSeq(t, "/api/v1/domains"))
+	require.Equal(t, list, changeSeq(t, "/api/v1/domains/example.com"))
+
+	_, err = s.CreateDomain(&model.CreateDomainRequest{Domain: "example.com", Alias: "cert"})
+	require.NoError(t, err)
+	require.Greater(t, changeSeq(t, "/api/v1/domains"), list)
+	require.Equal(t, changeSeq(t, "/api/v1/domains"), changeSeq(t, "/api/v1/domains/example.com/aliases/cert"))
+}
+
+// TestCreateDomainLocation verifies that the Location header of a create resolves to the created entry.
+func TestCreateDomainLocation(t *testing.T) {
+	dc := dehydrated.NewConfig().WithBaseDir(t.TempDir()).Load()
+	s := service.NewDomainService(dc, nil)
+	t.Cleanup(func() { _ = s.Close() })
+
+	app := fiber.New()
+	NewDomainHandler(s).RegisterRoutes(app.Group("/api/v1"))
+
+	tests := []struct {
+		name     string
+		body     string
+		alias    string
+		location string
+	}{
+		{"WithoutAlias", `{"domain":"example.com"}`, "", "http://example.com/api/v1/domains/example.com"},
+		{"WithAlias", `{"domain":"example.com","alias":"cert"}`, "cert", "http://example.com/api/v1/domains/example.com?alias=cert"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest("POST", "/api/v1/domains", strings.NewReader(tt.body))
+			req.Header.Set("Content-Type", "application/json")
+			resp, err := app.Test(req)
+			require.NoError(t, err)
+			resp.Body.Close()
+			require.Equal(t, fiber.StatusCreated, resp.StatusCode)
+			location := resp.Header.Get(fiber.HeaderLocation)
+			require.Equal(t, tt.location, location)
+
+			// The location resolves to the created entry
+			resp, err = app.Test(httptest.NewRequest("GET", location, http.NoBody))
+			require.NoError(t, err)
+			defer resp.Body.Close()
+			require.Equal(t, fiber.StatusOK, resp.StatusCode)
+
+			var response model.DomainResponse
+			require.NoError(t, json.NewDecoder(resp.Body).Decode(&response))
+			require.Equal(t, "example.com", response.Data.Domain)
+			require.Equal(t, tt.alias, response.Data.Alias)
+		})
+	}
+
+	t.Run("PublicBaseURL", func(t *testing.T) {
+		app := fiber.New()
+		NewDomainHandler(s).WithBaseURL("https://example.org/acme").RegisterRoutes(app.Group("/api/v1"))
+
+		req := httptest.NewRequest("POST", "/api/v1/domains", strings.NewReader(`{"domain":"example.net"}`))
+		req.Header.Set("Content-Type", "application/json")
+		resp, err := app.Test(req)
+		require.NoError(t, err)
+		defer resp.Body.Close()
+		require.Equal(t, fiber.StatusCreated, resp.StatusCode)
+		require.Equal(t, "https://example.org/acme/api/v1/domains/example.net", resp.Header.Get(fiber.HeaderLocation))
+	})
+
+	t.Run("Conflict", func(t *testing.T) {
+		req := httptest.NewRequest("POST", "/api/v1/domains", strings.NewReader(`{"domain":"example.com"}`))
+		req.Header.Set("Content-Type", "application/json")
+		resp, err := app.Test(req)
+		require.NoError(t, err)
+		defer resp.Body.Close()
+		require.Equal(t, fiber.StatusConflict, resp.StatusCode)
+		require.Empty(t, resp.Header.Get(fiber.HeaderLocation))
+	})
+}
+
+// TestKeyFingerprint verifies that the fingerprint of the public key is returned without the key material.
+func TestKeyFingerprint(t *testing.T) {
+	dc := dehydrated.NewConfig().WithBaseDir(t.TempDir()).Load()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	der, err := x509.MarshalECPrivateKey(key)
+	require.NoError(t, err)
+	keyFile, err := dc.KeyFile("example.com")
+	require.NoError(t, err)
+	testutil.WritePEM(t, keyFile, "EC PRIVATE KEY", der)
+
+	s := service.NewDomainService(dc, nil)
+	t.Cleanup(func() { _ = s.Close() })
+	for _, req := range []*model.CreateDomainRequest{
+		{Domain: "example.com", Enabled: util.BoolPtr(true)},
+		{Domain: "example.org", Enabled: util.BoolPtr(true)},
+	} {
+		_, err := s.CreateDomain(req)
+		require.NoError(t, err)
+	}
+
+	app := fiber.New()
+	NewDomainHandler(s).RegisterRoutes(app.Group("/api/v1"))
+
+	tests := []struct {
+		name   string
+		url    string
+		status int
+	}{
+		{"Found", "/api/v1/domains/example.com/key/fingerprint", fiber.StatusOK},
+		{"KeyNotFound", "/api/v1/domains/example.org/key/fingerprint", fiber.StatusNotFound},
+		{"DomainNotFound", "/api/v1/domains/example.net/key/fingerprint", fiber.StatusNotFound},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp, err := app.Test(httptest.NewRequest("GET", tt.url, http.NoBody))
+			require.NoError(t, err)
+			defer resp.Body.Close()
+			require.Equal(t, tt.status, resp.StatusCode)
+
+			body, err := io.ReadAll(resp.Body)
+			require.NoError(t, err)
+			require.NotContains(t, string(body), "PRIVATE KEY")
+
+			var response model.KeyFingerprintResponse
+			require.NoError(t, json.Unmarshal(body, &response))
+			if tt.status != fiber.StatusOK {
+				require.False(t, response.Success)
+				require.Equal(t, model.CodeNotFound, response.Code)
+				return
+			}
+			require.True(t, response.Success)
+			require.Equal(t, "ECDSA", response.Data.Algorithm)
+			require.Equal(t, 256, response.Data.Size)
+			require.Len(t, response.Data.SHA256, 64)
+			require.Equal(t, "privkey.pem", response.Data.Source)
+		})
+	}
+}
+
+// TestEntryLimit verifies that creates beyond the maximum number of entries are rejected with 507.
+func TestEntryLimit(t *testing.T) {
+	dc := dehydrated.NewConfig().WithBaseDir(t.TempDir()).Load()
+	s := service.NewDomainService(dc, nil).WithEntryLimits(0, 1)
+	t.Cleanup(func() { _ = s.Close() })
+
+	app := fiber.New()
+	NewDomainHandler(s).RegisterRoutes(app.Group("/api/v1"))
+
+	send := func(method, path, body string) (int, model.DomainResponse) {
+		req := httptest.NewRequest(method, path, strings.NewReader(body))
+		req.Header.Set(fiber.HeaderContentType, fiber.MIMEApplicationJSON)
+		resp, err := app.Test(req)
+		require.NoError(t, err)
+		defer resp.Body.Close()
+
+		var response model.DomainResponse
+		require.NoError(t, json.NewDecoder(resp.Body).Decode(&response))
+		return resp.StatusCode, response
+	}
+
+	status, _ := send("POST", "/api/v1/domains", `{"domain": "a.example.com"}`)
+	require.Equal(t, fiber.StatusCreated, status)
+
+	status, response := send("POST", "/api/v1/domains", `{"domain": "b.example.com"}`)
+	require.Equal(t, fiber.StatusInsufficientStorage, status)
+	require.False(t, response.Success)
+	require.Contains(t, response.Error, "too many domain entries")
+
+	status, _ = send("PUT", "/api/v1/domains/b.example.com?upsert=true", `{"enabled": true}`)
+	require.Equal(t, fiber.StatusInsufficientStorage, status)
+
+	status, _ = send("PUT", "/api/v1/domains/a.example.com?upsert=true", `{"enabled": true}`)
+	require.Equal(t, fiber.StatusOK, status)
+}
+
+// TestValidationStatus verifies that malformed requests are rejected with 400 and well-formed but invalid
+// entries with 422, carrying the invalid field.
+func TestValidationStatus(t *testing.T) {
+	dc := dehydrated.NewConfig().WithBaseDir(t.TempDir()).Load()
+	s := service.NewDomainService(dc, nil)
+	t.Cleanup(func() { _ = s.Close() })
+	_, err := s.CreateDomain(&model.CreateDomainRequest{Domain: "example.com", Enabled: util.BoolPtr(true)})
+	require.NoError(t, err)
+
+	app := fiber.New()
+	NewDomainHandler(s).RegisterRoutes(app.Group("/api/v1"))
+
+	tests := []struct {
+		name         string
+		method, path string
+		body         string
+		status       int
+		code, field  string
+	}{
+		{"MalformedJSON", "POST", "/api/v1/domains", `{"domain": `, fiber.StatusBadRequest, model.CodeValidationFailed, ""},
+		{"InvalidHostname", "POST", "/api/v1/domains", `{"domain": "-invalid-.com"}`, fiber.StatusUnprocessableEntity, model.CodeInvalidDomain, "domain"},
+		{"DuplicateAlternativeNames", "POST", "/api/v1/domains", `{"domain": "example.org", "alternative_names": ["www.example.org", "www.example.org"]}`,
+			fiber.StatusUnprocessableEntity, model.CodeInvalidDomain, "alternative_names"},
+		{"InvalidAlternativeName", "POST", "/api/v1/domains/preview", `{"domain": "example.org", "alternative_names": ["not a domain"]}`,
+			fiber.StatusUnprocessableEntity, model.CodeInvalidDomain, "alternative_names"},
+		{"InvalidComment", "PUT", "/api/v1/domains/example.com", `{"comment": "a\nb"}`, fiber.StatusUnprocessableEntity, model.CodeInvalidDomain, "comment"},
+		{"InvalidAlias", "PUT", "/api/v1/domains/example.org?upsert=true", `{"alias": "a b"}`, fiber.StatusUnprocessableEntity, model.CodeInvalidDomain, "alias"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(tt.method, tt.path, strings.NewReader(tt.body))
+			req.Header.Set("Content-Type", "application/json")
+			resp, err := app.Test(req)
+			require.NoError(t, err)
+			defer resp.Body.Close()
+
+			var response model.DomainResponse
+			require.NoError(t, json.NewDecoder(resp.Body).Decode(&response))
+			require.Equal(t, tt.status, resp.StatusCode, response.Error)
+			require.Equal(t, tt.code, response.Code)
+			require.Equal(t, tt.field, response.Field)
+		})
+	}
+}
+
+// TestRawDomainLine verifies that the raw line of an entry is returned with its parsed components,
+// including tokens that have no representation in the entry.
+func TestRawDomainLine(t *testing.T) {
+	dc := dehydrated.NewConfig().WithBaseDir(t.TempDir()).Load()
+	content := "example.com\nexample.com www.example.com > cert KEY_ALGO=rsa extra # web\n"
+	require.NoError(t, os.WriteFile(dc.DomainsFile, []byte(content), 0644))
+
+	s := service.NewDomainService(dc, nil)
+	t.Cleanup(func() { _ = s.Close() })
+	require.NoError(t, s.Reload())
+
+	app := fiber.New()
+	NewDomainHandler(s).RegisterRoutes(app.Group("/api/v1"))
+
+	get := func(t *testing.T, url string) (int, model.RawDomainLineResponse) {
+		resp, err := app.Test(httptest.NewRequest("GET", url, http.NoBody))
+		require.NoError(t, err)
+		defer resp.Body.Close()
+
+		var response model.RawDomainLineResponse
+		require.NoError(t, json.NewDecoder(resp.Body).Decode(&response))
+		return resp.StatusCode, response
+	}
+
+	status, response := get(t, "/api/v1/domains/example.com/raw?alias=cert")
+	require.Equal(t, fiber.StatusOK, status)
+	require.True(t, response.Success)
+	require.Equal(t, &model.RawDomainLine{
+		Line:    2,
+		Raw:     "example.com www.example.com > cert KEY_ALGO=rsa extra # web",
+		Primary: "example.com",
+		SANs:    []string{"www.example.com"},
+		Alias:   "cert",
+		Options: "KEY_ALGO=rsa extra",
+		Comment: "web",
+	}, response.Data)
+
+	status, response = get(t, "/api/v1/domains/example.com/raw")
+	require.Equal(t, fiber.StatusOK, status)
+	require.Equal(t, "example.com", response.Data.Raw)
+	require.Equal(t, 1, response.Data.Line)
+
+	status, response = get(t, "/api/v1/domains/example.org/raw")
+	require.Equal(t, fiber.StatusNotFound, status)
+	require.Equal(t, model.CodeNotFound, response.Code)
+}
+
+// TestEffectiveConfigReflectsEdits verifies that edits of the config file of a certificate are reflected
+// by the effective configuration and passed to plugins without a restart or reload.
+func TestEffectiveConfigReflectsEdits(t *testing.T) {
+	dc := dehydrated.NewConfig().WithBaseDir(t.TempDir()).Load()
+	cfgFile := filepath.Join(dc.CertDir, "example.com", "config")
+	require.NoError(t, os.MkdirAll(filepath.Dir(cfgFile), 0755))
+	require.NoError(t, os.WriteFile(cfgFile, []byte("KEY_ALGO=prime256v1\n"), 0644))
+
+	plugin := &serviceinterface.MockPlugin{}
+	s := service.NewDomainService(dc, &serviceinterface.MockPluginRegistry{
+		Clients: map[string]*serviceinterface.MockPlugin{"inventory": plugin},
+	})
+	t.Cleanup(func() { _ = s.Close() })
+	_, err := s.CreateDomain(&model.CreateDomainRequest{Domain: "example.com", Enabled: util.BoolPtr(true)})
+	require.NoError(t, err)
+
+	app := fiber.New()
+	NewDomainHandler(s).RegisterRoutes(app.Group("/api/v1"))
+
+	keyAlgo := func(t *testing.T) string {
+		resp, err := app.Test(httptest.NewRequest("GET", "/api/v1/domains/example.com/effective-config", http.NoBody))
+		require.NoError(t, err)
+		defer resp.Body.Close()
+		require.Equal(t, fiber.StatusOK, resp.StatusCode)
+
+		var response model.ConfigResponse
+		require.NoError(t, json.NewDecoder(resp.Body).Decode(&response))
+		return response.Data.KeyAlgo
+	}
+	enrichedKeyAlgo := func(t *testing.T) string {
+		resp, err := app.Test(httptest.NewRequest("GET", "/api/v1/domains/example.com", http.NoBody))
+		require.NoError(t, err)
+		defer resp.Body.Close()
+		require.Equal(t, fiber.StatusOK, resp.StatusCode)
+		return plugin.Config.GetKeyAlgo()
+	}
+
+	require.Equal(t, "prime256v1", keyAlgo(t))
+	require.Equal(t, "prime256v1", enrichedKeyAlgo(t))
+
+	require.NoError(t, os.WriteFile(cfgFile, []byte("KEY_ALGO=secp384r1\n"), 0644))
+	require.Equal(t, "secp384r1", keyAlgo(t))
+	require.Equal(t, "secp384r1", enrichedKeyAlgo(t))
+
+	// Removing the file restores the global configuration
+	require.NoError(t, os.Remove(cfgFile))
+	require.Equal(t, dc.KeyAlgo, keyAlgo(t))
+	require.Equal(t, dc.KeyAlgo, enrichedKeyAlgo(t))
+}
+
+// TestCertPathTraversal verifies that entries written manually to the domains file with an alias leaving
+// CERTDIR are rejected with 400 instead of reading or running dehydrated for files outside of it.
+func TestCertPathTraversal(t *testing.T) {
+	baseDir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(baseDir, "domains.txt"),
+		[]byte("example.com > ../outside\nexample.org > ../../etc\n"), 0644))
+
+	// A config file outside of CERTDIR that must never be read
+	require.NoError(t, os.MkdirAll(filepath.Join(baseDir, "outside"), 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(baseDir, "outside", "config"), []byte("KEY_ALGO=secp384r1\n"), 0644))
+
+	dc := dehydrated.NewConfig().WithBaseDir(baseDir).Load()
+	s := service.NewDomainService(dc, nil).WithDehydratedScript(filepath.Join(baseDir, "missing-dehydrated"))
+	t.Cleanup(func() { _ = s.Close() })
+	require.NoError(t, s.Reload())
+
+	app := fiber.New()
+	NewDomainHandler(s).WithOCSPRefresh(true).RegisterRoutes(app.Group("/api/v1"))
+
+	tests := []struct {
+		name, method, path string
+	}{
+		{"EffectiveConfig", "GET", "/api/v1/domains/example.com/effective-config?alias=../outside"},
+		{"EffectiveConfigNested", "GET", "/api/v1/domains/example.org/effective-config?alias=../../etc"},
+		{"RefreshOCSP", "POST", "/api/v1/domains/example.com/ocsp/refresh?alias=../outside"},
+		{"KeyFingerprint", "GET", "/api/v1/domains/example.com/key/fingerprint?alias=../outside"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp, err := app.Test(httptest.NewRequest(tt.method, tt.path, http.NoBody))
+			require.NoError(t, err)
+			defer resp.Body.Close()
+			require.Equal(t, fiber.StatusBadRequest, resp.StatusCode)
+
+			var response model.ErrorResponse
+			require.NoError(t, json.NewDecoder(resp.Body).Decode(&response))
+			require.Equal(t, model.CodeValidationFailed, response.Code)
+			require.Contains(t, response.Error, "invalid certificate path")
+			require.NotContains(t, response.Error, "secp384r1")
+		})
+	}
+}
+
+// TestMetadataFormat compares the nested and flattened metadata of a plugin with multiple and nested keys.
+func TestMetadataFormat(t *testing.T) {
+	dc := dehydrated.NewConfig().WithBaseDir(t.TempDir()).Load()
+	site, err := structpb.NewStruct(map[string]any{"name": "dc1", "region": "eu"})
+	require.NoError(t, err)
+	s := service.NewDomainService(dc, &serviceinterface.MockPluginRegistry{
+		Clients: map[string]*serviceinterface.MockPlugin{"netbox": {Metadata: map[string]*structpb.Value{
+			"owner": structpb.NewStringValue("ops"),
+			"vlan":  structpb.NewNumberValue(42),
+			"site":  structpb.NewStructValue(site),
+		}}},
+	})
+	t.Cleanup(func() { _ = s.Close() })
+	_, err = s.CreateDomain(&model.CreateDomainRequest{Domain: "example.com", Enabled: util.BoolPtr(true)})
+	require.NoError(t, err)
+
+	nested := map[string]any{"netbox": map[string]any{
+		"owner": "ops",
+		"vlan":  float64(42),
+		"site":  map[string]any{"name": "dc1", "region": "eu"},
+	}}
+	flat := map[string]any{
+		"netbox.owner":       "ops",
+		"netbox.vlan":        float64(42),
+		"netbox.site.name":   "dc1",
+		"netbox.site.region": "eu",
+	}
+
+	metadata := func(t *testing.T, app *fiber.App, path string) (map[string]any, map[string]any) {
+		resp, err := app.Test(httptest.NewRequest("GET", path, http.NoBody))
+		require.NoError(t, err)
+		defer resp.Body.Close()
+		require.Equal(t, fiber.StatusOK, resp.StatusCode)
+
+		var response struct {
+			Data json.RawMessage `json:"data"`
+		}
+		require.NoError(t, json.NewDecoder(resp.Body).Decode(&response))
+		if strings.HasPrefix(path, "/api/v1/domains?") {
+			var entries []map[string]any
+			require.NoError(t, json.Unmarshal(response.Data, &entries))
+			require.Len(t, entries, 1)
+			return entries[0]["metadata"].(map[string]any), entries[0]
+		}
+		var entry map[string]any
+		require.NoError(t, json.Unmarshal(response.Data, &entry))
+		return entry["metadata"].(map[string]any), entry
+	}
+
+	app := fiber.New()
+	NewDomainHandler(s).RegisterRoutes(app.Group("/api/v1"))
+
+	t.Run("NestedByDefault", func(t *testing.T) {
+		m, entry := metadata(t, app, "/api/v1/domains/example.com")
+		require.Equal(t, nested, m)
+		require.Equal(t, "example.com", entry["domain"])
+
+		m, _ = metadata(t, app, "/api/v1/domains?metadata=nested")
+		require.Equal(t, nested, m)
+	})
+
+	t.Run("Flat", func(t *testing.T) {
+		m, entry := metadata(t, app, "/api/v1/domains/example.com?metadata=flat")
+		require.Equal(t, flat, m)
+		require.Equal(t, "example.com", entry["domain"])
+
+		m, _ = metadata(t, app, "/api/v1/domains?metadata=flat")
+		require.Equal(t, flat, m)
+	})
+
+	t.Run("FlatWithFields", func(t *testing.T) {
+		m, entry := metadata(t, app, "/api/v1/domains?metadata=flat&fields=domain,metadata")
+		require.Equal(t, flat, m)
+		require.Len(t, entry, 2)
+	})
+
+	t.Run("ConfiguredDefault", func(t *testing.T) {
+		flatApp := fiber.New()
+		NewDomainHandler(s).WithMetadataFormat(model.MetadataFormatFlat).RegisterRoutes(flatApp.Group("/api/v1"))
+
+		m, _ := metadata(t, flatApp, "/api/v1/domains/example.com")
+		require.Equal(t, flat, m)
+
+		m, _ = metadata(t, flatApp, "/api/v1/domains/example.com?metadata=nested")
+		require.Equal(t, nested, m)
+	})
+
+	t.Run("InvalidFormat", func(t *testing.T) {
+		for _, path := range []string{"/api/v1/domains?metadata=tree", "/api/v1/domains/example.com?metadata=tree"} {
+			resp, err := app.Test(httptest.NewRequest("GET", path, http.NoBody))
+			require.NoError(t, err)
+			resp.Body.Close()
+			require.Equal(t, fiber.StatusBadRequest, resp.StatusCode, path)
+		}
+	})
+}
+
+// TestRejectUnknownFields verifies that request bodies with unknown fields are rejected if configured.
+func TestRejectUnknownFields(t *testing.T) {
+	newApp := func(t *testing.T, reject bool) *fiber.App {
+		dc := dehydrated.NewConfig().WithBaseDir(t.TempDir()).Load()
+		s := service.NewDomainService(dc, nil)
+		t.Cleanup(func() { _ = s.Close() })
+
+		app := fiber.New()
+		NewDomainHandler(s).WithRejectUnknownFields(reject).RegisterRoutes(app.Group("/api/v1"))
+		return app
+	}
+
+	send := func(t *testing.T, app *fiber.App, method, path, body string) (int, model.DomainResponse) {
+		t.Helper()
+		req := httptest.NewRequest(method, path, strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		resp, err := app.Test(req)
+		require.NoError(t, err)
+		defer resp.Body.Close()
+
+		var response model.DomainResponse
+		require.NoError(t, json.NewDecoder(resp.Body).Decode(&response))
+		return resp.StatusCode, response
+	}
+
+	t.Run("Lenient", func(t *testing.T) {
+		app := newApp(t, false)
+		status, response := send(t, app, "POST", "/api/v1/domains", `{"domain":"example.com","enabeld":true}`)
+		require.Equal(t, fiber.StatusCreated, status)
+		require.Equal(t, "example.com", response.Data.Domain)
+
+		// The misspelled domain is ignored, so the entry has no domain
+		status, response = send(t, app, "POST", "/api/v1/domains", `{"domian":"example.org"}`)
+		require.Equal(t, fiber.StatusUnprocessableEntity, status)
+		require.NotContains(t, response.Error, "domian")
+	})
+
+	t.Run("Strict", func(t *testing.T) {
+		app := newApp(t, true)
+		status, _ := send(t, app, "POST", "/api/v1/domains", `{"domain":"example.com","enabled":true}`)
+		require.Equal(t, fiber.StatusCreated, status)
+
+		tests := []struct {
+			name   string
+			method string
+			path   string
+			body   string
+			field  string
+		}{
+			{"Create", "POST", "/api/v1/domains", `{"domian":"example.org"}`, "domian"},
+			{"Update", "PUT", "/api/v1/domains/example.com", `{"enabled":false,"coment":"typo"}`, "coment"},
+			{"Upsert", "PUT", "/api/v1/domains/example.org?upsert=true", `{"alternative_name":["www.example.org"]}`, "alternative_name"},
+			{"Rename", "PUT", "/api/v1/domains/example.com/rename", `{"domain":"example.net","alais":"cert"}`, "alais"},
+			{"Delete", "DELETE", "/api/v1/domains/example.com", `{"alias":"","force":true}`, "force"},
+		}
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				status, response := send(t, app, tt.method, tt.path, tt.body)
+				require.Equal(t, fiber.StatusBadRequest, status)
+				require.False(t, response.Success)
+				require.Equal(t, model.CodeValidationFailed, response.Code)
+				require.Equal(t, `invalid request body: unknown field "`+tt.field+`"`, response.Error)
+			})
+		}
+
+		// Trailing data is rejected as well
+		status, response := send(t, app, "POST", "/api/v1/domains", `{"domain":"example.org"} {}`)
+		require.Equal(t, fiber.StatusBadRequest, status)
+		require.Equal(t, "invalid request body", response.Error)
+
+		// The rejected requests did not change the entry
+		status, response = send(t, app, "GET", "/api/v1/domains/example.com", "")
+		require.Equal(t, fiber.StatusOK, status)
+		require.True(t, response.Data.Enabled)
+	})
+}
+
+// TestWriterRole verifies that every modifying route requires the writer role, while the preview does not.
+func TestWriterRole(t *testing.T) {
+	newApp := func(writerRole bool, roles ...any) *fiber.App {
+		app := fiber.New(fiber.Config{ErrorHandler: ErrorHandler})
+		g := app.Group("/api/v1", func(c *fiber.Ctx) error {
+			c.Locals("claims", jwt.MapClaims{"roles": roles})
+			return c.Next()
+		})
+		NewDomainHandler(&serviceinterface.MockDomainService{}).WithOCSPRefresh(true).WithWriterRole(writerRole).RegisterRoutes(g)
+		return app
+	}
+
+	// Routes of single entries only require the writer role if configured
+	entryRoutes := map[string]bool{
+		"POST /api/v1/domains":                          true,
+		"PUT /api/v1/domains/:domain":                   true,
+		"DELETE /api/v1/domains/:domain":                true,
+		"PUT /api/v1/domains/:domain/rename":            true,
+		"PUT /api/v1/domains/:domain/aliases/:alias":    true,
+		"DELETE /api/v1/domains/:domain/aliases/:alias": true,
+	}
+
+	for _, writerRole := range []bool{false, true} {
+		reader, writer := newApp(writerRole, "reader"), newApp(writerRole, "writer")
+
+		paths := strings.NewReplacer(":domain", "example.com", ":alias", "example")
+		for _, route := range reader.GetRoutes(true) {
+			switch route.Method {
+			case fiber.MethodPost, fiber.MethodPut, fiber.MethodPatch, fiber.MethodDelete:
+			default:
+				continue
+			}
+			if route.Path == "/api/v1/domains/preview" {
+				continue
+			}
+			restricted := writerRole || !entryRoutes[route.Method+" "+route.Path]
+
+			for app, forbidden := range map[*fiber.App]bool{reader: restricted, writer: false} {
+				req := httptest.NewRequest(route.Method, paths.Replace(route.Path), strings.NewReader(`{}`))
+				req.Header.Set("Content-Type", "application/json")
+				resp, err := app.Test(req)
+				require.NoError(t, err)
+				resp.Body.Close()
+				require.Equal(t, forbidden, resp.StatusCode == fiber.StatusForbidden, "%s %s (writer role %t)", route.Method, route.Path, writerRole)
+			}
+		}
+	}
+}
@@ -0,0 +1,70 @@
+package handler
+
+import (
+	"errors"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/schumann-it/dehydrated-api-go/internal/dehydrated"
+	"github.com/schumann-it/dehydrated-api-go/internal/model"
+	serviceinterface "github.com/schumann-it/dehydrated-api-go/internal/service/interface"
+)
+
+// errorCode returns the code of an error response with the given status. It is derived from the
+// sentinel error err wraps, if any, or from the status otherwise; err may be nil.
+func errorCode(err error, status int) string {
+	switch {
+	case errors.Is(err, serviceinterface.ErrDomainExists):
+		return model.CodeDomainExists
+	case errors.Is(err, serviceinterface.ErrDomainNotFound),
+		errors.Is(err, dehydrated.ErrAccountNotFound),
+		errors.Is(err, dehydrated.ErrOCSPNotFound),
+		errors.Is(err, dehydrated.ErrKeyNotFound):
+		return model.CodeNotFound
+	case errors.Is(err, serviceinterface.ErrDomainDisabled):
+		return model.CodeDomainDisabled
+	case errors.Is(err, serviceinterface.ErrInvalidDomainEntry):
+		return model.CodeInvalidDomain
+	case errors.Is(err, serviceinterface.ErrPluginFailed):
+		return model.CodePluginFailed
+	case errors.Is(err, serviceinterface.ErrTooManyEntries):
+		return model.CodeTooManyEntries
+	case errors.Is(err, serviceinterface.ErrCacheUnavailable):
+		return model.CodeUnavailable
+	}
+
+	switch status {
+	case fiber.StatusBadRequest:
+		return model.CodeValidationFailed
+	case fiber.StatusUnauthorized:
+		return model.CodeUnauthorized
+	case fiber.StatusForbidden:
+		return model.CodeForbidden
+	case fiber.StatusNotFound:
+		return model.CodeNotFound
+	case fiber.StatusConflict:
+		return model.CodeDomainExists
+	case fiber.StatusBadGateway:
+		return model.CodePluginFailed
+	case fiber.StatusServiceUnavailable:
+		return model.CodeUnavailable
+	case fiber.StatusInsufficientStorage:
+		return model.CodeTooManyEntries
+	}
+	return model.CodeInternalError
+}
+
+// ErrorHandler renders errors returned by handlers and middlewares, e.g., failed authentication,
+// as ErrorResponse with the code of the status.
+func ErrorHandler(c *fiber.Ctx, err error) error {
+	status := fiber.StatusInternalServerError
+	var e *fiber.Error
+	if errors.As(err, &e) {
+		status = e.Code
+	}
+
+	return c.Status(status).JSON(model.ErrorResponse{
+		Success: false,
+		Error:   err.Error(),
+		Code:    errorCode(err, status),
+	})
+}
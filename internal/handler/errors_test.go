@@ -0,0 +1,129 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/require"
+
+	"github.com/schumann-it/dehydrated-api-go/internal/dehydrated"
+	"github.com/schumann-it/dehydrated-api-go/internal/model"
+	"github.com/schumann-it/dehydrated-api-go/internal/service"
+	serviceinterface "github.com/schumann-it/dehydrated-api-go/internal/service/interface"
+	"github.com/schumann-it/dehydrated-api-go/internal/util"
+)
+
+// TestErrorCodes verifies the code of error responses per failure scenario.
+func TestErrorCodes(t *testing.T) {
+	dc := dehydrated.NewConfig().WithBaseDir(t.TempDir()).Load()
+	s := service.NewDomainService(dc, nil).WithEntryLimits(0, 1)
+	t.Cleanup(func() { _ = s.Close() })
+	_, err := s.CreateDomain(&model.CreateDomainRequest{Domain: "example.com", Enabled: util.BoolPtr(true)})
+	require.NoError(t, err)
+
+	app := fiber.New(fiber.Config{ErrorHandler: ErrorHandler})
+	NewDomainHandler(s).RegisterRoutes(app.Group("/api/v1"))
+
+	errApp := fiber.New(fiber.Config{ErrorHandler: ErrorHandler})
+	NewDomainHandler(&serviceinterface.MockErrDomainService{}).RegisterRoutes(errApp.Group("/api/v1"))
+
+	strictApp := fiber.New(fiber.Config{ErrorHandler: ErrorHandler})
+	NewDomainHandler(&strictDomainService{}).RegisterRoutes(strictApp.Group("/api/v1"))
+
+	tests := []struct {
+		name         string
+		app          *fiber.App
+		method, path string
+		body         string
+		status       int
+		code         string
+	}{
+		{"DomainExists", app, "POST", "/api/v1/domains", `{"domain": "example.com"}`, fiber.StatusConflict, model.CodeDomainExists},
+		{"NotFound", app, "GET", "/api/v1/domains/missing.com", "", fiber.StatusNotFound, model.CodeNotFound},
+		{"DeleteNotFound", app, "DELETE", "/api/v1/domains/missing.com", "", fiber.StatusNotFound, model.CodeNotFound},
+		{"InvalidDomain", app, "POST", "/api/v1/domains", `{"domain": "not a domain"}`, fiber.StatusUnprocessableEntity, model.CodeInvalidDomain},
+		{"InvalidUpdate", app, "PUT", "/api/v1/domains/example.com", `{"comment": "a\nb"}`, fiber.StatusUnprocessableEntity, model.CodeInvalidDomain},
+		{"InvalidBody", app, "POST", "/api/v1/domains", `{`, fiber.StatusBadRequest, model.CodeValidationFailed},
+		{"InvalidParameter", app, "GET", "/api/v1/domains?page=0", "", fiber.StatusBadRequest, model.CodeValidationFailed},
+		{"TooManyEntries", app, "POST", "/api/v1/domains", `{"domain": "example.org"}`, fiber.StatusInsufficientStorage, model.CodeTooManyEntries},
+		{"PluginFailed", strictApp, "GET", "/api/v1/domains?strict=true", "", fiber.StatusBadGateway, model.CodePluginFailed},
+		{"InternalError", errApp, "GET", "/api/v1/domains", "", fiber.StatusInternalServerError, model.CodeInternalError},
+		{"UnknownRoute", app, "GET", "/api/v1/unknown", "", fiber.StatusNotFound, model.CodeNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(tt.method, tt.path, strings.NewReader(tt.body))
+			req.Header.Set(fiber.HeaderContentType, fiber.MIMEApplicationJSON)
+			resp, err := tt.app.Test(req)
+			require.NoError(t, err)
+			defer resp.Body.Close()
+			require.Equal(t, tt.status, resp.StatusCode)
+
+			var response model.ErrorResponse
+			require.NoError(t, json.NewDecoder(resp.Body).Decode(&response))
+			require.False(t, response.Success)
+			require.NotEmpty(t, response.Error)
+			require.Equal(t, tt.code, response.Code)
+		})
+	}
+}
+
+func TestErrorCode(t *testing.T) {
+	tests := []struct {
+		err    error
+		status int
+		code   string
+	}{
+		{fmt.Errorf("create: %w", serviceinterface.ErrDomainExists), fiber.StatusBadRequest, model.CodeDomainExists},
+		{fmt.Errorf("%w: comment too long", serviceinterface.ErrInvalidDomainEntry), fiber.StatusBadRequest, model.CodeInvalidDomain},
+		{&serviceinterface.ImportError{}, fiber.StatusBadRequest, model.CodeInvalidDomain},
+		{&serviceinterface.PluginFailureError{}, fiber.StatusBadGateway, model.CodePluginFailed},
+		{dehydrated.ErrAccountNotFound, fiber.StatusNotFound, model.CodeNotFound},
+		{serviceinterface.ErrDomainDisabled, fiber.StatusConflict, model.CodeDomainDisabled},
+		{fmt.Errorf("%w: read failed", serviceinterface.ErrCacheUnavailable), fiber.StatusInternalServerError, model.CodeUnavailable},
+		{errors.New("invalid page"), fiber.StatusBadRequest, model.CodeValidationFailed},
+		{nil, fiber.StatusUnauthorized, model.CodeUnauthorized},
+		{nil, fiber.StatusForbidden, model.CodeForbidden},
+		{nil, fiber.StatusServiceUnavailable, model.CodeUnavailable},
+		{errors.New("disk full"), fiber.StatusInternalServerError, model.CodeInternalError},
+	}
+
+	for _, tt := range tests {
+		require.Equal(t, tt.code, errorCode(tt.err, tt.status), "%v %d", tt.err, tt.status)
+		require.Contains(t, model.ErrorCodes, tt.code)
+	}
+}
+
+// TestErrorHandler verifies that errors of middlewares, e.g., failed authentication, are rendered with a code.
+func TestErrorHandler(t *testing.T) {
+	app := fiber.New(fiber.Config{ErrorHandler: ErrorHandler})
+	app.Get("/unauthorized", func(_ *fiber.Ctx) error {
+		return fiber.NewError(fiber.StatusUnauthorized, "missing authorization header")
+	})
+	app.Get("/forbidden", func(_ *fiber.Ctx) error {
+		return fiber.NewError(fiber.StatusForbidden, "missing required role: writer")
+	})
+	app.Get("/error", func(_ *fiber.Ctx) error {
+		return errors.New("unexpected")
+	})
+
+	for path, want := range map[string]model.ErrorResponse{
+		"/unauthorized": {Error: "missing authorization header", Code: model.CodeUnauthorized},
+		"/forbidden":    {Error: "missing required role: writer", Code: model.CodeForbidden},
+		"/error":        {Error: "unexpected", Code: model.CodeInternalError},
+	} {
+		resp, err := app.Test(httptest.NewRequest("GET", path, nil))
+		require.NoError(t, err)
+		defer resp.Body.Close()
+
+		var response model.ErrorResponse
+		require.NoError(t, json.NewDecoder(resp.Body).Decode(&response))
+		require.Equal(t, want, response, path)
+	}
+}
@@ -0,0 +1,139 @@
+package handler
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/schumann-it/dehydrated-api-go/internal/model"
+	serviceinterface "github.com/schumann-it/dehydrated-api-go/internal/service/interface"
+)
+
+const (
+	// ExportFormatJSON exports the domain entries as a JSON array (default).
+	ExportFormatJSON = "json"
+	// ExportFormatCSV exports the domain entries as CSV, without metadata.
+	ExportFormatCSV = "csv"
+)
+
+// exportCSVHeader are the columns of the CSV export.
+var exportCSVHeader = []string{"domain", "alternative_names", "alias", "enabled", "comment"}
+
+// @Summary Export domains
+// @Description Export all domain entries as a file download. CSV contains the columns domain,
+// @Description alternative_names (semicolon-separated), alias, enabled and comment. JSON contains the entries
+// @Description with metadata enriched from plugins, unless metadata=false. Entries are exported in the order of domains.txt.
+// @Description CSV cells starting with =, +, - or @ are prefixed with ' so spreadsheets do not run them as formulas.
+// @Tags domains
+// @Produce json
+// @Produce text/csv
+// @Security BearerAuth
+// @Param format query string false "Export format (defaults to json)" Enums(json, csv)
+// @Param metadata query bool false "Include metadata in the JSON export (defaults to true)"
+// @Success 200 {array} model.DomainEntry
+// @Failure 400 {object} model.PaginatedDomainsResponse "Bad Request - Invalid format or metadata parameter"
+// @Failure 401 {object} model.PaginatedDomainsResponse "Unauthorized - Invalid or missing authentication token"
+// @Failure 500 {object} model.PaginatedDomainsResponse "Internal Server Error"
+// @Failure 503 {object} model.PaginatedDomainsResponse "Service Unavailable - domains file cannot be read"
+// @Header 200 {string} Content-Disposition "attachment; filename=domains.json or domains.csv"
+// @Router /api/v1/domains/export [get]
+// ExportDomains handles GET /api/v1/domains/export
+func (h *DomainHandler) ExportDomains(c *fiber.Ctx) error {
+	format := strings.ToLower(c.Query("format", ExportFormatJSON))
+	if format != ExportFormatJSON && format != ExportFormatCSV {
+		return c.Status(fiber.StatusBadRequest).JSON(model.PaginatedDomainsResponse{
+			Success: false,
+			Error:   fmt.Sprintf("invalid format: %s", format),
+			Code:    model.CodeValidationFailed,
+		})
+	}
+
+	metadata := format == ExportFormatJSON
+	if param := c.Query("metadata"); param != "" {
+		include, err := strconv.ParseBool(param)
+		if err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(model.PaginatedDomainsResponse{
+				Success: false,
+				Error:   fmt.Sprintf("invalid metadata: %s", param),
+				Code:    model.CodeValidationFailed,
+			})
+		}
+		metadata = metadata && include
+	}
+
+	var opts []serviceinterface.QueryOption
+	if !metadata {
+		opts = append(opts, serviceinterface.WithoutMetadata())
+	}
+
+	entries, err := h.service.AllDomains(opts...)
+	if err != nil {
+		status := fiber.StatusInternalServerError
+		if errors.Is(err, serviceinterface.ErrCacheUnavailable) {
+			status = fiber.StatusServiceUnavailable
+		}
+		return c.Status(status).JSON(model.PaginatedDomainsResponse{
+			Success: false,
+			Error:   err.Error(),
+			Code:    errorCode(err, status),
+		})
+	}
+
+	if format == ExportFormatCSV {
+		c.Attachment("domains.csv")
+		c.Set(fiber.HeaderContentType, "text/csv; charset=utf-8")
+		return writeDomainsCSV(c, entries)
+	}
+
+	c.Attachment("domains.json")
+	return c.JSON(entries)
+}
+
+// writeDomainsCSV writes the CSV export of entries to the response body.
+func writeDomainsCSV(c *fiber.Ctx, entries []*model.DomainEntry) error {
+	w := csv.NewWriter(c)
+	if err := w.Write(exportCSVHeader); err != nil {
+		return err
+	}
+	for _, entry := range entries {
+		record := []string{
+			entry.Domain,
+			strings.Join(entry.AlternativeNames, ";"),
+			entry.Alias,
+			strconv.FormatBool(entry.Enabled),
+			entry.Comment,
+		}
+		for i := range record {
+			record[i] = escapeCSVFormula(record[i])
+		}
+		if err := w.Write(record); err != nil {
+			return err
+		}
+	}
+	w.Flush()
+	return w.Error()
+}
+
+// csvFormulaChars are the characters spreadsheets interpret as the start of a formula.
+const csvFormulaChars = "=+-@"
+
+// escapeCSVFormula prefixes cell with a single quote if spreadsheets would interpret it as a formula,
+// so opening the export cannot run formulas injected via the API. Cells already starting with an escaped
+// formula are escaped again, so unescapeCSVFormula restores every cell.
+func escapeCSVFormula(cell string) string {
+	if rest := strings.TrimLeft(cell, "'"); rest != "" && strings.ContainsRune(csvFormulaChars, rune(rest[0])) {
+		return "'" + cell
+	}
+	return cell
+}
+
+// unescapeCSVFormula reverts escapeCSVFormula.
+func unescapeCSVFormula(cell string) string {
+	if unescaped, ok := strings.CutPrefix(cell, "'"); ok && escapeCSVFormula(unescaped) == cell {
+		return unescaped
+	}
+	return cell
+}
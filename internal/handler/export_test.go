@@ -0,0 +1,133 @@
+package handler
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/require"
+
+	"github.com/schumann-it/dehydrated-api-go/internal/dehydrated"
+	"github.com/schumann-it/dehydrated-api-go/internal/model"
+	"github.com/schumann-it/dehydrated-api-go/internal/service"
+	serviceinterface "github.com/schumann-it/dehydrated-api-go/internal/service/interface"
+	pb "github.com/schumann-it/dehydrated-api-go/plugin/proto"
+)
+
+func TestExportDomains(t *testing.T) {
+	dc := dehydrated.NewConfig().WithBaseDir(t.TempDir()).Load()
+	require.NoError(t, service.WriteDomainsFile(dc.DomainsFile, model.DomainEntries{
+		{DomainEntry: pb.DomainEntry{
+			Domain: "example.com", AlternativeNames: []string{"www.example.com", "api.example.com"}, Enabled: true, Comment: "Production, main",
+		}},
+		{DomainEntry: pb.DomainEntry{Domain: "example.org", Alias: "org"}},
+	}))
+	s := service.NewDomainService(dc, nil)
+	defer s.Close()
+	require.NoError(t, s.Reload())
+
+	app := fiber.New()
+	NewDomainHandler(s).RegisterRoutes(app.Group("/api/v1"))
+
+	get := func(t *testing.T, query string) *http.Response {
+		resp, err := app.Test(httptest.NewRequest("GET", "/api/v1/domains/export"+query, http.NoBody))
+		require.NoError(t, err)
+		t.Cleanup(func() { resp.Body.Close() })
+		return resp
+	}
+
+	t.Run("CSV", func(t *testing.T) {
+		resp := get(t, "?format=csv")
+		require.Equal(t, fiber.StatusOK, resp.StatusCode)
+		require.Equal(t, "text/csv; charset=utf-8", resp.Header.Get(fiber.HeaderContentType))
+		require.Equal(t, `attachment; filename="domains.csv"`, resp.Header.Get(fiber.HeaderContentDisposition))
+
+		records, err := csv.NewReader(resp.Body).ReadAll()
+		require.NoError(t, err)
+		require.Equal(t, [][]string{
+			{"domain", "alternative_names", "alias", "enabled", "comment"},
+			{"example.com", "www.example.com;api.example.com", "", "true", "Production, main"},
+			{"example.org", "", "org", "false", ""},
+		}, records)
+	})
+
+	t.Run("JSON", func(t *testing.T) {
+		for _, query := range []string{"", "?format=json", "?format=json&metadata=false"} {
+			resp := get(t, query)
+			require.Equal(t, fiber.StatusOK, resp.StatusCode, query)
+			require.Contains(t, resp.Header.Get(fiber.HeaderContentType), fiber.MIMEApplicationJSON)
+			require.Equal(t, `attachment; filename="domains.json"`, resp.Header.Get(fiber.HeaderContentDisposition))
+
+			var entries []map[string]any
+			require.NoError(t, json.NewDecoder(resp.Body).Decode(&entries))
+			require.Len(t, entries, 2)
+			require.Equal(t, "example.com", entries[0]["domain"])
+			require.Equal(t, "org", entries[1]["alias"])
+		}
+	})
+
+	t.Run("InvalidParameters", func(t *testing.T) {
+		require.Equal(t, fiber.StatusBadRequest, get(t, "?format=xml").StatusCode)
+		require.Equal(t, fiber.StatusBadRequest, get(t, "?metadata=maybe").StatusCode)
+	})
+
+	t.Run("ServiceError", func(t *testing.T) {
+		errApp := fiber.New()
+		NewDomainHandler(&serviceinterface.MockErrDomainService{}).RegisterRoutes(errApp.Group("/api/v1"))
+
+		resp, err := errApp.Test(httptest.NewRequest("GET", "/api/v1/domains/export", http.NoBody))
+		require.NoError(t, err)
+		defer resp.Body.Close()
+		require.Equal(t, fiber.StatusInternalServerError, resp.StatusCode)
+	})
+}
+
+// TestCSVFormulaEscaping verifies that cells spreadsheets would run as formulas are escaped in the export,
+// and that the import restores them.
+func TestCSVFormulaEscaping(t *testing.T) {
+	for cell, escaped := range map[string]string{
+		"":                  "",
+		"shop":              "shop",
+		"=HYPERLINK(\"x\")": "'=HYPERLINK(\"x\")",
+		"+1":                "'+1",
+		"-1":                "'-1",
+		"@SUM(A1)":          "'@SUM(A1)",
+		"'quoted":           "'quoted",
+		"'=already escaped": "''=already escaped",
+		"a=b":               "a=b",
+	} {
+		require.Equal(t, escaped, escapeCSVFormula(cell), cell)
+		require.Equal(t, cell, unescapeCSVFormula(escaped), cell)
+	}
+
+	dc := dehydrated.NewConfig().WithBaseDir(t.TempDir()).Load()
+	require.NoError(t, service.WriteDomainsFile(dc.DomainsFile, model.DomainEntries{
+		{DomainEntry: pb.DomainEntry{Domain: "example.com", Enabled: true, Comment: "=cmd|' /C calc'!A0"}},
+	}))
+	s := service.NewDomainService(dc, nil)
+	defer s.Close()
+	require.NoError(t, s.Reload())
+
+	app := fiber.New()
+	NewDomainHandler(s).RegisterRoutes(app.Group("/api/v1"))
+
+	resp, err := app.Test(httptest.NewRequest("GET", "/api/v1/domains/export?format=csv", http.NoBody))
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	body, err := io.ReadAll(resp.Body)
+	require.NoError(t, err)
+
+	records, err := csv.NewReader(bytes.NewReader(body)).ReadAll()
+	require.NoError(t, err)
+	require.Equal(t, "'=cmd|' /C calc'!A0", records[1][4])
+
+	reqs, _, rowErrors, err := parseImportCSV(body)
+	require.NoError(t, err)
+	require.Empty(t, rowErrors)
+	require.Equal(t, "=cmd|' /C calc'!A0", reqs[0].Comment)
+}
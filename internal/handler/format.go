@@ -0,0 +1,78 @@
+package handler
+
+import (
+	"fmt"
+	"mime"
+	"slices"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/schumann-it/dehydrated-api-go/internal/model"
+	serviceinterface "github.com/schumann-it/dehydrated-api-go/internal/service/interface"
+)
+
+// envelopeParam is the Accept media type parameter clients use to negotiate the response format,
+// e.g., "Accept: application/json; envelope=false".
+const envelopeParam = "envelope"
+
+// wantsFlatMetadata reports whether the metadata of domain entries should be flattened.
+// The metadata query parameter takes precedence over the configured default.
+func wantsFlatMetadata(c *fiber.Ctx, defaultFormat string) (bool, error) {
+	format := c.Query("metadata", defaultFormat)
+	if !model.IsValidMetadataFormat(format) {
+		return false, fmt.Errorf("invalid metadata format: %s, use %s or %s", format, model.MetadataFormatNested, model.MetadataFormatFlat)
+	}
+	return format == model.MetadataFormatFlat, nil
+}
+
+// wantsBare reports whether the successful response should be sent without an envelope.
+// The envelope parameter of the Accept header takes precedence over the configured default.
+// Error responses are always enveloped.
+func wantsBare(c *fiber.Ctx, defaultFormat string) bool {
+	for _, part := range strings.Split(c.Get(fiber.HeaderAccept), ",") {
+		_, params, err := mime.ParseMediaType(strings.TrimSpace(part))
+		if err != nil {
+			continue
+		}
+		switch strings.ToLower(params[envelopeParam]) {
+		case "false":
+			return true
+		case "true":
+			return false
+		}
+	}
+
+	return defaultFormat == model.ResponseFormatBare
+}
+
+// parseFields parses the comma-separated fields query parameter selecting the DomainEntry fields
+// of the response (sparse fieldset). It returns nil if all fields are requested.
+func parseFields(c *fiber.Ctx) ([]string, error) {
+	param := c.Query("fields")
+	if param == "" {
+		return nil, nil
+	}
+
+	var fields []string
+	for _, f := range strings.Split(param, ",") {
+		f = strings.TrimSpace(f)
+		if f == "" {
+			continue
+		}
+		if !model.IsValidDomainEntryField(f) {
+			return nil, fmt.Errorf("unknown field: %s", f)
+		}
+		fields = append(fields, f)
+	}
+
+	return fields, nil
+}
+
+// fieldsQueryOptions returns the service query options for the selected fields.
+// Metadata enrichment is skipped if metadata is not selected.
+func fieldsQueryOptions(fields []string) []serviceinterface.QueryOption {
+	if len(fields) > 0 && !slices.Contains(fields, "metadata") {
+		return []serviceinterface.QueryOption{serviceinterface.WithoutMetadata()}
+	}
+	return nil
+}
@@ -0,0 +1,195 @@
+package handler
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/schumann-it/dehydrated-api-go/internal/dehydrated"
+	"github.com/schumann-it/dehydrated-api-go/internal/model"
+	"github.com/schumann-it/dehydrated-api-go/internal/service"
+	serviceinterface "github.com/schumann-it/dehydrated-api-go/internal/service/interface"
+	"github.com/schumann-it/dehydrated-api-go/internal/util"
+	"github.com/stretchr/testify/require"
+)
+
+// newFormatTestApp creates an app serving the domain routes backed by a domains.txt with two entries.
+func newFormatTestApp(t *testing.T, format string) *fiber.App {
+	t.Helper()
+
+	dc := dehydrated.NewConfig().WithBaseDir(t.TempDir()).Load()
+	s := service.NewDomainService(dc, nil)
+	t.Cleanup(func() { s.Close() })
+
+	for _, domain := range []string{"a.example.com", "b.example.com"} {
+		_, err := s.CreateDomain(&model.CreateDomainRequest{Domain: domain, Enabled: util.BoolPtr(true)})
+		require.NoError(t, err)
+	}
+
+	app := fiber.New()
+	NewDomainHandler(s).WithResponseFormat(format).RegisterRoutes(app.Group("/api/v1"))
+
+	return app
+}
+
+// TestResponseFormat verifies enveloped and bare responses and their negotiation via the Accept header.
+func TestResponseFormat(t *testing.T) {
+	tests := []struct {
+		name   string
+		format string
+		accept string
+		bare   bool
+	}{
+		{name: "DefaultEnveloped", format: "", bare: false},
+		{name: "ConfiguredBare", format: model.ResponseFormatBare, bare: true},
+		{name: "AcceptBare", format: model.ResponseFormatEnveloped, accept: "application/json; envelope=false", bare: true},
+		{name: "AcceptEnveloped", format: model.ResponseFormatBare, accept: "application/json; envelope=true", bare: false},
+		{name: "AcceptWithoutParameter", format: model.ResponseFormatBare, accept: "application/json", bare: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			app := newFormatTestApp(t, tt.format)
+
+			req := httptest.NewRequest("GET", "/api/v1/domains?per_page=1", http.NoBody)
+			if tt.accept != "" {
+				req.Header.Set("Accept", tt.accept)
+			}
+			resp, err := app.Test(req)
+			require.NoError(t, err)
+			defer resp.Body.Close()
+			require.Equal(t, fiber.StatusOK, resp.StatusCode)
+
+			body, err := io.ReadAll(resp.Body)
+			require.NoError(t, err)
+
+			if tt.bare {
+				var entries []map[string]any
+				require.NoError(t, json.Unmarshal(body, &entries))
+				require.Len(t, entries, 1)
+				require.Equal(t, "a.example.com", entries[0]["domain"])
+				require.Equal(t, "2", resp.Header.Get("X-Total-Count"))
+				require.Equal(t, "2", resp.Header.Get("X-Total-Pages"))
+				return
+			}
+
+			var response model.PaginatedDomainsResponse
+			require.NoError(t, json.Unmarshal(body, &response))
+			require.True(t, response.Success)
+			require.Len(t, response.Data, 1)
+			require.Equal(t, 2, response.Pagination.Total)
+			require.Empty(t, resp.Header.Get("X-Total-Count"))
+		})
+	}
+
+	t.Run("BareSingleEntry", func(t *testing.T) {
+		app := newFormatTestApp(t, model.ResponseFormatBare)
+
+		resp, err := app.Test(httptest.NewRequest("GET", "/api/v1/domains/a.example.com", http.NoBody))
+		require.NoError(t, err)
+		defer resp.Body.Close()
+		require.Equal(t, fiber.StatusOK, resp.StatusCode)
+
+		var entry map[string]any
+		require.NoError(t, json.NewDecoder(resp.Body).Decode(&entry))
+		require.Equal(t, "a.example.com", entry["domain"])
+		require.NotContains(t, entry, "success")
+	})
+
+	t.Run("BareErrorStaysEnveloped", func(t *testing.T) {
+		app := newFormatTestApp(t, model.ResponseFormatBare)
+
+		resp, err := app.Test(httptest.NewRequest("GET", "/api/v1/domains/missing.example.com", http.NoBody))
+		require.NoError(t, err)
+		defer resp.Body.Close()
+		require.Equal(t, fiber.StatusNotFound, resp.StatusCode)
+
+		var response model.DomainResponse
+		require.NoError(t, json.NewDecoder(resp.Body).Decode(&response))
+		require.False(t, response.Success)
+		require.NotEmpty(t, response.Error)
+	})
+}
+
+// recordingDomainService records the query options of GetDomain and ListDomains calls.
+type recordingDomainService struct {
+	serviceinterface.MockDomainService
+	opts serviceinterface.QueryOptions
+}
+
+func (m *recordingDomainService) ListDomains(page, perPage int, sortOrder, search string, opts ...serviceinterface.QueryOption) ([]*model.DomainEntry, *model.PaginationInfo, error) {
+	m.opts = serviceinterface.NewQueryOptions(opts...)
+	return m.MockDomainService.ListDomains(page, perPage, sortOrder, search)
+}
+
+func (m *recordingDomainService) GetDomain(domain, alias string, opts ...serviceinterface.QueryOption) (*model.DomainEntry, error) {
+	m.opts = serviceinterface.NewQueryOptions(opts...)
+	return m.MockDomainService.GetDomain(domain, alias)
+}
+
+// TestSparseFieldsets verifies that the fields parameter restricts the response to the requested fields.
+func TestSparseFieldsets(t *testing.T) {
+	app := newFormatTestApp(t, model.ResponseFormatBare)
+
+	t.Run("List", func(t *testing.T) {
+		resp, err := app.Test(httptest.NewRequest("GET", "/api/v1/domains?fields=domain,enabled", http.NoBody))
+		require.NoError(t, err)
+		defer resp.Body.Close()
+		require.Equal(t, fiber.StatusOK, resp.StatusCode)
+
+		var entries []map[string]any
+		require.NoError(t, json.NewDecoder(resp.Body).Decode(&entries))
+		require.Len(t, entries, 2)
+		for _, entry := range entries {
+			require.Len(t, entry, 2)
+			require.Contains(t, entry, "domain")
+			require.Contains(t, entry, "enabled")
+		}
+	})
+
+	t.Run("Get", func(t *testing.T) {
+		resp, err := app.Test(httptest.NewRequest("GET", "/api/v1/domains/a.example.com?fields=alias,metadata", http.NoBody))
+		require.NoError(t, err)
+		defer resp.Body.Close()
+		require.Equal(t, fiber.StatusOK, resp.StatusCode)
+
+		var entry map[string]any
+		require.NoError(t, json.NewDecoder(resp.Body).Decode(&entry))
+		require.Equal(t, map[string]any{"alias": "", "metadata": map[string]any{}}, entry)
+	})
+
+	t.Run("UnknownField", func(t *testing.T) {
+		for _, path := range []string{"/api/v1/domains?fields=domain,foo", "/api/v1/domains/a.example.com?fields=foo"} {
+			resp, err := app.Test(httptest.NewRequest("GET", path, http.NoBody))
+			require.NoError(t, err)
+			resp.Body.Close()
+			require.Equal(t, fiber.StatusBadRequest, resp.StatusCode)
+		}
+	})
+
+	t.Run("SkipMetadataEnrichment", func(t *testing.T) {
+		s := &recordingDomainService{}
+		recorder := fiber.New()
+		NewDomainHandler(s).RegisterRoutes(recorder.Group("/api/v1"))
+
+		tests := []struct {
+			path string
+			skip bool
+		}{
+			{"/api/v1/domains?fields=domain", true},
+			{"/api/v1/domains?fields=domain,metadata", false},
+			{"/api/v1/domains", false},
+			{"/api/v1/domains/a.example.com?fields=enabled", true},
+			{"/api/v1/domains/a.example.com", false},
+		}
+		for _, tt := range tests {
+			resp, err := recorder.Test(httptest.NewRequest("GET", tt.path, http.NoBody))
+			require.NoError(t, err)
+			resp.Body.Close()
+			require.Equal(t, tt.skip, s.opts.SkipMetadata, tt.path)
+		}
+	})
+}
@@ -1,13 +1,23 @@
 package handler
 
 import (
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
 	"github.com/gofiber/fiber/v2"
 	"github.com/schumann-it/dehydrated-api-go/internal/model"
 )
 
 // HealthHandler handles HTTP requests for health operations
 type HealthHandler struct {
-	status bool
+	status       bool
+	storageDir   string // Directory that must stay writable, usually the one holding domains.txt
+	minFreeBytes uint64 // Minimum free disk space required in storageDir
+
+	pluginFailures func() []*model.PluginFailure // Enabled plugins that are not available
+	domainsCheck   func() error                  // Check of the domain entries, e.g., that there are any
 }
 
 // NewHealthHandler creates a new HealthHandler instance
@@ -15,9 +25,33 @@ func NewHealthHandler() *HealthHandler {
 	return &HealthHandler{status: true}
 }
 
+// WithStorageCheck enables the writability and disk-space checks of the readiness endpoint
+// for the given directory. Readiness fails if less than minFreeBytes are available; the disk-space check
+// is disabled if minFreeBytes is 0.
+func (h *HealthHandler) WithStorageCheck(dir string, minFreeBytes uint64) *HealthHandler {
+	h.storageDir = dir
+	h.minFreeBytes = minFreeBytes
+	return h
+}
+
+// WithPluginCheck enables the plugin check of the readiness endpoint.
+// Readiness fails if failures returns any misconfigured or dead plugins.
+func (h *HealthHandler) WithPluginCheck(failures func() []*model.PluginFailure) *HealthHandler {
+	h.pluginFailures = failures
+	return h
+}
+
+// WithDomainsCheck enables the domains check of the readiness endpoint.
+// Readiness fails if check returns an error, e.g., serviceinterface.ErrNoDomains.
+func (h *HealthHandler) WithDomainsCheck(check func() error) *HealthHandler {
+	h.domainsCheck = check
+	return h
+}
+
 // RegisterRoutes registers all health-related routes
 func (h *HealthHandler) RegisterRoutes(app *fiber.App) {
 	app.Get("/health", h.Health)
+	app.Get("/readyz", h.Ready)
 }
 
 // @Summary Health check
@@ -33,3 +67,106 @@ func (h *HealthHandler) Health(c *fiber.Ctx) error {
 		Success: h.status,
 	})
 }
+
+// @Summary Readiness check
+// @Description Check if the API is ready to accept writes (storage writable, enough disk space available, all enabled plugins available and, if required, domain entries present)
+// @Tags health
+// @Produce json
+// @Success 200 {object} model.ReadinessResponse
+// @Failure 503 {object} model.ReadinessResponse "Service Unavailable - Storage not writable, disk space below threshold, plugins misconfigured or dead or no domain entries"
+// @Router /readyz [get]
+// Ready handles GET /readyz
+func (h *HealthHandler) Ready(c *fiber.Ctx) error {
+	if h.storageDir == "" && h.pluginFailures == nil && h.domainsCheck == nil {
+		return c.JSON(model.ReadinessResponse{
+			Success: h.status,
+		})
+	}
+
+	status := &model.ReadinessStatus{
+		MinFreeBytes: h.minFreeBytes,
+	}
+
+	if h.pluginFailures != nil {
+		status.Plugins = h.pluginFailures()
+	}
+
+	err := h.checkStorage(status)
+	if err == nil {
+		err = checkPlugins(status.Plugins)
+	}
+	if err == nil && h.domainsCheck != nil {
+		err = h.domainsCheck()
+	}
+	if err != nil {
+		return c.Status(fiber.StatusServiceUnavailable).JSON(model.ReadinessResponse{
+			Success: false,
+			Data:    status,
+			Error:   err.Error(),
+			Code:    errorCode(err, fiber.StatusServiceUnavailable),
+		})
+	}
+
+	return c.JSON(model.ReadinessResponse{
+		Success: h.status,
+		Data:    status,
+	})
+}
+
+// checkStorage runs the storage checks, if enabled, and records their results in status.
+func (h *HealthHandler) checkStorage(status *model.ReadinessStatus) error {
+	if h.storageDir == "" {
+		return nil
+	}
+
+	if err := checkWritable(h.storageDir); err != nil {
+		return fmt.Errorf("storage not writable: %w", err)
+	}
+	status.Writable = true
+
+	if h.minFreeBytes == 0 {
+		return nil
+	}
+
+	free, err := freeDiskSpace(h.storageDir)
+	if err != nil {
+		return fmt.Errorf("failed to determine free disk space: %w", err)
+	}
+	status.FreeBytes = free
+
+	if free < h.minFreeBytes {
+		return fmt.Errorf("free disk space %d bytes below threshold of %d bytes", free, h.minFreeBytes)
+	}
+
+	return nil
+}
+
+// checkPlugins returns an error listing the given unavailable plugins, if any.
+func checkPlugins(failures []*model.PluginFailure) error {
+	if len(failures) == 0 {
+		return nil
+	}
+
+	reasons := make([]string, 0, len(failures))
+	for _, f := range failures {
+		reasons = append(reasons, fmt.Sprintf("%s is %s: %s", f.Name, f.State, f.Reason))
+	}
+
+	return errors.New("plugins not available: " + strings.Join(reasons, "; "))
+}
+
+// checkWritable verifies that files can be created in dir by creating and removing a temp file.
+func checkWritable(dir string) error {
+	f, err := os.CreateTemp(dir, ".readyz-*")
+	if err != nil {
+		return err
+	}
+
+	name := f.Name()
+	if err := f.Close(); err != nil {
+		_ = os.Remove(name)
+		return err
+	}
+
+	return os.Remove(name)
+}
@@ -0,0 +1,140 @@
+package handler
+
+import (
+	"encoding/json"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/schumann-it/dehydrated-api-go/internal/model"
+	serviceinterface "github.com/schumann-it/dehydrated-api-go/internal/service/interface"
+	"github.com/stretchr/testify/require"
+)
+
+// readyz performs a GET /readyz request against a health handler and decodes the response.
+func readyz(t *testing.T, h *HealthHandler) (int, model.ReadinessResponse) {
+	t.Helper()
+
+	app := fiber.New()
+	h.RegisterRoutes(app)
+
+	result, err := app.Test(httptest.NewRequest("GET", "/readyz", http.NoBody))
+	require.NoError(t, err)
+	defer result.Body.Close()
+
+	var response model.ReadinessResponse
+	require.NoError(t, json.NewDecoder(result.Body).Decode(&response))
+
+	return result.StatusCode, response
+}
+
+// TestReadiness verifies the storage checks of the readiness endpoint.
+func TestReadiness(t *testing.T) {
+	t.Run("WithoutStorageCheck", func(t *testing.T) {
+		status, response := readyz(t, NewHealthHandler())
+		require.Equal(t, fiber.StatusOK, status)
+		require.True(t, response.Success)
+		require.Nil(t, response.Data)
+	})
+
+	t.Run("WritableDirectory", func(t *testing.T) {
+		dir := t.TempDir()
+
+		status, response := readyz(t, NewHealthHandler().WithStorageCheck(dir, 1))
+		require.Equal(t, fiber.StatusOK, status)
+		require.True(t, response.Success)
+		require.True(t, response.Data.Writable)
+		require.Positive(t, response.Data.FreeBytes)
+
+		// The probe file must not be left behind
+		entries, err := os.ReadDir(dir)
+		require.NoError(t, err)
+		require.Empty(t, entries)
+	})
+
+	t.Run("ReadOnlyDirectory", func(t *testing.T) {
+		if os.Geteuid() == 0 {
+			t.Skip("Skipping read-only directory test; running as root")
+		}
+
+		dir := filepath.Join(t.TempDir(), "readonly")
+		require.NoError(t, os.Mkdir(dir, 0555))
+		defer os.Chmod(dir, 0755) //nolint:errcheck // cleanup only
+
+		status, response := readyz(t, NewHealthHandler().WithStorageCheck(dir, 0))
+		require.Equal(t, fiber.StatusServiceUnavailable, status)
+		require.False(t, response.Success)
+		require.False(t, response.Data.Writable)
+		require.Contains(t, response.Error, "storage not writable")
+	})
+
+	t.Run("MissingDirectory", func(t *testing.T) {
+		dir := filepath.Join(t.TempDir(), "missing")
+
+		status, response := readyz(t, NewHealthHandler().WithStorageCheck(dir, 0))
+		require.Equal(t, fiber.StatusServiceUnavailable, status)
+		require.False(t, response.Success)
+	})
+
+	t.Run("DiskSpaceCheckDisabled", func(t *testing.T) {
+		status, response := readyz(t, NewHealthHandler().WithStorageCheck(t.TempDir(), 0))
+		require.Equal(t, fiber.StatusOK, status)
+		require.True(t, response.Data.Writable)
+		require.Zero(t, response.Data.FreeBytes)
+	})
+
+	t.Run("BelowThreshold", func(t *testing.T) {
+		status, response := readyz(t, NewHealthHandler().WithStorageCheck(t.TempDir(), math.MaxUint64))
+		require.Equal(t, fiber.StatusServiceUnavailable, status)
+		require.False(t, response.Success)
+		require.True(t, response.Data.Writable)
+		require.Contains(t, response.Error, "below threshold")
+	})
+	t.Run("PluginsAvailable", func(t *testing.T) {
+		h := NewHealthHandler().WithPluginCheck(func() []*model.PluginFailure { return nil })
+
+		status, response := readyz(t, h)
+		require.Equal(t, fiber.StatusOK, status)
+		require.True(t, response.Success)
+		require.Empty(t, response.Data.Plugins)
+	})
+
+	t.Run("PluginMisconfigured", func(t *testing.T) {
+		failures := []*model.PluginFailure{
+			{Name: "netbox", State: model.PluginMisconfigured, Reason: "missing apiToken"},
+			{Name: "dns", State: model.PluginDead, Reason: "connection refused"},
+		}
+		h := NewHealthHandler().WithStorageCheck(t.TempDir(), 0).
+			WithPluginCheck(func() []*model.PluginFailure { return failures })
+
+		status, response := readyz(t, h)
+		require.Equal(t, fiber.StatusServiceUnavailable, status)
+		require.False(t, response.Success)
+		require.Equal(t, model.CodeUnavailable, response.Code)
+		require.True(t, response.Data.Writable)
+		require.Equal(t, failures, response.Data.Plugins)
+		require.Equal(t, "plugins not available: netbox is misconfigured: missing apiToken; "+
+			"dns is dead: connection refused", response.Error)
+	})
+	t.Run("NoDomains", func(t *testing.T) {
+		h := NewHealthHandler().WithDomainsCheck(func() error { return serviceinterface.ErrNoDomains })
+
+		status, response := readyz(t, h)
+		require.Equal(t, fiber.StatusServiceUnavailable, status)
+		require.False(t, response.Success)
+		require.Equal(t, model.CodeUnavailable, response.Code)
+		require.Equal(t, serviceinterface.ErrNoDomains.Error(), response.Error)
+	})
+
+	t.Run("Domains", func(t *testing.T) {
+		h := NewHealthHandler().WithDomainsCheck(func() error { return nil })
+
+		status, response := readyz(t, h)
+		require.Equal(t, fiber.StatusOK, status)
+		require.True(t, response.Success)
+	})
+}
@@ -0,0 +1,218 @@
+package handler
+
+import (
+	"bytes"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/schumann-it/dehydrated-api-go/internal/model"
+	serviceinterface "github.com/schumann-it/dehydrated-api-go/internal/service/interface"
+)
+
+const (
+	// ImportModeMerge creates and updates the imported entries and keeps all others (default).
+	ImportModeMerge = "merge"
+	// ImportModeReplace replaces all entries with the imported ones.
+	ImportModeReplace = "replace"
+)
+
+// utf8BOM is the byte order mark spreadsheet applications may prepend to CSV files.
+var utf8BOM = []byte("\xef\xbb\xbf")
+
+// @Summary Import domains
+// @Description Import domain entries from a CSV file with the columns of the CSV export: domain (required),
+// @Description alternative_names (semicolon-separated), alias, enabled and comment. Entries are identified by domain
+// @Description and alias. In merge mode, imported entries are created or updated and all others are kept; in replace
+// @Description mode, all other entries are removed. All rows are validated first, if any is invalid, nothing is changed
+// @Description and the invalid rows are reported with their line numbers.
+// @Tags domains
+// @Accept text/csv
+// @Produce json
+// @Security BearerAuth
+// @Param format query string false "Import format (defaults to csv)" Enums(csv)
+// @Param mode query string false "Import mode (defaults to merge)" Enums(merge, replace)
+// @Param request body string true "CSV file"
+// @Success 200 {object} model.ImportResponse
+// @Failure 400 {object} model.ImportResponse "Bad Request - Invalid parameters, header or malformed rows"
+// @Failure 401 {object} model.ImportResponse "Unauthorized - Invalid or missing authentication token"
+// @Failure 403 {object} model.ImportResponse "Forbidden - Missing writer role"
+// @Failure 422 {object} model.ImportResponse "Unprocessable Entity - Rows with invalid or duplicate entries"
+// @Failure 500 {object} model.ImportResponse "Internal Server Error"
+// @Failure 502 {object} model.ImportResponse "Bad Gateway - A validating plugin failed"
+// @Failure 507 {object} model.ImportResponse "Insufficient Storage - Maximum number of entries exceeded"
+// @Failure 503 {object} model.ImportResponse "Service Unavailable - domains file cannot be read"
+// @Router /api/v1/domains/import [post]
+// ImportDomains handles POST /api/v1/domains/import
+func (h *DomainHandler) ImportDomains(c *fiber.Ctx) error {
+	format := strings.ToLower(c.Query("format", ExportFormatCSV))
+	if format != ExportFormatCSV {
+		return c.Status(fiber.StatusBadRequest).JSON(model.ImportResponse{
+			Success: false,
+			Error:   fmt.Sprintf("invalid format: %s", format),
+			Code:    model.CodeValidationFailed,
+		})
+	}
+
+	mode := strings.ToLower(c.Query("mode", ImportModeMerge))
+	if mode != ImportModeMerge && mode != ImportModeReplace {
+		return c.Status(fiber.StatusBadRequest).JSON(model.ImportResponse{
+			Success: false,
+			Error:   fmt.Sprintf("invalid mode: %s", mode),
+			Code:    model.CodeValidationFailed,
+		})
+	}
+
+	reqs, lines, rowErrors, err := parseImportCSV(c.Body())
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(model.ImportResponse{
+			Success: false,
+			Error:   err.Error(),
+			Code:    errorCode(err, fiber.StatusBadRequest),
+		})
+	}
+	if len(rowErrors) > 0 {
+		return c.Status(fiber.StatusBadRequest).JSON(model.ImportResponse{
+			Success: false,
+			Error:   "invalid rows",
+			Code:    model.CodeValidationFailed,
+			Errors:  rowErrors,
+		})
+	}
+
+	result, err := h.service.ImportDomains(reqs, mode == ImportModeReplace)
+	if err != nil {
+		var importErr *serviceinterface.ImportError
+		switch {
+		case errors.As(err, &importErr):
+			for _, e := range importErr.Errors {
+				rowErrors = append(rowErrors, model.ImportRowError{Line: lines[e.Index], Error: e.Err.Error(), Field: invalidField(e.Err)})
+			}
+			return c.Status(fiber.StatusUnprocessableEntity).JSON(model.ImportResponse{
+				Success: false,
+				Error:   "invalid rows",
+				Code:    errorCode(err, fiber.StatusUnprocessableEntity),
+				Errors:  rowErrors,
+			})
+		case errors.Is(err, serviceinterface.ErrPluginFailed):
+			return c.Status(fiber.StatusBadGateway).JSON(model.ImportResponse{
+				Success: false,
+				Error:   err.Error(),
+				Code:    errorCode(err, fiber.StatusBadGateway),
+			})
+		case errors.Is(err, serviceinterface.ErrTooManyEntries):
+			return c.Status(fiber.StatusInsufficientStorage).JSON(model.ImportResponse{
+				Success: false,
+				Error:   err.Error(),
+				Code:    errorCode(err, fiber.StatusInsufficientStorage),
+			})
+		case errors.Is(err, serviceinterface.ErrCacheUnavailable):
+			return c.Status(fiber.StatusServiceUnavailable).JSON(model.ImportResponse{
+				Success: false,
+				Error:   err.Error(),
+				Code:    errorCode(err, fiber.StatusServiceUnavailable),
+			})
+		}
+		return c.Status(fiber.StatusInternalServerError).JSON(model.ImportResponse{
+			Success: false,
+			Error:   err.Error(),
+			Code:    errorCode(err, fiber.StatusInternalServerError),
+		})
+	}
+
+	return c.JSON(model.ImportResponse{
+		Success: true,
+		Data:    result,
+	})
+}
+
+// parseImportCSV parses a CSV import into create requests and the line number of each request.
+// Rows that cannot be parsed are returned as row errors; an error is returned for an invalid header.
+func parseImportCSV(body []byte) ([]*model.CreateDomainRequest, []int, []model.ImportRowError, error) {
+	r := csv.NewReader(bytes.NewReader(bytes.TrimPrefix(body, utf8BOM)))
+
+	header, err := r.Read()
+	if errors.Is(err, io.EOF) {
+		return nil, nil, nil, errors.New("missing header")
+	}
+	if err != nil {
+		return nil, nil, nil, fmt.Errorf("invalid header: %w", err)
+	}
+
+	columns := make(map[string]int, len(header))
+	for i, name := range header {
+		name = strings.ToLower(strings.TrimSpace(name))
+		if !slices.Contains(exportCSVHeader, name) {
+			return nil, nil, nil, fmt.Errorf("unknown column: %s", name)
+		}
+		columns[name] = i
+	}
+	if _, ok := columns["domain"]; !ok {
+		return nil, nil, nil, errors.New("missing column: domain")
+	}
+
+	var reqs []*model.CreateDomainRequest
+	var lines []int
+	var rowErrors []model.ImportRowError
+	for {
+		record, err := r.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			rowErrors = append(rowErrors, csvRowError(err))
+			continue
+		}
+		line, _ := r.FieldPos(0)
+
+		value := func(column string) string {
+			if i, ok := columns[column]; ok {
+				return unescapeCSVFormula(strings.TrimSpace(record[i]))
+			}
+			return ""
+		}
+
+		// Without a value, the configured default applies
+		var enabled *bool
+		if v := value("enabled"); v != "" {
+			b, err := strconv.ParseBool(v)
+			if err != nil {
+				rowErrors = append(rowErrors, model.ImportRowError{Line: line, Error: fmt.Sprintf("invalid enabled: %s", v)})
+				continue
+			}
+			enabled = &b
+		}
+
+		var alternativeNames []string
+		for _, name := range strings.Split(value("alternative_names"), ";") {
+			if name = strings.TrimSpace(name); name != "" {
+				alternativeNames = append(alternativeNames, name)
+			}
+		}
+
+		reqs = append(reqs, &model.CreateDomainRequest{
+			Domain:           value("domain"),
+			AlternativeNames: alternativeNames,
+			Alias:            value("alias"),
+			Enabled:          enabled,
+			Comment:          value("comment"),
+		})
+		lines = append(lines, line)
+	}
+
+	return reqs, lines, rowErrors, nil
+}
+
+// csvRowError converts a CSV parse error into a row error.
+func csvRowError(err error) model.ImportRowError {
+	var parseErr *csv.ParseError
+	if errors.As(err, &parseErr) {
+		return model.ImportRowError{Line: parseErr.Line, Error: parseErr.Err.Error()}
+	}
+	return model.ImportRowError{Error: err.Error()}
+}
@@ -0,0 +1,168 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/require"
+
+	"github.com/schumann-it/dehydrated-api-go/internal/dehydrated"
+	"github.com/schumann-it/dehydrated-api-go/internal/model"
+	"github.com/schumann-it/dehydrated-api-go/internal/service"
+	serviceinterface "github.com/schumann-it/dehydrated-api-go/internal/service/interface"
+	"github.com/schumann-it/dehydrated-api-go/internal/util"
+	pb "github.com/schumann-it/dehydrated-api-go/plugin/proto"
+)
+
+func TestImportDomains(t *testing.T) {
+	setup := func(t *testing.T) (*fiber.App, *dehydrated.Config) {
+		dc := dehydrated.NewConfig().WithBaseDir(t.TempDir()).Load()
+		require.NoError(t, service.WriteDomainsFile(dc.DomainsFile, model.DomainEntries{
+			{DomainEntry: pb.DomainEntry{Domain: "existing.com", Enabled: true}},
+			{DomainEntry: pb.DomainEntry{Domain: "example.com", Comment: "old"}},
+		}))
+		s := service.NewDomainService(dc, nil)
+		t.Cleanup(func() { _ = s.Close() })
+		require.NoError(t, s.Reload())
+
+		app := fiber.New()
+		NewDomainHandler(s).RegisterRoutes(app.Group("/api/v1"))
+		return app, dc
+	}
+
+	post := func(t *testing.T, app *fiber.App, query, body string) (int, model.ImportResponse) {
+		req := httptest.NewRequest("POST", "/api/v1/domains/import"+query, strings.NewReader(body))
+		req.Header.Set(fiber.HeaderContentType, "text/csv")
+		resp, err := app.Test(req)
+		require.NoError(t, err)
+		defer resp.Body.Close()
+
+		var response model.ImportResponse
+		require.NoError(t, json.NewDecoder(resp.Body).Decode(&response))
+		return resp.StatusCode, response
+	}
+
+	valid := strings.Join([]string{
+		"domain,alternative_names,alias,enabled,comment",
+		"example.com,www.example.com;api.example.com,,true,\"Production, main\"",
+		"example.org,,org,false,",
+	}, "\n")
+
+	t.Run("Merge", func(t *testing.T) {
+		app, dc := setup(t)
+
+		status, response := post(t, app, "?format=csv", valid)
+		require.Equal(t, fiber.StatusOK, status)
+		require.True(t, response.Success)
+		require.Equal(t, &model.ImportResult{Created: 1, Updated: 1}, response.Data)
+
+		entries, err := service.ReadDomainsFile(dc.DomainsFile)
+		require.NoError(t, err)
+		require.Len(t, entries, 3)
+		require.Equal(t, "example.com", entries[0].Domain)
+		require.Equal(t, []string{"www.example.com", "api.example.com"}, entries[0].AlternativeNames)
+		require.Equal(t, "Production, main", entries[0].Comment)
+		require.True(t, entries[0].Enabled)
+	})
+
+	t.Run("Replace", func(t *testing.T) {
+		app, dc := setup(t)
+
+		status, response := post(t, app, "?mode=replace", valid)
+		require.Equal(t, fiber.StatusOK, status)
+		require.Equal(t, &model.ImportResult{Created: 1, Updated: 1, Removed: 1}, response.Data)
+
+		entries, err := service.ReadDomainsFile(dc.DomainsFile)
+		require.NoError(t, err)
+		require.Len(t, entries, 2)
+	})
+
+	t.Run("MalformedRowRejectsImport", func(t *testing.T) {
+		app, dc := setup(t)
+		before, err := service.ReadDomainsFile(dc.DomainsFile)
+		require.NoError(t, err)
+
+		body := strings.Join([]string{
+			"domain,alternative_names,alias,enabled,comment",
+			"example.net,,,true,",
+			"example.io,,,yes please,",
+			"broken.com,too,many,fields,in,this,row",
+			"not a domain,,,true,",
+		}, "\n")
+
+		status, response := post(t, app, "?mode=replace", body)
+		require.Equal(t, fiber.StatusBadRequest, status)
+		require.False(t, response.Success)
+		require.Len(t, response.Errors, 2)
+		require.Equal(t, 3, response.Errors[0].Line)
+		require.Contains(t, response.Errors[0].Error, "invalid enabled")
+		require.Equal(t, 4, response.Errors[1].Line)
+
+		after, err := service.ReadDomainsFile(dc.DomainsFile)
+		require.NoError(t, err)
+		require.Equal(t, before, after)
+	})
+
+	t.Run("InvalidEntryRejectsImport", func(t *testing.T) {
+		app, dc := setup(t)
+
+		body := strings.Join([]string{
+			"domain,enabled",
+			"example.net,true",
+			"not a domain,true",
+			"example.net,false",
+		}, "\n")
+
+		status, response := post(t, app, "?mode=replace", body)
+		require.Equal(t, fiber.StatusUnprocessableEntity, status)
+		require.Equal(t, []int{3, 4}, []int{response.Errors[0].Line, response.Errors[1].Line})
+		require.Contains(t, response.Errors[1].Error, "duplicates an earlier entry")
+
+		entries, err := service.ReadDomainsFile(dc.DomainsFile)
+		require.NoError(t, err)
+		require.Len(t, entries, 2)
+	})
+
+	t.Run("OmittedEnabled", func(t *testing.T) {
+		body := strings.Join([]string{
+			"domain,enabled",
+			"example.net,",
+			"example.io,false",
+			"example.org,true",
+		}, "\n")
+
+		reqs, _, rowErrors, err := parseImportCSV([]byte(body))
+		require.NoError(t, err)
+		require.Empty(t, rowErrors)
+		require.Nil(t, reqs[0].Enabled)
+		require.Equal(t, util.BoolPtr(false), reqs[1].Enabled)
+		require.Equal(t, util.BoolPtr(true), reqs[2].Enabled)
+	})
+
+	t.Run("InvalidParameters", func(t *testing.T) {
+		app, _ := setup(t)
+
+		for _, tt := range []struct{ query, body string }{
+			{"?format=json", valid},
+			{"?mode=sync", valid},
+			{"", ""},
+			{"", "domain,owner\nexample.com,me"},
+			{"", "alias\norg"},
+		} {
+			status, response := post(t, app, tt.query, tt.body)
+			require.Equal(t, fiber.StatusBadRequest, status, tt)
+			require.NotEmpty(t, response.Error)
+		}
+	})
+
+	t.Run("ServiceError", func(t *testing.T) {
+		errApp := fiber.New()
+		NewDomainHandler(&serviceinterface.MockErrDomainService{}).RegisterRoutes(errApp.Group("/api/v1"))
+
+		status, _ := post(t, errApp, "", valid)
+		require.Equal(t, fiber.StatusInternalServerError, status)
+	})
+}
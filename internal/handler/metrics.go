@@ -0,0 +1,63 @@
+package handler
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/schumann-it/dehydrated-api-go/internal/model"
+)
+
+// MetricsHandler handles HTTP requests for metrics in the Prometheus text format
+type MetricsHandler struct {
+	integrity func() *model.IntegrityStatus // Result of the last integrity check of the domains file
+}
+
+// NewMetricsHandler creates a new MetricsHandler instance
+func NewMetricsHandler() *MetricsHandler {
+	return &MetricsHandler{}
+}
+
+// WithIntegrityCheck exposes the result of the integrity check of the domains file returned by status,
+// which is nil until the first check.
+func (h *MetricsHandler) WithIntegrityCheck(status func() *model.IntegrityStatus) *MetricsHandler {
+	h.integrity = status
+	return h
+}
+
+// RegisterRoutes registers all metrics-related routes
+func (h *MetricsHandler) RegisterRoutes(app *fiber.App) {
+	app.Get("/metrics", h.Metrics)
+}
+
+// @Summary Metrics
+// @Description Get metrics in the Prometheus text format: domains_file_healthy and domains_file_last_check_timestamp_seconds once the integrity of the domains file has been checked
+// @Tags health
+// @Produce plain
+// @Success 200 {string} string "Metrics"
+// @Router /metrics [get]
+// Metrics handles GET /metrics
+func (h *MetricsHandler) Metrics(c *fiber.Ctx) error {
+	var b strings.Builder
+
+	if h.integrity != nil {
+		if status := h.integrity(); status != nil {
+			healthy := 0
+			if status.Healthy {
+				healthy = 1
+			}
+			writeGauge(&b, "domains_file_healthy",
+				"Whether the last integrity check of the domains file passed (1) or failed (0).", healthy)
+			writeGauge(&b, "domains_file_last_check_timestamp_seconds",
+				"Unix time of the last integrity check of the domains file.", status.CheckedAt.Unix())
+		}
+	}
+
+	c.Set(fiber.HeaderContentType, "text/plain; version=0.0.4; charset=utf-8")
+	return c.SendString(b.String())
+}
+
+// writeGauge writes a gauge with its help text to b.
+func writeGauge(b *strings.Builder, name, help string, value any) {
+	fmt.Fprintf(b, "# HELP %s %s\n# TYPE %s gauge\n%s %v\n", name, help, name, name, value)
+}
@@ -0,0 +1,64 @@
+package handler
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/schumann-it/dehydrated-api-go/internal/model"
+	"github.com/stretchr/testify/require"
+)
+
+// metrics performs a GET /metrics request against a metrics handler and returns the body.
+func metrics(t *testing.T, h *MetricsHandler) string {
+	t.Helper()
+
+	app := fiber.New()
+	h.RegisterRoutes(app)
+
+	result, err := app.Test(httptest.NewRequest("GET", "/metrics", http.NoBody))
+	require.NoError(t, err)
+	defer result.Body.Close()
+	require.Equal(t, fiber.StatusOK, result.StatusCode)
+	require.Contains(t, result.Header.Get(fiber.HeaderContentType), "text/plain")
+
+	body, err := io.ReadAll(result.Body)
+	require.NoError(t, err)
+	return string(body)
+}
+
+// TestMetrics verifies the integrity check gauges of the metrics endpoint.
+func TestMetrics(t *testing.T) {
+	t.Run("WithoutIntegrityCheck", func(t *testing.T) {
+		require.Empty(t, metrics(t, NewMetricsHandler()))
+	})
+
+	t.Run("NotCheckedYet", func(t *testing.T) {
+		h := NewMetricsHandler().WithIntegrityCheck(func() *model.IntegrityStatus { return nil })
+		require.Empty(t, metrics(t, h))
+	})
+
+	checkedAt := time.Unix(1704067200, 0)
+	for _, tt := range []struct {
+		name    string
+		healthy bool
+		gauge   string
+	}{
+		{"Healthy", true, "domains_file_healthy 1\n"},
+		{"Unhealthy", false, "domains_file_healthy 0\n"},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			h := NewMetricsHandler().WithIntegrityCheck(func() *model.IntegrityStatus {
+				return &model.IntegrityStatus{Healthy: tt.healthy, CheckedAt: checkedAt}
+			})
+
+			body := metrics(t, h)
+			require.Contains(t, body, "# TYPE domains_file_healthy gauge\n")
+			require.Contains(t, body, tt.gauge)
+			require.Contains(t, body, "domains_file_last_check_timestamp_seconds 1704067200\n")
+		})
+	}
+}
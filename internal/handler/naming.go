@@ -0,0 +1,185 @@
+package handler
+
+import (
+	"bytes"
+	"encoding/json"
+	"mime"
+	"strings"
+	"unicode"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/schumann-it/dehydrated-api-go/internal/model"
+)
+
+// namingParam is the Accept media type parameter clients use to negotiate the naming of JSON fields,
+// e.g., "Accept: application/json; naming=camelCase".
+const namingParam = "naming"
+
+// opaqueFields are the fields whose values are passed through unchanged, since their keys are data,
+// e.g., the metadata keys of plugins.
+var opaqueFields = map[string]bool{"metadata": true}
+
+// opaqueKeyFields are the fields whose object keys are passed through unchanged, while the field names
+// of their values are converted, e.g., the provenance of metadata by metadata key.
+var opaqueKeyFields = map[string]bool{"metadata_provenance": true}
+
+// FieldNaming creates middleware that converts the names of the fields of JSON requests and responses
+// from and to camelCase if that is the negotiated naming. The naming parameter of the Accept header
+// takes precedence over defaultNaming. The field names of the API are snake_case, so nothing is
+// converted otherwise. Query parameters keep their names, the paths of JSON Patch documents may use either naming.
+func FieldNaming(defaultNaming string) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		if negotiateNaming(c, defaultNaming) != model.FieldNamingCamelCase {
+			return c.Next()
+		}
+
+		if isJSON(string(c.Request().Header.ContentType())) {
+			// Invalid bodies are left as they are and rejected by the handlers
+			if body, err := convertFieldNames(c.Body(), camelToSnake); err == nil {
+				c.Request().SetBody(body)
+			}
+		}
+
+		if err := c.Next(); err != nil {
+			// Errors are converted to responses by the error handler of the app, after the middleware
+			if err = c.App().ErrorHandler(c, err); err != nil {
+				return err
+			}
+		}
+
+		if isJSON(string(c.Response().Header.ContentType())) {
+			if body, err := convertFieldNames(c.Response().Body(), snakeToCamel); err == nil {
+				c.Response().SetBody(body)
+			}
+		}
+		return nil
+	}
+}
+
+// negotiateNaming returns the field naming of the request, defaultNaming unless the client requests one
+// with the naming parameter of the Accept header.
+func negotiateNaming(c *fiber.Ctx, defaultNaming string) string {
+	for _, part := range strings.Split(c.Get(fiber.HeaderAccept), ",") {
+		_, params, err := mime.ParseMediaType(strings.TrimSpace(part))
+		if err != nil {
+			continue
+		}
+		if naming := params[namingParam]; model.IsValidFieldNaming(naming) {
+			return naming
+		}
+	}
+	return defaultNaming
+}
+
+// isJSON reports whether contentType is application/json. JSON Patch documents are not included,
+// their paths are resolved in either naming by applyPatch.
+func isJSON(contentType string) bool {
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	return err == nil && mediaType == fiber.MIMEApplicationJSON
+}
+
+// convertFieldNames returns the JSON document data with the object keys renamed by rename,
+// except for the keys below opaqueFields and opaqueKeyFields.
+func convertFieldNames(data []byte, rename func(string) string) ([]byte, error) {
+	if len(bytes.TrimSpace(data)) == 0 {
+		return data, nil
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	var buf bytes.Buffer
+	if err := copyJSON(decoder, &buf, rename, false); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// copyJSON copies the next JSON value of decoder to buf, renaming object keys with rename unless it is nil.
+// If keepKeys is set, the keys of the object are kept and only the keys of its values are renamed.
+// The order of the keys and the representation of numbers are kept.
+func copyJSON(decoder *json.Decoder, buf *bytes.Buffer, rename func(string) string, keepKeys bool) error {
+	token, err := decoder.Token()
+	if err != nil {
+		return err
+	}
+
+	delim, ok := token.(json.Delim)
+	if !ok {
+		// json.Number is encoded as is, strings are escaped
+		value, err := json.Marshal(token)
+		if err != nil {
+			return err
+		}
+		buf.Write(value)
+		return nil
+	}
+
+	buf.WriteRune(rune(delim))
+	for i := 0; decoder.More(); i++ {
+		if i > 0 {
+			buf.WriteByte(',')
+		}
+
+		valueRename, valueKeepKeys := rename, false
+		if delim == '{' {
+			token, err := decoder.Token()
+			if err != nil {
+				return err
+			}
+			name := token.(string)
+			if rename != nil && !keepKeys {
+				// The opaque fields are known by their snake_case names
+				snake := camelToSnake(name)
+				if opaqueFields[snake] {
+					valueRename = nil
+				}
+				valueKeepKeys = opaqueKeyFields[snake]
+				name = rename(name)
+			}
+			encoded, err := json.Marshal(name)
+			if err != nil {
+				return err
+			}
+			buf.Write(encoded)
+			buf.WriteByte(':')
+		}
+
+		if err := copyJSON(decoder, buf, valueRename, valueKeepKeys); err != nil {
+			return err
+		}
+	}
+	// The closing delimiter
+	token, err = decoder.Token()
+	if err != nil {
+		return err
+	}
+	buf.WriteRune(rune(token.(json.Delim)))
+	return nil
+}
+
+// snakeToCamel converts a snake_case name to camelCase, e.g., "alternative_names" to "alternativeNames".
+func snakeToCamel(name string) string {
+	parts := strings.Split(name, "_")
+	for i := 1; i < len(parts); i++ {
+		if parts[i] != "" {
+			parts[i] = strings.ToUpper(parts[i][:1]) + parts[i][1:]
+		}
+	}
+	return strings.Join(parts, "")
+}
+
+// camelToSnake converts a camelCase name to snake_case, e.g., "alternativeNames" to "alternative_names".
+// snake_case names are returned unchanged.
+func camelToSnake(name string) string {
+	var b strings.Builder
+	for i, r := range name {
+		if unicode.IsUpper(r) {
+			if i > 0 {
+				b.WriteByte('_')
+			}
+			r = unicode.ToLower(r)
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
@@ -0,0 +1,111 @@
+package handler
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/schumann-it/dehydrated-api-go/internal/dehydrated"
+	"github.com/schumann-it/dehydrated-api-go/internal/model"
+	"github.com/schumann-it/dehydrated-api-go/internal/service"
+	"github.com/stretchr/testify/require"
+)
+
+func TestConvertFieldNames(t *testing.T) {
+	in := `{"alternative_names":["www.example.com"],"per_page":10,"big":12345678901234567890,"metadata":{"netbox":{"site_name":"a"}},` +
+		`"metadata_provenance":{"netbox.site_name":{"duration_ms":1.5}},"data":[{"has_next":true,"comment":"<a_b>"}],"empty":{}}`
+	out, err := convertFieldNames([]byte(in), snakeToCamel)
+	require.NoError(t, err)
+	require.Equal(t, `{"alternativeNames":["www.example.com"],"perPage":10,"big":12345678901234567890,"metadata":{"netbox":{"site_name":"a"}},`+
+		`"metadataProvenance":{"netbox.site_name":{"durationMs":1.5}},"data":[{"hasNext":true,"comment":"\u003ca_b\u003e"}],"empty":{}}`, string(out))
+
+	back, err := convertFieldNames(out, camelToSnake)
+	require.NoError(t, err)
+	require.JSONEq(t, in, string(back))
+
+	_, err = convertFieldNames([]byte(`{"domain":`), snakeToCamel)
+	require.Error(t, err)
+}
+
+// TestFieldNaming verifies the camelCase naming of request and response fields and its negotiation.
+func TestFieldNaming(t *testing.T) {
+	dc := dehydrated.NewConfig().WithBaseDir(t.TempDir()).Load()
+	s := service.NewDomainService(dc, nil)
+	t.Cleanup(func() { s.Close() })
+
+	newApp := func(naming string) *fiber.App {
+		app := fiber.New(fiber.Config{ErrorHandler: ErrorHandler})
+		NewDomainHandler(s).RegisterRoutes(app.Group("/api/v1", FieldNaming(naming)))
+		return app
+	}
+
+	do := func(t *testing.T, app *fiber.App, method, path, accept, body string) (int, string) {
+		req := httptest.NewRequest(method, path, strings.NewReader(body))
+		if body != "" {
+			req.Header.Set(fiber.HeaderContentType, fiber.MIMEApplicationJSON)
+		}
+		if accept != "" {
+			req.Header.Set(fiber.HeaderAccept, accept)
+		}
+		resp, err := app.Test(req)
+		require.NoError(t, err)
+		defer resp.Body.Close()
+		data, err := io.ReadAll(resp.Body)
+		require.NoError(t, err)
+		return resp.StatusCode, string(data)
+	}
+
+	camel := newApp(model.FieldNamingCamelCase)
+	snake := newApp(model.FieldNamingSnakeCase)
+
+	t.Run("CamelCaseRequest", func(t *testing.T) {
+		status, body := do(t, camel, "POST", "/api/v1/domains", "", `{"domain":"example.com","alternativeNames":["www.example.com"],"enabled":true}`)
+		require.Equal(t, fiber.StatusCreated, status)
+		require.Contains(t, body, `"alternativeNames":["www.example.com"]`)
+		require.NotContains(t, body, "alternative_names")
+	})
+
+	t.Run("CamelCaseList", func(t *testing.T) {
+		status, body := do(t, camel, "GET", "/api/v1/domains", "", "")
+		require.Equal(t, fiber.StatusOK, status)
+		require.Contains(t, body, `"perPage":`)
+		require.Contains(t, body, `"alternativeNames":`)
+	})
+
+	t.Run("CamelCaseError", func(t *testing.T) {
+		status, body := do(t, camel, "GET", "/api/v1/domains/missing.example.com", "", "")
+		require.Equal(t, fiber.StatusNotFound, status)
+		require.Contains(t, body, `"success":false`)
+	})
+
+	t.Run("Default", func(t *testing.T) {
+		status, body := do(t, snake, "GET", "/api/v1/domains/example.com", "", "")
+		require.Equal(t, fiber.StatusOK, status)
+		require.Contains(t, body, `"alternative_names":["www.example.com"]`)
+	})
+
+	t.Run("AcceptCamelCase", func(t *testing.T) {
+		status, body := do(t, snake, "GET", "/api/v1/domains/example.com", "application/json; naming=camelCase", "")
+		require.Equal(t, fiber.StatusOK, status)
+		require.Contains(t, body, `"alternativeNames":["www.example.com"]`)
+	})
+
+	t.Run("AcceptSnakeCase", func(t *testing.T) {
+		status, body := do(t, camel, "GET", "/api/v1/domains/example.com", "application/json; naming=snake_case", "")
+		require.Equal(t, fiber.StatusOK, status)
+		require.Contains(t, body, `"alternative_names":["www.example.com"]`)
+	})
+
+	t.Run("NotJSON", func(t *testing.T) {
+		req := httptest.NewRequest("GET", "/api/v1/domains/export?format=csv", http.NoBody)
+		resp, err := camel.Test(req)
+		require.NoError(t, err)
+		defer resp.Body.Close()
+		data, err := io.ReadAll(resp.Body)
+		require.NoError(t, err)
+		require.True(t, strings.HasPrefix(string(data), "domain,alternative_names,"), string(data))
+	})
+}
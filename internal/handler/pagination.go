@@ -0,0 +1,125 @@
+package handler
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/schumann-it/dehydrated-api-go/internal/model"
+)
+
+// errInvalidPage is returned by parsePagination for pages below MinPage.
+var errInvalidPage = errors.New("page parameter must be at least 1")
+
+// parsePagination parses the page and per_page query parameters shared by all paginated endpoints.
+// per_page is capped to the range of MinPerPage to MaxPerPage, an invalid page is an error.
+func parsePagination(c *fiber.Ctx) (page, perPage int, err error) {
+	page = c.QueryInt("page", 1)
+	perPage = c.QueryInt("per_page", model.DefaultPerPage)
+
+	if page < model.MinPage {
+		return 0, 0, errInvalidPage
+	}
+
+	if perPage < model.MinPerPage {
+		perPage = model.MinPerPage
+	} else if perPage > model.MaxPerPage {
+		perPage = model.MaxPerPage
+	}
+
+	return page, perPage, nil
+}
+
+// setPagination completes the pagination information with the next and previous URLs and exposes it
+// as Link header, and as X-* headers for bare list responses.
+func (h *DomainHandler) setPagination(c *fiber.Ctx, pagination *model.PaginationInfo) {
+	if pagination == nil {
+		return
+	}
+
+	h.generatePaginationURLs(c, pagination)
+	h.setLinkHeader(c, pagination)
+	if wantsBare(c, h.responseFormat) {
+		setPaginationHeaders(c, pagination)
+	}
+}
+
+// generatePaginationURLs generates the next and previous URLs for pagination
+func (h *DomainHandler) generatePaginationURLs(c *fiber.Ctx, pagination *model.PaginationInfo) {
+	if pagination.HasNext {
+		pagination.NextURL = h.pageURL(c, pagination.CurrentPage+1, pagination.PerPage)
+	}
+	if pagination.HasPrev {
+		pagination.PrevURL = h.pageURL(c, pagination.CurrentPage-1, pagination.PerPage)
+	}
+}
+
+// setLinkHeader exposes the pagination URLs as RFC 5988 Link header (next, prev, first and last)
+func (h *DomainHandler) setLinkHeader(c *fiber.Ctx, pagination *model.PaginationInfo) {
+	var links []string
+	if pagination.NextURL != "" {
+		links = append(links, fmt.Sprintf(`<%s>; rel="next"`, pagination.NextURL))
+	}
+	if pagination.PrevURL != "" {
+		links = append(links, fmt.Sprintf(`<%s>; rel="prev"`, pagination.PrevURL))
+	}
+	if pagination.TotalPages > 0 {
+		links = append(links,
+			fmt.Sprintf(`<%s>; rel="first"`, h.pageURL(c, 1, pagination.PerPage)),
+			fmt.Sprintf(`<%s>; rel="last"`, h.pageURL(c, pagination.TotalPages, pagination.PerPage)))
+	}
+
+	if len(links) > 0 {
+		c.Set(fiber.HeaderLink, strings.Join(links, ", "))
+	}
+}
+
+// pageURL returns the URL of the given page, keeping all other query parameters of the request
+func (h *DomainHandler) pageURL(c *fiber.Ctx, page, perPage int) string {
+	params := url.Values{}
+
+	// Add existing query parameters (except pagination ones)
+	c.Context().QueryArgs().VisitAll(func(key, value []byte) {
+		keyStr := string(key)
+		if keyStr != "page" && keyStr != "per_page" {
+			params.Add(keyStr, string(value))
+		}
+	})
+
+	// Always include per_page in URLs
+	params.Set("per_page", strconv.Itoa(perPage))
+	params.Set("page", strconv.Itoa(page))
+
+	return h.buildURL(h.requestURL(c), params)
+}
+
+// requestURL returns the URL of the request without query parameters, used for links to other resources.
+// The scheme and host are those of the
+// configured base URL if set, or those of the request otherwise, which Fiber takes from the X-Forwarded-Proto
+// and X-Forwarded-Host headers of trusted proxies.
+func (h *DomainHandler) requestURL(c *fiber.Ctx) string {
+	if h.baseURL != "" {
+		return h.baseURL + c.Path()
+	}
+	return c.BaseURL() + c.Path()
+}
+
+// setPaginationHeaders exposes the pagination information as response headers for bare list responses
+func setPaginationHeaders(c *fiber.Ctx, pagination *model.PaginationInfo) {
+	c.Set("X-Total-Count", strconv.Itoa(pagination.Total))
+	c.Set("X-Page", strconv.Itoa(pagination.CurrentPage))
+	c.Set("X-Per-Page", strconv.Itoa(pagination.PerPage))
+	c.Set("X-Total-Pages", strconv.Itoa(pagination.TotalPages))
+}
+
+// buildURL constructs a URL with properly encoded query parameters
+func (h *DomainHandler) buildURL(baseURL string, params url.Values) string {
+	if len(params) == 0 {
+		return baseURL
+	}
+
+	return baseURL + "?" + params.Encode()
+}
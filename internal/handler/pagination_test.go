@@ -0,0 +1,191 @@
+package handler
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/require"
+
+	"github.com/schumann-it/dehydrated-api-go/internal/model"
+	serviceinterface "github.com/schumann-it/dehydrated-api-go/internal/service/interface"
+	pb "github.com/schumann-it/dehydrated-api-go/plugin/proto"
+)
+
+// paginationService serves the same number of domains, plugins and plugin errors.
+type paginationService struct {
+	serviceinterface.MockDomainService
+	total int
+}
+
+func (s *paginationService) ListDomains(page, perPage int, _, _ string, _ ...serviceinterface.QueryOption) ([]*model.DomainEntry, *model.PaginationInfo, error) {
+	pagination := model.NewPaginationInfo(page, perPage, s.total)
+	start, end := pagination.Bounds()
+	entries := make([]*model.DomainEntry, 0, end-start)
+	for i := start; i < end; i++ {
+		entries = append(entries, &model.DomainEntry{DomainEntry: pb.DomainEntry{Domain: fmt.Sprintf("%d.example.com", i)}})
+	}
+	return entries, pagination, nil
+}
+
+func (s *paginationService) Plugins(page, perPage int) ([]*model.PluginInfo, *model.PaginationInfo, error) {
+	pagination := model.NewPaginationInfo(page, perPage, s.total)
+	start, end := pagination.Bounds()
+	plugins := make([]*model.PluginInfo, 0, end-start)
+	for i := start; i < end; i++ {
+		plugins = append(plugins, &model.PluginInfo{Name: fmt.Sprintf("plugin%d", i)})
+	}
+	return plugins, pagination, nil
+}
+
+func (s *paginationService) PluginErrors(page, perPage int) ([]*model.PluginError, *model.PaginationInfo, error) {
+	pagination := model.NewPaginationInfo(page, perPage, s.total)
+	start, end := pagination.Bounds()
+	errs := make([]*model.PluginError, 0, end-start)
+	for i := start; i < end; i++ {
+		errs = append(errs, &model.PluginError{Domain: fmt.Sprintf("%d.example.com", i), Plugin: "p"})
+	}
+	return errs, pagination, nil
+}
+
+// TestPaginationPublicURL verifies that pagination links use the public URL of the server behind a reverse proxy,
+// taken from the configured base URL or the X-Forwarded-* headers of trusted proxies.
+func TestPaginationPublicURL(t *testing.T) {
+	// Requests of app.Test come from 0.0.0.0
+	tests := []struct {
+		name     string
+		config   fiber.Config
+		baseURL  string
+		expected string
+	}{
+		{"TrustedProxy", fiber.Config{EnableTrustedProxyCheck: true, TrustedProxies: []string{"0.0.0.0"}}, "", "https://acme.example.com"},
+		{"AnyProxy", fiber.Config{}, "", "https://acme.example.com"},
+		{"UntrustedProxy", fiber.Config{EnableTrustedProxyCheck: true, TrustedProxies: []string{"10.0.0.0/8"}}, "", "http://example.com"},
+		{"BaseURL", fiber.Config{EnableTrustedProxyCheck: true}, "https://example.org/acme/", "https://example.org/acme"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			app := fiber.New(tt.config)
+			NewDomainHandler(&paginationService{total: 5}).WithBaseURL(tt.baseURL).RegisterRoutes(app.Group("/api/v1"))
+
+			req := httptest.NewRequest("GET", "/api/v1/domains?page=2&per_page=2", http.NoBody)
+			req.Header.Set(fiber.HeaderXForwardedProto, "https")
+			req.Header.Set(fiber.HeaderXForwardedHost, "acme.example.com")
+			resp, err := app.Test(req)
+			require.NoError(t, err)
+			defer resp.Body.Close()
+
+			var r struct {
+				Pagination *model.PaginationInfo `json:"pagination"`
+			}
+			require.NoError(t, json.NewDecoder(resp.Body).Decode(&r))
+			require.Equal(t, tt.expected+"/api/v1/domains?page=3&per_page=2", r.Pagination.NextURL)
+			require.Equal(t, tt.expected+"/api/v1/domains?page=1&per_page=2", r.Pagination.PrevURL)
+			require.Contains(t, resp.Header.Get(fiber.HeaderLink), "<"+tt.expected+"/api/v1/domains?page=1&per_page=2>; rel=\"first\"")
+		})
+	}
+}
+
+// TestPaginationConsistency verifies that all paginated endpoints handle the pagination parameters
+// and report the pagination metadata the same way.
+func TestPaginationConsistency(t *testing.T) {
+	app := fiber.New()
+	NewDomainHandler(&paginationService{total: 5}).RegisterRoutes(app.Group("/api/v1"))
+
+	type response struct {
+		Success    bool                  `json:"success"`
+		Data       []json.RawMessage     `json:"data"`
+		Pagination *model.PaginationInfo `json:"pagination"`
+		Error      string                `json:"error"`
+	}
+
+	get := func(t *testing.T, url, accept string) (*http.Response, []byte) {
+		req := httptest.NewRequest("GET", url, http.NoBody)
+		if accept != "" {
+			req.Header.Set("Accept", accept)
+		}
+		resp, err := app.Test(req)
+		require.NoError(t, err)
+		t.Cleanup(func() { resp.Body.Close() })
+
+		var body json.RawMessage
+		require.NoError(t, json.NewDecoder(resp.Body).Decode(&body))
+		return resp, body
+	}
+
+	for _, path := range []string{"/api/v1/domains", "/api/v1/plugins", "/api/v1/plugins/errors"} {
+		t.Run(path, func(t *testing.T) {
+			t.Run("Page", func(t *testing.T) {
+				resp, body := get(t, path+"?page=2&per_page=2", "")
+				require.Equal(t, fiber.StatusOK, resp.StatusCode)
+
+				var r response
+				require.NoError(t, json.Unmarshal(body, &r))
+				require.True(t, r.Success)
+				require.Len(t, r.Data, 2)
+				require.Equal(t, &model.PaginationInfo{
+					CurrentPage: 2,
+					PerPage:     2,
+					Total:       5,
+					TotalPages:  3,
+					HasNext:     true,
+					HasPrev:     true,
+					NextURL:     "http://example.com" + path + "?page=3&per_page=2",
+					PrevURL:     "http://example.com" + path + "?page=1&per_page=2",
+				}, r.Pagination)
+
+				base := "http://example.com" + path
+				require.Equal(t, fmt.Sprintf(`<%[1]s?page=3&per_page=2>; rel="next", <%[1]s?page=1&per_page=2>; rel="prev", `+
+					`<%[1]s?page=1&per_page=2>; rel="first", <%[1]s?page=3&per_page=2>; rel="last"`, base),
+					resp.Header.Get(fiber.HeaderLink))
+			})
+
+			t.Run("BeyondLastPage", func(t *testing.T) {
+				_, body := get(t, path+"?page=9&per_page=2", "")
+
+				var r response
+				require.NoError(t, json.Unmarshal(body, &r))
+				require.Empty(t, r.Data)
+				require.False(t, r.Pagination.HasNext)
+				require.True(t, r.Pagination.HasPrev)
+			})
+
+			t.Run("PerPageCapped", func(t *testing.T) {
+				for perPage, want := range map[string]int{"0": model.MinPerPage, "5000": model.MaxPerPage} {
+					_, body := get(t, path+"?per_page="+perPage, "")
+
+					var r response
+					require.NoError(t, json.Unmarshal(body, &r))
+					require.Equal(t, want, r.Pagination.PerPage, perPage)
+				}
+			})
+
+			t.Run("InvalidPage", func(t *testing.T) {
+				resp, body := get(t, path+"?page=0", "")
+				require.Equal(t, fiber.StatusBadRequest, resp.StatusCode)
+
+				var r response
+				require.NoError(t, json.Unmarshal(body, &r))
+				require.False(t, r.Success)
+				require.Equal(t, "page parameter must be at least 1", r.Error)
+			})
+
+			t.Run("Bare", func(t *testing.T) {
+				resp, body := get(t, path+"?per_page=2", "application/json; envelope=false")
+				require.Equal(t, fiber.StatusOK, resp.StatusCode)
+
+				var items []json.RawMessage
+				require.NoError(t, json.Unmarshal(body, &items))
+				require.Len(t, items, 2)
+				require.Equal(t, "5", resp.Header.Get("X-Total-Count"))
+				require.Equal(t, "1", resp.Header.Get("X-Page"))
+				require.Equal(t, "2", resp.Header.Get("X-Per-Page"))
+				require.Equal(t, "3", resp.Header.Get("X-Total-Pages"))
+				require.Contains(t, resp.Header.Get(fiber.HeaderLink), `rel="next"`)
+			})
+		})
+	}
+}
@@ -0,0 +1,143 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/schumann-it/dehydrated-api-go/internal/model"
+)
+
+// MIMEApplicationJSONPatch is the content type of JSON Patch (RFC 6902) request bodies.
+const MIMEApplicationJSONPatch = "application/json-patch+json"
+
+// errPatchPathNotFound is returned for remove and replace operations on a member the entry does not have.
+// RFC 6902 requires the target of these operations to exist.
+var errPatchPathNotFound = errors.New("path does not exist")
+
+// PatchOperation is a single operation of a JSON Patch (RFC 6902) document.
+type PatchOperation struct {
+	Op    string          `json:"op"`
+	Path  string          `json:"path"`
+	Value json.RawMessage `json:"value,omitempty"`
+}
+
+// applyPatch applies the JSON Patch operations add, remove and replace to the mutable fields
+// of entry (alternative_names, enabled, comment and alias). The paths may use either field naming,
+// e.g., /alternativeNames. Operations are applied in order, the first failing operation aborts the patch.
+func applyPatch(entry *model.DomainEntry, ops []PatchOperation) error {
+	for i, op := range ops {
+		if err := applyPatchOperation(entry, op); err != nil {
+			return fmt.Errorf("patch operation %d: %w", i, err)
+		}
+	}
+	return nil
+}
+
+// applyPatchOperation applies a single JSON Patch operation to entry.
+func applyPatchOperation(entry *model.DomainEntry, op PatchOperation) error {
+	switch op.Op {
+	case "add", "remove", "replace":
+	default:
+		return fmt.Errorf("unsupported operation %q", op.Op)
+	}
+
+	if op.Op != "remove" && op.Value == nil {
+		return fmt.Errorf("missing value for %s %s", op.Op, op.Path)
+	}
+
+	segments := strings.Split(strings.TrimPrefix(op.Path, "/"), "/")
+	segments[0] = camelToSnake(segments[0])
+	if !strings.HasPrefix(op.Path, "/") || len(segments) > 2 || (len(segments) == 2 && segments[0] != "alternative_names") {
+		return fmt.Errorf("invalid path %q", op.Path)
+	}
+
+	switch segments[0] {
+	case "alternative_names":
+		if len(segments) == 2 {
+			return patchAlternativeName(entry, op, segments[1])
+		}
+		if op.Op == "remove" {
+			entry.AlternativeNames = []string{}
+			return nil
+		}
+		var names []string
+		if err := json.Unmarshal(op.Value, &names); err != nil {
+			return fmt.Errorf("invalid value for %s: %w", op.Path, err)
+		}
+		entry.AlternativeNames = names
+	case "enabled":
+		if op.Op == "remove" {
+			return fmt.Errorf("cannot remove %s", op.Path)
+		}
+		if err := json.Unmarshal(op.Value, &entry.Enabled); err != nil {
+			return fmt.Errorf("invalid value for %s: %w", op.Path, err)
+		}
+	case "comment", "alias":
+		current := entry.Comment
+		if segments[0] == "alias" {
+			current = entry.Alias
+		}
+		if op.Op != "add" && current == "" {
+			return fmt.Errorf("%s %s: %w", op.Op, op.Path, errPatchPathNotFound)
+		}
+		value := ""
+		if op.Op != "remove" {
+			if err := json.Unmarshal(op.Value, &value); err != nil {
+				return fmt.Errorf("invalid value for %s: %w", op.Path, err)
+			}
+		}
+		if segments[0] == "comment" {
+			entry.Comment = value
+		} else {
+			entry.Alias = value
+		}
+	case "domain", "metadata":
+		return fmt.Errorf("path %s is immutable", op.Path)
+	default:
+		return fmt.Errorf("unknown path %q", op.Path)
+	}
+
+	return nil
+}
+
+// patchAlternativeName applies a JSON Patch operation to a single element of the alternative names.
+// The index "-" refers to the end of the list and is only valid for add.
+func patchAlternativeName(entry *model.DomainEntry, op PatchOperation, index string) error {
+	names := entry.AlternativeNames
+
+	i := len(names)
+	if index != "-" || op.Op != "add" {
+		var err error
+		i, err = strconv.Atoi(index)
+		if err != nil || i < 0 || i > len(names) || (op.Op != "add" && i == len(names)) {
+			return fmt.Errorf("invalid index in path %q", op.Path)
+		}
+	}
+
+	var name string
+	if op.Op != "remove" {
+		if err := json.Unmarshal(op.Value, &name); err != nil {
+			return fmt.Errorf("invalid value for %s: %w", op.Path, err)
+		}
+	}
+
+	result := make([]string, 0, len(names)+1)
+	switch op.Op {
+	case "add":
+		result = append(result, names[:i]...)
+		result = append(result, name)
+		result = append(result, names[i:]...)
+	case "remove":
+		result = append(result, names[:i]...)
+		result = append(result, names[i+1:]...)
+	case "replace":
+		result = append(result, names...)
+		result[i] = name
+	}
+	entry.AlternativeNames = result
+
+	return nil
+}
@@ -0,0 +1,193 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/schumann-it/dehydrated-api-go/internal/dehydrated"
+	"github.com/schumann-it/dehydrated-api-go/internal/model"
+	"github.com/schumann-it/dehydrated-api-go/internal/service"
+	"github.com/schumann-it/dehydrated-api-go/internal/util"
+	pb "github.com/schumann-it/dehydrated-api-go/plugin/proto"
+	"github.com/stretchr/testify/require"
+)
+
+// TestApplyPatch verifies the supported JSON Patch operations on the mutable fields of an entry.
+func TestApplyPatch(t *testing.T) {
+	tests := []struct {
+		name    string
+		patch   string
+		want    *pb.DomainEntry
+		wantErr string
+	}{
+		{
+			name:  "ReplaceScalars",
+			patch: `[{"op":"replace","path":"/enabled","value":false},{"op":"replace","path":"/comment","value":"patched"}]`,
+			want:  &pb.DomainEntry{AlternativeNames: []string{"a.example.com", "b.example.com"}, Alias: "rsa", Enabled: false, Comment: "patched"},
+		},
+		{
+			name:  "AddAlternativeNames",
+			patch: `[{"op":"add","path":"/alternative_names/-","value":"c.example.com"},{"op":"add","path":"/alternative_names/0","value":"0.example.com"}]`,
+			want:  &pb.DomainEntry{AlternativeNames: []string{"0.example.com", "a.example.com", "b.example.com", "c.example.com"}, Alias: "rsa", Enabled: true, Comment: "initial"},
+		},
+		{
+			name:  "RemoveAlternativeName",
+			patch: `[{"op":"remove","path":"/alternative_names/0"}]`,
+			want:  &pb.DomainEntry{AlternativeNames: []string{"b.example.com"}, Alias: "rsa", Enabled: true, Comment: "initial"},
+		},
+		{
+			name:  "ReplaceAlternativeNames",
+			patch: `[{"op":"replace","path":"/alternative_names","value":["x.example.com"]},{"op":"remove","path":"/alias"}]`,
+			want:  &pb.DomainEntry{AlternativeNames: []string{"x.example.com"}, Enabled: true, Comment: "initial"},
+		},
+		{
+			name:  "CamelCasePath",
+			patch: `[{"op":"add","path":"/alternativeNames/-","value":"c.example.com"}]`,
+			want:  &pb.DomainEntry{AlternativeNames: []string{"a.example.com", "b.example.com", "c.example.com"}, Alias: "rsa", Enabled: true, Comment: "initial"},
+		},
+		{
+			name:  "AddMissingComment",
+			patch: `[{"op":"remove","path":"/comment"},{"op":"add","path":"/comment","value":"added"}]`,
+			want:  &pb.DomainEntry{AlternativeNames: []string{"a.example.com", "b.example.com"}, Alias: "rsa", Enabled: true, Comment: "added"},
+		},
+		{name: "ReplaceMissingComment", patch: `[{"op":"remove","path":"/comment"},{"op":"replace","path":"/comment","value":"x"}]`, wantErr: "path does not exist"},
+		{name: "RemoveMissingAlias", patch: `[{"op":"remove","path":"/alias"},{"op":"remove","path":"/alias"}]`, wantErr: "path does not exist"},
+		{name: "ImmutableDomain", patch: `[{"op":"replace","path":"/domain","value":"other.com"}]`, wantErr: "immutable"},
+		{name: "UnknownPath", patch: `[{"op":"replace","path":"/foo","value":"bar"}]`, wantErr: "unknown path"},
+		{name: "UnsupportedOperation", patch: `[{"op":"move","from":"/comment","path":"/alias"}]`, wantErr: "unsupported operation"},
+		{name: "IndexOutOfRange", patch: `[{"op":"remove","path":"/alternative_names/2"}]`, wantErr: "invalid index"},
+		{name: "RemoveEnabled", patch: `[{"op":"remove","path":"/enabled"}]`, wantErr: "cannot remove"},
+		{name: "MissingValue", patch: `[{"op":"replace","path":"/comment"}]`, wantErr: "missing value"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			entry := &model.DomainEntry{DomainEntry: pb.DomainEntry{
+				AlternativeNames: []string{"a.example.com", "b.example.com"},
+				Alias:            "rsa",
+				Enabled:          true,
+				Comment:          "initial",
+			}}
+
+			var ops []PatchOperation
+			require.NoError(t, json.Unmarshal([]byte(tt.patch), &ops))
+
+			err := applyPatch(entry, ops)
+			if tt.wantErr != "" {
+				require.ErrorContains(t, err, tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tt.want.AlternativeNames, entry.AlternativeNames)
+			require.Equal(t, tt.want.Alias, entry.Alias)
+			require.Equal(t, tt.want.Enabled, entry.Enabled)
+			require.Equal(t, tt.want.Comment, entry.Comment)
+		})
+	}
+}
+
+// TestPatchDomain verifies JSON Patch requests on the update endpoint.
+func TestPatchDomain(t *testing.T) {
+	dc := dehydrated.NewConfig().WithBaseDir(t.TempDir()).Load()
+	s := service.NewDomainService(dc, nil)
+	defer s.Close()
+
+	for _, alias := range []string{"", "vpn.example.com-rsa", "vpn.example.com-ecdsa"} {
+		_, err := s.CreateDomain(&model.CreateDomainRequest{
+			Domain:           "vpn.example.com",
+			AlternativeNames: []string{"a.example.com"},
+			Alias:            alias,
+			Enabled:          util.BoolPtr(true),
+		})
+		require.NoError(t, err)
+	}
+
+	app := fiber.New()
+	NewDomainHandler(s).RegisterRoutes(app.Group("/api/v1"))
+
+	patch := func(t *testing.T, path, body string) (int, model.DomainResponse) {
+		req := httptest.NewRequest("PUT", path, strings.NewReader(body))
+		req.Header.Set("Content-Type", MIMEApplicationJSONPatch)
+		resp, err := app.Test(req)
+		require.NoError(t, err)
+		defer resp.Body.Close()
+
+		var response model.DomainResponse
+		require.NoError(t, json.NewDecoder(resp.Body).Decode(&response))
+		return resp.StatusCode, response
+	}
+
+	t.Run("Replace", func(t *testing.T) {
+		status, response := patch(t, "/api/v1/domains/vpn.example.com",
+			`[{"op":"replace","path":"/enabled","value":false},{"op":"add","path":"/comment","value":"patched"}]`)
+		require.Equal(t, fiber.StatusOK, status)
+		require.False(t, response.Data.Enabled)
+		require.Equal(t, "patched", response.Data.Comment)
+
+		entry, err := s.GetDomain("vpn.example.com", "")
+		require.NoError(t, err)
+		require.False(t, entry.Enabled)
+	})
+
+	t.Run("AddAndRemoveAlternativeNames", func(t *testing.T) {
+		status, response := patch(t, "/api/v1/domains/vpn.example.com?alias=vpn.example.com-rsa",
+			`[{"op":"add","path":"/alternative_names/-","value":"b.example.com"},{"op":"remove","path":"/alternative_names/0"}]`)
+		require.Equal(t, fiber.StatusOK, status)
+		require.Equal(t, []string{"b.example.com"}, response.Data.AlternativeNames)
+
+		entry, err := s.GetDomain("vpn.example.com", "vpn.example.com-ecdsa")
+		require.NoError(t, err)
+		require.Equal(t, []string{"a.example.com"}, entry.AlternativeNames)
+	})
+
+	t.Run("AliasRoute", func(t *testing.T) {
+		status, response := patch(t, "/api/v1/domains/vpn.example.com/aliases/vpn.example.com-ecdsa",
+			`[{"op":"replace","path":"/alias","value":"vpn.example.com-p384"}]`)
+		require.Equal(t, fiber.StatusOK, status)
+		require.Equal(t, "vpn.example.com-p384", response.Data.Alias)
+
+		_, err := s.GetDomain("vpn.example.com", "vpn.example.com-ecdsa")
+		require.Error(t, err)
+	})
+
+	t.Run("AliasCollision", func(t *testing.T) {
+		status, _ := patch(t, "/api/v1/domains/vpn.example.com/aliases/vpn.example.com-p384",
+			`[{"op":"replace","path":"/alias","value":"vpn.example.com-rsa"}]`)
+		require.Equal(t, fiber.StatusConflict, status)
+	})
+
+	t.Run("ImmutablePath", func(t *testing.T) {
+		status, response := patch(t, "/api/v1/domains/vpn.example.com",
+			`[{"op":"replace","path":"/domain","value":"other.example.com"}]`)
+		require.Equal(t, fiber.StatusBadRequest, status)
+		require.Contains(t, response.Error, "immutable")
+	})
+
+	t.Run("CamelCasePath", func(t *testing.T) {
+		status, response := patch(t, "/api/v1/domains/vpn.example.com",
+			`[{"op":"replace","path":"/alternativeNames","value":["c.example.com"]}]`)
+		require.Equal(t, fiber.StatusOK, status)
+		require.Equal(t, []string{"c.example.com"}, response.Data.AlternativeNames)
+	})
+
+	t.Run("MissingTarget", func(t *testing.T) {
+		status, response := patch(t, "/api/v1/domains/vpn.example.com", `[{"op":"remove","path":"/alias"}]`)
+		require.Equal(t, fiber.StatusUnprocessableEntity, status)
+		require.Contains(t, response.Error, "path does not exist")
+	})
+
+	t.Run("InvalidValue", func(t *testing.T) {
+		status, response := patch(t, "/api/v1/domains/vpn.example.com",
+			`[{"op":"replace","path":"/enabled","value":"yes"}]`)
+		require.Equal(t, fiber.StatusBadRequest, status)
+		require.Contains(t, response.Error, "invalid value")
+	})
+
+	t.Run("NotFound", func(t *testing.T) {
+		status, _ := patch(t, "/api/v1/domains/missing.example.com", `[]`)
+		require.Equal(t, fiber.StatusNotFound, status)
+	})
+}
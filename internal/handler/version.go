@@ -0,0 +1,36 @@
+package handler
+
+import (
+	"github.com/gofiber/fiber/v2"
+	"github.com/schumann-it/dehydrated-api-go/internal/model"
+)
+
+// VersionHandler handles HTTP requests for the version information of the running build
+type VersionHandler struct {
+	info *model.VersionInfo
+}
+
+// NewVersionHandler creates a new VersionHandler instance returning info
+func NewVersionHandler(info *model.VersionInfo) *VersionHandler {
+	return &VersionHandler{
+		info: info,
+	}
+}
+
+// RegisterRoutes registers all version-related routes
+func (h *VersionHandler) RegisterRoutes(app fiber.Router) {
+	app.Get("version", h.Version)
+}
+
+// @Summary Get version
+// @Description Retrieve the version, commit and build time of the running build and the Go version it was built with
+// @Tags health
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} model.VersionInfo "Version information"
+// @Failure 401 {object} model.DomainsResponse "Unauthorized - Invalid or missing authentication token"
+// @Router /api/v1/version [get]
+// Version handles GET /api/v1/version
+func (h *VersionHandler) Version(c *fiber.Ctx) error {
+	return c.JSON(h.info)
+}
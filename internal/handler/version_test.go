@@ -0,0 +1,34 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/schumann-it/dehydrated-api-go/internal/model"
+	"github.com/stretchr/testify/require"
+)
+
+// TestVersion verifies that the version endpoint returns the configured version information.
+func TestVersion(t *testing.T) {
+	info := &model.VersionInfo{Version: "1.2.3", Commit: "a1b2c3d", BuildTime: "2024-01-01T00:00:00Z", GoVersion: "go1.24.2"}
+
+	app := fiber.New()
+	NewVersionHandler(info).RegisterRoutes(app.Group("/api/v1"))
+
+	result, err := app.Test(httptest.NewRequest("GET", "/api/v1/version", http.NoBody))
+	require.NoError(t, err)
+	defer result.Body.Close()
+	require.Equal(t, fiber.StatusOK, result.StatusCode)
+
+	var body map[string]string
+	require.NoError(t, json.NewDecoder(result.Body).Decode(&body))
+	require.Equal(t, map[string]string{
+		"version":    "1.2.3",
+		"commit":     "a1b2c3d",
+		"build_time": "2024-01-01T00:00:00Z",
+		"go_version": "go1.24.2",
+	}, body)
+}
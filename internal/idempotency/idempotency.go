@@ -0,0 +1,244 @@
+// Package idempotency provides middleware that makes mutations safe to retry. Responses of requests
+// carrying an Idempotency-Key header are cached per actor, or per client IP without authentication,
+// and replayed for retries with the same key.
+package idempotency
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/schumann-it/dehydrated-api-go/internal/auth"
+	"github.com/schumann-it/dehydrated-api-go/internal/model"
+	"go.uber.org/zap"
+)
+
+const (
+	// Header is the request header carrying the idempotency key.
+	Header = "Idempotency-Key"
+
+	// ReplayedHeader is set on replayed responses.
+	ReplayedHeader = "Idempotent-Replayed"
+
+	// MaxKeyLength is the maximum length of an idempotency key.
+	MaxKeyLength = 255
+
+	// DefaultTTL is the default time a response is kept for retries.
+	DefaultTTL = 24 * time.Hour
+
+	// DefaultMaxKeys is the default number of keys kept.
+	DefaultMaxKeys = 10000
+)
+
+// Config configures the idempotency middleware.
+type Config struct {
+	// TTL is the time a response is kept for retries (default 24h).
+	TTL time.Duration `yaml:"ttl"`
+
+	// MaxKeys is the number of keys kept; the oldest keys are dropped first (default 10000).
+	MaxKeys int `yaml:"maxKeys"`
+}
+
+// response is a cached response, or a reservation for a request in progress if done is false.
+type response struct {
+	fingerprint [sha256.Size]byte
+	expires     time.Time
+	done        bool
+	status      int
+	header      [][2][]byte
+	body        []byte
+}
+
+// store holds the responses by scope and key.
+type store struct {
+	mu        sync.Mutex
+	ttl       time.Duration
+	maxKeys   int
+	responses map[string]*response
+	now       func() time.Time
+}
+
+// Middleware creates middleware that replays the response of the first POST, PUT, PATCH or DELETE
+// request with an Idempotency-Key for retries with the same key by the same actor, within the TTL.
+// Requests without an actor, e.g., if authentication is disabled, are distinguished by their client IP.
+// Reusing a key for a different request is rejected with 422 Unprocessable Entity, retrying while the
+// first request is in progress with 409 Conflict. Server errors are not cached, so they can be retried.
+func Middleware(cfg *Config, logger *zap.Logger) fiber.Handler {
+	s := newStore(cfg)
+
+	return func(c *fiber.Ctx) error {
+		key := c.Get(Header)
+		if key == "" || !isMutation(c.Method()) {
+			return c.Next()
+		}
+
+		if len(key) > MaxKeyLength {
+			return fiber.NewError(fiber.StatusBadRequest, "invalid idempotency key: longer than 255 characters")
+		}
+
+		id := scope(c) + "\x00" + key
+		fingerprint := sha256.Sum256(slices.Concat([]byte(c.Method()), []byte{0}, []byte(c.OriginalURL()), []byte{0}, c.Body()))
+
+		cached, ok := s.reserve(id, fingerprint)
+		switch {
+		case !ok:
+			// The reservation failed, because the key is taken by another request
+			return errorResponse(c, fiber.StatusUnprocessableEntity, model.CodeIdempotencyKeyReused,
+				"idempotency key was used for a different request")
+		case cached != nil && !cached.done:
+			return errorResponse(c, fiber.StatusConflict, model.CodeIdempotencyKeyInUse,
+				"a request with this idempotency key is still in progress")
+		case cached != nil:
+			logger.Debug("Replaying response", zap.String("key", key), zap.Int("status", cached.status))
+			return replay(c, cached)
+		}
+
+		err := c.Next()
+		status := c.Response().StatusCode()
+		if err != nil || status >= fiber.StatusInternalServerError {
+			s.release(id)
+			return err
+		}
+
+		s.complete(id, c.Response())
+
+		return nil
+	}
+}
+
+// scope returns the scope of the idempotency keys of the request: the actor, or the client IP
+// if the request has no actor.
+func scope(c *fiber.Ctx) string {
+	if actor := auth.Actor(c); actor != "" {
+		return "actor:" + actor
+	}
+	return "ip:" + c.IP()
+}
+
+// isMutation reports whether requests with the method are handled by the middleware.
+func isMutation(method string) bool {
+	switch method {
+	case fiber.MethodPost, fiber.MethodPut, fiber.MethodPatch, fiber.MethodDelete:
+		return true
+	}
+	return false
+}
+
+// errorResponse renders an error response with the given code.
+func errorResponse(c *fiber.Ctx, status int, code, msg string) error {
+	return c.Status(status).JSON(model.ErrorResponse{
+		Success: false,
+		Error:   msg,
+		Code:    code,
+	})
+}
+
+// replay renders a cached response.
+func replay(c *fiber.Ctx, r *response) error {
+	for _, h := range r.header {
+		c.Response().Header.SetBytesKV(h[0], h[1])
+	}
+	c.Set(ReplayedHeader, "true")
+
+	return c.Status(r.status).Send(r.body)
+}
+
+// newStore creates a store, applying the defaults for unset values of cfg.
+func newStore(cfg *Config) *store {
+	s := &store{
+		ttl:       DefaultTTL,
+		maxKeys:   DefaultMaxKeys,
+		responses: make(map[string]*response),
+		now:       time.Now,
+	}
+	if cfg != nil && cfg.TTL > 0 {
+		s.ttl = cfg.TTL
+	}
+	if cfg != nil && cfg.MaxKeys > 0 {
+		s.maxKeys = cfg.MaxKeys
+	}
+
+	return s
+}
+
+// reserve returns the cached response or reservation of id. If there is none, it reserves id and
+// returns nil. It returns false if id is cached or reserved for a request with another fingerprint.
+func (s *store) reserve(id string, fingerprint [sha256.Size]byte) (*response, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := s.now()
+	if r, ok := s.responses[id]; ok && now.Before(r.expires) {
+		if r.fingerprint != fingerprint {
+			return nil, false
+		}
+		return r, true
+	}
+
+	s.evict(now)
+	s.responses[id] = &response{fingerprint: fingerprint, expires: now.Add(s.ttl)}
+
+	return nil, true
+}
+
+// complete caches resp for the reservation of id.
+func (s *store) complete(id string, resp *fiber.Response) {
+	r := &response{
+		status: resp.StatusCode(),
+		body:   bytes.Clone(resp.Body()),
+	}
+	resp.Header.VisitAll(func(k, v []byte) {
+		// The length and date are set when the replayed response is written
+		if bytes.EqualFold(k, []byte(fiber.HeaderContentLength)) || bytes.EqualFold(k, []byte(fiber.HeaderDate)) {
+			return
+		}
+		r.header = append(r.header, [2][]byte{bytes.Clone(k), bytes.Clone(v)})
+	})
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	reservation, ok := s.responses[id]
+	if !ok {
+		return
+	}
+	r.fingerprint = reservation.fingerprint
+	r.expires = s.now().Add(s.ttl)
+	r.done = true
+	s.responses[id] = r
+}
+
+// release drops the reservation of id, so the request can be retried.
+func (s *store) release(id string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delete(s.responses, id)
+}
+
+// evict makes room for a response if the store is full, dropping expired responses and, if that is not
+// enough, the responses expiring first.
+func (s *store) evict(now time.Time) {
+	if len(s.responses) < s.maxKeys {
+		return
+	}
+
+	for id, r := range s.responses {
+		if !now.Before(r.expires) {
+			delete(s.responses, id)
+		}
+	}
+
+	for len(s.responses) >= s.maxKeys {
+		var oldest string
+		for id, r := range s.responses {
+			if oldest == "" || r.expires.Before(s.responses[oldest].expires) {
+				oldest = id
+			}
+		}
+		delete(s.responses, oldest)
+	}
+}
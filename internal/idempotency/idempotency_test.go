@@ -0,0 +1,216 @@
+package idempotency
+
+import (
+	"crypto/sha256"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"github.com/schumann-it/dehydrated-api-go/internal/dehydrated"
+	"github.com/schumann-it/dehydrated-api-go/internal/handler"
+	"github.com/schumann-it/dehydrated-api-go/internal/model"
+	"github.com/schumann-it/dehydrated-api-go/internal/service"
+)
+
+func TestMiddleware(t *testing.T) {
+	dc := dehydrated.NewConfig().WithBaseDir(t.TempDir()).Load()
+	s := service.NewDomainService(dc, nil)
+	t.Cleanup(func() { _ = s.Close() })
+
+	app := fiber.New(fiber.Config{ErrorHandler: handler.ErrorHandler})
+	g := app.Group("/api/v1", func(c *fiber.Ctx) error {
+		if actor := c.Get("X-Actor"); actor != "" {
+			c.Locals("claims", jwt.MapClaims{"oid": actor, "roles": []any{"writer"}})
+		}
+		return c.Next()
+	}, Middleware(&Config{}, zap.NewNop()))
+	handler.NewDomainHandler(s).RegisterRoutes(g)
+
+	do := func(method, path, key, actor, body string) (*http.Response, string) {
+		req := httptest.NewRequest(method, path, strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		if key != "" {
+			req.Header.Set(Header, key)
+		}
+		if actor != "" {
+			req.Header.Set("X-Actor", actor)
+		}
+		resp, err := app.Test(req)
+		require.NoError(t, err)
+		defer resp.Body.Close()
+		data, err := io.ReadAll(resp.Body)
+		require.NoError(t, err)
+		return resp, string(data)
+	}
+
+	code := func(body string) string {
+		var response model.ErrorResponse
+		require.NoError(t, json.Unmarshal([]byte(body), &response))
+		return response.Code
+	}
+
+	t.Run("ReplayCreate", func(t *testing.T) {
+		resp, first := do("POST", "/api/v1/domains", "create-1", "alice", `{"domain": "example.com"}`)
+		require.Equal(t, fiber.StatusCreated, resp.StatusCode)
+		require.Empty(t, resp.Header.Get(ReplayedHeader))
+
+		resp, retry := do("POST", "/api/v1/domains", "create-1", "alice", `{"domain": "example.com"}`)
+		require.Equal(t, fiber.StatusCreated, resp.StatusCode)
+		require.Equal(t, "true", resp.Header.Get(ReplayedHeader))
+		require.Equal(t, fiber.MIMEApplicationJSON, resp.Header.Get(fiber.HeaderContentType))
+		require.Equal(t, first, retry)
+
+		entries, _, err := s.ListDomains(1, 10, "", "")
+		require.NoError(t, err)
+		require.Len(t, entries, 1)
+	})
+
+	t.Run("WithoutKey", func(t *testing.T) {
+		resp, body := do("POST", "/api/v1/domains", "", "alice", `{"domain": "example.com"}`)
+		require.Equal(t, fiber.StatusConflict, resp.StatusCode)
+		require.Equal(t, model.CodeDomainExists, code(body))
+	})
+
+	t.Run("KeysPerActor", func(t *testing.T) {
+		resp, body := do("POST", "/api/v1/domains", "create-1", "bob", `{"domain": "example.com"}`)
+		require.Equal(t, fiber.StatusConflict, resp.StatusCode)
+		require.Equal(t, model.CodeDomainExists, code(body))
+		require.Empty(t, resp.Header.Get(ReplayedHeader))
+	})
+
+	t.Run("KeyReused", func(t *testing.T) {
+		resp, body := do("POST", "/api/v1/domains", "create-1", "alice", `{"domain": "example.org"}`)
+		require.Equal(t, fiber.StatusUnprocessableEntity, resp.StatusCode)
+		require.Equal(t, model.CodeIdempotencyKeyReused, code(body))
+
+		_, err := s.GetDomain("example.org", "")
+		require.Error(t, err)
+	})
+
+	t.Run("ReplayDelete", func(t *testing.T) {
+		resp, _ := do("DELETE", "/api/v1/domains/example.com", "delete-1", "alice", "")
+		require.Equal(t, fiber.StatusNoContent, resp.StatusCode)
+
+		resp, _ = do("DELETE", "/api/v1/domains/example.com", "delete-1", "alice", "")
+		require.Equal(t, fiber.StatusNoContent, resp.StatusCode)
+		require.Equal(t, "true", resp.Header.Get(ReplayedHeader))
+	})
+
+	t.Run("InvalidKey", func(t *testing.T) {
+		resp, body := do("POST", "/api/v1/domains", strings.Repeat("k", MaxKeyLength+1), "alice", `{"domain": "example.net"}`)
+		require.Equal(t, fiber.StatusBadRequest, resp.StatusCode)
+		require.Equal(t, model.CodeValidationFailed, code(body))
+	})
+}
+
+// TestMiddlewareAnonymous verifies that keys of requests without an actor are stored per client IP.
+func TestMiddlewareAnonymous(t *testing.T) {
+	created := 0
+	app := fiber.New(fiber.Config{ProxyHeader: fiber.HeaderXForwardedFor})
+	app.Post("/", Middleware(nil, zap.NewNop()), func(c *fiber.Ctx) error {
+		created++
+		return c.SendStatus(fiber.StatusCreated)
+	})
+
+	post := func(ip string) *http.Response {
+		req := httptest.NewRequest("POST", "/", http.NoBody)
+		req.Header.Set(Header, "key")
+		req.Header.Set(fiber.HeaderXForwardedFor, ip)
+		resp, err := app.Test(req)
+		require.NoError(t, err)
+		resp.Body.Close()
+		return resp
+	}
+
+	require.Empty(t, post("192.0.2.1").Header.Get(ReplayedHeader))
+	require.Equal(t, "true", post("192.0.2.1").Header.Get(ReplayedHeader))
+	require.Empty(t, post("192.0.2.2").Header.Get(ReplayedHeader))
+	require.Equal(t, 2, created)
+}
+
+func TestMiddlewareInProgress(t *testing.T) {
+	started, release := make(chan struct{}), make(chan struct{})
+	failures := 1
+
+	app := fiber.New()
+	app.Post("/", Middleware(nil, zap.NewNop()), func(c *fiber.Ctx) error {
+		if failures > 0 {
+			failures--
+			return c.SendStatus(fiber.StatusInternalServerError)
+		}
+		close(started)
+		<-release
+		return c.SendStatus(fiber.StatusCreated)
+	})
+
+	post := func() int {
+		req := httptest.NewRequest("POST", "/", http.NoBody)
+		req.Header.Set(Header, "key")
+		resp, err := app.Test(req, -1)
+		require.NoError(t, err)
+		defer resp.Body.Close()
+		return resp.StatusCode
+	}
+
+	// Server errors are not cached
+	require.Equal(t, fiber.StatusInternalServerError, post())
+
+	done := make(chan int)
+	go func() { done <- post() }()
+	<-started
+
+	require.Equal(t, fiber.StatusConflict, post())
+
+	close(release)
+	require.Equal(t, fiber.StatusCreated, <-done)
+	require.Equal(t, fiber.StatusCreated, post())
+}
+
+func TestStore(t *testing.T) {
+	now := time.Now()
+	s := newStore(&Config{TTL: time.Minute, MaxKeys: 2})
+	s.now = func() time.Time { return now }
+
+	fingerprint := sha256.Sum256([]byte("request"))
+	complete := func(id string) {
+		cached, ok := s.reserve(id, fingerprint)
+		require.True(t, ok)
+		require.Nil(t, cached)
+		resp := &fiber.Response{}
+		resp.SetStatusCode(fiber.StatusCreated)
+		s.complete(id, resp)
+	}
+
+	complete("a")
+	cached, ok := s.reserve("a", fingerprint)
+	require.True(t, ok)
+	require.True(t, cached.done)
+	require.Equal(t, fiber.StatusCreated, cached.status)
+
+	_, ok = s.reserve("a", sha256.Sum256([]byte("other")))
+	require.False(t, ok)
+
+	// Expired responses are dropped
+	now = now.Add(time.Minute)
+	cached, ok = s.reserve("a", fingerprint)
+	require.True(t, ok)
+	require.Nil(t, cached)
+	s.release("a")
+
+	// The oldest responses are dropped if the store is full
+	complete("a")
+	now = now.Add(time.Second)
+	complete("b")
+	complete("c")
+	require.Len(t, s.responses, 2)
+	require.NotContains(t, s.responses, "a")
+}
@@ -18,6 +18,9 @@ type Config struct {
 
 	// OutputPath specifies the path to the log file. If empty, logs are written to stdout.
 	OutputPath string `yaml:"outputPath"`
+
+	// AccessLogFields are the fields of the access log of HTTP requests. If nil, the server's defaults are used.
+	AccessLogFields []string `yaml:"accessLogFields"`
 }
 
 // defaultLoggerConfig returns a new Config with default settings.
@@ -35,14 +38,22 @@ func DefaultLoggerConfig() *Config {
 }
 
 func NewLogger(cfg *Config) (*zap.Logger, error) {
+	l, _, err := NewLoggerWithLevel(cfg)
+	return l, err
+}
+
+// NewLoggerWithLevel creates a new logger like NewLogger and additionally returns
+// the atomic level backing it, which allows changing the log level at runtime.
+func NewLoggerWithLevel(cfg *Config) (*zap.Logger, zap.AtomicLevel, error) {
 	if cfg == nil {
 		cfg = DefaultLoggerConfig()
 	}
 	// Parse log level
-	level, err := zapcore.ParseLevel(cfg.Level)
+	l, err := zapcore.ParseLevel(cfg.Level)
 	if err != nil {
-		return nil, err
+		return nil, zap.AtomicLevel{}, err
 	}
+	level := zap.NewAtomicLevelAt(l)
 
 	// Create encoder config
 	encoderConfig := zap.NewProductionEncoderConfig()
@@ -62,7 +73,7 @@ func NewLogger(cfg *Config) (*zap.Logger, error) {
 	if cfg.OutputPath != "" {
 		output, err = os.OpenFile(cfg.OutputPath, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
 		if err != nil {
-			return nil, err
+			return nil, zap.AtomicLevel{}, err
 		}
 	} else {
 		output = zapcore.AddSync(os.Stdout)
@@ -75,5 +86,5 @@ func NewLogger(cfg *Config) (*zap.Logger, error) {
 		level,
 	)
 
-	return zap.New(core, zap.AddCaller(), zap.AddStacktrace(zapcore.ErrorLevel)), nil
+	return zap.New(core, zap.AddCaller(), zap.AddStacktrace(zapcore.ErrorLevel)), level, nil
 }
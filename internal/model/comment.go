@@ -0,0 +1,34 @@
+package model
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"slices"
+)
+
+// Placeholders of comment templates, which set the comment of entries created without one.
+const (
+	// CommentPlaceholderDate is replaced by the current date in UTC, e.g., 2024-01-02.
+	CommentPlaceholderDate = "{date}"
+	// CommentPlaceholderActor is replaced by the caller.
+	CommentPlaceholderActor = "{actor}"
+	// CommentPlaceholderDomain is replaced by the domain of the created entry.
+	CommentPlaceholderDomain = "{domain}"
+)
+
+// commentPlaceholder matches placeholders in comment templates, known or not.
+var commentPlaceholder = regexp.MustCompile(`\{[A-Za-z_]+\}`)
+
+// ValidateCommentTemplate returns an error if tmpl has unknown placeholders or does not fit into a single line.
+func ValidateCommentTemplate(tmpl string) error {
+	for _, p := range commentPlaceholder.FindAllString(tmpl, -1) {
+		if !slices.Contains([]string{CommentPlaceholderDate, CommentPlaceholderActor, CommentPlaceholderDomain}, p) {
+			return fmt.Errorf("unknown placeholder %s", p)
+		}
+	}
+	if !IsValidComment(tmpl, 0) {
+		return errors.New("must not contain control characters such as newlines")
+	}
+	return nil
+}
@@ -0,0 +1,15 @@
+package model
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestValidateCommentTemplate(t *testing.T) {
+	require.NoError(t, ValidateCommentTemplate(""))
+	require.NoError(t, ValidateCommentTemplate("created {date} by {actor} via api for {domain}"))
+	require.NoError(t, ValidateCommentTemplate("{}"))
+	require.ErrorContains(t, ValidateCommentTemplate("created by {user}"), "unknown placeholder {user}")
+	require.ErrorContains(t, ValidateCommentTemplate("created\n{date}"), "control characters")
+}
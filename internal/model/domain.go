@@ -4,7 +4,10 @@ package model
 
 import (
 	"encoding/json"
+	"maps"
+	"slices"
 	"sort"
+	"time"
 
 	"github.com/schumann-it/dehydrated-api-go/internal/dehydrated"
 
@@ -21,22 +24,38 @@ type DomainEntries []*DomainEntry
 // This method modifies the slice in-place.
 func (e DomainEntries) Sort() {
 	sort.Slice(e, func(i, j int) bool {
-		// Primary sort: domain name
-		if e[i].Domain != e[j].Domain {
-			return e[i].Domain < e[j].Domain
+		return sortsBefore(e[i], e[j])
+	})
+}
+
+// Position returns the zero-based index of entry in e once sorted by Sort, i.e., its line in the domains file.
+func (e DomainEntries) Position(entry *DomainEntry) int {
+	position := 0
+	for _, other := range e {
+		if sortsBefore(other, entry) {
+			position++
 		}
+	}
+	return position
+}
 
-		// Secondary sort: within same domain, no alias comes first
-		hasAliasI := e[i].Alias != ""
-		hasAliasJ := e[j].Alias != ""
+// sortsBefore reports whether a is sorted before b by Sort.
+func sortsBefore(a, b *DomainEntry) bool {
+	// Primary sort: domain name
+	if a.Domain != b.Domain {
+		return a.Domain < b.Domain
+	}
 
-		if hasAliasI != hasAliasJ {
-			return !hasAliasI // No alias comes first
-		}
+	// Secondary sort: within same domain, no alias comes first
+	hasAliasA := a.Alias != ""
+	hasAliasB := b.Alias != ""
 
-		// Tertiary sort: if both have aliases, sort by alias name
-		return e[i].Alias < e[j].Alias
-	})
+	if hasAliasA != hasAliasB {
+		return !hasAliasA // No alias comes first
+	}
+
+	// Tertiary sort: if both have aliases, sort by alias name
+	return a.Alias < b.Alias
 }
 
 // DomainEntry represents a domain configuration entry in the dehydrated system.
@@ -48,31 +67,170 @@ type DomainEntry struct {
 	// Metadata contains additional information about the domain entry.
 	// @Description Additional metadata about the domain entry
 	Metadata *pb.Metadata `json:"metadata,omitempty"`
+
+	// CA is the CA profile the certificate config of the entry selects, empty if the CA of the dehydrated config applies.
+	// A CA that matches none of the configured profiles is reported as is.
+	// @Description CA profile selected for the certificate, empty if the CA of the dehydrated config applies
+	CA string `json:"ca,omitempty"`
+
+	// Provenance tells which plugin produced each top-level metadata value and how long it took, by metadata key.
+	// It is only set if requested, see serviceinterface.WithExplain, and output along with the metadata.
+	// @Description Source of each top-level metadata value by key, only with explain=true
+	Provenance map[string]*MetadataProvenance `json:"metadata_provenance,omitempty"`
+
+	// Options is the raw text following the alias in the domains file, e.g., written by hand for other tools.
+	// It is not part of the API, but kept when the entry is written back.
+	Options string `json:"-"`
+
+	// fields restricts the JSON output to the selected fields, see Select.
+	fields []string
+
+	// flatMetadata flattens the metadata in the JSON output, see WithFlatMetadata.
+	flatMetadata bool
 }
 
-// MarshalJSON implements the json.Marshaler interface to ensure all fields are included
-func (e *DomainEntry) MarshalJSON() ([]byte, error) {
-	metadata := make(map[string]any)
-	if e.Metadata != nil {
-		protoMap, err := e.Metadata.ToProto()
-		if err != nil {
-			return nil, err
+// Sources of metadata values, see MetadataProvenance.
+const (
+	ProvenanceSourcePlugin  = "plugin"
+	ProvenanceSourceSidecar = "sidecar"
+)
+
+// MetadataProvenance tells where a top-level metadata value of a domain entry comes from.
+// @Description Source of a metadata value
+type MetadataProvenance struct {
+	// Source is ProvenanceSourcePlugin or ProvenanceSourceSidecar.
+	// @Description Source of the value
+	Source string `json:"source" example:"plugin" enums:"plugin,sidecar"`
+
+	// Plugin is the name of the plugin that produced the value, empty for the sidecar.
+	// @Description Name of the plugin that produced the value
+	Plugin string `json:"plugin,omitempty" example:"netbox"`
+
+	// DurationMS is the time the plugin call took in milliseconds, of the whole GetMetadataBatch call if batched.
+	// @Description Time the plugin call took in milliseconds
+	DurationMS float64 `json:"duration_ms" example:"12.5"`
+
+	// Batch indicates that the value was returned by a GetMetadataBatch call for several entries.
+	// @Description Whether the value was returned by a batch call for several entries
+	Batch bool `json:"batch,omitempty" example:"false"`
+}
+
+// MetadataKeySeparator joins the keys of nested metadata maps in flattened metadata.
+const MetadataKeySeparator = "."
+
+// DomainEntryFields lists the JSON fields of a DomainEntry.
+var DomainEntryFields = []string{"domain", "alternative_names", "alias", "enabled", "comment", "ca", "metadata"}
+
+// IsValidDomainEntryField reports whether field is one of DomainEntryFields.
+func IsValidDomainEntryField(field string) bool {
+	return slices.Contains(DomainEntryFields, field)
+}
+
+// Select returns a copy of the entry whose JSON output only contains the given fields.
+// An empty selection includes all fields.
+func (e *DomainEntry) Select(fields []string) *DomainEntry {
+	return &DomainEntry{
+		DomainEntry: pb.DomainEntry{
+			Domain:           e.Domain,
+			AlternativeNames: e.AlternativeNames,
+			Alias:            e.Alias,
+			Enabled:          e.Enabled,
+			Comment:          e.Comment,
+		},
+		Metadata:     e.Metadata,
+		CA:           e.CA,
+		Provenance:   e.Provenance,
+		Options:      e.Options,
+		fields:       fields,
+		flatMetadata: e.flatMetadata,
+	}
+}
+
+// WithFlatMetadata returns a copy of the entry whose JSON output contains the metadata flattened
+// by FlattenMetadata, e.g., {"netbox.site": "a"} instead of {"netbox": {"site": "a"}}.
+func (e *DomainEntry) WithFlatMetadata() *DomainEntry {
+	c := e.Select(e.fields)
+	c.flatMetadata = true
+	return c
+}
+
+// FlattenMetadata flattens nested metadata maps into a single-level map whose keys are the keys of the
+// nested maps joined by MetadataKeySeparator, e.g., {"netbox": {"site": {"name": "a"}}} becomes
+// {"netbox.site.name": "a"}. Empty maps and values of other types, including arrays, are kept as is.
+func FlattenMetadata(values map[string]any) map[string]any {
+	flat := make(map[string]any, len(values))
+	flattenMetadata(flat, "", values)
+	return flat
+}
+
+// flattenMetadata adds values to flat, prefixing their keys with prefix.
+func flattenMetadata(flat map[string]any, prefix string, values map[string]any) {
+	for k, v := range values {
+		if prefix != "" {
+			k = prefix + MetadataKeySeparator + k
 		}
-		for k, v := range protoMap {
-			metadata[k] = v.AsInterface()
+		if m, ok := v.(map[string]any); ok && len(m) > 0 {
+			flattenMetadata(flat, k, m)
+			continue
 		}
+		flat[k] = v
+	}
+}
+
+// MarshalJSON implements the json.Marshaler interface to ensure all fields are included.
+// alternative_names and metadata are always serialized as an array and object, never as null,
+// ca is omitted if empty, metadata_provenance if not set.
+// If the entry was created by Select, only the selected fields are included, if it was created by
+// WithFlatMetadata, the metadata is flattened.
+func (e *DomainEntry) MarshalJSON() ([]byte, error) {
+	alternativeNames := e.GetAlternativeNames()
+	if alternativeNames == nil {
+		alternativeNames = []string{}
 	}
 
-	return json.Marshal(map[string]any{
+	values := map[string]any{
 		"domain":            e.GetDomain(),
-		"alternative_names": e.GetAlternativeNames(),
+		"alternative_names": alternativeNames,
 		"alias":             e.GetAlias(),
 		"enabled":           e.GetEnabled(),
 		"comment":           e.GetComment(),
-		"metadata":          metadata,
-	})
+	}
+	if e.CA != "" {
+		values["ca"] = e.CA
+	}
+
+	if len(e.fields) > 0 {
+		selected := make(map[string]any, len(e.fields))
+		for _, f := range e.fields {
+			if v, ok := values[f]; ok {
+				selected[f] = v
+			}
+		}
+		values = selected
+	}
+
+	if len(e.fields) == 0 || slices.Contains(e.fields, "metadata") {
+		// The metadata values are marshaled with their original types, not through their
+		// proto representation, which would turn integers into floats.
+		metadata := make(map[string]any)
+		if e.Metadata != nil {
+			maps.Copy(metadata, e.Metadata.Values())
+		}
+		if e.flatMetadata {
+			metadata = FlattenMetadata(metadata)
+		}
+		values["metadata"] = metadata
+		if e.Provenance != nil {
+			values["metadata_provenance"] = e.Provenance
+		}
+	}
+
+	return json.Marshal(values)
 }
 
+// Equals reports whether entry has the same domain, alternative names, alias, enabled state and comment as e.
+// The alternative names are compared in order, so entries with reordered alternative names are different.
+// The service sorts them before comparing if it is configured to canonicalize their order.
 func (e *DomainEntry) Equals(entry *DomainEntry) bool {
 	if e == nil || entry == nil {
 		return false
@@ -128,13 +286,17 @@ type CreateDomainRequest struct {
 	// @Description Optional alternative identifier for the domain
 	Alias string `json:"alias,omitempty" example:"my-domain"`
 
-	// Enabled indicates whether the domain should be active.
-	// @Description Whether the domain is enabled for certificate issuance
-	Enabled bool `json:"enabled" example:"true"`
+	// Enabled indicates whether the domain should be active. If omitted, the configured default applies.
+	// @Description Whether the domain is enabled for certificate issuance (defaults to the server configuration, defaultEnabled)
+	Enabled *bool `json:"enabled,omitempty" example:"true"`
 
 	// Comment is an optional description.
 	// @Description Optional description or comment for the domain
 	Comment string `json:"comment,omitempty" example:"Production domain for web application"`
+
+	// CA is an optional CA profile to issue the certificate with, one of the configured CA profiles.
+	// @Description Optional CA profile to issue the certificate with (one of the configured caProfiles)
+	CA string `json:"ca,omitempty" example:"staging"`
 }
 
 // UpdateDomainRequest represents a request to update an existing domain entry.
@@ -156,6 +318,10 @@ type UpdateDomainRequest struct {
 	// Comment is an optional description.
 	// @Description Optional description or comment for the domain
 	Comment *string `json:"comment,omitempty" example:"Production domain for web application"`
+
+	// CA is the CA profile to issue the certificate with, an empty string removes the selection.
+	// @Description CA profile to issue the certificate with (one of the configured caProfiles), empty to use the CA of the dehydrated config
+	CA *string `json:"ca,omitempty" example:"staging"`
 }
 
 // DeleteDomainRequest represents a request to delete an existing domain entry.
@@ -167,6 +333,151 @@ type DeleteDomainRequest struct {
 	Alias *string `json:"alias,omitempty" example:"my-domain"`
 }
 
+// RenameDomainRequest represents a request to change the primary domain name of an existing domain entry.
+// @Description Request to change the primary domain name of an existing domain entry
+type RenameDomainRequest struct {
+	// Domain is the new primary domain name (required).
+	// @Description New primary domain name (required)
+	// @required
+	Domain string `json:"domain" validate:"required" example:"example.org"`
+
+	// Alias is the new alias, the current one is kept if omitted.
+	// @Description New alias of the entry, the current one is kept if omitted
+	Alias *string `json:"alias,omitempty" example:"my-domain"`
+}
+
+// BulkUpdateRequest represents a request to change all domain entries matching a filter.
+// @Description Request to enable or disable all domain entries matching a filter
+type BulkUpdateRequest struct {
+	// Search filters entries by domain field (case-insensitive contains), like the list endpoint.
+	// @Description Search term to filter domains by domain field (case-insensitive contains, empty matches all)
+	Search string `json:"search,omitempty" example:"staging."`
+
+	// Enabled optionally restricts the change to entries in the given state.
+	// @Description Only change entries currently in this enabled state
+	Enabled *bool `json:"enabled,omitempty" example:"true"`
+}
+
+// BulkUpdateResponse represents the result of a bulk update.
+// @Description Response containing the number of changed domain entries
+type BulkUpdateResponse struct {
+	// Success indicates whether the operation was successful.
+	// @Description Whether the operation was successful
+	Success bool `json:"success" example:"true"`
+
+	// Count is the number of entries that changed.
+	// @Description Number of domain entries that changed
+	Count int `json:"count" example:"3"`
+
+	// Error contains an error message if the operation failed.
+	// @Description Error message if the operation failed
+	Error string `json:"error,omitempty" example:"invalid request body"`
+
+	// Code is the machine-readable code of the error, see ErrorCodes.
+	// @Description Machine-readable error code if the operation failed
+	Code string `json:"code,omitempty" example:"VALIDATION_FAILED"`
+}
+
+// BulkDeleteRequest represents a request to delete all domain entries matching a filter.
+// @Description Request to delete all domain entries matching a filter
+type BulkDeleteRequest struct {
+	// Search filters entries by domain field (case-insensitive contains), like the list endpoint.
+	// @Description Search term to filter domains by domain field (case-insensitive contains, empty matches all)
+	Search string `json:"search,omitempty" example:"staging."`
+
+	// Enabled optionally restricts the deletion to entries in the given state.
+	// @Description Only delete entries currently in this enabled state
+	Enabled *bool `json:"enabled,omitempty" example:"false"`
+
+	// Confirm must be true, so entries are not deleted by accident.
+	// @Description Must be true to delete the entries
+	Confirm bool `json:"confirm" example:"true"`
+}
+
+// BulkDeleteResponse represents the result of a bulk deletion.
+// @Description Response containing the deleted domain entries
+type BulkDeleteResponse struct {
+	// Success indicates whether the operation was successful.
+	// @Description Whether the operation was successful
+	Success bool `json:"success" example:"true"`
+
+	// Count is the number of deleted entries.
+	// @Description Number of deleted domain entries
+	Count int `json:"count" example:"3"`
+
+	// Data contains the deleted entries, without metadata.
+	// @Description Deleted domain entries, without metadata
+	Data []*DomainEntry `json:"data,omitempty"`
+
+	// Error contains an error message if the operation failed.
+	// @Description Error message if the operation failed
+	Error string `json:"error,omitempty" example:"confirm must be true to delete entries"`
+
+	// Code is the machine-readable code of the error, see ErrorCodes.
+	// @Description Machine-readable error code if the operation failed
+	Code string `json:"code,omitempty" example:"VALIDATION_FAILED"`
+}
+
+// NormalizeChange is a line of the domains file changed by the normalization.
+// @Description Line of the domains file changed by the normalization
+type NormalizeChange struct {
+	// Line is the 1-based number of the line in the domains file before the normalization.
+	// @Description Line number in the domains file before the normalization
+	Line int `json:"line" example:"3"`
+
+	// Before is the line as read from the domains file.
+	// @Description Line as read from the domains file
+	Before string `json:"before" example:"Example.com www.example.com WWW.example.com"`
+
+	// After is the normalized line, empty if the line is removed.
+	// @Description Normalized line, empty if the line is removed
+	After string `json:"after,omitempty" example:"example.com www.example.com"`
+}
+
+// NormalizeResult describes the normalization of the domains file.
+// @Description Result of the normalization of the domains file
+type NormalizeResult struct {
+	// DryRun is set if the domains file was not rewritten.
+	// @Description Whether the domains file was left unchanged
+	DryRun bool `json:"dry_run" example:"false"`
+
+	// Changed is set if the normalization changes the domains file.
+	// @Description Whether the normalization changes the domains file
+	Changed bool `json:"changed" example:"true"`
+
+	// Reordered is set if the entries are not sorted in the domains file.
+	// @Description Whether the entries are reordered
+	Reordered bool `json:"reordered" example:"true"`
+
+	// Entries is the number of entries after the normalization.
+	// @Description Number of entries after the normalization
+	Entries int `json:"entries" example:"12"`
+
+	// Changes are the lines changed or removed, in the order of the domains file.
+	// @Description Lines changed or removed, in the order of the domains file
+	Changes []NormalizeChange `json:"changes"`
+}
+
+// NormalizeResponse represents the result of the normalization of the domains file.
+// @Description Response containing the result of the normalization
+type NormalizeResponse struct {
+	// Success indicates whether the operation was successful.
+	// @Description Whether the operation was successful
+	Success bool `json:"success" example:"true"`
+
+	// Data contains the result of the normalization.
+	// @Description Result of the normalization
+	Data *NormalizeResult `json:"data,omitempty"`
+
+	// Error contains an error message if the operation failed.
+	// @Description Error message if the operation failed
+	Error string `json:"error,omitempty" example:"invalid dry_run: maybe"`
+
+	// Code is the machine-readable code of the error, see ErrorCodes.
+	// @Description Machine-readable error code if the operation failed
+	Code string `json:"code,omitempty" example:"VALIDATION_FAILED"`
+}
+
 // DomainResponse represents a response containing a single domain entry.
 // It includes a success flag, the domain data, and an optional error message.
 // @Description Response containing a single domain entry
@@ -182,6 +493,22 @@ type DomainResponse struct {
 	// Error contains an error message if the operation failed.
 	// @Description Error message if the operation failed
 	Error string `json:"error,omitempty" example:"Domain not found"`
+
+	// Code is the machine-readable code of the error, see ErrorCodes.
+	// @Description Machine-readable error code if the operation failed
+	Code string `json:"code,omitempty" example:"NOT_FOUND"`
+
+	// Field is the JSON name of the field that did not pass validation, if known.
+	// @Description Field that did not pass validation, if known
+	Field string `json:"field,omitempty" example:"alias"`
+
+	// PluginErrors contains the plugin errors that failed a strict request.
+	// @Description Plugin errors that failed a strict request
+	PluginErrors []*PluginError `json:"plugin_errors,omitempty"`
+
+	// Position is the zero-based index of the entry in the sorted domains file, if requested.
+	// @Description Zero-based index of the entry in the sorted domains file, if requested with include_position
+	Position *int `json:"position,omitempty" example:"3"`
 }
 
 // DomainsResponse represents a response containing multiple domain entries.
@@ -199,6 +526,10 @@ type DomainsResponse struct {
 	// Error contains an error message if the operation failed.
 	// @Description Error message if the operation failed
 	Error string `json:"error,omitempty" example:"Failed to load domains"`
+
+	// Code is the machine-readable code of the error, see ErrorCodes.
+	// @Description Machine-readable error code if the operation failed
+	Code string `json:"code,omitempty" example:"INTERNAL_ERROR"`
 }
 
 type ConfigResponse struct {
@@ -206,7 +537,247 @@ type ConfigResponse struct {
 
 	Data *dehydrated.Config `json:"data,omitempty"`
 
+	// Warnings contains validation warnings for the configuration, if validation is enabled.
+	// @Description Validation warnings for the configuration, if validation is enabled
+	Warnings []string `json:"warnings,omitempty" example:"KEY_SIZE 2048 is ignored for KEY_ALGO prime256v1"`
+
 	Error string `json:"error,omitempty" example:"Failed to load config"`
+	Code  string `json:"code,omitempty" example:"NOT_FOUND"`
+}
+
+// RawDomainLine is the line of a domain entry as written in domains.txt, with its parsed components.
+// @Description Line of a domain entry in domains.txt with its parsed components
+type RawDomainLine struct {
+	// Line is the 1-based line number in domains.txt.
+	// @Description Line number in domains.txt, starting at 1
+	Line int `json:"line" example:"3"`
+
+	// Raw is the line as written, without the line break.
+	// @Description Line as written in domains.txt
+	Raw string `json:"raw" example:"example.com www.example.com > example-rsa KEY_ALGO=rsa # web"`
+
+	// Disabled is set if the line is commented out.
+	// @Description Whether the line is commented out
+	Disabled bool `json:"disabled" example:"false"`
+
+	// Primary is the first domain name of the line.
+	// @Description First domain name of the line
+	Primary string `json:"primary" example:"example.com"`
+
+	// SANs are the alternative names following the primary domain.
+	// @Description Alternative names following the primary domain
+	SANs []string `json:"sans" example:"www.example.com"`
+
+	// Alias is the certificate alias following '>'.
+	// @Description Certificate alias following '>'
+	Alias string `json:"alias" example:"example-rsa"`
+
+	// Options is the text following the alias up to the comment, which has no representation in the entry.
+	// @Description Text following the alias up to the comment
+	Options string `json:"options" example:"KEY_ALGO=rsa"`
+
+	// Comment is the inline comment following '#'.
+	// @Description Inline comment following '#'
+	Comment string `json:"comment" example:"web"`
+}
+
+// RawDomainLineResponse represents a response containing the line of a domain entry.
+// @Description Response containing the line of a domain entry in domains.txt
+type RawDomainLineResponse struct {
+	// Success indicates whether the operation was successful.
+	// @Description Whether the operation was successful
+	Success bool `json:"success" example:"true"`
+
+	// Data contains the line if the operation was successful.
+	// @Description Line of the domain entry if the operation was successful
+	Data *RawDomainLine `json:"data,omitempty"`
+
+	// Error contains an error message if the operation failed.
+	// @Description Error message if the operation failed
+	Error string `json:"error,omitempty" example:"domain not found"`
+
+	// Code is the machine-readable code of the error, see ErrorCodes.
+	// @Description Machine-readable error code if the operation failed
+	Code string `json:"code,omitempty" example:"NOT_FOUND"`
+}
+
+// Plugin states reported for enabled plugins that are not available.
+const (
+	// PluginMisconfigured is the state of a plugin that rejected its configuration.
+	PluginMisconfigured = "misconfigured"
+
+	// PluginDead is the state of a plugin that could not be started or connected to.
+	PluginDead = "dead"
+)
+
+// PluginFailure describes an enabled plugin that is not available.
+// @Description Enabled plugin that is not available
+type PluginFailure struct {
+	// Name is the name of the plugin in the configuration.
+	// @Description Name of the plugin in the configuration
+	Name string `json:"name" example:"netbox"`
+
+	// State is either PluginMisconfigured or PluginDead.
+	// @Description State of the plugin
+	State string `json:"state" example:"misconfigured" enums:"misconfigured,dead"`
+
+	// Reason is the error the plugin failed with.
+	// @Description Error the plugin failed with
+	Reason string `json:"reason" example:"missing required config key apiToken"`
+}
+
+// IntegrityStatus is the result of the last integrity check of the domains file.
+type IntegrityStatus struct {
+	// Healthy indicates whether the domains file could be read and matched the cache.
+	Healthy bool `json:"healthy"`
+
+	// CheckedAt is the time of the last check.
+	CheckedAt time.Time `json:"checked_at"`
+
+	// FileEntries is the number of entries read from the domains file.
+	FileEntries int `json:"file_entries"`
+
+	// CacheEntries is the number of entries in the cache.
+	CacheEntries int `json:"cache_entries"`
+
+	// Error describes why the check failed, empty if healthy.
+	Error string `json:"error,omitempty"`
+}
+
+// VersionInfo is the version information of the running build.
+// @Description Version information of the running build
+type VersionInfo struct {
+	// Version is the version of the application.
+	// @Description Version of the application
+	Version string `json:"version" example:"1.2.3"`
+
+	// Commit is the Git commit the application was built from.
+	// @Description Git commit the application was built from
+	Commit string `json:"commit" example:"a1b2c3d"`
+
+	// BuildTime is the time the application was built at.
+	// @Description Time the application was built at
+	BuildTime string `json:"build_time" example:"2024-01-01T00:00:00Z"`
+
+	// GoVersion is the version of the Go runtime the application was built with.
+	// @Description Version of the Go runtime the application was built with
+	GoVersion string `json:"go_version" example:"go1.24.2"`
+}
+
+// ReadinessStatus contains the results of the readiness checks.
+// @Description Results of the readiness checks
+type ReadinessStatus struct {
+	// Writable indicates whether the domains file directory is writable.
+	// @Description Whether the domains file directory is writable
+	Writable bool `json:"writable" example:"true"`
+
+	// FreeBytes is the available disk space in bytes.
+	// @Description Available disk space in bytes
+	FreeBytes uint64 `json:"free_bytes" example:"1073741824"`
+
+	// MinFreeBytes is the minimum required disk space in bytes.
+	// @Description Minimum required disk space in bytes
+	MinFreeBytes uint64 `json:"min_free_bytes" example:"10485760"`
+
+	// Plugins contains the enabled plugins that are not available.
+	// @Description Enabled plugins that are not available
+	Plugins []*PluginFailure `json:"plugins,omitempty"`
+}
+
+// ReadinessResponse represents a response of the readiness check.
+// @Description Response of the readiness check
+type ReadinessResponse struct {
+	// Success indicates whether the service is ready.
+	// @Description Whether the service is ready
+	Success bool `json:"success" example:"true"`
+
+	// Data contains the storage check results if storage checks are enabled.
+	// @Description Storage check results if storage checks are enabled
+	Data *ReadinessStatus `json:"data,omitempty"`
+
+	// Error contains the reason why the service is not ready.
+	// @Description Reason why the service is not ready
+	Error string `json:"error,omitempty" example:"storage not writable"`
+
+	// Code is the machine-readable code of the error, see ErrorCodes.
+	// @Description Machine-readable error code if the operation failed
+	Code string `json:"code,omitempty" example:"UNAVAILABLE"`
+}
+
+// LogLevelRequest represents a request to change the log level at runtime.
+// @Description Request to change the log level at runtime
+type LogLevelRequest struct {
+	// Level is the new log level.
+	// @Description New log level
+	Level string `json:"level" validate:"required" example:"debug" enums:"debug,info,warn,error"`
+}
+
+// LogLevelResponse represents a response containing the current log level.
+// @Description Response containing the current log level
+type LogLevelResponse struct {
+	// Success indicates whether the operation was successful.
+	// @Description Whether the operation was successful
+	Success bool `json:"success" example:"true"`
+
+	// Level contains the current log level.
+	// @Description Current log level
+	Level string `json:"level,omitempty" example:"debug"`
+
+	// Error contains an error message if the operation failed.
+	// @Description Error message if the operation failed
+	Error string `json:"error,omitempty" example:"invalid log level"`
+
+	// Code is the machine-readable code of the error, see ErrorCodes.
+	// @Description Machine-readable error code if the operation failed
+	Code string `json:"code,omitempty" example:"VALIDATION_FAILED"`
+}
+
+// WatcherRequest represents a request to pause or resume the file watcher.
+// @Description Request to pause or resume the file watcher
+type WatcherRequest struct {
+	// Enabled resumes the file watcher if true and pauses it if false.
+	// @Description Whether the file watcher applies external changes of domains.txt
+	Enabled *bool `json:"enabled" validate:"required" example:"false"`
+}
+
+// WatcherResponse represents a response containing the state of the file watcher.
+// @Description Response containing the state of the file watcher
+type WatcherResponse struct {
+	// Success indicates whether the operation was successful.
+	// @Description Whether the operation was successful
+	Success bool `json:"success" example:"true"`
+
+	// Enabled indicates whether the file watcher applies external changes, i.e., is not paused.
+	// @Description Whether the file watcher applies external changes of domains.txt
+	Enabled bool `json:"enabled" example:"true"`
+
+	// Error contains an error message if the operation failed.
+	// @Description Error message if the operation failed
+	Error string `json:"error,omitempty" example:"enabled is required"`
+
+	// Code is the machine-readable code of the error, see ErrorCodes.
+	// @Description Machine-readable error code if the operation failed
+	Code string `json:"code,omitempty" example:"VALIDATION_FAILED"`
+}
+
+// AccountResponse represents a response containing the ACME account information.
+// @Description Response containing the ACME account information
+type AccountResponse struct {
+	// Success indicates whether the operation was successful.
+	// @Description Whether the operation was successful
+	Success bool `json:"success" example:"true"`
+
+	// Data contains the account information if the operation was successful.
+	// @Description Account information if the operation was successful
+	Data *dehydrated.Account `json:"data,omitempty"`
+
+	// Error contains an error message if the operation failed.
+	// @Description Error message if the operation failed
+	Error string `json:"error,omitempty" example:"account not found"`
+
+	// Code is the machine-readable code of the error, see ErrorCodes.
+	// @Description Machine-readable error code if the operation failed
+	Code string `json:"code,omitempty" example:"NOT_FOUND"`
 }
 
 // Pagination constants
@@ -271,4 +842,329 @@ type PaginatedDomainsResponse struct {
 	// Error contains an error message if the operation failed
 	// @Description Error message if the operation failed
 	Error string `json:"error,omitempty" example:"Failed to load domains"`
+
+	// Code is the machine-readable code of the error, see ErrorCodes.
+	// @Description Machine-readable error code if the operation failed
+	Code string `json:"code,omitempty" example:"INTERNAL_ERROR"`
+
+	// PluginErrors contains the plugin errors that failed a strict request.
+	// @Description Plugin errors that failed a strict request
+	PluginErrors []*PluginError `json:"plugin_errors,omitempty"`
+
+	// Partial indicates that the metadata of some entries is incomplete.
+	// @Description Whether the metadata of some entries is incomplete
+	Partial bool `json:"partial,omitempty" example:"false"`
+
+	// TimedOut indicates that the time budget of the request was exceeded before all plugins provided metadata.
+	// @Description Whether the time budget was exceeded before all plugins provided metadata
+	TimedOut bool `json:"timed_out,omitempty" example:"false"`
+}
+
+// PluginError is an error a plugin returned while enriching the metadata of a domain entry.
+// @Description Error returned by a plugin while enriching the metadata of a domain entry
+type PluginError struct {
+	// Domain is the primary domain of the entry.
+	// @Description Primary domain of the entry
+	Domain string `json:"domain" example:"example.com"`
+
+	// Alias is the alias of the entry, if any.
+	// @Description Alias of the entry, if any
+	Alias string `json:"alias,omitempty" example:"my-domain"`
+
+	// Plugin is the name of the plugin.
+	// @Description Name of the plugin
+	Plugin string `json:"plugin" example:"netscaler"`
+
+	// Message is the error message.
+	// @Description Error message
+	Message string `json:"message" example:"connection refused"`
+
+	// Timestamp is the time the error occurred.
+	// @Description Time the error occurred
+	Timestamp time.Time `json:"timestamp" example:"2024-01-01T00:00:00Z"`
+}
+
+// PaginatedPluginErrorsResponse represents a paginated response containing recent plugin errors.
+// @Description Paginated response containing recent plugin errors, newest first
+type PaginatedPluginErrorsResponse struct {
+	// Success indicates whether the operation was successful
+	// @Description Whether the operation was successful
+	Success bool `json:"success" example:"true"`
+
+	// Data contains the plugin errors if the operation was successful
+	// @Description Plugin errors, newest first
+	Data []*PluginError `json:"data,omitempty"`
+
+	// Pagination contains pagination metadata
+	// @Description Pagination metadata
+	Pagination *PaginationInfo `json:"pagination,omitempty"`
+
+	// Error contains an error message if the operation failed
+	// @Description Error message if the operation failed
+	Error string `json:"error,omitempty" example:"Failed to load plugin errors"`
+
+	// Code is the machine-readable code of the error, see ErrorCodes.
+	// @Description Machine-readable error code if the operation failed
+	Code string `json:"code,omitempty" example:"INTERNAL_ERROR"`
+}
+
+// PluginInfo describes a registered plugin.
+// @Description Registered plugin
+type PluginInfo struct {
+	// Name is the name of the plugin in the configuration.
+	// @Description Name of the plugin in the configuration
+	Name string `json:"name" example:"netbox"`
+
+	// Validate indicates whether the plugin validates domain entries before they are written.
+	// @Description Whether the plugin validates domain entries before they are written
+	Validate bool `json:"validate" example:"false"`
+
+	// Capabilities are the optional RPCs the plugin supports, e.g., metadata_batch and validate.
+	// @Description Optional RPCs the plugin supports
+	Capabilities []string `json:"capabilities" example:"metadata_batch,validate"`
+}
+
+// PaginatedPluginsResponse represents a paginated response containing the registered plugins.
+// @Description Paginated response containing the registered plugins, sorted by name
+type PaginatedPluginsResponse struct {
+	// Success indicates whether the operation was successful
+	// @Description Whether the operation was successful
+	Success bool `json:"success" example:"true"`
+
+	// Data contains the plugins if the operation was successful
+	// @Description Plugins, sorted by name
+	Data []*PluginInfo `json:"data,omitempty"`
+
+	// Pagination contains pagination metadata
+	// @Description Pagination metadata
+	Pagination *PaginationInfo `json:"pagination,omitempty"`
+
+	// Error contains an error message if the operation failed
+	// @Description Error message if the operation failed
+	Error string `json:"error,omitempty" example:"Failed to load plugins"`
+
+	// Code is the machine-readable code of the error, see ErrorCodes.
+	// @Description Machine-readable error code if the operation failed
+	Code string `json:"code,omitempty" example:"INTERNAL_ERROR"`
+}
+
+// Summary aggregates the status of all domain entries and their certificates.
+// @Description Status summary of all domain entries and their certificates
+type Summary struct {
+	// Total is the number of domain entries.
+	// @Description Number of domain entries
+	Total int `json:"total" example:"10"`
+
+	// Enabled is the number of enabled domain entries.
+	// @Description Number of enabled domain entries
+	Enabled int `json:"enabled" example:"8"`
+
+	// Valid is the number of certificates not expiring within the threshold.
+	// @Description Number of certificates not expiring within the threshold
+	Valid int `json:"valid" example:"6"`
+
+	// Expiring is the number of certificates expiring within the threshold.
+	// @Description Number of certificates expiring within the threshold
+	Expiring int `json:"expiring" example:"1"`
+
+	// Expired is the number of expired certificates.
+	// @Description Number of expired certificates
+	Expired int `json:"expired" example:"1"`
+
+	// Missing is the number of domain entries without a (readable) certificate.
+	// @Description Number of domain entries without a (readable) certificate
+	Missing int `json:"missing" example:"2"`
+
+	// ExpiryDays is the threshold in days certificates are considered expiring within.
+	// @Description Threshold in days certificates are considered expiring within
+	ExpiryDays int `json:"expiry_days" example:"14"`
+
+	// Duplicates are the entries occurring more than once in the domains file, e.g., after editing it by hand,
+	// as "domain" or "domain > alias". Only the first occurrence is reachable through the API.
+	// @Description Entries occurring more than once in domains.txt, as "domain" or "domain > alias"; only the first occurrence is reachable through the API
+	Duplicates []string `json:"duplicates" example:"example.com > cert"`
+}
+
+// SummaryResponse represents a response containing the status summary.
+// @Description Response containing the status summary
+type SummaryResponse struct {
+	// Success indicates whether the operation was successful.
+	// @Description Whether the operation was successful
+	Success bool `json:"success" example:"true"`
+
+	// Data contains the summary if the operation was successful.
+	// @Description Summary if the operation was successful
+	Data *Summary `json:"data,omitempty"`
+
+	// Error contains an error message if the operation failed.
+	// @Description Error message if the operation failed
+	Error string `json:"error,omitempty" example:"invalid expiry_days"`
+
+	// Code is the machine-readable code of the error, see ErrorCodes.
+	// @Description Machine-readable error code if the operation failed
+	Code string `json:"code,omitempty" example:"VALIDATION_FAILED"`
+}
+
+// Reconciliation lists the drift between the domain entries and the certificate directories of dehydrated.
+// @Description Drift between the domain entries and the certificate directories below CertDir
+type Reconciliation struct {
+	// MissingCerts are the path names (alias or domain) of enabled entries without an issued certificate.
+	// @Description Path names (alias or domain) of enabled entries without an issued certificate, sorted
+	MissingCerts []string `json:"missing_certs" example:"new.example.com"`
+
+	// OrphanCerts are the names of directories below CertDir that no entry refers to.
+	// @Description Names of directories below CertDir that no entry refers to, sorted
+	OrphanCerts []string `json:"orphan_certs" example:"old.example.com"`
+}
+
+// ReconciliationResponse represents a response containing the drift between entries and certificates.
+// @Description Response containing the drift between entries and certificates
+type ReconciliationResponse struct {
+	// Success indicates whether the operation was successful.
+	// @Description Whether the operation was successful
+	Success bool `json:"success" example:"true"`
+
+	// Data contains the drift if the operation was successful.
+	// @Description Drift if the operation was successful
+	Data *Reconciliation `json:"data,omitempty"`
+
+	// Error contains an error message if the operation failed.
+	// @Description Error message if the operation failed
+	Error string `json:"error,omitempty" example:"failed to read certificate directory"`
+
+	// Code is the machine-readable code of the error, see ErrorCodes.
+	// @Description Machine-readable error code if the operation failed
+	Code string `json:"code,omitempty" example:"INTERNAL_ERROR"`
+}
+
+// DomainPreview contains the line of the domains file an entry would be written as.
+// @Description Line of the domains file an entry would be written as
+type DomainPreview struct {
+	// Line is the rendered line, without the newline.
+	// @Description Rendered line, without the newline
+	Line string `json:"line" example:"example.com www.example.com > example # Production"`
+}
+
+// DomainPreviewResponse represents a response containing a rendered domains file line.
+// @Description Response containing a rendered domains file line
+type DomainPreviewResponse struct {
+	// Success indicates whether the operation was successful.
+	// @Description Whether the operation was successful
+	Success bool `json:"success" example:"true"`
+
+	// Data contains the preview if the operation was successful.
+	// @Description Preview if the operation was successful
+	Data *DomainPreview `json:"data,omitempty"`
+
+	// Error contains an error message if the operation failed.
+	// @Description Error message if the operation failed
+	Error string `json:"error,omitempty" example:"invalid domain entry"`
+
+	// Code is the machine-readable code of the error, see ErrorCodes.
+	// @Description Machine-readable error code if the operation failed
+	Code string `json:"code,omitempty" example:"INVALID_DOMAIN"`
+
+	// Field is the JSON name of the field that did not pass validation, if known.
+	// @Description Field that did not pass validation, if known
+	Field string `json:"field,omitempty" example:"alias"`
+}
+
+// ImportResult reports the changes of a bulk import.
+// @Description Changes of a bulk import
+type ImportResult struct {
+	// Created is the number of entries created by the import.
+	// @Description Number of entries created by the import
+	Created int `json:"created" example:"3"`
+
+	// Updated is the number of existing entries changed by the import.
+	// @Description Number of existing entries changed by the import
+	Updated int `json:"updated" example:"1"`
+
+	// Unchanged is the number of imported entries that already existed as imported.
+	// @Description Number of imported entries that already existed as imported
+	Unchanged int `json:"unchanged" example:"5"`
+
+	// Removed is the number of entries removed because they were not imported (replace mode only).
+	// @Description Number of entries removed because they were not imported (replace mode only)
+	Removed int `json:"removed" example:"0"`
+}
+
+// ImportRowError describes an invalid row of a bulk import.
+// @Description Invalid row of a bulk import
+type ImportRowError struct {
+	// Line is the line number of the row in the imported file, starting at 1.
+	// @Description Line number of the row in the imported file, starting at 1
+	Line int `json:"line" example:"3"`
+
+	// Error describes why the row is invalid.
+	// @Description Why the row is invalid
+	Error string `json:"error" example:"invalid domain entry"`
+
+	// Field is the JSON name of the field that did not pass validation, if known.
+	// @Description Field that did not pass validation, if known
+	Field string `json:"field,omitempty" example:"alias"`
+}
+
+// ImportResponse represents the response of a bulk import.
+// @Description Response of a bulk import
+type ImportResponse struct {
+	// Success indicates whether the operation was successful.
+	// @Description Whether the operation was successful
+	Success bool `json:"success" example:"true"`
+
+	// Data contains the changes if the operation was successful.
+	// @Description Changes if the operation was successful
+	Data *ImportResult `json:"data,omitempty"`
+
+	// Error contains an error message if the operation failed.
+	// @Description Error message if the operation failed
+	Error string `json:"error,omitempty" example:"invalid rows"`
+
+	// Code is the machine-readable code of the error, see ErrorCodes.
+	// @Description Machine-readable error code if the operation failed
+	Code string `json:"code,omitempty" example:"INVALID_DOMAIN"`
+
+	// Errors contains the invalid rows if the import was rejected because of them.
+	// @Description Invalid rows if the import was rejected because of them
+	Errors []ImportRowError `json:"errors,omitempty"`
+}
+
+// KeyFingerprintResponse represents a response containing the public key fingerprint of a domain.
+// @Description Response containing the public key fingerprint of a domain
+type KeyFingerprintResponse struct {
+	// Success indicates whether the operation was successful.
+	// @Description Whether the operation was successful
+	Success bool `json:"success" example:"true"`
+
+	// Data contains the public key fingerprint if the operation was successful.
+	// @Description Public key fingerprint if the operation was successful
+	Data *dehydrated.KeyInfo `json:"data,omitempty"`
+
+	// Error contains an error message if the operation failed.
+	// @Description Error message if the operation failed
+	Error string `json:"error,omitempty" example:"key not found"`
+
+	// Code is the machine-readable code of the error, see ErrorCodes.
+	// @Description Machine-readable error code if the operation failed
+	Code string `json:"code,omitempty" example:"NOT_FOUND"`
+}
+
+// OCSPResponse represents a response containing the OCSP response information of a domain.
+// @Description Response containing the OCSP response information of a domain
+type OCSPResponse struct {
+	// Success indicates whether the operation was successful.
+	// @Description Whether the operation was successful
+	Success bool `json:"success" example:"true"`
+
+	// Data contains the OCSP response information if the operation was successful.
+	// @Description OCSP response information if the operation was successful
+	Data *dehydrated.OCSPInfo `json:"data,omitempty"`
+
+	// Error contains an error message if the operation failed.
+	// @Description Error message if the operation failed
+	Error string `json:"error,omitempty" example:"ocsp response not found"`
+
+	// Code is the machine-readable code of the error, see ErrorCodes.
+	// @Description Machine-readable error code if the operation failed
+	Code string `json:"code,omitempty" example:"NOT_FOUND"`
 }
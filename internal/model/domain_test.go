@@ -41,7 +41,16 @@ func TestDomainEntry_MarshalJSON(t *testing.T) {
 				},
 				Metadata: pb.NewMetadata(),
 			},
-			expected: `{"domain":"example.com","alternative_names":null,"alias":"","enabled":true,"comment":"","metadata":{}}`,
+			expected: `{"domain":"example.com","alternative_names":[],"alias":"","enabled":true,"comment":"","metadata":{}}`,
+		},
+		{
+			name: "entry without metadata",
+			entry: &DomainEntry{
+				DomainEntry: pb.DomainEntry{
+					Domain: "example.com",
+				},
+			},
+			expected: `{"domain":"example.com","alternative_names":[],"alias":"","enabled":false,"comment":"","metadata":{}}`,
 		},
 		{
 			name: "entry with metadata",
@@ -56,7 +65,31 @@ func TestDomainEntry_MarshalJSON(t *testing.T) {
 					return m
 				}(),
 			},
-			expected: `{"domain":"example.com","alternative_names":null,"alias":"","enabled":true,"comment":"","metadata":{"key":"value"}}`,
+			expected: `{"domain":"example.com","alternative_names":[],"alias":"","enabled":true,"comment":"","metadata":{"key":"value"}}`,
+		},
+		{
+			name: "entry with plugin metadata",
+			entry: &DomainEntry{
+				DomainEntry: pb.DomainEntry{
+					Domain:  "example.com",
+					Enabled: true,
+				},
+				Metadata: func() *pb.Metadata {
+					// metadata set by a plugin and received through its proto representation
+					plugin := pb.NewMetadata()
+					plugin.Set("example_number", 42)
+					plugin.Set("example_float", 1.5)
+					plugin.Set("example_list", []any{1, 2})
+					resp, err := plugin.ToGetMetadataResponse()
+					require.NoError(t, err)
+
+					m := pb.NewMetadata()
+					m.FromProto("simple", resp.Metadata)
+					m.Set("large", int64(1)<<60)
+					return m
+				}(),
+			},
+			expected: `{"domain":"example.com","alternative_names":[],"alias":"","enabled":true,"comment":"","metadata":{"simple":{"example_number":42,"example_float":1.5,"example_list":[1,2]},"large":1152921504606846976}}`,
 		},
 	}
 
@@ -69,6 +102,61 @@ func TestDomainEntry_MarshalJSON(t *testing.T) {
 	}
 }
 
+func TestDomainEntry_MarshalJSON_IntegerMetadata(t *testing.T) {
+	plugin := pb.NewMetadata()
+	plugin.Set("example_number", 42)
+	resp, err := plugin.ToGetMetadataResponse()
+	require.NoError(t, err)
+
+	entry := &DomainEntry{Metadata: pb.NewMetadata()}
+	entry.Metadata.FromProto("simple", resp.Metadata)
+
+	data, err := json.Marshal(entry)
+	require.NoError(t, err)
+	require.Contains(t, string(data), `"example_number":42}`)
+}
+
+func TestDomainEntry_MarshalJSON_LargeIntegerMetadata(t *testing.T) {
+	plugin := pb.NewMetadata()
+	plugin.Set("id", int64(1<<60+1))
+	plugin.Set("nested", map[string]any{"ts": uint64(1<<63 + 7)})
+	resp, err := plugin.ToGetMetadataResponse()
+	require.NoError(t, err)
+
+	entry := &DomainEntry{Metadata: pb.NewMetadata()}
+	entry.Metadata.FromProto("simple", resp.Metadata)
+	data, err := json.Marshal(entry)
+	require.NoError(t, err)
+	require.Contains(t, string(data), `"id":"1152921504606846977"`)
+
+	entry = &DomainEntry{Metadata: pb.NewMetadata().WithLargeIntegers()}
+	entry.Metadata.FromProto("simple", resp.Metadata)
+	data, err = json.Marshal(entry)
+	require.NoError(t, err)
+	require.Contains(t, string(data), `"id":1152921504606846977`)
+	require.Contains(t, string(data), `"ts":9223372036854775815`)
+}
+
+func TestDomainEntry_MarshalJSON_Provenance(t *testing.T) {
+	entry := &DomainEntry{Metadata: pb.NewMetadata()}
+	entry.Metadata.Set("owner", "team")
+	data, err := json.Marshal(entry)
+	require.NoError(t, err)
+	require.NotContains(t, string(data), "metadata_provenance")
+
+	entry.Provenance = map[string]*MetadataProvenance{
+		"owner": {Source: ProvenanceSourcePlugin, Plugin: "cmdb", DurationMS: 1.5},
+	}
+	data, err = json.Marshal(entry)
+	require.NoError(t, err)
+	require.Contains(t, string(data), `"metadata_provenance":{"owner":{"source":"plugin","plugin":"cmdb","duration_ms":1.5}}`)
+
+	// Omitted along with the metadata
+	data, err = json.Marshal(entry.Select([]string{"domain"}))
+	require.NoError(t, err)
+	require.NotContains(t, string(data), "metadata_provenance")
+}
+
 func TestDomainEntry_SetMetadata(t *testing.T) {
 	entry := &DomainEntry{
 		DomainEntry: pb.DomainEntry{
@@ -118,6 +206,18 @@ func TestDomainEntry_PathName(t *testing.T) {
 	}
 }
 
+func TestDomainEntry_Equals(t *testing.T) {
+	entry := func(altNames ...string) *DomainEntry {
+		return &DomainEntry{DomainEntry: pb.DomainEntry{Domain: "example.com", AlternativeNames: altNames, Enabled: true}}
+	}
+
+	require.True(t, entry("a.example.com", "b.example.com").Equals(entry("a.example.com", "b.example.com")))
+	require.False(t, entry("a.example.com", "b.example.com").Equals(entry("b.example.com", "a.example.com")),
+		"reordered alternative names are different")
+	require.False(t, entry("a.example.com").Equals(entry("a.example.com", "b.example.com")))
+	require.False(t, entry().Equals(nil))
+}
+
 func TestCreateDomainRequest_Validation(t *testing.T) {
 	tests := []struct {
 		name    string
@@ -129,7 +229,7 @@ func TestCreateDomainRequest_Validation(t *testing.T) {
 			request: &CreateDomainRequest{
 				Domain:           "example.com",
 				AlternativeNames: []string{"www.example.com"},
-				Enabled:          true,
+				Enabled:          util.BoolPtr(true),
 			},
 			wantErr: false,
 		},
@@ -137,7 +237,7 @@ func TestCreateDomainRequest_Validation(t *testing.T) {
 			name: "missing domain",
 			request: &CreateDomainRequest{
 				AlternativeNames: []string{"www.example.com"},
-				Enabled:          true,
+				Enabled:          util.BoolPtr(true),
 			},
 			wantErr: true,
 		},
@@ -155,6 +255,27 @@ func TestCreateDomainRequest_Validation(t *testing.T) {
 	}
 }
 
+func TestCreateDomainRequest_UnmarshalEnabled(t *testing.T) {
+	tests := []struct {
+		name     string
+		body     string
+		expected *bool
+	}{
+		{"omitted", `{"domain":"example.com"}`, nil},
+		{"null", `{"domain":"example.com","enabled":null}`, nil},
+		{"false", `{"domain":"example.com","enabled":false}`, util.BoolPtr(false)},
+		{"true", `{"domain":"example.com","enabled":true}`, util.BoolPtr(true)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var req CreateDomainRequest
+			require.NoError(t, json.Unmarshal([]byte(tt.body), &req))
+			require.Equal(t, tt.expected, req.Enabled)
+		})
+	}
+}
+
 func TestUpdateDomainRequest_Validation(t *testing.T) {
 	tests := []struct {
 		name    string
@@ -278,6 +399,54 @@ func TestDomainsResponse(t *testing.T) {
 	}
 }
 
+// TestResponses_NonNullCollections verifies that alternative_names and metadata
+// are never serialized as null in any response shape.
+func TestResponses_NonNullCollections(t *testing.T) {
+	entry := &DomainEntry{
+		DomainEntry: pb.DomainEntry{
+			Domain: "example.com",
+		},
+	}
+
+	responses := map[string]any{
+		"DomainResponse":           DomainResponse{Success: true, Data: entry},
+		"DomainsResponse":          DomainsResponse{Success: true, Data: DomainEntries{entry}},
+		"PaginatedDomainsResponse": PaginatedDomainsResponse{Success: true, Data: DomainEntries{entry}},
+	}
+
+	for name, response := range responses {
+		t.Run(name, func(t *testing.T) {
+			data, err := json.Marshal(response)
+			require.NoError(t, err)
+			require.Contains(t, string(data), `"alternative_names":[]`)
+			require.Contains(t, string(data), `"metadata":{}`)
+			require.NotContains(t, string(data), "null")
+		})
+	}
+}
+
+// TestDomainEntries_Position verifies that the position of each entry matches its index once sorted.
+func TestDomainEntries_Position(t *testing.T) {
+	entries := DomainEntries{
+		{DomainEntry: pb.DomainEntry{Domain: "vpn.example.com", Alias: "vpn-rsa"}},
+		{DomainEntry: pb.DomainEntry{Domain: "www.example.com"}},
+		{DomainEntry: pb.DomainEntry{Domain: "vpn.example.com"}},
+		{DomainEntry: pb.DomainEntry{Domain: "api.example.com"}},
+		{DomainEntry: pb.DomainEntry{Domain: "vpn.example.com", Alias: "vpn-ecc"}},
+	}
+
+	positions := make(map[*DomainEntry]int)
+	for _, entry := range entries {
+		positions[entry] = entries.Position(entry)
+	}
+
+	sorted := append(DomainEntries{}, entries...)
+	sorted.Sort()
+	for i, entry := range sorted {
+		require.Equal(t, i, positions[entry], "%s > %s", entry.Domain, entry.Alias)
+	}
+}
+
 func TestDomainEntries_Sort(t *testing.T) {
 	// Create test domains with mixed order
 	entries := DomainEntries{
@@ -362,3 +531,36 @@ func TestDomainEntries_Sort(t *testing.T) {
 		}
 	})
 }
+
+func TestFlattenMetadata(t *testing.T) {
+	values := map[string]any{
+		"netbox": map[string]any{
+			"owner": "ops",
+			"site":  map[string]any{"name": "dc1", "rack": map[string]any{"row": float64(3)}},
+			"tags":  []any{"a", "b"},
+			"empty": map[string]any{},
+		},
+		"sidecar": "value",
+	}
+
+	require.Equal(t, map[string]any{
+		"netbox.owner":         "ops",
+		"netbox.site.name":     "dc1",
+		"netbox.site.rack.row": float64(3),
+		"netbox.tags":          []any{"a", "b"},
+		"netbox.empty":         map[string]any{},
+		"sidecar":              "value",
+	}, FlattenMetadata(values))
+
+	// The entry is not modified, only its JSON output
+	entry := &DomainEntry{DomainEntry: pb.DomainEntry{Domain: "example.com"}, Metadata: pb.NewMetadata()}
+	require.NoError(t, entry.Metadata.SetMap("netbox", map[string]any{"owner": "ops"}))
+
+	data, err := json.Marshal(entry.Select([]string{"metadata"}).WithFlatMetadata())
+	require.NoError(t, err)
+	require.JSONEq(t, `{"metadata": {"netbox.owner": "ops"}}`, string(data))
+
+	data, err = json.Marshal(entry)
+	require.NoError(t, err)
+	require.Contains(t, string(data), `"metadata":{"netbox":{"owner":"ops"}}`)
+}
@@ -0,0 +1,78 @@
+package model
+
+// Error codes of error responses. Clients branch on the code, the error message is meant for humans
+// and may change.
+const (
+	// CodeValidationFailed is returned for malformed request bodies and invalid parameters.
+	CodeValidationFailed = "VALIDATION_FAILED"
+
+	// CodeInvalidDomain is returned for domain entries that do not pass validation,
+	// including entries rejected by a validating plugin.
+	CodeInvalidDomain = "INVALID_DOMAIN"
+
+	// CodeDomainExists is returned if an entry with the same domain and alias already exists.
+	CodeDomainExists = "DOMAIN_EXISTS"
+
+	// CodeNotFound is returned if the requested entry or resource does not exist.
+	CodeNotFound = "NOT_FOUND"
+
+	// CodeDomainDisabled is returned if dehydrated should run for a disabled entry without override.
+	CodeDomainDisabled = "DOMAIN_DISABLED"
+
+	// CodePluginFailed is returned if a plugin failed, e.g., in strict mode or while validating an entry.
+	CodePluginFailed = "PLUGIN_FAILED"
+
+	// CodeTooManyEntries is returned if creating entries would exceed the maximum number of entries.
+	CodeTooManyEntries = "TOO_MANY_ENTRIES"
+
+	// CodeUnauthorized is returned for missing or invalid authentication tokens.
+	CodeUnauthorized = "UNAUTHORIZED"
+
+	// CodeForbidden is returned if the token lacks a required role.
+	CodeForbidden = "FORBIDDEN"
+
+	// CodeUnavailable is returned if the service is not ready.
+	CodeUnavailable = "UNAVAILABLE"
+
+	// CodeIdempotencyKeyInUse is returned if a request with the same idempotency key is still in progress.
+	CodeIdempotencyKeyInUse = "IDEMPOTENCY_KEY_IN_USE"
+
+	// CodeIdempotencyKeyReused is returned if an idempotency key is reused for a different request.
+	CodeIdempotencyKeyReused = "IDEMPOTENCY_KEY_REUSED"
+
+	// CodeInternalError is returned for unexpected errors.
+	CodeInternalError = "INTERNAL_ERROR"
+)
+
+// ErrorCodes are all error codes of error responses.
+var ErrorCodes = []string{
+	CodeValidationFailed,
+	CodeInvalidDomain,
+	CodeDomainExists,
+	CodeNotFound,
+	CodeDomainDisabled,
+	CodePluginFailed,
+	CodeTooManyEntries,
+	CodeUnauthorized,
+	CodeForbidden,
+	CodeUnavailable,
+	CodeIdempotencyKeyInUse,
+	CodeIdempotencyKeyReused,
+	CodeInternalError,
+}
+
+// ErrorResponse is the response of errors not specific to an endpoint, e.g., failed authentication.
+// @Description Error response
+type ErrorResponse struct {
+	// Success is always false
+	// @Description Always false
+	Success bool `json:"success" example:"false"`
+
+	// Error contains the error message.
+	// @Description Error message
+	Error string `json:"error" example:"missing authorization header"`
+
+	// Code is the machine-readable code of the error, see ErrorCodes.
+	// @Description Machine-readable error code
+	Code string `json:"code" example:"UNAUTHORIZED"`
+}
@@ -0,0 +1,34 @@
+package model
+
+// Formats of API responses, configured on the server and negotiated per request by the handlers.
+const (
+	// ResponseFormatEnveloped wraps response data in a {success, data} envelope (default).
+	ResponseFormatEnveloped = "enveloped"
+	// ResponseFormatBare returns response data directly, e.g., a plain array for lists.
+	ResponseFormatBare = "bare"
+
+	// MetadataFormatNested returns metadata nested by plugin, e.g., {"netbox": {"site": "a"}} (default).
+	MetadataFormatNested = "nested"
+	// MetadataFormatFlat returns metadata as a single-level map with dot-joined keys, e.g., {"netbox.site": "a"}.
+	MetadataFormatFlat = "flat"
+
+	// FieldNamingSnakeCase names JSON fields in snake_case, e.g., "alternative_names" (default).
+	FieldNamingSnakeCase = "snake_case"
+	// FieldNamingCamelCase names JSON fields in camelCase, e.g., "alternativeNames".
+	FieldNamingCamelCase = "camelCase"
+)
+
+// IsValidResponseFormat reports whether format is a supported response format.
+func IsValidResponseFormat(format string) bool {
+	return format == ResponseFormatEnveloped || format == ResponseFormatBare
+}
+
+// IsValidMetadataFormat reports whether format is a supported metadata format.
+func IsValidMetadataFormat(format string) bool {
+	return format == MetadataFormatNested || format == MetadataFormatFlat
+}
+
+// IsValidFieldNaming reports whether naming is a supported naming of JSON fields.
+func IsValidFieldNaming(naming string) bool {
+	return naming == FieldNamingSnakeCase || naming == FieldNamingCamelCase
+}
@@ -0,0 +1,51 @@
+package model
+
+// NormalizePage defaults page and perPage if they are not positive and caps perPage at MaxPerPage.
+func NormalizePage(page, perPage int) (int, int) {
+	if page < MinPage {
+		page = MinPage
+	}
+	if perPage < MinPerPage {
+		perPage = DefaultPerPage
+	} else if perPage > MaxPerPage {
+		perPage = MaxPerPage
+	}
+	return page, perPage
+}
+
+// NewPaginationInfo returns the pagination metadata of the given page of total items.
+// All paginated endpoints use it, so they report pages the same way.
+func NewPaginationInfo(page, perPage, total int) *PaginationInfo {
+	totalPages := (total + perPage - 1) / perPage // Ceiling division
+	return &PaginationInfo{
+		CurrentPage: page,
+		PerPage:     perPage,
+		Total:       total,
+		TotalPages:  totalPages,
+		HasNext:     page < totalPages,
+		HasPrev:     page > 1,
+	}
+}
+
+// Bounds returns the range [start, end) of the items on the current page.
+// The range is empty for pages beyond the last one.
+func (p *PaginationInfo) Bounds() (start, end int) {
+	start = min((p.CurrentPage-1)*p.PerPage, p.Total)
+	end = min(start+p.PerPage, p.Total)
+	return start, end
+}
+
+// Paginate returns the given page of items and its pagination metadata.
+// page and perPage are normalized with NormalizePage. The page shares the backing array of items
+// and is empty, but not nil, for pages beyond the last one.
+func Paginate[T any](items []T, page, perPage int) ([]T, *PaginationInfo) {
+	page, perPage = NormalizePage(page, perPage)
+
+	pagination := NewPaginationInfo(page, perPage, len(items))
+	start, end := pagination.Bounds()
+	if items == nil {
+		return []T{}, pagination
+	}
+
+	return items[start:end], pagination
+}
@@ -0,0 +1,75 @@
+package model
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestPaginate(t *testing.T) {
+	items := []int{1, 2, 3, 4, 5}
+
+	tests := []struct {
+		name          string
+		items         []int
+		page, perPage int
+		want          []int
+		pagination    PaginationInfo
+	}{
+		{
+			name: "Empty", items: nil, page: 1, perPage: 2, want: []int{},
+			pagination: PaginationInfo{CurrentPage: 1, PerPage: 2},
+		},
+		{
+			name: "SinglePage", items: items, page: 1, perPage: 10, want: items,
+			pagination: PaginationInfo{CurrentPage: 1, PerPage: 10, Total: 5, TotalPages: 1},
+		},
+		{
+			name: "FirstPage", items: items, page: 1, perPage: 2, want: []int{1, 2},
+			pagination: PaginationInfo{CurrentPage: 1, PerPage: 2, Total: 5, TotalPages: 3, HasNext: true},
+		},
+		{
+			name: "MiddlePage", items: items, page: 2, perPage: 2, want: []int{3, 4},
+			pagination: PaginationInfo{CurrentPage: 2, PerPage: 2, Total: 5, TotalPages: 3, HasNext: true, HasPrev: true},
+		},
+		{
+			name: "LastPage", items: items, page: 3, perPage: 2, want: []int{5},
+			pagination: PaginationInfo{CurrentPage: 3, PerPage: 2, Total: 5, TotalPages: 3, HasPrev: true},
+		},
+		{
+			name: "OutOfRange", items: items, page: 4, perPage: 2, want: []int{},
+			pagination: PaginationInfo{CurrentPage: 4, PerPage: 2, Total: 5, TotalPages: 3, HasPrev: true},
+		},
+		{
+			name: "Defaults", items: items, page: 0, perPage: 0, want: items,
+			pagination: PaginationInfo{CurrentPage: MinPage, PerPage: DefaultPerPage, Total: 5, TotalPages: 1},
+		},
+		{
+			name: "PerPageCapped", items: items, page: 1, perPage: MaxPerPage + 1, want: items,
+			pagination: PaginationInfo{CurrentPage: 1, PerPage: MaxPerPage, Total: 5, TotalPages: 1},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, pagination := Paginate(tt.items, tt.page, tt.perPage)
+			require.NotNil(t, got)
+			require.Equal(t, tt.want, got)
+			require.Equal(t, &tt.pagination, pagination)
+		})
+	}
+}
+
+// TestPaginationInfo_MarshalJSON verifies the serialized pagination metadata, which all paginated
+// endpoints return unchanged.
+func TestPaginationInfo_MarshalJSON(t *testing.T) {
+	_, pagination := Paginate([]string{"a", "b", "c"}, 2, 1)
+	pagination.NextURL = "/api/v1/domains?page=3&per_page=1"
+	pagination.PrevURL = "/api/v1/domains?page=1&per_page=1"
+
+	data, err := json.Marshal(pagination)
+	require.NoError(t, err)
+	require.Equal(t, `{"current_page":2,"per_page":1,"total":3,"total_pages":3,"has_next":true,"has_prev":true,`+
+		`"next_url":"/api/v1/domains?page=3\u0026per_page=1","prev_url":"/api/v1/domains?page=1\u0026per_page=1"}`, string(data))
+}
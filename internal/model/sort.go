@@ -0,0 +1,102 @@
+package model
+
+import (
+	"cmp"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// SortFields are the fields domain entries can be sorted by.
+var SortFields = []string{"domain", "alias", "enabled", "comment"}
+
+// ErrInvalidSort is returned by ParseSort for unknown or repeated sort fields.
+var ErrInvalidSort = errors.New("invalid sort parameter")
+
+// SortKey is a field to sort domain entries by and its direction.
+type SortKey struct {
+	// Field is one of SortFields.
+	Field string
+
+	// Desc sorts in descending order.
+	Desc bool
+}
+
+// ParseSort parses a comma-separated list of sort fields, each optionally prefixed by '-' for
+// descending order, e.g., "-enabled,domain,alias". For compatibility, "asc" and "desc" sort by domain.
+// It returns no keys for an empty string.
+func ParseSort(s string) ([]SortKey, error) {
+	switch s = strings.TrimSpace(s); s {
+	case "":
+		return nil, nil
+	case "asc":
+		return []SortKey{{Field: "domain"}}, nil
+	case "desc":
+		return []SortKey{{Field: "domain", Desc: true}}, nil
+	}
+
+	var keys []SortKey
+	for _, field := range strings.Split(s, ",") {
+		field = strings.TrimSpace(field)
+		desc := strings.HasPrefix(field, "-")
+		field = strings.TrimPrefix(field, "-")
+
+		if !slices.Contains(SortFields, field) {
+			return nil, fmt.Errorf("%w: unknown field %q, use one of %v", ErrInvalidSort, field, SortFields)
+		}
+		if slices.ContainsFunc(keys, func(k SortKey) bool { return k.Field == field }) {
+			return nil, fmt.Errorf("%w: field %q given more than once", ErrInvalidSort, field)
+		}
+		keys = append(keys, SortKey{Field: field, Desc: desc})
+	}
+
+	return keys, nil
+}
+
+// SortBy sorts the entries by the given keys. The sort is stable, entries equal under all keys keep their order.
+func (e DomainEntries) SortBy(keys []SortKey) {
+	if len(keys) == 0 {
+		return
+	}
+
+	slices.SortStableFunc(e, func(a, b *DomainEntry) int {
+		for _, key := range keys {
+			c := compareField(a, b, key.Field)
+			if key.Desc {
+				c = -c
+			}
+			if c != 0 {
+				return c
+			}
+		}
+		return 0
+	})
+}
+
+// compareField compares a field of two entries, disabled entries and entries without alias come first.
+func compareField(a, b *DomainEntry, field string) int {
+	switch field {
+	case "domain":
+		return cmp.Compare(a.Domain, b.Domain)
+	case "alias":
+		return cmp.Compare(a.Alias, b.Alias)
+	case "enabled":
+		return compareBool(a.Enabled, b.Enabled)
+	case "comment":
+		return cmp.Compare(a.Comment, b.Comment)
+	}
+	return 0
+}
+
+// compareBool orders false before true.
+func compareBool(a, b bool) int {
+	switch {
+	case a == b:
+		return 0
+	case !a:
+		return -1
+	default:
+		return 1
+	}
+}
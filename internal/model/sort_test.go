@@ -0,0 +1,89 @@
+package model
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	pb "github.com/schumann-it/dehydrated-api-go/plugin/proto"
+)
+
+func TestParseSort(t *testing.T) {
+	tests := []struct {
+		sort    string
+		want    []SortKey
+		wantErr bool
+	}{
+		{sort: "", want: nil},
+		{sort: "asc", want: []SortKey{{Field: "domain"}}},
+		{sort: "desc", want: []SortKey{{Field: "domain", Desc: true}}},
+		{sort: "domain,alias", want: []SortKey{{Field: "domain"}, {Field: "alias"}}},
+		{sort: "-enabled, domain", want: []SortKey{{Field: "enabled", Desc: true}, {Field: "domain"}}},
+		{sort: "comment", want: []SortKey{{Field: "comment"}}},
+		{sort: "invalid", wantErr: true},
+		{sort: "domain,metadata", wantErr: true},
+		{sort: "domain,-domain", wantErr: true},
+		{sort: "domain,", wantErr: true},
+		{sort: "--domain", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.sort, func(t *testing.T) {
+			keys, err := ParseSort(tt.sort)
+			if tt.wantErr {
+				require.ErrorIs(t, err, ErrInvalidSort)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tt.want, keys)
+		})
+	}
+}
+
+func TestDomainEntries_SortBy(t *testing.T) {
+	entry := func(domain, alias string, enabled bool) *DomainEntry {
+		return &DomainEntry{DomainEntry: pb.DomainEntry{Domain: domain, Alias: alias, Enabled: enabled}}
+	}
+	names := func(entries DomainEntries) []string {
+		result := make([]string, len(entries))
+		for i, e := range entries {
+			result[i] = e.Domain + ">" + e.Alias
+		}
+		return result
+	}
+
+	newEntries := func() DomainEntries {
+		return DomainEntries{
+			entry("b.com", "y", true),
+			entry("a.com", "", false),
+			entry("b.com", "", false),
+			entry("a.com", "x", true),
+			entry("c.com", "", true),
+		}
+	}
+
+	tests := []struct {
+		sort string
+		want []string
+	}{
+		{sort: "", want: []string{"b.com>y", "a.com>", "b.com>", "a.com>x", "c.com>"}},
+		// Stable, aliased variants keep their order
+		{sort: "domain", want: []string{"a.com>", "a.com>x", "b.com>y", "b.com>", "c.com>"}},
+		{sort: "domain,alias", want: []string{"a.com>", "a.com>x", "b.com>", "b.com>y", "c.com>"}},
+		{sort: "domain,-alias", want: []string{"a.com>x", "a.com>", "b.com>y", "b.com>", "c.com>"}},
+		{sort: "-domain,alias", want: []string{"c.com>", "b.com>", "b.com>y", "a.com>", "a.com>x"}},
+		{sort: "enabled,domain", want: []string{"a.com>", "b.com>", "a.com>x", "b.com>y", "c.com>"}},
+		{sort: "-enabled,domain,alias", want: []string{"a.com>x", "b.com>y", "c.com>", "a.com>", "b.com>"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.sort, func(t *testing.T) {
+			keys, err := ParseSort(tt.sort)
+			require.NoError(t, err)
+
+			entries := newEntries()
+			entries.SortBy(keys)
+			require.Equal(t, tt.want, names(entries))
+		})
+	}
+}
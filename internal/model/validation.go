@@ -1,9 +1,19 @@
 package model
 
 import (
+	"fmt"
 	"regexp"
+	"strings"
+	"unicode"
+	"unicode/utf8"
 )
 
+// DefaultMaxCommentLength is the maximum length of comments set via the API in characters, unless configured otherwise.
+const DefaultMaxCommentLength = 256
+
+// DefaultMaxAliasLength is the maximum length of aliases set via the API in characters, unless configured otherwise.
+const DefaultMaxAliasLength = 64
+
 // IsValidDomain checks if a string is a valid domain name or wildcard domain.
 // It validates the domain against a regular expression that enforces the following rules:
 // - Domain parts can contain letters, numbers, and hyphens
@@ -33,3 +43,65 @@ func IsValidDomain(domain string) bool {
 func IsValidDomainEntry(entry *DomainEntry) bool {
 	return IsValidDomain(entry.Domain)
 }
+
+// CheckAlternativeNames checks that all alternative names are valid domain names and that no name,
+// including the primary domain, is listed twice. Names are compared case-insensitively.
+func CheckAlternativeNames(domain string, names []string) error {
+	seen := map[string]bool{strings.ToLower(domain): true}
+	for _, name := range names {
+		if !IsValidDomain(name) {
+			return fmt.Errorf("invalid alternative name %q", name)
+		}
+		if seen[strings.ToLower(name)] {
+			return fmt.Errorf("duplicate alternative name %q", name)
+		}
+		seen[strings.ToLower(name)] = true
+	}
+	return nil
+}
+
+// IsValidDomainSuffix checks if a string is a valid domain suffix as matched by MatchesDomainSuffix:
+// a domain name, which may consist of a single label like "com", optionally with a leading '.'.
+func IsValidDomainSuffix(suffix string) bool {
+	suffix = strings.TrimPrefix(suffix, ".")
+	return suffix != "" && !strings.HasPrefix(suffix, "*") && IsValidDomain("x."+suffix)
+}
+
+// MatchesDomainSuffix checks if the domain name is covered by suffix, case-insensitively.
+// A suffix like "example.com" matches the domain itself and all its subdomains, including wildcards
+// like "*.example.com". A suffix with a leading '.', like ".example.com", matches subdomains only.
+func MatchesDomainSuffix(domain, suffix string) bool {
+	domain = strings.ToLower(domain)
+	suffix = strings.ToLower(suffix)
+
+	if strings.HasPrefix(suffix, ".") {
+		return strings.HasSuffix(domain, suffix)
+	}
+	return domain == suffix || strings.HasSuffix(domain, "."+suffix)
+}
+
+// IsValidAlias checks if an alias is safe to use as the directory name of a certificate below CertDir
+// and fits into a single line of the domains file. It may only contain letters, digits, '.', '-' and '_',
+// must not start with '.', so it cannot refer to "." or "..", and, if maxLength is positive, must not be
+// longer than maxLength characters. An empty alias is valid.
+func IsValidAlias(alias string, maxLength int) bool {
+	if maxLength > 0 && len(alias) > maxLength {
+		return false
+	}
+	if strings.HasPrefix(alias, ".") {
+		return false
+	}
+	return !strings.ContainsFunc(alias, func(r rune) bool {
+		return (r < 'a' || r > 'z') && (r < 'A' || r > 'Z') && (r < '0' || r > '9') && r != '.' && r != '-' && r != '_'
+	})
+}
+
+// IsValidComment checks if a comment fits into a single line of the domains file.
+// It must not contain control characters such as newlines and, if maxLength is positive,
+// must not be longer than maxLength characters.
+func IsValidComment(comment string, maxLength int) bool {
+	if maxLength > 0 && utf8.RuneCountInString(comment) > maxLength {
+		return false
+	}
+	return !strings.ContainsFunc(comment, unicode.IsControl)
+}
@@ -101,3 +101,137 @@ func TestIsValidDomainEntry(t *testing.T) {
 		})
 	}
 }
+
+// TestCheckAlternativeNames verifies that invalid and duplicate alternative names are rejected.
+func TestCheckAlternativeNames(t *testing.T) {
+	tests := []struct {
+		name     string
+		names    []string
+		expected bool
+	}{
+		{"No names", nil, true},
+		{"Valid names", []string{"www.example.com", "*.example.com"}, true},
+		{"Invalid name", []string{"www.example.com", "not a domain"}, false},
+		{"Duplicate name", []string{"www.example.com", "WWW.example.com"}, false},
+		{"Primary domain", []string{"example.com"}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := CheckAlternativeNames("example.com", tt.names); (err == nil) != tt.expected {
+				t.Errorf("CheckAlternativeNames(%q) = %v; want valid %v", tt.names, err, tt.expected)
+			}
+		})
+	}
+}
+
+// TestIsValidAlias verifies that aliases which would break the line format or leave CertDir are rejected.
+func TestIsValidAlias(t *testing.T) {
+	tests := []struct {
+		name      string
+		alias     string
+		maxLength int
+		expected  bool
+	}{
+		{"Empty alias", "", 10, true},
+		{"Valid alias", "example.com-rsa_2", 0, true},
+		{"Alias with space", "my alias", 0, false},
+		{"Alias with newline", "alias\nexample.org", 0, false},
+		{"Alias with comment separator", "alias#1", 0, false},
+		{"Alias with alias separator", "a>b", 0, false},
+		{"Alias with slash", "certs/example", 0, false},
+		{"Alias with backslash", "certs\\example", 0, false},
+		{"Alias with parent directory", "../example", 0, false},
+		{"Parent directory", "..", 0, false},
+		{"Current directory", ".", 0, false},
+		{"Hidden directory", ".example", 0, false},
+		{"Alias with null byte", "a\x00b", 0, false},
+		{"Non-ASCII alias", "exämple", 0, false},
+		{"Alias at limit", "0123456789", 10, true},
+		{"Alias exceeding limit", "01234567890", 10, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if result := IsValidAlias(tt.alias, tt.maxLength); result != tt.expected {
+				t.Errorf("IsValidAlias(%q, %d) = %v; want %v", tt.alias, tt.maxLength, result, tt.expected)
+			}
+		})
+	}
+}
+
+// TestIsValidComment verifies that multi-line comments and comments exceeding the limit are rejected.
+func TestIsValidComment(t *testing.T) {
+	tests := []struct {
+		name      string
+		comment   string
+		maxLength int
+		expected  bool
+	}{
+		{"Empty comment", "", 10, true},
+		{"Valid comment", "Production # web", 20, true},
+		{"Multi-line comment", "first line\nsecond line", 0, false},
+		{"Carriage return", "comment\r", 0, false},
+		{"Tab", "a\tb", 0, false},
+		{"Comment at limit", "äöü", 3, true},
+		{"Comment over limit", "äöüß", 3, false},
+		{"Unlimited", "a very long comment", 0, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if result := IsValidComment(tt.comment, tt.maxLength); result != tt.expected {
+				t.Errorf("IsValidComment(%q, %d) = %v; want %v", tt.comment, tt.maxLength, result, tt.expected)
+			}
+		})
+	}
+}
+
+// TestMatchesDomainSuffix verifies that suffixes match on label boundaries and that a leading '.'
+// restricts a suffix to subdomains.
+func TestMatchesDomainSuffix(t *testing.T) {
+	tests := []struct {
+		domain, suffix string
+		expected       bool
+	}{
+		{"example.com", "example.com", true},
+		{"sub.example.com", "example.com", true},
+		{"*.example.com", "example.com", true},
+		{"Sub.Example.COM", "example.com", true},
+		{"notexample.com", "example.com", false},
+		{"example.com.evil.org", "example.com", false},
+		{"example.com", ".example.com", false},
+		{"sub.example.com", ".example.com", true},
+		{"*.example.com", ".example.com", true},
+		{"example.org", "org", true},
+	}
+
+	for _, tt := range tests {
+		if result := MatchesDomainSuffix(tt.domain, tt.suffix); result != tt.expected {
+			t.Errorf("MatchesDomainSuffix(%q, %q) = %v; want %v", tt.domain, tt.suffix, result, tt.expected)
+		}
+	}
+}
+
+// TestIsValidDomainSuffix verifies the validation of configured domain suffixes.
+func TestIsValidDomainSuffix(t *testing.T) {
+	tests := []struct {
+		suffix   string
+		expected bool
+	}{
+		{"example.com", true},
+		{".example.com", true},
+		{"com", true},
+		{"", false},
+		{".", false},
+		{"*.example.com", false},
+		{"example..com", false},
+		{"exa mple.com", false},
+	}
+
+	for _, tt := range tests {
+		if result := IsValidDomainSuffix(tt.suffix); result != tt.expected {
+			t.Errorf("IsValidDomainSuffix(%q) = %v; want %v", tt.suffix, result, tt.expected)
+		}
+	}
+}
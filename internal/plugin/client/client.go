@@ -12,6 +12,7 @@ import (
 
 	"github.com/hashicorp/go-hclog"
 	"github.com/hashicorp/go-plugin"
+	pluginconfig "github.com/schumann-it/dehydrated-api-go/internal/plugin/config"
 	pb "github.com/schumann-it/dehydrated-api-go/plugin/proto"
 	"google.golang.org/grpc"
 	"google.golang.org/protobuf/types/known/structpb"
@@ -19,10 +20,13 @@ import (
 
 // Client represents a plugin client
 type Client struct {
-	client    *plugin.Client
-	rpcClient plugin.ClientProtocol
+	client    *plugin.Client        // go-plugin client of subprocess plugins
+	rpcClient plugin.ClientProtocol // go-plugin protocol client of subprocess plugins
+	conn      *grpc.ClientConn      // connection of TCP plugins
 	plugin    pb.PluginClient
 	logger    hclog.Logger
+
+	capabilities *pb.InitializeResponse // Capabilities the plugin advertised in Initialize
 }
 
 // GRPCPlugin is the plugin implementation for go-plugin
@@ -51,8 +55,31 @@ func (p *GRPCPlugin) Client(_ *plugin.MuxBroker, _ *rpc.Client) (any, error) {
 	return nil, fmt.Errorf("net/rpc not supported")
 }
 
+// Options configures how a plugin client is started.
+type Options struct {
+	// StartupTimeout bounds the time to wait for the plugin process to start and to become ready
+	// for Initialize. If zero, config.DefaultStartupTimeout applies.
+	StartupTimeout time.Duration
+
+	// Insecure allows a plaintext connection to a TCP plugin without TLS configuration.
+	Insecure bool
+
+	// Env contains environment variables set on the plugin process in addition to,
+	// and taking precedence over, the environment of the API.
+	Env pluginconfig.Env
+}
+
+// readinessInterval is the interval in which the plugin connection is probed until it is ready.
+const readinessInterval = 50 * time.Millisecond
+
 // NewClient creates a new plugin client
-func NewClient(ctx context.Context, pluginName, pluginPath string, config map[string]*structpb.Value) (*Client, error) {
+func NewClient(ctx context.Context, pluginName, pluginPath string, config map[string]*structpb.Value, opts Options) (*Client, error) {
+	timeout := opts.StartupTimeout
+	if timeout <= 0 {
+		timeout = pluginconfig.DefaultStartupTimeout
+	}
+	deadline := time.Now().Add(timeout)
+
 	// Create logger
 	logger := hclog.New(&hclog.LoggerOptions{
 		Name:   "plugin-client",
@@ -60,6 +87,11 @@ func NewClient(ctx context.Context, pluginName, pluginPath string, config map[st
 		Output: os.Stdout,
 	})
 
+	// The plugin inherits the environment, extended by the configured variables.
+	// The host environment is added here rather than by go-plugin, so the configured variables win.
+	cmd := exec.Command(pluginPath)
+	cmd.Env = append(os.Environ(), opts.Env.Environ()...)
+
 	// Create the plugin client
 	client := plugin.NewClient(&plugin.ClientConfig{
 		HandshakeConfig: plugin.HandshakeConfig{
@@ -70,8 +102,10 @@ func NewClient(ctx context.Context, pluginName, pluginPath string, config map[st
 		Plugins: map[string]plugin.Plugin{
 			pluginName: &GRPCPlugin{},
 		},
-		Cmd:    exec.Command(pluginPath),
-		Logger: logger,
+		Cmd:          cmd,
+		SkipHostEnv:  true,
+		Logger:       logger,
+		StartTimeout: timeout,
 		AllowedProtocols: []plugin.Protocol{
 			plugin.ProtocolGRPC,
 		},
@@ -80,39 +114,74 @@ func NewClient(ctx context.Context, pluginName, pluginPath string, config map[st
 	// Connect to the plugin
 	rpcClient, err := client.Client()
 	if err != nil {
+		client.Kill()
 		return nil, fmt.Errorf("failed to connect to plugin %s: %w", pluginName, err)
 	}
 
+	// Wait until the plugin answers on its connection
+	if err := waitReady(rpcClient, deadline); err != nil {
+		client.Kill()
+		return nil, fmt.Errorf("plugin %s not ready after %s: %w", pluginName, timeout, err)
+	}
+
 	// Get the plugin instance
 	raw, err := rpcClient.Dispense(pluginName)
 	if err != nil {
+		client.Kill()
 		return nil, fmt.Errorf("failed to dispense plugin %s: %w", pluginName, err)
 	}
 
 	// Type assert to our plugin interface
 	p, ok := raw.(pb.PluginClient)
 	if !ok {
+		client.Kill()
 		return nil, fmt.Errorf("plugin does not implement Plugin interface")
 	}
 
-	if _, err := p.Initialize(ctx, &pb.InitializeRequest{
+	initCtx, cancel := context.WithDeadline(ctx, deadline)
+	defer cancel()
+
+	resp, err := p.Initialize(initCtx, &pb.InitializeRequest{
 		Config: config,
-	}); err != nil {
+	})
+	if err != nil {
+		client.Kill()
 		return nil, fmt.Errorf("failed to initialize plugin: %w", err)
 	}
 
 	return &Client{
-		client:    client,
-		rpcClient: rpcClient,
-		plugin:    p,
-		logger:    logger,
+		client:       client,
+		rpcClient:    rpcClient,
+		plugin:       p,
+		logger:       logger,
+		capabilities: resp,
 	}, nil
 }
 
+// waitReady probes the plugin connection until it answers or the deadline has passed.
+func waitReady(rpcClient plugin.ClientProtocol, deadline time.Time) error {
+	for {
+		err := rpcClient.Ping()
+		if err == nil {
+			return nil
+		}
+		if time.Now().Add(readinessInterval).After(deadline) {
+			return err
+		}
+		time.Sleep(readinessInterval)
+	}
+}
+
 func (c *Client) Plugin() pb.PluginClient {
 	return c.plugin
 }
 
+// Capabilities returns the capabilities the plugin advertised when it was initialized,
+// see pb.InitializeResponse.SupportedCapabilities.
+func (c *Client) Capabilities() []string {
+	return c.capabilities.SupportedCapabilities()
+}
+
 // Close closes the plugin client and cleans up resources
 func (c *Client) Close() error {
 	var errs []error
@@ -130,6 +199,13 @@ func (c *Client) Close() error {
 		c.client.Kill()
 	}
 
+	// Close the connection to a TCP plugin
+	if c.conn != nil {
+		if err := c.conn.Close(); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
 	if len(errs) > 0 {
 		return fmt.Errorf("errors during cleanup: %v", errs)
 	}
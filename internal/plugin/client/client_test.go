@@ -8,12 +8,39 @@ import (
 	"time"
 
 	"github.com/schumann-it/dehydrated-api-go/internal/plugin/config"
+	"github.com/schumann-it/dehydrated-api-go/plugin/server"
+	"google.golang.org/protobuf/types/known/structpb"
 
 	pb "github.com/schumann-it/dehydrated-api-go/plugin/proto"
 
 	"github.com/stretchr/testify/require"
 )
 
+// testPluginEnv makes the test binary serve envPlugin instead of running the tests,
+// so tests can launch it as a plugin subprocess.
+const testPluginEnv = "DEHYDRATED_API_TEST_PLUGIN"
+
+func TestMain(m *testing.M) {
+	if os.Getenv(testPluginEnv) == "env" {
+		server.NewPluginServer(&envPlugin{}).Serve()
+		os.Exit(0)
+	}
+	os.Exit(m.Run())
+}
+
+// envPlugin returns the value of the TEST_PLUGIN_SECRET environment variable as metadata.
+type envPlugin struct {
+	mockPlugin
+}
+
+func (p *envPlugin) GetMetadata(_ context.Context, _ *pb.GetMetadataRequest) (*pb.GetMetadataResponse, error) {
+	return &pb.GetMetadataResponse{
+		Metadata: map[string]*structpb.Value{
+			"secret": structpb.NewStringValue(os.Getenv("TEST_PLUGIN_SECRET")),
+		},
+	}, nil
+}
+
 func TestClient(t *testing.T) {
 	// Build the example plugin
 	pluginPath := filepath.Join("..", "..", "..", "examples", "plugins", "simple", "simple")
@@ -36,7 +63,7 @@ func TestClient(t *testing.T) {
 	require.NoError(t, err)
 
 	// Create a new client
-	client, err := NewClient(ctx, "example", pluginPath, cfgValues)
+	client, err := NewClient(ctx, "example", pluginPath, cfgValues, Options{})
 	require.NoError(t, err)
 	defer client.Close()
 
@@ -109,3 +136,75 @@ func TestClient(t *testing.T) {
 	require.Equal(t, float64(42), resp.Metadata["example_number"].GetNumberValue())
 	require.True(t, resp.Metadata["example_bool"].GetBoolValue())
 }
+
+// TestClientDisabledEntries verifies that the example plugin only returns metadata for disabled entries
+// with enrichDisabled.
+func TestClientDisabledEntries(t *testing.T) {
+	pluginPath := filepath.Join("..", "..", "..", "examples", "plugins", "simple", "simple")
+	if _, err := os.Stat(pluginPath); os.IsNotExist(err) {
+		t.Skip("Example plugin not built, skipping test")
+	}
+
+	req := &pb.GetMetadataRequest{
+		DomainEntry:      &pb.DomainEntry{Domain: "example.com", Enabled: false},
+		DehydratedConfig: &pb.DehydratedConfig{},
+	}
+
+	for _, enrichDisabled := range []bool{false, true} {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+
+		cfg := &config.PluginConfig{Config: map[string]any{"name": "example", "enrichDisabled": enrichDisabled}}
+		cfgValues, err := cfg.ToProto()
+		require.NoError(t, err)
+
+		client, err := NewClient(ctx, "example", pluginPath, cfgValues, Options{})
+		require.NoError(t, err)
+		defer client.Close()
+
+		resp, err := client.plugin.GetMetadata(ctx, req)
+		require.NoError(t, err)
+		if enrichDisabled {
+			require.Equal(t, "example", resp.Metadata["name"].GetStringValue())
+			require.Equal(t, "example.com", resp.Metadata["domain"].GetStringValue())
+		} else {
+			require.Empty(t, resp.Metadata)
+		}
+	}
+}
+
+func TestClientStartupTimeout(t *testing.T) {
+	// A plugin that never completes the handshake
+	pluginPath := filepath.Join(t.TempDir(), "slow")
+	//nolint:gosec // the test plugin has to be executable
+	require.NoError(t, os.WriteFile(pluginPath, []byte("#!/bin/sh\nexec sleep 10\n"), 0755))
+
+	start := time.Now()
+	_, err := NewClient(context.Background(), "slow", pluginPath, nil, Options{
+		StartupTimeout: 200 * time.Millisecond,
+	})
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "timeout")
+	require.Less(t, time.Since(start), 5*time.Second)
+}
+
+func TestClientEnv(t *testing.T) {
+	// The variable of the API environment is overridden by the plugin configuration
+	t.Setenv("TEST_PLUGIN_SECRET", "host")
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	c, err := NewClient(ctx, "env", os.Args[0], nil, Options{
+		Env: config.Env{
+			testPluginEnv:        "env",
+			"TEST_PLUGIN_SECRET": "s3cr3t",
+		},
+	})
+	require.NoError(t, err)
+	defer c.Close()
+
+	resp, err := c.Plugin().GetMetadata(ctx, &pb.GetMetadataRequest{})
+	require.NoError(t, err)
+	require.Equal(t, "s3cr3t", resp.Metadata["secret"].GetStringValue())
+}
@@ -0,0 +1,120 @@
+package client
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/hashicorp/go-hclog"
+	pluginconfig "github.com/schumann-it/dehydrated-api-go/internal/plugin/config"
+	pb "github.com/schumann-it/dehydrated-api-go/plugin/proto"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/connectivity"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/protobuf/types/known/structpb"
+)
+
+// NewTCPClient creates a plugin client connected to an already running plugin listening on address (host:port).
+// The connection uses (mutual) TLS according to tlsConfig. A plaintext connection requires tlsConfig
+// to be nil and opts.Insecure to be set.
+func NewTCPClient(ctx context.Context, pluginName, address string, tlsConfig *pluginconfig.TLSConfig, config map[string]*structpb.Value, opts Options) (*Client, error) {
+	if tlsConfig == nil && !opts.Insecure {
+		return nil, fmt.Errorf("plugin %s: TLS configuration required for TCP plugins; set insecure to allow plaintext", pluginName)
+	}
+
+	timeout := opts.StartupTimeout
+	if timeout <= 0 {
+		timeout = pluginconfig.DefaultStartupTimeout
+	}
+	deadline := time.Now().Add(timeout)
+
+	creds, err := transportCredentials(tlsConfig)
+	if err != nil {
+		return nil, fmt.Errorf("failed to configure TLS for plugin %s: %w", pluginName, err)
+	}
+
+	conn, err := grpc.NewClient(address, grpc.WithTransportCredentials(creds))
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to plugin %s: %w", pluginName, err)
+	}
+
+	// Wait until the connection is established
+	readyCtx, cancel := context.WithDeadline(ctx, deadline)
+	defer cancel()
+	if err := waitConnReady(readyCtx, conn); err != nil {
+		_ = conn.Close()
+		return nil, fmt.Errorf("plugin %s not ready after %s: %w", pluginName, timeout, err)
+	}
+
+	p := pb.NewPluginClient(conn)
+	resp, err := p.Initialize(readyCtx, &pb.InitializeRequest{
+		Config: config,
+	})
+	if err != nil {
+		_ = conn.Close()
+		return nil, fmt.Errorf("failed to initialize plugin: %w", err)
+	}
+
+	return &Client{
+		conn:         conn,
+		plugin:       p,
+		capabilities: resp,
+		logger: hclog.New(&hclog.LoggerOptions{
+			Name:   "plugin-client",
+			Level:  hclog.Trace,
+			Output: os.Stdout,
+		}),
+	}, nil
+}
+
+// transportCredentials returns the gRPC transport credentials for the given TLS configuration.
+func transportCredentials(cfg *pluginconfig.TLSConfig) (credentials.TransportCredentials, error) {
+	if cfg == nil {
+		return insecure.NewCredentials(), nil
+	}
+
+	tlsConfig := &tls.Config{
+		MinVersion: tls.VersionTLS12,
+		ServerName: cfg.ServerName,
+	}
+
+	if cfg.CAFile != "" {
+		pem, err := os.ReadFile(cfg.CAFile)
+		if err != nil {
+			return nil, err
+		}
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("no certificates found in %s", cfg.CAFile)
+		}
+		tlsConfig.RootCAs = pool
+	}
+
+	if cfg.CertFile != "" || cfg.KeyFile != "" {
+		cert, err := tls.LoadX509KeyPair(cfg.CertFile, cfg.KeyFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load client certificate: %w", err)
+		}
+		tlsConfig.Certificates = []tls.Certificate{cert}
+	}
+
+	return credentials.NewTLS(tlsConfig), nil
+}
+
+// waitConnReady connects conn and waits until it is ready or ctx is done.
+func waitConnReady(ctx context.Context, conn *grpc.ClientConn) error {
+	conn.Connect()
+	for {
+		state := conn.GetState()
+		if state == connectivity.Ready {
+			return nil
+		}
+		if !conn.WaitForStateChange(ctx, state) {
+			return fmt.Errorf("timeout waiting for connection, last state %s", state)
+		}
+	}
+}
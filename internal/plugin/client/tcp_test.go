@@ -0,0 +1,165 @@
+package client
+
+import (
+	"context"
+	"crypto/x509"
+	"net"
+	"testing"
+	"time"
+
+	pluginconfig "github.com/schumann-it/dehydrated-api-go/internal/plugin/config"
+	"github.com/schumann-it/dehydrated-api-go/internal/testutil"
+	pb "github.com/schumann-it/dehydrated-api-go/plugin/proto"
+	"github.com/schumann-it/dehydrated-api-go/plugin/server"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/types/known/structpb"
+)
+
+// mockPlugin returns the name it was initialized with as metadata, also in batches.
+type mockPlugin struct {
+	pb.UnimplementedPluginServer
+	name string
+}
+
+func (m *mockPlugin) Initialize(_ context.Context, req *pb.InitializeRequest) (*pb.InitializeResponse, error) {
+	m.name = req.GetConfig()["name"].GetStringValue()
+	return &pb.InitializeResponse{Capabilities: []string{pb.CapabilityMetadataBatch}}, nil
+}
+
+func (m *mockPlugin) GetMetadata(_ context.Context, req *pb.GetMetadataRequest) (*pb.GetMetadataResponse, error) {
+	return &pb.GetMetadataResponse{
+		Metadata: map[string]*structpb.Value{
+			"name":   structpb.NewStringValue(m.name),
+			"domain": structpb.NewStringValue(req.GetDomainEntry().GetDomain()),
+		},
+	}, nil
+}
+
+func (m *mockPlugin) GetMetadataBatch(ctx context.Context, req *pb.GetMetadataBatchRequest) (*pb.GetMetadataBatchResponse, error) {
+	return pb.GetMetadataBatch(ctx, req, m.GetMetadata)
+}
+
+func (m *mockPlugin) Close(_ context.Context, _ *pb.CloseRequest) (*pb.CloseResponse, error) {
+	return &pb.CloseResponse{}, nil
+}
+
+func TestTCPClient(t *testing.T) {
+	address, _ := testutil.ServePlugin(t, &mockPlugin{})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	c, err := NewTCPClient(ctx, "mock", address, nil, map[string]*structpb.Value{
+		"name": structpb.NewStringValue("remote"),
+	}, Options{Insecure: true})
+	require.NoError(t, err)
+	defer c.Close()
+
+	resp, err := c.Plugin().GetMetadata(ctx, &pb.GetMetadataRequest{
+		DomainEntry: &pb.DomainEntry{Domain: "example.com"},
+	})
+	require.NoError(t, err)
+	require.Equal(t, "remote", resp.Metadata["name"].GetStringValue())
+	require.Equal(t, "example.com", resp.Metadata["domain"].GetStringValue())
+
+	// The capabilities advertised in Initialize are kept
+	require.Equal(t, []string{pb.CapabilityMetadataBatch}, c.Capabilities())
+
+	batch, err := c.Plugin().GetMetadataBatch(ctx, &pb.GetMetadataBatchRequest{Requests: []*pb.GetMetadataRequest{
+		{DomainEntry: &pb.DomainEntry{Domain: "example.com"}},
+		{DomainEntry: &pb.DomainEntry{Domain: "example.org", Alias: "org"}},
+	}})
+	require.NoError(t, err)
+	require.Len(t, batch.Responses, 2)
+	require.Equal(t, resp.Metadata["domain"].GetStringValue(), batch.Responses["example.com"].Metadata["domain"].GetStringValue())
+	require.Equal(t, "example.org", batch.Responses["example.org/org"].Metadata["domain"].GetStringValue())
+}
+
+func TestTCPClientUnreachable(t *testing.T) {
+	// Reserve a port and release it, so nothing is listening on it
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	address := lis.Addr().String()
+	require.NoError(t, lis.Close())
+
+	start := time.Now()
+	_, err = NewTCPClient(context.Background(), "mock", address, nil, nil, Options{
+		StartupTimeout: 300 * time.Millisecond,
+		Insecure:       true,
+	})
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "not ready")
+	require.Less(t, time.Since(start), 5*time.Second)
+}
+
+func TestTCPClientRequiresTLS(t *testing.T) {
+	_, err := NewTCPClient(context.Background(), "mock", "127.0.0.1:1", nil, nil, Options{})
+	require.ErrorContains(t, err, "TLS configuration required")
+}
+
+func TestTCPClientMutualTLS(t *testing.T) {
+	dir := t.TempDir()
+	ca := testutil.NewCA(t, dir, "ca")
+	serverCert, serverKey := ca.Issue(t, dir, "plugin.test", x509.ExtKeyUsageServerAuth)
+	clientCert, clientKey := ca.Issue(t, dir, "api.test", x509.ExtKeyUsageClientAuth)
+
+	creds, err := server.MutualTLS(serverCert, serverKey, ca.File)
+	require.NoError(t, err)
+	address, _ := testutil.ServePlugin(t, &mockPlugin{}, creds)
+
+	connect := func(tlsConfig *pluginconfig.TLSConfig) (*Client, error) {
+		return NewTCPClient(context.Background(), "mock", address, tlsConfig, map[string]*structpb.Value{
+			"name": structpb.NewStringValue("mtls"),
+		}, Options{StartupTimeout: 2 * time.Second})
+	}
+
+	t.Run("ValidClientCertificate", func(t *testing.T) {
+		c, err := connect(&pluginconfig.TLSConfig{
+			CAFile:     ca.File,
+			ServerName: "plugin.test",
+			CertFile:   clientCert,
+			KeyFile:    clientKey,
+		})
+		require.NoError(t, err)
+		defer c.Close()
+
+		resp, err := c.Plugin().GetMetadata(context.Background(), &pb.GetMetadataRequest{
+			DomainEntry: &pb.DomainEntry{Domain: "example.com"},
+		})
+		require.NoError(t, err)
+		require.Equal(t, "mtls", resp.Metadata["name"].GetStringValue())
+	})
+
+	t.Run("MissingClientCertificate", func(t *testing.T) {
+		_, err := connect(&pluginconfig.TLSConfig{
+			CAFile:     ca.File,
+			ServerName: "plugin.test",
+		})
+		require.Error(t, err)
+	})
+
+	t.Run("UntrustedClientCertificate", func(t *testing.T) {
+		other := testutil.NewCA(t, dir, "other-ca")
+		otherCert, otherKey := other.Issue(t, dir, "rogue.test", x509.ExtKeyUsageClientAuth)
+
+		_, err := connect(&pluginconfig.TLSConfig{
+			CAFile:     ca.File,
+			ServerName: "plugin.test",
+			CertFile:   otherCert,
+			KeyFile:    otherKey,
+		})
+		require.Error(t, err)
+	})
+
+	t.Run("UntrustedServerCertificate", func(t *testing.T) {
+		other := testutil.NewCA(t, dir, "server-other-ca")
+
+		_, err := connect(&pluginconfig.TLSConfig{
+			CAFile:     other.File,
+			ServerName: "plugin.test",
+			CertFile:   clientCert,
+			KeyFile:    clientKey,
+		})
+		require.Error(t, err)
+	})
+}
@@ -1,7 +1,14 @@
 package config
 
 import (
+	"encoding/json"
 	"fmt"
+	"maps"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"go.uber.org/zap/zapcore"
 
 	"google.golang.org/protobuf/types/known/structpb"
 )
@@ -23,9 +30,200 @@ type PluginConfig struct {
 	// Registry configuration for plugin source
 	Registry *RegistryConfig `yaml:"registry"`
 
+	// Address of an already running plugin to connect to over TCP (host:port).
+	// If set, the plugin is not started as a subprocess and Registry is ignored.
+	Address string `yaml:"address"`
+
+	// TLS configures (mutual) TLS for the TCP connection to the plugin.
+	// It is required for TCP plugins unless Insecure is set.
+	TLS *TLSConfig `yaml:"tls"`
+
+	// Insecure explicitly allows a plaintext TCP connection to the plugin if TLS is not set.
+	// Local plugins always communicate over a Unix socket and are not affected.
+	Insecure bool `yaml:"insecure"`
+
 	// Config contains plugin-specific configuration settings.
 	// The structure of this map depends on the specific plugin implementation.
 	Config map[string]any `yaml:"config"`
+
+	// Env contains environment variables set on the plugin process, e.g., secrets like API keys
+	// that should not be passed in Config. Values are redacted when logged or marshaled.
+	Env Env `yaml:"env"`
+
+	// StartupTimeout bounds the time to wait for the plugin to start and become ready.
+	// If not specified, DefaultStartupTimeout applies.
+	StartupTimeout time.Duration `yaml:"startupTimeout"`
+
+	// CircuitBreaker configures the circuit breaker guarding GetMetadata calls.
+	// If not specified, the defaults of CircuitBreakerConfig apply.
+	CircuitBreaker *CircuitBreakerConfig `yaml:"circuitBreaker"`
+
+	// Validate enables calling the plugin's Validate method before domain entries are created or changed.
+	// The change is rejected if the plugin reports the entry as invalid or the call fails.
+	Validate bool `yaml:"validate"`
+
+	// Priority orders the plugins: plugins with a higher priority are called first for metadata and
+	// validation, and their values win if metadata is merged. Plugins with the same priority are
+	// ordered by name. The default is 0.
+	Priority int `yaml:"priority"`
+
+	// DisableBatch calls GetMetadata for each listed domain entry even if the plugin
+	// advertises GetMetadataBatch, e.g., to work around a faulty batch implementation.
+	DisableBatch bool `yaml:"disableBatch"`
+}
+
+// SortByPriority sorts the plugin names by descending priority, then by name.
+func SortByPriority(names []string, priority func(name string) int) {
+	sort.SliceStable(names, func(i, j int) bool {
+		if pi, pj := priority(names[i]), priority(names[j]); pi != pj {
+			return pi > pj
+		}
+		return names[i] < names[j]
+	})
+}
+
+// ResolvePaths returns a copy of the configuration with its relative file paths, i.e., the path
+// of a local plugin and the TLS files, resolved against root.
+func (c PluginConfig) ResolvePaths(root string) PluginConfig {
+	if c.Registry != nil && c.Registry.Type == PluginSourceTypeLocal {
+		if p, ok := c.Registry.Config["path"].(string); ok {
+			r := *c.Registry
+			r.Config = maps.Clone(c.Registry.Config)
+			r.Config["path"] = resolvePath(root, p)
+			c.Registry = &r
+		}
+	}
+
+	if c.TLS != nil {
+		t := *c.TLS
+		t.CAFile = resolvePath(root, t.CAFile)
+		t.CertFile = resolvePath(root, t.CertFile)
+		t.KeyFile = resolvePath(root, t.KeyFile)
+		c.TLS = &t
+	}
+
+	return c
+}
+
+// resolvePath joins a relative path to root, empty and absolute paths are returned unchanged.
+func resolvePath(root, p string) string {
+	if p == "" || filepath.IsAbs(p) {
+		return p
+	}
+	return filepath.Join(root, p)
+}
+
+// Env holds environment variables for a plugin process.
+// Its values are redacted in its string, JSON and log representations.
+type Env map[string]string
+
+// redacted replaces the values of environment variables in their representations.
+const redacted = "***"
+
+// Environ returns the variables in the "key=value" form of os.Environ, sorted by key.
+func (e Env) Environ() []string {
+	keys := e.keys()
+	environ := make([]string, 0, len(keys))
+	for _, k := range keys {
+		environ = append(environ, k+"="+e[k])
+	}
+	return environ
+}
+
+// Redacted returns a copy with all values redacted.
+func (e Env) Redacted() map[string]string {
+	if e == nil {
+		return nil
+	}
+	r := make(map[string]string, len(e))
+	for k := range e {
+		r[k] = redacted
+	}
+	return r
+}
+
+// String implements fmt.Stringer with redacted values.
+func (e Env) String() string {
+	return fmt.Sprint(e.Redacted())
+}
+
+// MarshalJSON implements json.Marshaler with redacted values.
+func (e Env) MarshalJSON() ([]byte, error) {
+	return json.Marshal(e.Redacted())
+}
+
+// MarshalLogObject implements zapcore.ObjectMarshaler with redacted values.
+func (e Env) MarshalLogObject(enc zapcore.ObjectEncoder) error {
+	for _, k := range e.keys() {
+		enc.AddString(k, redacted)
+	}
+	return nil
+}
+
+func (e Env) keys() []string {
+	keys := make([]string, 0, len(e))
+	for k := range e {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// DefaultStartupTimeout is the default time to wait for a plugin to start and become ready.
+const DefaultStartupTimeout = time.Minute
+
+// TLSConfig holds the TLS settings of a TCP plugin connection.
+type TLSConfig struct {
+	// CAFile is the PEM file of the CA the plugin certificate is verified against.
+	// If not specified, the system roots are used.
+	CAFile string `yaml:"caFile"`
+
+	// ServerName overrides the name the plugin certificate is verified against.
+	// If not specified, the host of Address is used.
+	ServerName string `yaml:"serverName"`
+
+	// CertFile and KeyFile are the PEM files of the client certificate presented to the plugin
+	// for mutual TLS. Both must be set together.
+	CertFile string `yaml:"certFile"`
+	KeyFile  string `yaml:"keyFile"`
+}
+
+// Default circuit breaker settings, used for every zero value in CircuitBreakerConfig.
+const (
+	DefaultCircuitBreakerFailureThreshold = 5
+	DefaultCircuitBreakerWindow           = time.Minute
+	DefaultCircuitBreakerCoolDown         = 30 * time.Second
+)
+
+// CircuitBreakerConfig holds the thresholds of a plugin circuit breaker.
+type CircuitBreakerConfig struct {
+	// FailureThreshold is the number of consecutive failures within Window that opens the circuit.
+	FailureThreshold int `yaml:"failureThreshold"`
+
+	// Window is the period in which the consecutive failures have to occur.
+	Window time.Duration `yaml:"window"`
+
+	// CoolDown is the period the circuit stays open before a probe call is let through.
+	CoolDown time.Duration `yaml:"coolDown"`
+}
+
+// WithDefaults returns a copy of the config with all zero values replaced by the defaults.
+// It is safe to call on a nil config.
+func (c *CircuitBreakerConfig) WithDefaults() CircuitBreakerConfig {
+	cfg := CircuitBreakerConfig{}
+	if c != nil {
+		cfg = *c
+	}
+	if cfg.FailureThreshold <= 0 {
+		cfg.FailureThreshold = DefaultCircuitBreakerFailureThreshold
+	}
+	if cfg.Window <= 0 {
+		cfg.Window = DefaultCircuitBreakerWindow
+	}
+	if cfg.CoolDown <= 0 {
+		cfg.CoolDown = DefaultCircuitBreakerCoolDown
+	}
+	return cfg
 }
 
 // RegistryConfig represents the configuration for a plugin registry
@@ -0,0 +1,33 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+func TestEnvRedaction(t *testing.T) {
+	env := Env{"API_KEY": "s3cr3t", "REGION": "eu"}
+
+	require.Equal(t, []string{"API_KEY=s3cr3t", "REGION=eu"}, env.Environ())
+
+	require.NotContains(t, env.String(), "s3cr3t")
+	require.NotContains(t, fmt.Sprintf("%v", PluginConfig{Env: env}), "s3cr3t")
+
+	data, err := json.Marshal(PluginConfig{Env: env})
+	require.NoError(t, err)
+	require.NotContains(t, string(data), "s3cr3t")
+	require.Contains(t, string(data), `"API_KEY":"***"`)
+
+	core, logs := observer.New(zapcore.InfoLevel)
+	zap.New(core).Info("plugin", zap.Object("env", env), zap.Any("config", PluginConfig{Env: env}))
+	require.Equal(t, 1, logs.Len())
+	for _, v := range logs.All()[0].ContextMap() {
+		require.NotContains(t, fmt.Sprint(v), "s3cr3t")
+	}
+}
@@ -0,0 +1,172 @@
+package registry
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/schumann-it/dehydrated-api-go/internal/plugin/config"
+	pb "github.com/schumann-it/dehydrated-api-go/plugin/proto"
+	"go.uber.org/zap"
+	"google.golang.org/grpc"
+)
+
+// ErrCircuitOpen is the error reported for GetMetadata calls short-circuited by an open circuit.
+const ErrCircuitOpen = "circuit open"
+
+type circuitState int
+
+const (
+	circuitClosed circuitState = iota
+	circuitOpen
+	circuitHalfOpen
+)
+
+func (s circuitState) String() string {
+	switch s {
+	case circuitOpen:
+		return "open"
+	case circuitHalfOpen:
+		return "half-open"
+	default:
+		return "closed"
+	}
+}
+
+// CircuitBreaker wraps a plugin client and stops calling GetMetadata after repeated failures.
+// After FailureThreshold consecutive failures within Window the circuit opens and GetMetadata
+// returns an error response without calling the plugin. Once CoolDown has passed, a single
+// probe call is let through: success closes the circuit, failure opens it again.
+type CircuitBreaker struct {
+	pb.PluginClient
+
+	name   string
+	cfg    config.CircuitBreakerConfig
+	logger *zap.Logger
+	now    func() time.Time
+
+	mutex        sync.Mutex
+	state        circuitState
+	failures     int
+	firstFailure time.Time
+	openedAt     time.Time
+	probing      bool
+}
+
+// NewCircuitBreaker creates a circuit breaker for the named plugin client.
+func NewCircuitBreaker(name string, p pb.PluginClient, cfg *config.CircuitBreakerConfig, logger *zap.Logger) *CircuitBreaker {
+	return &CircuitBreaker{
+		PluginClient: p,
+		name:         name,
+		cfg:          cfg.WithDefaults(),
+		logger:       logger,
+		now:          time.Now,
+	}
+}
+
+// GetMetadata calls the wrapped plugin unless the circuit is open.
+func (b *CircuitBreaker) GetMetadata(ctx context.Context, in *pb.GetMetadataRequest, opts ...grpc.CallOption) (*pb.GetMetadataResponse, error) {
+	if !b.allow() {
+		return &pb.GetMetadataResponse{Error: ErrCircuitOpen}, nil
+	}
+
+	resp, err := b.PluginClient.GetMetadata(ctx, in, opts...)
+	b.record(err == nil && (resp == nil || resp.Error == ""))
+
+	return resp, err
+}
+
+// GetMetadataBatch calls the wrapped plugin unless the circuit is open, in which case every entry
+// gets an error response. The batch counts as a single call, it fails if any entry failed.
+func (b *CircuitBreaker) GetMetadataBatch(ctx context.Context, in *pb.GetMetadataBatchRequest, opts ...grpc.CallOption) (*pb.GetMetadataBatchResponse, error) {
+	if !b.allow() {
+		resp := &pb.GetMetadataBatchResponse{Responses: make(map[string]*pb.GetMetadataResponse, len(in.GetRequests()))}
+		for _, r := range in.GetRequests() {
+			resp.Responses[pb.MetadataBatchKey(r.GetDomainEntry())] = &pb.GetMetadataResponse{Error: ErrCircuitOpen}
+		}
+		return resp, nil
+	}
+
+	resp, err := b.PluginClient.GetMetadataBatch(ctx, in, opts...)
+	success := err == nil
+	for _, r := range resp.GetResponses() {
+		if r.GetError() != "" {
+			success = false
+		}
+	}
+	b.record(success)
+
+	return resp, err
+}
+
+// allow reports whether a call may be passed to the plugin.
+func (b *CircuitBreaker) allow() bool {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	switch b.state {
+	case circuitOpen:
+		if b.now().Sub(b.openedAt) < b.cfg.CoolDown {
+			return false
+		}
+		b.setState(circuitHalfOpen)
+		b.probing = true
+		return true
+	case circuitHalfOpen:
+		// only a single probe call at a time
+		if b.probing {
+			return false
+		}
+		b.probing = true
+		return true
+	default:
+		return true
+	}
+}
+
+// record updates the circuit with the outcome of a plugin call.
+func (b *CircuitBreaker) record(success bool) {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	now := b.now()
+
+	if b.state == circuitHalfOpen {
+		b.probing = false
+		if success {
+			b.failures = 0
+			b.setState(circuitClosed)
+		} else {
+			b.openedAt = now
+			b.setState(circuitOpen)
+		}
+		return
+	}
+
+	if success {
+		b.failures = 0
+		return
+	}
+
+	if b.failures == 0 || now.Sub(b.firstFailure) > b.cfg.Window {
+		b.failures = 0
+		b.firstFailure = now
+	}
+	b.failures++
+
+	if b.state == circuitClosed && b.failures >= b.cfg.FailureThreshold {
+		b.openedAt = now
+		b.setState(circuitOpen)
+	}
+}
+
+func (b *CircuitBreaker) setState(s circuitState) {
+	if b.state == s {
+		return
+	}
+	b.state = s
+	b.logger.Warn("Plugin circuit breaker changed state",
+		zap.String("plugin", b.name),
+		zap.String("state", s.String()),
+		zap.Int("failures", b.failures))
+}
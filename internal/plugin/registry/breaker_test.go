@@ -0,0 +1,175 @@
+package registry
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/schumann-it/dehydrated-api-go/internal/plugin/config"
+	pb "github.com/schumann-it/dehydrated-api-go/plugin/proto"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"google.golang.org/grpc"
+)
+
+// mockPlugin is a plugin client whose GetMetadata outcome can be switched between failure and success.
+type mockPlugin struct {
+	pb.PluginClient
+	fail  bool
+	calls int
+}
+
+func (m *mockPlugin) GetMetadata(_ context.Context, _ *pb.GetMetadataRequest, _ ...grpc.CallOption) (*pb.GetMetadataResponse, error) {
+	m.calls++
+	if m.fail {
+		return nil, errors.New("plugin unavailable")
+	}
+	return &pb.GetMetadataResponse{}, nil
+}
+
+func (m *mockPlugin) GetMetadataBatch(_ context.Context, in *pb.GetMetadataBatchRequest, _ ...grpc.CallOption) (*pb.GetMetadataBatchResponse, error) {
+	m.calls++
+	if m.fail {
+		return nil, errors.New("plugin unavailable")
+	}
+	return &pb.GetMetadataBatchResponse{}, nil
+}
+
+func newTestBreaker(p pb.PluginClient, cfg *config.CircuitBreakerConfig) (*CircuitBreaker, *time.Time) {
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	b := NewCircuitBreaker("mock", p, cfg, zap.NewNop())
+	b.now = func() time.Time { return now }
+	return b, &now
+}
+
+func TestCircuitBreaker(t *testing.T) {
+	cfg := &config.CircuitBreakerConfig{
+		FailureThreshold: 3,
+		Window:           time.Minute,
+		CoolDown:         30 * time.Second,
+	}
+	req := &pb.GetMetadataRequest{}
+
+	t.Run("TripAndRecover", func(t *testing.T) {
+		p := &mockPlugin{fail: true}
+		b, now := newTestBreaker(p, cfg)
+
+		for i := 0; i < 3; i++ {
+			_, err := b.GetMetadata(context.Background(), req)
+			require.Error(t, err)
+		}
+		require.Equal(t, circuitOpen, b.state)
+
+		// open circuit short-circuits without calling the plugin
+		resp, err := b.GetMetadata(context.Background(), req)
+		require.NoError(t, err)
+		require.Equal(t, ErrCircuitOpen, resp.Error)
+		require.Equal(t, 3, p.calls)
+
+		// failed probe after cool-down opens the circuit again
+		*now = now.Add(31 * time.Second)
+		_, err = b.GetMetadata(context.Background(), req)
+		require.Error(t, err)
+		require.Equal(t, 4, p.calls)
+		require.Equal(t, circuitOpen, b.state)
+
+		resp, err = b.GetMetadata(context.Background(), req)
+		require.NoError(t, err)
+		require.Equal(t, ErrCircuitOpen, resp.Error)
+		require.Equal(t, 4, p.calls)
+
+		// successful probe closes the circuit
+		p.fail = false
+		*now = now.Add(31 * time.Second)
+		resp, err = b.GetMetadata(context.Background(), req)
+		require.NoError(t, err)
+		require.Empty(t, resp.Error)
+		require.Equal(t, circuitClosed, b.state)
+		require.Equal(t, 5, p.calls)
+	})
+
+	t.Run("FailuresOutsideWindow", func(t *testing.T) {
+		p := &mockPlugin{fail: true}
+		b, now := newTestBreaker(p, cfg)
+
+		for i := 0; i < 5; i++ {
+			_, _ = b.GetMetadata(context.Background(), req)
+			*now = now.Add(40 * time.Second)
+		}
+		require.Equal(t, circuitClosed, b.state)
+		require.Equal(t, 5, p.calls)
+	})
+
+	t.Run("SuccessResetsFailures", func(t *testing.T) {
+		p := &mockPlugin{fail: true}
+		b, _ := newTestBreaker(p, cfg)
+
+		for i := 0; i < 2; i++ {
+			_, _ = b.GetMetadata(context.Background(), req)
+		}
+		p.fail = false
+		_, _ = b.GetMetadata(context.Background(), req)
+		p.fail = true
+		for i := 0; i < 2; i++ {
+			_, _ = b.GetMetadata(context.Background(), req)
+		}
+		require.Equal(t, circuitClosed, b.state)
+	})
+
+	t.Run("ErrorResponseCountsAsFailure", func(t *testing.T) {
+		p := &errorResponsePlugin{}
+		b, _ := newTestBreaker(p, cfg)
+
+		for i := 0; i < 3; i++ {
+			_, _ = b.GetMetadata(context.Background(), req)
+		}
+		require.Equal(t, circuitOpen, b.state)
+	})
+
+	t.Run("Defaults", func(t *testing.T) {
+		b, _ := newTestBreaker(&mockPlugin{}, nil)
+		require.Equal(t, config.DefaultCircuitBreakerFailureThreshold, b.cfg.FailureThreshold)
+		require.Equal(t, config.DefaultCircuitBreakerWindow, b.cfg.Window)
+		require.Equal(t, config.DefaultCircuitBreakerCoolDown, b.cfg.CoolDown)
+	})
+}
+
+// errorResponsePlugin reports failures through the error field of the response.
+type errorResponsePlugin struct {
+	pb.PluginClient
+}
+
+func (m *errorResponsePlugin) GetMetadata(_ context.Context, _ *pb.GetMetadataRequest, _ ...grpc.CallOption) (*pb.GetMetadataResponse, error) {
+	return &pb.GetMetadataResponse{Error: "backend down"}, nil
+}
+
+func TestCircuitBreakerBatch(t *testing.T) {
+	cfg := &config.CircuitBreakerConfig{
+		FailureThreshold: 2,
+		Window:           time.Minute,
+		CoolDown:         30 * time.Second,
+	}
+	req := &pb.GetMetadataBatchRequest{Requests: []*pb.GetMetadataRequest{
+		{DomainEntry: &pb.DomainEntry{Domain: "example.com"}},
+		{DomainEntry: &pb.DomainEntry{Domain: "example.com", Alias: "ecc"}},
+	}}
+
+	p := &mockPlugin{fail: true}
+	b, _ := newTestBreaker(p, cfg)
+
+	// A failed batch counts as a single failure
+	for i := 0; i < 2; i++ {
+		_, err := b.GetMetadataBatch(context.Background(), req)
+		require.Error(t, err)
+	}
+	require.Equal(t, circuitOpen, b.state)
+
+	// The open circuit answers every entry with an error response
+	resp, err := b.GetMetadataBatch(context.Background(), req)
+	require.NoError(t, err)
+	require.Equal(t, 2, p.calls)
+	require.Len(t, resp.Responses, 2)
+	require.Equal(t, ErrCircuitOpen, resp.Responses["example.com"].Error)
+	require.Equal(t, ErrCircuitOpen, resp.Responses["example.com/ecc"].Error)
+}
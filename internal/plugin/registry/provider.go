@@ -0,0 +1,40 @@
+package registry
+
+import "context"
+
+// ConfigProvider supplies dynamic configuration values passed to a plugin on Initialize,
+// e.g., a short-lived token fetched whenever the plugin is started, which should not be kept
+// in the static configuration. The returned values are merged into the plugin's config,
+// overriding static values of the same key. The context is canceled after the startup timeout of the plugin.
+type ConfigProvider interface {
+	PluginConfig(ctx context.Context, name string) (map[string]any, error)
+}
+
+// ConfigProviderFunc adapts a function to a ConfigProvider.
+type ConfigProviderFunc func(ctx context.Context, name string) (map[string]any, error)
+
+// PluginConfig calls f(ctx, name).
+func (f ConfigProviderFunc) PluginConfig(ctx context.Context, name string) (map[string]any, error) {
+	return f(ctx, name)
+}
+
+// NoopConfigProvider is the default ConfigProvider, adding no values.
+type NoopConfigProvider struct{}
+
+// PluginConfig returns no values.
+func (NoopConfigProvider) PluginConfig(context.Context, string) (map[string]any, error) {
+	return nil, nil
+}
+
+// Option configures a Registry.
+type Option func(*Registry)
+
+// WithConfigProvider sets the provider of dynamic plugin configuration values.
+// A nil provider leaves the NoopConfigProvider in place.
+func WithConfigProvider(p ConfigProvider) Option {
+	return func(r *Registry) {
+		if p != nil {
+			r.provider = p
+		}
+	}
+}
@@ -0,0 +1,103 @@
+package registry
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/schumann-it/dehydrated-api-go/internal/plugin/config"
+	"github.com/schumann-it/dehydrated-api-go/internal/testutil"
+	pb "github.com/schumann-it/dehydrated-api-go/plugin/proto"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"google.golang.org/protobuf/types/known/structpb"
+)
+
+// initRecordingPlugin records the config it was initialized with.
+type initRecordingPlugin struct {
+	pb.UnimplementedPluginServer
+	config chan map[string]*structpb.Value
+}
+
+func (p *initRecordingPlugin) Initialize(_ context.Context, req *pb.InitializeRequest) (*pb.InitializeResponse, error) {
+	p.config <- req.GetConfig()
+	return &pb.InitializeResponse{}, nil
+}
+
+func (p *initRecordingPlugin) Close(_ context.Context, _ *pb.CloseRequest) (*pb.CloseResponse, error) {
+	return &pb.CloseResponse{}, nil
+}
+
+func TestConfigProvider(t *testing.T) {
+	plugin := &initRecordingPlugin{config: make(chan map[string]*structpb.Value, 1)}
+
+	address, _ := testutil.ServePlugin(t, plugin)
+
+	cfg := map[string]config.PluginConfig{
+		"remote": {
+			Enabled:  true,
+			Address:  address,
+			Insecure: true,
+			Config: map[string]any{
+				"endpoint": "https://api.example.com",
+				"token":    "static",
+			},
+		},
+	}
+
+	t.Run("InjectsValues", func(t *testing.T) {
+		var names []string
+		provider := ConfigProviderFunc(func(_ context.Context, name string) (map[string]any, error) {
+			names = append(names, name)
+			return map[string]any{"token": "fetched"}, nil
+		})
+
+		r := New(t.TempDir(), cfg, zap.NewNop(), WithConfigProvider(provider))
+		defer r.Close()
+		require.Contains(t, r.Plugins(), "remote")
+		require.Equal(t, []string{"remote"}, names)
+
+		received := <-plugin.config
+		require.Equal(t, "fetched", received["token"].GetStringValue())
+		require.Equal(t, "https://api.example.com", received["endpoint"].GetStringValue())
+		// the caller's configuration is left untouched
+		require.Equal(t, "static", cfg["remote"].Config["token"])
+	})
+
+	t.Run("Default", func(t *testing.T) {
+		r := New(t.TempDir(), cfg, zap.NewNop())
+		defer r.Close()
+		require.Contains(t, r.Plugins(), "remote")
+
+		received := <-plugin.config
+		require.Equal(t, "static", received["token"].GetStringValue())
+	})
+
+	t.Run("ProviderError", func(t *testing.T) {
+		provider := ConfigProviderFunc(func(context.Context, string) (map[string]any, error) {
+			return nil, errors.New("token service unavailable")
+		})
+
+		r := New(t.TempDir(), cfg, zap.NewNop(), WithConfigProvider(provider))
+		defer r.Close()
+		require.NotContains(t, r.Plugins(), "remote")
+	})
+
+	t.Run("ProviderTimeout", func(t *testing.T) {
+		provider := ConfigProviderFunc(func(ctx context.Context, _ string) (map[string]any, error) {
+			<-ctx.Done()
+			return nil, ctx.Err()
+		})
+
+		cfg := map[string]config.PluginConfig{
+			"remote": {Enabled: true, Address: address, Insecure: true, StartupTimeout: 50 * time.Millisecond},
+		}
+		r := New(t.TempDir(), cfg, zap.NewNop(), WithConfigProvider(provider))
+		defer r.Close()
+		require.NotContains(t, r.Plugins(), "remote")
+		failures := r.Failures()
+		require.Len(t, failures, 1)
+		require.Contains(t, failures[0].Reason, context.DeadlineExceeded.Error())
+	})
+}
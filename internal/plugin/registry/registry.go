@@ -2,8 +2,14 @@ package registry
 
 import (
 	"context"
+	"slices"
+	"strings"
+	"time"
 
+	"github.com/schumann-it/dehydrated-api-go/internal/model"
 	"github.com/schumann-it/dehydrated-api-go/internal/plugin/cache"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
 	"google.golang.org/protobuf/types/known/structpb"
 
 	"github.com/schumann-it/dehydrated-api-go/internal/plugin/client"
@@ -13,14 +19,29 @@ import (
 )
 
 type Registry struct {
-	clients map[string]*client.Client
-	logger  *zap.Logger
+	clients    map[string]*client.Client
+	breakers   map[string]*CircuitBreaker
+	validators map[string]bool
+	priorities map[string]int
+	capability map[string][]string
+	failures   map[string]*model.PluginFailure
+	provider   ConfigProvider
+	logger     *zap.Logger
 }
 
-func New(baseDir string, cfg map[string]config.PluginConfig, logger *zap.Logger) *Registry {
+func New(baseDir string, cfg map[string]config.PluginConfig, logger *zap.Logger, opts ...Option) *Registry {
 	r := &Registry{
-		clients: make(map[string]*client.Client),
-		logger:  logger,
+		clients:    make(map[string]*client.Client),
+		breakers:   make(map[string]*CircuitBreaker),
+		validators: make(map[string]bool),
+		priorities: make(map[string]int),
+		capability: make(map[string][]string),
+		failures:   make(map[string]*model.PluginFailure),
+		provider:   NoopConfigProvider{},
+		logger:     logger,
+	}
+	for _, opt := range opts {
+		opt(r)
 	}
 
 	err := cache.Prepare(baseDir)
@@ -36,71 +57,218 @@ func New(baseDir string, cfg map[string]config.PluginConfig, logger *zap.Logger)
 			continue
 		}
 
-		_, err := cache.Add(n, c.Registry)
+		// plugins connected over TCP are not started from the cache
+		if c.Address == "" {
+			_, err := cache.Add(n, c.Registry)
+			if err != nil {
+				r.logger.Error("Failed to add plugin to cache; ignoring plugin",
+					zap.String("plugin", n),
+					zap.Error(err))
+				r.fail(n, model.PluginDead, err)
+				continue
+			}
+		}
+
+		// add log level configuration form the main logger, if not set specifically.
+		// The map is copied, so the caller's configuration is left untouched.
+		values := make(map[string]any, len(c.Config)+1)
+		for k, v := range c.Config {
+			values[k] = v
+		}
+		if _, ok := values["logLevel"]; !ok {
+			values["logLevel"] = logger.Level().String()
+		}
+
+		// add dynamic values, e.g., short-lived credentials
+		dynamic, err := r.dynamicConfig(n, c.StartupTimeout)
 		if err != nil {
-			r.logger.Error("Failed to add plugin to cache; ignoring plugin",
+			r.logger.Error("Failed to get dynamic plugin config; ignoring plugin",
 				zap.String("plugin", n),
 				zap.Error(err))
+			r.fail(n, model.PluginDead, err)
 			continue
 		}
-
-		// add log level configuration form the main logger, if not set specifically
-		if _, ok := c.Config["logLevel"]; !ok {
-			c.Config["logLevel"] = logger.Level().String()
+		for k, v := range dynamic {
+			values[k] = v
 		}
+		c.Config = values
 
 		pluginConfig, err := c.ToProto()
 		if err != nil {
 			r.logger.Error("Failed to convert plugin config to proto; ignoring plugin",
 				zap.String("plugin", n),
 				zap.Error(err))
+			r.fail(n, model.PluginMisconfigured, err)
 			continue
 		}
-		r.register(n, pluginConfig)
+		r.register(n, pluginConfig, c)
 	}
 
 	return r
 }
 
-func (r *Registry) register(name string, cfg map[string]*structpb.Value) {
-	// Get plugin path using the new registry system or fallback to old system
-	pluginPath, err := cache.Get(name)
-	if err != nil {
-		r.logger.Error("Failed to get plugin path; ignoring plugin",
-			zap.String("plugin", name),
-			zap.Error(err))
-		return
+// dynamicConfig returns the dynamic configuration values of the plugin from the provider,
+// which has the startup timeout of the plugin to return them.
+func (r *Registry) dynamicConfig(name string, timeout time.Duration) (map[string]any, error) {
+	if timeout == 0 {
+		timeout = config.DefaultStartupTimeout
 	}
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+	return r.provider.PluginConfig(ctx, name)
+}
 
-	// Create a new client
-	c, err := client.NewClient(context.Background(), name, pluginPath, cfg)
-	if err != nil {
-		r.logger.Error("Failed to create plugin client; ignoring plugin",
-			zap.String("plugin", name),
-			zap.String("path", pluginPath),
-			zap.Error(err))
-		return
+func (r *Registry) register(name string, cfg map[string]*structpb.Value, pc config.PluginConfig) {
+	opts := client.Options{
+		StartupTimeout: pc.StartupTimeout,
+		Insecure:       pc.Insecure,
+		Env:            pc.Env,
+	}
+
+	var c *client.Client
+	var location zap.Field
+	if pc.Address != "" {
+		// Connect to a plugin listening on a TCP address
+		location = zap.String("address", pc.Address)
+
+		var err error
+		c, err = client.NewTCPClient(context.Background(), name, pc.Address, pc.TLS, cfg, opts)
+		if err != nil {
+			r.logger.Error("Failed to create plugin client; ignoring plugin",
+				zap.String("plugin", name),
+				location,
+				zap.Error(err))
+			r.fail(name, clientFailureState(err), err)
+			return
+		}
+	} else {
+		// Get plugin path using the new registry system or fallback to old system
+		pluginPath, err := cache.Get(name)
+		if err != nil {
+			r.logger.Error("Failed to get plugin path; ignoring plugin",
+				zap.String("plugin", name),
+				zap.Error(err))
+			r.fail(name, model.PluginDead, err)
+			return
+		}
+		location = zap.String("path", pluginPath)
+
+		// Create a new client
+		c, err = client.NewClient(context.Background(), name, pluginPath, cfg, opts)
+		if err != nil {
+			r.logger.Error("Failed to create plugin client; ignoring plugin",
+				zap.String("plugin", name),
+				location,
+				zap.Error(err))
+			r.fail(name, clientFailureState(err), err)
+			return
+		}
 	}
 
 	r.clients[name] = c
+	r.breakers[name] = NewCircuitBreaker(name, c.Plugin(), pc.CircuitBreaker, r.logger)
+	r.validators[name] = pc.Validate
+	r.priorities[name] = pc.Priority
+	r.capability[name] = c.Capabilities()
+	if pc.DisableBatch {
+		r.capability[name] = slices.DeleteFunc(r.capability[name], func(c string) bool { return c == pb.CapabilityMetadataBatch })
+	}
 	r.logger.Info("Plugin registered successfully",
 		zap.String("plugin", name),
-		zap.String("path", pluginPath))
+		location,
+		zap.Strings("capabilities", r.capability[name]))
+}
+
+// fail records that the named plugin is not available.
+func (r *Registry) fail(name, state string, err error) {
+	r.failures[name] = &model.PluginFailure{Name: name, State: state, Reason: err.Error()}
+}
+
+// clientFailureState returns the state of a plugin whose client could not be created.
+// Plugins reject their configuration by failing Initialize with codes.InvalidArgument.
+func clientFailureState(err error) string {
+	if status.Code(err) == codes.InvalidArgument {
+		return model.PluginMisconfigured
+	}
+	return model.PluginDead
+}
+
+// Failures returns the enabled plugins that are not available, sorted by name.
+func (r *Registry) Failures() []*model.PluginFailure {
+	var f []*model.PluginFailure
+
+	if r != nil {
+		for _, failure := range r.failures {
+			f = append(f, failure)
+		}
+		slices.SortFunc(f, func(a, b *model.PluginFailure) int {
+			return strings.Compare(a.Name, b.Name)
+		})
+	}
+
+	return f
 }
 
+// Plugins returns the registered plugins, each guarded by its circuit breaker.
 func (r *Registry) Plugins() map[string]pb.PluginClient {
 	p := make(map[string]pb.PluginClient)
 
 	if r != nil {
-		for n, c := range r.clients {
-			p[n] = c.Plugin()
+		for n, b := range r.breakers {
+			p[n] = b
 		}
 	}
 
 	return p
 }
 
+// Validators returns the registered plugins with validation enabled.
+// The circuit breakers only guard GetMetadata, Validate is always passed to the plugin.
+func (r *Registry) Validators() map[string]pb.PluginClient {
+	p := make(map[string]pb.PluginClient)
+
+	if r != nil {
+		for n, b := range r.breakers {
+			if r.validators[n] {
+				p[n] = b
+			}
+		}
+	}
+
+	return p
+}
+
+// Capabilities returns the capabilities the named plugin advertised, without pb.CapabilityMetadataBatch
+// if batching is disabled for it.
+func (r *Registry) Capabilities(name string) []string {
+	if r == nil {
+		return nil
+	}
+
+	return slices.Clone(r.capability[name])
+}
+
+// Order returns the names of the registered plugins by descending priority, then by name.
+func (r *Registry) Order() []string {
+	if r == nil {
+		return nil
+	}
+
+	names := make([]string, 0, len(r.breakers))
+	for n := range r.breakers {
+		names = append(names, n)
+	}
+	config.SortByPriority(names, func(name string) int { return r.priorities[name] })
+
+	return names
+}
+
+// Close closes the connections to all registered plugins.
 func (r *Registry) Close() {
+	if r == nil {
+		return
+	}
+
 	for name, c := range r.clients {
 		r.logger.Debug("Closing plugin client", zap.String("plugin", name))
 		err := c.Close()
@@ -2,15 +2,20 @@ package registry
 
 import (
 	"context"
+	"net"
 	"os"
 	"path/filepath"
 	"testing"
 	"time"
 
+	"github.com/schumann-it/dehydrated-api-go/internal/model"
 	"github.com/schumann-it/dehydrated-api-go/internal/plugin/cache"
 
 	"github.com/schumann-it/dehydrated-api-go/internal/plugin/config"
+	"github.com/schumann-it/dehydrated-api-go/internal/testutil"
 	pb "github.com/schumann-it/dehydrated-api-go/plugin/proto"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
 
 	"github.com/stretchr/testify/require"
 	"go.uber.org/zap"
@@ -76,3 +81,93 @@ func TestRegistry(t *testing.T) {
 
 	cache.Clean()
 }
+
+// initPlugin fails Initialize with err, if set, or advertises capabilities.
+type initPlugin struct {
+	pb.UnimplementedPluginServer
+	err          error
+	capabilities []string
+}
+
+func (p *initPlugin) Initialize(_ context.Context, _ *pb.InitializeRequest) (*pb.InitializeResponse, error) {
+	if p.err != nil {
+		return nil, p.err
+	}
+	return &pb.InitializeResponse{Capabilities: p.capabilities}, nil
+}
+
+func TestRegistryFailures(t *testing.T) {
+	// Reserve an address nothing listens on
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	unreachable := lis.Addr().String()
+	require.NoError(t, lis.Close())
+
+	ok, _ := testutil.ServePlugin(t, &initPlugin{})
+	typo, _ := testutil.ServePlugin(t, &initPlugin{err: status.Error(codes.InvalidArgument, "missing apiToken")})
+	broken, _ := testutil.ServePlugin(t, &initPlugin{err: status.Error(codes.Internal, "out of memory")})
+
+	r := New(t.TempDir(), map[string]config.PluginConfig{
+		"ok":     {Enabled: true, Address: ok, Insecure: true},
+		"typo":   {Enabled: true, Address: typo, Insecure: true},
+		"broken": {Enabled: true, Address: broken, Insecure: true},
+		"gone":   {Enabled: true, Address: unreachable, Insecure: true, StartupTimeout: 200 * time.Millisecond},
+		"off":    {Enabled: false, Address: unreachable},
+	}, zap.NewNop())
+	defer r.Close()
+
+	require.Contains(t, r.Plugins(), "ok")
+	require.Len(t, r.Plugins(), 1)
+
+	failures := r.Failures()
+	require.Len(t, failures, 3)
+
+	require.Equal(t, "broken", failures[0].Name)
+	require.Equal(t, model.PluginDead, failures[0].State)
+	require.Contains(t, failures[0].Reason, "out of memory")
+
+	require.Equal(t, "gone", failures[1].Name)
+	require.Equal(t, model.PluginDead, failures[1].State)
+
+	require.Equal(t, "typo", failures[2].Name)
+	require.Equal(t, model.PluginMisconfigured, failures[2].State)
+	require.Contains(t, failures[2].Reason, "missing apiToken")
+
+	// A nil registry has no failures
+	var nilRegistry *Registry
+	require.Empty(t, nilRegistry.Failures())
+	nilRegistry.Close()
+}
+
+func TestRegistryOrder(t *testing.T) {
+	r := &Registry{
+		breakers:   map[string]*CircuitBreaker{"b": nil, "a": nil, "c": nil, "d": nil},
+		priorities: map[string]int{"c": 10, "d": -1},
+	}
+	require.Equal(t, []string{"c", "a", "b", "d"}, r.Order())
+
+	var nilRegistry *Registry
+	require.Nil(t, nilRegistry.Order())
+}
+
+func TestRegistryCapabilities(t *testing.T) {
+	batch := []string{pb.CapabilityMetadataBatch, pb.CapabilityValidate}
+	legacy, _ := testutil.ServePlugin(t, &initPlugin{})
+	batched, _ := testutil.ServePlugin(t, &initPlugin{capabilities: batch})
+	disabled, _ := testutil.ServePlugin(t, &initPlugin{capabilities: batch})
+	r := New(t.TempDir(), map[string]config.PluginConfig{
+		"legacy":   {Enabled: true, Address: legacy, Insecure: true},
+		"batch":    {Enabled: true, Address: batched, Insecure: true},
+		"disabled": {Enabled: true, Address: disabled, Insecure: true, DisableBatch: true},
+	}, zap.NewNop())
+	defer r.Close()
+
+	require.Equal(t, []string{pb.CapabilityValidate}, r.Capabilities("legacy"), "plugins advertising nothing support Validate")
+	require.Equal(t, batch, r.Capabilities("batch"))
+	require.Equal(t, []string{pb.CapabilityValidate}, r.Capabilities("disabled"))
+	require.Empty(t, r.Capabilities("unknown"))
+
+	// The recorded capabilities cannot be changed by callers
+	r.Capabilities("batch")[0] = "changed"
+	require.Equal(t, batch, r.Capabilities("batch"))
+}
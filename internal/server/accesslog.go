@@ -0,0 +1,71 @@
+package server
+
+import (
+	"slices"
+
+	"github.com/gofiber/contrib/fiberzap/v2"
+	"github.com/gofiber/fiber/v2"
+	"go.uber.org/zap"
+
+	"github.com/schumann-it/dehydrated-api-go/internal/auth"
+)
+
+// DefaultAccessLogFields are the fields of the access log if logging.accessLogFields is not set.
+var DefaultAccessLogFields = []string{"ip", "latency", "status", "method", "url", "actor", "roles"}
+
+// requestLogFields are the fields of the request logged by fiberzap.
+var requestLogFields = []string{
+	"referer", "protocol", "pid", "port", "ip", "ips", "host", "path", "url", "ua", "latency", "status",
+	"resBody", "queryParams", "body", "bytesReceived", "bytesSent", "route", "method", "requestId", "error",
+	"reqHeaders",
+}
+
+// identityLogFields are the fields of the caller's identity, see auth.Identity. If authentication is not
+// configured, the actor is auth.Anonymous.
+var identityLogFields = []string{"actor", "roles"}
+
+// isAccessLogField reports whether field is a field of the request or of the caller's identity.
+func isAccessLogField(field string) bool {
+	return slices.Contains(requestLogFields, field) || slices.Contains(identityLogFields, field)
+}
+
+// accessLogFields returns the configured fields of the access log or DefaultAccessLogFields.
+func accessLogFields(cfg *Config) []string {
+	if cfg == nil || cfg.Logging == nil || cfg.Logging.AccessLogFields == nil {
+		return DefaultAccessLogFields
+	}
+	return cfg.Logging.AccessLogFields
+}
+
+// accessLogConfig returns the config of the access log middleware logging the configured fields.
+func (s *Server) accessLogConfig() fiberzap.Config {
+	var fields []string
+	var actor, roles bool
+	for _, field := range accessLogFields(s.Config) {
+		switch field {
+		case "actor":
+			actor = true
+		case "roles":
+			roles = true
+		default:
+			fields = append(fields, field)
+		}
+	}
+
+	return fiberzap.Config{
+		Logger: s.Logger,
+		// An empty slice, so fiberzap does not apply its defaults
+		Fields: append([]string{}, fields...),
+		FieldsFunc: func(c *fiber.Ctx) []zap.Field {
+			id := auth.RequestIdentity(c)
+			var f []zap.Field
+			if actor {
+				f = append(f, zap.String("actor", id.Subject))
+			}
+			if roles {
+				f = append(f, zap.Strings("roles", id.Roles))
+			}
+			return f
+		},
+	}
+}
@@ -4,17 +4,58 @@
 package server
 
 import (
+	"errors"
 	"fmt"
+	"io/fs"
+	"net"
+	"net/url"
 	"os"
 	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
 
+	"github.com/schumann-it/dehydrated-api-go/internal/idempotency"
 	"github.com/schumann-it/dehydrated-api-go/internal/plugin/config"
+	"github.com/schumann-it/dehydrated-api-go/internal/service"
 
 	"github.com/schumann-it/dehydrated-api-go/internal/auth"
+	"github.com/schumann-it/dehydrated-api-go/internal/dehydrated"
 	"github.com/schumann-it/dehydrated-api-go/internal/logger"
+	"github.com/schumann-it/dehydrated-api-go/internal/model"
+	"github.com/schumann-it/dehydrated-api-go/internal/util"
 	"gopkg.in/yaml.v3"
 )
 
+var (
+	// ErrConfigNotFound is returned by CheckConfigFile if the configuration file does not exist.
+	ErrConfigNotFound = errors.New("config file not found")
+
+	// ErrConfigEmpty is returned by CheckConfigFile if the configuration file contains no settings.
+	ErrConfigEmpty = errors.New("config file is empty")
+)
+
+// CheckConfigFile returns ErrConfigNotFound if the configuration file at path does not exist and
+// ErrConfigEmpty if it contains no settings, e.g., only comments. In both cases, Load applies the defaults.
+func CheckConfigFile(path string) error {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return fmt.Errorf("%w: %s", ErrConfigNotFound, path)
+	}
+	if err != nil {
+		return err
+	}
+
+	// Invalid content is reported by Load
+	var settings map[string]any
+	if err := yaml.Unmarshal(data, &settings); err == nil && len(settings) == 0 {
+		return fmt.Errorf("%w: %s", ErrConfigEmpty, path)
+	}
+
+	return nil
+}
+
 // Config holds the application configuration for the dehydrated-api-go server.
 // It includes settings for the HTTP server, plugin management, dehydrated client,
 // and logging configuration.
@@ -22,6 +63,27 @@ type Config struct {
 	// Server configuration
 	Port int `yaml:"port"` // Port number for the HTTP server (1-65535)
 
+	// ReadTimeout is the maximum duration for reading the entire request, including the body.
+	ReadTimeout time.Duration `yaml:"readTimeout"`
+
+	// WriteTimeout is the maximum duration before timing out writes of the response.
+	WriteTimeout time.Duration `yaml:"writeTimeout"`
+
+	// IdleTimeout is the maximum amount of time to wait for the next request when keep-alive is enabled.
+	IdleTimeout time.Duration `yaml:"idleTimeout"`
+
+	// PublicBaseURL is the URL clients reach the server at behind a reverse proxy (e.g., "https://acme.example.com"),
+	// used for pagination links instead of the URL of the request. Empty uses the URL of the request.
+	PublicBaseURL string `yaml:"publicBaseURL"`
+
+	// TrustedProxies are the IP addresses or CIDR ranges of reverse proxies whose X-Forwarded-Proto and
+	// X-Forwarded-Host headers are honored for the URL of the request. If empty, the headers of all clients are honored.
+	TrustedProxies []string `yaml:"trustedProxies"`
+
+	// AppRoot is the directory relative paths in the configuration are resolved against.
+	// A relative AppRoot is resolved against the directory of the configuration file, which is also the default.
+	AppRoot string `yaml:"appRoot"`
+
 	// Dehydrated configuration
 	DehydratedBaseDir string `yaml:"dehydratedBaseDir"` // Base directory for dehydrated client files
 
@@ -30,10 +92,146 @@ type Config struct {
 	// dehydrated client-specific settings.
 	DehydratedConfigFile string `yaml:"dehydratedConfigFile"`
 
+	// ValidateDehydratedConfig enables validation of the dehydrated configuration
+	// (e.g., KEY_ALGO/KEY_SIZE compatibility). Warnings are logged and returned by the config endpoint.
+	ValidateDehydratedConfig bool `yaml:"validateDehydratedConfig"`
+
 	// EnableWatcher determines whether the file watcher is active.
 	// When enabled, the server monitors for changes in the dehydrated configuration.
 	EnableWatcher bool `yaml:"enableWatcher"`
 
+	// WatcherGracePeriod is the time after a change via the API during which events of the file watcher are
+	// ignored, so a late event of the server's own write does not reload the file. Disabled if zero.
+	WatcherGracePeriod time.Duration `yaml:"watcherGracePeriod"`
+
+	// WatchConfig determines whether the server configuration file is watched.
+	// When enabled, hot-reloadable settings (log level, plugins) are applied on change,
+	// all other changes are logged as requiring a restart.
+	WatchConfig bool `yaml:"watchConfig"`
+
+	// MinFreeDiskSpaceMB is the minimum free disk space in megabytes required on the
+	// filesystem holding domains.txt. The readiness check fails below this threshold.
+	// A pointer, so an explicit 0 disables the check instead of keeping the default.
+	MinFreeDiskSpaceMB *uint64 `yaml:"minFreeDiskSpaceMB"`
+
+	// ResponseFormat is the default format of successful API responses: "enveloped" wraps data in
+	// a {success, data} envelope, "bare" returns the data directly. Clients can override it per
+	// request with the envelope parameter of the Accept header. Errors are always enveloped.
+	ResponseFormat string `yaml:"responseFormat"`
+
+	// MetadataFormat is the default format of the metadata of domain entries: "nested" groups it by
+	// plugin, "flat" returns a single-level map with dot-joined keys (e.g., "netbox.site").
+	// Clients can override it per request with the metadata query parameter.
+	MetadataFormat string `yaml:"metadataFormat"`
+
+	// FieldNaming is the default naming of the JSON fields of API requests and responses: "snake_case"
+	// (e.g., "alternative_names") or "camelCase" (e.g., "alternativeNames"). Clients can override it per
+	// request with the naming parameter of the Accept header.
+	FieldNaming string `yaml:"fieldNaming"`
+
+	// CommentMarker is prepended to the comment of entries created via the API (e.g., "[api]"),
+	// to distinguish them from manually added ones. Disabled if empty.
+	CommentMarker string `yaml:"commentMarker"`
+
+	// CommentTemplate is the comment of entries created via the API without one, with the placeholders {date},
+	// {actor} and {domain} replaced, e.g., "created {date} by {actor} via api".
+	CommentTemplate string `yaml:"commentTemplate"`
+
+	// DefaultEnabled is the enabled state of entries created via the API if the request omits it.
+	DefaultEnabled bool `yaml:"defaultEnabled"`
+
+	// EnableOCSPRefresh enables the endpoint refreshing the OCSP response of a domain by running DehydratedScript.
+	EnableOCSPRefresh bool `yaml:"enableOcspRefresh"`
+
+	// DehydratedScript is the path of the dehydrated script run for OCSP refreshes.
+	// Relative paths containing a directory are resolved against the directory of the config file;
+	// a plain name is looked up in PATH.
+	DehydratedScript string `yaml:"dehydratedScript"`
+
+	// SidecarMetadata enables reading static metadata from CertDir/<alias or domain>/metadata.json
+	// into the "sidecar" key of the metadata of an entry.
+	SidecarMetadata bool `yaml:"sidecarMetadata"`
+
+	// StrictPlugins makes GET requests for domains fail with 502 Bad Gateway if any plugin fails,
+	// instead of embedding the error in the metadata. Clients can override it with ?strict=.
+	StrictPlugins bool `yaml:"strictPlugins"`
+
+	// SkipDisabledMetadata skips the metadata enrichment by plugins for disabled entries of GET requests for domains.
+	// Clients can override it with ?enrich_disabled=.
+	SkipDisabledMetadata bool `yaml:"skipDisabledMetadata"`
+
+	// RequestTimeBudget is the time budget of listing domains. Once exceeded, no more plugins are called and the
+	// entries are returned with partial metadata. Clients can shorten it with the X-Timeout header. Disabled if zero.
+	RequestTimeBudget time.Duration `yaml:"requestTimeBudget"`
+
+	// RejectUnknownFields rejects JSON request bodies creating, previewing, updating, renaming or deleting domain
+	// entries with fields the request does not have, e.g., a misspelled "domian", instead of ignoring them.
+	RejectUnknownFields bool `yaml:"rejectUnknownFields"`
+
+	// MergeMetadata merges the metadata of all plugins into a single map instead of namespacing it by
+	// plugin name. If plugins set the same key, the plugin with the highest priority wins.
+	MergeMetadata bool `yaml:"mergeMetadata"`
+
+	// LargeIntegerMetadata renders integers beyond ±2^53 in the metadata of plugins as JSON numbers instead of
+	// strings. They are exact, but clients parsing JSON numbers as doubles, e.g., JavaScript, round them.
+	LargeIntegerMetadata bool `yaml:"largeIntegerMetadata"`
+
+	// OmitTrailingNewline omits the newline after the last line of domains.txt.
+	OmitTrailingNewline bool `yaml:"omitTrailingNewline"`
+
+	// SortAlternativeNames sorts the alternative names of entries alphabetically instead of preserving
+	// the order they were given in.
+	SortAlternativeNames bool `yaml:"sortAlternativeNames"`
+
+	// AllowedChallengeTypes restricts the CHALLENGETYPE of the dehydrated configuration and the overrides
+	// in the config files of certificates (e.g., ["dns-01"]). All challenge types are allowed if empty.
+	AllowedChallengeTypes []string `yaml:"allowedChallengeTypes"`
+
+	// AllowedDomainSuffixes restricts the domain names of entries created or changed via the API to the
+	// given zones (e.g., ["example.com"]); a leading '.' matches subdomains only. All are allowed if empty.
+	AllowedDomainSuffixes []string `yaml:"allowedDomainSuffixes"`
+
+	// DeniedDomainSuffixes rejects domain names of entries created or changed via the API in the given
+	// zones, even if allowed by AllowedDomainSuffixes.
+	DeniedDomainSuffixes []string `yaml:"deniedDomainSuffixes"`
+
+	// CAProfiles maps the names of the CA profiles entries may select to CA settings of dehydrated
+	// (e.g., {"staging": "letsencrypt-test"}). Entries cannot select a CA if empty.
+	CAProfiles map[string]string `yaml:"caProfiles"`
+
+	// DomainsFilePermissions configures the mode and ownership of domains.txt and its directory.
+	// Unchanged if nil.
+	DomainsFilePermissions *FilePermissionsConfig `yaml:"domainsFilePermissions"`
+
+	// MaxCommentLength is the maximum length of comments set via the API in characters (default 256).
+	MaxCommentLength int `yaml:"maxCommentLength"`
+
+	// MaxAliasLength is the maximum length of aliases set via the API in characters (default 64).
+	MaxAliasLength int `yaml:"maxAliasLength"`
+
+	// PluginErrorLimit is the number of recent plugin errors retained for the plugin errors report (default 1000).
+	PluginErrorLimit int `yaml:"pluginErrorLimit"`
+
+	// WarnEntries is the number of domain entries above which a warning is logged (default 10000, -1 disables it).
+	WarnEntries int `yaml:"warnEntries"`
+
+	// MaxEntries is the number of domain entries beyond which creates are rejected (default 100000, -1 disables it).
+	MaxEntries int `yaml:"maxEntries"`
+
+	// RequireNonEmptyDomains makes startup and the readiness check fail if domains.txt has no entries,
+	// for deployments that always expect at least one domain.
+	RequireNonEmptyDomains bool `yaml:"requireNonEmptyDomains"`
+
+	// WriteCoalescing enables coalescing of domains file writes. Disabled if nil.
+	WriteCoalescing *WriteCoalescingConfig `yaml:"writeCoalescing"`
+
+	// IntegrityCheck enables the periodic integrity check of domains.txt. Disabled if nil.
+	IntegrityCheck *IntegrityCheckConfig `yaml:"integrityCheck"`
+
+	// Idempotency enables replaying the responses of mutations retried with the same Idempotency-Key.
+	// Disabled if nil.
+	Idempotency *idempotency.Config `yaml:"idempotency"`
+
 	// Logging configuration
 	Logging *logger.Config `yaml:"logging"` // Configuration for the application logger
 
@@ -46,19 +244,103 @@ type Config struct {
 	parsedConfig *Config
 }
 
+// WriteCoalescingConfig configures the coalescing of domains file writes. Mutations update the
+// in-memory state and return, a background flusher writes the domains file at most once per Interval.
+type WriteCoalescingConfig struct {
+	// Interval is the maximum time a change waits before it is written (default 100ms).
+	Interval time.Duration `yaml:"interval"`
+
+	// MaxPending is the number of pending changes that triggers an immediate write (default 100).
+	MaxPending int `yaml:"maxPending"`
+
+	// Durable makes mutations wait until their change has been written.
+	Durable bool `yaml:"durable"`
+}
+
+// IntegrityCheckConfig configures the periodic integrity check of domains.txt, which compares the
+// parsed file with the cache and exposes the result via /metrics.
+type IntegrityCheckConfig struct {
+	// Interval is the time between checks (default 5m).
+	Interval time.Duration `yaml:"interval"`
+
+	// Reload reloads the cache from domains.txt if it does not match.
+	Reload bool `yaml:"reload"`
+}
+
+// FilePermissionsConfig configures the mode and ownership applied to domains.txt and its directory
+// on every write. Empty fields keep the current value.
+type FilePermissionsConfig struct {
+	// Mode is the octal mode of domains.txt (e.g., "0640").
+	Mode string `yaml:"mode"`
+
+	// DirMode is the octal mode of the directory of domains.txt (e.g., "0750").
+	DirMode string `yaml:"dirMode"`
+
+	// User is the name or numeric id of the owner.
+	User string `yaml:"user"`
+
+	// Group is the name or numeric id of the group (e.g., "www-data").
+	Group string `yaml:"group"`
+}
+
+// FilePermissions returns the service file permissions of the configuration.
+func (p *FilePermissionsConfig) FilePermissions() (service.FilePermissions, error) {
+	fileMode, err := parseMode(p.Mode)
+	if err != nil {
+		return service.FilePermissions{}, fmt.Errorf("invalid mode: %w", err)
+	}
+	dirMode, err := parseMode(p.DirMode)
+	if err != nil {
+		return service.FilePermissions{}, fmt.Errorf("invalid dirMode: %w", err)
+	}
+
+	return service.FilePermissions{
+		FileMode: fileMode,
+		DirMode:  dirMode,
+		User:     p.User,
+		Group:    p.Group,
+	}, nil
+}
+
+// parseMode parses an octal permission mode, an empty mode is zero.
+func parseMode(mode string) (os.FileMode, error) {
+	if mode == "" {
+		return 0, nil
+	}
+	m, err := strconv.ParseUint(strings.TrimPrefix(mode, "0o"), 8, 32)
+	if err != nil || m > 0o777 {
+		return 0, fmt.Errorf("%s is not an octal permission mode", mode)
+	}
+	return os.FileMode(m), nil
+}
+
 // NewConfig creates a new Config instance with default values.
 // The default configuration includes:
 // - Port: 3000
+// - ReadTimeout: 30s
+// - WriteTimeout: 30s
+// - IdleTimeout: 120s
 // - DehydratedBaseDir: "."
 // - DehydratedConfigFile: "config"
 // - EnableWatcher: false
+// - MinFreeDiskSpaceMB: 10
+// - ResponseFormat: "enveloped"
+// - MetadataFormat: "nested"
+// - FieldNaming: "snake_case"
 // - Logging: default logger configuration
 func NewConfig() *Config {
 	return &Config{
 		Port:                 3000,
+		ReadTimeout:          30 * time.Second,
+		WriteTimeout:         30 * time.Second,
+		IdleTimeout:          120 * time.Second,
 		DehydratedBaseDir:    ".",
 		DehydratedConfigFile: "config",
 		EnableWatcher:        false,
+		MinFreeDiskSpaceMB:   util.Uint64Ptr(10),
+		ResponseFormat:       model.ResponseFormatEnveloped,
+		MetadataFormat:       model.MetadataFormatNested,
+		FieldNaming:          model.FieldNamingSnakeCase,
 	}
 }
 
@@ -102,19 +384,130 @@ func (c *Config) Load(path string) *Config {
 		return c
 	}
 
-	// Only assign loaded values if the config file exists
-	if _, err := os.Stat(absConfigPath); err == nil {
+	// Empty and missing config files keep the default port
+	if fc.Port != 0 {
 		c.Port = fc.Port
 	}
+	if fc.ReadTimeout > 0 {
+		c.ReadTimeout = fc.ReadTimeout
+	}
+	if fc.WriteTimeout > 0 {
+		c.WriteTimeout = fc.WriteTimeout
+	}
+	if fc.IdleTimeout > 0 {
+		c.IdleTimeout = fc.IdleTimeout
+	}
+	if fc.PublicBaseURL != "" {
+		c.PublicBaseURL = fc.PublicBaseURL
+	}
+	if len(fc.TrustedProxies) > 0 {
+		c.TrustedProxies = fc.TrustedProxies
+	}
+	if fc.AppRoot != "" {
+		c.AppRoot = fc.AppRoot
+	}
 	if fc.DehydratedBaseDir != "" {
 		c.DehydratedBaseDir = fc.DehydratedBaseDir
 	}
 	if fc.DehydratedConfigFile != "" {
 		c.DehydratedConfigFile = fc.DehydratedConfigFile
 	}
+	if fc.ValidateDehydratedConfig {
+		c.ValidateDehydratedConfig = true
+	}
 	if fc.EnableWatcher {
 		c.EnableWatcher = true
 	}
+	if fc.WatcherGracePeriod > 0 {
+		c.WatcherGracePeriod = fc.WatcherGracePeriod
+	}
+	if fc.WatchConfig {
+		c.WatchConfig = true
+	}
+	if fc.MinFreeDiskSpaceMB != nil {
+		c.MinFreeDiskSpaceMB = fc.MinFreeDiskSpaceMB
+	}
+	if fc.ResponseFormat != "" {
+		c.ResponseFormat = fc.ResponseFormat
+	}
+	if fc.MetadataFormat != "" {
+		c.MetadataFormat = fc.MetadataFormat
+	}
+	if fc.FieldNaming != "" {
+		c.FieldNaming = fc.FieldNaming
+	}
+	if fc.CommentMarker != "" {
+		c.CommentMarker = fc.CommentMarker
+	}
+	if fc.CommentTemplate != "" {
+		c.CommentTemplate = fc.CommentTemplate
+	}
+	if fc.DefaultEnabled {
+		c.DefaultEnabled = true
+	}
+	if fc.EnableOCSPRefresh {
+		c.EnableOCSPRefresh = true
+	}
+	if fc.DehydratedScript != "" {
+		c.DehydratedScript = fc.DehydratedScript
+	}
+	if fc.SidecarMetadata {
+		c.SidecarMetadata = true
+	}
+	if fc.StrictPlugins {
+		c.StrictPlugins = true
+	}
+	if fc.SkipDisabledMetadata {
+		c.SkipDisabledMetadata = true
+	}
+	if fc.RequestTimeBudget > 0 {
+		c.RequestTimeBudget = fc.RequestTimeBudget
+	}
+	if fc.RejectUnknownFields {
+		c.RejectUnknownFields = true
+	}
+	if fc.MergeMetadata {
+		c.MergeMetadata = true
+	}
+	if fc.LargeIntegerMetadata {
+		c.LargeIntegerMetadata = true
+	}
+	if fc.OmitTrailingNewline {
+		c.OmitTrailingNewline = true
+	}
+	if fc.SortAlternativeNames {
+		c.SortAlternativeNames = true
+	}
+	if len(fc.AllowedChallengeTypes) > 0 {
+		c.AllowedChallengeTypes = fc.AllowedChallengeTypes
+	}
+	if len(fc.AllowedDomainSuffixes) > 0 {
+		c.AllowedDomainSuffixes = fc.AllowedDomainSuffixes
+	}
+	if len(fc.DeniedDomainSuffixes) > 0 {
+		c.DeniedDomainSuffixes = fc.DeniedDomainSuffixes
+	}
+	if len(fc.CAProfiles) > 0 {
+		c.CAProfiles = fc.CAProfiles
+	}
+	if fc.MaxCommentLength > 0 {
+		c.MaxCommentLength = fc.MaxCommentLength
+	}
+	if fc.MaxAliasLength > 0 {
+		c.MaxAliasLength = fc.MaxAliasLength
+	}
+	if fc.PluginErrorLimit > 0 {
+		c.PluginErrorLimit = fc.PluginErrorLimit
+	}
+	if fc.WarnEntries != 0 {
+		c.WarnEntries = fc.WarnEntries
+	}
+	if fc.MaxEntries != 0 {
+		c.MaxEntries = fc.MaxEntries
+	}
+	if fc.RequireNonEmptyDomains {
+		c.RequireNonEmptyDomains = true
+	}
 
 	// Merge logging configuration
 	if fc.Logging != nil {
@@ -130,6 +523,27 @@ func (c *Config) Load(path string) *Config {
 		if fc.Logging.OutputPath != "" {
 			c.Logging.OutputPath = fc.Logging.OutputPath
 		}
+		if fc.Logging.AccessLogFields != nil {
+			c.Logging.AccessLogFields = fc.Logging.AccessLogFields
+		}
+	}
+
+	// Merge domains file permissions
+	if fc.DomainsFilePermissions != nil {
+		c.DomainsFilePermissions = fc.DomainsFilePermissions
+	}
+
+	// Merge write coalescing configuration
+	if fc.WriteCoalescing != nil {
+		c.WriteCoalescing = fc.WriteCoalescing
+	}
+	if fc.IntegrityCheck != nil {
+		c.IntegrityCheck = fc.IntegrityCheck
+	}
+
+	// Merge idempotency configuration
+	if fc.Idempotency != nil {
+		c.Idempotency = fc.Idempotency
 	}
 
 	// Merge auth configuration
@@ -142,21 +556,59 @@ func (c *Config) Load(path string) *Config {
 		c.Plugins = fc.Plugins
 	}
 
+	c.resolvePaths(filepath.Dir(absConfigPath))
+
+	return c
+}
+
+// resolvePaths makes the relative paths of the configuration absolute, independent of the working directory.
+// The app root is resolved against configDir, all other paths are resolved against the app root,
+// except for the dehydrated config file, which is resolved against the dehydrated base directory.
+func (c *Config) resolvePaths(configDir string) {
+	switch {
+	case c.AppRoot == "":
+		c.AppRoot = configDir
+	case !filepath.IsAbs(c.AppRoot):
+		c.AppRoot = filepath.Join(configDir, c.AppRoot)
+	}
+
 	if !filepath.IsAbs(c.DehydratedBaseDir) {
-		c.DehydratedBaseDir = filepath.Join(filepath.Dir(absConfigPath), c.DehydratedBaseDir)
+		c.DehydratedBaseDir = filepath.Join(c.AppRoot, c.DehydratedBaseDir)
 	}
 
 	if !filepath.IsAbs(c.DehydratedConfigFile) {
 		c.DehydratedConfigFile = filepath.Join(c.DehydratedBaseDir, c.DehydratedConfigFile)
 	}
 
-	return c
+	if strings.ContainsRune(c.DehydratedScript, filepath.Separator) && !filepath.IsAbs(c.DehydratedScript) {
+		c.DehydratedScript = filepath.Join(c.AppRoot, c.DehydratedScript)
+	}
+
+	if c.Logging != nil && c.Logging.OutputPath != "" && !filepath.IsAbs(c.Logging.OutputPath) {
+		logging := *c.Logging
+		logging.OutputPath = filepath.Join(c.AppRoot, logging.OutputPath)
+		c.Logging = &logging
+	}
+
+	if c.Plugins != nil {
+		plugins := make(map[string]config.PluginConfig, len(c.Plugins))
+		for name, p := range c.Plugins {
+			plugins[name] = p.ResolvePaths(c.AppRoot)
+		}
+		c.Plugins = plugins
+	}
 }
 
 // Validate checks if the configuration is valid and returns an error if any issues are found.
 // It validates:
 // - Port number (must be between 1 and 65535)
+// - App root (must exist, if set)
 // - Dehydrated base directory (must exist)
+// - Response format (must be enveloped or bare)
+// - Comment marker (must not contain '#')
+// - CA profiles (names and CAs must not be empty, CAs must not contain quotes, backticks, '$', '\' or newlines)
+// - Write coalescing (interval and max pending must not be negative)
+// - Domains file permissions (modes must be octal permissions)
 // - Plugin configurations (paths must exist and be absolute)
 func (c *Config) Validate() error {
 	// Validate port
@@ -164,11 +616,110 @@ func (c *Config) Validate() error {
 		return fmt.Errorf("invalid port number: %d", c.Port)
 	}
 
+	// Validate app root
+	if _, err := os.Stat(c.AppRoot); c.AppRoot != "" && os.IsNotExist(err) {
+		return fmt.Errorf("app root does not exist: %s", c.AppRoot)
+	}
+
 	// Validate dehydrated base dir
 	if _, err := os.Stat(c.DehydratedBaseDir); os.IsNotExist(err) {
 		return fmt.Errorf("dehydrated base dir does not exist: %s", c.DehydratedBaseDir)
 	}
 
+	// Validate the public base URL, it replaces the scheme and host of the request
+	if c.PublicBaseURL != "" {
+		u, err := url.Parse(c.PublicBaseURL)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" || u.RawQuery != "" || u.Fragment != "" {
+			return fmt.Errorf("invalid public base URL: %s", c.PublicBaseURL)
+		}
+	}
+
+	// Validate access log fields
+	for _, field := range accessLogFields(c) {
+		if !isAccessLogField(field) {
+			return fmt.Errorf("invalid access log field: %s", field)
+		}
+	}
+
+	// Validate trusted proxies
+	for _, proxy := range c.TrustedProxies {
+		if _, _, err := net.ParseCIDR(proxy); err != nil && net.ParseIP(proxy) == nil {
+			return fmt.Errorf("invalid trusted proxy: %s", proxy)
+		}
+	}
+
+	// Validate response format
+	if c.ResponseFormat != "" && !model.IsValidResponseFormat(c.ResponseFormat) {
+		return fmt.Errorf("invalid response format: %s", c.ResponseFormat)
+	}
+	if c.MetadataFormat != "" && !model.IsValidMetadataFormat(c.MetadataFormat) {
+		return fmt.Errorf("invalid metadata format: %s", c.MetadataFormat)
+	}
+	if c.FieldNaming != "" && !model.IsValidFieldNaming(c.FieldNaming) {
+		return fmt.Errorf("invalid field naming: %s, use %s or %s", c.FieldNaming, model.FieldNamingSnakeCase, model.FieldNamingCamelCase)
+	}
+
+	// Validate comment marker, a '#' would be read back as part of the comment separator
+	if strings.Contains(c.CommentMarker, "#") {
+		return fmt.Errorf("invalid comment marker: %s", c.CommentMarker)
+	}
+	if err := model.ValidateCommentTemplate(c.CommentTemplate); err != nil {
+		return fmt.Errorf("invalid comment template: %w", err)
+	}
+
+	// Validate allowed challenge types
+	for _, t := range c.AllowedChallengeTypes {
+		if !slices.Contains(dehydrated.ChallengeTypes, t) {
+			return fmt.Errorf("invalid allowed challenge type: %s, use one of %v", t, dehydrated.ChallengeTypes)
+		}
+	}
+
+	// Validate domain suffixes
+	for _, suffix := range slices.Concat(c.AllowedDomainSuffixes, c.DeniedDomainSuffixes) {
+		if !model.IsValidDomainSuffix(suffix) {
+			return fmt.Errorf("invalid domain suffix: %q", suffix)
+		}
+	}
+
+	// Validate CA profiles, the CA is written quoted to the shell config of the certificate
+	for name, ca := range c.CAProfiles {
+		if name == "" || ca == "" || strings.ContainsAny(ca, "\"'`$\\\n") {
+			return fmt.Errorf("invalid CA profile: %q: %q", name, ca)
+		}
+	}
+
+	// Validate entry limits, the warning threshold has to be below the limit
+	if c.WarnEntries > 0 && c.MaxEntries > 0 && c.WarnEntries > c.MaxEntries {
+		return fmt.Errorf("invalid entry limits: warnEntries %d exceeds maxEntries %d", c.WarnEntries, c.MaxEntries)
+	}
+
+	// Validate domains file permissions
+	if c.DomainsFilePermissions != nil {
+		if _, err := c.DomainsFilePermissions.FilePermissions(); err != nil {
+			return fmt.Errorf("invalid domains file permissions: %w", err)
+		}
+	}
+
+	// Validate the watcher grace period
+	if c.WatcherGracePeriod < 0 {
+		return fmt.Errorf("invalid watcher grace period: %s", c.WatcherGracePeriod)
+	}
+
+	// Validate write coalescing, zero values select the defaults
+	if wc := c.WriteCoalescing; wc != nil && (wc.Interval < 0 || wc.MaxPending < 0) {
+		return fmt.Errorf("invalid write coalescing: interval %s, max pending %d", wc.Interval, wc.MaxPending)
+	}
+
+	// Validate the integrity check, a zero interval selects the default
+	if ic := c.IntegrityCheck; ic != nil && ic.Interval < 0 {
+		return fmt.Errorf("invalid integrity check interval: %s", ic.Interval)
+	}
+
+	// Validate idempotency, zero values select the defaults
+	if ic := c.Idempotency; ic != nil && (ic.TTL < 0 || ic.MaxKeys < 0) {
+		return fmt.Errorf("invalid idempotency: ttl %s, max keys %d", ic.TTL, ic.MaxKeys)
+	}
+
 	return nil
 }
 
@@ -186,3 +737,18 @@ func (c *Config) String() string {
 
 	return string(b)
 }
+
+// Values returns the settings of the Config by their YAML keys, as listed by String.
+func (c *Config) Values() (map[string]any, error) {
+	b, err := yaml.Marshal(c)
+	if err != nil {
+		return nil, err
+	}
+
+	values := make(map[string]any)
+	if err := yaml.Unmarshal(b, &values); err != nil {
+		return nil, err
+	}
+
+	return values, nil
+}
@@ -5,8 +5,11 @@ import (
 	"os"
 	"path/filepath"
 	"testing"
+	"time"
 
+	"github.com/schumann-it/dehydrated-api-go/internal/idempotency"
 	"github.com/schumann-it/dehydrated-api-go/internal/logger"
+	"github.com/schumann-it/dehydrated-api-go/internal/util"
 	"github.com/stretchr/testify/require"
 	"gopkg.in/yaml.v3"
 )
@@ -122,6 +125,198 @@ func TestConfigValidation(t *testing.T) {
 			wantErr:     true,
 			errContains: "dehydrated base dir does not exist",
 		},
+		{
+			name: "invalid comment marker",
+			setupConfig: func() *Config {
+				return &Config{
+					Port:              3000,
+					DehydratedBaseDir: ".",
+					CommentMarker:     "#api",
+				}
+			},
+			wantErr:     true,
+			errContains: "invalid comment marker",
+		},
+		{
+			name: "unknown comment template placeholder",
+			setupConfig: func() *Config {
+				return &Config{
+					Port:              3000,
+					DehydratedBaseDir: ".",
+					CommentTemplate:   "created {date} by {user}",
+				}
+			},
+			wantErr:     true,
+			errContains: "invalid comment template: unknown placeholder {user}",
+		},
+		{
+			name: "invalid public base URL",
+			setupConfig: func() *Config {
+				return &Config{
+					Port:              3000,
+					DehydratedBaseDir: ".",
+					PublicBaseURL:     "acme.example.com",
+				}
+			},
+			wantErr:     true,
+			errContains: "invalid public base URL",
+		},
+		{
+			name: "invalid trusted proxy",
+			setupConfig: func() *Config {
+				return &Config{
+					Port:              3000,
+					DehydratedBaseDir: ".",
+					TrustedProxies:    []string{"10.0.0.0/8", "proxy.internal"},
+				}
+			},
+			wantErr:     true,
+			errContains: "invalid trusted proxy",
+		},
+		{
+			name: "invalid watcher grace period",
+			setupConfig: func() *Config {
+				return &Config{
+					Port:               3000,
+					DehydratedBaseDir:  ".",
+					WatcherGracePeriod: -time.Second,
+				}
+			},
+			wantErr:     true,
+			errContains: "invalid watcher grace period",
+		},
+		{
+			name: "invalid access log field",
+			setupConfig: func() *Config {
+				return &Config{
+					Port:              3000,
+					DehydratedBaseDir: ".",
+					Logging:           &logger.Config{AccessLogFields: []string{"status", "subject"}},
+				}
+			},
+			wantErr:     true,
+			errContains: "invalid access log field: subject",
+		},
+		{
+			name: "invalid write coalescing",
+			setupConfig: func() *Config {
+				return &Config{
+					Port:              3000,
+					DehydratedBaseDir: ".",
+					WriteCoalescing:   &WriteCoalescingConfig{Interval: -time.Second},
+				}
+			},
+			wantErr:     true,
+			errContains: "invalid write coalescing",
+		},
+		{
+			name: "invalid allowed challenge type",
+			setupConfig: func() *Config {
+				return &Config{
+					Port:                  3000,
+					DehydratedBaseDir:     ".",
+					AllowedChallengeTypes: []string{"dns-01", "dns"},
+				}
+			},
+			wantErr:     true,
+			errContains: "invalid allowed challenge type: dns",
+		},
+		{
+			name: "warning threshold above entry limit",
+			setupConfig: func() *Config {
+				return &Config{
+					Port:              3000,
+					DehydratedBaseDir: ".",
+					WarnEntries:       200,
+					MaxEntries:        100,
+				}
+			},
+			wantErr:     true,
+			errContains: "invalid entry limits",
+		},
+		{
+			name: "invalid domains file mode",
+			setupConfig: func() *Config {
+				return &Config{
+					Port:                   3000,
+					DehydratedBaseDir:      ".",
+					DomainsFilePermissions: &FilePermissionsConfig{Mode: "0648"},
+				}
+			},
+			wantErr:     true,
+			errContains: "invalid domains file permissions",
+		},
+		{
+			name: "invalid idempotency",
+			setupConfig: func() *Config {
+				return &Config{
+					Port:              3000,
+					DehydratedBaseDir: ".",
+					Idempotency:       &idempotency.Config{TTL: -time.Hour},
+				}
+			},
+			wantErr:     true,
+			errContains: "invalid idempotency",
+		},
+		{
+			name: "invalid metadata format",
+			setupConfig: func() *Config {
+				return &Config{
+					Port:              3000,
+					DehydratedBaseDir: ".",
+					MetadataFormat:    "tree",
+				}
+			},
+			wantErr:     true,
+			errContains: "invalid metadata format: tree",
+		},
+		{
+			name: "invalid field naming",
+			setupConfig: func() *Config {
+				return &Config{
+					Port:              3000,
+					DehydratedBaseDir: ".",
+					FieldNaming:       "kebab-case",
+				}
+			},
+			wantErr:     true,
+			errContains: "invalid field naming: kebab-case",
+		},
+		{
+			name: "invalid domain suffix",
+			setupConfig: func() *Config {
+				return &Config{
+					Port:                  3000,
+					DehydratedBaseDir:     ".",
+					AllowedDomainSuffixes: []string{"example.com"},
+					DeniedDomainSuffixes:  []string{"*.internal.example.com"},
+				}
+			},
+			wantErr:     true,
+			errContains: "invalid domain suffix",
+		},
+		{
+			name: "invalid CA profile",
+			setupConfig: func() *Config {
+				return &Config{
+					Port:              3000,
+					DehydratedBaseDir: ".",
+					CAProfiles:        map[string]string{"staging": "letsencrypt-test\"; rm"},
+				}
+			},
+			wantErr:     true,
+			errContains: "invalid CA profile",
+		},
+		{
+			name: "valid domains file permissions",
+			setupConfig: func() *Config {
+				return &Config{
+					Port:                   3000,
+					DehydratedBaseDir:      ".",
+					DomainsFilePermissions: &FilePermissionsConfig{Mode: "0640", DirMode: "0o750", Group: "www-data"},
+				}
+			},
+		},
 	}
 
 	for _, tt := range tests {
@@ -179,9 +374,22 @@ port: 8080
 `,
 			expectError: false,
 			expectedConfig: &Config{
-				Port:              8080,
-				DehydratedBaseDir: ".",
-				EnableWatcher:     false,
+				Port:               8080,
+				DehydratedBaseDir:  ".",
+				EnableWatcher:      false,
+				MinFreeDiskSpaceMB: util.Uint64Ptr(10),
+			},
+		},
+		{
+			name: "disable disk space check",
+			configContent: `
+minFreeDiskSpaceMB: 0
+`,
+			expectError: false,
+			expectedConfig: &Config{
+				Port:               3000,
+				DehydratedBaseDir:  ".",
+				MinFreeDiskSpaceMB: util.Uint64Ptr(0),
 			},
 		},
 		{
@@ -242,6 +450,9 @@ port: not-a-number
 				require.Equal(t, tt.expectedConfig.Port, cfg.Port)
 				require.Equal(t, tt.expectedConfig.DehydratedBaseDir, cfg.DehydratedBaseDir)
 				require.Equal(t, tt.expectedConfig.EnableWatcher, cfg.EnableWatcher)
+				if tt.expectedConfig.MinFreeDiskSpaceMB != nil {
+					require.Equal(t, tt.expectedConfig.MinFreeDiskSpaceMB, cfg.MinFreeDiskSpaceMB)
+				}
 
 				if tt.expectedConfig.Logging != nil {
 					require.NotNil(t, cfg.Logging)
@@ -253,3 +464,74 @@ port: not-a-number
 		})
 	}
 }
+
+// TestAppRoot verifies that relative paths are resolved against the app root, independent of the working directory.
+func TestAppRoot(t *testing.T) {
+	// Run from a different working directory than the configuration
+	wd, err := os.Getwd()
+	require.NoError(t, err)
+	require.NoError(t, os.Chdir(t.TempDir()))
+	t.Cleanup(func() {
+		require.NoError(t, os.Chdir(wd))
+	})
+
+	configDir := t.TempDir()
+	appRoot := filepath.Join(configDir, "app")
+	require.NoError(t, os.MkdirAll(filepath.Join(appRoot, "dehydrated"), 0755))
+	configPath := filepath.Join(configDir, "config.yaml")
+	configContent := `
+port: 0
+appRoot: app
+dehydratedBaseDir: dehydrated
+dehydratedScript: bin/dehydrated
+logging:
+  level: info
+  outputPath: api.log
+plugins:
+  local:
+    enabled: false
+    registry:
+      type: local
+      config:
+        path: plugins/simple
+  remote:
+    enabled: false
+    address: localhost:9000
+    tls:
+      caFile: certs/ca.pem
+      certFile: /etc/certs/client.pem
+`
+	require.NoError(t, os.WriteFile(configPath, []byte(configContent), 0644))
+
+	cfg := NewConfig().Load(configPath)
+	require.NoError(t, cfg.err)
+	require.NoError(t, cfg.Validate())
+	require.Equal(t, appRoot, cfg.AppRoot)
+	require.Equal(t, filepath.Join(appRoot, "dehydrated"), cfg.DehydratedBaseDir)
+	require.Equal(t, filepath.Join(appRoot, "dehydrated", "config"), cfg.DehydratedConfigFile)
+	require.Equal(t, filepath.Join(appRoot, "bin", "dehydrated"), cfg.DehydratedScript)
+	require.Equal(t, filepath.Join(appRoot, "api.log"), cfg.Logging.OutputPath)
+	require.Equal(t, filepath.Join(appRoot, "plugins", "simple"), cfg.Plugins["local"].Registry.Config["path"])
+	require.Equal(t, filepath.Join(appRoot, "certs", "ca.pem"), cfg.Plugins["remote"].TLS.CAFile)
+	require.Equal(t, "/etc/certs/client.pem", cfg.Plugins["remote"].TLS.CertFile)
+
+	// The parsed configuration is left untouched
+	require.Equal(t, "plugins/simple", cfg.parsedConfig.Plugins["local"].Registry.Config["path"])
+
+	// The server creates its files below the app root
+	s := NewServer().WithConfig(configPath).WithLogger().WithDomainService()
+	defer s.domainService.Close()
+	require.Equal(t, filepath.Join(appRoot, "dehydrated", "domains.txt"), s.domainService.DehydratedConfig.DomainsFile)
+	require.FileExists(t, filepath.Join(appRoot, "dehydrated", "domains.txt"))
+	require.FileExists(t, filepath.Join(appRoot, "api.log"))
+
+	// Without an app root, paths are resolved against the directory of the configuration file
+	require.NoError(t, os.WriteFile(configPath, []byte("port: 8080\ndehydratedBaseDir: app\n"), 0644))
+	cfg = NewConfig().Load(configPath)
+	require.Equal(t, configDir, cfg.AppRoot)
+	require.Equal(t, appRoot, cfg.DehydratedBaseDir)
+
+	// A missing app root is invalid
+	cfg.AppRoot = filepath.Join(configDir, "missing")
+	require.ErrorContains(t, cfg.Validate(), "app root does not exist")
+}
@@ -0,0 +1,131 @@
+package server
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"runtime"
+	"sort"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/schumann-it/dehydrated-api-go/internal/model"
+)
+
+// Formats of the output of PrintInfo.
+const (
+	InfoFormatText = "text"
+	InfoFormatJSON = "json"
+)
+
+// VersionInfo is the version information of the build.
+type VersionInfo = model.VersionInfo
+
+// Info is the information printed by PrintInfo. Both the text and the JSON output are rendered from it.
+// Sections that are not requested are nil.
+type Info struct {
+	// Version is the version information of the build.
+	Version *VersionInfo `json:"version,omitempty"`
+
+	// ServerConfig are the settings of the resolved server config by their YAML keys.
+	ServerConfig map[string]any `json:"server_config,omitempty"`
+
+	// DehydratedConfig are the settings of the resolved dehydrated config by their upper-case names.
+	DehydratedConfig map[string]any `json:"dehydrated_config,omitempty"`
+}
+
+// Info assembles the version information if v is set and the resolved configs if i is set.
+func (s *Server) Info(v, i bool) (*Info, error) {
+	info := &Info{}
+
+	if v {
+		info.Version = s.versionInfo()
+	}
+
+	if i {
+		values, err := s.Config.Values()
+		if err != nil {
+			return nil, err
+		}
+		info.ServerConfig = values
+
+		if s.domainService != nil {
+			info.DehydratedConfig = s.domainService.DehydratedConfig.Values()
+		}
+	}
+
+	return info, nil
+}
+
+// versionInfo returns the version information set by WithVersionInfo and the version of the Go runtime.
+func (s *Server) versionInfo() *VersionInfo {
+	return &VersionInfo{Version: s.Version, Commit: s.Commit, BuildTime: s.BuildTime, GoVersion: runtime.Version()}
+}
+
+// WriteInfo writes the version information if v is set and the resolved configs if i is set to w,
+// formatted as InfoFormatText or InfoFormatJSON.
+func (s *Server) WriteInfo(w io.Writer, v, i bool, format string) error {
+	info, err := s.Info(v, i)
+	if err != nil {
+		return err
+	}
+
+	switch format {
+	case InfoFormatText, "":
+		return info.WriteText(w)
+	case InfoFormatJSON:
+		return info.WriteJSON(w)
+	default:
+		return fmt.Errorf("invalid format: %s, use %s or %s", format, InfoFormatText, InfoFormatJSON)
+	}
+}
+
+// WriteText writes the info to w for humans: the version line, the server config as YAML
+// and the dehydrated config as KEY=value lines.
+func (info *Info) WriteText(w io.Writer) error {
+	if info.Version != nil {
+		if _, err := fmt.Fprintf(w, "dehydrated-api-go version %s (commit: %s, built: %s)\n",
+			info.Version.Version, info.Version.Commit, info.Version.BuildTime); err != nil {
+			return err
+		}
+	}
+
+	if info.ServerConfig != nil {
+		b, err := yaml.Marshal(info.ServerConfig)
+		if err != nil {
+			return err
+		}
+		if _, err := fmt.Fprintf(w, "%sResolved Server Config:%s\n%s\n", bold, reset, b); err != nil {
+			return err
+		}
+	}
+
+	if info.DehydratedConfig != nil {
+		names := make([]string, 0, len(info.DehydratedConfig))
+		for name := range info.DehydratedConfig {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+
+		if _, err := fmt.Fprintf(w, "%sResolved Dehydrated Config:%s\n", bold, reset); err != nil {
+			return err
+		}
+		for _, name := range names {
+			if _, err := fmt.Fprintf(w, "%s=%v\n", name, info.DehydratedConfig[name]); err != nil {
+				return err
+			}
+		}
+		if _, err := fmt.Fprintln(w); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// WriteJSON writes the info to w as a single JSON document for machine consumption.
+func (info *Info) WriteJSON(w io.Writer) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(info)
+}
@@ -0,0 +1,158 @@
+package server
+
+import (
+	"reflect"
+	"slices"
+	"strings"
+
+	pluginregistry "github.com/schumann-it/dehydrated-api-go/internal/plugin/registry"
+	"github.com/schumann-it/dehydrated-api-go/internal/service"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// WithConfigWatcher watches the server configuration file if enabled by the configuration.
+// On change, hot-reloadable settings are applied and all other changes are logged as requiring a restart.
+func (s *Server) WithConfigWatcher() *Server {
+	if s.Config == nil || !s.Config.WatchConfig || s.configPath == "" {
+		return s
+	}
+
+	w, err := service.NewFileWatcher(s.configPath, s.reloadConfig)
+	if err != nil {
+		s.Logger.Error("Failed to set up config watcher", zap.Error(err))
+		return s
+	}
+	w.WithLogger(s.Logger)
+	w.Watch()
+	s.configWatcher = w
+
+	s.Logger.Info("Config watcher is now enabled", zap.String("file", s.configPath))
+
+	return s
+}
+
+// reloadConfig re-reads the server configuration file and applies the changes.
+func (s *Server) reloadConfig() error {
+	cfg := NewConfig().Load(s.configPath)
+	if cfg.err != nil {
+		return cfg.err
+	}
+
+	s.applyConfig(cfg)
+
+	return nil
+}
+
+// hotReloadable are the configuration fields, by their YAML path, that are applied at runtime.
+// Changes to all other fields require a restart.
+var hotReloadable = []string{"logging.level", "plugins"}
+
+// applyConfig applies hot-reloadable settings of cfg to the running server.
+// Settings that cannot be changed at runtime are kept and logged as requiring a restart.
+func (s *Server) applyConfig(cfg *Config) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.applyLogLevel(cfg)
+	s.applyPlugins(cfg)
+
+	for _, field := range restartRequired(s.Config, cfg) {
+		s.Logger.Warn("Configuration change requires a restart to take effect", zap.String("field", field))
+	}
+}
+
+// restartRequired returns the YAML paths of the fields that differ between the configurations and are not
+// hot-reloadable, in the order of the Config struct. Every field is compared, so new fields require a restart
+// unless they are added to hotReloadable.
+func restartRequired(running, cfg *Config) []string {
+	return changedFields("", reflect.ValueOf(running).Elem(), reflect.ValueOf(cfg).Elem())
+}
+
+// changedFields returns the YAML paths, prefixed by prefix, of the exported fields of the structs a and b that
+// differ and are not hot-reloadable. Fields containing hot-reloadable fields are compared field by field.
+func changedFields(prefix string, a, b reflect.Value) []string {
+	var changed []string
+	for i := range a.NumField() {
+		field := a.Type().Field(i)
+		if !field.IsExported() {
+			continue
+		}
+		name, _, _ := strings.Cut(field.Tag.Get("yaml"), ",")
+		if name == "-" {
+			continue
+		}
+		if name == "" {
+			name = field.Name
+		}
+		path := prefix + name
+
+		switch {
+		case slices.Contains(hotReloadable, path):
+		case containsHotReloadable(path):
+			changed = append(changed, changedFields(path+".", indirect(a.Field(i)), indirect(b.Field(i)))...)
+		case !reflect.DeepEqual(a.Field(i).Interface(), b.Field(i).Interface()):
+			changed = append(changed, path)
+		}
+	}
+	return changed
+}
+
+// containsHotReloadable reports whether a hot-reloadable field is nested in the field with the given path.
+func containsHotReloadable(path string) bool {
+	return slices.ContainsFunc(hotReloadable, func(field string) bool {
+		return strings.HasPrefix(field, path+".")
+	})
+}
+
+// indirect returns the struct v points to, or its zero value if v is nil.
+func indirect(v reflect.Value) reflect.Value {
+	if v.Kind() != reflect.Pointer {
+		return v
+	}
+	if v.IsNil() {
+		return reflect.New(v.Type().Elem()).Elem()
+	}
+	return v.Elem()
+}
+
+// applyLogLevel changes the log level of the running logger if it differs from cfg.
+func (s *Server) applyLogLevel(cfg *Config) {
+	if cfg.Logging == nil || cfg.Logging.Level == "" {
+		return
+	}
+
+	level, err := zapcore.ParseLevel(cfg.Logging.Level)
+	if err != nil {
+		s.Logger.Error("Invalid log level in configuration", zap.String("level", cfg.Logging.Level), zap.Error(err))
+		return
+	}
+
+	if s.level == (zap.AtomicLevel{}) || s.level.Level() == level {
+		return
+	}
+
+	s.level.SetLevel(level)
+	if s.Config.Logging != nil {
+		s.Config.Logging.Level = cfg.Logging.Level
+	}
+
+	s.Logger.Info("Log level changed", zap.String("level", level.String()))
+}
+
+// applyPlugins replaces the plugin registry of the domain service if the plugin configuration changed.
+func (s *Server) applyPlugins(cfg *Config) {
+	if reflect.DeepEqual(cfg.Plugins, s.Config.Plugins) {
+		return
+	}
+
+	s.Config.Plugins = cfg.Plugins
+
+	if s.domainService == nil {
+		return
+	}
+
+	s.Logger.Info("Plugin configuration changed, reloading plugins")
+	s.domainService.ReplaceRegistry(pluginregistry.New(s.domainService.DehydratedConfig.BaseDir, cfg.Plugins, s.Logger,
+		pluginregistry.WithConfigProvider(s.pluginConfigProvider)))
+}
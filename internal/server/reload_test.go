@@ -0,0 +1,151 @@
+package server
+
+import (
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/schumann-it/dehydrated-api-go/internal/auth"
+	"github.com/schumann-it/dehydrated-api-go/internal/logger"
+	"github.com/schumann-it/dehydrated-api-go/internal/plugin/config"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+// TestConfigWatcher verifies that changes to the server configuration file are applied at runtime.
+func TestConfigWatcher(t *testing.T) {
+	tmpDir := t.TempDir()
+	configPath := filepath.Join(tmpDir, "config.yaml")
+	configContent := `
+port: 8080
+dehydratedBaseDir: %s
+watchConfig: true
+logging:
+  level: %s
+`
+	err := os.WriteFile(configPath, []byte(fmt.Sprintf(configContent, tmpDir, "info")), 0644)
+	require.NoError(t, err)
+
+	s := NewServer().WithConfig(configPath).WithLogger().WithConfigWatcher()
+	require.NotNil(t, s.configWatcher)
+	defer s.configWatcher.Close()
+
+	require.False(t, s.Logger.Core().Enabled(zapcore.DebugLevel))
+
+	t.Run("LogLevelIsApplied", func(t *testing.T) {
+		err := os.WriteFile(configPath, []byte(fmt.Sprintf(configContent, tmpDir, "debug")), 0644)
+		require.NoError(t, err)
+
+		require.Eventually(t, func() bool {
+			return s.Logger.Core().Enabled(zapcore.DebugLevel)
+		}, 2*time.Second, 10*time.Millisecond)
+	})
+
+	t.Run("RestartRequiredIsNotApplied", func(t *testing.T) {
+		core, logs := observer.New(zapcore.WarnLevel)
+		s.Logger = zap.New(core)
+
+		cfg := NewConfig().Load(configPath)
+		require.NoError(t, cfg.err)
+		cfg.Port = 9090
+		s.applyConfig(cfg)
+		require.Equal(t, 8080, s.Config.Port)
+
+		warnings := logs.FilterMessage("Configuration change requires a restart to take effect").All()
+		require.Len(t, warnings, 1)
+		require.Equal(t, "port", warnings[0].ContextMap()["field"])
+	})
+}
+
+// TestRestartRequired verifies that changes of all fields except the hot-reloadable ones require a restart.
+func TestRestartRequired(t *testing.T) {
+	running := NewConfig()
+	running.Logging = &logger.Config{Level: "info", Encoding: "json"}
+
+	t.Run("Unchanged", func(t *testing.T) {
+		cfg := NewConfig()
+		cfg.Logging = &logger.Config{Level: "info", Encoding: "json"}
+		require.Empty(t, restartRequired(running, cfg))
+	})
+
+	t.Run("HotReloadable", func(t *testing.T) {
+		cfg := NewConfig()
+		cfg.Logging = &logger.Config{Level: "debug", Encoding: "json"}
+		cfg.Plugins = map[string]config.PluginConfig{"netbox": {Enabled: true, Address: "127.0.0.1:50051"}}
+		require.Empty(t, restartRequired(running, cfg))
+	})
+
+	t.Run("Changed", func(t *testing.T) {
+		cfg := NewConfig()
+		cfg.Logging = &logger.Config{Level: "debug", Encoding: "console"}
+		cfg.Port = 9090
+		cfg.FieldNaming = "camelCase"
+		cfg.Auth = &auth.Config{RequireWriterRole: true}
+		cfg.DomainsFilePermissions = &FilePermissionsConfig{DirMode: "0750"}
+		require.Equal(t, []string{"port", "fieldNaming", "domainsFilePermissions", "logging.encoding", "auth"}, restartRequired(running, cfg))
+	})
+
+	t.Run("NoLogging", func(t *testing.T) {
+		cfg := NewConfig()
+		cfg.Logging = nil
+		require.Equal(t, []string{"logging.encoding"}, restartRequired(running, cfg))
+	})
+}
+
+// TestConfigWatcherPlugins verifies that a changed plugin configuration replaces the plugins of the running server.
+func TestConfigWatcherPlugins(t *testing.T) {
+	tmpDir := t.TempDir()
+	configPath := filepath.Join(tmpDir, "config.yaml")
+	configContent := `
+port: 8080
+dehydratedBaseDir: %s
+watchConfig: true
+`
+	require.NoError(t, os.WriteFile(configPath, []byte(fmt.Sprintf(configContent, tmpDir)), 0644))
+
+	s := NewServer().WithConfig(configPath).WithLogger().WithDomainService()
+	defer s.domainService.Close()
+	require.Empty(t, s.domainService.PluginFailures())
+
+	// Nothing listens on the address, so the plugin is reported as failed
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	addr := lis.Addr().String()
+	require.NoError(t, lis.Close())
+
+	pluginConfig := configContent + `plugins:
+  netbox:
+    enabled: true
+    address: %s
+    insecure: true
+    startupTimeout: 100ms
+`
+	require.NoError(t, os.WriteFile(configPath, []byte(fmt.Sprintf(pluginConfig, tmpDir, addr)), 0644))
+	require.NoError(t, s.reloadConfig())
+
+	failures := s.domainService.PluginFailures()
+	require.Len(t, failures, 1)
+	require.Equal(t, "netbox", failures[0].Name)
+	require.Contains(t, s.Config.Plugins, "netbox")
+
+	// Removing the plugin again replaces the registry with an empty one
+	require.NoError(t, os.WriteFile(configPath, []byte(fmt.Sprintf(configContent, tmpDir)), 0644))
+	require.NoError(t, s.reloadConfig())
+	require.Empty(t, s.domainService.PluginFailures())
+}
+
+// TestConfigWatcherDisabled verifies that the config watcher is only started if enabled.
+func TestConfigWatcherDisabled(t *testing.T) {
+	tmpDir := t.TempDir()
+	configPath := filepath.Join(tmpDir, "config.yaml")
+	err := os.WriteFile(configPath, []byte("port: 8080\n"), 0644)
+	require.NoError(t, err)
+
+	s := NewServer().WithConfig(configPath).WithLogger().WithConfigWatcher()
+	require.Nil(t, s.configWatcher)
+}
@@ -6,6 +6,7 @@ import (
 	"fmt"
 	"net"
 	"os"
+	"path/filepath"
 	"sync"
 
 	pluginregistry "github.com/schumann-it/dehydrated-api-go/internal/plugin/registry"
@@ -20,8 +21,10 @@ import (
 	"github.com/schumann-it/dehydrated-api-go/internal/auth"
 	"github.com/schumann-it/dehydrated-api-go/internal/dehydrated"
 	"github.com/schumann-it/dehydrated-api-go/internal/handler"
+	"github.com/schumann-it/dehydrated-api-go/internal/idempotency"
 	"github.com/schumann-it/dehydrated-api-go/internal/logger"
 	"github.com/schumann-it/dehydrated-api-go/internal/service"
+	"github.com/schumann-it/dehydrated-api-go/internal/util"
 	"go.uber.org/zap"
 )
 
@@ -49,6 +52,14 @@ type Server struct {
 	Config        *Config
 	Logger        *zap.Logger
 	domainService *service.DomainService
+
+	configPath    string               // Path of the loaded server configuration file
+	configErr     error                // Why the defaults apply instead of the configuration file, if they do
+	strictConfig  bool                 // Whether configErr is fatal
+	level         zap.AtomicLevel      // Log level of Logger, adjustable at runtime
+	configWatcher *service.FileWatcher // Watcher for the server configuration file
+
+	pluginConfigProvider pluginregistry.ConfigProvider // Provider of dynamic plugin configuration values
 }
 
 // NewServer creates a new server instance.
@@ -70,21 +81,71 @@ func (s *Server) WithVersionInfo(v, c, b string) *Server {
 }
 
 func (s *Server) WithConfig(path string) *Server {
+	s.configPath = path
 	s.Config = NewConfig().Load(path)
+	s.configErr = s.Config.err
+	if s.configErr == nil {
+		s.configErr = CheckConfigFile(path)
+	}
+
+	// Recreate the app, so the configured timeouts are applied
+	s.app = fiber.New(fiberConfig(s.Config))
 
 	return s
 }
 
+// fiberConfig returns the Fiber app configuration derived from the server configuration.
+func fiberConfig(c *Config) fiber.Config {
+	return fiber.Config{
+		ReadTimeout:             c.ReadTimeout,
+		WriteTimeout:            c.WriteTimeout,
+		IdleTimeout:             c.IdleTimeout,
+		ErrorHandler:            handler.ErrorHandler,
+		EnableTrustedProxyCheck: len(c.TrustedProxies) > 0,
+		TrustedProxies:          c.TrustedProxies,
+	}
+}
+
 func (s *Server) WithLogger() *Server {
 	if s.Config != nil {
 		// Initialize logger with config
-		l, _ := logger.NewLogger(s.Config.Logging)
+		l, level, _ := logger.NewLoggerWithLevel(s.Config.Logging)
 		s.Logger = l
+		s.level = level
 	}
 
-	s.app.Use(fiberzap.New(fiberzap.Config{
-		Logger: s.Logger,
-	}))
+	s.app.Use(fiberzap.New(s.accessLogConfig()))
+
+	s.checkConfig()
+
+	return s
+}
+
+// WithStrictConfig makes a missing, empty or invalid configuration file fatal, instead of logging
+// a warning and applying the defaults. It has to be called before WithLogger.
+func (s *Server) WithStrictConfig(strict bool) *Server {
+	s.strictConfig = strict
+
+	return s
+}
+
+// checkConfig reports that the configuration file was not applied, which is fatal in strict mode.
+func (s *Server) checkConfig() {
+	if s.configErr == nil {
+		return
+	}
+
+	if s.strictConfig {
+		s.Logger.Fatal("Configuration file not applied", zap.String("path", s.configPath), zap.Error(s.configErr))
+		return
+	}
+	s.Logger.Warn("Configuration file not applied, using defaults", zap.String("path", s.configPath), zap.Error(s.configErr))
+}
+
+// WithPluginConfigProvider sets the provider of dynamic values passed to plugins on Initialize,
+// e.g., short-lived tokens. It has to be called before WithDomainService.
+func (s *Server) WithPluginConfigProvider(p pluginregistry.ConfigProvider) *Server {
+	s.pluginConfigProvider = p
 
 	return s
 }
@@ -92,8 +153,22 @@ func (s *Server) WithLogger() *Server {
 func (s *Server) WithDomainService() *Server {
 	cfg := dehydrated.NewConfig().
 		WithBaseDir(s.Config.DehydratedBaseDir).
-		WithConfigFile(s.Config.DehydratedConfigFile).
-		Load()
+		WithConfigFile(s.Config.DehydratedConfigFile)
+
+	if s.Config.ValidateDehydratedConfig {
+		cfg.WithValidation()
+	}
+
+	cfg.Load()
+
+	for _, w := range cfg.Warnings() {
+		s.Logger.Warn("Dehydrated config validation", zap.String("warning", w))
+	}
+
+	if err := cfg.CheckChallengeType(s.Config.AllowedChallengeTypes); err != nil {
+		s.Logger.Fatal("Invalid dehydrated config", zap.Error(err))
+		return s
+	}
 
 	// Create domain service
 	s.Logger.Debug("Creating domain service",
@@ -102,15 +177,97 @@ func (s *Server) WithDomainService() *Server {
 		zap.Bool("watcher_enabled", s.Config.EnableWatcher),
 	)
 
-	r := pluginregistry.New(cfg.BaseDir, s.Config.Plugins, s.Logger)
+	r := pluginregistry.New(cfg.BaseDir, s.Config.Plugins, s.Logger, pluginregistry.WithConfigProvider(s.pluginConfigProvider))
 	domainService := service.NewDomainService(cfg, r)
 
 	if s.Logger != nil {
 		domainService.WithLogger(s.Logger)
 	}
 
+	if s.Config.CommentMarker != "" {
+		domainService.WithCommentMarker(s.Config.CommentMarker)
+	}
+
+	if s.Config.DefaultEnabled {
+		domainService.WithDefaultEnabled(true)
+	}
+
+	if s.Config.DehydratedScript != "" {
+		domainService.WithDehydratedScript(s.Config.DehydratedScript)
+	}
+
+	if s.Config.SidecarMetadata {
+		domainService.WithSidecarMetadata()
+	}
+
+	if s.Config.MergeMetadata {
+		domainService.WithMergedMetadata()
+	}
+
+	if s.Config.LargeIntegerMetadata {
+		domainService.WithLargeIntegerMetadata()
+	}
+
+	if s.Config.OmitTrailingNewline {
+		domainService.WithoutTrailingNewline()
+	}
+	if s.Config.SortAlternativeNames {
+		domainService.WithSortedAlternativeNames()
+	}
+
+	if len(s.Config.AllowedChallengeTypes) > 0 {
+		domainService.WithAllowedChallengeTypes(s.Config.AllowedChallengeTypes)
+	}
+
+	if len(s.Config.AllowedDomainSuffixes) > 0 || len(s.Config.DeniedDomainSuffixes) > 0 {
+		domainService.WithDomainSuffixes(s.Config.AllowedDomainSuffixes, s.Config.DeniedDomainSuffixes)
+	}
+
+	if len(s.Config.CAProfiles) > 0 {
+		domainService.WithCAProfiles(s.Config.CAProfiles)
+	}
+
+	if p := s.Config.DomainsFilePermissions; p != nil {
+		// Validate rejects invalid modes, so the error cannot occur after validation
+		if perms, err := p.FilePermissions(); err != nil {
+			s.Logger.Error("Invalid domains file permissions", zap.Error(err))
+		} else {
+			domainService.WithFilePermissions(perms)
+		}
+	}
+
+	if s.Config.MaxCommentLength > 0 {
+		domainService.WithMaxCommentLength(s.Config.MaxCommentLength)
+	}
+
+	if s.Config.MaxAliasLength > 0 {
+		domainService.WithMaxAliasLength(s.Config.MaxAliasLength)
+	}
+
+	if s.Config.PluginErrorLimit > 0 {
+		domainService.WithPluginErrorLimit(s.Config.PluginErrorLimit)
+	}
+
+	// Zero values keep the defaults, negative values disable the limits
+	warnEntries, maxEntries := service.DefaultWarnEntries, service.DefaultMaxEntries
+	if s.Config.WarnEntries != 0 {
+		warnEntries = s.Config.WarnEntries
+	}
+	if s.Config.MaxEntries != 0 {
+		maxEntries = s.Config.MaxEntries
+	}
+	domainService.WithEntryLimits(warnEntries, maxEntries)
+
+	if wc := s.Config.WriteCoalescing; wc != nil {
+		domainService.WithWriteCoalescing(wc.Interval, wc.MaxPending, wc.Durable)
+	}
+
+	if ic := s.Config.IntegrityCheck; ic != nil {
+		domainService.WithIntegrityCheck(ic.Interval, ic.Reload)
+	}
+
 	if s.Config.EnableWatcher {
-		domainService.WithFileWatcher()
+		domainService.WithWatcherGracePeriod(s.Config.WatcherGracePeriod).WithFileWatcher()
 	}
 
 	err := domainService.Reload()
@@ -122,6 +279,13 @@ func (s *Server) WithDomainService() *Server {
 		return s
 	}
 
+	if s.Config.RequireNonEmptyDomains {
+		if err := domainService.CheckNotEmpty(); err != nil {
+			s.Logger.Fatal("Domains file must not be empty", zap.Error(err))
+			return s
+		}
+	}
+
 	s.Logger.Info("Domain service created successfully")
 
 	s.domainService = domainService
@@ -166,15 +330,33 @@ func (s *Server) setupMiddleware() {
 // setupRoutes configures all routes including health, swagger, and API routes
 func (s *Server) setupRoutes() {
 	// Add health handler
-	handler.NewHealthHandler().RegisterRoutes(s.app)
+	h := handler.NewHealthHandler()
+	if s.domainService != nil {
+		h.WithStorageCheck(filepath.Dir(s.domainService.DehydratedConfig.DomainsFile), util.Uint64(s.Config.MinFreeDiskSpaceMB)*1024*1024).
+			WithPluginCheck(s.domainService.PluginFailures)
+		if s.Config.RequireNonEmptyDomains {
+			h.WithDomainsCheck(s.domainService.CheckNotEmpty)
+		}
+	}
+	h.RegisterRoutes(s.app)
+
+	// Add metrics handler
+	m := handler.NewMetricsHandler()
+	if s.domainService != nil {
+		m.WithIntegrityCheck(s.domainService.IntegrityStatus)
+	}
+	m.RegisterRoutes(s.app)
 
 	// Add Swagger documentation
 	s.app.Get("/docs/*", swagger.HandlerDefault)
 
 	// add API group
-	g := s.app.Group("/api/v1")
+	g := s.app.Group("/api/v1", handler.FieldNaming(s.Config.FieldNaming))
 	s.setupAuthMiddleware(g)
+	s.setupIdempotencyMiddleware(g)
+	handler.NewVersionHandler(s.versionInfo()).RegisterRoutes(g)
 	s.setupDomainRoutes(g)
+	s.setupAdminRoutes(g)
 }
 
 // setupAuthMiddleware configures authentication middleware for the API group
@@ -192,14 +374,49 @@ func (s *Server) setupAuthMiddleware(g fiber.Router) {
 	}
 }
 
+// setupIdempotencyMiddleware configures replaying mutations retried with the same Idempotency-Key for the
+// API group. It has to follow the authentication middleware, since keys are stored per actor, or per client IP
+// without authentication.
+func (s *Server) setupIdempotencyMiddleware(g fiber.Router) {
+	if s.Config.Idempotency != nil {
+		if s.Config.Auth == nil {
+			s.Logger.Warn("Idempotency keys are stored per client IP without authentication")
+		}
+		g.Use(idempotency.Middleware(s.Config.Idempotency, s.Logger))
+	}
+}
+
 // setupDomainRoutes configures domain-related routes
 func (s *Server) setupDomainRoutes(g fiber.Router) {
 	if s.domainService != nil {
-		handler.NewDomainHandler(s.domainService).RegisterRoutes(g)
+		handler.NewDomainHandler(s.domainService).
+			WithResponseFormat(s.Config.ResponseFormat).
+			WithBaseURL(s.Config.PublicBaseURL).
+			WithMetadataFormat(s.Config.MetadataFormat).
+			WithOCSPRefresh(s.Config.EnableOCSPRefresh).
+			WithWriterRole(s.Config.Auth != nil && s.Config.Auth.RequireWriterRole).
+			WithStrictPlugins(s.Config.StrictPlugins).
+			WithSkipDisabledMetadata(s.Config.SkipDisabledMetadata).
+			WithTimeBudget(s.Config.RequestTimeBudget).
+			WithRejectUnknownFields(s.Config.RejectUnknownFields).
+			WithCommentTemplate(s.Config.CommentTemplate).
+			RegisterRoutes(g)
+		handler.NewAccountHandler(s.domainService.DehydratedConfig).RegisterRoutes(g)
 		handler.NewConfigHandler(s.domainService.DehydratedConfig).RegisterRoutes(s.app)
 	}
 }
 
+// setupAdminRoutes configures administrative routes, restricted to the admin role
+func (s *Server) setupAdminRoutes(g fiber.Router) {
+	if s.level != (zap.AtomicLevel{}) {
+		h := handler.NewAdminHandler(s.level, s.Logger)
+		if s.domainService != nil && s.domainService.Watcher() != nil {
+			h.WithWatcher(s.domainService.Watcher())
+		}
+		h.RegisterRoutes(g.Group("admin", auth.RequireRole(auth.RoleAdmin)))
+	}
+}
+
 // startServerGoroutine starts the server in a separate goroutine
 func (s *Server) startServerGoroutine() {
 	s.wg.Add(1)
@@ -307,6 +524,12 @@ func (s *Server) handleShutdown() {
 	// Graceful shutdown
 	s.Logger.Info("Starting graceful shutdown")
 
+	if s.configWatcher != nil {
+		if err := s.configWatcher.Close(); err != nil {
+			s.Logger.Error("Failed to close config watcher", zap.Error(err))
+		}
+	}
+
 	if s.domainService != nil {
 		s.domainService.Close()
 	}
@@ -342,31 +565,42 @@ func (s *Server) GetPort() int {
 	return s.port
 }
 
-func (s *Server) PrintInfo(v, i bool) {
-	if v {
-		s.PrintVersion()
+// PrintInfo prints the version information if v is set and the resolved configs if i is set
+// to stdout, formatted as InfoFormatText or InfoFormatJSON, and exits if anything was printed.
+func (s *Server) PrintInfo(v, i bool, format string) {
+	if !v && !i {
+		return
 	}
 
-	if i {
-		s.PrintServerConfig()
-		s.PrintDehydratedConfig()
+	if err := s.WriteInfo(os.Stdout, v, i, format); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
 	}
 
-	if v || i {
-		os.Exit(0)
-	}
+	os.Exit(0)
 }
 
 func (s *Server) PrintVersion() {
-	fmt.Printf("dehydrated-api-go version %s (commit: %s, built: %s)\n", s.Version, s.Commit, s.BuildTime)
+	info, _ := s.Info(true, false)
+	_ = info.WriteText(os.Stdout)
 }
 
 func (s *Server) PrintServerConfig() {
-	fmt.Printf("%sResolved Server Config:%s\n", bold, reset)
-	fmt.Printf("%s\n", s.Config.String())
+	info, err := s.Info(false, true)
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+	info.DehydratedConfig = nil
+	_ = info.WriteText(os.Stdout)
 }
 
 func (s *Server) PrintDehydratedConfig() {
-	fmt.Printf("%sResolved Dehydrated Config:%s\n", bold, reset)
-	fmt.Printf("%s\n", s.domainService.DehydratedConfig.String())
+	info, err := s.Info(false, true)
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+	info.ServerConfig = nil
+	_ = info.WriteText(os.Stdout)
 }
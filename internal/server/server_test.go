@@ -2,21 +2,35 @@ package server
 
 import (
 	"bufio"
+	"bytes"
+	"context"
 	"encoding/json"
 	"flag"
 	"fmt"
 	"net/http"
+	"net/http/httptest"
 	"os"
 	"path/filepath"
+	"runtime"
 	"strings"
 	"testing"
 	"time"
 
 	"github.com/schumann-it/dehydrated-api-go/internal/plugin/cache"
 
+	"github.com/gofiber/contrib/fiberzap/v2"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/schumann-it/dehydrated-api-go/internal/auth"
+	"github.com/schumann-it/dehydrated-api-go/internal/logger"
 	"github.com/schumann-it/dehydrated-api-go/internal/model"
+	"github.com/schumann-it/dehydrated-api-go/internal/testutil"
+	pb "github.com/schumann-it/dehydrated-api-go/plugin/proto"
 	"github.com/stretchr/testify/require"
 	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
 )
 
 // TestMain handles global state for all tests in this package.
@@ -132,6 +146,22 @@ func TestServerInitialization(t *testing.T) {
 		require.Equal(t, "2024-01-01", s.BuildTime)
 	})
 
+	t.Run("VersionEndpoint", func(t *testing.T) {
+		s := NewServer().WithVersionInfo("1.0.0", "abc123", "2024-01-01")
+		s.Config = NewConfig()
+		s.setupRoutes()
+
+		resp, err := s.app.Test(httptest.NewRequest(http.MethodGet, "/api/v1/version", http.NoBody))
+		require.NoError(t, err)
+		defer resp.Body.Close()
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+
+		var version VersionInfo
+		require.NoError(t, json.NewDecoder(resp.Body).Decode(&version))
+		require.Equal(t, VersionInfo{Version: "1.0.0", Commit: "abc123", BuildTime: "2024-01-01",
+			GoVersion: runtime.Version()}, version)
+	})
+
 	t.Run("WithLogger", func(t *testing.T) {
 		// Create a temporary config file with logging configuration
 		tmpDir := t.TempDir()
@@ -156,6 +186,208 @@ logging:
 		// Should use default values when config file doesn't exist
 		require.Equal(t, 3000, s.Config.Port)
 	})
+
+	t.Run("WithTimeouts", func(t *testing.T) {
+		tmpDir := t.TempDir()
+		configPath := filepath.Join(tmpDir, "config.yaml")
+		configContent := `
+port: 8080
+readTimeout: 5s
+writeTimeout: 10s
+`
+		err := os.WriteFile(configPath, []byte(configContent), 0644)
+		require.NoError(t, err)
+
+		s := NewServer().WithConfig(configPath)
+		require.Equal(t, 5*time.Second, s.app.Config().ReadTimeout)
+		require.Equal(t, 10*time.Second, s.app.Config().WriteTimeout)
+		// Not configured, so the default applies
+		require.Equal(t, 120*time.Second, s.app.Config().IdleTimeout)
+		require.False(t, s.app.Config().EnableTrustedProxyCheck)
+	})
+
+	t.Run("WithTrustedProxies", func(t *testing.T) {
+		tmpDir := t.TempDir()
+		configPath := filepath.Join(tmpDir, "config.yaml")
+		configContent := `
+trustedProxies: ["10.0.0.1", "192.168.0.0/16"]
+publicBaseURL: https://acme.example.com
+`
+		require.NoError(t, os.WriteFile(configPath, []byte(configContent), 0644))
+
+		s := NewServer().WithConfig(configPath)
+		require.True(t, s.app.Config().EnableTrustedProxyCheck)
+		require.Equal(t, []string{"10.0.0.1", "192.168.0.0/16"}, s.app.Config().TrustedProxies)
+		require.Equal(t, "https://acme.example.com", s.Config.PublicBaseURL)
+	})
+}
+
+// TestConfigFileCheck verifies that a configuration file that is not applied is reported,
+// with a warning by default and fatally in strict mode.
+func TestConfigFileCheck(t *testing.T) {
+	tmpDir := t.TempDir()
+	write := func(name, content string) string {
+		path := filepath.Join(tmpDir, name)
+		require.NoError(t, os.WriteFile(path, []byte(content), 0644))
+		return path
+	}
+	missing := filepath.Join(tmpDir, "missing.yaml")
+	empty := write("empty.yaml", "")
+	comments := write("comments.yaml", "# port: 8080\n")
+	invalid := write("invalid.yaml", "port: [")
+	valid := write("valid.yaml", "port: 8080\n")
+
+	t.Run("CheckConfigFile", func(t *testing.T) {
+		require.ErrorIs(t, CheckConfigFile(missing), ErrConfigNotFound)
+		require.ErrorIs(t, CheckConfigFile(empty), ErrConfigEmpty)
+		require.ErrorIs(t, CheckConfigFile(comments), ErrConfigEmpty)
+		require.NoError(t, CheckConfigFile(invalid))
+		require.NoError(t, CheckConfigFile(valid))
+	})
+
+	t.Run("Warn", func(t *testing.T) {
+		for path, want := range map[string]string{
+			missing:  ErrConfigNotFound.Error(),
+			empty:    ErrConfigEmpty.Error(),
+			comments: ErrConfigEmpty.Error(),
+			invalid:  "yaml",
+		} {
+			core, logs := observer.New(zapcore.WarnLevel)
+			s := NewServer().WithConfig(path)
+			s.Logger = zap.New(core)
+			s.checkConfig()
+
+			require.Equal(t, 3000, s.Config.Port, path)
+			entries := logs.FilterMessage("Configuration file not applied, using defaults").All()
+			require.Len(t, entries, 1, path)
+			require.Equal(t, path, entries[0].ContextMap()["path"])
+			require.Contains(t, entries[0].ContextMap()["error"], want)
+		}
+	})
+
+	t.Run("Valid", func(t *testing.T) {
+		core, logs := observer.New(zapcore.DebugLevel)
+		s := NewServer().WithConfig(valid).WithStrictConfig(true)
+		s.Logger = zap.New(core, zap.WithFatalHook(zapcore.WriteThenPanic))
+		require.NotPanics(t, s.checkConfig)
+		require.Zero(t, logs.Len())
+	})
+
+	t.Run("Strict", func(t *testing.T) {
+		for _, path := range []string{missing, empty, invalid} {
+			core, logs := observer.New(zapcore.WarnLevel)
+			s := NewServer().WithConfig(path).WithStrictConfig(true)
+			s.Logger = zap.New(core, zap.WithFatalHook(zapcore.WriteThenPanic))
+
+			require.Panics(t, s.checkConfig, path)
+			require.Equal(t, 1, logs.FilterLevelExact(zapcore.FatalLevel).Len(), path)
+		}
+	})
+}
+
+// TestRequireNonEmptyDomains verifies that an empty domains file fails startup and readiness only if required.
+func TestRequireNonEmptyDomains(t *testing.T) {
+	newServer := func(t *testing.T, required bool, domains string) (*Server, *observer.ObservedLogs) {
+		tmpDir := t.TempDir()
+		configPath := filepath.Join(tmpDir, "config.yaml")
+		config := fmt.Sprintf("port: 0\ndehydratedBaseDir: %s\nrequireNonEmptyDomains: %t\n", tmpDir, required)
+		require.NoError(t, os.WriteFile(configPath, []byte(config), 0o644))
+		require.NoError(t, os.WriteFile(filepath.Join(tmpDir, "domains.txt"), []byte(domains), 0o644))
+
+		core, logs := observer.New(zapcore.WarnLevel)
+		s := NewServer().WithConfig(configPath)
+		s.Logger = zap.New(core, zap.WithFatalHook(zapcore.WriteThenPanic))
+		return s, logs
+	}
+	readyz := func(t *testing.T, s *Server) int {
+		resp, err := s.app.Test(httptest.NewRequest(http.MethodGet, "/readyz", http.NoBody))
+		require.NoError(t, err)
+		defer resp.Body.Close()
+		return resp.StatusCode
+	}
+
+	t.Run("EmptyAllowed", func(t *testing.T) {
+		s, _ := newServer(t, false, "")
+		require.NotPanics(t, func() { s.WithDomainService() })
+		defer s.domainService.Close()
+		s.setupRoutes()
+
+		require.Equal(t, http.StatusOK, readyz(t, s))
+	})
+
+	t.Run("EmptyRequired", func(t *testing.T) {
+		s, logs := newServer(t, true, "# no domains yet\n")
+		require.Panics(t, func() { s.WithDomainService() })
+		entries := logs.FilterLevelExact(zapcore.FatalLevel).All()
+		require.Len(t, entries, 1)
+		require.Contains(t, entries[0].ContextMap()["error"], "domains file has no entries")
+	})
+
+	t.Run("EmptiedRequired", func(t *testing.T) {
+		s, _ := newServer(t, true, "example.com\n")
+		require.NotPanics(t, func() { s.WithDomainService() })
+		defer s.domainService.Close()
+		s.setupRoutes()
+		require.Equal(t, http.StatusOK, readyz(t, s))
+
+		require.NoError(t, s.domainService.DeleteDomain("example.com", model.DeleteDomainRequest{}))
+		require.Equal(t, http.StatusServiceUnavailable, readyz(t, s))
+	})
+}
+
+// TestAccessLog verifies that the access log contains the configured fields and the identity of the caller.
+func TestAccessLog(t *testing.T) {
+	token, err := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{
+		"iss":   "https://sts.windows.net/tenant/",
+		"aud":   "api://dehydrated",
+		"exp":   float64(time.Now().Add(time.Hour).Unix()),
+		"sub":   "user",
+		"roles": []any{auth.RoleWriter},
+	}).SignedString([]byte("secret"))
+	require.NoError(t, err)
+
+	tests := []struct {
+		name     string
+		auth     bool
+		fields   []string
+		expected map[string]any
+	}{
+		{"Authenticated", true, nil, map[string]any{"status": int64(http.StatusOK), "actor": "user", "roles": []any{auth.RoleWriter}}},
+		{"Anonymous", false, nil, map[string]any{"status": int64(http.StatusOK), "actor": auth.Anonymous, "roles": []any{}}},
+		{"Configured", true, []string{"method", "actor"}, map[string]any{"method": http.MethodGet, "actor": "user"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			core, logs := observer.New(zapcore.InfoLevel)
+			s := NewServer().WithVersionInfo("1.0.0", "abc123", "2024-01-01")
+			s.Config = NewConfig()
+			s.Config.Logging = &logger.Config{AccessLogFields: tt.fields}
+			if tt.auth {
+				s.Config.Auth = &auth.Config{TenantID: "tenant", AllowedAudiences: []string{"api://dehydrated"}}
+			}
+			s.Logger = zap.New(core)
+			s.app.Use(fiberzap.New(s.accessLogConfig()))
+			s.setupRoutes()
+
+			req := httptest.NewRequest(http.MethodGet, "/api/v1/version", http.NoBody)
+			req.Header.Set("Authorization", "Bearer "+token)
+			resp, err := s.app.Test(req)
+			require.NoError(t, err)
+			defer resp.Body.Close()
+			require.Equal(t, http.StatusOK, resp.StatusCode)
+
+			entries := logs.FilterMessage("Success").All()
+			require.Len(t, entries, 1)
+			fields := entries[0].ContextMap()
+			for key, value := range tt.expected {
+				require.Equal(t, value, fields[key], key)
+			}
+			if tt.fields != nil {
+				require.Len(t, fields, len(tt.fields))
+			}
+		})
+	}
 }
 
 // TestServerPrintFunctions tests the server's print functions.
@@ -249,6 +481,43 @@ enableWatcher: true
 		require.Contains(t, output, "Resolved Dehydrated Config")
 	})
 
+	// Test WriteInfo with the JSON format
+	t.Run("WriteInfoJSON", func(t *testing.T) {
+		var buf bytes.Buffer
+		require.NoError(t, s.WriteInfo(&buf, true, true, InfoFormatJSON))
+
+		var info map[string]json.RawMessage
+		require.NoError(t, json.Unmarshal(buf.Bytes(), &info))
+		require.Len(t, info, 3)
+		require.Contains(t, info, "version")
+		require.Contains(t, info, "server_config")
+		require.Contains(t, info, "dehydrated_config")
+
+		var version VersionInfo
+		require.NoError(t, json.Unmarshal(info["version"], &version))
+		require.Equal(t, VersionInfo{Version: "1.0.0", Commit: "abc123", BuildTime: "2024-01-01",
+			GoVersion: runtime.Version()}, version)
+
+		var serverConfig map[string]any
+		require.NoError(t, json.Unmarshal(info["server_config"], &serverConfig))
+		require.EqualValues(t, 8080, serverConfig["port"])
+		require.Equal(t, "/tmp/dehydrated", serverConfig["dehydratedBaseDir"])
+
+		var dehydratedConfig map[string]any
+		require.NoError(t, json.Unmarshal(info["dehydrated_config"], &dehydratedConfig))
+		require.Equal(t, "/tmp/dehydrated", dehydratedConfig["BASEDIR"])
+
+		// Only the requested sections are included
+		buf.Reset()
+		require.NoError(t, s.WriteInfo(&buf, true, false, InfoFormatJSON))
+		info = nil
+		require.NoError(t, json.Unmarshal(buf.Bytes(), &info))
+		require.Len(t, info, 1)
+		require.Contains(t, info, "version")
+
+		require.ErrorContains(t, s.WriteInfo(&buf, true, true, "xml"), "invalid format")
+	})
+
 	cache.Clean()
 }
 
@@ -452,3 +721,48 @@ plugins:
 
 	cache.Clean()
 }
+
+// rejectingPlugin rejects its configuration in Initialize.
+type rejectingPlugin struct {
+	pb.UnimplementedPluginServer
+}
+
+func (p *rejectingPlugin) Initialize(_ context.Context, _ *pb.InitializeRequest) (*pb.InitializeResponse, error) {
+	return nil, status.Error(codes.InvalidArgument, "missing apiToken")
+}
+
+// TestReadinessWithMisconfiguredPlugin verifies that /readyz reports a plugin rejecting its configuration.
+func TestReadinessWithMisconfiguredPlugin(t *testing.T) {
+	address, _ := testutil.ServePlugin(t, &rejectingPlugin{})
+
+	tmpDir := t.TempDir()
+	configPath := filepath.Join(tmpDir, "config.yaml")
+	configContent := `
+port: 0
+dehydratedBaseDir: %s
+plugins:
+  netbox:
+    enabled: true
+    address: %s
+    insecure: true
+`
+	err := os.WriteFile(configPath, []byte(fmt.Sprintf(configContent, tmpDir, address)), 0644)
+	require.NoError(t, err)
+
+	s := NewServer().WithConfig(configPath).WithLogger().WithDomainService()
+	defer s.domainService.Close()
+	s.setupRoutes()
+
+	resp, err := s.app.Test(httptest.NewRequest(http.MethodGet, "/readyz", http.NoBody))
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	require.Equal(t, http.StatusServiceUnavailable, resp.StatusCode)
+
+	var readiness model.ReadinessResponse
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&readiness))
+	require.False(t, readiness.Success)
+	require.Len(t, readiness.Data.Plugins, 1)
+	require.Equal(t, "netbox", readiness.Data.Plugins[0].Name)
+	require.Equal(t, model.PluginMisconfigured, readiness.Data.Plugins[0].State)
+	require.Contains(t, readiness.Data.Plugins[0].Reason, "missing apiToken")
+}
@@ -0,0 +1,160 @@
+package service
+
+import (
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/schumann-it/dehydrated-api-go/internal/model"
+)
+
+// Defaults of the write coalescing, applied to zero values.
+const (
+	// DefaultCoalesceInterval is the maximum time a change waits before it is written to disk.
+	DefaultCoalesceInterval = 100 * time.Millisecond
+
+	// DefaultCoalesceMaxPending is the number of pending changes that triggers an immediate write.
+	DefaultCoalesceMaxPending = 100
+)
+
+// writeBatcher coalesces writes of the domains file. Mutations schedule the latest state of all
+// entries, a background loop writes the latest scheduled state at most once per interval, or
+// immediately once maxPending changes are pending. A failed write keeps the state pending, so it
+// is retried with the next flush.
+type writeBatcher struct {
+	interval   time.Duration
+	maxPending int
+	write      func([]*model.DomainEntry) error
+	logger     *zap.Logger
+
+	mu      sync.Mutex
+	entries []*model.DomainEntry // latest scheduled state, nil if everything is written
+	pending int                  // number of changes in entries
+	waiters []chan error         // callers waiting for entries to be written
+	writing bool                 // whether a write is in progress
+	flushes uint64               // number of completed writes
+
+	flushMu sync.Mutex // serializes writes of the loop and close
+	kick    chan struct{}
+	stop    chan struct{}
+	stopped chan struct{}
+}
+
+// newWriteBatcher creates a writeBatcher writing with write and starts its background loop.
+func newWriteBatcher(interval time.Duration, maxPending int, write func([]*model.DomainEntry) error, logger *zap.Logger) *writeBatcher {
+	if interval <= 0 {
+		interval = DefaultCoalesceInterval
+	}
+	if maxPending <= 0 {
+		maxPending = DefaultCoalesceMaxPending
+	}
+
+	b := &writeBatcher{
+		interval:   interval,
+		maxPending: maxPending,
+		write:      write,
+		logger:     logger,
+		kick:       make(chan struct{}, 1),
+		stop:       make(chan struct{}),
+		stopped:    make(chan struct{}),
+	}
+	go b.run()
+
+	return b
+}
+
+// schedule marks entries as the state to be written. The returned channel receives the result
+// of the write including this change.
+func (b *writeBatcher) schedule(entries []*model.DomainEntry) <-chan error {
+	done := make(chan error, 1)
+
+	b.mu.Lock()
+	// The caller keeps modifying its slice, the batcher writes a copy without holding the caller's lock.
+	b.entries = append(make([]*model.DomainEntry, 0, len(entries)), entries...)
+	b.pending++
+	b.waiters = append(b.waiters, done)
+	full := b.pending >= b.maxPending
+	b.mu.Unlock()
+
+	if full {
+		select {
+		case b.kick <- struct{}{}:
+		default:
+		}
+	}
+
+	return done
+}
+
+// state returns whether changes are pending or being written and the number of completed writes.
+func (b *writeBatcher) state() (dirty bool, flushes uint64) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.entries != nil || b.writing, b.flushes
+}
+
+func (b *writeBatcher) run() {
+	defer close(b.stopped)
+
+	ticker := time.NewTicker(b.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-b.stop:
+			return
+		case <-ticker.C:
+		case <-b.kick:
+		}
+		// The error is reported to the waiters and logged, the state is retried with the next flush
+		_ = b.flush()
+	}
+}
+
+// flush writes the pending state, if any.
+func (b *writeBatcher) flush() error {
+	b.flushMu.Lock()
+	defer b.flushMu.Unlock()
+
+	b.mu.Lock()
+	entries, pending, waiters := b.entries, b.pending, b.waiters
+	b.entries, b.pending, b.waiters = nil, 0, nil
+	b.writing = entries != nil
+	b.mu.Unlock()
+
+	if entries == nil {
+		return nil
+	}
+
+	err := b.write(entries)
+
+	b.mu.Lock()
+	b.writing = false
+	if err != nil {
+		b.logger.Error("Failed to write coalesced changes, retrying with the next flush",
+			zap.Int("changes", pending), zap.Error(err))
+		// Keep the state for a retry, unless a newer one has been scheduled meanwhile
+		if b.entries == nil {
+			b.entries = entries
+		}
+		b.pending += pending
+	} else {
+		b.flushes++
+		b.logger.Debug("Wrote coalesced changes", zap.Int("changes", pending))
+	}
+	b.mu.Unlock()
+
+	for _, w := range waiters {
+		w <- err
+	}
+
+	return err
+}
+
+// close stops the background loop and writes the pending state.
+func (b *writeBatcher) close() error {
+	close(b.stop)
+	<-b.stopped
+	return b.flush()
+}
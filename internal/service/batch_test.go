@@ -0,0 +1,140 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"github.com/schumann-it/dehydrated-api-go/internal/dehydrated"
+	"github.com/schumann-it/dehydrated-api-go/internal/model"
+)
+
+// createDomains creates n domains concurrently and fails the test on any error.
+func createDomains(t *testing.T, s *DomainService, n int) {
+	t.Helper()
+
+	var wg sync.WaitGroup
+	errs := make(chan error, n)
+	for i := 0; i < n; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			_, err := s.CreateDomain(&model.CreateDomainRequest{Domain: fmt.Sprintf("example%d.com", i)})
+			errs <- err
+		}(i)
+	}
+	wg.Wait()
+	close(errs)
+
+	for err := range errs {
+		require.NoError(t, err)
+	}
+}
+
+func TestWriteCoalescing(t *testing.T) {
+	const n = 50
+
+	t.Run("Coalesced", func(t *testing.T) {
+		dc := dehydrated.NewConfig().WithBaseDir(t.TempDir()).Load()
+		s := NewDomainService(dc, nil).WithWriteCoalescing(time.Hour, 1000, false)
+
+		createDomains(t, s, n)
+
+		// The changes are visible before they are written
+		entry, err := s.GetDomain("example0.com", "")
+		require.NoError(t, err)
+		require.Equal(t, "example0.com", entry.Domain)
+
+		dirty, flushes := s.batcher.state()
+		require.True(t, dirty)
+		require.Zero(t, flushes)
+
+		// Close writes the pending changes
+		require.NoError(t, s.Close())
+
+		dirty, flushes = s.batcher.state()
+		require.False(t, dirty)
+		require.Equal(t, uint64(1), flushes)
+
+		entries, err := ReadDomainsFile(dc.DomainsFile)
+		require.NoError(t, err)
+		require.Len(t, entries, n)
+	})
+
+	t.Run("Durable", func(t *testing.T) {
+		dc := dehydrated.NewConfig().WithBaseDir(t.TempDir()).Load()
+		s := NewDomainService(dc, nil).WithWriteCoalescing(20*time.Millisecond, 1000, true)
+		defer s.Close()
+
+		createDomains(t, s, n)
+
+		// All mutations returned, so all changes are on disk
+		entries, err := ReadDomainsFile(dc.DomainsFile)
+		require.NoError(t, err)
+		require.Len(t, entries, n)
+
+		_, flushes := s.batcher.state()
+		require.Less(t, flushes, uint64(n))
+	})
+
+	t.Run("MaxPending", func(t *testing.T) {
+		dc := dehydrated.NewConfig().WithBaseDir(t.TempDir()).Load()
+		s := NewDomainService(dc, nil).WithWriteCoalescing(time.Hour, 5, false)
+		defer s.Close()
+
+		createDomains(t, s, 5)
+
+		require.Eventually(t, func() bool {
+			entries, err := ReadDomainsFile(dc.DomainsFile)
+			return err == nil && len(entries) == 5
+		}, time.Second, 10*time.Millisecond)
+	})
+
+	t.Run("ReloadKeepsPendingChanges", func(t *testing.T) {
+		dc := dehydrated.NewConfig().WithBaseDir(t.TempDir()).Load()
+		s := NewDomainService(dc, nil).WithWriteCoalescing(time.Hour, 1000, false)
+		defer s.Close()
+
+		createDomains(t, s, 1)
+		require.NoError(t, s.Reload())
+
+		_, err := s.GetDomain("example0.com", "")
+		require.NoError(t, err)
+	})
+}
+
+func TestWriteBatcherRetry(t *testing.T) {
+	var calls atomic.Int32
+	var written []*model.DomainEntry
+	write := func(entries []*model.DomainEntry) error {
+		if calls.Add(1) == 1 {
+			return errors.New("disk full")
+		}
+		written = entries
+		return nil
+	}
+
+	b := newWriteBatcher(time.Hour, 1000, write, zap.NewNop())
+
+	entries := []*model.DomainEntry{{}}
+	done := b.schedule(entries)
+
+	// The failed write is reported and kept pending
+	require.Error(t, b.flush())
+	require.Error(t, <-done)
+	dirty, flushes := b.state()
+	require.True(t, dirty)
+	require.Zero(t, flushes)
+
+	require.NoError(t, b.close())
+	require.Equal(t, entries, written)
+	dirty, flushes = b.state()
+	require.False(t, dirty)
+	require.Equal(t, uint64(1), flushes)
+}
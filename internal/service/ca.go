@@ -0,0 +1,84 @@
+package service
+
+import (
+	"fmt"
+	"maps"
+	"slices"
+
+	"go.uber.org/zap"
+
+	"github.com/schumann-it/dehydrated-api-go/internal/dehydrated"
+	"github.com/schumann-it/dehydrated-api-go/internal/model"
+	serviceinterface "github.com/schumann-it/dehydrated-api-go/internal/service/interface"
+)
+
+// WithCAProfiles sets the CA profiles entries may select, by name. The values are CA settings of dehydrated,
+// e.g., letsencrypt-test or the URL of an ACME directory. The CA of the selected profile is written to the
+// certificate config of the entry. Without profiles, entries cannot select a CA.
+func (s *DomainService) WithCAProfiles(profiles map[string]string) *DomainService {
+	s.caProfiles = profiles
+	return s
+}
+
+// checkCAProfile returns an error wrapping ErrInvalidDomainEntry if profile is not one of the CA profiles.
+func (s *DomainService) checkCAProfile(profile string) error {
+	if _, ok := s.caProfiles[profile]; ok {
+		return nil
+	}
+	if len(s.caProfiles) == 0 {
+		return fmt.Errorf("%w: CA profile %s selected, but no CA profiles are configured", serviceinterface.ErrInvalidDomainEntry, profile)
+	}
+	return fmt.Errorf("%w: unknown CA profile %s, use one of %v", serviceinterface.ErrInvalidDomainEntry,
+		profile, slices.Sorted(maps.Keys(s.caProfiles)))
+}
+
+// caProfile returns the name of the first CA profile, in order of the names, with the given CA.
+// A CA that matches no profile is returned as is.
+func (s *DomainService) caProfile(ca string) string {
+	for _, name := range slices.Sorted(maps.Keys(s.caProfiles)) {
+		if s.caProfiles[name] == ca {
+			return name
+		}
+	}
+	return ca
+}
+
+// entryConfig returns the effective dehydrated configuration of entry, with the CA of its CA profile, if selected.
+// It lets plugins validate an entry with the CA selected by the request before it is written.
+func (s *DomainService) entryConfig(entry *model.DomainEntry) *dehydrated.Config {
+	cfg := s.DehydratedConfig.DomainSpecificConfig(entry.PathName())
+	if ca, ok := s.caProfiles[entry.CA]; ok && ca != cfg.Ca {
+		return cfg.WithCA(ca)
+	}
+	return cfg
+}
+
+// writeCA writes the CA of profile to the certificate config of entry, an empty profile removes the override.
+// It returns the previous override, which restoreCA restores if the change of the entry fails.
+func (s *DomainService) writeCA(entry *model.DomainEntry, profile string) (string, error) {
+	previous := s.DehydratedConfig.DomainSpecificCA(entry.PathName())
+	if err := s.DehydratedConfig.SetDomainSpecificCA(entry.PathName(), s.caProfiles[profile]); err != nil {
+		s.logger.Error("Failed to write certificate config", zap.String("certificate", entry.PathName()), zap.Error(err))
+		return "", fmt.Errorf("failed to write certificate config of %s: %w", entry.PathName(), err)
+	}
+	return previous, nil
+}
+
+// restoreCA restores the previous CA override of entry, see writeCA.
+func (s *DomainService) restoreCA(entry *model.DomainEntry, previous string) {
+	if err := s.DehydratedConfig.SetDomainSpecificCA(entry.PathName(), previous); err != nil {
+		s.logger.Error("Failed to restore certificate config", zap.String("certificate", entry.PathName()), zap.Error(err))
+	}
+}
+
+// loadCA sets the CA profile of entry from its certificate config, if CA profiles are configured.
+func (s *DomainService) loadCA(entry *model.DomainEntry) {
+	if len(s.caProfiles) == 0 {
+		return
+	}
+
+	entry.CA = ""
+	if ca := s.DehydratedConfig.DomainSpecificCA(entry.PathName()); ca != "" {
+		entry.CA = s.caProfile(ca)
+	}
+}
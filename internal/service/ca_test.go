@@ -0,0 +1,89 @@
+package service
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/schumann-it/dehydrated-api-go/internal/dehydrated"
+	"github.com/schumann-it/dehydrated-api-go/internal/model"
+	serviceinterface "github.com/schumann-it/dehydrated-api-go/internal/service/interface"
+	"github.com/schumann-it/dehydrated-api-go/internal/util"
+)
+
+func TestCAProfiles(t *testing.T) {
+	plugin := &serviceinterface.MockPlugin{Validates: true}
+	r := &serviceinterface.MockPluginRegistry{Clients: map[string]*serviceinterface.MockPlugin{"inventory": plugin}}
+	dc := dehydrated.NewConfig().WithBaseDir(t.TempDir()).Load()
+	s := NewDomainService(dc, r).WithCAProfiles(map[string]string{
+		"production": "letsencrypt",
+		"staging":    "letsencrypt-test",
+	})
+	defer s.Close()
+
+	// A domain tagged with the staging CA round-trips
+	entry, err := s.CreateDomain(&model.CreateDomainRequest{Domain: "example.com", Alias: "example", Enabled: util.BoolPtr(true), CA: "staging"})
+	require.NoError(t, err)
+	require.Equal(t, "staging", entry.CA)
+	require.Equal(t, "letsencrypt-test", plugin.Config.GetCa(), "plugins validate with the selected CA")
+
+	data, err := os.ReadFile(filepath.Join(dc.CertDir, "example", "config"))
+	require.NoError(t, err)
+	require.Equal(t, "CA=\"letsencrypt-test\"\n", string(data))
+
+	entry, err = s.GetDomain("example.com", "example")
+	require.NoError(t, err)
+	require.Equal(t, "staging", entry.CA)
+	require.Equal(t, "letsencrypt-test", plugin.Config.GetCa(), "plugins enrich with the selected CA")
+
+	// The effective config reflects the chosen CA
+	cfg, err := s.EffectiveConfig("example.com", "example")
+	require.NoError(t, err)
+	require.Equal(t, "letsencrypt-test", cfg.Ca)
+
+	// Updates keep the CA unless it is changed
+	entry, err = s.UpdateDomain("example.com", model.UpdateDomainRequest{Alias: util.StringPtr("example"), Comment: util.StringPtr("test")})
+	require.NoError(t, err)
+	require.Equal(t, "staging", entry.CA)
+
+	entry, err = s.UpdateDomain("example.com", model.UpdateDomainRequest{Alias: util.StringPtr("example"), CA: util.StringPtr("")})
+	require.NoError(t, err)
+	require.Empty(t, entry.CA)
+	require.NoFileExists(t, filepath.Join(dc.CertDir, "example", "config"))
+
+	entries, _, err := s.ListDomains(1, 10, "", "")
+	require.NoError(t, err)
+	require.Empty(t, entries[0].CA)
+
+	// Upserts select the CA, too
+	entry, created, err := s.UpsertDomain("example.org", model.UpdateDomainRequest{CA: util.StringPtr("production")})
+	require.NoError(t, err)
+	require.True(t, created)
+	require.Equal(t, "production", entry.CA)
+
+	// A CA without a profile is reported as is
+	require.NoError(t, dc.SetDomainSpecificCA("example.org", "zerossl"))
+	entry, err = s.GetDomain("example.org", "")
+	require.NoError(t, err)
+	require.Equal(t, "zerossl", entry.CA)
+
+	// Unknown profiles are rejected without writing the entry
+	_, err = s.CreateDomain(&model.CreateDomainRequest{Domain: "example.net", CA: "buypass"})
+	require.ErrorIs(t, err, serviceinterface.ErrInvalidDomainEntry)
+	require.ErrorContains(t, err, "use one of [production staging]")
+	_, err = s.GetDomain("example.net", "")
+	require.ErrorIs(t, err, serviceinterface.ErrDomainNotFound)
+	require.NoFileExists(t, filepath.Join(dc.CertDir, "example.net", "config"))
+
+	// Imports cannot select a CA
+	_, err = s.ImportDomains([]*model.CreateDomainRequest{{Domain: "example.net", CA: "staging"}}, false)
+	require.ErrorIs(t, err, serviceinterface.ErrInvalidDomainEntry)
+
+	// Without profiles, no CA can be selected
+	s = NewDomainService(dc, nil)
+	defer s.Close()
+	_, err = s.CreateDomain(&model.CreateDomainRequest{Domain: "example.net", CA: "staging"})
+	require.ErrorContains(t, err, "no CA profiles are configured")
+}
@@ -0,0 +1,279 @@
+package service
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/schumann-it/dehydrated-api-go/internal/dehydrated"
+	"github.com/schumann-it/dehydrated-api-go/internal/model"
+	serviceinterface "github.com/schumann-it/dehydrated-api-go/internal/service/interface"
+	"github.com/schumann-it/dehydrated-api-go/internal/testutil"
+	"github.com/schumann-it/dehydrated-api-go/internal/util"
+	pb "github.com/schumann-it/dehydrated-api-go/plugin/proto"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/types/known/structpb"
+)
+
+const day = 24 * time.Hour
+
+// newCertTestService creates a service with entries whose certificates expire at various times:
+// valid.example.com in 60 days, expiring.example.com in 7 days, expired.example.com 1 day ago,
+// missing.example.com has no certificate, and the aliased entry of valid.example.com expires in 10 days.
+func newCertTestService(t *testing.T) *DomainService {
+	t.Helper()
+
+	dc := dehydrated.NewConfig().WithBaseDir(t.TempDir()).Load()
+	s := NewDomainService(dc, nil)
+	t.Cleanup(func() { s.Close() })
+
+	entries := []struct {
+		domain  string
+		alias   string
+		enabled bool
+		expires time.Duration
+	}{
+		{"valid.example.com", "", true, 60 * day},
+		{"valid.example.com", "valid.example.com-ecdsa", true, 10 * day},
+		{"expiring.example.com", "", true, 7 * day},
+		{"expired.example.com", "", false, -day},
+		{"missing.example.com", "", true, 0},
+	}
+	for _, e := range entries {
+		entry, err := s.CreateDomain(&model.CreateDomainRequest{Domain: e.domain, Alias: e.alias, Enabled: util.BoolPtr(e.enabled)})
+		require.NoError(t, err)
+		if e.expires != 0 {
+			file, err := dc.CertFile(entry.PathName())
+			require.NoError(t, err)
+			testutil.WriteCert(t, file, e.domain, time.Now().Add(e.expires))
+		}
+	}
+
+	return s
+}
+
+func TestSummary(t *testing.T) {
+	s := newCertTestService(t)
+
+	t.Run("DefaultThreshold", func(t *testing.T) {
+		summary, err := s.Summary(14 * day)
+		require.NoError(t, err)
+		require.Equal(t, &model.Summary{
+			Total:      5,
+			Enabled:    4,
+			Valid:      1,
+			Expiring:   2,
+			Expired:    1,
+			Missing:    1,
+			ExpiryDays: 14,
+			Duplicates: []string{},
+		}, summary)
+	})
+
+	t.Run("ShortThreshold", func(t *testing.T) {
+		summary, err := s.Summary(day)
+		require.NoError(t, err)
+		require.Equal(t, 3, summary.Valid)
+		require.Equal(t, 0, summary.Expiring)
+		require.Equal(t, 1, summary.Expired)
+		require.Equal(t, 1, summary.ExpiryDays)
+	})
+
+	t.Run("UnreadableCertificate", func(t *testing.T) {
+		file, err := s.DehydratedConfig.CertFile("expiring.example.com")
+		require.NoError(t, err)
+		require.NoError(t, os.WriteFile(file, []byte("garbage"), 0o600))
+
+		summary, err := s.Summary(14 * day)
+		require.NoError(t, err)
+		require.Equal(t, 1, summary.Expiring)
+		require.Equal(t, 2, summary.Missing)
+	})
+
+	t.Run("Duplicates", func(t *testing.T) {
+		dc := dehydrated.NewConfig().WithBaseDir(t.TempDir()).Load()
+		content := "example.com\nexample.com www.example.com\nexample.com > cert\n# example.com > cert\nexample.org\nexample.com\n"
+		require.NoError(t, os.WriteFile(dc.DomainsFile, []byte(content), 0o600))
+		s := NewDomainService(dc, nil)
+		defer s.Close()
+
+		summary, err := s.Summary(14 * day)
+		require.NoError(t, err)
+		require.Equal(t, 6, summary.Total)
+		require.Equal(t, []string{"example.com", "example.com > cert"}, summary.Duplicates)
+	})
+}
+
+func TestReconcile(t *testing.T) {
+	t.Run("Drift", func(t *testing.T) {
+		s := newCertTestService(t)
+
+		// A certificate directory without a certificate, one left behind by a removed entry and a stray file
+		dir, err := s.DehydratedConfig.CertPath("issuing.example.com")
+		require.NoError(t, err)
+		require.NoError(t, os.MkdirAll(dir, 0o755))
+		_, err = s.CreateDomain(&model.CreateDomainRequest{Domain: "issuing.example.com", Enabled: util.BoolPtr(true)})
+		require.NoError(t, err)
+		file, err := s.DehydratedConfig.CertFile("removed.example.com")
+		require.NoError(t, err)
+		testutil.WriteCert(t, file, "removed.example.com", time.Now().Add(30*day))
+		require.NoError(t, os.WriteFile(filepath.Join(s.DehydratedConfig.CertDir, "README"), nil, 0o600))
+
+		// Disabled entries are neither missing nor are their certificates orphans
+		_, err = s.CreateDomain(&model.CreateDomainRequest{Domain: "disabled.example.com", Enabled: util.BoolPtr(false)})
+		require.NoError(t, err)
+
+		result, err := s.Reconcile()
+		require.NoError(t, err)
+		require.Equal(t, &model.Reconciliation{
+			MissingCerts: []string{"issuing.example.com", "missing.example.com"},
+			OrphanCerts:  []string{"removed.example.com"},
+		}, result)
+	})
+
+	t.Run("NoCertDir", func(t *testing.T) {
+		dc := dehydrated.NewConfig().WithBaseDir(t.TempDir()).Load()
+		s := NewDomainService(dc, nil)
+		defer s.Close()
+
+		result, err := s.Reconcile()
+		require.NoError(t, err)
+		require.Equal(t, &model.Reconciliation{MissingCerts: []string{}, OrphanCerts: []string{}}, result)
+
+		_, err = s.CreateDomain(&model.CreateDomainRequest{Domain: "example.com", Alias: "cert", Enabled: util.BoolPtr(true)})
+		require.NoError(t, err)
+
+		result, err = s.Reconcile()
+		require.NoError(t, err)
+		require.Equal(t, []string{"cert"}, result.MissingCerts)
+		require.Empty(t, result.OrphanCerts)
+	})
+}
+
+// TestPluginCertExpiry verifies that the certificate status of entries without a local certificate
+// falls back to the expiry provided by plugins, while local certificates take precedence.
+func TestPluginCertExpiry(t *testing.T) {
+	r := &serviceinterface.MockPluginRegistry{
+		Clients: map[string]*serviceinterface.MockPlugin{
+			"vault": {Metadata: map[string]*structpb.Value{
+				pb.MetadataCertNotAfter: structpb.NewStringValue(time.Now().Add(5 * day).Format(time.RFC3339)),
+			}},
+			"broken": {Metadata: map[string]*structpb.Value{pb.MetadataCertNotAfter: structpb.NewStringValue("soon")}},
+		},
+		Priorities: map[string]int{"broken": 10},
+	}
+	dc := dehydrated.NewConfig().WithBaseDir(t.TempDir()).Load()
+	s := NewDomainService(dc, r)
+	defer s.Close()
+
+	for _, domain := range []string{"local.example.com", "remote.example.com"} {
+		_, err := s.CreateDomain(&model.CreateDomainRequest{Domain: domain, Enabled: util.BoolPtr(true)})
+		require.NoError(t, err)
+	}
+	file, err := dc.CertFile("local.example.com")
+	require.NoError(t, err)
+	testutil.WriteCert(t, file, "local.example.com", time.Now().Add(60*day))
+
+	summary, err := s.Summary(14 * day)
+	require.NoError(t, err)
+	require.Equal(t, 1, summary.Valid)
+	require.Equal(t, 1, summary.Expiring)
+	require.Equal(t, 0, summary.Missing)
+
+	entries, _, err := s.ListDomains(1, 10, "", "", serviceinterface.WithCertStatus(dehydrated.CertStatusExpiring, 14*day))
+	require.NoError(t, err)
+	require.Len(t, entries, 1)
+	require.Equal(t, "remote.example.com", entries[0].Domain)
+
+	// Without a value from a plugin, the certificate is missing
+	r.Clients["vault"].Metadata = nil
+	summary, err = s.Summary(14 * day)
+	require.NoError(t, err)
+	require.Equal(t, 1, summary.Valid)
+	require.Equal(t, 1, summary.Missing)
+}
+
+func TestListDomainsCertStatus(t *testing.T) {
+	s := newCertTestService(t)
+
+	// pathNames returns the path names of the entries listed with opts
+	pathNames := func(t *testing.T, search string, opts ...serviceinterface.QueryOption) []string {
+		t.Helper()
+		entries, pagination, err := s.ListDomains(1, 100, "asc", search, opts...)
+		require.NoError(t, err)
+		require.Len(t, entries, pagination.Total)
+
+		names := make([]string, 0, len(entries))
+		for _, entry := range entries {
+			names = append(names, entry.PathName())
+		}
+		return names
+	}
+
+	tests := []struct {
+		name     string
+		search   string
+		opts     []serviceinterface.QueryOption
+		expected []string
+	}{
+		{
+			name:     "Valid",
+			opts:     []serviceinterface.QueryOption{serviceinterface.WithCertStatus(dehydrated.CertStatusValid, 14*day)},
+			expected: []string{"valid.example.com"},
+		},
+		{
+			name:     "Expiring",
+			opts:     []serviceinterface.QueryOption{serviceinterface.WithCertStatus(dehydrated.CertStatusExpiring, 14*day)},
+			expected: []string{"expiring.example.com", "valid.example.com-ecdsa"},
+		},
+		{
+			name:     "ExpiringShortThreshold",
+			opts:     []serviceinterface.QueryOption{serviceinterface.WithCertStatus(dehydrated.CertStatusExpiring, 8*day)},
+			expected: []string{"expiring.example.com"},
+		},
+		{
+			name:     "Expired",
+			opts:     []serviceinterface.QueryOption{serviceinterface.WithCertStatus(dehydrated.CertStatusExpired, 14*day)},
+			expected: []string{"expired.example.com"},
+		},
+		{
+			name:     "Missing",
+			opts:     []serviceinterface.QueryOption{serviceinterface.WithCertStatus(dehydrated.CertStatusMissing, 14*day)},
+			expected: []string{"missing.example.com"},
+		},
+		{
+			name:     "WithSearch",
+			search:   "valid",
+			opts:     []serviceinterface.QueryOption{serviceinterface.WithCertStatus(dehydrated.CertStatusExpiring, 14*day)},
+			expected: []string{"valid.example.com-ecdsa"},
+		},
+		{
+			name: "WithEnabled",
+			opts: []serviceinterface.QueryOption{
+				serviceinterface.WithEnabled(false),
+				serviceinterface.WithCertStatus(dehydrated.CertStatusExpired, 14*day),
+			},
+			expected: []string{"expired.example.com"},
+		},
+		{
+			name: "NoMatch",
+			opts: []serviceinterface.QueryOption{
+				serviceinterface.WithEnabled(true),
+				serviceinterface.WithCertStatus(dehydrated.CertStatusExpired, 14*day),
+			},
+			expected: []string{},
+		},
+		{
+			name:     "EnabledOnly",
+			opts:     []serviceinterface.QueryOption{serviceinterface.WithEnabled(false)},
+			expected: []string{"expired.example.com"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require.Equal(t, tt.expected, pathNames(t, tt.search, tt.opts...))
+		})
+	}
+}
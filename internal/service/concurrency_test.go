@@ -0,0 +1,182 @@
+package service
+
+import (
+	"fmt"
+	"os"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/schumann-it/dehydrated-api-go/internal/dehydrated"
+	"github.com/schumann-it/dehydrated-api-go/internal/model"
+	serviceinterface "github.com/schumann-it/dehydrated-api-go/internal/service/interface"
+	"github.com/schumann-it/dehydrated-api-go/internal/util"
+)
+
+// TestConcurrentReloadAndMutations runs mutations concurrently with external edits of the domains file
+// and reloads, and verifies that no change made through the API is lost or reverted. Run it with -race.
+func TestConcurrentReloadAndMutations(t *testing.T) {
+	dc := dehydrated.NewConfig().WithBaseDir(t.TempDir()).Load()
+	s := NewDomainService(dc, nil).WithFileWatcher()
+	t.Cleanup(func() { _ = s.Close() })
+	require.NoError(t, s.Reload())
+
+	const writers, perWriter = 4, 15
+	domain := func(w, i int) string { return fmt.Sprintf("api-%d-%d.example.com", w, i) }
+
+	stop := make(chan struct{})
+	var background sync.WaitGroup
+
+	// External edits append lines, like an administrator adding domains. They may be overwritten
+	// by a concurrent write of the service, but never remove entries written by the service.
+	background.Add(1)
+	go func() {
+		defer background.Done()
+		for i := 0; ; i++ {
+			select {
+			case <-stop:
+				return
+			default:
+			}
+			f, err := os.OpenFile(dc.DomainsFile, os.O_APPEND|os.O_WRONLY, 0644)
+			if err == nil {
+				_, _ = fmt.Fprintf(f, "external-%d.example.com\n", i)
+				_ = f.Close()
+			}
+			time.Sleep(time.Millisecond)
+		}
+	}()
+
+	// Reloads in addition to the ones triggered by the file watcher
+	background.Add(1)
+	go func() {
+		defer background.Done()
+		for {
+			select {
+			case <-stop:
+				return
+			default:
+			}
+			_ = s.Reload()
+			time.Sleep(time.Millisecond)
+		}
+	}()
+
+	// Each writer creates its entries, updates every third and deletes every third
+	errs := make(chan error, writers)
+	var wg sync.WaitGroup
+	for w := range writers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range perWriter {
+				if _, err := s.CreateDomain(&model.CreateDomainRequest{Domain: domain(w, i), Enabled: util.BoolPtr(true)}); err != nil {
+					errs <- fmt.Errorf("create %s: %w", domain(w, i), err)
+					return
+				}
+				switch i % 3 {
+				case 1:
+					comment := "updated"
+					if _, err := s.UpdateDomain(domain(w, i), model.UpdateDomainRequest{Comment: &comment}); err != nil {
+						errs <- fmt.Errorf("update %s: %w", domain(w, i), err)
+						return
+					}
+				case 2:
+					if err := s.DeleteDomain(domain(w, i), model.DeleteDomainRequest{}); err != nil {
+						errs <- fmt.Errorf("delete %s: %w", domain(w, i), err)
+						return
+					}
+				}
+			}
+		}()
+	}
+	wg.Wait()
+	close(stop)
+	background.Wait()
+	close(errs)
+	for err := range errs {
+		require.NoError(t, err)
+	}
+
+	verify := func(t *testing.T) {
+		entries, err := ReadDomainsFile(dc.DomainsFile)
+		require.NoError(t, err)
+		written := make(map[string]string, len(entries))
+		for _, entry := range entries {
+			written[entry.Domain] = entry.Comment
+		}
+
+		for w := range writers {
+			for i := range perWriter {
+				entry, err := s.GetDomain(domain(w, i), "")
+				comment, ok := written[domain(w, i)]
+				switch i % 3 {
+				case 0:
+					require.NoError(t, err, domain(w, i))
+					require.True(t, ok, "%s not in domains file", domain(w, i))
+				case 1:
+					require.NoError(t, err, domain(w, i))
+					require.Equal(t, "updated", entry.Comment, domain(w, i))
+					require.Equal(t, "updated", comment, domain(w, i))
+				case 2:
+					require.ErrorIs(t, err, serviceinterface.ErrDomainNotFound, domain(w, i))
+					require.False(t, ok, "%s resurrected in domains file", domain(w, i))
+				}
+			}
+		}
+	}
+
+	t.Run("Cache", verify)
+
+	require.NoError(t, s.Reload())
+	t.Run("Reloaded", verify)
+}
+
+// TestConcurrentPatches verifies that concurrent patches of the same entry don't lose each other's changes.
+func TestConcurrentPatches(t *testing.T) {
+	dc := dehydrated.NewConfig().WithBaseDir(t.TempDir()).Load()
+	s := NewDomainService(dc, nil)
+	t.Cleanup(func() { _ = s.Close() })
+	_, err := s.CreateDomain(&model.CreateDomainRequest{Domain: "example.com", Enabled: util.BoolPtr(true)})
+	require.NoError(t, err)
+
+	const patches = 20
+	errs := make(chan error, patches)
+	var wg sync.WaitGroup
+	for i := range patches {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, err := s.PatchDomain("example.com", "", func(entry *model.DomainEntry) error {
+				entry.AlternativeNames = append(entry.AlternativeNames, fmt.Sprintf("www%d.example.com", i))
+				return nil
+			})
+			errs <- err
+		}()
+	}
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		require.NoError(t, err)
+	}
+
+	entry, err := s.GetDomain("example.com", "", serviceinterface.WithoutMetadata())
+	require.NoError(t, err)
+	require.Len(t, entry.AlternativeNames, patches)
+
+	// Errors of the patch are returned as is and nothing is written
+	errPatch := fmt.Errorf("patch failed")
+	_, err = s.PatchDomain("example.com", "", func(entry *model.DomainEntry) error {
+		entry.Enabled = false
+		return errPatch
+	})
+	require.ErrorIs(t, err, errPatch)
+	entry, err = s.GetDomain("example.com", "", serviceinterface.WithoutMetadata())
+	require.NoError(t, err)
+	require.True(t, entry.Enabled)
+
+	_, err = s.PatchDomain("missing.example.com", "", func(*model.DomainEntry) error { return nil })
+	require.ErrorIs(t, err, serviceinterface.ErrDomainNotFound)
+}
@@ -5,18 +5,23 @@ package service
 import (
 	"context"
 	"errors"
+	"fmt"
 	"os"
 	"path/filepath"
-	"sort"
+	"slices"
 	"strings"
 	"sync"
+	"time"
 
-	"github.com/schumann-it/dehydrated-api-go/internal/plugin/registry"
+	serviceinterface "github.com/schumann-it/dehydrated-api-go/internal/service/interface"
 	"github.com/schumann-it/dehydrated-api-go/internal/util"
 
 	pb "github.com/schumann-it/dehydrated-api-go/plugin/proto"
 	"go.uber.org/zap"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
 
+	"github.com/schumann-it/dehydrated-api-go/internal/auth"
 	"github.com/schumann-it/dehydrated-api-go/internal/dehydrated"
 	"github.com/schumann-it/dehydrated-api-go/internal/model"
 )
@@ -27,15 +32,45 @@ type DomainService struct {
 	DehydratedConfig *dehydrated.Config   // Path to the domains.txt file
 	watcher          *FileWatcher         // File watcher for monitoring changes
 	cache            []*model.DomainEntry // In-memory cache of domain entries
+	loaded           bool                 // Whether the cache has been loaded from the domains file successfully
 	mutex            sync.RWMutex         // Mutex for thread-safe access to the cache
 	logger           *zap.Logger
-	registry         *registry.Registry
+	registry         *pluginRegistry           // Plugin registry in use, replaced by ReplaceRegistry
+	registryMu       sync.Mutex                // Guards the registry, independent of the mutex so replacing it does not wait for requests
+	commentMarker    string                    // Marker prepended to the comment of created entries
+	defaultEnabled   bool                      // Enabled state of created entries if the request omits it
+	certs            *dehydrated.CertInfoCache // Cache of the certificate information of the entries
+	dehydratedScript string                    // Path of the dehydrated script run for OCSP refreshes
+	batcher          *writeBatcher             // Coalesces writes of the domains file, nil if disabled
+	integrity        *integrityChecker         // Periodic integrity check of the domains file, nil if disabled
+	changeSeq        uint64                    // Incremented on every change of the cache, guarded by the mutex
+	durableWrites    bool                      // Whether mutations wait for coalesced writes
+	pluginErrors     *pluginErrorLog           // Recent errors returned by plugins
+	maxCommentLength int                       // Maximum length of comments set via the API, unlimited if not positive
+	maxAliasLength   int                       // Maximum length of aliases set via the API, unlimited if not positive
+	permissions      *FilePermissions          // Mode and ownership applied on writes, nil if unchanged
+	sidecarMetadata  bool                      // Whether metadata is read from sidecar files
+	omitNewline      bool                      // Whether the newline after the last line of the domains file is omitted
+	sortAltNames     bool                      // Whether alternative names are sorted alphabetically instead of kept in input order
+	challengeTypes   []string                  // Challenge types allowed for certificates, all if empty
+	allowedSuffixes  []string                  // Domain suffixes names of entries must match, all if empty
+	deniedSuffixes   []string                  // Domain suffixes names of entries must not match
+	mergeMetadata    bool                      // Merge the metadata of plugins instead of namespacing it by plugin
+	largeIntegers    bool                      // Render large integers of plugin metadata as JSON numbers
+	watcherGrace     time.Duration             // Time after a mutation during which events of the file watcher are ignored
+	caProfiles       map[string]string         // CA of dehydrated by profile name, entries cannot select a CA if empty
+	warnEntries      int                       // Number of entries above which a warning is logged, disabled if not positive
+	maxEntries       int                       // Number of entries beyond which creates are rejected, unlimited if not positive
 }
 
 // NewDomainService creates a new DomainService instance with the provided configuration.
 // It initializes the dehydrated client, sets up the plugin registry, and optionally
 // enables file watching for automatic updates.
-func NewDomainService(cfg *dehydrated.Config, r *registry.Registry) *DomainService {
+func NewDomainService(cfg *dehydrated.Config, r serviceinterface.PluginRegistry) *DomainService {
+	if r == nil {
+		r = noPlugins{}
+	}
+
 	// Ensure the domains file exists
 	if _, err := os.Stat(cfg.DomainsFile); err != nil {
 		// Create the directory if it doesn't exist
@@ -51,8 +86,15 @@ func NewDomainService(cfg *dehydrated.Config, r *registry.Registry) *DomainServi
 
 	s := &DomainService{
 		logger:           zap.NewNop(),
-		registry:         r,
+		registry:         newPluginRegistry(r),
 		DehydratedConfig: cfg,
+		certs:            dehydrated.NewCertInfoCache(),
+		dehydratedScript: DefaultDehydratedScript,
+		pluginErrors:     newPluginErrorLog(DefaultPluginErrorLimit),
+		maxCommentLength: model.DefaultMaxCommentLength,
+		maxAliasLength:   model.DefaultMaxAliasLength,
+		warnEntries:      DefaultWarnEntries,
+		maxEntries:       DefaultMaxEntries,
 	}
 
 	return s
@@ -63,6 +105,94 @@ func (s *DomainService) WithLogger(l *zap.Logger) *DomainService {
 	return s
 }
 
+// WithCommentMarker sets a marker that is prepended to the comment of entries created via CreateDomain.
+func (s *DomainService) WithCommentMarker(marker string) *DomainService {
+	s.commentMarker = strings.TrimSpace(marker)
+	return s
+}
+
+// WithDefaultEnabled sets the enabled state of entries created without one, false by default.
+func (s *DomainService) WithDefaultEnabled(enabled bool) *DomainService {
+	s.defaultEnabled = enabled
+	return s
+}
+
+// WithMaxCommentLength sets the maximum length of comments set via the API in characters.
+// Non-positive values disable the limit.
+func (s *DomainService) WithMaxCommentLength(maxLength int) *DomainService {
+	s.maxCommentLength = maxLength
+	return s
+}
+
+// WithMaxAliasLength sets the maximum length of aliases set via the API in characters.
+// Non-positive values disable the limit.
+func (s *DomainService) WithMaxAliasLength(maxLength int) *DomainService {
+	s.maxAliasLength = maxLength
+	return s
+}
+
+// DefaultDehydratedScript is the dehydrated script run by default, looked up in PATH.
+const DefaultDehydratedScript = "dehydrated"
+
+// WithDehydratedScript sets the path of the dehydrated script run by RefreshOCSP.
+func (s *DomainService) WithDehydratedScript(script string) *DomainService {
+	s.dehydratedScript = script
+	return s
+}
+
+// WithPluginErrorLimit sets the number of recent plugin errors retained for PluginErrors.
+// Non-positive values select DefaultPluginErrorLimit.
+func (s *DomainService) WithPluginErrorLimit(limit int) *DomainService {
+	s.pluginErrors = newPluginErrorLog(limit)
+	return s
+}
+
+// WithoutTrailingNewline omits the newline after the last line of the domains file.
+func (s *DomainService) WithoutTrailingNewline() *DomainService {
+	s.omitNewline = true
+	return s
+}
+
+// WithSortedAlternativeNames sorts the alternative names of entries alphabetically instead of keeping the order
+// they were given in. Entries are sorted when they are loaded and when they are created or changed, so an entry whose
+// alternative names are only reordered is unchanged. The domains file is written in that canonical order.
+func (s *DomainService) WithSortedAlternativeNames() *DomainService {
+	s.sortAltNames = true
+	return s
+}
+
+// WithMergedMetadata merges the metadata of all plugins into a single map instead of namespacing it
+// by plugin name. If plugins set the same key, the value of the plugin called first wins, i.e., the one
+// with the highest priority, see PluginRegistry.Order. Errors of plugins are still set under their name.
+func (s *DomainService) WithMergedMetadata() *DomainService {
+	s.mergeMetadata = true
+	return s
+}
+
+// WithLargeIntegerMetadata renders integers beyond ±2^53 in the metadata of plugins as JSON numbers without
+// losing precision, instead of the strings plugins send them as, see pb.Metadata.WithLargeIntegers.
+func (s *DomainService) WithLargeIntegerMetadata() *DomainService {
+	s.largeIntegers = true
+	return s
+}
+
+// WithAllowedChallengeTypes restricts the challenge types of certificates, configured globally or overridden
+// in their config file. Entries whose certificate uses a type not in types are rejected on creation and update.
+func (s *DomainService) WithAllowedChallengeTypes(types []string) *DomainService {
+	s.challengeTypes = types
+	return s
+}
+
+// WithWriteCoalescing coalesces writes of the domains file. Mutations update the cache and return,
+// the domains file is written at most once per interval, or immediately once maxPending changes
+// are pending. If durable is set, mutations wait until their change has been written.
+// Zero values select DefaultCoalesceInterval and DefaultCoalesceMaxPending.
+func (s *DomainService) WithWriteCoalescing(interval time.Duration, maxPending int, durable bool) *DomainService {
+	s.batcher = newWriteBatcher(interval, maxPending, s.writeEntriesToFile, s.logger)
+	s.durableWrites = durable
+	return s
+}
+
 func (s *DomainService) WithFileWatcher() *DomainService {
 	s.logger.Info("Enabling file watcher")
 
@@ -71,7 +201,7 @@ func (s *DomainService) WithFileWatcher() *DomainService {
 		s.logger.Error("Failed to set up file watcher", zap.Error(err))
 		return s
 	}
-	watcher.WithLogger(s.logger)
+	watcher.WithLogger(s.logger).WithGracePeriod(s.watcherGrace)
 	s.watcher = watcher
 	s.watcher.Watch()
 
@@ -80,11 +210,109 @@ func (s *DomainService) WithFileWatcher() *DomainService {
 	return s
 }
 
+// WithWatcherGracePeriod ignores events of the file watcher for the duration after a mutation re-enabled it,
+// so a late event of the service's own write does not reload the data just written, see
+// FileWatcher.WithGracePeriod. Disabled if zero.
+func (s *DomainService) WithWatcherGracePeriod(d time.Duration) *DomainService {
+	s.watcherGrace = d
+	if s.watcher != nil {
+		s.watcher.WithGracePeriod(d)
+	}
+	return s
+}
+
+// Watcher returns the file watcher of the domains file, nil if not enabled.
+func (s *DomainService) Watcher() *FileWatcher {
+	return s.watcher
+}
+
+// noPlugins is the PluginRegistry used when the DomainService is created without one.
+type noPlugins struct{}
+
+func (noPlugins) Plugins() map[string]pb.PluginClient    { return map[string]pb.PluginClient{} }
+func (noPlugins) Validators() map[string]pb.PluginClient { return map[string]pb.PluginClient{} }
+func (noPlugins) Capabilities(string) []string           { return nil }
+func (noPlugins) Order() []string                        { return nil }
+func (noPlugins) Failures() []*model.PluginFailure       { return nil }
+func (noPlugins) Close()                                 {}
+
+// pluginRegistry is a plugin registry in use by the service. It counts the requests calling its plugins,
+// so it is only closed once they are done after it was replaced.
+type pluginRegistry struct {
+	serviceinterface.PluginRegistry
+	users sync.WaitGroup
+}
+
+// newPluginRegistry returns r in use by the service, one without plugins if nil.
+func newPluginRegistry(r serviceinterface.PluginRegistry) *pluginRegistry {
+	if r == nil {
+		r = noPlugins{}
+	}
+	return &pluginRegistry{PluginRegistry: r}
+}
+
+// supports reports whether the named plugin advertised the capability, i.e., implements the optional RPC.
+func (r *pluginRegistry) supports(name, capability string) bool {
+	return slices.Contains(r.Capabilities(name), capability)
+}
+
+// currentRegistry returns the plugin registry, e.g., to list its plugins without calling them.
+func (s *DomainService) currentRegistry() *pluginRegistry {
+	s.registryMu.Lock()
+	defer s.registryMu.Unlock()
+	return s.registry
+}
+
+// acquireRegistry returns the plugin registry for calls to its plugins and a function to release it
+// once they are done. A registry is not closed while it is acquired.
+func (s *DomainService) acquireRegistry() (*pluginRegistry, func()) {
+	s.registryMu.Lock()
+	defer s.registryMu.Unlock()
+
+	r := s.registry
+	r.users.Add(1)
+	return r, r.users.Done
+}
+
+// ReplaceRegistry swaps the plugin registry used for metadata enrichment and validation
+// and closes the previous one once no request is using it anymore.
+// Requests in flight finish with the plugins of the previous registry, later ones use r.
+func (s *DomainService) ReplaceRegistry(r serviceinterface.PluginRegistry) {
+	replacement := newPluginRegistry(r)
+
+	s.registryMu.Lock()
+	old := s.registry
+	s.registry = replacement
+	s.registryMu.Unlock()
+
+	s.logger.Info("Plugin registry replaced", zap.Int("plugins", len(replacement.Plugins())))
+
+	go func() {
+		old.users.Wait()
+		old.Close()
+		s.logger.Info("Previous plugin registry closed")
+	}()
+}
+
 // Reload reloads the domain entries from the file into the cache.
 // This method is called during initialization and when file changes are detected.
+// The file is read while holding the mutex, so a reload is serialized with the mutations
+// and can neither read a partially written file nor replace changes made meanwhile.
 func (s *DomainService) Reload() error {
 	s.logger.Info("Reloading domains file")
 
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	// Changes scheduled but not yet written must not be replaced by the file. Writes are
+	// scheduled with the mutex held, so none can start until the file has been read.
+	if s.batcher != nil {
+		if dirty, _ := s.batcher.state(); dirty {
+			s.logger.Info("Skipping reload, changes are pending to be written")
+			return nil
+		}
+	}
+
 	entries, err := ReadDomainsFile(s.DehydratedConfig.DomainsFile)
 	if err != nil {
 		s.logger.Error("Failed to read domains file", zap.Error(err))
@@ -94,12 +322,62 @@ func (s *DomainService) Reload() error {
 	// Convert entries to pointers (entries can be empty slice, which is valid)
 	pointerEntries := make([]*model.DomainEntry, len(entries))
 	copy(pointerEntries, entries)
+	for _, entry := range pointerEntries {
+		s.canonicalize(entry)
+	}
+
+	// Entries are addressed by domain and alias, so duplicates in the file are ambiguous.
+	// Only the first one is reachable through the API, they are reported by Summary.
+	for _, duplicate := range duplicates(pointerEntries) {
+		s.logger.Warn("Duplicate domain entry in domains file, only the first one is used", zap.String("entry", duplicate))
+	}
+
+	// Entries cannot be rejected here, a challenge type that is not allowed only blocks changes via the API
+	for _, entry := range pointerEntries {
+		if err := s.DehydratedConfig.DomainSpecificConfig(entry.PathName()).CheckChallengeType(s.challengeTypes); err != nil {
+			s.logger.Warn("Certificate config of domain entry is not allowed",
+				zap.String("domain", entry.Domain), zap.String("alias", entry.Alias), zap.Error(err))
+		}
+	}
 
-	s.mutex.Lock()
 	s.cache = pointerEntries
-	s.mutex.Unlock()
+	s.loaded = true
+	s.changeSeq++
 
 	s.logger.Info("Entries reloaded", zap.Int("count", len(pointerEntries)))
+	s.warnEntryCount(len(pointerEntries))
+	return nil
+}
+
+// ensureLoaded loads the domains file into the cache if it has never been loaded successfully, e.g., if a request
+// arrives before the initial Reload or that failed. It returns serviceinterface.ErrCacheUnavailable if the file cannot
+// be read, instead of serving an empty cache.
+func (s *DomainService) ensureLoaded() error {
+	s.mutex.RLock()
+	loaded := s.loaded
+	s.mutex.RUnlock()
+	if loaded {
+		return nil
+	}
+
+	if err := s.Reload(); err != nil {
+		return fmt.Errorf("%w: %w", serviceinterface.ErrCacheUnavailable, err)
+	}
+	return nil
+}
+
+// CheckNotEmpty returns serviceinterface.ErrNoDomains if the domains file has no entries, for deployments
+// that consider an empty file a misconfiguration, or serviceinterface.ErrCacheUnavailable if it cannot be read.
+func (s *DomainService) CheckNotEmpty() error {
+	if err := s.ensureLoaded(); err != nil {
+		return err
+	}
+
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+	if len(s.cache) == 0 {
+		return fmt.Errorf("%w: %s", serviceinterface.ErrNoDomains, s.DehydratedConfig.DomainsFile)
+	}
 	return nil
 }
 
@@ -108,16 +386,23 @@ func (s *DomainService) Reload() error {
 func (s *DomainService) Close() error {
 	s.logger.Info("Closing domain service")
 
+	s.closeIntegrityCheck()
+
 	if s.watcher != nil {
 		if err := s.watcher.Close(); err != nil {
 			s.logger.Error("Failed to  close watcher", zap.Error(err))
 		}
 	}
 
-	if s.registry != nil {
-		s.registry.Close()
+	// Write changes that are still pending
+	if s.batcher != nil {
+		if err := s.batcher.close(); err != nil {
+			s.logger.Error("Failed to write pending changes", zap.Error(err))
+		}
 	}
 
+	s.currentRegistry().Close()
+
 	s.logger.Sync()
 
 	return nil
@@ -125,6 +410,7 @@ func (s *DomainService) Close() error {
 
 // findDomainEntry finds a domain entry in the cache by domain and optional alias.
 // If alias is empty, it looks for entries without an alias.
+// The pair of domain and alias is unique, CreateDomain rejects entries colliding with an existing one.
 func (s *DomainService) findDomainEntry(domain, alias string) (*model.DomainEntry, int) {
 	for i, entry := range s.cache {
 		if entry.Domain == domain && entry.Alias == alias {
@@ -134,26 +420,6 @@ func (s *DomainService) findDomainEntry(domain, alias string) (*model.DomainEntr
 	return nil, -1
 }
 
-// writeCacheToFile writes the current cache to the domains file.
-// It converts pointer entries to values for file writing.
-func (s *DomainService) writeCacheToFile() error {
-	entries := make(model.DomainEntries, 0, len(s.cache))
-	for _, entry := range s.cache {
-		entries = append(entries, &model.DomainEntry{
-			DomainEntry: pb.DomainEntry{
-				Domain:           entry.Domain,
-				AlternativeNames: entry.AlternativeNames,
-				Alias:            entry.Alias,
-				Enabled:          entry.Enabled,
-				Comment:          entry.Comment,
-			},
-		})
-	}
-
-	s.logger.Info("Dumping domains to disk", zap.Int("count", len(s.cache)))
-	return WriteDomainsFile(s.DehydratedConfig.DomainsFile, entries)
-}
-
 // writeEntriesToFile writes a specific set of domain entries to the domains file.
 // It converts pointer entries to values for file writing.
 func (s *DomainService) writeEntriesToFile(entries []*model.DomainEntry) error {
@@ -168,11 +434,120 @@ func (s *DomainService) writeEntriesToFile(entries []*model.DomainEntry) error {
 				Enabled:          entry.Enabled,
 				Comment:          entry.Comment,
 			},
+			Options: entry.Options,
 		})
 	}
 
 	s.logger.Info("Dumping domains to disk", zap.Int("count", len(entries)))
-	return WriteDomainsFile(s.DehydratedConfig.DomainsFile, valueEntries)
+	var opts []WriteOption
+	if s.omitNewline {
+		opts = append(opts, WithoutTrailingNewline())
+	}
+	if s.sortAltNames {
+		opts = append(opts, WithSortedAlternativeNames())
+	}
+	if s.permissions != nil {
+		s.applyDirPermissions()
+		opts = append(opts, withBeforeWrite(s.applyFilePermissions))
+	}
+	return WriteDomainsFile(s.DehydratedConfig.DomainsFile, valueEntries, opts...)
+}
+
+// persist writes entries to the domains file, or schedules the write if write coalescing is enabled.
+// The mutex must be held. A scheduled write is reported on the returned channel, see awaitWrite.
+// As entries replace the cache unless persisting fails, it increments the change sequence on success.
+func (s *DomainService) persist(entries []*model.DomainEntry) (<-chan error, error) {
+	if s.batcher == nil {
+		if err := s.writeEntriesToFile(entries); err != nil {
+			return nil, err
+		}
+		s.changeSeq++
+		return nil, nil
+	}
+	s.changeSeq++
+	return s.batcher.schedule(entries), nil
+}
+
+// ChangeSeq returns the change sequence, which is incremented on every change of the entries via the API and on
+// every reload of the domains file. Clients can compare it to detect changes without comparing the entries.
+// It starts at 0 and is not persisted, so it is reset by a restart.
+func (s *DomainService) ChangeSeq() uint64 {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+	return s.changeSeq
+}
+
+// awaitWrite waits for the write scheduled by persist if durable writes are enabled.
+// It must be called without holding the mutex, so other changes can be coalesced meanwhile.
+// A failed write remains pending and is retried, the change is kept in the cache.
+func (s *DomainService) awaitWrite(done <-chan error) error {
+	if done == nil || !s.durableWrites {
+		return nil
+	}
+	if err := <-done; err != nil {
+		s.logger.Error("Failed to write domains file", zap.Error(err))
+		return err
+	}
+	return nil
+}
+
+// canonicalize sorts the alternative names of entry if WithSortedAlternativeNames is set. The names are sorted
+// in a copy, as the slice may be shared with a request or another entry.
+func (s *DomainService) canonicalize(entry *model.DomainEntry) {
+	if s.sortAltNames && !slices.IsSorted(entry.AlternativeNames) {
+		entry.AlternativeNames = slices.Sorted(slices.Values(entry.AlternativeNames))
+	}
+}
+
+// validateEntry canonicalizes the alternative names and trims the alias and comment of an entry created or changed
+// via the API and validates it.
+// The alias and comment are only validated if they differ from existing, so entries written manually
+// to the domains file can still be changed otherwise. The challenge type of the entry's certificate must
+// be allowed. It returns an error wrapping ErrInvalidDomainEntry. The validation by the plugins is left to
+// withPluginValidation, as it must not happen while holding the mutex.
+func (s *DomainService) validateEntry(entry, existing *model.DomainEntry) error {
+	s.canonicalize(entry)
+	entry.Alias = strings.TrimSpace(entry.Alias)
+	entry.Comment = strings.TrimSpace(entry.Comment)
+
+	if !model.IsValidDomainEntry(entry) {
+		return &serviceinterface.FieldError{Field: "domain", Reason: fmt.Sprintf("invalid domain name %q", entry.Domain)}
+	}
+	if existing == nil || !slices.Equal(entry.AlternativeNames, existing.AlternativeNames) {
+		if err := model.CheckAlternativeNames(entry.Domain, entry.AlternativeNames); err != nil {
+			return &serviceinterface.FieldError{Field: "alternative_names", Reason: err.Error()}
+		}
+	}
+	if existing == nil || entry.Domain != existing.Domain || !slices.Equal(entry.AlternativeNames, existing.AlternativeNames) {
+		if err := s.checkDomainSuffixes(entry); err != nil {
+			return err
+		}
+	}
+	if existing == nil || entry.Alias != existing.Alias {
+		if !model.IsValidAlias(entry.Alias, 0) {
+			return &serviceinterface.FieldError{Field: "alias", Reason: "alias may only contain letters, digits, '.', '-' and '_' and must not start with '.'"}
+		}
+		if !model.IsValidAlias(entry.Alias, s.maxAliasLength) {
+			return &serviceinterface.FieldError{Field: "alias", Reason: fmt.Sprintf("alias exceeds %d characters", s.maxAliasLength)}
+		}
+	}
+	if existing == nil || entry.Comment != existing.Comment {
+		if !model.IsValidComment(entry.Comment, 0) {
+			return &serviceinterface.FieldError{Field: "comment", Reason: "comment must be a single line without control characters"}
+		}
+		if !model.IsValidComment(entry.Comment, s.maxCommentLength) {
+			return &serviceinterface.FieldError{Field: "comment", Reason: fmt.Sprintf("comment exceeds %d characters", s.maxCommentLength)}
+		}
+	}
+	if entry.CA != "" && (existing == nil || entry.CA != existing.CA) {
+		if err := s.checkCAProfile(entry.CA); err != nil {
+			return err
+		}
+	}
+	if err := s.DehydratedConfig.DomainSpecificConfig(entry.PathName()).CheckChallengeType(s.challengeTypes); err != nil {
+		return fmt.Errorf("%w: certificate %s: %w", serviceinterface.ErrInvalidDomainEntry, entry.PathName(), err)
+	}
+	return nil
 }
 
 // updateEntry creates a new domain entry with updated fields from the request.
@@ -193,6 +568,11 @@ func updateEntry(entry *model.DomainEntry, req model.UpdateDomainRequest) *model
 		comment = util.String(req.Comment)
 	}
 
+	ca := entry.CA
+	if req.CA != nil {
+		ca = util.String(req.CA)
+	}
+
 	return &model.DomainEntry{
 		DomainEntry: pb.DomainEntry{
 			Domain:           entry.Domain,
@@ -201,6 +581,8 @@ func updateEntry(entry *model.DomainEntry, req model.UpdateDomainRequest) *model
 			Enabled:          enabled,
 			Comment:          comment,
 		},
+		CA:      ca,
+		Options: entry.Options,
 	}
 }
 
@@ -229,217 +611,583 @@ func (s *DomainService) entriesWithout(domain string, alias *string) ([]*model.D
 // CreateDomain adds a new domain entry to the domains file.
 // It validates the entry, checks for duplicates, and updates both the cache and file.
 func (s *DomainService) CreateDomain(req *model.CreateDomainRequest) (*model.DomainEntry, error) {
+	if err := s.ensureLoaded(); err != nil {
+		return nil, err
+	}
+
 	s.logger.Info("Creating domain", zap.Any("domain", req.Domain), zap.Any("req", req))
 
 	if s.watcher != nil {
 		s.watcher.Disable()
 	}
+	defer func() {
+		if s.watcher != nil {
+			s.watcher.Enable()
+		}
+	}()
+
+	var entry *model.DomainEntry
+	var done <-chan error
+	err := s.withPluginValidation(func(verdicts pluginVerdicts) (err error) {
+		entry, done, err = s.createEntry(req, verdicts)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.awaitWrite(done); err != nil {
+		return nil, err
+	}
+
+	return entry, nil
+}
+
+// createEntry validates and adds the entry to the cache and persists the change while holding the mutex.
+func (s *DomainService) createEntry(req *model.CreateDomainRequest, verdicts pluginVerdicts) (*model.DomainEntry, <-chan error, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
 
 	entry := &model.DomainEntry{
 		DomainEntry: pb.DomainEntry{
 			Domain:           req.Domain,
 			AlternativeNames: req.AlternativeNames,
 			Alias:            req.Alias,
-			Enabled:          req.Enabled,
-			Comment:          req.Comment,
+			Enabled:          s.enabledOrDefault(req.Enabled),
+			Comment:          s.markComment(req.Comment),
 		},
+		CA: req.CA,
 	}
 
 	// Validate the domain entry
-	if !model.IsValidDomainEntry(entry) {
-		s.logger.Error("Invalid domain entry", zap.Any("entry", entry))
-		return nil, errors.New("invalid domain entry")
+	if err := s.validateEntry(entry, nil); err != nil {
+		s.logger.Error("Invalid domain entry", zap.Any("entry", entry), zap.Error(err))
+		return nil, nil, err
 	}
 
-	s.mutex.Lock()
-
-	existing, _ := s.findDomainEntry(req.Domain, req.Alias)
-	if existing != nil {
-		s.mutex.Unlock()
+	if existing, _ := s.findDomainEntry(entry.Domain, entry.Alias); existing != nil {
 		s.logger.Error("Domain already exists", zap.Any("entry", entry))
-		return nil, errors.New("domain exists")
+		return nil, nil, serviceinterface.ErrDomainExists
+	}
+
+	if err := s.checkEntryLimit(len(s.cache) + 1); err != nil {
+		s.logger.Error("Too many domain entries", zap.Any("entry", entry), zap.Error(err))
+		return nil, nil, err
+	}
+
+	if err := s.pluginVerdict(verdicts, entry); err != nil {
+		return nil, nil, err
+	}
+
+	// Select the CA before the entry is written, so dehydrated never sees the entry without it
+	var previousCA string
+	if entry.CA != "" {
+		var err error
+		if previousCA, err = s.writeCA(entry, entry.CA); err != nil {
+			return nil, nil, err
+		}
 	}
 
 	// Add the new entry
 	s.cache = append(s.cache, entry)
 
 	// Write back to file
-	if err := s.writeCacheToFile(); err != nil {
+	done, err := s.persist(s.cache)
+	if err != nil {
 		// Revert cache on error
 		s.cache = s.cache[:len(s.cache)-1]
-		s.mutex.Unlock()
-		s.logger.Error("Failed to write domains file", zap.Error(err))
-		// Re-enable watcher even on error
-		if s.watcher != nil {
-			s.watcher.Enable()
+		if entry.CA != "" {
+			s.restoreCA(entry, previousCA)
 		}
-		return nil, err
+		s.logger.Error("Failed to write domains file", zap.Error(err))
+		return nil, nil, err
 	}
+	s.warnEntryCount(len(s.cache))
 
-	s.mutex.Unlock()
+	return entry, done, nil
+}
 
-	// Re-enable watcher after successful write (outside of locked section)
-	if s.watcher != nil {
-		s.watcher.Enable()
+// PreviewDomain returns the line of the domains file an entry created from req would be written as.
+// The entry is validated like by CreateDomain, but not written.
+func (s *DomainService) PreviewDomain(req *model.CreateDomainRequest) (string, error) {
+	if err := s.ensureLoaded(); err != nil {
+		return "", err
 	}
 
-	return entry, nil
+	entry := &model.DomainEntry{
+		DomainEntry: pb.DomainEntry{
+			Domain:           req.Domain,
+			AlternativeNames: req.AlternativeNames,
+			Alias:            req.Alias,
+			Enabled:          s.enabledOrDefault(req.Enabled),
+			Comment:          s.markComment(req.Comment),
+		},
+		CA: req.CA,
+	}
+
+	if err := s.validateEntry(entry, nil); err != nil {
+		return "", err
+	}
+	if err := s.validateWithPlugins(entry); err != nil {
+		return "", err
+	}
+
+	return formatLine(entry), nil
 }
 
-// enrichMetadata enriches the domain entry with metadata from all enabled plugins.
-// It calls each plugin's GetMetadata method and merges the results into the entry.
-func (s *DomainService) enrichMetadata(entry *model.DomainEntry) {
-	if entry.Metadata == nil {
-		entry.Metadata = pb.NewMetadata()
+// enabledOrDefault returns enabled if set, the default of WithDefaultEnabled otherwise.
+func (s *DomainService) enabledOrDefault(enabled *bool) bool {
+	if enabled == nil {
+		return s.defaultEnabled
 	}
+	return *enabled
+}
 
-	for name, plugin := range s.registry.Plugins() {
-		resp, err := plugin.GetMetadata(context.Background(), &pb.GetMetadataRequest{
-			DomainEntry:      &entry.DomainEntry,
-			DehydratedConfig: s.DehydratedConfig.DomainSpecificConfig(entry.PathName()).ToProto(),
-		})
+// markComment prepends the comment marker to comment, unless it is not configured or already present.
+func (s *DomainService) markComment(comment string) string {
+	comment = strings.TrimSpace(comment)
+	if s.commentMarker == "" || strings.HasPrefix(comment, s.commentMarker) {
+		return comment
+	}
+	if comment == "" {
+		return s.commentMarker
+	}
+	return s.commentMarker + " " + comment
+}
 
-		if err != nil {
-			s.logger.Error("plugin request failed", zap.String("plugin", name), zap.String("domain", entry.Domain), zap.Error(err))
-			entry.Metadata.SetMap(name, map[string]string{"error": err.Error()})
-			continue
+// enrichMetadata enriches the domain entries with metadata from all enabled plugins and the sidecar file, if enabled.
+// It calls each plugin in the order of the registry (by priority, then name) and sets the results under the
+// plugin's name, or merges them into a single map, see WithMergedMetadata. Plugins supporting GetMetadataBatch
+// are called once for all entries, the others with GetMetadata per entry, or per domain if they advertise
+// pb.CapabilityDomainMetadata.
+// If the deadline of o is set, no more plugins are called once it passed and pending calls are canceled; the entries
+// keep the metadata gathered so far and timedOut is set. With SkipDisabledMetadata, disabled entries are not passed
+// to plugins.
+// The sidecar metadata is added last, so its reserved key cannot be overwritten by a plugin.
+// It returns the errors of the failed plugins.
+func (s *DomainService) enrichMetadata(o serviceinterface.QueryOptions, entries ...*model.DomainEntry) (pluginErrors []*model.PluginError, timedOut bool) {
+	for _, entry := range entries {
+		if entry.Metadata == nil {
+			entry.Metadata = pb.NewMetadata()
 		}
-
-		if resp.Error != "" {
-			s.logger.Error("plugin request failed", zap.String("plugin", name),
-				zap.String("domain", entry.Domain), zap.Error(errors.New(resp.Error)))
-			entry.Metadata.SetMap(name, map[string]string{"error": resp.Error})
-			continue
+		if s.largeIntegers {
+			entry.Metadata.WithLargeIntegers()
 		}
-
-		if resp.Metadata != nil {
-			entry.Metadata.FromProto(name, resp.Metadata)
+		if o.Explain {
+			entry.Provenance = make(map[string]*model.MetadataProvenance)
 		}
 	}
-}
-
-// GetDomain retrieves a domain entry by its domain name.
-// It returns a copy of the entry with metadata enriched from plugins.
-func (s *DomainService) GetDomain(domain, alias string) (*model.DomainEntry, error) {
-	s.logger.Info("Load domain", zap.String("domain", domain), zap.Any("alias", alias))
+	defer func(entries []*model.DomainEntry) {
+		for _, entry := range entries {
+			s.enrichSidecarMetadata(entry)
+			if o.Explain && entry.Metadata.Get(SidecarMetadataKey) != nil {
+				entry.Provenance[SidecarMetadataKey] = &model.MetadataProvenance{Source: model.ProvenanceSourceSidecar}
+			}
+		}
+	}(entries)
 
-	s.mutex.RLock()
-	defer s.mutex.RUnlock()
+	if o.SkipDisabledMetadata {
+		entries = slices.DeleteFunc(slices.Clone(entries), func(e *model.DomainEntry) bool { return !e.Enabled })
+		if len(entries) == 0 {
+			return nil, false
+		}
+	}
 
-	entry, _ := s.findDomainEntry(domain, alias)
-	if entry == nil {
-		s.logger.Error("Domain not found", zap.String("domain", domain), zap.Any("alias", alias))
-		return nil, errors.New("domain not found")
+	ctx := context.Background()
+	if !o.Deadline.IsZero() {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithDeadline(ctx, o.Deadline)
+		defer cancel()
 	}
 
-	entryCopy := entry
-	s.enrichMetadata(entryCopy)
-	return entryCopy, nil
-}
+	registry, release := s.acquireRegistry()
+	defer release()
 
-// ListDomains returns paginated domain entries with their metadata enriched from plugins.
-// It returns a copy of the cached entries to prevent modification of the cache.
-func (s *DomainService) ListDomains(page, perPage int, sortOrder, search string) ([]*model.DomainEntry, *model.PaginationInfo, error) {
-	s.logger.Info("Load domains",
-		zap.Int("page", page),
-		zap.Int("perPage", perPage),
-		zap.String("sortOrder", sortOrder),
-		zap.String("search", search))
+	plugins := registry.Plugins()
+plugins:
+	for _, name := range registry.Order() {
+		plugin, ok := plugins[name]
+		if !ok {
+			continue
+		}
 
-	s.mutex.RLock()
-	defer s.mutex.RUnlock()
+		if len(entries) > 1 && registry.supports(name, pb.CapabilityMetadataBatch) {
+			if ctx.Err() != nil {
+				timedOut = true
+				break
+			}
+			errs, ok := s.enrichMetadataBatch(ctx, o, name, plugin, entries)
+			if ok {
+				pluginErrors = append(pluginErrors, errs...)
+				continue
+			}
+		}
 
-	// Create a copy of the cache to work with
-	entries := make([]*model.DomainEntry, len(s.cache))
-	copy(entries, s.cache)
+		// Plugins whose metadata only depends on the domain are called once per domain
+		var shared map[string]metadataResult
+		if registry.supports(name, pb.CapabilityDomainMetadata) {
+			shared = make(map[string]metadataResult)
+		}
 
-	// Apply search filter if provided
-	if search != "" {
-		filteredEntries := make([]*model.DomainEntry, 0)
 		for _, entry := range entries {
-			if strings.Contains(strings.ToLower(entry.Domain), strings.ToLower(search)) {
-				filteredEntries = append(filteredEntries, entry)
+			if ctx.Err() != nil {
+				timedOut = true
+				break plugins
+			}
+			result, ok := shared[entry.Domain]
+			if !ok {
+				start := time.Now()
+				result.resp, result.err = plugin.GetMetadata(ctx, s.metadataRequest(entry))
+				result.elapsed = time.Since(start)
+				if result.err != nil && ctx.Err() != nil {
+					// Canceled by the deadline, not a failure of the plugin
+					timedOut = true
+					break plugins
+				}
+				if shared != nil {
+					shared[entry.Domain] = result
+				}
+			}
+			resp, err := result.resp, result.err
+			if o.Explain {
+				s.explainMetadata(name, entry, resp, err, result.elapsed, false)
+			}
+			if pluginErr := s.applyMetadata(name, entry, resp, err); pluginErr != nil {
+				pluginErrors = append(pluginErrors, pluginErr)
 			}
 		}
-		entries = filteredEntries
 	}
 
-	// Apply sorting only if sortOrder is provided
-	switch sortOrder {
-	case "desc":
-		sort.Slice(entries, func(i, j int) bool {
-			return entries[i].Domain > entries[j].Domain
-		})
-	case "asc":
-		sort.Slice(entries, func(i, j int) bool {
-			return entries[i].Domain < entries[j].Domain
-		})
+	return pluginErrors, timedOut
+}
+
+// metadataResult is the result of a GetMetadata call, shared by the entries of a domain for plugins
+// advertising pb.CapabilityDomainMetadata.
+type metadataResult struct {
+	resp    *pb.GetMetadataResponse
+	err     error
+	elapsed time.Duration
+}
+
+// enrichMetadataBatch enriches the domain entries with metadata from the named plugin with a single
+// GetMetadataBatch call. It returns false if the plugin does not implement it after all or the call was
+// canceled by the deadline of ctx, so the entries are enriched with GetMetadata instead, which stops right away
+// if canceled.
+func (s *DomainService) enrichMetadataBatch(ctx context.Context, o serviceinterface.QueryOptions, name string, plugin pb.PluginClient, entries []*model.DomainEntry) ([]*model.PluginError, bool) {
+	req := &pb.GetMetadataBatchRequest{Requests: make([]*pb.GetMetadataRequest, len(entries))}
+	for i, entry := range entries {
+		req.Requests[i] = s.metadataRequest(entry)
+	}
+
+	start := time.Now()
+	resp, err := plugin.GetMetadataBatch(ctx, req)
+	elapsed := time.Since(start)
+	if status.Code(err) == codes.Unimplemented {
+		s.logger.Warn("plugin advertised GetMetadataBatch but does not implement it", zap.String("plugin", name))
+		return nil, false
+	}
+	if err != nil && ctx.Err() != nil {
+		// Canceled by the deadline, not a failure of the plugin; the fallback stops right away
+		return nil, false
+	}
+
+	var pluginErrors []*model.PluginError
+	for _, entry := range entries {
+		var m *pb.GetMetadataResponse
+		if err == nil {
+			if m = resp.GetResponses()[pb.MetadataBatchKey(&entry.DomainEntry)]; m == nil {
+				// Entries without a response get no metadata, like an empty GetMetadata response
+				m = &pb.GetMetadataResponse{}
+			}
+		}
+		if o.Explain {
+			s.explainMetadata(name, entry, m, err, elapsed, true)
+		}
+		if pluginErr := s.applyMetadata(name, entry, m, err); pluginErr != nil {
+			pluginErrors = append(pluginErrors, pluginErr)
+		}
+	}
+
+	return pluginErrors, true
+}
+
+// metadataRequest returns the GetMetadata request for entry, with the dehydrated config effective for it.
+func (s *DomainService) metadataRequest(entry *model.DomainEntry) *pb.GetMetadataRequest {
+	return &pb.GetMetadataRequest{
+		DomainEntry:      &entry.DomainEntry,
+		DehydratedConfig: s.DehydratedConfig.DomainSpecificConfig(entry.PathName()).ToProto(),
+	}
+}
+
+// explainMetadata records the provenance of the metadata the named plugin returned for entry after elapsed,
+// see serviceinterface.WithExplain. It has to be called before applyMetadata: merged keys that are already
+// set keep the value, and thus the provenance, of the plugin called first.
+func (s *DomainService) explainMetadata(name string, entry *model.DomainEntry, resp *pb.GetMetadataResponse, err error, elapsed time.Duration, batch bool) {
+	var keys []string
+	switch {
+	case err != nil || resp.Error != "":
+		// The error is set under the plugin's name
+		keys = []string{name}
+	case s.mergeMetadata:
+		values := entry.Metadata.Values()
+		for k, v := range resp.Metadata {
+			if _, ok := values[k]; !ok && v != nil {
+				keys = append(keys, k)
+			}
+		}
+	case resp.Metadata != nil:
+		keys = []string{name}
+	}
+
+	provenance := &model.MetadataProvenance{
+		Source:     model.ProvenanceSourcePlugin,
+		Plugin:     name,
+		DurationMS: float64(elapsed.Microseconds()) / 1000,
+		Batch:      batch,
+	}
+	for _, k := range keys {
+		entry.Provenance[k] = provenance
+	}
+}
+
+// applyMetadata adds the metadata the named plugin returned for entry, or records its error.
+func (s *DomainService) applyMetadata(name string, entry *model.DomainEntry, resp *pb.GetMetadataResponse, err error) *model.PluginError {
+	if err != nil {
+		s.logger.Error("plugin request failed", zap.String("plugin", name), zap.String("domain", entry.Domain), zap.Error(err))
+		entry.Metadata.SetMap(name, map[string]string{"error": err.Error()})
+		return s.pluginErrors.record(entry, name, err.Error())
+	}
+
+	if resp.Error != "" {
+		s.logger.Error("plugin request failed", zap.String("plugin", name),
+			zap.String("domain", entry.Domain), zap.Error(errors.New(resp.Error)))
+		entry.Metadata.SetMap(name, map[string]string{"error": resp.Error})
+		return s.pluginErrors.record(entry, name, resp.Error)
+	}
+
+	if resp.Metadata != nil {
+		if s.mergeMetadata {
+			entry.Metadata.MergeProto(resp.Metadata)
+		} else {
+			entry.Metadata.FromProto(name, resp.Metadata)
+		}
+	}
+
+	return nil
+}
+
+// matchesSearch reports whether the domain field of entry contains search (case-insensitive).
+func matchesSearch(entry *model.DomainEntry, search string) bool {
+	return strings.Contains(strings.ToLower(entry.Domain), strings.ToLower(search))
+}
+
+// Position returns the zero-based index of the entry identified by domain and alias in the domains file,
+// which is written sorted by model.DomainEntries.Sort. The cache keeps the order of the last load, so the
+// index is derived from the entries sorted before it.
+func (s *DomainService) Position(domain, alias string) (int, error) {
+	if err := s.ensureLoaded(); err != nil {
+		return 0, err
+	}
+
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	entry, _ := s.findDomainEntry(domain, alias)
+	if entry == nil {
+		return 0, serviceinterface.ErrDomainNotFound
+	}
+	return model.DomainEntries(s.cache).Position(entry), nil
+}
+
+// copyEntry returns a copy of a cached entry without metadata, so the metadata of a request is neither
+// shared with concurrent requests nor kept for later ones.
+func copyEntry(entry *model.DomainEntry) *model.DomainEntry {
+	c := entry.Select(nil)
+	c.Metadata = nil
+	return c
+}
+
+// GetDomain retrieves a domain entry by its domain name.
+// It returns a copy of the entry with metadata enriched from plugins, unless skipped by opts.
+func (s *DomainService) GetDomain(domain, alias string, opts ...serviceinterface.QueryOption) (*model.DomainEntry, error) {
+	if err := s.ensureLoaded(); err != nil {
+		return nil, err
+	}
+
+	s.logger.Info("Load domain", zap.String("domain", domain), zap.Any("alias", alias))
+
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	entry, _ := s.findDomainEntry(domain, alias)
+	if entry == nil {
+		s.logger.Error("Domain not found", zap.String("domain", domain), zap.Any("alias", alias))
+		return nil, serviceinterface.ErrDomainNotFound
+	}
+
+	entryCopy := copyEntry(entry)
+	s.loadCA(entryCopy)
+	if o := serviceinterface.NewQueryOptions(opts...); !o.SkipMetadata {
+		if pluginErrors, _ := s.enrichMetadata(o, entryCopy); o.StrictPlugins && len(pluginErrors) > 0 {
+			return nil, &serviceinterface.PluginFailureError{Errors: pluginErrors}
+		}
+	}
+	return entryCopy, nil
+}
+
+// ListDomains returns paginated domain entries with their metadata enriched from plugins, unless skipped by opts.
+// It returns a copy of the cached entries to prevent modification of the cache.
+func (s *DomainService) ListDomains(page, perPage int, sortOrder, search string, opts ...serviceinterface.QueryOption) ([]*model.DomainEntry, *model.PaginationInfo, error) {
+	if err := s.ensureLoaded(); err != nil {
+		return nil, nil, err
 	}
-	// If sortOrder is empty or any other value, don't sort (keep original order)
 
-	total := len(entries)
+	s.logger.Info("Load domains",
+		zap.Int("page", page),
+		zap.Int("perPage", perPage),
+		zap.String("sortOrder", sortOrder),
+		zap.String("search", search))
 
-	// Calculate pagination info
-	totalPages := (total + perPage - 1) / perPage // Ceiling division
-	hasNext := page < totalPages
-	hasPrev := page > 1
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
 
-	// Calculate start and end indices
-	start := (page - 1) * perPage
-	end := start + perPage
+	// Create a copy of the cache to work with
+	entries := make([]*model.DomainEntry, len(s.cache))
+	copy(entries, s.cache)
 
-	// Ensure we don't go beyond the available data
-	if start >= total {
-		// Return empty result for pages beyond available data
-		return []*model.DomainEntry{}, &model.PaginationInfo{
-			CurrentPage: page,
-			PerPage:     perPage,
-			Total:       total,
-			TotalPages:  totalPages,
-			HasNext:     false,
-			HasPrev:     hasPrev,
-		}, nil
+	// Apply the search and other filters if provided; all of them have to match
+	o := serviceinterface.NewQueryOptions(opts...)
+	if search != "" || o.Enabled != nil || o.CertStatus != "" {
+		now := time.Now()
+		filteredEntries := make([]*model.DomainEntry, 0)
+		for _, entry := range entries {
+			if search != "" && !matchesSearch(entry, search) {
+				continue
+			}
+			if o.Enabled != nil && entry.Enabled != *o.Enabled {
+				continue
+			}
+			// The certificate is only read for entries passing the cheaper filters
+			if o.CertStatus != "" && s.certStatus(entry, now, o.ExpiryThreshold) != o.CertStatus {
+				continue
+			}
+			filteredEntries = append(filteredEntries, entry)
+		}
+		entries = filteredEntries
 	}
 
-	if end > total {
-		end = total
+	// Apply sorting only if sortOrder is provided, otherwise keep the order of the domains file
+	keys, err := model.ParseSort(sortOrder)
+	if err != nil {
+		return nil, nil, err
 	}
+	model.DomainEntries(entries).SortBy(keys)
+
+	// Select the page, pages beyond the available data are empty
+	entries, pagination := model.Paginate(entries, page, perPage)
 
 	// Return a copy of the paginated entries with enriched metadata
-	resultEntries := make([]*model.DomainEntry, end-start)
-	for i, entry := range entries[start:end] {
-		resultEntries[i] = entry
-		s.enrichMetadata(resultEntries[i])
+	resultEntries := make([]*model.DomainEntry, len(entries))
+	for i, entry := range entries {
+		resultEntries[i] = copyEntry(entry)
+		s.loadCA(resultEntries[i])
+	}
+	var pluginErrors []*model.PluginError
+	var timedOut bool
+	if !o.SkipMetadata {
+		pluginErrors, timedOut = s.enrichMetadata(o, resultEntries...)
 	}
 
-	pagination := &model.PaginationInfo{
-		CurrentPage: page,
-		PerPage:     perPage,
-		Total:       total,
-		TotalPages:  totalPages,
-		HasNext:     hasNext,
-		HasPrev:     hasPrev,
+	if o.StrictPlugins && len(pluginErrors) > 0 {
+		return nil, nil, &serviceinterface.PluginFailureError{Errors: pluginErrors}
 	}
 
 	s.logger.Info("Loaded domains",
 		zap.Int("count", len(resultEntries)),
-		zap.Int("total", total),
-		zap.Int("page", page),
-		zap.Int("totalPages", totalPages))
+		zap.Int("total", pagination.Total),
+		zap.Int("page", pagination.CurrentPage),
+		zap.Int("totalPages", pagination.TotalPages))
+
+	if timedOut {
+		s.logger.Warn("Time budget exceeded, returning partial metadata", zap.Int("count", len(resultEntries)))
+		return resultEntries, pagination, serviceinterface.ErrTimeBudgetExceeded
+	}
 
 	return resultEntries, pagination, nil
 }
 
+// AllDomains returns copies of all domain entries in the order of the domains file, with their metadata enriched
+// from plugins unless skipped by opts. Unlike reading all pages of ListDomains, the entries are a single snapshot,
+// so concurrent changes can neither duplicate nor skip entries.
+func (s *DomainService) AllDomains(opts ...serviceinterface.QueryOption) ([]*model.DomainEntry, error) {
+	if err := s.ensureLoaded(); err != nil {
+		return nil, err
+	}
+
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	entries := make([]*model.DomainEntry, len(s.cache))
+	for i, entry := range s.cache {
+		entries[i] = copyEntry(entry)
+		s.loadCA(entries[i])
+	}
+
+	o := serviceinterface.NewQueryOptions(opts...)
+	if o.SkipMetadata {
+		return entries, nil
+	}
+	pluginErrors, timedOut := s.enrichMetadata(o, entries...)
+	if o.StrictPlugins && len(pluginErrors) > 0 {
+		return nil, &serviceinterface.PluginFailureError{Errors: pluginErrors}
+	}
+	if timedOut {
+		s.logger.Warn("Time budget exceeded, returning partial metadata", zap.Int("count", len(entries)))
+		return entries, serviceinterface.ErrTimeBudgetExceeded
+	}
+
+	return entries, nil
+}
+
 // UpdateDomain updates an existing domain entry with new information.
 // It validates the updated entry and writes the changes to both cache and file.
 func (s *DomainService) UpdateDomain(domain string, req model.UpdateDomainRequest) (*model.DomainEntry, error) {
+	if err := s.ensureLoaded(); err != nil {
+		return nil, err
+	}
+
 	s.logger.Info("Update domain", zap.String("domain", domain), zap.Any("req", req))
 
 	if s.watcher != nil {
 		s.watcher.Disable()
 	}
+	defer func() {
+		if s.watcher != nil {
+			s.watcher.Enable()
+		}
+	}()
+
+	var updatedEntry *model.DomainEntry
+	var done <-chan error
+	err := s.withPluginValidation(func(verdicts pluginVerdicts) (err error) {
+		updatedEntry, done, err = s.applyUpdate(domain, req, verdicts)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.awaitWrite(done); err != nil {
+		return nil, err
+	}
+
+	return updatedEntry, nil
+}
 
+// applyUpdate updates the entry in the cache and persists the change while holding the mutex.
+func (s *DomainService) applyUpdate(domain string, req model.UpdateDomainRequest, verdicts pluginVerdicts) (*model.DomainEntry, <-chan error, error) {
 	s.mutex.Lock()
+	defer s.mutex.Unlock()
 
 	alias := ""
 	if req.Alias != nil {
@@ -447,32 +1195,42 @@ func (s *DomainService) UpdateDomain(domain string, req model.UpdateDomainReques
 	}
 	entry, index := s.findDomainEntry(domain, alias)
 	if entry == nil {
-		s.mutex.Unlock()
 		s.logger.Error("Domain not found", zap.String("domain", domain), zap.Any("req", req))
-		return nil, errors.New("domain not found")
+		return nil, nil, serviceinterface.ErrDomainNotFound
 	}
 
 	updatedEntry := updateEntry(entry, req)
 
 	// Validate the updated entry
-	if !model.IsValidDomainEntry(updatedEntry) {
-		s.mutex.Unlock()
-		s.logger.Error("Invalid domain entry", zap.Any("entry", updatedEntry))
-		return nil, errors.New("invalid domain entry")
+	if err := s.validateEntry(updatedEntry, entry); err != nil {
+		s.logger.Error("Invalid domain entry", zap.Any("entry", updatedEntry), zap.Error(err))
+		return nil, nil, err
+	}
+
+	if err := s.pluginVerdict(verdicts, updatedEntry); err != nil {
+		return nil, nil, err
+	}
+
+	var previousCA string
+	if req.CA != nil {
+		var err error
+		if previousCA, err = s.writeCA(updatedEntry, *req.CA); err != nil {
+			return nil, nil, err
+		}
 	}
 
+	var done <-chan error
 	if !updatedEntry.Equals(entry) {
 		s.cache[index] = updatedEntry
 
 		// Write back to file
-		if err := s.writeCacheToFile(); err != nil {
-			s.mutex.Unlock()
-			s.logger.Error("Failed to write domains file", zap.Error(err))
-			// Re-enable watcher even on error
-			if s.watcher != nil {
-				s.watcher.Enable()
+		var err error
+		if done, err = s.persist(s.cache); err != nil {
+			if req.CA != nil {
+				s.restoreCA(updatedEntry, previousCA)
 			}
-			return nil, err
+			s.logger.Error("Failed to write domains file", zap.Error(err))
+			return nil, nil, err
 		}
 
 		s.logger.Info("Updated domain", zap.String("domain", domain), zap.Any("req", req))
@@ -480,56 +1238,868 @@ func (s *DomainService) UpdateDomain(domain string, req model.UpdateDomainReques
 		s.logger.Info("No changes detected for domain", zap.String("domain", domain), zap.Any("req", req))
 	}
 
-	s.mutex.Unlock()
-
-	// Re-enable watcher after successful write (outside of locked section)
-	if s.watcher != nil {
-		s.watcher.Enable()
-	}
+	s.loadCA(updatedEntry)
 
-	return updatedEntry, nil
+	return updatedEntry, done, nil
 }
 
-// DeleteDomain removes a domain entry from both the cache and the domains file.
-// It returns an error if the domain is not found.
-func (s *DomainService) DeleteDomain(domain string, req model.DeleteDomainRequest) error {
-	s.logger.Info("Delete domain", zap.String("domain", domain), zap.Any("req", req))
+// UpsertDomain updates the entry identified by domain and the alias of req like UpdateDomain,
+// or creates it like CreateDomain if it does not exist. Both happen under the same lock, so concurrent
+// upserts of the same entry cannot create it twice. It reports whether the entry was created.
+func (s *DomainService) UpsertDomain(domain string, req model.UpdateDomainRequest) (*model.DomainEntry, bool, error) {
+	if err := s.ensureLoaded(); err != nil {
+		return nil, false, err
+	}
+
+	s.logger.Info("Upsert domain", zap.String("domain", domain), zap.Any("req", req))
 
 	if s.watcher != nil {
 		s.watcher.Disable()
 	}
+	defer func() {
+		if s.watcher != nil {
+			s.watcher.Enable()
+		}
+	}()
+
+	var entry *model.DomainEntry
+	var created bool
+	var done <-chan error
+	err := s.withPluginValidation(func(verdicts pluginVerdicts) (err error) {
+		entry, created, done, err = s.upsertEntry(domain, req, verdicts)
+		return err
+	})
+	if err != nil {
+		return nil, false, err
+	}
+
+	if err := s.awaitWrite(done); err != nil {
+		return nil, false, err
+	}
+
+	return entry, created, nil
+}
 
+// upsertEntry updates or creates the entry in the cache and persists the change while holding the mutex.
+func (s *DomainService) upsertEntry(domain string, req model.UpdateDomainRequest, verdicts pluginVerdicts) (*model.DomainEntry, bool, <-chan error, error) {
 	s.mutex.Lock()
+	defer s.mutex.Unlock()
 
-	newEntries, found := s.entriesWithout(domain, req.Alias)
-	if !found {
-		s.mutex.Unlock()
-		s.logger.Error("Domain without alias not found", zap.String("domain", domain), zap.Any("req", req))
-		return errors.New("domain without specified alias not found")
+	alias := util.String(req.Alias)
+	existing, index := s.findDomainEntry(domain, alias)
+
+	var entry *model.DomainEntry
+	if existing != nil {
+		entry = updateEntry(existing, req)
+		s.canonicalize(entry)
+		if entry.Equals(existing) && req.CA == nil {
+			s.logger.Info("No changes detected for domain", zap.String("domain", domain), zap.Any("req", req))
+			return entry, false, nil, nil
+		}
+	} else {
+		entry = &model.DomainEntry{
+			DomainEntry: pb.DomainEntry{
+				Domain:           domain,
+				AlternativeNames: util.StringSlice(req.AlternativeNames),
+				Alias:            alias,
+				Enabled:          s.enabledOrDefault(req.Enabled),
+				Comment:          s.markComment(util.String(req.Comment)),
+			},
+			CA: util.String(req.CA),
+		}
+	}
+
+	if err := s.validateEntry(entry, existing); err != nil {
+		s.logger.Error("Invalid domain entry", zap.Any("entry", entry), zap.Error(err))
+		return nil, false, nil, err
+	}
+
+	if existing == nil {
+		if err := s.checkEntryLimit(len(s.cache) + 1); err != nil {
+			s.logger.Error("Too many domain entries", zap.Any("entry", entry), zap.Error(err))
+			return nil, false, nil, err
+		}
+	}
+
+	if err := s.pluginVerdict(verdicts, entry); err != nil {
+		return nil, false, nil, err
+	}
+
+	var previousCA string
+	if req.CA != nil {
+		var err error
+		if previousCA, err = s.writeCA(entry, *req.CA); err != nil {
+			return nil, false, nil, err
+		}
+	}
+
+	newEntries := make([]*model.DomainEntry, len(s.cache), len(s.cache)+1)
+	copy(newEntries, s.cache)
+	if existing != nil {
+		newEntries[index] = entry
+	} else {
+		newEntries = append(newEntries, entry)
 	}
 
 	// Write back to file
-	if err := s.writeEntriesToFile(newEntries); err != nil {
-		s.mutex.Unlock()
+	done, err := s.persist(newEntries)
+	if err != nil {
+		if req.CA != nil {
+			s.restoreCA(entry, previousCA)
+		}
 		s.logger.Error("Failed to write domains file", zap.Error(err))
-		// Re-enable watcher even on error
+		return nil, false, nil, err
+	}
+
+	// Update cache only after successful write
+	s.cache = newEntries
+	s.warnEntryCount(len(s.cache))
+	s.loadCA(entry)
+
+	s.logger.Info("Upserted domain", zap.String("domain", domain), zap.String("alias", alias), zap.Bool("created", existing == nil))
+
+	return entry, existing == nil, done, nil
+}
+
+// ReplaceDomain replaces the entry identified by domain and alias with the given entry.
+// The domain name is kept, the alias may change as long as it doesn't collide with another entry.
+func (s *DomainService) ReplaceDomain(domain, alias string, entry *model.DomainEntry) (*model.DomainEntry, error) {
+	if err := s.ensureLoaded(); err != nil {
+		return nil, err
+	}
+
+	s.logger.Info("Replace domain", zap.String("domain", domain), zap.String("alias", alias), zap.Any("entry", entry))
+
+	if s.watcher != nil {
+		s.watcher.Disable()
+	}
+	defer func() {
+		if s.watcher != nil {
+			s.watcher.Enable()
+		}
+	}()
+
+	return s.replaceDomain(domain, alias, func(_ *model.DomainEntry) (*model.DomainEntry, error) {
+		return entry, nil
+	})
+}
+
+// PatchDomain changes the entry identified by domain and alias by calling patch with a copy of it.
+// The copy is taken and the patched entry is written while holding the mutex, so changes made meanwhile are not lost.
+// Like by ReplaceDomain, the domain name is kept and the alias may change. An error of patch is returned as is.
+func (s *DomainService) PatchDomain(domain, alias string, patch func(entry *model.DomainEntry) error) (*model.DomainEntry, error) {
+	if err := s.ensureLoaded(); err != nil {
+		return nil, err
+	}
+
+	s.logger.Info("Patch domain", zap.String("domain", domain), zap.String("alias", alias))
+
+	if s.watcher != nil {
+		s.watcher.Disable()
+	}
+	defer func() {
 		if s.watcher != nil {
 			s.watcher.Enable()
 		}
+	}()
+
+	return s.replaceDomain(domain, alias, func(existing *model.DomainEntry) (*model.DomainEntry, error) {
+		entry := copyEntry(existing)
+		entry.AlternativeNames = slices.Clone(existing.AlternativeNames)
+		if err := patch(entry); err != nil {
+			return nil, err
+		}
+		return entry, nil
+	})
+}
+
+// replaceDomain replaces the entry identified by domain and alias with the entry returned by replace for it.
+func (s *DomainService) replaceDomain(domain, alias string, replace func(existing *model.DomainEntry) (*model.DomainEntry, error)) (*model.DomainEntry, error) {
+	var replacement *model.DomainEntry
+	var done <-chan error
+	err := s.withPluginValidation(func(verdicts pluginVerdicts) (err error) {
+		replacement, done, err = s.replaceEntry(domain, alias, replace, verdicts)
 		return err
+	})
+	if err != nil {
+		return nil, err
 	}
 
-	// Update cache only after successful write
-	s.cache = newEntries
+	if err := s.awaitWrite(done); err != nil {
+		return nil, err
+	}
 
-	s.mutex.Unlock()
+	return replacement, nil
+}
 
-	s.logger.Info("Deleted domain", zap.String("domain", domain), zap.Any("req", req))
+// replaceEntry replaces the entry in the cache with the entry returned by replace and persists the change
+// while holding the mutex.
+func (s *DomainService) replaceEntry(domain, alias string, replace func(existing *model.DomainEntry) (*model.DomainEntry, error), verdicts pluginVerdicts) (*model.DomainEntry, <-chan error, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
 
-	// Re-enable watcher after successful write (outside of locked section)
-	if s.watcher != nil {
-		s.watcher.Enable()
+	existing, index := s.findDomainEntry(domain, alias)
+	if existing == nil {
+		s.logger.Error("Domain not found", zap.String("domain", domain), zap.String("alias", alias))
+		return nil, nil, serviceinterface.ErrDomainNotFound
 	}
 
-	return nil
+	entry, err := replace(existing)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	replacement := &model.DomainEntry{
+		DomainEntry: pb.DomainEntry{
+			Domain:           existing.Domain,
+			AlternativeNames: entry.AlternativeNames,
+			Alias:            entry.Alias,
+			Enabled:          entry.Enabled,
+			Comment:          entry.Comment,
+		},
+		Options: existing.Options,
+	}
+
+	if err := s.validateEntry(replacement, existing); err != nil {
+		s.logger.Error("Invalid domain entry", zap.Any("entry", replacement), zap.Error(err))
+		return nil, nil, err
+	}
+
+	if replacement.Alias != alias {
+		if other, _ := s.findDomainEntry(domain, replacement.Alias); other != nil {
+			s.logger.Error("Domain already exists", zap.Any("entry", replacement))
+			return nil, nil, serviceinterface.ErrDomainExists
+		}
+	}
+
+	if replacement.Equals(existing) {
+		s.logger.Info("No changes detected for domain", zap.String("domain", domain), zap.String("alias", alias))
+		s.loadCA(replacement)
+		return replacement, nil, nil
+	}
+
+	if err := s.pluginVerdict(verdicts, replacement); err != nil {
+		return nil, nil, err
+	}
+
+	newEntries := make([]*model.DomainEntry, len(s.cache))
+	copy(newEntries, s.cache)
+	newEntries[index] = replacement
+
+	// Write back to file
+	done, err := s.persist(newEntries)
+	if err != nil {
+		s.logger.Error("Failed to write domains file", zap.Error(err))
+		return nil, nil, err
+	}
+
+	// Update cache only after successful write
+	s.cache = newEntries
+
+	s.logger.Info("Replaced domain", zap.String("domain", domain), zap.String("alias", alias))
+	s.loadCA(replacement)
+
+	return replacement, done, nil
+}
+
+// RenameDomain changes the primary domain name, and optionally the alias, of the entry identified by domain and alias.
+// The entry keeps its alternative names, enabled state and comment. Like every write, the domains file is sorted,
+// so the entry moves to the position of its new name.
+func (s *DomainService) RenameDomain(domain, alias string, req model.RenameDomainRequest) (*model.DomainEntry, error) {
+	if err := s.ensureLoaded(); err != nil {
+		return nil, err
+	}
+
+	s.logger.Info("Rename domain", zap.String("domain", domain), zap.String("alias", alias), zap.Any("req", req))
+
+	if s.watcher != nil {
+		s.watcher.Disable()
+	}
+	defer func() {
+		if s.watcher != nil {
+			s.watcher.Enable()
+		}
+	}()
+
+	var renamed *model.DomainEntry
+	var done <-chan error
+	err := s.withPluginValidation(func(verdicts pluginVerdicts) (err error) {
+		renamed, done, err = s.renameEntry(domain, alias, req, verdicts)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.awaitWrite(done); err != nil {
+		return nil, err
+	}
+
+	return renamed, nil
+}
+
+// renameEntry renames the entry in the cache and persists the change while holding the mutex.
+func (s *DomainService) renameEntry(domain, alias string, req model.RenameDomainRequest, verdicts pluginVerdicts) (*model.DomainEntry, <-chan error, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	existing, index := s.findDomainEntry(domain, alias)
+	if existing == nil {
+		s.logger.Error("Domain not found", zap.String("domain", domain), zap.String("alias", alias))
+		return nil, nil, serviceinterface.ErrDomainNotFound
+	}
+
+	newAlias := existing.Alias
+	if req.Alias != nil {
+		newAlias = *req.Alias
+	}
+
+	renamed := &model.DomainEntry{
+		DomainEntry: pb.DomainEntry{
+			Domain:           req.Domain,
+			AlternativeNames: existing.AlternativeNames,
+			Alias:            newAlias,
+			Enabled:          existing.Enabled,
+			Comment:          existing.Comment,
+		},
+		Options: existing.Options,
+	}
+
+	if err := s.validateEntry(renamed, existing); err != nil {
+		s.logger.Error("Invalid domain entry", zap.Any("entry", renamed), zap.Error(err))
+		return nil, nil, err
+	}
+
+	if renamed.Equals(existing) {
+		s.logger.Info("No changes detected for domain", zap.String("domain", domain), zap.String("alias", alias))
+		s.loadCA(renamed)
+		return renamed, nil, nil
+	}
+
+	if other, i := s.findDomainEntry(renamed.Domain, renamed.Alias); other != nil && i != index {
+		s.logger.Error("Domain already exists", zap.Any("entry", renamed))
+		return nil, nil, serviceinterface.ErrDomainExists
+	}
+
+	if err := s.pluginVerdict(verdicts, renamed); err != nil {
+		return nil, nil, err
+	}
+
+	// Replace the entry at its index; the domains file is sorted when written
+	newEntries := make([]*model.DomainEntry, len(s.cache))
+	copy(newEntries, s.cache)
+	newEntries[index] = renamed
+
+	// Write back to file
+	done, err := s.persist(newEntries)
+	if err != nil {
+		s.logger.Error("Failed to write domains file", zap.Error(err))
+		return nil, nil, err
+	}
+
+	// Update cache only after successful write
+	s.cache = newEntries
+
+	s.logger.Info("Renamed domain",
+		zap.String("domain", domain), zap.String("alias", alias),
+		zap.String("newDomain", renamed.Domain), zap.String("newAlias", renamed.Alias))
+	s.loadCA(renamed)
+
+	return renamed, done, nil
+}
+
+// DeleteDomain removes a domain entry from both the cache and the domains file.
+// It returns an error if the domain is not found.
+func (s *DomainService) DeleteDomain(domain string, req model.DeleteDomainRequest) error {
+	if err := s.ensureLoaded(); err != nil {
+		return err
+	}
+
+	s.logger.Info("Delete domain", zap.String("domain", domain), zap.Any("req", req))
+
+	if s.watcher != nil {
+		s.watcher.Disable()
+	}
+	defer func() {
+		if s.watcher != nil {
+			s.watcher.Enable()
+		}
+	}()
+
+	s.mutex.Lock()
+
+	newEntries, found := s.entriesWithout(domain, req.Alias)
+	if !found {
+		s.mutex.Unlock()
+		s.logger.Error("Domain without alias not found", zap.String("domain", domain), zap.Any("req", req))
+		return errors.New("domain without specified alias not found")
+	}
+
+	// Write back to file
+	done, err := s.persist(newEntries)
+	if err != nil {
+		s.mutex.Unlock()
+		s.logger.Error("Failed to write domains file", zap.Error(err))
+		return err
+	}
+
+	// Update cache only after successful write
+	s.cache = newEntries
+
+	s.mutex.Unlock()
+
+	s.logger.Info("Deleted domain", zap.String("domain", domain), zap.Any("req", req))
+
+	return s.awaitWrite(done)
+}
+
+// SetEnabled enables or disables all domain entries matching the filter with a single file write.
+// search has the same semantics as for ListDomains, filterEnabled optionally restricts the change
+// to entries in the given state. It returns the number of entries that changed.
+func (s *DomainService) SetEnabled(search string, filterEnabled *bool, enabled bool) (int, error) {
+	if err := s.ensureLoaded(); err != nil {
+		return 0, err
+	}
+
+	s.logger.Info("Bulk update enabled state",
+		zap.String("search", search),
+		zap.Any("filterEnabled", filterEnabled),
+		zap.Bool("enabled", enabled))
+
+	if s.watcher != nil {
+		s.watcher.Disable()
+	}
+
+	s.mutex.Lock()
+
+	newEntries := make([]*model.DomainEntry, len(s.cache))
+	count := 0
+	for i, entry := range s.cache {
+		newEntries[i] = entry
+		if search != "" && !matchesSearch(entry, search) {
+			continue
+		}
+		if filterEnabled != nil && entry.Enabled != *filterEnabled {
+			continue
+		}
+		if entry.Enabled == enabled {
+			continue
+		}
+		newEntries[i] = updateEntry(entry, model.UpdateDomainRequest{Enabled: &enabled})
+		count++
+	}
+
+	var done <-chan error
+	if count > 0 {
+		// Write back to file
+		var err error
+		if done, err = s.persist(newEntries); err != nil {
+			s.mutex.Unlock()
+			s.logger.Error("Failed to write domains file", zap.Error(err))
+			// Re-enable watcher even on error
+			if s.watcher != nil {
+				s.watcher.Enable()
+			}
+			return 0, err
+		}
+
+		// Update cache only after successful write
+		s.cache = newEntries
+	}
+
+	s.mutex.Unlock()
+
+	s.logger.Info("Bulk updated enabled state", zap.Int("count", count), zap.Bool("enabled", enabled))
+
+	// Re-enable watcher after successful write (outside of locked section)
+	if s.watcher != nil {
+		s.watcher.Enable()
+	}
+
+	if err := s.awaitWrite(done); err != nil {
+		return 0, err
+	}
+
+	return count, nil
+}
+
+// DeleteDomains removes all domain entries matching the filter with a single file write.
+// search has the same semantics as for ListDomains, filterEnabled optionally restricts the deletion
+// to entries in the given state. It returns the deleted entries in the order of the domains file.
+func (s *DomainService) DeleteDomains(search string, filterEnabled *bool) ([]*model.DomainEntry, error) {
+	if err := s.ensureLoaded(); err != nil {
+		return nil, err
+	}
+
+	s.logger.Info("Bulk delete domains",
+		zap.String("search", search),
+		zap.Any("filterEnabled", filterEnabled))
+
+	if s.watcher != nil {
+		s.watcher.Disable()
+	}
+	defer func() {
+		if s.watcher != nil {
+			s.watcher.Enable()
+		}
+	}()
+
+	s.mutex.Lock()
+
+	newEntries := make([]*model.DomainEntry, 0, len(s.cache))
+	var deleted []*model.DomainEntry
+	for _, entry := range s.cache {
+		if (search == "" || matchesSearch(entry, search)) && (filterEnabled == nil || entry.Enabled == *filterEnabled) {
+			deleted = append(deleted, entry)
+			continue
+		}
+		newEntries = append(newEntries, entry)
+	}
+
+	var done <-chan error
+	if len(deleted) > 0 {
+		// Write back to file
+		var err error
+		if done, err = s.persist(newEntries); err != nil {
+			s.mutex.Unlock()
+			s.logger.Error("Failed to write domains file", zap.Error(err))
+			return nil, err
+		}
+
+		// Update cache only after successful write
+		s.cache = newEntries
+	}
+
+	s.mutex.Unlock()
+
+	s.logger.Info("Bulk deleted domains", zap.Int("count", len(deleted)))
+
+	if err := s.awaitWrite(done); err != nil {
+		return nil, err
+	}
+
+	return deleted, nil
+}
+
+// PluginErrors returns the given page of the errors plugins returned recently while enriching metadata, newest first.
+func (s *DomainService) PluginErrors(page, perPage int) ([]*model.PluginError, *model.PaginationInfo, error) {
+	errs, pagination := s.pluginErrors.list(page, perPage)
+	return errs, pagination, nil
+}
+
+// PluginFailures returns the enabled plugins that are not available, sorted by name.
+func (s *DomainService) PluginFailures() []*model.PluginFailure {
+	return s.currentRegistry().Failures()
+}
+
+// Plugins returns the given page of the registered plugins, sorted by name.
+func (s *DomainService) Plugins(page, perPage int) ([]*model.PluginInfo, *model.PaginationInfo, error) {
+	registry := s.currentRegistry()
+	plugins, validators := registry.Plugins(), registry.Validators()
+	capabilities := make(map[string][]string, len(plugins))
+	for name := range plugins {
+		capabilities[name] = registry.Capabilities(name)
+	}
+
+	names := make([]string, 0, len(plugins))
+	for name := range plugins {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+
+	names, pagination := model.Paginate(names, page, perPage)
+
+	result := make([]*model.PluginInfo, 0, len(names))
+	for _, name := range names {
+		_, validate := validators[name]
+		result = append(result, &model.PluginInfo{Name: name, Validate: validate, Capabilities: capabilities[name]})
+	}
+
+	return result, pagination, nil
+}
+
+// CertInfo returns the information of the current certificate of entry, cached until the certificate changes.
+// It returns dehydrated.ErrCertNotFound if dehydrated has not issued a certificate yet.
+func (s *DomainService) CertInfo(entry *model.DomainEntry) (*dehydrated.CertInfo, error) {
+	file, err := s.DehydratedConfig.CertFile(entry.PathName())
+	if err != nil {
+		return nil, err
+	}
+
+	return s.certs.Get(file)
+}
+
+// certStatus returns the certificate status of entry at now.
+// Without a local certificate, the end of the validity period provided by a plugin is used, see pluginCertInfo.
+// Certificates that cannot be read are logged and reported as missing.
+func (s *DomainService) certStatus(entry *model.DomainEntry, now time.Time, expiryThreshold time.Duration) string {
+	info, err := s.CertInfo(entry)
+	switch {
+	case errors.Is(err, dehydrated.ErrCertNotFound):
+		info = s.pluginCertInfo(entry)
+	case err != nil:
+		s.logger.Warn("Failed to read certificate", zap.String("domain", entry.Domain),
+			zap.String("alias", entry.Alias), zap.Error(err))
+	}
+
+	return info.Status(now, expiryThreshold)
+}
+
+// pluginCertInfo returns the certificate information of entry with the end of the validity period provided by a
+// plugin as pb.MetadataCertNotAfter, e.g., by one reading certificates from a secret manager. The plugins are asked
+// in the order of the registry and the first valid value wins. It returns nil if no plugin provides one.
+func (s *DomainService) pluginCertInfo(entry *model.DomainEntry) *dehydrated.CertInfo {
+	registry, release := s.acquireRegistry()
+	defer release()
+
+	plugins := registry.Plugins()
+	for _, name := range registry.Order() {
+		plugin, ok := plugins[name]
+		if !ok {
+			continue
+		}
+
+		resp, err := plugin.GetMetadata(context.Background(), s.metadataRequest(entry))
+		if err != nil || resp.GetError() != "" {
+			continue
+		}
+		value, ok := resp.GetMetadata()[pb.MetadataCertNotAfter]
+		if !ok {
+			continue
+		}
+		notAfter, err := time.Parse(time.RFC3339, value.GetStringValue())
+		if err != nil {
+			s.logger.Warn("Invalid certificate expiry provided by plugin", zap.String("plugin", name),
+				zap.String("domain", entry.Domain), zap.String("alias", entry.Alias), zap.Error(err))
+			continue
+		}
+		return &dehydrated.CertInfo{NotAfter: notAfter}
+	}
+
+	return nil
+}
+
+// Summary aggregates the status of all domain entries and their certificates.
+// Certificates expiring within expiryThreshold are counted as expiring.
+func (s *DomainService) Summary(expiryThreshold time.Duration) (*model.Summary, error) {
+	if err := s.ensureLoaded(); err != nil {
+		return nil, err
+	}
+
+	s.mutex.RLock()
+	entries := slices.Clone(s.cache)
+	s.mutex.RUnlock()
+
+	summary := &model.Summary{
+		Total:      len(entries),
+		ExpiryDays: int(expiryThreshold / (24 * time.Hour)),
+		Duplicates: duplicates(entries),
+	}
+
+	now := time.Now()
+	for _, entry := range entries {
+		if entry.Enabled {
+			summary.Enabled++
+		}
+
+		switch s.certStatus(entry, now, expiryThreshold) {
+		case dehydrated.CertStatusValid:
+			summary.Valid++
+		case dehydrated.CertStatusExpiring:
+			summary.Expiring++
+		case dehydrated.CertStatusExpired:
+			summary.Expired++
+		default:
+			summary.Missing++
+		}
+	}
+
+	return summary, nil
+}
+
+// duplicates returns the entries occurring more than once, as "domain" or "domain > alias", in the order of
+// their first occurrence.
+func duplicates(entries []*model.DomainEntry) []string {
+	counts := make(map[string]int, len(entries))
+	result := []string{}
+	for _, entry := range entries {
+		key := entry.Domain
+		if entry.Alias != "" {
+			key += " > " + entry.Alias
+		}
+		counts[key]++
+		if counts[key] == 2 {
+			result = append(result, key)
+		}
+	}
+	return result
+}
+
+// Reconcile compares the entries to the certificate directories below CertDir. Enabled entries whose certificate
+// does not exist, or whose path name is outside of CertDir, are missing; disabled entries are commented out in the
+// domains file, so dehydrated does not issue their certificates. Directories that are not the path name of any
+// entry, enabled or not, are orphans. A CertDir that does not exist has no orphans.
+func (s *DomainService) Reconcile() (*model.Reconciliation, error) {
+	if err := s.ensureLoaded(); err != nil {
+		return nil, err
+	}
+
+	s.mutex.RLock()
+	entries := slices.Clone(s.cache)
+	s.mutex.RUnlock()
+
+	result := &model.Reconciliation{MissingCerts: []string{}, OrphanCerts: []string{}}
+
+	pathNames := make(map[string]bool, len(entries))
+	for _, entry := range entries {
+		pathName := entry.PathName()
+		if pathNames[pathName] {
+			continue
+		}
+		pathNames[pathName] = true
+
+		if !entry.Enabled {
+			continue
+		}
+		file, err := s.DehydratedConfig.CertFile(pathName)
+		if err == nil {
+			_, err = os.Stat(file)
+		}
+		if err != nil {
+			result.MissingCerts = append(result.MissingCerts, pathName)
+		}
+	}
+
+	dirs, err := os.ReadDir(s.DehydratedConfig.CertDir)
+	if err != nil && !os.IsNotExist(err) {
+		return nil, fmt.Errorf("failed to read certificate directory: %w", err)
+	}
+	for _, dir := range dirs {
+		if dir.IsDir() && !pathNames[dir.Name()] {
+			result.OrphanCerts = append(result.OrphanCerts, dir.Name())
+		}
+	}
+
+	slices.Sort(result.MissingCerts)
+	slices.Sort(result.OrphanCerts)
+
+	return result, nil
+}
+
+// EffectiveConfig returns the dehydrated configuration of the entry identified by domain and alias,
+// with the overrides of its certificate's config file applied, as passed to plugins.
+// It returns dehydrated.ErrInvalidPath if the certificate directory of the entry is outside of CertDir.
+func (s *DomainService) EffectiveConfig(domain, alias string) (*dehydrated.Config, error) {
+	if err := s.ensureLoaded(); err != nil {
+		return nil, err
+	}
+
+	s.mutex.RLock()
+	entry, _ := s.findDomainEntry(domain, alias)
+	s.mutex.RUnlock()
+	if entry == nil {
+		return nil, serviceinterface.ErrDomainNotFound
+	}
+	if _, err := s.DehydratedConfig.CertPath(entry.PathName()); err != nil {
+		return nil, err
+	}
+
+	return s.DehydratedConfig.DomainSpecificConfig(entry.PathName()), nil
+}
+
+// KeyFingerprint returns the fingerprint of the public key of the certificate of the entry identified by domain
+// and alias, read from its private key or certificate, see dehydrated.Config.KeyInfo. The key itself is never returned.
+// It returns dehydrated.ErrKeyNotFound if neither exists, or dehydrated.ErrInvalidPath if the certificate directory
+// of the entry is outside of CertDir.
+func (s *DomainService) KeyFingerprint(domain, alias string) (*dehydrated.KeyInfo, error) {
+	if err := s.ensureLoaded(); err != nil {
+		return nil, err
+	}
+
+	s.mutex.RLock()
+	entry, _ := s.findDomainEntry(domain, alias)
+	s.mutex.RUnlock()
+	if entry == nil {
+		return nil, serviceinterface.ErrDomainNotFound
+	}
+
+	return s.DehydratedConfig.KeyInfo(entry.PathName())
+}
+
+// RawDomainLine returns the line of the entry identified by domain and alias as currently written to the
+// domains file, with its parsed components. With write coalescing, recent changes may not be written yet.
+func (s *DomainService) RawDomainLine(domain, alias string) (*model.RawDomainLine, error) {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+
+	lines, err := readDomainsFileLines(s.DehydratedConfig.DomainsFile)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, l := range lines {
+		if l.Primary == domain && l.Alias == alias {
+			sans := l.SANs
+			if sans == nil {
+				sans = []string{}
+			}
+			return &model.RawDomainLine{
+				Line:     l.Number,
+				Raw:      l.Raw,
+				Disabled: l.Disabled,
+				Primary:  l.Primary,
+				SANs:     sans,
+				Alias:    l.Alias,
+				Options:  l.Options,
+				Comment:  l.Comment,
+			}, nil
+		}
+	}
+
+	return nil, serviceinterface.ErrDomainNotFound
+}
+
+// RefreshOCSP runs dehydrated for the entry identified by domain and alias, which fetches a new
+// OCSP response if due (and renews the certificate if due), and returns the current OCSP response.
+// It returns dehydrated.ErrOCSPNotFound if no OCSP response exists afterwards, and dehydrated.ErrInvalidPath
+// without running dehydrated if the certificate directory of the entry is outside of CertDir.
+// Since dehydrated may renew the certificate, it refuses a disabled entry with
+// serviceinterface.ErrDomainDisabled unless allowDisabled is set. opts, e.g., to force a renewal, apply to this
+// run only; invalid combinations are rejected with dehydrated.ErrForceValidationWithoutForce.
+func (s *DomainService) RefreshOCSP(ctx context.Context, domain, alias string, allowDisabled bool, opts dehydrated.CronOptions) (*dehydrated.OCSPInfo, error) {
+	if err := s.ensureLoaded(); err != nil {
+		return nil, err
+	}
+	if err := opts.Validate(); err != nil {
+		return nil, err
+	}
+
+	s.mutex.RLock()
+	entry, _ := s.findDomainEntry(domain, alias)
+	s.mutex.RUnlock()
+	if entry == nil {
+		return nil, serviceinterface.ErrDomainNotFound
+	}
+
+	file, err := s.DehydratedConfig.OCSPFile(entry.PathName())
+	if err != nil {
+		return nil, err
+	}
+
+	if !entry.Enabled && !allowDisabled {
+		return nil, serviceinterface.ErrDomainDisabled
+	}
+
+	s.logger.Info("Refreshing OCSP response", append([]zap.Field{zap.String("domain", domain), zap.String("alias", alias),
+		zap.Bool("force", opts.ForceRenew), zap.Bool("forceValidation", opts.ForceValidation)},
+		auth.IdentityFromContext(ctx).Fields()...)...)
+
+	domains := append([]string{entry.Domain}, entry.AlternativeNames...)
+	if err := s.DehydratedConfig.RunCron(ctx, s.dehydratedScript, domains, entry.Alias, opts); err != nil {
+		s.logger.Error("Failed to refresh OCSP response", zap.String("domain", domain),
+			zap.String("alias", alias), zap.Error(err))
+		return nil, err
+	}
+
+	return dehydrated.ReadOCSPInfo(file)
 }
@@ -1,9 +1,11 @@
 package service
 
 import (
+	"context"
 	"fmt"
 	"os"
 	"path/filepath"
+	"strings"
 	"sync"
 	"testing"
 	"time"
@@ -11,6 +13,8 @@ import (
 	"github.com/schumann-it/dehydrated-api-go/internal/plugin/cache"
 
 	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
 
 	pb "github.com/schumann-it/dehydrated-api-go/plugin/proto"
 
@@ -19,8 +23,11 @@ import (
 	"github.com/schumann-it/dehydrated-api-go/internal/model"
 	"github.com/schumann-it/dehydrated-api-go/internal/plugin/config"
 	"github.com/schumann-it/dehydrated-api-go/internal/plugin/registry"
+	serviceinterface "github.com/schumann-it/dehydrated-api-go/internal/service/interface"
+	"github.com/schumann-it/dehydrated-api-go/internal/testutil"
 	"github.com/schumann-it/dehydrated-api-go/internal/util"
 	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/types/known/structpb"
 )
 
 // Package service provides core business logic for the dehydrated-api-go application.
@@ -77,7 +84,22 @@ func TestDomainService(t *testing.T) {
 					Domain: "example.com",
 				}
 				_, err := service.CreateDomain(&req)
-				require.Error(t, err)
+				require.ErrorIs(t, err, serviceinterface.ErrDomainExists)
+			})
+
+			// Test CreateDuplicateAlias
+			t.Run("CreateDuplicateAlias", func(t *testing.T) {
+				req := model.CreateDomainRequest{
+					Domain: "example.com",
+					Alias:  "example.com-rsa",
+				}
+				_, err := service.CreateDomain(&req)
+				require.NoError(t, err)
+
+				_, err = service.CreateDomain(&req)
+				require.ErrorIs(t, err, serviceinterface.ErrDomainExists)
+
+				require.NoError(t, service.DeleteDomain("example.com", model.DeleteDomainRequest{Alias: &req.Alias}))
 			})
 
 			// Test GetDomain
@@ -116,6 +138,13 @@ func TestDomainService(t *testing.T) {
 				require.Equal(t, 1, pagination.TotalPages)
 				require.False(t, pagination.HasNext)
 				require.False(t, pagination.HasPrev)
+
+				// Invalid pagination parameters are defaulted
+				entries, pagination, err = service.ListDomains(0, 0, "", "")
+				require.NoError(t, err)
+				require.Len(t, entries, 1)
+				require.Equal(t, model.MinPage, pagination.CurrentPage)
+				require.Equal(t, model.DefaultPerPage, pagination.PerPage)
 			})
 
 			// Test DeleteDomain
@@ -431,8 +460,8 @@ func TestDomainService_UpdateDomain(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			// Create a new domain service with default config and empty registry
-			cfg := dehydrated.NewConfig()
+			// Create a new domain service with an empty domains file and registry
+			cfg := dehydrated.NewConfig().WithBaseDir(t.TempDir()).Load()
 			reg := registry.New("", make(map[string]config.PluginConfig), zap.NewNop())
 			service := NewDomainService(cfg, reg)
 
@@ -441,7 +470,7 @@ func TestDomainService_UpdateDomain(t *testing.T) {
 				_, err := service.CreateDomain(&model.CreateDomainRequest{
 					Domain:           tt.domain,
 					AlternativeNames: []string{"www.example.com"},
-					Enabled:          true,
+					Enabled:          util.BoolPtr(true),
 				})
 				require.NoError(t, err)
 			}
@@ -484,12 +513,12 @@ func TestGetDomainByAlias(t *testing.T) {
 		{
 			Domain:  "vpn.hq.schumann-it.com",
 			Alias:   "",
-			Enabled: true,
+			Enabled: util.BoolPtr(true),
 		},
 		{
 			Domain:  "vpn.hq.schumann-it.com",
 			Alias:   "vpn.hq.schumann-it.com-rsa",
-			Enabled: true,
+			Enabled: util.BoolPtr(true),
 		},
 	}
 
@@ -535,3 +564,689 @@ vpn.hq.schumann-it.com > vpn.hq.schumann-it.com-rsa # RSA entry
 	require.Error(t, err)
 	require.Contains(t, err.Error(), "domain not found")
 }
+
+// TestSetEnabled verifies that bulk enable/disable only changes the entries matching the filter
+// and persists them with a single write.
+func TestSetEnabled(t *testing.T) {
+	tmpDir := t.TempDir()
+	domainsFile := filepath.Join(tmpDir, "domains.txt")
+
+	initialContent := `staging.a.example.com
+staging.b.example.com
+prod.example.com
+# staging.c.example.com
+`
+	require.NoError(t, os.WriteFile(domainsFile, []byte(initialContent), 0644))
+
+	dc := dehydrated.NewConfig().WithBaseDir(tmpDir).Load()
+	s := NewDomainService(dc, nil)
+	defer s.Close()
+	require.NoError(t, s.Reload())
+
+	enabledState := func() map[string]bool {
+		entries, err := ReadDomainsFile(domainsFile)
+		require.NoError(t, err)
+		state := make(map[string]bool)
+		for _, entry := range entries {
+			state[entry.Domain] = entry.Enabled
+		}
+		return state
+	}
+
+	count, err := s.SetEnabled("STAGING.", nil, false)
+	require.NoError(t, err)
+	require.Equal(t, 2, count)
+	require.Equal(t, map[string]bool{
+		"staging.a.example.com": false,
+		"staging.b.example.com": false,
+		"prod.example.com":      true,
+		"staging.c.example.com": false,
+	}, enabledState())
+
+	// Nothing to change
+	count, err = s.SetEnabled("staging.", nil, false)
+	require.NoError(t, err)
+	require.Zero(t, count)
+
+	// Restrict to entries in a given state
+	count, err = s.SetEnabled("", util.BoolPtr(false), true)
+	require.NoError(t, err)
+	require.Equal(t, 3, count)
+	require.Equal(t, map[string]bool{
+		"staging.a.example.com": true,
+		"staging.b.example.com": true,
+		"prod.example.com":      true,
+		"staging.c.example.com": true,
+	}, enabledState())
+}
+
+// TestDeleteDomains verifies that bulk deletion only removes the entries matching the filter
+// and persists the change with a single write.
+func TestDeleteDomains(t *testing.T) {
+	tmpDir := t.TempDir()
+	domainsFile := filepath.Join(tmpDir, "domains.txt")
+
+	initialContent := `staging.a.example.com
+staging.b.example.com > staging-b
+prod.example.com
+# staging.c.example.com
+`
+	require.NoError(t, os.WriteFile(domainsFile, []byte(initialContent), 0644))
+
+	core, logs := observer.New(zapcore.InfoLevel)
+	dc := dehydrated.NewConfig().WithBaseDir(tmpDir).Load()
+	s := NewDomainService(dc, nil).WithLogger(zap.New(core))
+	defer s.Close()
+	require.NoError(t, s.Reload())
+
+	domains := func(entries []*model.DomainEntry) []string {
+		names := make([]string, 0, len(entries))
+		for _, entry := range entries {
+			names = append(names, entry.Domain)
+		}
+		return names
+	}
+	writes := func() int {
+		return logs.FilterMessage("Dumping domains to disk").Len()
+	}
+
+	deleted, err := s.DeleteDomains("STAGING.", util.BoolPtr(true))
+	require.NoError(t, err)
+	require.Equal(t, []string{"staging.a.example.com", "staging.b.example.com"}, domains(deleted))
+	require.Equal(t, "staging-b", deleted[1].Alias)
+	require.Equal(t, 1, writes())
+
+	entries, err := ReadDomainsFile(domainsFile)
+	require.NoError(t, err)
+	require.Equal(t, []string{"prod.example.com", "staging.c.example.com"}, domains(entries))
+
+	// Nothing to delete
+	deleted, err = s.DeleteDomains("staging.a", nil)
+	require.NoError(t, err)
+	require.Empty(t, deleted)
+	require.Equal(t, 1, writes())
+
+	// An empty filter deletes all entries
+	deleted, err = s.DeleteDomains("", nil)
+	require.NoError(t, err)
+	require.Len(t, deleted, 2)
+	require.Equal(t, 2, writes())
+
+	entries, _, err = s.ListDomains(1, 10, "", "")
+	require.NoError(t, err)
+	require.Empty(t, entries)
+}
+
+// TestCommentMarker verifies that entries created via the service carry the configured marker
+// in their comment and that it survives a reload from the domains file.
+func TestCommentMarker(t *testing.T) {
+	dc := dehydrated.NewConfig().WithBaseDir(t.TempDir()).Load()
+	s := NewDomainService(dc, nil).WithCommentMarker("[api]")
+	defer s.Close()
+
+	_, err := s.CreateDomain(&model.CreateDomainRequest{Domain: "a.example.com", Enabled: util.BoolPtr(true), Comment: "web"})
+	require.NoError(t, err)
+	_, err = s.CreateDomain(&model.CreateDomainRequest{Domain: "b.example.com", Enabled: util.BoolPtr(true)})
+	require.NoError(t, err)
+	_, err = s.CreateDomain(&model.CreateDomainRequest{Domain: "c.example.com", Enabled: util.BoolPtr(true), Comment: "[api] tagged"})
+	require.NoError(t, err)
+
+	require.NoError(t, s.Reload())
+
+	comments := map[string]string{}
+	for _, domain := range []string{"a.example.com", "b.example.com", "c.example.com"} {
+		entry, err := s.GetDomain(domain, "")
+		require.NoError(t, err)
+		comments[domain] = entry.Comment
+	}
+	require.Equal(t, map[string]string{
+		"a.example.com": "[api] web",
+		"b.example.com": "[api]",
+		"c.example.com": "[api] tagged",
+	}, comments)
+
+	// Updates keep the comment as given
+	entry, err := s.UpdateDomain("a.example.com", model.UpdateDomainRequest{Comment: util.StringPtr("changed")})
+	require.NoError(t, err)
+	require.Equal(t, "changed", entry.Comment)
+}
+
+// TestChangeSeq verifies that the change sequence increases with every change and reload, and is stable otherwise.
+func TestChangeSeq(t *testing.T) {
+	dc := dehydrated.NewConfig().WithBaseDir(t.TempDir()).Load()
+	s := NewDomainService(dc, nil)
+	defer s.Close()
+	require.Equal(t, uint64(0), s.ChangeSeq())
+
+	// changed asserts that f changes the sequence if want is set and keeps it otherwise
+	changed := func(want bool, f func()) {
+		t.Helper()
+		before := s.ChangeSeq()
+		f()
+		if want {
+			require.Greater(t, s.ChangeSeq(), before)
+		} else {
+			require.Equal(t, before, s.ChangeSeq())
+		}
+	}
+
+	changed(true, func() {
+		_, err := s.CreateDomain(&model.CreateDomainRequest{Domain: "example.com"})
+		require.NoError(t, err)
+	})
+	changed(false, func() {
+		_, _, err := s.ListDomains(1, 10, "", "")
+		require.NoError(t, err)
+		_, err = s.GetDomain("example.com", "")
+		require.NoError(t, err)
+		_, err = s.Summary(0)
+		require.NoError(t, err)
+	})
+	changed(false, func() {
+		_, err := s.CreateDomain(&model.CreateDomainRequest{Domain: "example.com"})
+		require.ErrorIs(t, err, serviceinterface.ErrDomainExists)
+	})
+	changed(true, func() {
+		_, err := s.UpdateDomain("example.com", model.UpdateDomainRequest{Enabled: util.BoolPtr(true)})
+		require.NoError(t, err)
+	})
+	changed(false, func() {
+		_, err := s.UpdateDomain("example.com", model.UpdateDomainRequest{Enabled: util.BoolPtr(true)})
+		require.NoError(t, err)
+	})
+	changed(true, func() {
+		require.NoError(t, s.Reload())
+	})
+	changed(true, func() {
+		require.NoError(t, s.DeleteDomain("example.com", model.DeleteDomainRequest{}))
+	})
+
+	t.Run("Concurrent", func(t *testing.T) {
+		before := s.ChangeSeq()
+		createDomains(t, s, 20)
+		require.Equal(t, before+20, s.ChangeSeq())
+	})
+}
+
+// TestDefaultEnabled verifies that created, upserted and imported entries without an enabled state
+// get the configured default, while an explicit state is kept.
+func TestDefaultEnabled(t *testing.T) {
+	for _, defaultEnabled := range []bool{false, true} {
+		t.Run(fmt.Sprintf("Default%t", defaultEnabled), func(t *testing.T) {
+			dc := dehydrated.NewConfig().WithBaseDir(t.TempDir()).Load()
+			s := NewDomainService(dc, nil).WithDefaultEnabled(defaultEnabled)
+			defer s.Close()
+
+			entry, err := s.CreateDomain(&model.CreateDomainRequest{Domain: "omitted.example.com"})
+			require.NoError(t, err)
+			require.Equal(t, defaultEnabled, entry.Enabled)
+
+			entry, err = s.CreateDomain(&model.CreateDomainRequest{Domain: "enabled.example.com", Enabled: util.BoolPtr(true)})
+			require.NoError(t, err)
+			require.True(t, entry.Enabled)
+
+			entry, err = s.CreateDomain(&model.CreateDomainRequest{Domain: "disabled.example.com", Enabled: util.BoolPtr(false)})
+			require.NoError(t, err)
+			require.False(t, entry.Enabled)
+
+			entry, _, err = s.UpsertDomain("upserted.example.com", model.UpdateDomainRequest{})
+			require.NoError(t, err)
+			require.Equal(t, defaultEnabled, entry.Enabled)
+
+			_, err = s.ImportDomains([]*model.CreateDomainRequest{{Domain: "imported.example.com"}}, false)
+			require.NoError(t, err)
+
+			line, err := s.PreviewDomain(&model.CreateDomainRequest{Domain: "preview.example.com"})
+			require.NoError(t, err)
+			require.Equal(t, !defaultEnabled, strings.HasPrefix(line, "#"))
+
+			entries, err := ReadDomainsFile(dc.DomainsFile)
+			require.NoError(t, err)
+			enabled := map[string]bool{}
+			for _, e := range entries {
+				enabled[e.Domain] = e.Enabled
+			}
+			require.Equal(t, map[string]bool{
+				"omitted.example.com":  defaultEnabled,
+				"enabled.example.com":  true,
+				"disabled.example.com": false,
+				"upserted.example.com": defaultEnabled,
+				"imported.example.com": defaultEnabled,
+			}, enabled)
+		})
+	}
+}
+
+// TestColdCache verifies that the first request loads the domains file if the cache was never loaded,
+// and that requests fail with ErrCacheUnavailable instead of serving an empty cache while it cannot be read.
+func TestColdCache(t *testing.T) {
+	t.Run("ReadThrough", func(t *testing.T) {
+		dc := dehydrated.NewConfig().WithBaseDir(t.TempDir()).Load()
+		require.NoError(t, os.WriteFile(dc.DomainsFile, []byte("a.example.com\nb.example.com > b\n"), 0o644))
+		s := NewDomainService(dc, nil)
+		defer s.Close()
+
+		entries, _, err := s.ListDomains(1, 10, "", "")
+		require.NoError(t, err)
+		require.Len(t, entries, 2)
+	})
+
+	t.Run("MutationLoadsFirst", func(t *testing.T) {
+		dc := dehydrated.NewConfig().WithBaseDir(t.TempDir()).Load()
+		require.NoError(t, os.WriteFile(dc.DomainsFile, []byte("a.example.com\n"), 0o644))
+		s := NewDomainService(dc, nil)
+		defer s.Close()
+
+		_, err := s.CreateDomain(&model.CreateDomainRequest{Domain: "a.example.com"})
+		require.ErrorIs(t, err, serviceinterface.ErrDomainExists)
+		_, err = s.CreateDomain(&model.CreateDomainRequest{Domain: "b.example.com"})
+		require.NoError(t, err)
+
+		entries, err := ReadDomainsFile(dc.DomainsFile)
+		require.NoError(t, err)
+		require.Len(t, entries, 2)
+	})
+
+	t.Run("FailedInitialLoad", func(t *testing.T) {
+		dc := dehydrated.NewConfig().WithBaseDir(t.TempDir()).Load()
+		s := NewDomainService(dc, nil)
+		defer s.Close()
+
+		// The domains file cannot be read while it is a directory
+		require.NoError(t, os.Remove(dc.DomainsFile))
+		require.NoError(t, os.Mkdir(dc.DomainsFile, 0o755))
+		require.Error(t, s.Reload())
+
+		_, _, err := s.ListDomains(1, 10, "", "")
+		require.ErrorIs(t, err, serviceinterface.ErrCacheUnavailable)
+		_, err = s.GetDomain("a.example.com", "")
+		require.ErrorIs(t, err, serviceinterface.ErrCacheUnavailable)
+		_, err = s.Summary(14 * 24 * time.Hour)
+		require.ErrorIs(t, err, serviceinterface.ErrCacheUnavailable)
+		_, err = s.CreateDomain(&model.CreateDomainRequest{Domain: "a.example.com"})
+		require.ErrorIs(t, err, serviceinterface.ErrCacheUnavailable)
+
+		// Once readable, the next request loads it
+		require.NoError(t, os.Remove(dc.DomainsFile))
+		require.NoError(t, os.WriteFile(dc.DomainsFile, []byte("a.example.com\n"), 0o644))
+		entry, err := s.GetDomain("a.example.com", "")
+		require.NoError(t, err)
+		require.Equal(t, "a.example.com", entry.Domain)
+	})
+}
+
+// TestCheckNotEmpty verifies that a domains file without entries is reported.
+func TestCheckNotEmpty(t *testing.T) {
+	for _, tt := range []struct {
+		name    string
+		content string
+		err     error
+	}{
+		{"Empty", "", serviceinterface.ErrNoDomains},
+		{"CommentsOnly", "# managed by the API\n\n", serviceinterface.ErrNoDomains},
+		{"Entries", "example.com\n", nil},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			dc := dehydrated.NewConfig().WithBaseDir(t.TempDir()).Load()
+			require.NoError(t, os.WriteFile(dc.DomainsFile, []byte(tt.content), 0o644))
+			s := NewDomainService(dc, nil)
+			defer s.Close()
+
+			err := s.CheckNotEmpty()
+			if tt.err != nil {
+				require.ErrorIs(t, err, tt.err)
+				return
+			}
+			require.NoError(t, err)
+
+			// Deleting the last entry empties the file
+			require.NoError(t, s.DeleteDomain("example.com", model.DeleteDomainRequest{}))
+			require.ErrorIs(t, s.CheckNotEmpty(), serviceinterface.ErrNoDomains)
+		})
+	}
+}
+
+// TestRenameDomain verifies that a renamed entry keeps its comment, alternative names
+// and enabled state, and is placed according to the sort order of the domains file.
+func TestRenameDomain(t *testing.T) {
+	tmpDir := t.TempDir()
+	domainsFile := filepath.Join(tmpDir, "domains.txt")
+
+	initialContent := `a.example.com
+c.example.com www.c.example.com > c-cert # shop
+# e.example.com
+`
+	require.NoError(t, os.WriteFile(domainsFile, []byte(initialContent), 0644))
+
+	dc := dehydrated.NewConfig().WithBaseDir(tmpDir).Load()
+	s := NewDomainService(dc, nil)
+	defer s.Close()
+	require.NoError(t, s.Reload())
+
+	entry, err := s.RenameDomain("c.example.com", "c-cert", model.RenameDomainRequest{Domain: "b.example.com"})
+	require.NoError(t, err)
+	require.Equal(t, "b.example.com", entry.Domain)
+	require.Equal(t, "c-cert", entry.Alias)
+
+	entries, err := ReadDomainsFile(domainsFile)
+	require.NoError(t, err)
+	require.Len(t, entries, 3)
+	require.Equal(t, "a.example.com", entries[0].Domain)
+	require.Equal(t, "b.example.com", entries[1].Domain)
+	require.Equal(t, []string{"www.c.example.com"}, entries[1].AlternativeNames)
+	require.Equal(t, "c-cert", entries[1].Alias)
+	require.True(t, entries[1].Enabled)
+	require.Equal(t, "shop", entries[1].Comment)
+	require.Equal(t, "e.example.com", entries[2].Domain)
+	require.False(t, entries[2].Enabled)
+
+	_, err = s.GetDomain("c.example.com", "c-cert")
+	require.ErrorIs(t, err, serviceinterface.ErrDomainNotFound)
+
+	// The alias can be changed along with the domain
+	entry, err = s.RenameDomain("b.example.com", "c-cert", model.RenameDomainRequest{Domain: "b.example.com", Alias: util.StringPtr("")})
+	require.NoError(t, err)
+	require.Empty(t, entry.Alias)
+
+	// Collisions, unknown entries and invalid names are rejected
+	_, err = s.RenameDomain("b.example.com", "", model.RenameDomainRequest{Domain: "a.example.com"})
+	require.ErrorIs(t, err, serviceinterface.ErrDomainExists)
+	_, err = s.RenameDomain("unknown.example.com", "", model.RenameDomainRequest{Domain: "x.example.com"})
+	require.ErrorIs(t, err, serviceinterface.ErrDomainNotFound)
+	_, err = s.RenameDomain("b.example.com", "", model.RenameDomainRequest{Domain: "not a domain"})
+	require.ErrorIs(t, err, serviceinterface.ErrInvalidDomainEntry)
+
+	// A name that sorts elsewhere moves the entry, it does not keep its position
+	_, err = s.RenameDomain("b.example.com", "", model.RenameDomainRequest{Domain: "z.example.com"})
+	require.NoError(t, err)
+	entries, err = ReadDomainsFile(domainsFile)
+	require.NoError(t, err)
+	require.Len(t, entries, 3)
+	require.Equal(t, "e.example.com", entries[1].Domain)
+	require.Equal(t, "z.example.com", entries[2].Domain)
+	require.Equal(t, "shop", entries[2].Comment)
+	position, err := s.Position("z.example.com", "")
+	require.NoError(t, err)
+	require.Equal(t, 2, position)
+}
+
+// TestUpsertDomain verifies that UpsertDomain creates missing entries and updates existing ones.
+func TestUpsertDomain(t *testing.T) {
+	dc := dehydrated.NewConfig().WithBaseDir(t.TempDir()).Load()
+	s := NewDomainService(dc, nil).WithCommentMarker("[api]")
+	defer s.Close()
+
+	req := model.UpdateDomainRequest{
+		Alias:            util.StringPtr("cert"),
+		AlternativeNames: util.StringSlicePtr([]string{"www.example.com"}),
+		Enabled:          util.BoolPtr(true),
+		Comment:          util.StringPtr("web"),
+	}
+
+	entry, created, err := s.UpsertDomain("example.com", req)
+	require.NoError(t, err)
+	require.True(t, created)
+	require.Equal(t, "cert", entry.Alias)
+	require.Equal(t, []string{"www.example.com"}, entry.AlternativeNames)
+	require.True(t, entry.Enabled)
+	require.Equal(t, "[api] web", entry.Comment)
+
+	// Unset fields keep their value on update
+	entry, created, err = s.UpsertDomain("example.com", model.UpdateDomainRequest{Alias: util.StringPtr("cert"), Enabled: util.BoolPtr(false)})
+	require.NoError(t, err)
+	require.False(t, created)
+	require.False(t, entry.Enabled)
+	require.Equal(t, []string{"www.example.com"}, entry.AlternativeNames)
+	require.Equal(t, "[api] web", entry.Comment)
+
+	// Repeating an upsert is idempotent
+	_, created, err = s.UpsertDomain("example.com", model.UpdateDomainRequest{Alias: util.StringPtr("cert"), Enabled: util.BoolPtr(false)})
+	require.NoError(t, err)
+	require.False(t, created)
+
+	entries, err := ReadDomainsFile(dc.DomainsFile)
+	require.NoError(t, err)
+	require.Len(t, entries, 1)
+	require.False(t, entries[0].Enabled)
+
+	_, _, err = s.UpsertDomain("not a domain", model.UpdateDomainRequest{})
+	require.ErrorIs(t, err, serviceinterface.ErrInvalidDomainEntry)
+}
+
+// TestCommentValidation verifies that comments and aliases are trimmed and that values
+// which would corrupt the line format of the domains file are rejected.
+func TestCommentValidation(t *testing.T) {
+	dc := dehydrated.NewConfig().WithBaseDir(t.TempDir()).Load()
+	s := NewDomainService(dc, nil).WithMaxCommentLength(10)
+	defer s.Close()
+
+	entry, err := s.CreateDomain(&model.CreateDomainRequest{Domain: "example.com", Alias: " cert ", Comment: "  web  "})
+	require.NoError(t, err)
+	require.Equal(t, "cert", entry.Alias)
+	require.Equal(t, "web", entry.Comment)
+
+	_, err = s.CreateDomain(&model.CreateDomainRequest{Domain: "a.example.com", Comment: "first\nsecond"})
+	require.ErrorIs(t, err, serviceinterface.ErrInvalidDomainEntry)
+	require.ErrorContains(t, err, "single line")
+
+	_, err = s.CreateDomain(&model.CreateDomainRequest{Domain: "a.example.com", Comment: "01234567890"})
+	require.ErrorIs(t, err, serviceinterface.ErrInvalidDomainEntry)
+	require.ErrorContains(t, err, "exceeds 10 characters")
+
+	_, err = s.CreateDomain(&model.CreateDomainRequest{Domain: "a.example.com", Alias: "a\nb"})
+	require.ErrorIs(t, err, serviceinterface.ErrInvalidDomainEntry)
+
+	_, err = s.UpdateDomain("example.com", model.UpdateDomainRequest{Alias: util.StringPtr("cert"), Comment: util.StringPtr("x\ny")})
+	require.ErrorIs(t, err, serviceinterface.ErrInvalidDomainEntry)
+
+	// Nothing invalid has been written
+	entries, err := ReadDomainsFile(dc.DomainsFile)
+	require.NoError(t, err)
+	require.Len(t, entries, 1)
+	require.Equal(t, "web", entries[0].Comment)
+}
+
+// TestCommentValidationKeepsExistingComments verifies that entries with a comment exceeding the
+// limit in the domains file can still be changed otherwise.
+func TestCommentValidationKeepsExistingComments(t *testing.T) {
+	tmpDir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(tmpDir, "domains.txt"), []byte("example.com # a rather long manual comment\n"), 0644))
+
+	dc := dehydrated.NewConfig().WithBaseDir(tmpDir).Load()
+	s := NewDomainService(dc, nil).WithMaxCommentLength(10)
+	defer s.Close()
+	require.NoError(t, s.Reload())
+
+	entry, err := s.UpdateDomain("example.com", model.UpdateDomainRequest{Enabled: util.BoolPtr(false)})
+	require.NoError(t, err)
+	require.False(t, entry.Enabled)
+	require.Equal(t, "a rather long manual comment", entry.Comment)
+}
+
+// TestAliasValidation verifies that aliases which could leave CertDir, as they name the directory of
+// the certificate, or exceed the limit are rejected for all changes.
+func TestAliasValidation(t *testing.T) {
+	dc := dehydrated.NewConfig().WithBaseDir(t.TempDir()).Load()
+	s := NewDomainService(dc, nil).WithMaxAliasLength(10)
+	defer s.Close()
+
+	_, err := s.CreateDomain(&model.CreateDomainRequest{Domain: "example.com", Alias: "cert-rsa_1"})
+	require.NoError(t, err)
+
+	for _, alias := range []string{"../etc", "certs/example", "..", "a\x00b", "01234567890"} {
+		t.Run(alias, func(t *testing.T) {
+			var fieldErr *serviceinterface.FieldError
+
+			_, err := s.CreateDomain(&model.CreateDomainRequest{Domain: "example.org", Alias: alias})
+			require.ErrorAs(t, err, &fieldErr)
+			require.Equal(t, "alias", fieldErr.Field)
+
+			_, _, err = s.UpsertDomain("example.com", model.UpdateDomainRequest{Alias: util.StringPtr(alias)})
+			require.ErrorAs(t, err, &fieldErr)
+			require.Equal(t, "alias", fieldErr.Field)
+
+			_, err = s.RenameDomain("example.com", "cert-rsa_1", model.RenameDomainRequest{Domain: "example.com", Alias: util.StringPtr(alias)})
+			require.ErrorAs(t, err, &fieldErr)
+			require.Equal(t, "alias", fieldErr.Field)
+
+			_, err = s.ImportDomains([]*model.CreateDomainRequest{{Domain: "example.net", Alias: alias}}, false)
+			require.ErrorIs(t, err, serviceinterface.ErrInvalidDomainEntry)
+		})
+	}
+
+	// Nothing has been written outside of CertDir
+	entries, err := ReadDomainsFile(dc.DomainsFile)
+	require.NoError(t, err)
+	require.Len(t, entries, 1)
+	require.Equal(t, "cert-rsa_1", entries[0].Alias)
+	_, err = os.Stat(filepath.Join(dc.CertDir, "..", "etc"))
+	require.True(t, os.IsNotExist(err))
+}
+
+// configPlugin returns the key algorithm of the configuration it receives as metadata.
+type configPlugin struct {
+	pb.UnimplementedPluginServer
+}
+
+func (p *configPlugin) Initialize(_ context.Context, _ *pb.InitializeRequest) (*pb.InitializeResponse, error) {
+	return &pb.InitializeResponse{}, nil
+}
+
+func (p *configPlugin) GetMetadata(_ context.Context, req *pb.GetMetadataRequest) (*pb.GetMetadataResponse, error) {
+	return &pb.GetMetadataResponse{Metadata: map[string]*structpb.Value{
+		"key_algo": structpb.NewStringValue(req.GetDehydratedConfig().GetKeyAlgo()),
+	}}, nil
+}
+
+func (p *configPlugin) Close(_ context.Context, _ *pb.CloseRequest) (*pb.CloseResponse, error) {
+	return &pb.CloseResponse{}, nil
+}
+
+// TestDomainSpecificConfigIsolation verifies that the config override of one certificate
+// is not passed to plugins enriching other entries.
+func TestDomainSpecificConfigIsolation(t *testing.T) {
+	dc := dehydrated.NewConfig().WithBaseDir(t.TempDir()).Load()
+	certDir := filepath.Join(dc.CertDir, "example.com")
+	require.NoError(t, os.MkdirAll(certDir, 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(certDir, "config"), []byte("KEY_ALGO=secp384r1\n"), 0644))
+
+	address, _ := testutil.ServePlugin(t, &configPlugin{})
+	s := NewDomainService(dc, registry.New(dc.BaseDir, map[string]config.PluginConfig{
+		"config": {Enabled: true, Address: address, Insecure: true},
+	}, zap.NewNop()))
+	t.Cleanup(func() { _ = s.Close() })
+
+	for _, domain := range []string{"example.com", "example.org"} {
+		_, err := s.CreateDomain(&model.CreateDomainRequest{Domain: domain, Enabled: util.BoolPtr(true)})
+		require.NoError(t, err)
+	}
+
+	keyAlgo := func(domain string) any {
+		entry, err := s.GetDomain(domain, "")
+		require.NoError(t, err)
+		return entry.Metadata.Get("config").(map[string]any)["key_algo"]
+	}
+
+	require.Equal(t, "secp384r1", keyAlgo("example.com"))
+	require.Equal(t, "rsa", keyAlgo("example.org"))
+	require.Equal(t, "rsa", dc.KeyAlgo)
+}
+
+// TestAllowedChallengeTypes verifies that entries whose certificate overrides the challenge type
+// with one that is not allowed are rejected on creation and update.
+func TestAllowedChallengeTypes(t *testing.T) {
+	tmpDir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(tmpDir, "config"), []byte("CHALLENGETYPE=dns-01\n"), 0644))
+	dc := dehydrated.NewConfig().WithBaseDir(tmpDir).Load()
+
+	s := NewDomainService(dc, nil).WithAllowedChallengeTypes([]string{"dns-01"})
+	t.Cleanup(func() { _ = s.Close() })
+
+	override := func(name, challengeType string) {
+		certDir := filepath.Join(dc.CertDir, name)
+		require.NoError(t, os.MkdirAll(certDir, 0755))
+		require.NoError(t, os.WriteFile(filepath.Join(certDir, "config"), []byte("CHALLENGETYPE="+challengeType+"\n"), 0644))
+	}
+
+	// The global challenge type is allowed
+	_, err := s.CreateDomain(&model.CreateDomainRequest{Domain: "example.com", Enabled: util.BoolPtr(true)})
+	require.NoError(t, err)
+
+	// An override with an allowed challenge type
+	override("dns", "dns-01")
+	_, err = s.CreateDomain(&model.CreateDomainRequest{Domain: "example.com", Alias: "dns", Enabled: util.BoolPtr(true)})
+	require.NoError(t, err)
+
+	// An override with a challenge type that is not allowed
+	override("http", "http-01")
+	_, err = s.CreateDomain(&model.CreateDomainRequest{Domain: "example.com", Alias: "http", Enabled: util.BoolPtr(true)})
+	require.ErrorIs(t, err, serviceinterface.ErrInvalidDomainEntry)
+	require.ErrorIs(t, err, dehydrated.ErrChallengeTypeNotAllowed)
+	require.ErrorContains(t, err, "certificate http: challenge type not allowed: CHALLENGETYPE http-01")
+
+	// An override added to an existing entry blocks its update
+	override("example.com", "http-01")
+	_, err = s.UpdateDomain("example.com", model.UpdateDomainRequest{Comment: util.StringPtr("changed")})
+	require.ErrorIs(t, err, dehydrated.ErrChallengeTypeNotAllowed)
+
+	// Without an allowlist, all challenge types are allowed
+	_, err = NewDomainService(dc, nil).CreateDomain(&model.CreateDomainRequest{Domain: "example.com", Alias: "http", Enabled: util.BoolPtr(true)})
+	require.NoError(t, err)
+}
+
+func TestListDomainsSort(t *testing.T) {
+	dc := dehydrated.NewConfig().WithBaseDir(t.TempDir()).Load()
+	require.NoError(t, os.WriteFile(dc.DomainsFile, []byte(strings.Join([]string{
+		"b.example.com > y",
+		"# a.example.com",
+		"# b.example.com",
+		"a.example.com > x",
+		"c.example.com",
+	}, "\n")), 0644))
+	s := NewDomainService(dc, nil)
+	defer s.Close()
+	require.NoError(t, s.Reload())
+
+	list := func(sort string) []string {
+		entries, _, err := s.ListDomains(1, 10, sort, "", serviceinterface.WithoutMetadata())
+		require.NoError(t, err)
+		result := make([]string, len(entries))
+		for i, e := range entries {
+			result[i] = e.Domain + ">" + e.Alias
+		}
+		return result
+	}
+
+	require.Equal(t, []string{"a.example.com>", "b.example.com>", "a.example.com>x", "b.example.com>y", "c.example.com>"}, list("enabled,domain"))
+	require.Equal(t, []string{"a.example.com>x", "b.example.com>y", "c.example.com>", "a.example.com>", "b.example.com>"}, list("-enabled,domain"))
+	require.Equal(t, []string{"c.example.com>", "b.example.com>", "b.example.com>y", "a.example.com>", "a.example.com>x"}, list("-domain,alias"))
+
+	_, _, err := s.ListDomains(1, 10, "domain,owner", "")
+	require.ErrorIs(t, err, model.ErrInvalidSort)
+}
+
+// TestAllDomains verifies that AllDomains returns copies of all entries in the order of the domains file,
+// beyond the maximum page size of ListDomains.
+func TestAllDomains(t *testing.T) {
+	dc := dehydrated.NewConfig().WithBaseDir(t.TempDir()).Load()
+	lines := make([]string, 0, model.MaxPerPage+1)
+	for i := model.MaxPerPage; i >= 0; i-- {
+		lines = append(lines, fmt.Sprintf("d%04d.example.com", i))
+	}
+	require.NoError(t, os.WriteFile(dc.DomainsFile, []byte(strings.Join(lines, "\n")), 0644))
+	s := NewDomainService(dc, nil)
+	defer s.Close()
+	require.NoError(t, s.Reload())
+
+	entries, err := s.AllDomains(serviceinterface.WithoutMetadata())
+	require.NoError(t, err)
+	require.Len(t, entries, model.MaxPerPage+1)
+	require.Equal(t, lines[0], entries[0].Domain)
+	require.Equal(t, lines[model.MaxPerPage], entries[model.MaxPerPage].Domain)
+
+	// The entries are copies, changing them does not change the cache
+	entries[0].Comment = "changed"
+	entry, err := s.GetDomain(lines[0], "", serviceinterface.WithoutMetadata())
+	require.NoError(t, err)
+	require.Empty(t, entry.Comment)
+}
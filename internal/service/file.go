@@ -2,7 +2,11 @@ package service
 
 import (
 	"bufio"
+	"bytes"
+	"compress/gzip"
+	"io"
 	"os"
+	"slices"
 	"strings"
 
 	"github.com/schumann-it/dehydrated-api-go/internal/model"
@@ -15,133 +19,180 @@ import (
 // - Aliases using the '>' syntax
 // - Comments using '#' prefix or inline
 // - Disabled entries (prefixed with '#')
+// The lines are parsed into DomainLine values, see ParseDomainLine.
+// Gzip-compressed files, e.g., archived as domains.txt.gz, are decompressed transparently.
 func ReadDomainsFile(filename string) (model.DomainEntries, error) {
+	lines, err := readDomainsFileLines(filename)
+	if err != nil {
+		return nil, err
+	}
+
+	entries := make(model.DomainEntries, 0, len(lines))
+	for _, l := range lines {
+		entries = append(entries, l.Entry())
+	}
+
+	return entries, nil
+}
+
+// readDomainsFileLines reads the lines of a domains file, see readDomainLines.
+// A missing file has no lines.
+func readDomainsFileLines(filename string) ([]*DomainLine, error) {
 	file, err := os.Open(filename)
 	if err != nil {
 		if os.IsNotExist(err) {
-			return model.DomainEntries{}, nil
+			return nil, nil
 		}
 		return nil, err
 	}
 	defer file.Close()
 
-	var entries model.DomainEntries
-	scanner := bufio.NewScanner(file)
-	for scanner.Scan() {
-		line := strings.TrimSpace(scanner.Text())
-		if line == "" {
-			continue // Skip empty lines
-		}
+	r, err := decompress(file)
+	if err != nil {
+		return nil, err
+	}
 
-		// Check if the line is a comment
-		enabled := true
-		comment := ""
-		if strings.HasPrefix(line, "#") {
-			// Remove the comment marker
-			line = strings.TrimPrefix(line, "#")
-			line = strings.TrimSpace(line)
-			enabled = false
-		}
+	return readDomainLines(r)
+}
 
-		// Extract inline comment if present
-		if strings.Contains(line, "#") {
-			parts := strings.SplitN(line, "#", 2)
-			line = strings.TrimSpace(parts[0])
-			comment = strings.TrimSpace(parts[1])
-		}
+// gzipMagic are the first bytes of gzip-compressed data.
+var gzipMagic = []byte{0x1f, 0x8b}
 
-		// Split by '>' to handle aliases
-		parts := strings.Split(line, ">")
-		mainPart := strings.TrimSpace(parts[0])
-		alias := ""
-		if len(parts) > 1 {
-			alias = strings.TrimSpace(parts[1])
-		}
+// decompress returns a reader of the decompressed content of r if it starts with the gzip magic bytes.
+// Other content, including empty files with a .gz extension, is returned as is.
+func decompress(r io.Reader) (io.Reader, error) {
+	br := bufio.NewReader(r)
+	if magic, err := br.Peek(len(gzipMagic)); err != nil || !bytes.Equal(magic, gzipMagic) {
+		// Read errors other than a short file are reported by the caller reading br
+		return br, nil
+	}
+	return gzip.NewReader(br)
+}
 
-		// Split the main part into domain and alternative names
-		fields := strings.Fields(mainPart)
-		if len(fields) == 0 {
-			continue
-		}
+// formatLine returns the line of entry in the domains file, without the newline.
+func formatLine(entry *model.DomainEntry) string {
+	return strings.ToValidUTF8(NewDomainLine(entry).String(), "\uFFFD")
+}
 
-		entry := model.DomainEntry{
-			DomainEntry: pb.DomainEntry{
-				Domain:           fields[0],
-				AlternativeNames: fields[1:],
-				Alias:            alias,
-				Enabled:          enabled,
-				Comment:          comment,
-			},
-		}
+// sortedAlternativeNames returns a copy of entry with its alternative names sorted alphabetically,
+// or entry itself if they are sorted already.
+func sortedAlternativeNames(entry *model.DomainEntry) *model.DomainEntry {
+	if slices.IsSorted(entry.AlternativeNames) {
+		return entry
+	}
+	return &model.DomainEntry{
+		DomainEntry: pb.DomainEntry{
+			Domain:           entry.Domain,
+			AlternativeNames: slices.Sorted(slices.Values(entry.AlternativeNames)),
+			Alias:            entry.Alias,
+			Enabled:          entry.Enabled,
+			Comment:          entry.Comment,
+		},
+		Options: entry.Options,
+	}
+}
 
-		// Only add valid domain entries
-		if model.IsValidDomainEntry(&entry) {
-			entries = append(entries, &entry)
-		}
+// WriteOption configures how WriteDomainsFile writes the domains file.
+type WriteOption func(*writeOptions)
+
+type writeOptions struct {
+	omitTrailingNewline  bool
+	sortAlternativeNames bool
+	beforeWrite          func(file *os.File)
+}
+
+// WithoutTrailingNewline omits the newline after the last line of the domains file.
+func WithoutTrailingNewline() WriteOption {
+	return func(o *writeOptions) {
+		o.omitTrailingNewline = true
 	}
+}
 
-	if err := scanner.Err(); err != nil {
-		return nil, err
+// WithSortedAlternativeNames writes the alternative names of each entry sorted alphabetically
+// instead of in the order they were added.
+func WithSortedAlternativeNames() WriteOption {
+	return func(o *writeOptions) {
+		o.sortAlternativeNames = true
 	}
+}
 
-	return entries, nil
+// withBeforeWrite calls fn with the opened domains file before its content is replaced,
+// e.g., to apply the configured mode and ownership.
+func withBeforeWrite(fn func(file *os.File)) WriteOption {
+	return func(o *writeOptions) {
+		o.beforeWrite = fn
+	}
 }
 
 // WriteDomainsFile writes a slice of DomainEntry to a domains.txt file.
 // It formats each entry according to the dehydrated domains.txt format:
 // - Disabled entries are prefixed with '#'
-// - Alternative names are space-separated
+// - Alternative names are space-separated, in their order unless WithSortedAlternativeNames sorts them
 // - Aliases are added with ' > ' separator
 // - Comments are added with ' # ' separator
 // - Entries are automatically sorted alphabetically before writing using the DomainEntries.Sort() method
-func WriteDomainsFile(filename string, entries model.DomainEntries) error {
-	file, err := os.Create(filename)
+// - Each line ends with a newline, unless WithoutTrailingNewline omits it for the last line
+// The file is written as UTF-8 without a byte order mark; invalid UTF-8 sequences are replaced.
+// If filename has a .gz extension, the file is gzip-compressed.
+// The file is rewritten in place, so it keeps its inode, mode and ownership, and symlinks and bind mounts
+// of single files keep working.
+func WriteDomainsFile(filename string, entries model.DomainEntries, opts ...WriteOption) error {
+	o := &writeOptions{}
+	for _, opt := range opts {
+		opt(o)
+	}
+
+	file, err := os.OpenFile(filename, os.O_WRONLY|os.O_CREATE, 0o666)
 	if err != nil {
 		return err
 	}
 	defer file.Close()
 
-	// Sort the entries
-	entries.Sort()
+	// Applied before the old content is replaced, so the new content is never accessible with other permissions
+	if o.beforeWrite != nil {
+		o.beforeWrite(file)
+	}
+	if err := file.Truncate(0); err != nil {
+		return err
+	}
 
-	writer := bufio.NewWriter(file)
-	for _, entry := range entries {
-		// Build the line
-		var line strings.Builder
+	if err := writeEntries(file, filename, entries, o); err != nil {
+		return err
+	}
+	return file.Close()
+}
 
-		// Add comment marker if disabled
-		if !entry.Enabled {
-			line.WriteString("# ")
-		}
+// writeEntries writes the sorted entries to file, gzip-compressed if filename has a .gz extension.
+func writeEntries(file *os.File, filename string, entries model.DomainEntries, o *writeOptions) error {
+	// Sort the entries
+	entries.Sort()
 
-		// Add domain and alternative names
-		line.WriteString(entry.Domain)
-		for _, altName := range entry.AlternativeNames {
-			line.WriteString(" ")
-			line.WriteString(altName)
-		}
+	var w io.Writer = file
+	var gz *gzip.Writer
+	if strings.HasSuffix(filename, ".gz") {
+		gz = gzip.NewWriter(file)
+		w = gz
+	}
 
-		// Add alias if present
-		if entry.Alias != "" {
-			line.WriteString(" > ")
-			line.WriteString(entry.Alias)
+	writer := bufio.NewWriter(w)
+	for i, entry := range entries {
+		if o.sortAlternativeNames {
+			entry = sortedAlternativeNames(entry)
 		}
-
-		// Add comment if present
-		if entry.Comment != "" {
-			line.WriteString(" # ")
-			line.WriteString(entry.Comment)
+		line := formatLine(entry)
+		if i < len(entries)-1 || !o.omitTrailingNewline {
+			line += "\n"
 		}
-
-		// Write the line
-		if _, err = writer.WriteString(line.String() + "\n"); err != nil {
+		if _, err := writer.WriteString(line); err != nil {
 			return err
 		}
 	}
 
-	err = writer.Flush()
-	if err != nil {
+	if err := writer.Flush(); err != nil {
 		return err
 	}
+	if gz != nil {
+		return gz.Close()
+	}
 	return nil
 }
@@ -1,13 +1,20 @@
 package service
 
 import (
+	"compress/gzip"
+	"errors"
+	"io"
 	"os"
 	"path/filepath"
+	"slices"
 	"testing"
 
 	pb "github.com/schumann-it/dehydrated-api-go/plugin/proto"
 
+	"github.com/schumann-it/dehydrated-api-go/internal/dehydrated"
 	"github.com/schumann-it/dehydrated-api-go/internal/model"
+	serviceinterface "github.com/schumann-it/dehydrated-api-go/internal/service/interface"
+	"github.com/schumann-it/dehydrated-api-go/internal/util"
 )
 
 // TestFileOperations tests the core file operations of the DomainService.
@@ -223,3 +230,415 @@ func TestComplexDomainsFile(t *testing.T) {
 		}
 	}
 }
+
+// TestTrailingNewline verifies that the newline after the last line can be omitted.
+func TestTrailingNewline(t *testing.T) {
+	entries := func() model.DomainEntries {
+		return model.DomainEntries{
+			{DomainEntry: pb.DomainEntry{Domain: "example.com", Enabled: true}},
+			{DomainEntry: pb.DomainEntry{Domain: "example.org", Enabled: true}},
+		}
+	}
+
+	tests := []struct {
+		name     string
+		opts     []WriteOption
+		expected string
+	}{
+		{"Default", nil, "example.com\nexample.org\n"},
+		{"Omitted", []WriteOption{WithoutTrailingNewline()}, "example.com\nexample.org"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			file := filepath.Join(t.TempDir(), "domains.txt")
+			if err := WriteDomainsFile(file, entries(), tt.opts...); err != nil {
+				t.Fatalf("Failed to write domains file: %v", err)
+			}
+
+			content, err := os.ReadFile(file)
+			if err != nil {
+				t.Fatalf("Failed to read domains file: %v", err)
+			}
+			if string(content) != tt.expected {
+				t.Errorf("Expected %q, got %q", tt.expected, content)
+			}
+		})
+	}
+
+	t.Run("Service", func(t *testing.T) {
+		dc := dehydrated.NewConfig().WithBaseDir(t.TempDir()).Load()
+		s := NewDomainService(dc, nil).WithoutTrailingNewline()
+		defer s.Close()
+
+		if _, err := s.CreateDomain(&model.CreateDomainRequest{Domain: "example.com"}); err != nil {
+			t.Fatalf("Failed to create domain: %v", err)
+		}
+
+		content, err := os.ReadFile(dc.DomainsFile)
+		if err != nil {
+			t.Fatalf("Failed to read domains file: %v", err)
+		}
+		if string(content) != "# example.com" {
+			t.Errorf("Expected no trailing newline, got %q", content)
+		}
+	})
+}
+
+// TestSortedAlternativeNames verifies that alternative names are written in input order by default
+// and sorted alphabetically if configured.
+func TestSortedAlternativeNames(t *testing.T) {
+	entries := func() model.DomainEntries {
+		return model.DomainEntries{
+			{DomainEntry: pb.DomainEntry{Domain: "example.com", AlternativeNames: []string{"www.example.com", "api.example.com"}, Enabled: true}},
+		}
+	}
+
+	tests := []struct {
+		name     string
+		opts     []WriteOption
+		expected string
+	}{
+		{"Default", nil, "example.com www.example.com api.example.com\n"},
+		{"Sorted", []WriteOption{WithSortedAlternativeNames()}, "example.com api.example.com www.example.com\n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			file := filepath.Join(t.TempDir(), "domains.txt")
+			in := entries()
+			if err := WriteDomainsFile(file, in, tt.opts...); err != nil {
+				t.Fatalf("Failed to write domains file: %v", err)
+			}
+
+			content, err := os.ReadFile(file)
+			if err != nil {
+				t.Fatalf("Failed to read domains file: %v", err)
+			}
+			if string(content) != tt.expected {
+				t.Errorf("Expected %q, got %q", tt.expected, content)
+			}
+			if !slices.Equal(in[0].AlternativeNames, []string{"www.example.com", "api.example.com"}) {
+				t.Errorf("Expected the written entry to be unchanged, got %v", in[0].AlternativeNames)
+			}
+		})
+	}
+
+	t.Run("Service", func(t *testing.T) {
+		for _, sorted := range []bool{false, true} {
+			dc := dehydrated.NewConfig().WithBaseDir(t.TempDir()).Load()
+			s := NewDomainService(dc, nil)
+			if sorted {
+				s.WithSortedAlternativeNames()
+			}
+			defer s.Close()
+
+			req := &model.CreateDomainRequest{
+				Domain:           "example.com",
+				AlternativeNames: []string{"www.example.com", "api.example.com"},
+				Enabled:          util.BoolPtr(true),
+			}
+			entry, err := s.CreateDomain(req)
+			if err != nil {
+				t.Fatalf("Failed to create domain: %v", err)
+			}
+
+			expected := "example.com www.example.com api.example.com\n"
+			if sorted {
+				expected = "example.com api.example.com www.example.com\n"
+				if !slices.IsSorted(entry.AlternativeNames) {
+					t.Errorf("Expected the created entry to have sorted alternative names, got %v", entry.AlternativeNames)
+				}
+				if req.AlternativeNames[0] != "www.example.com" {
+					t.Errorf("Expected the request to be unchanged, got %v", req.AlternativeNames)
+				}
+			}
+			content, err := os.ReadFile(dc.DomainsFile)
+			if err != nil {
+				t.Fatalf("Failed to read domains file: %v", err)
+			}
+			if string(content) != expected {
+				t.Errorf("Sorted %t: expected %q, got %q", sorted, expected, content)
+			}
+
+			// Reordered alternative names are a change unless they are sorted
+			reordered := &model.CreateDomainRequest{
+				Domain:           "example.com",
+				AlternativeNames: []string{"api.example.com", "www.example.com"},
+				Enabled:          util.BoolPtr(true),
+			}
+			result, err := s.ImportDomains([]*model.CreateDomainRequest{reordered}, false)
+			if err != nil {
+				t.Fatalf("Failed to import domain: %v", err)
+			}
+			if sorted && result.Unchanged != 1 {
+				t.Errorf("Expected reordered alternative names to be unchanged when sorted, got %+v", result)
+			}
+			if !sorted && result.Updated != 1 {
+				t.Errorf("Expected reordered alternative names to be an update when unsorted, got %+v", result)
+			}
+
+			// Duplicate alternative names are still rejected regardless of their order
+			_, err = s.CreateDomain(&model.CreateDomainRequest{
+				Domain:           "example.org",
+				AlternativeNames: []string{"www.example.org", "api.example.org", "www.example.org"},
+			})
+			if !errors.Is(err, serviceinterface.ErrInvalidDomainEntry) {
+				t.Errorf("Sorted %t: expected duplicate alternative names to be rejected, got %v", sorted, err)
+			}
+		}
+	})
+
+	t.Run("Reload", func(t *testing.T) {
+		dc := dehydrated.NewConfig().WithBaseDir(t.TempDir()).Load()
+		if err := os.WriteFile(dc.DomainsFile, []byte("example.com www.example.com api.example.com\n"), 0600); err != nil {
+			t.Fatalf("Failed to write domains file: %v", err)
+		}
+		s := NewDomainService(dc, nil).WithSortedAlternativeNames()
+		defer s.Close()
+
+		entry, err := s.GetDomain("example.com", "")
+		if err != nil {
+			t.Fatalf("Failed to get domain: %v", err)
+		}
+		if !slices.Equal(entry.AlternativeNames, []string{"api.example.com", "www.example.com"}) {
+			t.Errorf("Expected loaded alternative names to be sorted, got %v", entry.AlternativeNames)
+		}
+	})
+}
+
+// TestByteOrderMark verifies that a byte order mark is skipped on read and never written.
+func TestByteOrderMark(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "domains.txt")
+	if err := os.WriteFile(file, []byte("\xef\xbb\xbfexample.com www.example.com\r\nexample.org\n"), 0600); err != nil {
+		t.Fatalf("Failed to write domains file: %v", err)
+	}
+
+	entries, err := ReadDomainsFile(file)
+	if err != nil {
+		t.Fatalf("Failed to read domains file: %v", err)
+	}
+	if len(entries) != 2 || entries[0].Domain != "example.com" {
+		t.Fatalf("Expected example.com to be read despite the byte order mark, got %v", entries)
+	}
+
+	if err := WriteDomainsFile(file, entries); err != nil {
+		t.Fatalf("Failed to write domains file: %v", err)
+	}
+	content, err := os.ReadFile(file)
+	if err != nil {
+		t.Fatalf("Failed to read domains file: %v", err)
+	}
+	if string(content) != "example.com www.example.com\nexample.org\n" {
+		t.Errorf("Expected UTF-8 without byte order mark, got %q", content)
+	}
+}
+
+// TestDisabledEntries verifies that disabled entries are written as commented-out lines
+// and read back as disabled, while free-form comments are not mistaken for entries.
+func TestDisabledEntries(t *testing.T) {
+	dc := dehydrated.NewConfig().WithBaseDir(t.TempDir()).Load()
+	content := "# example.org is retired, see example.com\n# example.net www.example.net > net\nexample.com\n"
+	if err := os.WriteFile(dc.DomainsFile, []byte(content), 0600); err != nil {
+		t.Fatalf("Failed to write domains file: %v", err)
+	}
+
+	s := NewDomainService(dc, nil)
+	defer s.Close()
+	if err := s.Reload(); err != nil {
+		t.Fatalf("Failed to reload: %v", err)
+	}
+
+	entries, _, err := s.ListDomains(1, 10, "", "")
+	if err != nil {
+		t.Fatalf("Failed to list domains: %v", err)
+	}
+	if len(entries) != 2 {
+		t.Fatalf("Expected the comment not to be read as an entry, got %v", entries)
+	}
+
+	expectFile := func(expected string) {
+		t.Helper()
+		content, err := os.ReadFile(dc.DomainsFile)
+		if err != nil {
+			t.Fatalf("Failed to read domains file: %v", err)
+		}
+		if string(content) != expected {
+			t.Errorf("Expected %q, got %q", expected, content)
+		}
+	}
+
+	for _, tt := range []struct {
+		enabled  bool
+		expected string
+	}{
+		{true, "example.com\nexample.net www.example.net > net\n"},
+		{false, "example.com\n# example.net www.example.net > net\n"},
+	} {
+		entry, err := s.UpdateDomain("example.net", model.UpdateDomainRequest{Alias: util.StringPtr("net"), Enabled: util.BoolPtr(tt.enabled)})
+		if err != nil {
+			t.Fatalf("Failed to update domain: %v", err)
+		}
+		if entry.Enabled != tt.enabled {
+			t.Errorf("Expected enabled %v, got %v", tt.enabled, entry.Enabled)
+		}
+		expectFile(tt.expected)
+
+		if err := s.Reload(); err != nil {
+			t.Fatalf("Failed to reload: %v", err)
+		}
+		entry, err = s.GetDomain("example.net", "net")
+		if err != nil {
+			t.Fatalf("Failed to get domain: %v", err)
+		}
+		if entry.Enabled != tt.enabled || len(entry.AlternativeNames) != 1 {
+			t.Errorf("Expected enabled %v after reload, got %v", tt.enabled, entry)
+		}
+	}
+}
+
+// TestGzipDomainsFile verifies that gzip-compressed domains files are decompressed when read,
+// and compressed when written to a path with a .gz extension.
+func TestGzipDomainsFile(t *testing.T) {
+	want := []string{"example.com>", "example.net>net", "example.org>org"}
+	names := func(entries model.DomainEntries) []string {
+		result := make([]string, len(entries))
+		for i, e := range entries {
+			result[i] = e.Domain + ">" + e.Alias
+		}
+		slices.Sort(result)
+		return result
+	}
+
+	fixture, err := ReadDomainsFile(filepath.Join("testdata", "domains.txt.gz"))
+	if err != nil {
+		t.Fatalf("Failed to read gzip fixture: %v", err)
+	}
+	if got := names(fixture); !slices.Equal(got, want) {
+		t.Fatalf("Expected %v, got %v", want, got)
+	}
+
+	t.Run("MagicBytes", func(t *testing.T) {
+		// Compressed content is detected without the extension
+		data, err := os.ReadFile(filepath.Join("testdata", "domains.txt.gz"))
+		if err != nil {
+			t.Fatalf("Failed to read gzip fixture: %v", err)
+		}
+		file := filepath.Join(t.TempDir(), "domains.txt")
+		if err := os.WriteFile(file, data, 0600); err != nil {
+			t.Fatalf("Failed to write domains file: %v", err)
+		}
+
+		entries, err := ReadDomainsFile(file)
+		if err != nil {
+			t.Fatalf("Failed to read domains file: %v", err)
+		}
+		if got := names(entries); !slices.Equal(got, want) {
+			t.Errorf("Expected %v, got %v", want, got)
+		}
+	})
+
+	t.Run("RoundTrip", func(t *testing.T) {
+		file := filepath.Join(t.TempDir(), "domains.txt.gz")
+		if err := WriteDomainsFile(file, fixture); err != nil {
+			t.Fatalf("Failed to write domains file: %v", err)
+		}
+
+		f, err := os.Open(file)
+		if err != nil {
+			t.Fatalf("Failed to open domains file: %v", err)
+		}
+		defer f.Close()
+		gz, err := gzip.NewReader(f)
+		if err != nil {
+			t.Fatalf("Expected gzip-compressed file: %v", err)
+		}
+		content, err := io.ReadAll(gz)
+		if err != nil {
+			t.Fatalf("Failed to decompress domains file: %v", err)
+		}
+		if string(content) != "example.com www.example.com\nexample.net > net\n# example.org > org # Disabled\n" {
+			t.Errorf("Unexpected content %q", content)
+		}
+
+		entries, err := ReadDomainsFile(file)
+		if err != nil {
+			t.Fatalf("Failed to read domains file: %v", err)
+		}
+		if got := names(entries); !slices.Equal(got, want) {
+			t.Errorf("Expected %v, got %v", want, got)
+		}
+	})
+
+	t.Run("Service", func(t *testing.T) {
+		// The service creates an empty file, which is read as uncompressed
+		dc := dehydrated.NewConfig().WithBaseDir(t.TempDir()).Load()
+		dc.DomainsFile += ".gz"
+		s := NewDomainService(dc, nil)
+		defer s.Close()
+		if err := s.Reload(); err != nil {
+			t.Fatalf("Failed to load domains file: %v", err)
+		}
+
+		if _, err := s.CreateDomain(&model.CreateDomainRequest{Domain: "example.com", Enabled: util.BoolPtr(true)}); err != nil {
+			t.Fatalf("Failed to create domain: %v", err)
+		}
+		if err := s.Reload(); err != nil {
+			t.Fatalf("Failed to reload domains file: %v", err)
+		}
+		if _, err := s.GetDomain("example.com", ""); err != nil {
+			t.Errorf("Expected example.com after reload: %v", err)
+		}
+	})
+}
+
+// TestWriteDomainsFileInPlace verifies that writing rewrites the domains file in place,
+// keeping its inode, its mode and the symlink pointing to it.
+func TestWriteDomainsFileInPlace(t *testing.T) {
+	entries := model.DomainEntries{{DomainEntry: pb.DomainEntry{Domain: "example.com", Enabled: true}}}
+	dir := t.TempDir()
+	target := filepath.Join(dir, "domains.txt")
+	link := filepath.Join(dir, "link.txt")
+
+	if err := WriteDomainsFile(target, append(entries, &model.DomainEntry{DomainEntry: pb.DomainEntry{Domain: "example.net", Enabled: true}})); err != nil {
+		t.Fatalf("Failed to write domains file: %v", err)
+	}
+	if err := os.Chmod(target, 0o640); err != nil {
+		t.Fatalf("Failed to change mode: %v", err)
+	}
+	before, err := os.Stat(target)
+	if err != nil {
+		t.Fatalf("Failed to stat domains file: %v", err)
+	}
+	if err := os.Symlink(target, link); err != nil {
+		t.Skipf("Symlinks not supported: %v", err)
+	}
+
+	// The new content is shorter, the rest of the old content must not remain
+	if err := WriteDomainsFile(link, entries); err != nil {
+		t.Fatalf("Failed to write domains file: %v", err)
+	}
+
+	fi, err := os.Lstat(link)
+	if err != nil {
+		t.Fatalf("Failed to stat link: %v", err)
+	}
+	if fi.Mode()&os.ModeSymlink == 0 {
+		t.Errorf("Expected the symlink to be kept")
+	}
+	after, err := os.Stat(target)
+	if err != nil {
+		t.Fatalf("Failed to stat domains file: %v", err)
+	}
+	if !os.SameFile(before, after) {
+		t.Errorf("Expected the domains file to be rewritten in place")
+	}
+	if after.Mode().Perm() != 0o640 {
+		t.Errorf("Expected mode 0640, got %v", after.Mode().Perm())
+	}
+	content, err := os.ReadFile(target)
+	if err != nil {
+		t.Fatalf("Failed to read domains file: %v", err)
+	}
+	if string(content) != "example.com\n" {
+		t.Errorf("Unexpected content %q", content)
+	}
+}
@@ -0,0 +1,180 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"go.uber.org/zap"
+
+	"github.com/schumann-it/dehydrated-api-go/internal/model"
+	serviceinterface "github.com/schumann-it/dehydrated-api-go/internal/service/interface"
+	pb "github.com/schumann-it/dehydrated-api-go/plugin/proto"
+)
+
+// ImportDomains creates or replaces the entries of reqs, identified by domain and alias, in a single write.
+// With replace, all entries not in reqs are removed. All requests are validated before anything is changed;
+// invalid and duplicate requests are reported together in an *serviceinterface.ImportError.
+func (s *DomainService) ImportDomains(reqs []*model.CreateDomainRequest, replace bool) (*model.ImportResult, error) {
+	if err := s.ensureLoaded(); err != nil {
+		return nil, err
+	}
+
+	s.logger.Info("Importing domains", zap.Int("entries", len(reqs)), zap.Bool("replace", replace))
+
+	if s.watcher != nil {
+		s.watcher.Disable()
+	}
+
+	var result *model.ImportResult
+	var done <-chan error
+	err := s.withPluginValidation(func(verdicts pluginVerdicts) (err error) {
+		s.mutex.Lock()
+		defer s.mutex.Unlock()
+		result, done, err = s.importEntries(reqs, replace, verdicts)
+		return err
+	})
+
+	// Re-enable watcher after the write (outside of locked section)
+	if s.watcher != nil {
+		s.watcher.Enable()
+	}
+
+	if err != nil {
+		s.logger.Error("Failed to import domains", zap.Error(err))
+		return nil, err
+	}
+
+	if err := s.awaitWrite(done); err != nil {
+		return nil, err
+	}
+
+	s.logger.Info("Imported domains",
+		zap.Int("created", result.Created),
+		zap.Int("updated", result.Updated),
+		zap.Int("removed", result.Removed))
+
+	return result, nil
+}
+
+// importEntries validates and applies an import. It must be called with the mutex held.
+// All entries not validated by the plugins yet are returned together in an *unvalidatedError.
+func (s *DomainService) importEntries(reqs []*model.CreateDomainRequest, replace bool, verdicts pluginVerdicts) (*model.ImportResult, <-chan error, error) {
+	result := &model.ImportResult{}
+	importErr := &serviceinterface.ImportError{}
+
+	imported := make([]*model.DomainEntry, 0, len(reqs))
+	indexes := make([]int, 0, len(reqs)) // index of each imported entry in the cache, -1 if new
+	seen := make(map[string]bool, len(reqs))
+	unvalidated := &unvalidatedError{}
+
+	for i, req := range reqs {
+		entry := &model.DomainEntry{
+			DomainEntry: pb.DomainEntry{
+				Domain:           req.Domain,
+				AlternativeNames: req.AlternativeNames,
+				Alias:            strings.TrimSpace(req.Alias),
+				Enabled:          s.enabledOrDefault(req.Enabled),
+				Comment:          s.markComment(req.Comment),
+			},
+		}
+
+		// The certificate config is not part of the domains file, so imports cannot select a CA
+		if req.CA != "" {
+			importErr.Errors = append(importErr.Errors, serviceinterface.ImportEntryError{
+				Index: i,
+				Err:   fmt.Errorf("%w: ca cannot be set by imports", serviceinterface.ErrInvalidDomainEntry),
+			})
+			continue
+		}
+
+		existing, index := s.findDomainEntry(entry.Domain, entry.Alias)
+		if err := s.validateEntry(entry, existing); err != nil {
+			importErr.Errors = append(importErr.Errors, serviceinterface.ImportEntryError{Index: i, Err: err})
+			continue
+		}
+		if err := s.pluginVerdict(verdicts, entry); err != nil {
+			var u *unvalidatedError
+			switch {
+			case errors.As(err, &u):
+				unvalidated.entries = append(unvalidated.entries, u.entries...)
+			// A failing plugin fails the import as a whole, it does not make the entry invalid
+			case errors.Is(err, serviceinterface.ErrPluginFailed):
+				return nil, nil, err
+			default:
+				importErr.Errors = append(importErr.Errors, serviceinterface.ImportEntryError{Index: i, Err: err})
+				continue
+			}
+		}
+
+		key := entry.Domain + ">" + entry.Alias
+		if seen[key] {
+			importErr.Errors = append(importErr.Errors, serviceinterface.ImportEntryError{
+				Index: i,
+				Err:   fmt.Errorf("%w: duplicates an earlier entry", serviceinterface.ErrDomainExists),
+			})
+			continue
+		}
+		seen[key] = true
+
+		// The options of the line are not part of the import
+		if existing != nil {
+			entry.Options = existing.Options
+		}
+
+		switch {
+		case existing == nil:
+			result.Created++
+		case entry.Equals(existing):
+			result.Unchanged++
+		default:
+			result.Updated++
+		}
+		imported = append(imported, entry)
+		indexes = append(indexes, index)
+	}
+
+	if len(unvalidated.entries) > 0 {
+		return nil, nil, unvalidated
+	}
+	if len(importErr.Errors) > 0 {
+		return nil, nil, importErr
+	}
+
+	var newEntries []*model.DomainEntry
+	if replace {
+		result.Removed = len(s.cache) - result.Updated - result.Unchanged
+		newEntries = imported
+	} else {
+		newEntries = append(make([]*model.DomainEntry, 0, len(s.cache)+result.Created), s.cache...)
+		for i, entry := range imported {
+			if indexes[i] < 0 {
+				newEntries = append(newEntries, entry)
+			} else {
+				newEntries[indexes[i]] = entry
+			}
+		}
+	}
+
+	if result.Created == 0 && result.Updated == 0 && result.Removed == 0 {
+		return result, nil, nil
+	}
+
+	// Imports that do not grow the domains file are allowed, so it can be shrunk below the limit
+	if len(newEntries) > len(s.cache) {
+		if err := s.checkEntryLimit(len(newEntries)); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	done, err := s.persist(newEntries)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// Update cache only after successful write
+	s.cache = newEntries
+	s.warnEntryCount(len(s.cache))
+
+	return result, done, nil
+}
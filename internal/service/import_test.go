@@ -0,0 +1,98 @@
+package service
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/schumann-it/dehydrated-api-go/internal/dehydrated"
+	"github.com/schumann-it/dehydrated-api-go/internal/model"
+	serviceinterface "github.com/schumann-it/dehydrated-api-go/internal/service/interface"
+	"github.com/schumann-it/dehydrated-api-go/internal/util"
+)
+
+func TestImportDomains(t *testing.T) {
+	newService := func(t *testing.T) *DomainService {
+		dc := dehydrated.NewConfig().WithBaseDir(t.TempDir()).Load()
+		s := NewDomainService(dc, nil)
+		t.Cleanup(func() { _ = s.Close() })
+		require.NoError(t, s.Reload())
+
+		for _, req := range []*model.CreateDomainRequest{
+			{Domain: "example.com", Enabled: util.BoolPtr(true)},
+			{Domain: "example.com", Alias: "other", Enabled: util.BoolPtr(true)},
+			{Domain: "example.org", Comment: "old"},
+		} {
+			_, err := s.CreateDomain(req)
+			require.NoError(t, err)
+		}
+		return s
+	}
+
+	reqs := []*model.CreateDomainRequest{
+		{Domain: "example.com", Enabled: util.BoolPtr(true)},
+		{Domain: "example.org", Comment: "new"},
+		{Domain: "example.net", Enabled: util.BoolPtr(true)},
+	}
+
+	t.Run("Merge", func(t *testing.T) {
+		s := newService(t)
+
+		result, err := s.ImportDomains(reqs, false)
+		require.NoError(t, err)
+		require.Equal(t, &model.ImportResult{Created: 1, Updated: 1, Unchanged: 1}, result)
+
+		entries, err := ReadDomainsFile(s.DehydratedConfig.DomainsFile)
+		require.NoError(t, err)
+		require.Len(t, entries, 4)
+
+		entry, err := s.GetDomain("example.org", "")
+		require.NoError(t, err)
+		require.Equal(t, "new", entry.Comment)
+	})
+
+	t.Run("Replace", func(t *testing.T) {
+		s := newService(t)
+
+		result, err := s.ImportDomains(reqs, true)
+		require.NoError(t, err)
+		require.Equal(t, &model.ImportResult{Created: 1, Updated: 1, Unchanged: 1, Removed: 1}, result)
+
+		_, err = s.GetDomain("example.com", "other")
+		require.ErrorIs(t, err, serviceinterface.ErrDomainNotFound)
+
+		entries, err := ReadDomainsFile(s.DehydratedConfig.DomainsFile)
+		require.NoError(t, err)
+		require.Len(t, entries, 3)
+	})
+
+	t.Run("Unchanged", func(t *testing.T) {
+		s := newService(t)
+
+		result, err := s.ImportDomains(reqs[:1], false)
+		require.NoError(t, err)
+		require.Equal(t, &model.ImportResult{Unchanged: 1}, result)
+	})
+
+	t.Run("InvalidRejectsAll", func(t *testing.T) {
+		s := newService(t)
+
+		_, err := s.ImportDomains([]*model.CreateDomainRequest{
+			{Domain: "example.net"},
+			{Domain: "invalid domain"},
+			{Domain: "example.io", Alias: "in valid"},
+			{Domain: "example.net"},
+		}, true)
+		require.ErrorIs(t, err, serviceinterface.ErrInvalidDomainEntry)
+
+		var importErr *serviceinterface.ImportError
+		require.ErrorAs(t, err, &importErr)
+		require.Len(t, importErr.Errors, 3)
+		require.Equal(t, []int{1, 2, 3}, []int{importErr.Errors[0].Index, importErr.Errors[1].Index, importErr.Errors[2].Index})
+		require.ErrorIs(t, importErr.Errors[2].Err, serviceinterface.ErrDomainExists)
+
+		entries, err := ReadDomainsFile(s.DehydratedConfig.DomainsFile)
+		require.NoError(t, err)
+		require.Len(t, entries, 3)
+	})
+}
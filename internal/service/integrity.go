@@ -0,0 +1,157 @@
+package service
+
+import (
+	"crypto/sha256"
+	"fmt"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/schumann-it/dehydrated-api-go/internal/model"
+)
+
+// DefaultIntegrityCheckInterval is the interval of the integrity check of the domains file, applied to zero values.
+const DefaultIntegrityCheckInterval = 5 * time.Minute
+
+// integrityChecker periodically compares the domains file with the cache, see DomainService.CheckIntegrity.
+type integrityChecker struct {
+	interval time.Duration
+	reload   bool // Whether the cache is reloaded from the domains file on a mismatch
+
+	mu     sync.Mutex
+	status *model.IntegrityStatus // result of the last check, nil before the first one
+
+	stop    chan struct{}
+	stopped chan struct{}
+}
+
+// WithIntegrityCheck checks the integrity of the domains file once per interval, see CheckIntegrity.
+// If reload is set, the cache is reloaded from the domains file when it does not match.
+// A zero interval selects DefaultIntegrityCheckInterval.
+func (s *DomainService) WithIntegrityCheck(interval time.Duration, reload bool) *DomainService {
+	if interval <= 0 {
+		interval = DefaultIntegrityCheckInterval
+	}
+	s.integrity = &integrityChecker{
+		interval: interval,
+		reload:   reload,
+		stop:     make(chan struct{}),
+		stopped:  make(chan struct{}),
+	}
+	go s.runIntegrityCheck()
+	return s
+}
+
+func (s *DomainService) runIntegrityCheck() {
+	defer close(s.integrity.stopped)
+
+	ticker := time.NewTicker(s.integrity.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			s.CheckIntegrity()
+		case <-s.integrity.stop:
+			return
+		}
+	}
+}
+
+// IntegrityStatus returns the result of the last integrity check of the domains file,
+// or nil if it was not checked yet or WithIntegrityCheck is not set.
+func (s *DomainService) IntegrityStatus() *model.IntegrityStatus {
+	if s.integrity == nil {
+		return nil
+	}
+
+	s.integrity.mu.Lock()
+	defer s.integrity.mu.Unlock()
+	if s.integrity.status == nil {
+		return nil
+	}
+	status := *s.integrity.status
+	return &status
+}
+
+// CheckIntegrity reads and parses the domains file and compares its entries with the cache, to detect
+// a file that was corrupted, e.g., half-written by another process. A mismatch is logged as an error and,
+// if enabled by WithIntegrityCheck, the cache is reloaded from the file. The check is skipped while changes
+// are pending to be written. It returns the result of the check, which is also kept for IntegrityStatus.
+func (s *DomainService) CheckIntegrity() *model.IntegrityStatus {
+	if s.batcher != nil {
+		if dirty, _ := s.batcher.state(); dirty {
+			s.logger.Debug("Skipping integrity check, changes are pending to be written")
+			return s.IntegrityStatus()
+		}
+	}
+
+	status := &model.IntegrityStatus{Healthy: true, CheckedAt: time.Now()}
+
+	// The file is read while holding the mutex, so it is not compared while being written by the service
+	s.mutex.RLock()
+	entries, err := ReadDomainsFile(s.DehydratedConfig.DomainsFile)
+	cacheHash := hashEntries(s.cache)
+	status.CacheEntries = len(s.cache)
+	s.mutex.RUnlock()
+
+	if err != nil {
+		status.Healthy = false
+		status.Error = fmt.Sprintf("failed to read domains file: %v", err)
+	} else {
+		for _, entry := range entries {
+			s.canonicalize(entry)
+		}
+		status.FileEntries = len(entries)
+		if status.FileEntries != status.CacheEntries || hashEntries(entries) != cacheHash {
+			status.Healthy = false
+			status.Error = fmt.Sprintf("domains file with %d entries does not match the cache with %d entries",
+				status.FileEntries, status.CacheEntries)
+		}
+	}
+
+	if s.integrity != nil {
+		s.integrity.mu.Lock()
+		s.integrity.status = status
+		s.integrity.mu.Unlock()
+	}
+
+	if status.Healthy {
+		s.logger.Debug("Domains file integrity check passed", zap.Int("count", status.FileEntries))
+		return status
+	}
+
+	s.logger.Error("Domains file integrity check failed", zap.String("reason", status.Error))
+	if s.integrity != nil && s.integrity.reload && err == nil {
+		if err := s.Reload(); err != nil {
+			s.logger.Error("Failed to reload domains file after integrity check", zap.Error(err))
+		}
+	}
+
+	return status
+}
+
+// closeIntegrityCheck stops the periodic integrity check, if enabled.
+func (s *DomainService) closeIntegrityCheck() {
+	if s.integrity == nil {
+		return
+	}
+	close(s.integrity.stop)
+	<-s.integrity.stopped
+}
+
+// hashEntries returns a hash of the lines of entries in the order they are written to the domains file.
+func hashEntries(entries []*model.DomainEntry) [sha256.Size]byte {
+	sorted := append(make(model.DomainEntries, 0, len(entries)), entries...)
+	sorted.Sort()
+
+	h := sha256.New()
+	for _, entry := range sorted {
+		h.Write([]byte(formatLine(entry) + "\n"))
+	}
+
+	var sum [sha256.Size]byte
+	h.Sum(sum[:0])
+	return sum
+}
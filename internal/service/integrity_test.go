@@ -0,0 +1,131 @@
+package service
+
+import (
+	"os"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/schumann-it/dehydrated-api-go/internal/dehydrated"
+	"github.com/schumann-it/dehydrated-api-go/internal/model"
+	serviceinterface "github.com/schumann-it/dehydrated-api-go/internal/service/interface"
+)
+
+// newIntegrityService creates a service with two entries written to its domains file.
+func newIntegrityService(t *testing.T, reload bool) *DomainService {
+	t.Helper()
+
+	dc := dehydrated.NewConfig().WithBaseDir(t.TempDir()).Load()
+	s := NewDomainService(dc, nil).WithIntegrityCheck(time.Hour, reload)
+	t.Cleanup(func() { s.Close() })
+
+	for _, domain := range []string{"example.com", "example.org"} {
+		_, err := s.CreateDomain(&model.CreateDomainRequest{Domain: domain, AlternativeNames: []string{"www." + domain}})
+		require.NoError(t, err)
+	}
+
+	return s
+}
+
+func TestCheckIntegrity(t *testing.T) {
+	t.Run("Healthy", func(t *testing.T) {
+		s := newIntegrityService(t, false)
+		require.Nil(t, s.IntegrityStatus(), "no status before the first check")
+
+		status := s.CheckIntegrity()
+		require.True(t, status.Healthy, status.Error)
+		require.Equal(t, 2, status.FileEntries)
+		require.Equal(t, 2, status.CacheEntries)
+		require.Equal(t, status, s.IntegrityStatus())
+	})
+
+	t.Run("HalfWrittenFile", func(t *testing.T) {
+		s := newIntegrityService(t, false)
+		require.NoError(t, os.WriteFile(s.DehydratedConfig.DomainsFile, []byte("# example.com www.exa"), 0600))
+
+		status := s.CheckIntegrity()
+		require.False(t, status.Healthy)
+		require.Equal(t, 1, status.FileEntries)
+		require.Equal(t, 2, status.CacheEntries)
+		require.Contains(t, status.Error, "does not match the cache")
+		require.False(t, s.IntegrityStatus().Healthy)
+
+		// The cache is kept without reload
+		_, err := s.GetDomain("example.org", "")
+		require.NoError(t, err)
+	})
+
+	t.Run("ChangedLine", func(t *testing.T) {
+		s := newIntegrityService(t, false)
+		require.NoError(t, os.WriteFile(s.DehydratedConfig.DomainsFile, []byte("# example.com www.example.net\n# example.org www.example.org\n"), 0600))
+
+		status := s.CheckIntegrity()
+		require.False(t, status.Healthy, "same number of entries with different content")
+	})
+
+	t.Run("UnreadableFile", func(t *testing.T) {
+		s := newIntegrityService(t, true)
+		require.NoError(t, os.Remove(s.DehydratedConfig.DomainsFile))
+		require.NoError(t, os.Mkdir(s.DehydratedConfig.DomainsFile, 0700))
+
+		status := s.CheckIntegrity()
+		require.False(t, status.Healthy)
+		require.Contains(t, status.Error, "failed to read domains file")
+
+		// A file that cannot be read is not reloaded
+		_, err := s.GetDomain("example.org", "")
+		require.NoError(t, err)
+	})
+
+	t.Run("Reload", func(t *testing.T) {
+		s := newIntegrityService(t, true)
+		require.NoError(t, os.WriteFile(s.DehydratedConfig.DomainsFile, []byte("# example.com www.example.com\n"), 0600))
+
+		require.False(t, s.CheckIntegrity().Healthy)
+
+		_, err := s.GetDomain("example.org", "")
+		require.ErrorIs(t, err, serviceinterface.ErrDomainNotFound, "the cache is reloaded from the file")
+		require.True(t, s.CheckIntegrity().Healthy)
+	})
+
+	t.Run("SortedAlternativeNames", func(t *testing.T) {
+		dc := dehydrated.NewConfig().WithBaseDir(t.TempDir()).Load()
+		require.NoError(t, os.WriteFile(dc.DomainsFile, []byte("example.com www.example.com api.example.com\n"), 0600))
+		s := NewDomainService(dc, nil).WithSortedAlternativeNames()
+		defer s.Close()
+		require.NoError(t, s.Reload())
+
+		require.True(t, s.CheckIntegrity().Healthy, "the file is compared in canonical form")
+	})
+
+	t.Run("PendingWrites", func(t *testing.T) {
+		dc := dehydrated.NewConfig().WithBaseDir(t.TempDir()).Load()
+		s := NewDomainService(dc, nil).WithWriteCoalescing(time.Hour, 100, false)
+		defer s.Close()
+
+		_, err := s.CreateDomain(&model.CreateDomainRequest{Domain: "example.com"})
+		require.NoError(t, err)
+
+		require.Nil(t, s.CheckIntegrity(), "the check is skipped while changes are pending")
+	})
+
+	t.Run("Periodic", func(t *testing.T) {
+		dc := dehydrated.NewConfig().WithBaseDir(t.TempDir()).Load()
+		require.NoError(t, os.WriteFile(dc.DomainsFile, []byte("example.com\n"), 0600))
+		s := NewDomainService(dc, nil)
+		require.NoError(t, s.Reload())
+		s.WithIntegrityCheck(10*time.Millisecond, false)
+		defer s.Close()
+
+		require.Eventually(t, func() bool {
+			status := s.IntegrityStatus()
+			return status != nil && status.Healthy
+		}, time.Second, 5*time.Millisecond)
+
+		require.NoError(t, os.WriteFile(dc.DomainsFile, []byte("example.c"), 0600))
+		require.Eventually(t, func() bool {
+			return !s.IntegrityStatus().Healthy
+		}, time.Second, 5*time.Millisecond)
+	})
+}
@@ -1,6 +1,104 @@
 package serviceinterface
 
-import "github.com/schumann-it/dehydrated-api-go/internal/model"
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/schumann-it/dehydrated-api-go/internal/dehydrated"
+	"github.com/schumann-it/dehydrated-api-go/internal/model"
+)
+
+var (
+	// ErrDomainExists is returned when an entry with the same domain and alias already exists.
+	ErrDomainExists = errors.New("domain exists")
+
+	// ErrDomainNotFound is returned when no entry with the given domain and alias exists.
+	ErrDomainNotFound = errors.New("domain not found")
+
+	// ErrInvalidDomainEntry is returned when an entry does not pass validation.
+	ErrInvalidDomainEntry = errors.New("invalid domain entry")
+
+	// ErrPluginFailed is returned by ListDomains and GetDomain with WithStrictPlugins if a plugin failed.
+	ErrPluginFailed = errors.New("plugin failed")
+
+	// ErrTooManyEntries is returned when creating entries would exceed the maximum number of entries.
+	ErrTooManyEntries = errors.New("too many domain entries")
+
+	// ErrCacheUnavailable is returned when the domains file has never been loaded successfully and cannot be read,
+	// so the entries are unknown.
+	ErrCacheUnavailable = errors.New("domains file unavailable")
+
+	// ErrNoDomains is returned by readiness checks requiring domain entries if the domains file has none.
+	ErrNoDomains = errors.New("domains file has no entries")
+
+	// ErrTimeBudgetExceeded is returned by ListDomains with WithDeadline, together with the entries,
+	// if the deadline passed before all plugins provided metadata.
+	ErrTimeBudgetExceeded = errors.New("time budget exceeded")
+
+	// ErrDomainDisabled is returned when running dehydrated for a disabled entry without override.
+	ErrDomainDisabled = errors.New("domain is disabled")
+)
+
+// PluginFailureError reports the plugin errors that failed a strict ListDomains or GetDomain call.
+// It wraps ErrPluginFailed.
+type PluginFailureError struct {
+	Errors []*model.PluginError
+}
+
+func (e *PluginFailureError) Error() string {
+	msgs := make([]string, len(e.Errors))
+	for i, pe := range e.Errors {
+		msgs[i] = fmt.Sprintf("%s for %s: %s", pe.Plugin, pe.Domain, pe.Message)
+	}
+	return fmt.Sprintf("%s: %s", ErrPluginFailed, strings.Join(msgs, "; "))
+}
+
+func (e *PluginFailureError) Unwrap() error {
+	return ErrPluginFailed
+}
+
+// FieldError reports the field of an entry that did not pass validation. It wraps ErrInvalidDomainEntry.
+type FieldError struct {
+	// Field is the JSON name of the invalid field, e.g., "alias".
+	Field  string
+	Reason string
+}
+
+func (e *FieldError) Error() string {
+	return fmt.Sprintf("%s: %s", ErrInvalidDomainEntry, e.Reason)
+}
+
+func (e *FieldError) Unwrap() error {
+	return ErrInvalidDomainEntry
+}
+
+// ImportEntryError is the error of a single request of an import.
+type ImportEntryError struct {
+	// Index is the index of the request in the imported requests.
+	Index int
+	Err   error
+}
+
+// ImportError reports the invalid requests that rejected an import, ordered by index.
+// It wraps ErrInvalidDomainEntry.
+type ImportError struct {
+	Errors []ImportEntryError
+}
+
+func (e *ImportError) Error() string {
+	msgs := make([]string, len(e.Errors))
+	for i, ee := range e.Errors {
+		msgs[i] = fmt.Sprintf("entry %d: %v", ee.Index, ee.Err)
+	}
+	return fmt.Sprintf("%s: %s", ErrInvalidDomainEntry, strings.Join(msgs, "; "))
+}
+
+func (e *ImportError) Unwrap() error {
+	return ErrInvalidDomainEntry
+}
 
 // DomainService defines the interface for domain operations.
 // It provides methods for managing domain entries in the dehydrated configuration.
@@ -9,23 +107,206 @@ type DomainService interface {
 	// page and perPage are 1-based. If page is 0 or negative, it defaults to 1.
 	// If perPage is 0 or negative, it defaults to DefaultPerPage (100).
 	// If perPage exceeds MaxPerPage (1000), it is capped to MaxPerPage.
-	// sortOrder can be "asc" or "desc" to sort by domain field (optional - defaults to alphabetical order).
+	// sortOrder is a comma-separated list of sort fields as parsed by model.ParseSort, e.g., "-enabled,domain",
+	// or "asc" or "desc" to sort by domain field (optional - defaults to the order of the domains file).
 	// search is an optional search term to filter domains by domain field using contains().
-	ListDomains(page, perPage int, sortOrder, search string) ([]*model.DomainEntry, *model.PaginationInfo, error)
+	// With WithDeadline, it may return the entries and pagination together with ErrTimeBudgetExceeded.
+	ListDomains(page, perPage int, sortOrder, search string, opts ...QueryOption) ([]*model.DomainEntry, *model.PaginationInfo, error)
+
+	// AllDomains returns all domain entries in the order of the domains file, taken as a single snapshot.
+	// With WithDeadline, it may return the entries together with ErrTimeBudgetExceeded.
+	AllDomains(opts ...QueryOption) ([]*model.DomainEntry, error)
+
+	// ChangeSeq returns the change sequence, which increases on every change of the domain entries and every
+	// reload of the domains file, and is stable otherwise.
+	ChangeSeq() uint64
 
 	// GetDomain retrieves a specific domain entry by its domain name.
 	// If multiple entries exist with the same domain, returns the first match.
-	GetDomain(domain, alias string) (*model.DomainEntry, error)
+	GetDomain(domain, alias string, opts ...QueryOption) (*model.DomainEntry, error)
+
+	// Position returns the zero-based index of the entry identified by domain and alias in the sorted
+	// domains file. It returns ErrDomainNotFound if there is no such entry.
+	Position(domain, alias string) (int, error)
 
 	// CreateDomain creates a new domain entry with the given configuration.
+	// It returns ErrDomainExists if an entry with the same domain and alias already exists.
 	CreateDomain(req *model.CreateDomainRequest) (*model.DomainEntry, error)
 
+	// PreviewDomain returns the line of the domains file an entry created from req would be written as,
+	// without writing it. It returns an error wrapping ErrInvalidDomainEntry if the entry is invalid.
+	PreviewDomain(req *model.CreateDomainRequest) (string, error)
+
 	// UpdateDomain updates an existing domain entry with the given configuration.
 	UpdateDomain(domain string, req model.UpdateDomainRequest) (*model.DomainEntry, error)
 
+	// UpsertDomain updates the entry identified by domain and the alias of req, or creates it if it
+	// does not exist, atomically. It reports whether the entry was created.
+	UpsertDomain(domain string, req model.UpdateDomainRequest) (*model.DomainEntry, bool, error)
+
+	// ReplaceDomain replaces the entry identified by domain and alias with entry.
+	// The domain name of the entry cannot be changed, the alias can. It returns ErrDomainNotFound
+	// if there is no such entry and ErrDomainExists if the new alias collides with another entry.
+	ReplaceDomain(domain, alias string, entry *model.DomainEntry) (*model.DomainEntry, error)
+
+	// PatchDomain replaces the entry identified by domain and alias like ReplaceDomain with a copy of it
+	// changed by patch, atomically. An error of patch is returned as is.
+	PatchDomain(domain, alias string, patch func(entry *model.DomainEntry) error) (*model.DomainEntry, error)
+
+	// RenameDomain changes the primary domain name, and optionally the alias, of the entry identified by
+	// domain and alias, keeping its alternative names, enabled state and comment. It returns
+	// ErrDomainNotFound if there is no such entry and ErrDomainExists if the new name collides with another entry.
+	RenameDomain(domain, alias string, req model.RenameDomainRequest) (*model.DomainEntry, error)
+
 	// DeleteDomain removes a domain entry by its domain name.
 	DeleteDomain(domain string, req model.DeleteDomainRequest) error
 
+	// SetEnabled enables or disables all domain entries matching search (same semantics as for ListDomains)
+	// and, if filterEnabled is set, their current enabled state. It returns the number of changed entries.
+	SetEnabled(search string, filterEnabled *bool, enabled bool) (int, error)
+
+	// DeleteDomains removes all domain entries matching search (same semantics as for ListDomains) and, if
+	// filterEnabled is set, their current enabled state in a single write. It returns the deleted entries.
+	DeleteDomains(search string, filterEnabled *bool) ([]*model.DomainEntry, error)
+
+	// NormalizeDomains rewrites the domains file in canonical form: names are lowercased, duplicate
+	// alternative names and entries are removed and the entries are sorted. With dryRun, the file is
+	// left unchanged. It returns the lines changed by the normalization.
+	NormalizeDomains(dryRun bool) (*model.NormalizeResult, error)
+
+	// ImportDomains creates or replaces the entries of reqs, identified by domain and alias, in a single write.
+	// With replace, all entries not in reqs are removed. All requests are validated first; if any is invalid
+	// or duplicates another one, nothing is changed and an *ImportError is returned.
+	ImportDomains(reqs []*model.CreateDomainRequest, replace bool) (*model.ImportResult, error)
+
+	// Summary aggregates the status of all domain entries and their certificates, considering
+	// certificates expiring within expiryThreshold as expiring.
+	Summary(expiryThreshold time.Duration) (*model.Summary, error)
+
+	// Reconcile compares the entries to the certificate directories below CertDir: it returns the enabled
+	// entries without an issued certificate and the directories no entry refers to.
+	Reconcile() (*model.Reconciliation, error)
+
+	// EffectiveConfig returns the dehydrated configuration of the entry identified by domain and alias,
+	// with the overrides of its certificate's config file applied, as passed to plugins.
+	EffectiveConfig(domain, alias string) (*dehydrated.Config, error)
+
+	// RawDomainLine returns the line of the entry identified by domain and alias in the domains file
+	// with its parsed components. It returns ErrDomainNotFound if the file has no such line.
+	RawDomainLine(domain, alias string) (*model.RawDomainLine, error)
+
+	// KeyFingerprint returns the fingerprint of the public key of the certificate of the entry identified by
+	// domain and alias, never the key itself. It returns dehydrated.ErrKeyNotFound if neither the private key
+	// nor the certificate exists.
+	KeyFingerprint(domain, alias string) (*dehydrated.KeyInfo, error)
+
+	// RefreshOCSP runs dehydrated to fetch a new OCSP response for the entry identified by domain
+	// and alias, if due, and returns the current OCSP response. It returns ErrDomainDisabled for
+	// a disabled entry unless allowDisabled is set. opts apply to this run of dehydrated only.
+	RefreshOCSP(ctx context.Context, domain, alias string, allowDisabled bool, opts dehydrated.CronOptions) (*dehydrated.OCSPInfo, error)
+
+	// PluginErrors returns the given page of the errors plugins returned recently while enriching
+	// metadata, newest first. page and perPage default and are capped like for ListDomains.
+	PluginErrors(page, perPage int) ([]*model.PluginError, *model.PaginationInfo, error)
+
+	// Plugins returns the given page of the registered plugins, sorted by name.
+	// page and perPage default and are capped like for PluginErrors.
+	Plugins(page, perPage int) ([]*model.PluginInfo, *model.PaginationInfo, error)
+
 	// Close performs any necessary cleanup when the service is no longer needed.
 	Close() error
 }
+
+// QueryOptions control how ListDomains and GetDomain load domain entries.
+type QueryOptions struct {
+	// SkipMetadata skips the metadata enrichment by plugins.
+	SkipMetadata bool
+
+	// SkipDisabledMetadata skips the metadata enrichment by plugins for disabled entries.
+	SkipDisabledMetadata bool
+
+	// Enabled restricts ListDomains to entries with the given enabled state, if set.
+	Enabled *bool
+
+	// CertStatus restricts ListDomains to entries whose certificate has the given status
+	// (one of the dehydrated.CertStatus* values), if set.
+	CertStatus string
+
+	// ExpiryThreshold is the threshold within which certificates have the expiring status.
+	ExpiryThreshold time.Duration
+
+	// StrictPlugins fails the call with a PluginFailureError if any plugin fails to provide metadata.
+	StrictPlugins bool
+
+	// Deadline stops the metadata enrichment of ListDomains once passed, if set.
+	Deadline time.Time
+
+	// Explain records the provenance of the metadata values, see model.DomainEntry.Provenance.
+	Explain bool
+}
+
+// QueryOption modifies the QueryOptions of a single ListDomains or GetDomain call.
+type QueryOption func(*QueryOptions)
+
+// WithoutMetadata skips the metadata enrichment by plugins.
+func WithoutMetadata() QueryOption {
+	return func(o *QueryOptions) {
+		o.SkipMetadata = true
+	}
+}
+
+// WithoutDisabledMetadata skips the metadata enrichment by plugins for disabled entries.
+func WithoutDisabledMetadata() QueryOption {
+	return func(o *QueryOptions) {
+		o.SkipDisabledMetadata = true
+	}
+}
+
+// WithEnabled restricts ListDomains to entries with the given enabled state.
+func WithEnabled(enabled bool) QueryOption {
+	return func(o *QueryOptions) {
+		o.Enabled = &enabled
+	}
+}
+
+// WithCertStatus restricts ListDomains to entries whose certificate has the given status,
+// considering certificates expiring within expiryThreshold as expiring.
+// The certificates are only read for ListDomains calls with this option.
+func WithCertStatus(status string, expiryThreshold time.Duration) QueryOption {
+	return func(o *QueryOptions) {
+		o.CertStatus = status
+		o.ExpiryThreshold = expiryThreshold
+	}
+}
+
+// WithStrictPlugins fails ListDomains and GetDomain with a PluginFailureError if any plugin fails
+// to provide metadata, instead of embedding the error in the metadata.
+func WithStrictPlugins() QueryOption {
+	return func(o *QueryOptions) {
+		o.StrictPlugins = true
+	}
+}
+
+// WithDeadline bounds the metadata enrichment of ListDomains: once deadline passes, no more plugins
+// are called and ListDomains returns the entries with the metadata gathered so far and ErrTimeBudgetExceeded.
+func WithDeadline(deadline time.Time) QueryOption {
+	return func(o *QueryOptions) {
+		o.Deadline = deadline
+	}
+}
+
+// WithExplain records which plugin produced each metadata value of the entries and how long it took.
+func WithExplain() QueryOption {
+	return func(o *QueryOptions) {
+		o.Explain = true
+	}
+}
+
+// NewQueryOptions returns the QueryOptions resulting from applying opts to the defaults.
+func NewQueryOptions(opts ...QueryOption) QueryOptions {
+	o := QueryOptions{}
+	for _, opt := range opts {
+		opt(&o)
+	}
+	return o
+}
@@ -3,18 +3,30 @@
 package serviceinterface
 
 import (
+	"context"
 	"fmt"
+	"sync"
+	"time"
 
+	"github.com/schumann-it/dehydrated-api-go/internal/dehydrated"
 	"github.com/schumann-it/dehydrated-api-go/internal/model"
+	"github.com/schumann-it/dehydrated-api-go/internal/plugin/config"
 	pb "github.com/schumann-it/dehydrated-api-go/plugin/proto"
+	"google.golang.org/grpc"
+	"google.golang.org/protobuf/types/known/structpb"
 )
 
 // MockDomainService implements the DomainService interface for testing.
 // It provides a simple in-memory implementation of domain operations that returns successful responses.
 type MockDomainService struct{}
 
+// ChangeSeq returns no changes for testing.
+func (m *MockDomainService) ChangeSeq() uint64 {
+	return 0
+}
+
 // ListDomains returns an empty list of domains for testing.
-func (m *MockDomainService) ListDomains(page, perPage int, sortOrder, search string) ([]*model.DomainEntry, *model.PaginationInfo, error) {
+func (m *MockDomainService) ListDomains(page, perPage int, sortOrder, search string, _ ...QueryOption) ([]*model.DomainEntry, *model.PaginationInfo, error) {
 	return []*model.DomainEntry{}, &model.PaginationInfo{
 		CurrentPage: page,
 		PerPage:     perPage,
@@ -25,8 +37,13 @@ func (m *MockDomainService) ListDomains(page, perPage int, sortOrder, search str
 	}, nil
 }
 
+// AllDomains returns an empty list of domains for testing.
+func (m *MockDomainService) AllDomains(_ ...QueryOption) ([]*model.DomainEntry, error) {
+	return []*model.DomainEntry{}, nil
+}
+
 // GetDomain returns a mock domain entry for testing.
-func (m *MockDomainService) GetDomain(domain, _ string) (*model.DomainEntry, error) {
+func (m *MockDomainService) GetDomain(domain, _ string, _ ...QueryOption) (*model.DomainEntry, error) {
 	return &model.DomainEntry{
 		DomainEntry: pb.DomainEntry{
 			Domain:  domain,
@@ -35,12 +52,17 @@ func (m *MockDomainService) GetDomain(domain, _ string) (*model.DomainEntry, err
 	}, nil
 }
 
+// Position returns the first position for testing.
+func (m *MockDomainService) Position(_, _ string) (int, error) {
+	return 0, nil
+}
+
 // CreateDomain creates a mock domain entry for testing.
 func (m *MockDomainService) CreateDomain(req *model.CreateDomainRequest) (*model.DomainEntry, error) {
 	return &model.DomainEntry{
 		DomainEntry: pb.DomainEntry{
 			Domain:  req.Domain,
-			Enabled: req.Enabled,
+			Enabled: req.Enabled != nil && *req.Enabled,
 		},
 	}, nil
 }
@@ -55,11 +77,110 @@ func (m *MockDomainService) UpdateDomain(domain string, _ model.UpdateDomainRequ
 	}, nil
 }
 
+// UpsertDomain updates a mock domain entry for testing, it never creates one.
+func (m *MockDomainService) UpsertDomain(domain string, req model.UpdateDomainRequest) (*model.DomainEntry, bool, error) {
+	entry, err := m.UpdateDomain(domain, req)
+	return entry, false, err
+}
+
+// ReplaceDomain returns the given entry for testing.
+func (m *MockDomainService) ReplaceDomain(_, _ string, entry *model.DomainEntry) (*model.DomainEntry, error) {
+	return entry, nil
+}
+
+// PatchDomain patches the entry returned by GetDomain for testing.
+func (m *MockDomainService) PatchDomain(domain, alias string, patch func(entry *model.DomainEntry) error) (*model.DomainEntry, error) {
+	entry, _ := m.GetDomain(domain, alias)
+	if err := patch(entry); err != nil {
+		return nil, err
+	}
+	return entry, nil
+}
+
+// RenameDomain returns the entry with the new name for testing.
+func (m *MockDomainService) RenameDomain(_, alias string, req model.RenameDomainRequest) (*model.DomainEntry, error) {
+	if req.Alias != nil {
+		alias = *req.Alias
+	}
+	return &model.DomainEntry{
+		DomainEntry: pb.DomainEntry{
+			Domain:  req.Domain,
+			Alias:   alias,
+			Enabled: true,
+		},
+	}, nil
+}
+
 // DeleteDomain simulates deleting a domain entry for testing.
 func (m *MockDomainService) DeleteDomain(_ string, _ model.DeleteDomainRequest) error {
 	return nil
 }
 
+// SetEnabled simulates a bulk update of the enabled state for testing.
+func (m *MockDomainService) SetEnabled(_ string, _ *bool, _ bool) (int, error) {
+	return 0, nil
+}
+
+// DeleteDomains simulates a bulk deletion for testing.
+func (m *MockDomainService) DeleteDomains(_ string, _ *bool) ([]*model.DomainEntry, error) {
+	return nil, nil
+}
+
+// NormalizeDomains simulates a normalization of the domains file for testing.
+func (m *MockDomainService) NormalizeDomains(dryRun bool) (*model.NormalizeResult, error) {
+	return &model.NormalizeResult{DryRun: dryRun, Changes: []model.NormalizeChange{}}, nil
+}
+
+// PreviewDomain simulates rendering an entry for testing.
+func (m *MockDomainService) PreviewDomain(req *model.CreateDomainRequest) (string, error) {
+	return req.Domain, nil
+}
+
+// ImportDomains simulates creating all imported entries for testing.
+func (m *MockDomainService) ImportDomains(reqs []*model.CreateDomainRequest, _ bool) (*model.ImportResult, error) {
+	return &model.ImportResult{Created: len(reqs)}, nil
+}
+
+// Summary returns an empty summary for testing.
+func (m *MockDomainService) Summary(_ time.Duration) (*model.Summary, error) {
+	return &model.Summary{}, nil
+}
+
+// Reconcile returns no drift for testing.
+func (m *MockDomainService) Reconcile() (*model.Reconciliation, error) {
+	return &model.Reconciliation{MissingCerts: []string{}, OrphanCerts: []string{}}, nil
+}
+
+// EffectiveConfig returns a default configuration for testing.
+func (m *MockDomainService) EffectiveConfig(_, _ string) (*dehydrated.Config, error) {
+	return dehydrated.NewConfig(), nil
+}
+
+// RawDomainLine returns the line of a plain entry for testing.
+func (m *MockDomainService) RawDomainLine(domain, alias string) (*model.RawDomainLine, error) {
+	return &model.RawDomainLine{Line: 1, Raw: domain, Primary: domain, SANs: []string{}, Alias: alias}, nil
+}
+
+// KeyFingerprint returns the fingerprint of an ECDSA key for testing.
+func (m *MockDomainService) KeyFingerprint(_, _ string) (*dehydrated.KeyInfo, error) {
+	return &dehydrated.KeyInfo{Algorithm: "ECDSA", Size: 256, SHA256: "00", Source: "privkey.pem"}, nil
+}
+
+// RefreshOCSP returns a good OCSP response for testing.
+func (m *MockDomainService) RefreshOCSP(_ context.Context, _, _ string, _ bool, _ dehydrated.CronOptions) (*dehydrated.OCSPInfo, error) {
+	return &dehydrated.OCSPInfo{Status: "good"}, nil
+}
+
+// PluginErrors returns no plugin errors for testing.
+func (m *MockDomainService) PluginErrors(page, perPage int) ([]*model.PluginError, *model.PaginationInfo, error) {
+	return []*model.PluginError{}, &model.PaginationInfo{CurrentPage: page, PerPage: perPage}, nil
+}
+
+// Plugins returns no plugins for testing.
+func (m *MockDomainService) Plugins(page, perPage int) ([]*model.PluginInfo, *model.PaginationInfo, error) {
+	return []*model.PluginInfo{}, model.NewPaginationInfo(page, perPage, 0), nil
+}
+
 // Close performs cleanup for the mock service.
 func (m *MockDomainService) Close() error {
 	return nil
@@ -69,16 +190,31 @@ func (m *MockDomainService) Close() error {
 // It provides a simple in-memory implementation of domain operations.
 type MockErrDomainService struct{}
 
+// ChangeSeq returns no changes for testing.
+func (m *MockErrDomainService) ChangeSeq() uint64 {
+	return 0
+}
+
 // ListDomains returns an empty list of domains for testing.
-func (m *MockErrDomainService) ListDomains(page, perPage int, sortOrder, search string) ([]*model.DomainEntry, *model.PaginationInfo, error) {
+func (m *MockErrDomainService) ListDomains(page, perPage int, sortOrder, search string, _ ...QueryOption) ([]*model.DomainEntry, *model.PaginationInfo, error) {
 	return nil, nil, fmt.Errorf("mock error")
 }
 
+// AllDomains simulates a failing listing for testing.
+func (m *MockErrDomainService) AllDomains(_ ...QueryOption) ([]*model.DomainEntry, error) {
+	return nil, fmt.Errorf("mock error")
+}
+
 // GetDomain returns a mock domain entry for testing.
-func (m *MockErrDomainService) GetDomain(_, _ string) (*model.DomainEntry, error) {
+func (m *MockErrDomainService) GetDomain(_, _ string, _ ...QueryOption) (*model.DomainEntry, error) {
 	return nil, fmt.Errorf("mock error")
 }
 
+// Position simulates failing to locate an entry for testing.
+func (m *MockErrDomainService) Position(_, _ string) (int, error) {
+	return 0, fmt.Errorf("mock error")
+}
+
 // CreateDomain creates a mock domain entry for testing.
 func (m *MockErrDomainService) CreateDomain(_ *model.CreateDomainRequest) (*model.DomainEntry, error) {
 	return nil, fmt.Errorf("mock error")
@@ -89,12 +225,265 @@ func (m *MockErrDomainService) UpdateDomain(_ string, _ model.UpdateDomainReques
 	return nil, fmt.Errorf("mock error")
 }
 
+// UpsertDomain simulates a failing upsert for testing.
+func (m *MockErrDomainService) UpsertDomain(_ string, _ model.UpdateDomainRequest) (*model.DomainEntry, bool, error) {
+	return nil, false, fmt.Errorf("mock error")
+}
+
+// ReplaceDomain simulates a failing replacement for testing.
+func (m *MockErrDomainService) ReplaceDomain(_, _ string, _ *model.DomainEntry) (*model.DomainEntry, error) {
+	return nil, fmt.Errorf("mock error")
+}
+
+// PatchDomain simulates a failing patch for testing.
+func (m *MockErrDomainService) PatchDomain(_, _ string, _ func(entry *model.DomainEntry) error) (*model.DomainEntry, error) {
+	return nil, fmt.Errorf("mock error")
+}
+
+// RenameDomain simulates a failing rename for testing.
+func (m *MockErrDomainService) RenameDomain(_, _ string, _ model.RenameDomainRequest) (*model.DomainEntry, error) {
+	return nil, fmt.Errorf("mock error")
+}
+
 // DeleteDomain simulates deleting a domain entry for testing.
 func (m *MockErrDomainService) DeleteDomain(_ string, _ model.DeleteDomainRequest) error {
 	return fmt.Errorf("mock error")
 }
 
+// SetEnabled simulates a bulk update of the enabled state for testing.
+func (m *MockErrDomainService) SetEnabled(_ string, _ *bool, _ bool) (int, error) {
+	return 0, fmt.Errorf("mock error")
+}
+
+// DeleteDomains simulates a failing bulk deletion for testing.
+func (m *MockErrDomainService) DeleteDomains(_ string, _ *bool) ([]*model.DomainEntry, error) {
+	return nil, fmt.Errorf("mock error")
+}
+
+// NormalizeDomains simulates a failing normalization for testing.
+func (m *MockErrDomainService) NormalizeDomains(_ bool) (*model.NormalizeResult, error) {
+	return nil, fmt.Errorf("mock error")
+}
+
+// PreviewDomain simulates a failing preview for testing.
+func (m *MockErrDomainService) PreviewDomain(_ *model.CreateDomainRequest) (string, error) {
+	return "", fmt.Errorf("mock error")
+}
+
+// ImportDomains simulates a failing import for testing.
+func (m *MockErrDomainService) ImportDomains(_ []*model.CreateDomainRequest, _ bool) (*model.ImportResult, error) {
+	return nil, fmt.Errorf("mock error")
+}
+
+// Summary simulates a failing summary for testing.
+func (m *MockErrDomainService) Summary(_ time.Duration) (*model.Summary, error) {
+	return nil, fmt.Errorf("mock error")
+}
+
+// Reconcile simulates failing to read the certificate directory for testing.
+func (m *MockErrDomainService) Reconcile() (*model.Reconciliation, error) {
+	return nil, fmt.Errorf("mock error")
+}
+
+// EffectiveConfig simulates failing to determine the configuration for testing.
+func (m *MockErrDomainService) EffectiveConfig(_, _ string) (*dehydrated.Config, error) {
+	return nil, fmt.Errorf("mock error")
+}
+
+// RawDomainLine simulates failing to read the domains file for testing.
+func (m *MockErrDomainService) RawDomainLine(_, _ string) (*model.RawDomainLine, error) {
+	return nil, fmt.Errorf("mock error")
+}
+
+// KeyFingerprint simulates failing to read the key for testing.
+func (m *MockErrDomainService) KeyFingerprint(_, _ string) (*dehydrated.KeyInfo, error) {
+	return nil, fmt.Errorf("mock error")
+}
+
+// RefreshOCSP simulates a failing OCSP refresh for testing.
+func (m *MockErrDomainService) RefreshOCSP(_ context.Context, _, _ string, _ bool, _ dehydrated.CronOptions) (*dehydrated.OCSPInfo, error) {
+	return nil, fmt.Errorf("mock error")
+}
+
+// PluginErrors simulates failing to list plugin errors for testing.
+func (m *MockErrDomainService) PluginErrors(_, _ int) ([]*model.PluginError, *model.PaginationInfo, error) {
+	return nil, nil, fmt.Errorf("mock error")
+}
+
+// Plugins simulates failing to list plugins for testing.
+func (m *MockErrDomainService) Plugins(_, _ int) ([]*model.PluginInfo, *model.PaginationInfo, error) {
+	return nil, nil, fmt.Errorf("mock error")
+}
+
 // Close performs cleanup for the mock service.
 func (m *MockErrDomainService) Close() error {
 	return nil
 }
+
+// MockPluginRegistry implements the PluginRegistry interface for testing.
+// It returns the given plugins, the ones with Validates set are returned as validators as well.
+type MockPluginRegistry struct {
+	Clients map[string]*MockPlugin
+	// Priorities are the priorities of the plugins by name, 0 if not set.
+	Priorities map[string]int
+	Failed     []*model.PluginFailure
+
+	mu     sync.Mutex
+	closed bool
+}
+
+// Plugins returns all mock plugins.
+func (m *MockPluginRegistry) Plugins() map[string]pb.PluginClient {
+	p := make(map[string]pb.PluginClient)
+	for name, c := range m.Clients {
+		p[name] = c
+	}
+	return p
+}
+
+// Validators returns the mock plugins with validation enabled.
+func (m *MockPluginRegistry) Validators() map[string]pb.PluginClient {
+	p := make(map[string]pb.PluginClient)
+	for name, c := range m.Clients {
+		if c.Validates {
+			p[name] = c
+		}
+	}
+	return p
+}
+
+// Capabilities returns the capabilities of the named mock plugin.
+func (m *MockPluginRegistry) Capabilities(name string) []string {
+	c, ok := m.Clients[name]
+	if !ok {
+		return nil
+	}
+	return (&pb.InitializeResponse{Capabilities: c.Capabilities}).SupportedCapabilities()
+}
+
+// Order returns the names of the mock plugins by descending priority, then by name.
+func (m *MockPluginRegistry) Order() []string {
+	names := make([]string, 0, len(m.Clients))
+	for name := range m.Clients {
+		names = append(names, name)
+	}
+	config.SortByPriority(names, func(name string) int { return m.Priorities[name] })
+	return names
+}
+
+// Failures returns the given failed plugins.
+func (m *MockPluginRegistry) Failures() []*model.PluginFailure {
+	return m.Failed
+}
+
+// Close marks the registry as closed.
+func (m *MockPluginRegistry) Close() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.closed = true
+}
+
+// IsClosed reports whether Close was called.
+func (m *MockPluginRegistry) IsClosed() bool {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.closed
+}
+
+// MockPlugin implements the PluginClient interface for testing.
+// It returns the scripted metadata, errors and validation results and records the requests it receives.
+type MockPlugin struct {
+	// Metadata is returned by GetMetadata.
+	Metadata map[string]*structpb.Value
+	// Error is returned in the GetMetadata response.
+	Error string
+	// Err is returned by GetMetadata and Validate as the call error.
+	Err error
+	// Validate enables validation for the plugin in the MockPluginRegistry.
+	Validates bool
+	// Reject is the reason Validate rejects domain entries with, if set.
+	Reject string
+	// Capabilities are advertised by the plugin in the MockPluginRegistry. Without, only Validate is supported.
+	Capabilities []string
+	// BatchErr is returned by GetMetadataBatch as the call error instead of Err, if set.
+	BatchErr error
+	// Delay delays GetMetadata and GetMetadataBatch, unless the context is canceled first.
+	Delay time.Duration
+	// OnMetadata is called by GetMetadata before it returns, e.g., to block it while in flight.
+	OnMetadata func()
+
+	MetadataCalls int
+	BatchCalls    int
+	ValidateCalls int
+	// Config is the dehydrated config of the last request.
+	Config *pb.DehydratedConfig
+}
+
+// Initialize does nothing for testing.
+func (m *MockPlugin) Initialize(_ context.Context, _ *pb.InitializeRequest, _ ...grpc.CallOption) (*pb.InitializeResponse, error) {
+	return &pb.InitializeResponse{}, nil
+}
+
+// GetMetadata returns the scripted metadata or errors.
+func (m *MockPlugin) GetMetadata(ctx context.Context, req *pb.GetMetadataRequest, _ ...grpc.CallOption) (*pb.GetMetadataResponse, error) {
+	m.MetadataCalls++
+	m.Config = req.GetDehydratedConfig()
+	if m.OnMetadata != nil {
+		m.OnMetadata()
+	}
+	if err := m.delay(ctx); err != nil {
+		return nil, err
+	}
+	if m.Err != nil {
+		return nil, m.Err
+	}
+	return &pb.GetMetadataResponse{Metadata: m.Metadata, Error: m.Error}, nil
+}
+
+// GetMetadataBatch returns the scripted metadata or errors for each entry with a single call.
+func (m *MockPlugin) GetMetadataBatch(ctx context.Context, req *pb.GetMetadataBatchRequest, _ ...grpc.CallOption) (*pb.GetMetadataBatchResponse, error) {
+	m.BatchCalls++
+	if err := m.delay(ctx); err != nil {
+		return nil, err
+	}
+	if m.BatchErr != nil {
+		return nil, m.BatchErr
+	}
+	if m.Err != nil {
+		return nil, m.Err
+	}
+	resp := &pb.GetMetadataBatchResponse{Responses: make(map[string]*pb.GetMetadataResponse)}
+	for _, r := range req.GetRequests() {
+		m.Config = r.GetDehydratedConfig()
+		resp.Responses[pb.MetadataBatchKey(r.GetDomainEntry())] = &pb.GetMetadataResponse{Metadata: m.Metadata, Error: m.Error}
+	}
+	return resp, nil
+}
+
+// delay waits for Delay or until ctx is canceled.
+func (m *MockPlugin) delay(ctx context.Context) error {
+	if m.Delay == 0 {
+		return nil
+	}
+	select {
+	case <-time.After(m.Delay):
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// Validate accepts all domain entries unless a rejection reason or error is scripted.
+func (m *MockPlugin) Validate(_ context.Context, req *pb.ValidateRequest, _ ...grpc.CallOption) (*pb.ValidateResponse, error) {
+	m.ValidateCalls++
+	m.Config = req.GetDehydratedConfig()
+	if m.Err != nil {
+		return nil, m.Err
+	}
+	return &pb.ValidateResponse{Valid: m.Reject == "", Reason: m.Reject}, nil
+}
+
+// Close does nothing for testing.
+func (m *MockPlugin) Close(_ context.Context, _ *pb.CloseRequest, _ ...grpc.CallOption) (*pb.CloseResponse, error) {
+	return &pb.CloseResponse{}, nil
+}
@@ -0,0 +1,29 @@
+package serviceinterface
+
+import (
+	"github.com/schumann-it/dehydrated-api-go/internal/model"
+	pb "github.com/schumann-it/dehydrated-api-go/plugin/proto"
+)
+
+// PluginRegistry provides the plugins the DomainService uses to enrich and validate domain entries.
+type PluginRegistry interface {
+	// Plugins returns the registered plugins by name.
+	Plugins() map[string]pb.PluginClient
+
+	// Validators returns the registered plugins with validation enabled by name.
+	Validators() map[string]pb.PluginClient
+
+	// Capabilities returns the capabilities of the named plugin, i.e., the optional RPCs it supports,
+	// see pb.CapabilityMetadataBatch and pb.CapabilityValidate.
+	Capabilities(name string) []string
+
+	// Order returns the names of the registered plugins in the order they are called,
+	// by descending priority, then by name.
+	Order() []string
+
+	// Failures returns the enabled plugins that are not available, sorted by name.
+	Failures() []*model.PluginFailure
+
+	// Close closes the connections to all registered plugins.
+	Close()
+}
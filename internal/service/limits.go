@@ -0,0 +1,42 @@
+package service
+
+import (
+	"fmt"
+
+	"go.uber.org/zap"
+
+	serviceinterface "github.com/schumann-it/dehydrated-api-go/internal/service/interface"
+)
+
+const (
+	// DefaultWarnEntries is the number of entries above which a warning is logged by default.
+	DefaultWarnEntries = 10000
+
+	// DefaultMaxEntries is the number of entries beyond which creates are rejected by default.
+	DefaultMaxEntries = 100000
+)
+
+// WithEntryLimits sets the number of entries of the domains file above which a warning is logged (soft limit)
+// and beyond which creates and imports are rejected with ErrTooManyEntries (hard limit).
+// Non-positive values disable the respective limit.
+func (s *DomainService) WithEntryLimits(warn, limit int) *DomainService {
+	s.warnEntries = warn
+	s.maxEntries = limit
+	return s
+}
+
+// checkEntryLimit returns ErrTooManyEntries if count entries exceed the hard limit.
+func (s *DomainService) checkEntryLimit(count int) error {
+	if s.maxEntries > 0 && count > s.maxEntries {
+		return fmt.Errorf("%w: %d entries exceed the limit of %d", serviceinterface.ErrTooManyEntries, count, s.maxEntries)
+	}
+	return nil
+}
+
+// warnEntryCount logs a warning if count entries exceed the soft limit.
+func (s *DomainService) warnEntryCount(count int) {
+	if s.warnEntries > 0 && count > s.warnEntries {
+		s.logger.Warn("Number of domain entries exceeds the warning threshold",
+			zap.Int("count", count), zap.Int("threshold", s.warnEntries), zap.Int("limit", s.maxEntries))
+	}
+}
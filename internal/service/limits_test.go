@@ -0,0 +1,123 @@
+package service
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+
+	"github.com/schumann-it/dehydrated-api-go/internal/dehydrated"
+	"github.com/schumann-it/dehydrated-api-go/internal/model"
+	serviceinterface "github.com/schumann-it/dehydrated-api-go/internal/service/interface"
+	"github.com/schumann-it/dehydrated-api-go/internal/util"
+)
+
+func TestEntryLimits(t *testing.T) {
+	const warn, limit = 2, 3
+
+	newService := func(t *testing.T, lines ...string) (*DomainService, *observer.ObservedLogs) {
+		dc := dehydrated.NewConfig().WithBaseDir(t.TempDir()).Load()
+		require.NoError(t, os.WriteFile(dc.DomainsFile, []byte(strings.Join(lines, "\n")), 0644))
+
+		core, logs := observer.New(zapcore.WarnLevel)
+		s := NewDomainService(dc, nil).WithLogger(zap.New(core)).WithEntryLimits(warn, limit)
+		t.Cleanup(func() { _ = s.Close() })
+		require.NoError(t, s.Reload())
+		return s, logs
+	}
+	warnings := func(logs *observer.ObservedLogs) int {
+		return logs.FilterMessage("Number of domain entries exceeds the warning threshold").Len()
+	}
+	create := func(s *DomainService, i int) error {
+		_, err := s.CreateDomain(&model.CreateDomainRequest{Domain: fmt.Sprintf("%d.example.com", i), Enabled: util.BoolPtr(true)})
+		return err
+	}
+
+	t.Run("Create", func(t *testing.T) {
+		s, logs := newService(t)
+
+		// Up to the warning threshold
+		for i := range warn {
+			require.NoError(t, create(s, i))
+		}
+		require.Zero(t, warnings(logs))
+
+		// Above the warning threshold, up to the limit
+		require.NoError(t, create(s, warn))
+		require.Equal(t, 1, warnings(logs))
+
+		// Beyond the limit
+		err := create(s, limit)
+		require.ErrorIs(t, err, serviceinterface.ErrTooManyEntries)
+		_, err = s.GetDomain(fmt.Sprintf("%d.example.com", limit), "")
+		require.ErrorIs(t, err, serviceinterface.ErrDomainNotFound)
+
+		entries, err := ReadDomainsFile(s.DehydratedConfig.DomainsFile)
+		require.NoError(t, err)
+		require.Len(t, entries, limit)
+
+		// Deleting an entry makes room for another one
+		require.NoError(t, s.DeleteDomain("0.example.com", model.DeleteDomainRequest{}))
+		require.NoError(t, create(s, limit))
+	})
+
+	t.Run("Upsert", func(t *testing.T) {
+		s, _ := newService(t, "a.example.com", "b.example.com", "c.example.com")
+
+		// Updates are allowed at the limit, creates are not
+		_, created, err := s.UpsertDomain("a.example.com", model.UpdateDomainRequest{Comment: util.StringPtr("updated")})
+		require.NoError(t, err)
+		require.False(t, created)
+
+		_, _, err = s.UpsertDomain("d.example.com", model.UpdateDomainRequest{})
+		require.ErrorIs(t, err, serviceinterface.ErrTooManyEntries)
+	})
+
+	t.Run("Import", func(t *testing.T) {
+		s, _ := newService(t, "a.example.com", "b.example.com")
+
+		reqs := func(domains ...string) []*model.CreateDomainRequest {
+			result := make([]*model.CreateDomainRequest, len(domains))
+			for i, domain := range domains {
+				result[i] = &model.CreateDomainRequest{Domain: domain, Enabled: util.BoolPtr(true)}
+			}
+			return result
+		}
+
+		_, err := s.ImportDomains(reqs("c.example.com", "d.example.com"), false)
+		require.ErrorIs(t, err, serviceinterface.ErrTooManyEntries)
+
+		_, err = s.ImportDomains(reqs("c.example.com"), false)
+		require.NoError(t, err)
+
+		// Imports that do not grow the domains file are allowed
+		_, err = s.ImportDomains(reqs("x.example.com"), true)
+		require.NoError(t, err)
+	})
+
+	t.Run("Reload", func(t *testing.T) {
+		// Files beyond the limit are loaded with a warning, but no entries can be created
+		s, logs := newService(t, "a.example.com", "b.example.com", "c.example.com", "d.example.com")
+		require.Equal(t, 1, warnings(logs))
+
+		entries, _, err := s.ListDomains(1, 10, "", "")
+		require.NoError(t, err)
+		require.Len(t, entries, 4)
+		require.ErrorIs(t, create(s, 0), serviceinterface.ErrTooManyEntries)
+	})
+
+	t.Run("Disabled", func(t *testing.T) {
+		s, logs := newService(t)
+		s.WithEntryLimits(0, 0)
+
+		for i := range limit + 1 {
+			require.NoError(t, create(s, i))
+		}
+		require.Zero(t, warnings(logs))
+	})
+}
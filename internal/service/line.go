@@ -0,0 +1,203 @@
+package service
+
+import (
+	"bufio"
+	"io"
+	"strings"
+	"unicode"
+
+	"github.com/schumann-it/dehydrated-api-go/internal/model"
+	pb "github.com/schumann-it/dehydrated-api-go/plugin/proto"
+)
+
+// DomainLine is a line of the domains file, parsed into its components.
+// It is used by the reader and writer of the domains file and keeps the parts of a line
+// that are not in the API representation of a DomainEntry, like options and leading comment lines.
+type DomainLine struct {
+	// Disabled is set for entries commented out with a leading '#'.
+	Disabled bool
+
+	// Primary is the first domain name of the line.
+	Primary string
+
+	// SANs are the alternative names following the primary domain.
+	SANs []string
+
+	// Alias is the certificate alias following '>'.
+	Alias string
+
+	// Options is the raw text following the alias up to the comment.
+	Options string
+
+	// Comment is the inline comment following '#'.
+	Comment string
+
+	// LeadingComments are the comment lines preceding the entry, as read.
+	LeadingComments []string
+
+	// Number is the 1-based line number in the domains file, if read from it.
+	Number int
+
+	// Raw is the line as read from the domains file, without the line break.
+	Raw string
+}
+
+// ParseDomainLine parses a line of the domains file.
+// It returns false if the line is empty, a comment or does not contain a valid domain.
+// Following dehydrated, a line commented out with '#' is a disabled entry. To tell it apart from
+// a free-form comment, all of its names must be valid domains, e.g., "# example.com www.example.com"
+// is a disabled entry, but "# example.com is the main site" is a comment.
+func ParseDomainLine(s string) (*DomainLine, bool) {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return nil, false
+	}
+
+	l := &DomainLine{}
+
+	// Disabled entries are commented out
+	if strings.HasPrefix(s, "#") {
+		s = strings.TrimSpace(strings.TrimPrefix(s, "#"))
+		l.Disabled = true
+	}
+
+	// Extract inline comment if present
+	if before, after, found := strings.Cut(s, "#"); found {
+		s = strings.TrimSpace(before)
+		l.Comment = strings.TrimSpace(after)
+	}
+
+	// Split by '>' to handle aliases, the text following the alias is kept as options
+	s, rest, _ := strings.Cut(s, ">")
+	rest = strings.TrimSpace(rest)
+	if i := strings.IndexFunc(rest, unicode.IsSpace); i >= 0 {
+		l.Alias = rest[:i]
+		l.Options = strings.TrimSpace(rest[i:])
+	} else {
+		l.Alias = rest
+	}
+
+	// Split the main part into domain and alternative names
+	fields := strings.Fields(s)
+	if len(fields) == 0 || !model.IsValidDomain(fields[0]) {
+		return nil, false
+	}
+	if l.Disabled {
+		for _, name := range fields[1:] {
+			if !model.IsValidDomain(name) {
+				return nil, false
+			}
+		}
+	}
+	l.Primary = fields[0]
+	l.SANs = fields[1:]
+
+	return l, true
+}
+
+// NewDomainLine creates the line of a domain entry.
+func NewDomainLine(entry *model.DomainEntry) *DomainLine {
+	return &DomainLine{
+		Disabled: !entry.Enabled,
+		Primary:  entry.Domain,
+		SANs:     entry.AlternativeNames,
+		Alias:    entry.Alias,
+		Options:  entry.Options,
+		Comment:  entry.Comment,
+	}
+}
+
+// Entry converts the line to a domain entry. Leading comments are not part of the entry.
+func (l *DomainLine) Entry() *model.DomainEntry {
+	return &model.DomainEntry{
+		DomainEntry: pb.DomainEntry{
+			Domain:           l.Primary,
+			AlternativeNames: l.SANs,
+			Alias:            l.Alias,
+			Enabled:          !l.Disabled,
+			Comment:          l.Comment,
+		},
+		Options: l.Options,
+	}
+}
+
+// String formats the line in the domains file format, preceded by its leading comment lines.
+func (l *DomainLine) String() string {
+	var line strings.Builder
+
+	for _, comment := range l.LeadingComments {
+		line.WriteString(comment)
+		line.WriteString("\n")
+	}
+
+	// Add comment marker if disabled
+	if l.Disabled {
+		line.WriteString("# ")
+	}
+
+	// Add domain and alternative names
+	line.WriteString(l.Primary)
+	for _, san := range l.SANs {
+		line.WriteString(" ")
+		line.WriteString(san)
+	}
+
+	// Add alias and options if present
+	if l.Alias != "" {
+		line.WriteString(" > ")
+		line.WriteString(l.Alias)
+		if l.Options != "" {
+			line.WriteString(" ")
+			line.WriteString(l.Options)
+		}
+	}
+
+	// Add comment if present
+	if l.Comment != "" {
+		line.WriteString(" # ")
+		line.WriteString(l.Comment)
+	}
+
+	return line.String()
+}
+
+// utf8BOM is the UTF-8 byte order mark, which is skipped when reading the domains file.
+const utf8BOM = "\uFEFF"
+
+// readDomainLines reads the lines of a domains file. Comment lines are attached to the
+// following entry as its leading comments; empty lines, invalid entries and comment lines
+// after the last entry are dropped.
+func readDomainLines(r io.Reader) ([]*DomainLine, error) {
+	var lines []*DomainLine
+	var comments []string
+
+	scanner := bufio.NewScanner(r)
+	for number := 1; scanner.Scan(); number++ {
+		raw := strings.TrimSuffix(scanner.Text(), "\r")
+		if number == 1 {
+			// Editors on Windows may prefix the file with a UTF-8 byte order mark
+			raw = strings.TrimPrefix(raw, utf8BOM)
+		}
+		text := strings.TrimSpace(raw)
+
+		l, ok := ParseDomainLine(text)
+		if !ok {
+			if strings.HasPrefix(text, "#") {
+				comments = append(comments, text)
+			}
+			continue
+		}
+
+		l.Number = number
+		l.Raw = raw
+		l.LeadingComments = comments
+		comments = nil
+		lines = append(lines, l)
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	return lines, nil
+}
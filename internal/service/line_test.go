@@ -0,0 +1,180 @@
+package service
+
+import (
+	"encoding/json"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/schumann-it/dehydrated-api-go/internal/dehydrated"
+	"github.com/schumann-it/dehydrated-api-go/internal/model"
+	serviceinterface "github.com/schumann-it/dehydrated-api-go/internal/service/interface"
+	"github.com/schumann-it/dehydrated-api-go/internal/util"
+)
+
+func TestParseDomainLine(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		expected *DomainLine
+	}{
+		{"Domain", "example.com", &DomainLine{Primary: "example.com", SANs: []string{}}},
+		{
+			"Full", "  example.com www.example.com > cert opt=1  # Production ",
+			&DomainLine{Primary: "example.com", SANs: []string{"www.example.com"}, Alias: "cert", Options: "opt=1", Comment: "Production"},
+		},
+		{"Disabled", "# example.com > cert", &DomainLine{Disabled: true, Primary: "example.com", SANs: []string{}, Alias: "cert"}},
+		{"Empty", "   ", nil},
+		{"Comment", "# Production domains", nil},
+		{"DoubleComment", "## example.com", nil},
+		{"CommentStartingWithDomain", "# example.com is the main site", nil},
+		{"DisabledWildcard", "# example.com *.example.com", &DomainLine{Disabled: true, Primary: "example.com", SANs: []string{"*.example.com"}}},
+		{"InvalidDomain", "not_a_domain", nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			l, ok := ParseDomainLine(tt.input)
+			require.Equal(t, tt.expected != nil, ok)
+			require.Equal(t, tt.expected, l)
+		})
+	}
+}
+
+// TestDomainLineRoundTrip verifies parse→line→entry→line→serialize for normalized lines.
+func TestDomainLineRoundTrip(t *testing.T) {
+	for _, input := range []string{
+		"example.com",
+		"example.com www.example.com api.example.com",
+		"example.com > cert",
+		"# example.com www.example.com > cert # Disabled",
+		"example.com # Production",
+	} {
+		t.Run(input, func(t *testing.T) {
+			l, ok := ParseDomainLine(input)
+			require.True(t, ok)
+			require.Equal(t, input, l.String())
+
+			entry := l.Entry()
+			require.Equal(t, l.Primary, entry.Domain)
+			require.Equal(t, !l.Disabled, entry.Enabled)
+			require.Equal(t, input, NewDomainLine(entry).String())
+		})
+	}
+
+	t.Run("Options", func(t *testing.T) {
+		input := "example.com > cert key=value # Comment"
+		l, ok := ParseDomainLine(input)
+		require.True(t, ok)
+		require.Equal(t, input, l.String())
+
+		// Options are carried by the entry, so they are written back
+		entry := l.Entry()
+		require.Equal(t, "key=value", entry.Options)
+		require.Equal(t, input, NewDomainLine(entry).String())
+	})
+}
+
+// TestDomainLineOptionsKept verifies that a mutation of one entry writes the options of the others back unchanged.
+func TestDomainLineOptionsKept(t *testing.T) {
+	dc := dehydrated.NewConfig().WithBaseDir(t.TempDir()).Load()
+	content := strings.Join([]string{
+		"example.com > example-com",
+		"example.net www.example.net > example-rsa KEY_ALGO=rsa extra # legacy",
+		"example.org > example-org KEY_ALGO=secp384r1",
+		"",
+	}, "\n")
+	require.NoError(t, os.WriteFile(dc.DomainsFile, []byte(content), 0644))
+
+	s := NewDomainService(dc, nil)
+	defer s.Close()
+	require.NoError(t, s.Reload())
+
+	// Mutating another entry keeps the options
+	_, err := s.UpdateDomain("example.com", model.UpdateDomainRequest{Comment: util.StringPtr("main"), Alias: util.StringPtr("example-com")})
+	require.NoError(t, err)
+	data, err := os.ReadFile(dc.DomainsFile)
+	require.NoError(t, err)
+	require.Equal(t, strings.Join([]string{
+		"example.com > example-com # main",
+		"example.net www.example.net > example-rsa KEY_ALGO=rsa extra # legacy",
+		"example.org > example-org KEY_ALGO=secp384r1",
+		"",
+	}, "\n"), string(data))
+
+	// Mutating the entry itself keeps them as well
+	_, err = s.UpdateDomain("example.org", model.UpdateDomainRequest{AlternativeNames: &[]string{"www.example.org"}, Alias: util.StringPtr("example-org")})
+	require.NoError(t, err)
+	data, err = os.ReadFile(dc.DomainsFile)
+	require.NoError(t, err)
+	require.Contains(t, string(data), "example.org www.example.org > example-org KEY_ALGO=secp384r1\n")
+
+	// Options are not part of the API
+	entry, err := s.GetDomain("example.net", "example-rsa")
+	require.NoError(t, err)
+	out, err := json.Marshal(entry)
+	require.NoError(t, err)
+	require.NotContains(t, string(out), "KEY_ALGO")
+}
+
+func TestReadDomainLines(t *testing.T) {
+	input := strings.Join([]string{
+		"# Production",
+		"#   managed by ops",
+		"",
+		"example.com www.example.com",
+		"# disabled.com",
+		"invalid_line",
+		"# Trailing",
+	}, "\n")
+
+	lines, err := readDomainLines(strings.NewReader(input))
+	require.NoError(t, err)
+	require.Len(t, lines, 2)
+
+	require.Equal(t, []string{"# Production", "#   managed by ops"}, lines[0].LeadingComments)
+	require.Equal(t, "# Production\n#   managed by ops\nexample.com www.example.com", lines[0].String())
+
+	require.True(t, lines[1].Disabled)
+	require.Empty(t, lines[1].LeadingComments)
+	require.Equal(t, "# disabled.com", lines[1].String())
+}
+
+func TestRawDomainLine(t *testing.T) {
+	dc := dehydrated.NewConfig().WithBaseDir(t.TempDir()).Load()
+	content := strings.Join([]string{
+		"# managed by hand",
+		"example.com",
+		"",
+		"  example.org www.example.org > example-rsa KEY_ALGO=rsa extra  # legacy \r",
+		"# example.net > example-net",
+	}, "\n")
+	require.NoError(t, os.WriteFile(dc.DomainsFile, []byte(content), 0644))
+
+	s := NewDomainService(dc, nil)
+	defer s.Close()
+	require.NoError(t, s.Reload())
+
+	line, err := s.RawDomainLine("example.org", "example-rsa")
+	require.NoError(t, err)
+	require.Equal(t, &model.RawDomainLine{
+		Line:    4,
+		Raw:     "  example.org www.example.org > example-rsa KEY_ALGO=rsa extra  # legacy ",
+		Primary: "example.org",
+		SANs:    []string{"www.example.org"},
+		Alias:   "example-rsa",
+		Options: "KEY_ALGO=rsa extra",
+		Comment: "legacy",
+	}, line)
+
+	line, err = s.RawDomainLine("example.net", "example-net")
+	require.NoError(t, err)
+	require.True(t, line.Disabled)
+	require.Equal(t, 5, line.Line)
+	require.Empty(t, line.SANs)
+
+	// The alias has to match
+	_, err = s.RawDomainLine("example.org", "")
+	require.ErrorIs(t, err, serviceinterface.ErrDomainNotFound)
+}
@@ -0,0 +1,182 @@
+package service
+
+import (
+	"bytes"
+	"io"
+	"os"
+	"strings"
+
+	"go.uber.org/zap"
+
+	"github.com/schumann-it/dehydrated-api-go/internal/model"
+)
+
+// NormalizeDomains rewrites the domains file in canonical form. The file is read again, so changes
+// made by an editor are included. Domain names and alternative names are lowercased, duplicate
+// alternative names and alternative names repeating the domain are removed, of duplicate entries
+// only the first one is kept, and the entries are sorted and formatted as by every write.
+// Comment lines, invalid lines and options are not part of the entries and are removed, too.
+// With dryRun, the file and the cache are left unchanged.
+func (s *DomainService) NormalizeDomains(dryRun bool) (*model.NormalizeResult, error) {
+	s.logger.Info("Normalizing domains file", zap.Bool("dryRun", dryRun))
+
+	if s.watcher != nil {
+		s.watcher.Disable()
+	}
+	defer func() {
+		if s.watcher != nil {
+			s.watcher.Enable()
+		}
+	}()
+
+	s.mutex.Lock()
+
+	// Changes not yet written are written first, the normalization works on the file
+	if s.batcher != nil {
+		if err := s.batcher.flush(); err != nil {
+			s.mutex.Unlock()
+			return nil, err
+		}
+	}
+
+	content, err := readDomainsFileContent(s.DehydratedConfig.DomainsFile)
+	if err != nil {
+		s.mutex.Unlock()
+		s.logger.Error("Failed to read domains file", zap.Error(err))
+		return nil, err
+	}
+
+	entries, result, err := normalizeDomainsContent(content)
+	if err != nil {
+		s.mutex.Unlock()
+		return nil, err
+	}
+	result.DryRun = dryRun
+
+	var done <-chan error
+	if !dryRun && result.Changed {
+		if done, err = s.persist(entries); err != nil {
+			s.mutex.Unlock()
+			s.logger.Error("Failed to write domains file", zap.Error(err))
+			return nil, err
+		}
+
+		// Update cache only after successful write
+		s.cache = entries
+	}
+
+	s.mutex.Unlock()
+
+	s.logger.Info("Normalized domains file",
+		zap.Bool("dryRun", dryRun),
+		zap.Bool("changed", result.Changed),
+		zap.Int("changes", len(result.Changes)))
+
+	if err := s.awaitWrite(done); err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+// readDomainsFileContent returns the decompressed content of the domains file.
+// A missing file has no content.
+func readDomainsFileContent(filename string) ([]byte, error) {
+	file, err := os.Open(filename)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	defer file.Close()
+
+	r, err := decompress(file)
+	if err != nil {
+		return nil, err
+	}
+
+	return io.ReadAll(r)
+}
+
+// normalizeDomainsContent normalizes the entries of the domains file content, see NormalizeDomains.
+// It returns the sorted entries and the lines changed by the normalization.
+func normalizeDomainsContent(content []byte) (model.DomainEntries, *model.NormalizeResult, error) {
+	lines, err := readDomainLines(bytes.NewReader(content))
+	if err != nil {
+		return nil, nil, err
+	}
+
+	entries := make(model.DomainEntries, 0, len(lines))
+	after := make(map[int]string, len(lines))
+	seen := make(map[string]bool, len(lines))
+	for _, l := range lines {
+		entry := normalizeEntry(l.Entry())
+
+		// Of duplicate entries, only the first one is reachable through the API, see Reload
+		key := entry.Domain + ">" + entry.Alias
+		if seen[key] {
+			after[l.Number] = ""
+			continue
+		}
+		seen[key] = true
+
+		entries = append(entries, entry)
+		after[l.Number] = formatLine(entry)
+	}
+
+	result := &model.NormalizeResult{
+		Entries: len(entries),
+		Changes: []model.NormalizeChange{},
+	}
+
+	for i, raw := range strings.Split(string(content), "\n") {
+		number := i + 1
+		raw = strings.TrimSuffix(raw, "\r")
+		if number == 1 {
+			raw = strings.TrimPrefix(raw, utf8BOM)
+		}
+
+		line, isEntry := after[number]
+		if !isEntry && strings.TrimSpace(raw) == "" {
+			continue
+		}
+		if isEntry && line == raw {
+			continue
+		}
+		result.Changes = append(result.Changes, model.NormalizeChange{Line: number, Before: raw, After: line})
+	}
+
+	sorted := append(make(model.DomainEntries, 0, len(entries)), entries...)
+	sorted.Sort()
+	for i := range sorted {
+		if sorted[i] != entries[i] {
+			result.Reordered = true
+			break
+		}
+	}
+
+	result.Changed = len(result.Changes) > 0 || result.Reordered
+
+	return sorted, result, nil
+}
+
+// normalizeEntry lowercases the names of entry and removes duplicate alternative names
+// and alternative names repeating the domain. The alias is kept as is.
+func normalizeEntry(entry *model.DomainEntry) *model.DomainEntry {
+	entry.Domain = strings.ToLower(entry.Domain)
+
+	names := make([]string, 0, len(entry.AlternativeNames))
+	seen := map[string]bool{entry.Domain: true}
+	for _, name := range entry.AlternativeNames {
+		name = strings.ToLower(name)
+		if seen[name] {
+			continue
+		}
+		seen[name] = true
+		names = append(names, name)
+	}
+	entry.AlternativeNames = names
+
+	return entry
+}
@@ -0,0 +1,107 @@
+package service
+
+import (
+	"os"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/schumann-it/dehydrated-api-go/internal/dehydrated"
+	"github.com/schumann-it/dehydrated-api-go/internal/model"
+	"github.com/schumann-it/dehydrated-api-go/internal/util"
+)
+
+// messyDomainsFile is a domains file with entries that are not in canonical form.
+const messyDomainsFile = `# Production
+Example.org www.example.org WWW.Example.org example.org
+example.com   www.example.com > web
+not a domain
+
+#  staging.example.com
+example.com www.example.com > web
+example.net # shop
+`
+
+func TestNormalizeDomains(t *testing.T) {
+	dc := dehydrated.NewConfig().WithBaseDir(t.TempDir()).Load()
+	require.NoError(t, os.WriteFile(dc.DomainsFile, []byte(messyDomainsFile), 0o644))
+
+	s := NewDomainService(dc, nil)
+	defer s.Close()
+	require.NoError(t, s.Reload())
+
+	expectedChanges := []model.NormalizeChange{
+		{Line: 1, Before: "# Production"},
+		{Line: 2, Before: "Example.org www.example.org WWW.Example.org example.org", After: "example.org www.example.org"},
+		{Line: 3, Before: "example.com   www.example.com > web", After: "example.com www.example.com > web"},
+		{Line: 4, Before: "not a domain"},
+		{Line: 6, Before: "#  staging.example.com", After: "# staging.example.com"},
+		{Line: 7, Before: "example.com www.example.com > web"},
+	}
+
+	t.Run("DryRun", func(t *testing.T) {
+		result, err := s.NormalizeDomains(true)
+		require.NoError(t, err)
+		require.True(t, result.DryRun)
+		require.True(t, result.Changed)
+		require.True(t, result.Reordered)
+		require.Equal(t, 4, result.Entries)
+		require.Equal(t, expectedChanges, result.Changes)
+
+		data, err := os.ReadFile(dc.DomainsFile)
+		require.NoError(t, err)
+		require.Equal(t, messyDomainsFile, string(data))
+
+		entries, _, err := s.ListDomains(1, 10, "", "")
+		require.NoError(t, err)
+		require.Equal(t, "Example.org", entries[0].Domain)
+	})
+
+	t.Run("Normalize", func(t *testing.T) {
+		result, err := s.NormalizeDomains(false)
+		require.NoError(t, err)
+		require.False(t, result.DryRun)
+		require.True(t, result.Changed)
+		require.Equal(t, expectedChanges, result.Changes)
+
+		data, err := os.ReadFile(dc.DomainsFile)
+		require.NoError(t, err)
+		require.Equal(t, "example.com www.example.com > web\n"+
+			"example.net # shop\n"+
+			"example.org www.example.org\n"+
+			"# staging.example.com\n", string(data))
+
+		entries, _, err := s.ListDomains(1, 10, "", "")
+		require.NoError(t, err)
+		require.Len(t, entries, 4)
+		require.Equal(t, "example.com", entries[0].Domain)
+		require.Equal(t, []string{"www.example.org"}, entries[2].AlternativeNames)
+	})
+
+	t.Run("Unchanged", func(t *testing.T) {
+		result, err := s.NormalizeDomains(false)
+		require.NoError(t, err)
+		require.False(t, result.Changed)
+		require.False(t, result.Reordered)
+		require.Empty(t, result.Changes)
+	})
+
+	t.Run("PendingWrites", func(t *testing.T) {
+		batched := NewDomainService(dc, nil).WithWriteCoalescing(time.Hour, 100, false)
+		require.NoError(t, batched.Reload())
+
+		_, err := batched.CreateDomain(&model.CreateDomainRequest{Domain: "Example.edu", Enabled: util.BoolPtr(true)})
+		require.NoError(t, err)
+
+		result, err := batched.NormalizeDomains(false)
+		require.NoError(t, err)
+		require.Equal(t, []model.NormalizeChange{{Line: 1, Before: "Example.edu", After: "example.edu"}}, result.Changes)
+
+		// The normalized entries are written with the next flush
+		require.NoError(t, batched.Close())
+		data, err := os.ReadFile(dc.DomainsFile)
+		require.NoError(t, err)
+		require.Contains(t, string(data), "example.com www.example.com > web\nexample.edu\n")
+	})
+}
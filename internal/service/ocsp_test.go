@@ -0,0 +1,107 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/schumann-it/dehydrated-api-go/internal/dehydrated"
+	"github.com/schumann-it/dehydrated-api-go/internal/model"
+	serviceinterface "github.com/schumann-it/dehydrated-api-go/internal/service/interface"
+	"github.com/schumann-it/dehydrated-api-go/internal/testutil"
+	"github.com/schumann-it/dehydrated-api-go/internal/util"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/ocsp"
+)
+
+func TestRefreshOCSP(t *testing.T) {
+	dc := dehydrated.NewConfig().WithBaseDir(t.TempDir()).Load()
+	s := NewDomainService(dc, nil)
+	defer s.Close()
+
+	_, err := s.CreateDomain(&model.CreateDomainRequest{
+		Domain:           "example.com",
+		AlternativeNames: []string{"www.example.com"},
+		Alias:            "example-rsa",
+		Enabled:          util.BoolPtr(true),
+	})
+	require.NoError(t, err)
+	_, err = s.CreateDomain(&model.CreateDomainRequest{Domain: "nofetch.example.com", Enabled: util.BoolPtr(true)})
+	require.NoError(t, err)
+	_, err = s.CreateDomain(&model.CreateDomainRequest{Domain: "disabled.example.com", Alias: "example-rsa", Enabled: util.BoolPtr(false)})
+	require.NoError(t, err)
+
+	// The stub dehydrated installs a prepared OCSP response for the alias, like dehydrated with OCSP_FETCH=yes
+	fixture := filepath.Join(t.TempDir(), "ocsp.der")
+	nextUpdate := time.Now().Add(3 * day).UTC().Truncate(time.Second)
+	require.NoError(t, os.WriteFile(fixture, testutil.OCSPResponse(t, ocsp.Good, nextUpdate), 0o600))
+
+	script := filepath.Join(t.TempDir(), "dehydrated")
+	stub := fmt.Sprintf("#!/bin/sh\necho \"$@\" > args\ncase \"$*\" in *--alias\\ example-rsa*)\n  mkdir -p %[1]s/example-rsa && cp %[2]s %[1]s/example-rsa/ocsp.der;;\nesac\n",
+		dc.CertDir, fixture)
+	//nolint:gosec // the stub has to be executable
+	require.NoError(t, os.WriteFile(script, []byte(stub), 0o755))
+	s.WithDehydratedScript(script)
+
+	t.Run("Refresh", func(t *testing.T) {
+		info, err := s.RefreshOCSP(context.Background(), "example.com", "example-rsa", false, dehydrated.CronOptions{})
+		require.NoError(t, err)
+		require.Equal(t, "good", info.Status)
+		require.True(t, nextUpdate.Equal(info.NextUpdate))
+
+		args, err := os.ReadFile(filepath.Join(dc.BaseDir, "args"))
+		require.NoError(t, err)
+		require.Contains(t, string(args), "--cron --config "+dc.ConfigFile+" --domain example.com --domain www.example.com --alias example-rsa")
+		require.NotContains(t, string(args), "--force")
+	})
+
+	t.Run("Force", func(t *testing.T) {
+		_, err := s.RefreshOCSP(context.Background(), "example.com", "example-rsa", false,
+			dehydrated.CronOptions{ForceRenew: true, ForceValidation: true})
+		require.NoError(t, err)
+
+		args, err := os.ReadFile(filepath.Join(dc.BaseDir, "args"))
+		require.NoError(t, err)
+		require.Contains(t, string(args), "--alias example-rsa --force --force-validation")
+
+		// The options are validated before dehydrated runs
+		require.NoError(t, os.Remove(filepath.Join(dc.BaseDir, "args")))
+		_, err = s.RefreshOCSP(context.Background(), "example.com", "example-rsa", false, dehydrated.CronOptions{ForceValidation: true})
+		require.ErrorIs(t, err, dehydrated.ErrForceValidationWithoutForce)
+		require.NoFileExists(t, filepath.Join(dc.BaseDir, "args"))
+	})
+
+	t.Run("NoOCSPResponse", func(t *testing.T) {
+		_, err := s.RefreshOCSP(context.Background(), "nofetch.example.com", "", false, dehydrated.CronOptions{})
+		require.ErrorIs(t, err, dehydrated.ErrOCSPNotFound)
+	})
+
+	t.Run("DomainNotFound", func(t *testing.T) {
+		_, err := s.RefreshOCSP(context.Background(), "missing.example.com", "", false, dehydrated.CronOptions{})
+		require.ErrorIs(t, err, serviceinterface.ErrDomainNotFound)
+	})
+
+	t.Run("DomainDisabled", func(t *testing.T) {
+		require.NoError(t, os.Remove(filepath.Join(dc.BaseDir, "args")))
+
+		_, err := s.RefreshOCSP(context.Background(), "disabled.example.com", "example-rsa", false, dehydrated.CronOptions{})
+		require.ErrorIs(t, err, serviceinterface.ErrDomainDisabled)
+		require.NoFileExists(t, filepath.Join(dc.BaseDir, "args"), "dehydrated must not run for a disabled entry")
+
+		info, err := s.RefreshOCSP(context.Background(), "disabled.example.com", "example-rsa", true, dehydrated.CronOptions{})
+		require.NoError(t, err)
+		require.Equal(t, "good", info.Status)
+		require.FileExists(t, filepath.Join(dc.BaseDir, "args"))
+	})
+
+	t.Run("DehydratedFails", func(t *testing.T) {
+		s.WithDehydratedScript(filepath.Join(t.TempDir(), "missing"))
+		defer s.WithDehydratedScript(script)
+
+		_, err := s.RefreshOCSP(context.Background(), "example.com", "example-rsa", false, dehydrated.CronOptions{})
+		require.ErrorContains(t, err, "dehydrated failed")
+	})
+}
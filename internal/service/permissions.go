@@ -0,0 +1,147 @@
+package service
+
+import (
+	"fmt"
+	"os"
+	"os/user"
+	"path/filepath"
+	"strconv"
+
+	"go.uber.org/zap"
+)
+
+// FilePermissions configures the mode and ownership applied to the domains file and its directory,
+// e.g., to make them readable for the group dehydrated runs as.
+type FilePermissions struct {
+	// FileMode is the mode of the domains file, unchanged if zero.
+	FileMode os.FileMode
+
+	// DirMode is the mode of the directory of the domains file, unchanged if zero.
+	DirMode os.FileMode
+
+	// User is the name or numeric id of the owner, unchanged if empty.
+	User string
+
+	// Group is the name or numeric id of the group, unchanged if empty.
+	Group string
+}
+
+// ids resolves the user and group to numeric ids, -1 if not set.
+func (p FilePermissions) ids() (uid, gid int, err error) {
+	uid, err = lookupID(p.User, func(name string) (string, error) {
+		u, err := user.Lookup(name)
+		if err != nil {
+			return "", err
+		}
+		return u.Uid, nil
+	})
+	if err != nil {
+		return -1, -1, err
+	}
+
+	gid, err = lookupID(p.Group, func(name string) (string, error) {
+		g, err := user.LookupGroup(name)
+		if err != nil {
+			return "", err
+		}
+		return g.Gid, nil
+	})
+	if err != nil {
+		return -1, -1, err
+	}
+
+	return uid, gid, nil
+}
+
+// lookupID returns the numeric id of nameOrID, looking up names with lookup, or -1 if it is empty.
+func lookupID(nameOrID string, lookup func(string) (string, error)) (int, error) {
+	if nameOrID == "" {
+		return -1, nil
+	}
+	if id, err := strconv.Atoi(nameOrID); err == nil {
+		return id, nil
+	}
+
+	id, err := lookup(nameOrID)
+	if err != nil {
+		return -1, err
+	}
+	n, err := strconv.Atoi(id)
+	if err != nil {
+		return -1, fmt.Errorf("unsupported id %s of %s", id, nameOrID)
+	}
+	return n, nil
+}
+
+// WithFilePermissions applies the given mode and ownership to the domains file and its directory, now and on
+// every write. Writes apply them to the file before its content is replaced, so the new content is never
+// accessible with other permissions. Failures, e.g., missing privileges to change the owner, are logged
+// and do not fail the write.
+func (s *DomainService) WithFilePermissions(p FilePermissions) *DomainService {
+	s.permissions = &p
+	s.applyDirPermissions()
+
+	file, err := os.Open(s.DehydratedConfig.DomainsFile)
+	if err != nil {
+		s.logger.Warn("Failed to open domains file to change its permissions", zap.Error(err))
+		return s
+	}
+	defer file.Close()
+	s.applyFilePermissions(file)
+	return s
+}
+
+// applyFilePermissions applies the configured mode and ownership, if any, to file.
+func (s *DomainService) applyFilePermissions(file *os.File) {
+	p := s.permissions
+	if p == nil {
+		return
+	}
+
+	if p.FileMode != 0 {
+		if err := file.Chmod(p.FileMode); err != nil {
+			s.logger.Warn("Failed to change mode of domains file", zap.String("file", file.Name()), zap.Error(err))
+		}
+	}
+
+	if p.User == "" && p.Group == "" {
+		return
+	}
+
+	uid, gid, err := p.ids()
+	if err != nil {
+		s.logger.Warn("Failed to resolve owner of domains file", zap.String("user", p.User), zap.String("group", p.Group), zap.Error(err))
+		return
+	}
+	if err := file.Chown(uid, gid); err != nil {
+		s.logger.Warn("Failed to change owner of domains file", zap.String("file", file.Name()), zap.Error(err))
+	}
+}
+
+// applyDirPermissions applies the configured directory mode and ownership, if any, to the directory of the domains file.
+func (s *DomainService) applyDirPermissions() {
+	p := s.permissions
+	if p == nil {
+		return
+	}
+
+	dir := filepath.Dir(s.DehydratedConfig.DomainsFile)
+	if p.DirMode != 0 {
+		if err := os.Chmod(dir, p.DirMode); err != nil {
+			s.logger.Warn("Failed to change mode of domains directory", zap.String("dir", dir), zap.Error(err))
+		}
+	}
+
+	if p.User == "" && p.Group == "" {
+		return
+	}
+
+	uid, gid, err := p.ids()
+	if err != nil {
+		s.logger.Warn("Failed to resolve owner of domains directory", zap.String("user", p.User), zap.String("group", p.Group), zap.Error(err))
+		return
+	}
+	if err := os.Chown(dir, uid, gid); err != nil {
+		s.logger.Warn("Failed to change owner of domains directory", zap.String("dir", dir), zap.Error(err))
+	}
+}
@@ -0,0 +1,60 @@
+package service
+
+import (
+	"os"
+	"path/filepath"
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/schumann-it/dehydrated-api-go/internal/dehydrated"
+	"github.com/schumann-it/dehydrated-api-go/internal/model"
+	"github.com/schumann-it/dehydrated-api-go/internal/util"
+)
+
+func TestFilePermissions(t *testing.T) {
+	dc := dehydrated.NewConfig().WithBaseDir(t.TempDir()).Load()
+	s := NewDomainService(dc, nil).WithFilePermissions(FilePermissions{
+		FileMode: 0o640,
+		DirMode:  0o750,
+		Group:    strconv.Itoa(os.Getgid()),
+	})
+	defer s.Close()
+
+	mode := func(t *testing.T, path string) os.FileMode {
+		fi, err := os.Stat(path)
+		require.NoError(t, err)
+		return fi.Mode().Perm()
+	}
+
+	// Applied to the existing file and its directory
+	require.Equal(t, os.FileMode(0o640), mode(t, dc.DomainsFile))
+	require.Equal(t, os.FileMode(0o750), mode(t, filepath.Dir(dc.DomainsFile)))
+
+	// Applied again on a write to a replaced file
+	require.NoError(t, os.Remove(dc.DomainsFile))
+	require.NoError(t, os.WriteFile(dc.DomainsFile, nil, 0o600))
+	require.NoError(t, os.Chmod(filepath.Dir(dc.DomainsFile), 0o755))
+	_, err := s.CreateDomain(&model.CreateDomainRequest{Domain: "example.com", Enabled: util.BoolPtr(true)})
+	require.NoError(t, err)
+	require.Equal(t, os.FileMode(0o640), mode(t, dc.DomainsFile))
+	require.Equal(t, os.FileMode(0o750), mode(t, filepath.Dir(dc.DomainsFile)))
+}
+
+func TestFilePermissionsUnknownOwner(t *testing.T) {
+	dc := dehydrated.NewConfig().WithBaseDir(t.TempDir()).Load()
+	s := NewDomainService(dc, nil).WithFilePermissions(FilePermissions{
+		FileMode: 0o600,
+		User:     "no-such-user-dehydrated-api",
+	})
+	defer s.Close()
+
+	// The owner cannot be changed, which is logged and does not fail writes
+	_, err := s.CreateDomain(&model.CreateDomainRequest{Domain: "example.com", Enabled: util.BoolPtr(true)})
+	require.NoError(t, err)
+
+	fi, err := os.Stat(dc.DomainsFile)
+	require.NoError(t, err)
+	require.Equal(t, os.FileMode(0o600), fi.Mode().Perm())
+}
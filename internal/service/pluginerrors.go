@@ -0,0 +1,73 @@
+package service
+
+import (
+	"sync"
+	"time"
+
+	"github.com/schumann-it/dehydrated-api-go/internal/model"
+)
+
+// DefaultPluginErrorLimit is the number of plugin errors retained by default.
+const DefaultPluginErrorLimit = 1000
+
+// pluginErrorLog retains the most recent plugin errors in a ring buffer of bounded size.
+type pluginErrorLog struct {
+	mu     sync.Mutex
+	errors []*model.PluginError // ring buffer, next is the index of the oldest entry once full
+	next   int
+	limit  int
+	now    func() time.Time
+}
+
+// newPluginErrorLog creates a pluginErrorLog retaining at most limit errors.
+func newPluginErrorLog(limit int) *pluginErrorLog {
+	if limit <= 0 {
+		limit = DefaultPluginErrorLimit
+	}
+	return &pluginErrorLog{
+		limit: limit,
+		now:   time.Now,
+	}
+}
+
+// record adds an error, dropping the oldest one if the limit is reached, and returns it.
+func (l *pluginErrorLog) record(entry *model.DomainEntry, plugin, message string) *model.PluginError {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	e := &model.PluginError{
+		Domain:    entry.Domain,
+		Alias:     entry.Alias,
+		Plugin:    plugin,
+		Message:   message,
+		Timestamp: l.now(),
+	}
+
+	if len(l.errors) < l.limit {
+		l.errors = append(l.errors, e)
+		return e
+	}
+	l.errors[l.next] = e
+	l.next = (l.next + 1) % l.limit
+	return e
+}
+
+// list returns the given page of the retained errors, newest first.
+func (l *pluginErrorLog) list(page, perPage int) ([]*model.PluginError, *model.PaginationInfo) {
+	page, perPage = model.NormalizePage(page, perPage)
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	total := len(l.errors)
+	pagination := model.NewPaginationInfo(page, perPage, total)
+	start, end := pagination.Bounds()
+
+	result := make([]*model.PluginError, 0, end-start)
+	for i := start; i < end; i++ {
+		// The newest error is the one before next
+		result = append(result, l.errors[(l.next-1-i+2*total)%total])
+	}
+
+	return result, pagination
+}
@@ -0,0 +1,160 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"github.com/schumann-it/dehydrated-api-go/internal/dehydrated"
+	"github.com/schumann-it/dehydrated-api-go/internal/model"
+	"github.com/schumann-it/dehydrated-api-go/internal/plugin/config"
+	"github.com/schumann-it/dehydrated-api-go/internal/plugin/registry"
+	serviceinterface "github.com/schumann-it/dehydrated-api-go/internal/service/interface"
+	"github.com/schumann-it/dehydrated-api-go/internal/testutil"
+	"github.com/schumann-it/dehydrated-api-go/internal/util"
+	pb "github.com/schumann-it/dehydrated-api-go/plugin/proto"
+)
+
+// failingPlugin returns an error for the metadata of every domain.
+type failingPlugin struct {
+	pb.UnimplementedPluginServer
+}
+
+func (p *failingPlugin) Initialize(_ context.Context, _ *pb.InitializeRequest) (*pb.InitializeResponse, error) {
+	return &pb.InitializeResponse{}, nil
+}
+
+func (p *failingPlugin) GetMetadata(_ context.Context, req *pb.GetMetadataRequest) (*pb.GetMetadataResponse, error) {
+	return &pb.GetMetadataResponse{Error: "lookup failed for " + req.GetDomainEntry().GetDomain()}, nil
+}
+
+func (p *failingPlugin) Close(_ context.Context, _ *pb.CloseRequest) (*pb.CloseResponse, error) {
+	return &pb.CloseResponse{}, nil
+}
+
+func TestPluginErrors(t *testing.T) {
+	address, _ := testutil.ServePlugin(t, &failingPlugin{})
+
+	dc := dehydrated.NewConfig().WithBaseDir(t.TempDir()).Load()
+	r := registry.New(dc.BaseDir, map[string]config.PluginConfig{
+		"failing": {Enabled: true, Address: address, Insecure: true},
+	}, zap.NewNop())
+	s := NewDomainService(dc, r)
+	defer s.Close()
+
+	for _, domain := range []string{"a.example.com", "b.example.com"} {
+		_, err := s.CreateDomain(&model.CreateDomainRequest{Domain: domain, Enabled: util.BoolPtr(true)})
+		require.NoError(t, err)
+	}
+
+	errs, pagination, err := s.PluginErrors(1, 10)
+	require.NoError(t, err)
+	require.Empty(t, errs)
+	require.Zero(t, pagination.Total)
+
+	_, _, err = s.ListDomains(1, 10, "asc", "")
+	require.NoError(t, err)
+
+	errs, pagination, err = s.PluginErrors(1, 10)
+	require.NoError(t, err)
+	require.Equal(t, 2, pagination.Total)
+	require.Len(t, errs, 2)
+
+	// Newest first
+	require.Equal(t, "b.example.com", errs[0].Domain)
+	require.Equal(t, "failing", errs[0].Plugin)
+	require.Equal(t, "lookup failed for b.example.com", errs[0].Message)
+	require.False(t, errs[0].Timestamp.IsZero())
+	require.Equal(t, "a.example.com", errs[1].Domain)
+
+	// Skipping the metadata skips the plugins
+	_, _, err = s.ListDomains(1, 10, "", "", serviceinterface.WithoutMetadata())
+	require.NoError(t, err)
+	_, pagination, err = s.PluginErrors(1, 10)
+	require.NoError(t, err)
+	require.Equal(t, 2, pagination.Total)
+
+	// Strict mode fails with the plugin errors of the request
+	_, _, err = s.ListDomains(1, 10, "", "", serviceinterface.WithStrictPlugins())
+	require.ErrorIs(t, err, serviceinterface.ErrPluginFailed)
+	var failure *serviceinterface.PluginFailureError
+	require.ErrorAs(t, err, &failure)
+	require.Len(t, failure.Errors, 2)
+
+	_, err = s.GetDomain("a.example.com", "", serviceinterface.WithStrictPlugins())
+	require.ErrorAs(t, err, &failure)
+	require.Len(t, failure.Errors, 1)
+	require.Equal(t, "a.example.com", failure.Errors[0].Domain)
+
+	// Skipping the metadata skips the plugins in strict mode as well
+	_, _, err = s.ListDomains(1, 10, "", "", serviceinterface.WithStrictPlugins(), serviceinterface.WithoutMetadata())
+	require.NoError(t, err)
+}
+
+func TestPluginErrorLog(t *testing.T) {
+	l := newPluginErrorLog(3)
+	for i := 0; i < 5; i++ {
+		l.record(&model.DomainEntry{DomainEntry: pb.DomainEntry{Domain: fmt.Sprintf("%d.example.com", i)}}, "p", "error")
+	}
+
+	domains := func(errs []*model.PluginError) []string {
+		result := make([]string, len(errs))
+		for i, e := range errs {
+			result[i] = e.Domain
+		}
+		return result
+	}
+
+	// Only the newest errors are retained
+	errs, pagination := l.list(1, 10)
+	require.Equal(t, []string{"4.example.com", "3.example.com", "2.example.com"}, domains(errs))
+	require.Equal(t, 3, pagination.Total)
+	require.Equal(t, 1, pagination.TotalPages)
+
+	errs, pagination = l.list(2, 2)
+	require.Equal(t, []string{"2.example.com"}, domains(errs))
+	require.False(t, pagination.HasNext)
+	require.True(t, pagination.HasPrev)
+
+	errs, _ = l.list(3, 2)
+	require.Empty(t, errs)
+}
+
+func TestPlugins(t *testing.T) {
+	dc := dehydrated.NewConfig().WithBaseDir(t.TempDir()).Load()
+	zeta, _ := testutil.ServePlugin(t, &failingPlugin{})
+	alpha, _ := testutil.ServePlugin(t, &failingPlugin{})
+	r := registry.New(dc.BaseDir, map[string]config.PluginConfig{
+		"zeta":  {Enabled: true, Address: zeta, Insecure: true},
+		"alpha": {Enabled: true, Address: alpha, Insecure: true, Validate: true},
+		"off":   {Enabled: false},
+	}, zap.NewNop())
+	s := NewDomainService(dc, r)
+	defer s.Close()
+
+	plugins, pagination, err := s.Plugins(1, 10)
+	require.NoError(t, err)
+	require.Equal(t, []*model.PluginInfo{
+		{Name: "alpha", Validate: true, Capabilities: []string{pb.CapabilityValidate}},
+		{Name: "zeta", Capabilities: []string{pb.CapabilityValidate}},
+	}, plugins)
+	require.Equal(t, 2, pagination.Total)
+
+	plugins, pagination, err = s.Plugins(2, 1)
+	require.NoError(t, err)
+	require.Equal(t, []*model.PluginInfo{{Name: "zeta", Capabilities: []string{pb.CapabilityValidate}}}, plugins)
+	require.True(t, pagination.HasPrev)
+	require.False(t, pagination.HasNext)
+
+	// Without a registry there are no plugins
+	s = NewDomainService(dc, nil)
+	defer s.Close()
+	plugins, pagination, err = s.Plugins(0, 0)
+	require.NoError(t, err)
+	require.Empty(t, plugins)
+	require.Equal(t, model.MinPage, pagination.CurrentPage)
+	require.Equal(t, model.DefaultPerPage, pagination.PerPage)
+}
@@ -0,0 +1,121 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/schumann-it/dehydrated-api-go/internal/model"
+	serviceinterface "github.com/schumann-it/dehydrated-api-go/internal/service/interface"
+	pb "github.com/schumann-it/dehydrated-api-go/plugin/proto"
+)
+
+// DefaultPluginValidationTimeout is the time plugins are given to validate an entry before the validation fails.
+const DefaultPluginValidationTimeout = 30 * time.Second
+
+// maxValidationAttempts is the number of times a change is validated by the plugins again because the entry
+// changed concurrently while the plugins validated it, before it fails.
+const maxValidationAttempts = 3
+
+// pluginVerdict is the result of the plugin validation of an entry.
+type pluginVerdict struct {
+	entry *model.DomainEntry
+	err   error
+}
+
+// pluginVerdicts are the results of the plugin validations of a change, collected by withPluginValidation.
+type pluginVerdicts []pluginVerdict
+
+// unvalidatedError is returned by a change if entries have to be validated by the plugins before it can be applied.
+type unvalidatedError struct {
+	entries []*model.DomainEntry
+}
+
+func (e *unvalidatedError) Error() string {
+	return fmt.Sprintf("%d entries not validated by plugins", len(e.entries))
+}
+
+// pluginVerdict returns the result of the plugin validation of entry. It must be called with the mutex held.
+// If the plugins did not validate entry as it is, e.g., because it was changed concurrently, an *unvalidatedError
+// is returned, so withPluginValidation validates it and applies the change again.
+func (s *DomainService) pluginVerdict(verdicts pluginVerdicts, entry *model.DomainEntry) error {
+	if len(s.currentRegistry().Validators()) == 0 {
+		return nil
+	}
+	for _, v := range verdicts {
+		if v.entry.Equals(entry) && v.entry.CA == entry.CA {
+			return v.err
+		}
+	}
+	return &unvalidatedError{entries: []*model.DomainEntry{entry}}
+}
+
+// withPluginValidation applies a change and validates its entries with the plugins if the change returns an
+// *unvalidatedError. The plugins are called without holding the mutex, so slow or hanging plugins neither block
+// reads nor other changes, and the change is applied again with their verdicts.
+func (s *DomainService) withPluginValidation(change func(verdicts pluginVerdicts) error) error {
+	var verdicts pluginVerdicts
+	for range maxValidationAttempts {
+		err := change(verdicts)
+		var unvalidated *unvalidatedError
+		if !errors.As(err, &unvalidated) {
+			return err
+		}
+		for _, entry := range unvalidated.entries {
+			verdicts = append(verdicts, pluginVerdict{entry: entry, err: s.validateWithPlugins(entry)})
+		}
+	}
+	return fmt.Errorf("%w: entries changed concurrently while being validated", serviceinterface.ErrPluginFailed)
+}
+
+// validateWithPlugins calls the Validate method of all plugins with validation enabled, in order of their names.
+// A failed call rejects the entry as well, so entries are never written without the plugins' consent.
+// The plugins are called without holding the mutex and have DefaultPluginValidationTimeout to respond.
+func (s *DomainService) validateWithPlugins(entry *model.DomainEntry) error {
+	registry, release := s.acquireRegistry()
+	defer release()
+
+	validators := registry.Validators()
+	order := registry.Order()
+	s.mutex.RLock()
+	cfg := s.entryConfig(entry).ToProto()
+	s.mutex.RUnlock()
+
+	ctx, cancel := context.WithTimeout(context.Background(), DefaultPluginValidationTimeout)
+	defer cancel()
+
+	for _, name := range order {
+		validator, ok := validators[name]
+		if !ok {
+			continue
+		}
+		// Validation is enabled for the plugin, so an entry must not be written without it
+		if !slices.Contains(registry.Capabilities(name), pb.CapabilityValidate) {
+			s.logger.Error("plugin does not support validation", zap.String("plugin", name), zap.String("domain", entry.Domain))
+			return fmt.Errorf("%w: %s for %s: plugin does not support validation", serviceinterface.ErrPluginFailed, name, entry.Domain)
+		}
+		resp, err := validator.Validate(ctx, &pb.ValidateRequest{
+			DomainEntry:      &entry.DomainEntry,
+			DehydratedConfig: cfg,
+		})
+		if err != nil {
+			s.logger.Error("plugin validation failed", zap.String("plugin", name), zap.String("domain", entry.Domain), zap.Error(err))
+			return fmt.Errorf("%w: %s for %s: %v", serviceinterface.ErrPluginFailed, name, entry.Domain, err)
+		}
+
+		if !resp.GetValid() {
+			reason := resp.GetReason()
+			if reason == "" {
+				reason = "no reason given"
+			}
+			s.logger.Error("Domain entry rejected by plugin", zap.String("plugin", name), zap.Any("entry", entry), zap.String("reason", reason))
+			return fmt.Errorf("%w: rejected by plugin %s: %s", serviceinterface.ErrInvalidDomainEntry, name, reason)
+		}
+	}
+
+	return nil
+}
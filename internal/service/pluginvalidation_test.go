@@ -0,0 +1,162 @@
+package service
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"github.com/schumann-it/dehydrated-api-go/internal/dehydrated"
+	"github.com/schumann-it/dehydrated-api-go/internal/model"
+	"github.com/schumann-it/dehydrated-api-go/internal/plugin/config"
+	"github.com/schumann-it/dehydrated-api-go/internal/plugin/registry"
+	serviceinterface "github.com/schumann-it/dehydrated-api-go/internal/service/interface"
+	"github.com/schumann-it/dehydrated-api-go/internal/testutil"
+	pb "github.com/schumann-it/dehydrated-api-go/plugin/proto"
+)
+
+// vetoPlugin rejects domains ending in .denied.example.com.
+type vetoPlugin struct {
+	pb.UnimplementedPluginServer
+}
+
+func (p *vetoPlugin) Initialize(_ context.Context, _ *pb.InitializeRequest) (*pb.InitializeResponse, error) {
+	return &pb.InitializeResponse{}, nil
+}
+
+func (p *vetoPlugin) GetMetadata(_ context.Context, _ *pb.GetMetadataRequest) (*pb.GetMetadataResponse, error) {
+	return &pb.GetMetadataResponse{}, nil
+}
+
+func (p *vetoPlugin) Validate(_ context.Context, req *pb.ValidateRequest) (*pb.ValidateResponse, error) {
+	if strings.HasSuffix(req.GetDomainEntry().GetDomain(), ".denied.example.com") {
+		return &pb.ValidateResponse{Reason: "not in inventory"}, nil
+	}
+	return &pb.ValidateResponse{Valid: true}, nil
+}
+
+func (p *vetoPlugin) Close(_ context.Context, _ *pb.CloseRequest) (*pb.CloseResponse, error) {
+	return &pb.CloseResponse{}, nil
+}
+
+// blockingPlugin blocks the validation of slow.example.com until release is closed.
+type blockingPlugin struct {
+	vetoPlugin
+	called  chan struct{}
+	release chan struct{}
+}
+
+func (p *blockingPlugin) Validate(ctx context.Context, req *pb.ValidateRequest) (*pb.ValidateResponse, error) {
+	if req.GetDomainEntry().GetDomain() == "slow.example.com" {
+		p.called <- struct{}{}
+		<-p.release
+	}
+	return p.vetoPlugin.Validate(ctx, req)
+}
+
+func TestPluginValidation(t *testing.T) {
+	newService := func(t *testing.T, plugins map[string]config.PluginConfig) *DomainService {
+		dc := dehydrated.NewConfig().WithBaseDir(t.TempDir()).Load()
+		s := NewDomainService(dc, registry.New(dc.BaseDir, plugins, zap.NewNop()))
+		t.Cleanup(func() { _ = s.Close() })
+		return s
+	}
+
+	t.Run("Veto", func(t *testing.T) {
+		address, _ := testutil.ServePlugin(t, &vetoPlugin{})
+		s := newService(t, map[string]config.PluginConfig{
+			"veto": {Enabled: true, Address: address, Insecure: true, Validate: true},
+		})
+
+		_, err := s.CreateDomain(&model.CreateDomainRequest{Domain: "a.denied.example.com"})
+		require.ErrorIs(t, err, serviceinterface.ErrInvalidDomainEntry)
+		require.ErrorContains(t, err, "rejected by plugin veto: not in inventory")
+		_, err = s.GetDomain("a.denied.example.com", "")
+		require.ErrorIs(t, err, serviceinterface.ErrDomainNotFound)
+
+		_, err = s.CreateDomain(&model.CreateDomainRequest{Domain: "example.com"})
+		require.NoError(t, err)
+
+		// Changes are validated as well
+		enabled := true
+		_, err = s.UpdateDomain("example.com", model.UpdateDomainRequest{Enabled: &enabled})
+		require.NoError(t, err)
+		_, err = s.RenameDomain("example.com", "", model.RenameDomainRequest{Domain: "b.denied.example.com"})
+		require.ErrorIs(t, err, serviceinterface.ErrInvalidDomainEntry)
+	})
+
+	t.Run("NotEnabled", func(t *testing.T) {
+		address, _ := testutil.ServePlugin(t, &vetoPlugin{})
+		s := newService(t, map[string]config.PluginConfig{
+			"veto": {Enabled: true, Address: address, Insecure: true},
+		})
+
+		_, err := s.CreateDomain(&model.CreateDomainRequest{Domain: "a.denied.example.com"})
+		require.NoError(t, err)
+	})
+
+	t.Run("Priority", func(t *testing.T) {
+		a, _ := testutil.ServePlugin(t, &vetoPlugin{})
+		b, _ := testutil.ServePlugin(t, &vetoPlugin{})
+		s := newService(t, map[string]config.PluginConfig{
+			"a": {Enabled: true, Address: a, Insecure: true, Validate: true},
+			"b": {Enabled: true, Address: b, Insecure: true, Validate: true, Priority: 10},
+		})
+
+		// The plugin with the higher priority is called first, so its rejection wins over the order of names
+		_, err := s.CreateDomain(&model.CreateDomainRequest{Domain: "a.denied.example.com"})
+		require.ErrorContains(t, err, "rejected by plugin b:")
+	})
+
+	t.Run("Unimplemented", func(t *testing.T) {
+		address, _ := testutil.ServePlugin(t, &failingPlugin{})
+		s := newService(t, map[string]config.PluginConfig{
+			"failing": {Enabled: true, Address: address, Insecure: true, Validate: true},
+		})
+
+		_, err := s.CreateDomain(&model.CreateDomainRequest{Domain: "example.com"})
+		require.ErrorIs(t, err, serviceinterface.ErrPluginFailed)
+		_, err = s.GetDomain("example.com", "")
+		require.ErrorIs(t, err, serviceinterface.ErrDomainNotFound)
+	})
+
+	t.Run("OutsideLock", func(t *testing.T) {
+		p := &blockingPlugin{called: make(chan struct{}), release: make(chan struct{})}
+		address, _ := testutil.ServePlugin(t, p)
+		s := newService(t, map[string]config.PluginConfig{
+			"blocking": {Enabled: true, Address: address, Insecure: true, Validate: true},
+		})
+		_, err := s.CreateDomain(&model.CreateDomainRequest{Domain: "example.com"})
+		require.NoError(t, err)
+
+		errs := make(chan error, 2)
+		for range 2 {
+			go func() {
+				_, err := s.CreateDomain(&model.CreateDomainRequest{Domain: "slow.example.com"})
+				errs <- err
+			}()
+		}
+		<-p.called
+		<-p.called
+
+		// Neither reads nor other changes wait for the hanging validations
+		_, err = s.GetDomain("example.com", "", serviceinterface.WithoutMetadata())
+		require.NoError(t, err)
+		_, err = s.CreateDomain(&model.CreateDomainRequest{Domain: "fast.example.com"})
+		require.NoError(t, err)
+
+		// Both creations were validated, but only one of them is applied
+		close(p.release)
+		err1, err2 := <-errs, <-errs
+		if err1 != nil {
+			err1, err2 = err2, err1
+		}
+		require.NoError(t, err1)
+		require.ErrorIs(t, err2, serviceinterface.ErrDomainExists)
+		entries, _, err := s.ListDomains(1, 10, "", "", serviceinterface.WithoutMetadata())
+		require.NoError(t, err)
+		require.Len(t, entries, 3)
+	})
+}
@@ -0,0 +1,606 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/structpb"
+
+	"github.com/schumann-it/dehydrated-api-go/internal/dehydrated"
+	"github.com/schumann-it/dehydrated-api-go/internal/model"
+	serviceinterface "github.com/schumann-it/dehydrated-api-go/internal/service/interface"
+	"github.com/schumann-it/dehydrated-api-go/internal/util"
+	pb "github.com/schumann-it/dehydrated-api-go/plugin/proto"
+)
+
+func TestEnrichMetadataWithMockRegistry(t *testing.T) {
+	r := &serviceinterface.MockPluginRegistry{Clients: map[string]*serviceinterface.MockPlugin{
+		"dns": {Metadata: map[string]*structpb.Value{
+			"provider": structpb.NewStringValue("example"),
+			"ttl":      structpb.NewNumberValue(300),
+		}},
+		"empty":       {},
+		"unreachable": {Err: errors.New("connection refused")},
+		"partial":     {Error: "lookup failed"},
+	}}
+	dc := dehydrated.NewConfig().WithBaseDir(t.TempDir()).Load()
+	s := NewDomainService(dc, r)
+	defer s.Close()
+
+	_, err := s.CreateDomain(&model.CreateDomainRequest{Domain: "example.com", Enabled: util.BoolPtr(true)})
+	require.NoError(t, err)
+
+	entry, err := s.GetDomain("example.com", "")
+	require.NoError(t, err)
+	require.Equal(t, map[string]any{"provider": "example", "ttl": int64(300)}, entry.Metadata.Get("dns"))
+	require.Nil(t, entry.Metadata.Get("empty"))
+	require.Equal(t, map[string]any{"error": "connection refused"}, entry.Metadata.Get("unreachable"))
+	require.Equal(t, map[string]any{"error": "lookup failed"}, entry.Metadata.Get("partial"))
+	for name, p := range r.Clients {
+		require.Equal(t, 1, p.MetadataCalls, name)
+	}
+
+	errs, pagination, err := s.PluginErrors(1, 10)
+	require.NoError(t, err)
+	require.Equal(t, 2, pagination.Total)
+	messages := map[string]string{}
+	for _, e := range errs {
+		messages[e.Plugin] = e.Message
+	}
+	require.Equal(t, map[string]string{"unreachable": "connection refused", "partial": "lookup failed"}, messages)
+
+	// Strict mode fails with both plugin errors
+	_, err = s.GetDomain("example.com", "", serviceinterface.WithStrictPlugins())
+	var failure *serviceinterface.PluginFailureError
+	require.ErrorAs(t, err, &failure)
+	require.Len(t, failure.Errors, 2)
+
+	// Skipping the metadata skips the plugins
+	_, err = s.GetDomain("example.com", "", serviceinterface.WithoutMetadata())
+	require.NoError(t, err)
+	require.Equal(t, 2, r.Clients["dns"].MetadataCalls)
+}
+
+func TestValidateWithMockRegistry(t *testing.T) {
+	veto := &serviceinterface.MockPlugin{Validates: true, Reject: "not in inventory"}
+	broken := &serviceinterface.MockPlugin{Validates: true, Err: errors.New("connection refused")}
+	passive := &serviceinterface.MockPlugin{}
+	r := &serviceinterface.MockPluginRegistry{Clients: map[string]*serviceinterface.MockPlugin{
+		"veto":    veto,
+		"passive": passive,
+	}}
+	dc := dehydrated.NewConfig().WithBaseDir(t.TempDir()).Load()
+	s := NewDomainService(dc, r)
+	defer s.Close()
+
+	_, err := s.CreateDomain(&model.CreateDomainRequest{Domain: "example.com", Enabled: util.BoolPtr(true)})
+	require.ErrorIs(t, err, serviceinterface.ErrInvalidDomainEntry)
+	require.ErrorContains(t, err, "rejected by plugin veto: not in inventory")
+	require.Equal(t, 1, veto.ValidateCalls)
+	require.Zero(t, passive.ValidateCalls)
+
+	veto.Reject = ""
+	_, err = s.CreateDomain(&model.CreateDomainRequest{Domain: "example.com", Enabled: util.BoolPtr(true)})
+	require.NoError(t, err)
+
+	// A failing validator rejects the entry as well
+	r.Clients["broken"] = broken
+	_, err = s.CreateDomain(&model.CreateDomainRequest{Domain: "other.example.com", Enabled: util.BoolPtr(true)})
+	require.ErrorIs(t, err, serviceinterface.ErrPluginFailed)
+	require.Equal(t, 1, broken.ValidateCalls)
+}
+
+func TestReplaceRegistry(t *testing.T) {
+	old := &serviceinterface.MockPluginRegistry{Clients: map[string]*serviceinterface.MockPlugin{
+		"old": {},
+	}}
+	dc := dehydrated.NewConfig().WithBaseDir(t.TempDir()).Load()
+	s := NewDomainService(dc, old)
+	defer s.Close()
+
+	r := &serviceinterface.MockPluginRegistry{Clients: map[string]*serviceinterface.MockPlugin{
+		"new": {Validates: true},
+	}}
+	s.ReplaceRegistry(r)
+	require.Eventually(t, old.IsClosed, time.Second, time.Millisecond)
+	require.False(t, r.IsClosed())
+
+	plugins, _, err := s.Plugins(1, 10)
+	require.NoError(t, err)
+	require.Equal(t, []*model.PluginInfo{{Name: "new", Validate: true, Capabilities: []string{pb.CapabilityValidate}}}, plugins)
+
+	// Replacing the registry with none removes all plugins
+	s.ReplaceRegistry(nil)
+	require.Eventually(t, r.IsClosed, time.Second, time.Millisecond)
+	plugins, _, err = s.Plugins(1, 10)
+	require.NoError(t, err)
+	require.Empty(t, plugins)
+}
+
+// TestReplaceRegistryInFlight verifies that a replaced registry is only closed once the requests
+// calling its plugins are done, and later requests use the new registry.
+func TestReplaceRegistryInFlight(t *testing.T) {
+	started, release := make(chan struct{}), make(chan struct{})
+	old := &serviceinterface.MockPluginRegistry{Clients: map[string]*serviceinterface.MockPlugin{
+		"cmdb": {
+			Metadata:   map[string]*structpb.Value{"owner": structpb.NewStringValue("old")},
+			OnMetadata: func() { close(started); <-release },
+		},
+	}}
+	dc := dehydrated.NewConfig().WithBaseDir(t.TempDir()).Load()
+	s := NewDomainService(dc, old)
+	defer s.Close()
+	_, err := s.CreateDomain(&model.CreateDomainRequest{Domain: "example.com", Enabled: util.BoolPtr(true)})
+	require.NoError(t, err)
+
+	type result struct {
+		entry *model.DomainEntry
+		err   error
+	}
+	inFlight := make(chan result, 1)
+	go func() {
+		entry, err := s.GetDomain("example.com", "")
+		inFlight <- result{entry, err}
+	}()
+	<-started
+
+	r := &serviceinterface.MockPluginRegistry{Clients: map[string]*serviceinterface.MockPlugin{
+		"cmdb": {Metadata: map[string]*structpb.Value{"owner": structpb.NewStringValue("new")}},
+	}}
+	s.ReplaceRegistry(r)
+
+	// Later requests use the new registry while the old one is still in use
+	entry, err := s.GetDomain("example.com", "")
+	require.NoError(t, err)
+	require.Equal(t, map[string]any{"owner": "new"}, entry.Metadata.Get("cmdb"))
+	require.Never(t, old.IsClosed, 50*time.Millisecond, time.Millisecond)
+
+	// The request in flight finishes with the old registry, which is closed afterwards
+	close(release)
+	res := <-inFlight
+	require.NoError(t, res.err)
+	require.Equal(t, map[string]any{"owner": "old"}, res.entry.Metadata.Get("cmdb"))
+	require.Eventually(t, old.IsClosed, time.Second, time.Millisecond)
+	require.False(t, r.IsClosed())
+}
+
+// TestMetadataPriority verifies that the metadata of plugins setting the same key is namespaced by
+// default and, if merged, the value of the plugin with the higher priority wins consistently.
+func TestMetadataPriority(t *testing.T) {
+	newRegistry := func(priorities map[string]int) *serviceinterface.MockPluginRegistry {
+		return &serviceinterface.MockPluginRegistry{
+			Clients: map[string]*serviceinterface.MockPlugin{
+				"cmdb": {Metadata: map[string]*structpb.Value{
+					"owner": structpb.NewStringValue("cmdb-team"),
+					"site":  structpb.NewStringValue("dc1"),
+				}},
+				"netbox": {Metadata: map[string]*structpb.Value{
+					"owner": structpb.NewStringValue("netbox-team"),
+					"vlan":  structpb.NewNumberValue(42),
+				}},
+				"broken": {Err: errors.New("connection refused")},
+			},
+			Priorities: priorities,
+		}
+	}
+	newService := func(t *testing.T, r serviceinterface.PluginRegistry) *DomainService {
+		dc := dehydrated.NewConfig().WithBaseDir(t.TempDir()).Load()
+		s := NewDomainService(dc, r)
+		t.Cleanup(func() { _ = s.Close() })
+		_, err := s.CreateDomain(&model.CreateDomainRequest{Domain: "example.com", Enabled: util.BoolPtr(true)})
+		require.NoError(t, err)
+		return s
+	}
+
+	t.Run("Namespaced", func(t *testing.T) {
+		s := newService(t, newRegistry(map[string]int{"netbox": 10}))
+
+		entry, err := s.GetDomain("example.com", "")
+		require.NoError(t, err)
+		require.Equal(t, map[string]any{"owner": "cmdb-team", "site": "dc1"}, entry.Metadata.Get("cmdb"))
+		require.Equal(t, map[string]any{"owner": "netbox-team", "vlan": int64(42)}, entry.Metadata.Get("netbox"))
+	})
+
+	for _, tt := range []struct {
+		name       string
+		priorities map[string]int
+		owner      string
+	}{
+		{"HigherPriorityWins", map[string]int{"netbox": 10}, "netbox-team"},
+		{"NegativePriority", map[string]int{"cmdb": -1}, "netbox-team"},
+		{"SamePriorityByName", nil, "cmdb-team"},
+	} {
+		t.Run("Merged"+tt.name, func(t *testing.T) {
+			s := newService(t, newRegistry(tt.priorities)).WithMergedMetadata()
+
+			for range 20 {
+				entry, err := s.GetDomain("example.com", "")
+				require.NoError(t, err)
+				require.Equal(t, map[string]any{
+					"owner":  tt.owner,
+					"site":   "dc1",
+					"vlan":   int64(42),
+					"broken": map[string]any{"error": "connection refused"},
+				}, entry.Metadata.Values())
+			}
+		})
+	}
+
+	t.Run("PluginErrorOrder", func(t *testing.T) {
+		r := newRegistry(map[string]int{"netbox": 10})
+		r.Clients["netbox"].Err = errors.New("timeout")
+		s := newService(t, r)
+
+		_, err := s.GetDomain("example.com", "", serviceinterface.WithStrictPlugins())
+		var failure *serviceinterface.PluginFailureError
+		require.ErrorAs(t, err, &failure)
+		require.Len(t, failure.Errors, 2)
+		require.Equal(t, "netbox", failure.Errors[0].Plugin)
+		require.Equal(t, "broken", failure.Errors[1].Plugin)
+	})
+}
+
+// TestMetadataExplain verifies that the plugin that produced each metadata value is recorded only if requested.
+func TestMetadataExplain(t *testing.T) {
+	newService := func(t *testing.T) *DomainService {
+		r := &serviceinterface.MockPluginRegistry{
+			Clients: map[string]*serviceinterface.MockPlugin{
+				"cmdb": {Metadata: map[string]*structpb.Value{
+					"owner": structpb.NewStringValue("cmdb-team"),
+					"site":  structpb.NewStringValue("dc1"),
+				}},
+				"netbox": {Metadata: map[string]*structpb.Value{
+					"owner": structpb.NewStringValue("netbox-team"),
+					"vlan":  structpb.NewNumberValue(42),
+				}},
+			},
+			Priorities: map[string]int{"netbox": 10},
+		}
+		dc := dehydrated.NewConfig().WithBaseDir(t.TempDir()).Load()
+		s := NewDomainService(dc, r)
+		t.Cleanup(func() { _ = s.Close() })
+		_, err := s.CreateDomain(&model.CreateDomainRequest{Domain: "example.com", Enabled: util.BoolPtr(true)})
+		require.NoError(t, err)
+		return s
+	}
+	plugins := func(provenance map[string]*model.MetadataProvenance) map[string]string {
+		result := make(map[string]string, len(provenance))
+		for k, p := range provenance {
+			require.Equal(t, model.ProvenanceSourcePlugin, p.Source, k)
+			require.GreaterOrEqual(t, p.DurationMS, float64(0), k)
+			result[k] = p.Plugin
+		}
+		return result
+	}
+
+	t.Run("Disabled", func(t *testing.T) {
+		entry, err := newService(t).GetDomain("example.com", "")
+		require.NoError(t, err)
+		require.Nil(t, entry.Provenance)
+	})
+
+	t.Run("Namespaced", func(t *testing.T) {
+		entry, err := newService(t).GetDomain("example.com", "", serviceinterface.WithExplain())
+		require.NoError(t, err)
+		require.Equal(t, map[string]string{"cmdb": "cmdb", "netbox": "netbox"}, plugins(entry.Provenance))
+	})
+
+	t.Run("Merged", func(t *testing.T) {
+		s := newService(t).WithMergedMetadata()
+
+		entry, err := s.GetDomain("example.com", "", serviceinterface.WithExplain())
+		require.NoError(t, err)
+		require.Equal(t, "netbox-team", entry.Metadata.Get("owner"))
+		require.Equal(t, map[string]string{"owner": "netbox", "vlan": "netbox", "site": "cmdb"}, plugins(entry.Provenance))
+
+		// The cache is not modified
+		entry, err = s.GetDomain("example.com", "")
+		require.NoError(t, err)
+		require.Nil(t, entry.Provenance)
+	})
+}
+
+func TestMetadataBatch(t *testing.T) {
+	newRegistry := func(batches bool) *serviceinterface.MockPluginRegistry {
+		var capabilities []string
+		if batches {
+			capabilities = []string{pb.CapabilityMetadataBatch}
+		}
+		return &serviceinterface.MockPluginRegistry{
+			Clients: map[string]*serviceinterface.MockPlugin{
+				"cmdb": {Capabilities: capabilities, Metadata: map[string]*structpb.Value{
+					"owner": structpb.NewStringValue("cmdb-team"),
+				}},
+				"netbox": {Capabilities: capabilities, Error: "rate limited"},
+				"broken": {Capabilities: capabilities, Err: errors.New("connection refused")},
+			},
+		}
+	}
+	list := func(t *testing.T, r serviceinterface.PluginRegistry) ([]*model.DomainEntry, *DomainService) {
+		dc := dehydrated.NewConfig().WithBaseDir(t.TempDir()).Load()
+		s := NewDomainService(dc, r)
+		t.Cleanup(func() { _ = s.Close() })
+		for _, req := range []*model.CreateDomainRequest{
+			{Domain: "example.com", Enabled: util.BoolPtr(true)},
+			{Domain: "example.com", Alias: "example-ecc", Enabled: util.BoolPtr(true)},
+			{Domain: "example.org"},
+		} {
+			_, err := s.CreateDomain(req)
+			require.NoError(t, err)
+		}
+
+		entries, _, err := s.ListDomains(1, 10, "", "")
+		require.NoError(t, err)
+		return entries, s
+	}
+
+	unbatched := newRegistry(false)
+	expected, s := list(t, unbatched)
+	expectedErrors, _, err := s.PluginErrors(1, 10)
+	require.NoError(t, err)
+	require.Equal(t, 3, unbatched.Clients["cmdb"].MetadataCalls)
+	require.Zero(t, unbatched.Clients["cmdb"].BatchCalls)
+
+	t.Run("SameResults", func(t *testing.T) {
+		batched := newRegistry(true)
+		entries, s := list(t, batched)
+		require.Equal(t, 1, batched.Clients["cmdb"].BatchCalls)
+		require.Zero(t, batched.Clients["cmdb"].MetadataCalls)
+
+		require.Len(t, entries, len(expected))
+		for i := range entries {
+			require.Equal(t, expected[i].Metadata.Values(), entries[i].Metadata.Values(), entries[i].Domain)
+		}
+
+		pluginErrors, _, err := s.PluginErrors(1, 10)
+		require.NoError(t, err)
+		require.Len(t, pluginErrors, len(expectedErrors))
+		for i := range pluginErrors {
+			require.Equal(t, expectedErrors[i].Plugin, pluginErrors[i].Plugin)
+			require.Equal(t, expectedErrors[i].Domain, pluginErrors[i].Domain)
+			require.Equal(t, expectedErrors[i].Alias, pluginErrors[i].Alias)
+			require.Equal(t, expectedErrors[i].Message, pluginErrors[i].Message)
+		}
+	})
+
+	t.Run("SingleEntry", func(t *testing.T) {
+		batched := newRegistry(true)
+		_, s := list(t, batched)
+		calls := batched.Clients["cmdb"].BatchCalls
+
+		entry, err := s.GetDomain("example.com", "")
+		require.NoError(t, err)
+		require.Equal(t, expected[0].Metadata.Values(), entry.Metadata.Values())
+		require.Equal(t, calls, batched.Clients["cmdb"].BatchCalls, "a single entry is requested with GetMetadata")
+	})
+
+	t.Run("FallbackIfUnimplemented", func(t *testing.T) {
+		batched := newRegistry(true)
+		for _, c := range batched.Clients {
+			c.BatchErr = status.Error(codes.Unimplemented, "method GetMetadataBatch not implemented")
+		}
+		entries, _ := list(t, batched)
+		require.Equal(t, 3, batched.Clients["cmdb"].MetadataCalls)
+
+		for i := range entries {
+			require.Equal(t, expected[i].Metadata.Values(), entries[i].Metadata.Values(), entries[i].Domain)
+		}
+	})
+}
+
+// TestDomainMetadata verifies that plugins advertising pb.CapabilityDomainMetadata are called once per domain
+// and their metadata is shared by the entries of the domain.
+func TestDomainMetadata(t *testing.T) {
+	for _, capabilities := range [][]string{nil, {pb.CapabilityDomainMetadata}} {
+		t.Run(fmt.Sprintf("Capabilities%v", capabilities), func(t *testing.T) {
+			r := &serviceinterface.MockPluginRegistry{
+				Clients: map[string]*serviceinterface.MockPlugin{
+					"cmdb": {
+						Capabilities: capabilities,
+						Metadata:     map[string]*structpb.Value{"owner": structpb.NewStringValue("cmdb-team")},
+					},
+					"broken": {Capabilities: capabilities, Err: errors.New("connection refused")},
+				},
+			}
+			dc := dehydrated.NewConfig().WithBaseDir(t.TempDir()).Load()
+			s := NewDomainService(dc, r)
+			t.Cleanup(func() { _ = s.Close() })
+			for _, req := range []*model.CreateDomainRequest{
+				{Domain: "example.com", Alias: "rsa"},
+				{Domain: "example.com", Alias: "ecdsa"},
+				{Domain: "example.org"},
+			} {
+				_, err := s.CreateDomain(req)
+				require.NoError(t, err)
+			}
+
+			entries, _, err := s.ListDomains(1, 10, "", "")
+			require.NoError(t, err)
+			require.Len(t, entries, 3)
+			for _, entry := range entries {
+				require.Equal(t, map[string]any{
+					"cmdb":   map[string]any{"owner": "cmdb-team"},
+					"broken": map[string]any{"error": "connection refused"},
+				}, entry.Metadata.Values(), entry.PathName())
+			}
+
+			calls := 3
+			if capabilities != nil {
+				calls = 2
+			}
+			require.Equal(t, calls, r.Clients["cmdb"].MetadataCalls)
+			require.Equal(t, calls, r.Clients["broken"].MetadataCalls)
+
+			// The shared failure is recorded for each entry
+			pluginErrors, _, err := s.PluginErrors(1, 10)
+			require.NoError(t, err)
+			require.Len(t, pluginErrors, 3)
+		})
+	}
+}
+
+// TestSkipDisabledMetadata verifies that disabled entries are enriched by default and skipped with
+// WithoutDisabledMetadata, while plugins see the enabled state of the entries.
+func TestSkipDisabledMetadata(t *testing.T) {
+	for _, capabilities := range [][]string{nil, {pb.CapabilityMetadataBatch}} {
+		t.Run(fmt.Sprintf("Capabilities%v", capabilities), func(t *testing.T) {
+			r := &serviceinterface.MockPluginRegistry{
+				Clients: map[string]*serviceinterface.MockPlugin{
+					"cmdb": {
+						Capabilities: capabilities,
+						Metadata:     map[string]*structpb.Value{"owner": structpb.NewStringValue("cmdb-team")},
+					},
+				},
+			}
+			dc := dehydrated.NewConfig().WithBaseDir(t.TempDir()).Load()
+			s := NewDomainService(dc, r)
+			defer s.Close()
+			for _, e := range []struct {
+				domain  string
+				enabled bool
+			}{{"a.example.com", true}, {"b.example.com", false}, {"c.example.com", true}} {
+				_, err := s.CreateDomain(&model.CreateDomainRequest{Domain: e.domain, Enabled: util.BoolPtr(e.enabled)})
+				require.NoError(t, err)
+			}
+
+			owners := func(entries []*model.DomainEntry) map[string]any {
+				result := map[string]any{}
+				for _, entry := range entries {
+					result[entry.Domain] = entry.Metadata.Values()["cmdb"]
+				}
+				return result
+			}
+
+			entries, _, err := s.ListDomains(1, 10, "", "")
+			require.NoError(t, err)
+			require.NotNil(t, owners(entries)["b.example.com"])
+
+			entries, _, err = s.ListDomains(1, 10, "", "", serviceinterface.WithoutDisabledMetadata())
+			require.NoError(t, err)
+			require.Nil(t, owners(entries)["b.example.com"])
+			require.NotNil(t, owners(entries)["a.example.com"])
+			require.NotNil(t, owners(entries)["c.example.com"])
+			require.NotNil(t, entries[1].Metadata)
+
+			entry, err := s.GetDomain("b.example.com", "", serviceinterface.WithoutDisabledMetadata())
+			require.NoError(t, err)
+			require.Empty(t, entry.Metadata.Values())
+		})
+	}
+}
+
+// TestTimeBudget verifies that ListDomains stops calling slow plugins once the deadline passed and
+// returns the entries with the metadata gathered so far.
+func TestTimeBudget(t *testing.T) {
+	newService := func(t *testing.T, capabilities []string) (*DomainService, *serviceinterface.MockPluginRegistry) {
+		r := &serviceinterface.MockPluginRegistry{
+			Clients: map[string]*serviceinterface.MockPlugin{
+				"cmdb": {Metadata: map[string]*structpb.Value{"owner": structpb.NewStringValue("cmdb-team")}},
+				"slow": {
+					Capabilities: capabilities,
+					Delay:        time.Second,
+					Metadata:     map[string]*structpb.Value{"site": structpb.NewStringValue("dc1")},
+				},
+			},
+			Priorities: map[string]int{"cmdb": 10},
+		}
+		dc := dehydrated.NewConfig().WithBaseDir(t.TempDir()).Load()
+		s := NewDomainService(dc, r)
+		t.Cleanup(func() { _ = s.Close() })
+		for _, domain := range []string{"example.com", "example.org", "example.net"} {
+			_, err := s.CreateDomain(&model.CreateDomainRequest{Domain: domain, Enabled: util.BoolPtr(true)})
+			require.NoError(t, err)
+		}
+		return s, r
+	}
+
+	for _, tt := range []struct {
+		name         string
+		capabilities []string
+	}{
+		{"GetMetadata", nil},
+		{"GetMetadataBatch", []string{pb.CapabilityMetadataBatch}},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			s, r := newService(t, tt.capabilities)
+
+			start := time.Now()
+			entries, pagination, err := s.ListDomains(1, 10, "", "",
+				serviceinterface.WithDeadline(start.Add(100*time.Millisecond)))
+			require.ErrorIs(t, err, serviceinterface.ErrTimeBudgetExceeded)
+			require.Less(t, time.Since(start), 500*time.Millisecond)
+			require.Equal(t, 3, pagination.Total)
+
+			// The plugin called before the deadline provided metadata, the slow one was canceled after one call
+			require.Len(t, entries, 3)
+			for _, entry := range entries {
+				require.Equal(t, map[string]any{"owner": "cmdb-team"}, entry.Metadata.Get("cmdb"), entry.Domain)
+				require.Nil(t, entry.Metadata.Get("slow"), entry.Domain)
+			}
+			require.Equal(t, 1, r.Clients["slow"].MetadataCalls+r.Clients["slow"].BatchCalls)
+
+			// The canceled call is not a plugin failure
+			pluginErrors, _, err := s.PluginErrors(1, 10)
+			require.NoError(t, err)
+			require.Empty(t, pluginErrors)
+		})
+	}
+
+	t.Run("WithinBudget", func(t *testing.T) {
+		s, r := newService(t, nil)
+		r.Clients["slow"].Delay = 0
+
+		entries, _, err := s.ListDomains(1, 10, "", "", serviceinterface.WithDeadline(time.Now().Add(time.Minute)))
+		require.NoError(t, err)
+		for _, entry := range entries {
+			require.Equal(t, map[string]any{"site": "dc1"}, entry.Metadata.Get("slow"), entry.Domain)
+		}
+	})
+}
+
+func TestPluginCapabilities(t *testing.T) {
+	newService := func(t *testing.T, r serviceinterface.PluginRegistry) *DomainService {
+		dc := dehydrated.NewConfig().WithBaseDir(t.TempDir()).Load()
+		s := NewDomainService(dc, r)
+		t.Cleanup(func() { _ = s.Close() })
+		return s
+	}
+
+	t.Run("ValidateNotSupported", func(t *testing.T) {
+		plugin := &serviceinterface.MockPlugin{Validates: true, Capabilities: []string{pb.CapabilityMetadataBatch}}
+		s := newService(t, &serviceinterface.MockPluginRegistry{Clients: map[string]*serviceinterface.MockPlugin{"inventory": plugin}})
+
+		_, err := s.CreateDomain(&model.CreateDomainRequest{Domain: "example.com", Enabled: util.BoolPtr(true)})
+		require.ErrorIs(t, err, serviceinterface.ErrPluginFailed)
+		require.ErrorContains(t, err, "does not support validation")
+		require.Zero(t, plugin.ValidateCalls, "Validate is not called")
+	})
+
+	t.Run("ValidateSupported", func(t *testing.T) {
+		plugin := &serviceinterface.MockPlugin{Validates: true, Capabilities: []string{pb.CapabilityValidate}}
+		s := newService(t, &serviceinterface.MockPluginRegistry{Clients: map[string]*serviceinterface.MockPlugin{"inventory": plugin}})
+
+		_, err := s.CreateDomain(&model.CreateDomainRequest{Domain: "example.com", Enabled: util.BoolPtr(true)})
+		require.NoError(t, err)
+		require.Equal(t, 1, plugin.ValidateCalls)
+	})
+
+	t.Run("BatchNotSupported", func(t *testing.T) {
+		plugin := &serviceinterface.MockPlugin{Capabilities: []string{pb.CapabilityValidate}}
+		s := newService(t, &serviceinterface.MockPluginRegistry{Clients: map[string]*serviceinterface.MockPlugin{"inventory": plugin}})
+		for _, domain := range []string{"example.com", "example.org"} {
+			_, err := s.CreateDomain(&model.CreateDomainRequest{Domain: domain, Enabled: util.BoolPtr(true)})
+			require.NoError(t, err)
+		}
+
+		_, _, err := s.ListDomains(1, 10, "", "")
+		require.NoError(t, err)
+		require.Zero(t, plugin.BatchCalls, "GetMetadataBatch is not called")
+		require.Equal(t, 2, plugin.MetadataCalls)
+	})
+}
@@ -0,0 +1,67 @@
+package service
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"go.uber.org/zap"
+
+	"github.com/schumann-it/dehydrated-api-go/internal/model"
+)
+
+// SidecarMetadataKey is the metadata key reserved for the contents of the metadata sidecar file.
+const SidecarMetadataKey = "sidecar"
+
+// sidecarFile is the file in the certificate directory of an entry holding its static metadata.
+const sidecarFile = "metadata.json"
+
+// WithSidecarMetadata enables reading static metadata from the JSON object in
+// CertDir/<alias or domain>/metadata.json into the SidecarMetadataKey of the metadata.
+func (s *DomainService) WithSidecarMetadata() *DomainService {
+	s.sidecarMetadata = true
+	return s
+}
+
+// readSidecarMetadata reads the JSON object in file. It returns nil if the file does not exist.
+func readSidecarMetadata(file string) (map[string]any, error) {
+	data, err := os.ReadFile(file)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	var values map[string]any
+	if err := json.Unmarshal(data, &values); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", file, err)
+	}
+
+	return values, nil
+}
+
+// enrichSidecarMetadata adds the contents of the sidecar file of entry to its metadata, if enabled and present.
+// An unreadable file is logged and reported as error under the reserved key, like failing plugins.
+func (s *DomainService) enrichSidecarMetadata(entry *model.DomainEntry) {
+	if !s.sidecarMetadata {
+		return
+	}
+
+	file, err := s.DehydratedConfig.CertPath(entry.PathName(), sidecarFile)
+	var values map[string]any
+	if err == nil {
+		values, err = readSidecarMetadata(file)
+	}
+	if err != nil {
+		s.logger.Error("Failed to read metadata sidecar", zap.String("file", file), zap.Error(err))
+		values = map[string]any{"error": err.Error()}
+	}
+	if values == nil {
+		return
+	}
+
+	if err := entry.Metadata.SetMap(SidecarMetadataKey, values); err != nil {
+		s.logger.Error("Failed to set metadata from sidecar", zap.String("file", file), zap.Error(err))
+	}
+}
@@ -0,0 +1,71 @@
+package service
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/schumann-it/dehydrated-api-go/internal/dehydrated"
+	"github.com/schumann-it/dehydrated-api-go/internal/model"
+)
+
+// writeSidecar writes the metadata sidecar of the entry with the given path name.
+func writeSidecar(t *testing.T, dc *dehydrated.Config, pathName, content string) {
+	t.Helper()
+
+	dir := filepath.Join(dc.CertDir, pathName)
+	require.NoError(t, os.MkdirAll(dir, 0o755))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, sidecarFile), []byte(content), 0o600))
+}
+
+func TestSidecarMetadata(t *testing.T) {
+	dc := dehydrated.NewConfig().WithBaseDir(t.TempDir()).Load()
+	s := NewDomainService(dc, nil).WithSidecarMetadata()
+	defer s.Close()
+
+	for _, req := range []*model.CreateDomainRequest{
+		{Domain: "example.com", Alias: "example-rsa"},
+		{Domain: "plain.example.com"},
+		{Domain: "broken.example.com"},
+	} {
+		_, err := s.CreateDomain(req)
+		require.NoError(t, err)
+	}
+
+	writeSidecar(t, dc, "example-rsa", `{"owner":"team-web","ticket":42,"tags":["prod"]}`)
+	writeSidecar(t, dc, "broken.example.com", `{"owner":`)
+
+	entry, err := s.GetDomain("example.com", "example-rsa")
+	require.NoError(t, err)
+	require.Equal(t, map[string]any{
+		"owner":  "team-web",
+		"ticket": int64(42),
+		"tags":   []any{"prod"},
+	}, entry.Metadata.Get(SidecarMetadataKey))
+
+	// Entries without a sidecar have no sidecar metadata
+	entry, err = s.GetDomain("plain.example.com", "")
+	require.NoError(t, err)
+	require.Nil(t, entry.Metadata.Get(SidecarMetadataKey))
+
+	// An invalid sidecar is reported like a failing plugin
+	entry, err = s.GetDomain("broken.example.com", "")
+	require.NoError(t, err)
+	require.Contains(t, entry.Metadata.Get(SidecarMetadataKey), "error")
+}
+
+func TestSidecarMetadataDisabled(t *testing.T) {
+	dc := dehydrated.NewConfig().WithBaseDir(t.TempDir()).Load()
+	s := NewDomainService(dc, nil)
+	defer s.Close()
+
+	_, err := s.CreateDomain(&model.CreateDomainRequest{Domain: "example.com"})
+	require.NoError(t, err)
+	writeSidecar(t, dc, "example.com", `{"owner":"team-web"}`)
+
+	entry, err := s.GetDomain("example.com", "")
+	require.NoError(t, err)
+	require.Nil(t, entry.Metadata.Get(SidecarMetadataKey))
+}
@@ -0,0 +1,52 @@
+package service
+
+import (
+	"fmt"
+	"slices"
+
+	"github.com/schumann-it/dehydrated-api-go/internal/model"
+	serviceinterface "github.com/schumann-it/dehydrated-api-go/internal/service/interface"
+)
+
+// WithDomainSuffixes restricts the domain names of entries created or changed via the API to the zones
+// the API manages. If allowed is not empty, each name must match one of its suffixes; a name matching one
+// of the suffixes of denied is rejected in any case. See model.MatchesDomainSuffix for the matching.
+// All names are allowed if both are empty.
+func (s *DomainService) WithDomainSuffixes(allowed, denied []string) *DomainService {
+	s.allowedSuffixes = allowed
+	s.deniedSuffixes = denied
+	return s
+}
+
+// checkDomainSuffixes checks the primary domain and alternative names of entry against the allowed and
+// denied domain suffixes. It returns a FieldError for the first name that is not allowed.
+func (s *DomainService) checkDomainSuffixes(entry *model.DomainEntry) error {
+	if len(s.allowedSuffixes) == 0 && len(s.deniedSuffixes) == 0 {
+		return nil
+	}
+
+	if err := s.checkDomainSuffix(entry.Domain); err != nil {
+		return &serviceinterface.FieldError{Field: "domain", Reason: err.Error()}
+	}
+	for _, name := range entry.AlternativeNames {
+		if err := s.checkDomainSuffix(name); err != nil {
+			return &serviceinterface.FieldError{Field: "alternative_names", Reason: err.Error()}
+		}
+	}
+
+	return nil
+}
+
+// checkDomainSuffix checks a single domain name against the allowed and denied domain suffixes.
+func (s *DomainService) checkDomainSuffix(name string) error {
+	matches := func(suffix string) bool { return model.MatchesDomainSuffix(name, suffix) }
+
+	if i := slices.IndexFunc(s.deniedSuffixes, matches); i >= 0 {
+		return fmt.Errorf("domain %q is in the denied zone %q", name, s.deniedSuffixes[i])
+	}
+	if len(s.allowedSuffixes) > 0 && !slices.ContainsFunc(s.allowedSuffixes, matches) {
+		return fmt.Errorf("domain %q is not in an allowed zone %v", name, s.allowedSuffixes)
+	}
+
+	return nil
+}
@@ -0,0 +1,78 @@
+package service
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/schumann-it/dehydrated-api-go/internal/dehydrated"
+	"github.com/schumann-it/dehydrated-api-go/internal/model"
+	serviceinterface "github.com/schumann-it/dehydrated-api-go/internal/service/interface"
+	"github.com/schumann-it/dehydrated-api-go/internal/util"
+)
+
+func TestDomainSuffixes(t *testing.T) {
+	dc := dehydrated.NewConfig().WithBaseDir(t.TempDir()).Load()
+	s := NewDomainService(dc, nil).WithDomainSuffixes([]string{"example.com"}, []string{"internal.example.com"})
+	defer s.Close()
+
+	requireField := func(t *testing.T, err error, field string) {
+		t.Helper()
+		var fieldErr *serviceinterface.FieldError
+		require.ErrorAs(t, err, &fieldErr)
+		require.Equal(t, field, fieldErr.Field)
+	}
+
+	// Names in the allowed zone are permitted
+	_, err := s.CreateDomain(&model.CreateDomainRequest{Domain: "example.com", AlternativeNames: []string{"*.example.com"}})
+	require.NoError(t, err)
+	_, err = s.CreateDomain(&model.CreateDomainRequest{Domain: "sub.example.com"})
+	require.NoError(t, err)
+
+	// Names outside of it are rejected, as primary domain or alternative name
+	_, err = s.CreateDomain(&model.CreateDomainRequest{Domain: "other.org"})
+	requireField(t, err, "domain")
+	require.ErrorContains(t, err, "not in an allowed zone")
+	_, err = s.CreateDomain(&model.CreateDomainRequest{Domain: "www.example.com", AlternativeNames: []string{"other.org"}})
+	requireField(t, err, "alternative_names")
+	_, err = s.CreateDomain(&model.CreateDomainRequest{Domain: "notexample.com"})
+	requireField(t, err, "domain")
+
+	// Denied zones win over allowed ones
+	_, err = s.CreateDomain(&model.CreateDomainRequest{Domain: "db.internal.example.com"})
+	requireField(t, err, "domain")
+	require.ErrorContains(t, err, "denied zone")
+
+	// Updates, renames and imports are checked as well
+	_, err = s.UpdateDomain("sub.example.com", model.UpdateDomainRequest{AlternativeNames: &[]string{"other.org"}})
+	requireField(t, err, "alternative_names")
+	_, err = s.RenameDomain("sub.example.com", "", model.RenameDomainRequest{Domain: "sub.other.org"})
+	requireField(t, err, "domain")
+	_, err = s.ImportDomains([]*model.CreateDomainRequest{{Domain: "other.org"}}, false)
+	require.ErrorIs(t, err, serviceinterface.ErrInvalidDomainEntry)
+
+	entry, err := s.UpdateDomain("sub.example.com", model.UpdateDomainRequest{Comment: util.StringPtr("web")})
+	require.NoError(t, err)
+	require.Equal(t, "web", entry.Comment)
+}
+
+// TestDomainSuffixesSubdomainsOnly verifies that a suffix with a leading '.' allows subdomains only,
+// and that entries written manually outside the allowed zones can still be changed otherwise.
+func TestDomainSuffixesSubdomainsOnly(t *testing.T) {
+	dc := dehydrated.NewConfig().WithBaseDir(t.TempDir()).Load()
+	s := NewDomainService(dc, nil)
+	defer s.Close()
+
+	_, err := s.CreateDomain(&model.CreateDomainRequest{Domain: "other.org"})
+	require.NoError(t, err)
+
+	s.WithDomainSuffixes([]string{".example.com"}, nil)
+
+	_, err = s.CreateDomain(&model.CreateDomainRequest{Domain: "example.com"})
+	require.ErrorIs(t, err, serviceinterface.ErrInvalidDomainEntry)
+	_, err = s.CreateDomain(&model.CreateDomainRequest{Domain: "www.example.com"})
+	require.NoError(t, err)
+
+	_, err = s.UpdateDomain("other.org", model.UpdateDomainRequest{Enabled: util.BoolPtr(true)})
+	require.NoError(t, err)
+}
@@ -20,12 +20,15 @@ type FileWatcher struct {
 	filePath         string               // Path to the file being watched
 	watcher          *fsnotify.Watcher    // Underlying filesystem watcher
 	onChange         func() error         // Callback function to execute on file changes
-	mutex            sync.Mutex           // Mutex for thread-safe access to debounce map
+	mutex            sync.Mutex           // Mutex for thread-safe access to the watcher state
 	debounceMap      map[string]time.Time // Map for tracking last event time per file
 	done             chan struct{}        // Channel for signaling shutdown
 	logger           *zap.Logger          // Logger for the file watcher
 	suspended        bool                 // Flag to indicate if the watcher is suspended
+	paused           bool                 // Flag to indicate if the watcher is paused by an operator
 	debounceInterval time.Duration        // Interval for debouncing file change events
+	gracePeriod      time.Duration        // Time after Enable during which events are ignored, see WithGracePeriod
+	graceUntil       time.Time            // End of the grace period of the last Enable
 }
 
 // NewFileWatcher creates a new FileWatcher instance for the specified file.
@@ -54,25 +57,51 @@ func NewFileWatcher(filePath string, onChange func() error) (*FileWatcher, error
 	return fw, nil
 }
 
+// reset replaces the underlying filesystem watcher, starts watching it and reloads the file.
+// Events of the previous watcher that have not been handled yet are dropped.
 func (fw *FileWatcher) reset() error {
-	_ = fw.Close()
+	fw.mutex.Lock()
+	fw.stop()
 
 	watcher, err := fsnotify.NewWatcher()
 	if err != nil {
+		fw.mutex.Unlock()
 		return err
 	}
 
-	fw.watcher = watcher
-	if err = fw.watcher.Add(filepath.Dir(fw.filePath)); err != nil {
-		_ = fw.watcher.Close()
-		fw.watcher = nil
+	if err = watcher.Add(filepath.Dir(fw.filePath)); err != nil {
+		_ = watcher.Close()
+		fw.mutex.Unlock()
 		return err
 	}
 
+	fw.watcher = watcher
 	fw.debounceMap = make(map[string]time.Time)
 	fw.done = make(chan struct{})
+	go fw.watch(watcher, fw.done)
+	paused := fw.paused
+	fw.mutex.Unlock()
+
+	// The callback may take locks of its own, it must not be called with the mutex held.
+	// While paused, external changes are not applied until Resume.
+	if !paused {
+		fw.reload()
+	}
+
+	return nil
+}
 
-	fw.reload()
+// stop closes the underlying filesystem watcher, which ends its watch loop. The mutex must be held.
+func (fw *FileWatcher) stop() error {
+	if fw.done != nil {
+		close(fw.done)
+		fw.done = nil
+	}
+	if fw.watcher != nil {
+		err := fw.watcher.Close()
+		fw.watcher = nil
+		return err
+	}
 
 	return nil
 }
@@ -82,35 +111,76 @@ func (fw *FileWatcher) WithLogger(l *zap.Logger) *FileWatcher {
 	return fw
 }
 
+// WithGracePeriod ignores events for the duration after Enable. On some filesystems, the event of a write
+// made while the watcher was disabled arrives after it was enabled again, which would reload the data just
+// written. Enable reloads the file anyway, so changes of others within the grace period are not lost.
+func (fw *FileWatcher) WithGracePeriod(d time.Duration) *FileWatcher {
+	fw.mutex.Lock()
+	fw.gracePeriod = d
+	fw.mutex.Unlock()
+	return fw
+}
+
 func (fw *FileWatcher) Watch() {
 	if err := fw.reset(); err != nil {
 		fw.logger.Error("Failed to watch",
 			zap.String("file", fw.filePath),
 			zap.Error(err))
-		return
 	}
-
-	go fw.watch()
 }
 
 func (fw *FileWatcher) Disable() {
+	fw.mutex.Lock()
 	fw.suspended = true
+	fw.mutex.Unlock()
 	fw.logger.Debug("Disabled file watcher")
 }
 
 func (fw *FileWatcher) Enable() {
 	fw.logger.Debug("Enable file watcher and reload entries.")
+	fw.mutex.Lock()
 	fw.suspended = false
+	if fw.gracePeriod > 0 {
+		fw.graceUntil = time.Now().Add(fw.gracePeriod)
+	}
+	fw.mutex.Unlock()
 	err := fw.reset()
 	if err != nil {
 		fw.logger.Error("Failed to reload entries after enabling watcher")
 	}
 }
 
+// Pause ignores changes of the file until Resume, e.g., during bulk maintenance. Unlike Disable,
+// which suspends the watcher for the duration of a mutation, it is not lifted by Enable.
+func (fw *FileWatcher) Pause() {
+	fw.mutex.Lock()
+	fw.paused = true
+	fw.mutex.Unlock()
+	fw.logger.Info("Paused file watcher", zap.String("file", fw.filePath))
+}
+
+// Resume lifts Pause and reloads the file, which applies the changes made while paused.
+func (fw *FileWatcher) Resume() {
+	fw.mutex.Lock()
+	fw.paused = false
+	fw.mutex.Unlock()
+	fw.logger.Info("Resumed file watcher", zap.String("file", fw.filePath))
+	if err := fw.reset(); err != nil {
+		fw.logger.Error("Failed to reload entries after resuming watcher", zap.Error(err))
+	}
+}
+
+// Paused reports whether the watcher is paused by Pause.
+func (fw *FileWatcher) Paused() bool {
+	fw.mutex.Lock()
+	defer fw.mutex.Unlock()
+	return fw.paused
+}
+
 // watch monitors the file for changes and triggers the callback when appropriate.
 // It implements debouncing to prevent multiple rapid callbacks for the same file change.
-// The method runs in a goroutine and continues until the watcher is closed.
-func (fw *FileWatcher) watch() {
+// The method runs in a goroutine and continues until watcher is closed or done is closed.
+func (fw *FileWatcher) watch(watcher *fsnotify.Watcher, done <-chan struct{}) {
 	fw.logger.Info("Starting file watcher",
 		zap.String("file", fw.filePath),
 		zap.String("dir", filepath.Dir(fw.filePath)),
@@ -118,7 +188,7 @@ func (fw *FileWatcher) watch() {
 
 	for {
 		select {
-		case event, ok := <-fw.watcher.Events:
+		case event, ok := <-watcher.Events:
 			if !ok {
 				return
 			}
@@ -130,7 +200,7 @@ func (fw *FileWatcher) watch() {
 			}
 
 			// If the watcher is suspended, skip processing events
-			if fw.suspended {
+			if fw.isSuspended() {
 				fw.logger.Debug("Watcher is suspended, ignoring event",
 					zap.String("event", event.Op.String()),
 					zap.String("file", event.Name))
@@ -141,11 +211,18 @@ func (fw *FileWatcher) watch() {
 				continue
 			}
 
+			if fw.inGracePeriod() {
+				fw.logger.Debug("Ignoring event within the grace period after enabling the watcher",
+					zap.String("event", event.Op.String()),
+					zap.String("file", event.Name))
+				continue
+			}
+
 			fw.logger.Info("Handling event",
 				zap.String("operation", event.Op.String()),
 				zap.String("file", event.Name))
 
-			if !fw.shouldDebounce(event) {
+			if !fw.shouldDebounce(watcher, event) {
 				fw.logger.Debug("Triggering onChange callback",
 					zap.String("operation", event.Op.String()),
 					zap.String("file", event.Name))
@@ -157,19 +234,31 @@ func (fw *FileWatcher) watch() {
 						zap.Error(err))
 				}
 			}
-		case err, ok := <-fw.watcher.Errors:
+		case err, ok := <-watcher.Errors:
 			if !ok {
 				return
 			}
 			fw.logger.Error("Watcher error", zap.Error(err))
-		case <-fw.done:
-			fw.done = nil
+		case <-done:
 			return
 		}
 	}
 }
 
-func (fw *FileWatcher) shouldDebounce(event fsnotify.Event) bool {
+func (fw *FileWatcher) isSuspended() bool {
+	fw.mutex.Lock()
+	defer fw.mutex.Unlock()
+	return fw.suspended || fw.paused
+}
+
+// inGracePeriod reports whether the grace period of the last Enable has not passed yet.
+func (fw *FileWatcher) inGracePeriod() bool {
+	fw.mutex.Lock()
+	defer fw.mutex.Unlock()
+	return time.Now().Before(fw.graceUntil)
+}
+
+func (fw *FileWatcher) shouldDebounce(watcher *fsnotify.Watcher, event fsnotify.Event) bool {
 	debounce := false
 
 	fw.mutex.Lock()
@@ -180,10 +269,10 @@ func (fw *FileWatcher) shouldDebounce(event fsnotify.Event) bool {
 		delete(fw.debounceMap, event.Name)
 		// Try to re-add the directory to the watcher in case the file was recreated
 		dirPath := filepath.Dir(fw.filePath)
-		if err := fw.watcher.Remove(dirPath); err != nil {
+		if err := watcher.Remove(dirPath); err != nil {
 			fw.logger.Warn("Failed to remove directory from watcher", zap.String("dir", dirPath), zap.Error(err))
 		}
-		if err := fw.watcher.Add(dirPath); err != nil {
+		if err := watcher.Add(dirPath); err != nil {
 			fw.logger.Warn("Failed to re-add directory to watcher", zap.String("dir", dirPath), zap.Error(err))
 		} else {
 			fw.logger.Debug("Re-added directory to watcher after file recreation", zap.String("dir", dirPath))
@@ -263,12 +352,7 @@ func (fw *FileWatcher) reload() {
 
 // Close stops the file watcher and releases associated resources.
 func (fw *FileWatcher) Close() error {
-	if fw.done != nil {
-		close(fw.done)
-	}
-	if fw.watcher != nil {
-		return fw.watcher.Close()
-	}
-
-	return nil
+	fw.mutex.Lock()
+	defer fw.mutex.Unlock()
+	return fw.stop()
 }
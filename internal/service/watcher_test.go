@@ -4,8 +4,15 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"strings"
+	"sync/atomic"
 	"testing"
 	"time"
+
+	"github.com/schumann-it/dehydrated-api-go/internal/dehydrated"
+	"github.com/schumann-it/dehydrated-api-go/internal/model"
+	"github.com/schumann-it/dehydrated-api-go/internal/util"
+	"github.com/stretchr/testify/require"
 )
 
 func TestFileWatcher(t *testing.T) {
@@ -195,3 +202,101 @@ func TestFileWatcherMultipleEvents(t *testing.T) {
 		}
 	})
 }
+
+// TestFileWatcherPause verifies that a paused watcher ignores external edits, also across mutations,
+// and applies them once resumed.
+func TestFileWatcherPause(t *testing.T) {
+	dc := dehydrated.NewConfig().WithBaseDir(t.TempDir()).Load()
+	require.NoError(t, os.WriteFile(dc.DomainsFile, []byte("a.example.com\n"), 0644))
+	s := NewDomainService(dc, nil).WithFileWatcher()
+	defer s.Close()
+
+	domains := func(t *testing.T) []string {
+		t.Helper()
+		entries, _, err := s.ListDomains(1, 10, "", "")
+		require.NoError(t, err)
+		names := make([]string, len(entries))
+		for i, entry := range entries {
+			names[i] = entry.Domain
+		}
+		return names
+	}
+	require.Equal(t, []string{"a.example.com"}, domains(t))
+
+	s.Watcher().Pause()
+	require.True(t, s.Watcher().Paused())
+
+	// Mutations suspend and re-enable the watcher internally, which must not lift the pause
+	_, err := s.CreateDomain(&model.CreateDomainRequest{Domain: "b.example.com", Enabled: util.BoolPtr(true)})
+	require.NoError(t, err)
+	require.True(t, s.Watcher().Paused())
+
+	f, err := os.OpenFile(dc.DomainsFile, os.O_APPEND|os.O_WRONLY, 0644)
+	require.NoError(t, err)
+	_, err = f.WriteString("c.example.com\n")
+	require.NoError(t, err)
+	require.NoError(t, f.Close())
+
+	time.Sleep(300 * time.Millisecond)
+	require.Equal(t, []string{"a.example.com", "b.example.com"}, domains(t))
+
+	s.Watcher().Resume()
+	require.False(t, s.Watcher().Paused())
+	require.Equal(t, []string{"a.example.com", "b.example.com", "c.example.com"}, domains(t))
+}
+
+// TestFileWatcherGracePeriod verifies that an event arriving late after Enable, as the event of the service's
+// own write does on some filesystems, only reloads the file without a grace period.
+// TestFileWatcherGracePeriod verifies that the late event of the service's own write does not reload the
+// file within the grace period, while external changes after it are still applied. The writes are coalesced,
+// so the file is written after the mutation enabled the watcher again.
+func TestFileWatcherGracePeriod(t *testing.T) {
+	newService := func(t *testing.T, grace time.Duration) (*DomainService, *atomic.Int32) {
+		dc := dehydrated.NewConfig().WithBaseDir(t.TempDir()).Load()
+		require.NoError(t, os.WriteFile(dc.DomainsFile, []byte("a.example.com\n"), 0644))
+
+		s := NewDomainService(dc, nil).WithWriteCoalescing(50*time.Millisecond, 1000, false)
+		t.Cleanup(func() { s.Close() })
+
+		var reloads atomic.Int32
+		watcher, err := NewFileWatcher(dc.DomainsFile, func() error {
+			reloads.Add(1)
+			return s.Reload()
+		})
+		require.NoError(t, err)
+		s.watcher = watcher
+		s.WithWatcherGracePeriod(grace)
+		watcher.Watch()
+		require.Equal(t, int32(1), reloads.Load(), "Watch reloads the file")
+
+		_, err = s.CreateDomain(&model.CreateDomainRequest{Domain: "b.example.com"})
+		require.NoError(t, err)
+		require.Equal(t, int32(2), reloads.Load(), "Enable reloads the file")
+
+		require.Eventually(t, func() bool {
+			data, err := os.ReadFile(dc.DomainsFile)
+			return err == nil && strings.Contains(string(data), "b.example.com")
+		}, time.Second, 10*time.Millisecond, "the coalesced write happens")
+
+		return s, &reloads
+	}
+
+	t.Run("Disabled", func(t *testing.T) {
+		_, reloads := newService(t, 0)
+		require.Eventually(t, func() bool { return reloads.Load() > 2 }, time.Second, 10*time.Millisecond,
+			"the late event of the own write reloads the file")
+	})
+
+	t.Run("Enabled", func(t *testing.T) {
+		s, reloads := newService(t, time.Second)
+		require.Never(t, func() bool { return reloads.Load() > 2 }, 300*time.Millisecond, 10*time.Millisecond,
+			"the late event of the own write is ignored")
+
+		require.Eventually(t, func() bool { return !s.watcher.inGracePeriod() }, 2*time.Second, 10*time.Millisecond)
+		require.NoError(t, os.WriteFile(s.DehydratedConfig.DomainsFile, []byte("a.example.com\nb.example.com\nc.example.com\n"), 0644))
+		require.Eventually(t, func() bool {
+			_, err := s.GetDomain("c.example.com", "")
+			return reloads.Load() > 2 && err == nil
+		}, time.Second, 10*time.Millisecond, "changes after the grace period are applied")
+	})
+}
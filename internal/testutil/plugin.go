@@ -0,0 +1,28 @@
+package testutil
+
+import (
+	"net"
+	"testing"
+
+	pb "github.com/schumann-it/dehydrated-api-go/plugin/proto"
+	"github.com/schumann-it/dehydrated-api-go/plugin/server"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+)
+
+// ServePlugin serves impl over gRPC on a loopback TCP port and returns its address and a function stopping it.
+// The plugin is stopped at the end of the test anyway.
+func ServePlugin(t testing.TB, impl pb.PluginServer, opts ...grpc.ServerOption) (string, func()) {
+	t.Helper()
+
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+
+	s := server.NewPluginServer(impl).GRPCServer(opts...)
+	go func() {
+		_ = s.Serve(lis)
+	}()
+	t.Cleanup(s.Stop)
+
+	return lis.Addr().String(), s.Stop
+}
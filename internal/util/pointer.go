@@ -42,3 +42,17 @@ func Bool(b *bool) bool {
 func BoolPtr(b bool) *bool {
 	return &b
 }
+
+// Uint64 returns the uint64 value of a uint64 pointer.
+// If the pointer is nil, it returns 0.
+func Uint64(u *uint64) uint64 {
+	if u == nil {
+		return 0
+	}
+	return *u
+}
+
+// Uint64Ptr returns a pointer to the given uint64.
+func Uint64Ptr(u uint64) *uint64 {
+	return &u
+}
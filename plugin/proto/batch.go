@@ -0,0 +1,37 @@
+package proto
+
+import "context"
+
+// MetadataBatchKey returns the key of the response for entry in a GetMetadataBatchResponse:
+// the domain, followed by "/" and the alias if set.
+func MetadataBatchKey(entry *DomainEntry) string {
+	if entry.GetAlias() == "" {
+		return entry.GetDomain()
+	}
+	return entry.GetDomain() + "/" + entry.GetAlias()
+}
+
+// GetMetadataBatch answers a GetMetadataBatchRequest by calling getMetadata for each request.
+// Plugins without a more efficient way to look up many entries can implement GetMetadataBatch with it.
+// An error of getMetadata is returned in the response of the entry, like the error of a GetMetadataResponse.
+func GetMetadataBatch(
+	ctx context.Context,
+	req *GetMetadataBatchRequest,
+	getMetadata func(context.Context, *GetMetadataRequest) (*GetMetadataResponse, error),
+) (*GetMetadataBatchResponse, error) {
+	resp := &GetMetadataBatchResponse{Responses: make(map[string]*GetMetadataResponse, len(req.GetRequests()))}
+
+	for _, r := range req.GetRequests() {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+
+		m, err := getMetadata(ctx, r)
+		if err != nil {
+			m = &GetMetadataResponse{Error: err.Error()}
+		}
+		resp.Responses[MetadataBatchKey(r.GetDomainEntry())] = m
+	}
+
+	return resp, nil
+}
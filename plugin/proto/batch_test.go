@@ -0,0 +1,37 @@
+package proto
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/types/known/structpb"
+)
+
+func TestGetMetadataBatch(t *testing.T) {
+	req := &GetMetadataBatchRequest{Requests: []*GetMetadataRequest{
+		{DomainEntry: &DomainEntry{Domain: "example.com"}},
+		{DomainEntry: &DomainEntry{Domain: "example.com", Alias: "example-ecc"}},
+		{DomainEntry: &DomainEntry{Domain: "example.org"}},
+	}}
+
+	resp, err := GetMetadataBatch(context.Background(), req, func(_ context.Context, r *GetMetadataRequest) (*GetMetadataResponse, error) {
+		if r.GetDomainEntry().GetDomain() == "example.org" {
+			return nil, errors.New("not found")
+		}
+		return &GetMetadataResponse{Metadata: map[string]*structpb.Value{
+			"alias": structpb.NewStringValue(r.GetDomainEntry().GetAlias()),
+		}}, nil
+	})
+	require.NoError(t, err)
+	require.Len(t, resp.Responses, 3)
+	require.Empty(t, resp.Responses["example.com"].Metadata["alias"].GetStringValue())
+	require.Equal(t, "example-ecc", resp.Responses["example.com/example-ecc"].Metadata["alias"].GetStringValue())
+	require.Equal(t, "not found", resp.Responses["example.org"].Error)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err = GetMetadataBatch(ctx, req, nil)
+	require.ErrorIs(t, err, context.Canceled)
+}
@@ -0,0 +1,38 @@
+package proto
+
+import (
+	"slices"
+)
+
+// Capabilities of plugins, advertised in the InitializeResponse. They tell which optional RPCs a plugin
+// implements, so the API only calls those. Initialize, GetMetadata and Close are required.
+const (
+	// CapabilityMetadataBatch is the capability of plugins implementing GetMetadataBatch.
+	CapabilityMetadataBatch = "metadata_batch"
+
+	// CapabilityValidate is the capability of plugins implementing Validate.
+	CapabilityValidate = "validate"
+
+	// CapabilityDomainMetadata is the capability of plugins whose metadata only depends on the domain of an
+	// entry, not on its alias or other fields. GetMetadata is called once per domain when listing entries and
+	// the response is shared by the entries of the domain, e.g., certificates with RSA and ECDSA keys.
+	CapabilityDomainMetadata = "domain_metadata"
+)
+
+// legacyCapabilities are assumed for plugins advertising no capabilities. They predate the advertisement,
+// when Validate was called for every plugin with validation enabled.
+var legacyCapabilities = []string{CapabilityValidate}
+
+// HasCapability reports whether the plugin advertised the capability in its InitializeResponse.
+func (x *InitializeResponse) HasCapability(capability string) bool {
+	return slices.Contains(x.SupportedCapabilities(), capability)
+}
+
+// SupportedCapabilities returns the capabilities the plugin advertised in its InitializeResponse.
+// Plugins advertising none are assumed to support Validate.
+func (x *InitializeResponse) SupportedCapabilities() []string {
+	if len(x.GetCapabilities()) == 0 {
+		return slices.Clone(legacyCapabilities)
+	}
+	return slices.Clone(x.GetCapabilities())
+}
@@ -0,0 +1,21 @@
+package proto
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestCapabilities(t *testing.T) {
+	batch := &InitializeResponse{Capabilities: []string{CapabilityMetadataBatch}}
+	require.True(t, batch.HasCapability(CapabilityMetadataBatch))
+	require.False(t, batch.HasCapability(CapabilityValidate))
+	require.Equal(t, []string{CapabilityMetadataBatch}, batch.SupportedCapabilities())
+
+	// Plugins advertising no capabilities are assumed to implement Validate
+	for _, legacy := range []*InitializeResponse{{}, nil} {
+		require.True(t, legacy.HasCapability(CapabilityValidate))
+		require.False(t, legacy.HasCapability(CapabilityMetadataBatch))
+		require.Equal(t, []string{CapabilityValidate}, legacy.SupportedCapabilities())
+	}
+}
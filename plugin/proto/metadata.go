@@ -1,16 +1,25 @@
 package proto
 
 import (
+	"bytes"
 	"encoding/json"
 	"fmt"
+	"maps"
+	"strconv"
 
 	"google.golang.org/protobuf/types/known/structpb"
 )
 
+// MetadataCertNotAfter is the well-known metadata key of the end of the validity period of the certificate of
+// an entry, formatted as RFC 3339. Plugins of certificate storage backends set it, so the API reports the
+// certificate status of entries whose certificate is not stored locally.
+const MetadataCertNotAfter = "cert_not_after"
+
 // Metadata represents a map of metadata values that can be converted to and from proto values
 type Metadata struct {
-	values map[string]any
-	error  string
+	values        map[string]any
+	error         string
+	largeIntegers bool // Whether FromProto and MergeProto parse large integers sent as strings, see WithLargeIntegers
 }
 
 // NewMetadata creates a new Metadata
@@ -20,22 +29,170 @@ func NewMetadata() *Metadata {
 	}
 }
 
-// FromProto sets values from a proto value map
+// WithLargeIntegers makes FromProto and MergeProto parse strings holding integers beyond ±2^53, which
+// ToProto sends instead of doubles that cannot represent them exactly, back into json.Number values.
+// They are rendered as JSON numbers without losing precision. Without it, they stay strings, which is
+// safer for clients parsing JSON numbers as doubles, e.g., JavaScript.
+func (mm *Metadata) WithLargeIntegers() *Metadata {
+	mm.largeIntegers = true
+	return mm
+}
+
+// FromProto sets values from a proto value map.
+// Proto numbers are doubles, so whole numbers are restored as int64 (see normalizeNumbers).
 func (mm *Metadata) FromProto(name string, m map[string]*structpb.Value) {
 	result := make(map[string]any)
 	for k, v := range m {
 		if v != nil {
-			result[k] = v.AsInterface()
+			result[k] = mm.fromProtoValue(v)
 		}
 	}
 	mm.values[name] = result
 }
 
-// ToProto converts the Metadata to a proto value map
+// MergeProto sets the values of a proto value map at the top level, like FromProto does under a name.
+// Values already set for a key are kept, so the values merged first win.
+func (mm *Metadata) MergeProto(m map[string]*structpb.Value) {
+	for k, v := range m {
+		if _, ok := mm.values[k]; ok || v == nil {
+			continue
+		}
+		mm.values[k] = mm.fromProtoValue(v)
+	}
+}
+
+// fromProtoValue converts a proto value to its Go value, restoring integers.
+func (mm *Metadata) fromProtoValue(v *structpb.Value) any {
+	value := normalizeNumbers(v.AsInterface())
+	if mm.largeIntegers {
+		value = parseLargeIntegers(value)
+	}
+	return value
+}
+
+// maxExactInt is the largest integer a float64 represents exactly (2^53).
+const maxExactInt = 1 << 53
+
+// normalizeNumbers converts whole float64 numbers within ±2^53 to int64, recursively in maps and slices.
+// Numbers are floats after passing through structpb or JSON, which loses the distinction between
+// integers and floats of the original values; e.g., 42 would be read back as float64(42).
+// JSON numbers decoded as json.Number are converted to int64 if they are integers within its range,
+// kept as json.Number if they are larger integers and converted to float64 otherwise.
+func normalizeNumbers(v any) any {
+	switch t := v.(type) {
+	case float64:
+		if t >= -maxExactInt && t <= maxExactInt && t == float64(int64(t)) {
+			return int64(t)
+		}
+		return t
+	case json.Number:
+		if i, err := t.Int64(); err == nil {
+			return i
+		}
+		if _, err := strconv.ParseUint(string(t), 10, 64); err == nil {
+			return t
+		}
+		f, _ := t.Float64()
+		return normalizeNumbers(f)
+	case map[string]any:
+		for k, e := range t {
+			t[k] = normalizeNumbers(e)
+		}
+		return t
+	case []any:
+		for i, e := range t {
+			t[i] = normalizeNumbers(e)
+		}
+		return t
+	default:
+		return v
+	}
+}
+
+// parseLargeIntegers converts strings holding integers beyond ±2^53 in canonical decimal form, as written
+// by toProtoValue, to json.Number, recursively in maps and slices.
+func parseLargeIntegers(v any) any {
+	switch t := v.(type) {
+	case string:
+		if i, err := strconv.ParseInt(t, 10, 64); err == nil {
+			if (i > maxExactInt || i < -maxExactInt) && strconv.FormatInt(i, 10) == t {
+				return json.Number(t)
+			}
+		} else if u, err := strconv.ParseUint(t, 10, 64); err == nil && strconv.FormatUint(u, 10) == t {
+			return json.Number(t)
+		}
+		return t
+	case map[string]any:
+		for k, e := range t {
+			t[k] = parseLargeIntegers(e)
+		}
+		return t
+	case []any:
+		for i, e := range t {
+			t[i] = parseLargeIntegers(e)
+		}
+		return t
+	default:
+		return v
+	}
+}
+
+// toProtoValue returns v with integers beyond ±2^53 converted to decimal strings, recursively in maps and
+// slices, as proto numbers are doubles which would round them, e.g., IDs or timestamps in nanoseconds.
+// Integers within ±2^53 are kept, as are floats, which may be imprecise already. Maps and slices are copied,
+// so the values of the Metadata are not changed.
+func toProtoValue(v any) any {
+	switch t := v.(type) {
+	case int:
+		return toProtoValue(int64(t))
+	case int64:
+		if t > maxExactInt || t < -maxExactInt {
+			return strconv.FormatInt(t, 10)
+		}
+		return t
+	case uint:
+		return toProtoValue(uint64(t))
+	case uint64:
+		if t > maxExactInt {
+			return strconv.FormatUint(t, 10)
+		}
+		return t
+	case json.Number:
+		switch n := normalizeNumbers(t).(type) {
+		case json.Number:
+			return string(n)
+		default:
+			return toProtoValue(n)
+		}
+	case map[string]any:
+		m := make(map[string]any, len(t))
+		for k, e := range t {
+			m[k] = toProtoValue(e)
+		}
+		return m
+	case []any:
+		s := make([]any, len(t))
+		for i, e := range t {
+			s[i] = toProtoValue(e)
+		}
+		return s
+	default:
+		return v
+	}
+}
+
+// Values returns a copy of the metadata values with their original types.
+// Unlike ToProto, which converts all numbers to doubles, integers stay integers.
+func (mm *Metadata) Values() map[string]any {
+	return maps.Clone(mm.values)
+}
+
+// ToProto converts the Metadata to a proto value map.
+// Integers beyond ±2^53 are converted to decimal strings, see toProtoValue and WithLargeIntegers.
 func (mm *Metadata) ToProto() (map[string]*structpb.Value, error) {
 	result := make(map[string]*structpb.Value)
 	for k, v := range mm.values {
-		protoVal, err := structpb.NewValue(v)
+		protoVal, err := structpb.NewValue(toProtoValue(v))
 		if err != nil {
 			return nil, fmt.Errorf("failed to convert value for key %s: %w", k, err)
 		}
@@ -60,7 +217,7 @@ func (mm *Metadata) Set(key string, value any) {
 }
 
 // SetMap converts the parameter value to a map[string]interface{} using JSON marshaling
-// and sets the result as the value for the given key.
+// and sets the result as the value for the given key. Integers keep their precision, see normalizeNumbers.
 // If the conversion fails, an error is returned.
 func (mm *Metadata) SetMap(key string, value any) error {
 	data, err := json.Marshal(value)
@@ -69,11 +226,13 @@ func (mm *Metadata) SetMap(key string, value any) error {
 	}
 
 	var result map[string]any
-	if err = json.Unmarshal(data, &result); err != nil {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	if err = decoder.Decode(&result); err != nil {
 		return fmt.Errorf("failed to unmarshal: %w", err)
 	}
 
-	mm.values[key] = result
+	mm.values[key] = normalizeNumbers(result)
 
 	return nil
 }
@@ -0,0 +1,84 @@
+package proto
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/types/known/structpb"
+)
+
+func TestMetadataNumberTypes(t *testing.T) {
+	plugin := NewMetadata()
+	plugin.Set("example_number", 42)
+	plugin.Set("example_float", 1.5)
+	plugin.Set("nested", map[string]any{"count": 3, "items": []any{1, 2.5}})
+	plugin.Set("beyond_exact", float64(1<<60))
+
+	protoMap, err := plugin.ToProto()
+	require.NoError(t, err)
+
+	m := NewMetadata()
+	m.FromProto("simple", protoMap)
+	values := m.Get("simple").(map[string]any)
+
+	require.Equal(t, int64(42), values["example_number"])
+	require.InDelta(t, 1.5, values["example_float"], 0)
+	require.Equal(t, map[string]any{"count": int64(3), "items": []any{int64(1), 2.5}}, values["nested"])
+	require.IsType(t, float64(0), values["beyond_exact"])
+
+	require.NoError(t, m.SetMap("config", struct {
+		KeySize int `json:"key_size"`
+	}{KeySize: 4096}))
+	require.Equal(t, map[string]any{"key_size": int64(4096)}, m.Get("config"))
+}
+
+func TestMetadataLargeIntegers(t *testing.T) {
+	plugin := NewMetadata()
+	plugin.Set("id", int64(1<<60+1))
+	plugin.Set("negative", -(1<<53 + 1))
+	plugin.Set("exact", int64(1<<53))
+	plugin.Set("unsigned", uint64(1<<64-1))
+	plugin.Set("label", "9007199254740993")
+	require.NoError(t, plugin.SetMap("config", map[string]any{
+		"serial": json.Number("123456789012345678"),
+		"ratio":  json.Number("0.5"),
+	}))
+	require.Equal(t, map[string]any{"serial": int64(123456789012345678), "ratio": 0.5}, plugin.Get("config"))
+
+	protoMap, err := plugin.ToProto()
+	require.NoError(t, err)
+	require.Equal(t, "1152921504606846977", protoMap["id"].GetStringValue())
+	require.Equal(t, "123456789012345678", protoMap["config"].GetStructValue().GetFields()["serial"].GetStringValue())
+	require.Equal(t, map[string]any{"serial": int64(123456789012345678), "ratio": 0.5}, plugin.Get("config"),
+		"ToProto must not change the values")
+
+	m := NewMetadata()
+	m.MergeProto(protoMap)
+	require.Equal(t, "1152921504606846977", m.Get("id"))
+	require.Equal(t, int64(1<<53), m.Get("exact"))
+
+	m = NewMetadata().WithLargeIntegers()
+	m.MergeProto(protoMap)
+	require.Equal(t, json.Number("1152921504606846977"), m.Get("id"))
+	require.Equal(t, json.Number("-9007199254740993"), m.Get("negative"))
+	require.Equal(t, int64(1<<53), m.Get("exact"))
+	require.Equal(t, json.Number("18446744073709551615"), m.Get("unsigned"))
+	require.Equal(t, json.Number("9007199254740993"), m.Get("label"))
+	require.Equal(t, map[string]any{"serial": json.Number("123456789012345678"), "ratio": 0.5}, m.Get("config"))
+}
+
+func TestMetadataMergeProto(t *testing.T) {
+	m := NewMetadata()
+	m.MergeProto(map[string]*structpb.Value{
+		"owner": structpb.NewStringValue("first"),
+		"count": structpb.NewNumberValue(3),
+	})
+	m.MergeProto(map[string]*structpb.Value{
+		"owner": structpb.NewStringValue("second"),
+		"site":  structpb.NewStringValue("dc1"),
+		"unset": nil,
+	})
+
+	require.Equal(t, map[string]any{"owner": "first", "count": int64(3), "site": "dc1"}, m.Values())
+}
@@ -540,10 +540,12 @@ func (x *DomainEntry) GetComment() string {
 	return ""
 }
 
-// InitializeResponse is empty as no data is needed.
+// InitializeResponse contains the optional features the plugin supports.
 // The plugin should return an error if initialization fails.
 type InitializeResponse struct {
-	state         protoimpl.MessageState `protogen:"open.v1"`
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Capabilities of the plugin, e.g., "metadata_batch" for GetMetadataBatch.
+	Capabilities  []string `protobuf:"bytes,1,rep,name=capabilities,proto3" json:"capabilities,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
@@ -578,6 +580,13 @@ func (*InitializeResponse) Descriptor() ([]byte, []int) {
 	return file_plugin_proto_plugin_proto_rawDescGZIP(), []int{3}
 }
 
+func (x *InitializeResponse) GetCapabilities() []string {
+	if x != nil {
+		return x.Capabilities
+	}
+	return nil
+}
+
 // GetMetadataRequest contains the domain entry to get metadata for.
 // It includes all fields from the domain entry that the plugin can use
 // to generate or retrieve metadata.
@@ -695,6 +704,211 @@ func (x *GetMetadataResponse) GetError() string {
 	return ""
 }
 
+// GetMetadataBatchRequest contains the domain entries to get metadata for.
+type GetMetadataBatchRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// The requests for the individual domain entries, as passed to GetMetadata.
+	Requests      []*GetMetadataRequest `protobuf:"bytes,1,rep,name=requests,proto3" json:"requests,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetMetadataBatchRequest) Reset() {
+	*x = GetMetadataBatchRequest{}
+	mi := &file_plugin_proto_plugin_proto_msgTypes[6]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetMetadataBatchRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetMetadataBatchRequest) ProtoMessage() {}
+
+func (x *GetMetadataBatchRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_plugin_proto_plugin_proto_msgTypes[6]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetMetadataBatchRequest.ProtoReflect.Descriptor instead.
+func (*GetMetadataBatchRequest) Descriptor() ([]byte, []int) {
+	return file_plugin_proto_plugin_proto_rawDescGZIP(), []int{6}
+}
+
+func (x *GetMetadataBatchRequest) GetRequests() []*GetMetadataRequest {
+	if x != nil {
+		return x.Requests
+	}
+	return nil
+}
+
+// GetMetadataBatchResponse contains the metadata for the domain entries.
+type GetMetadataBatchResponse struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// The responses for the individual domain entries, keyed by the domain of the entry,
+	// followed by "/" and the alias if set. Entries without a response get no metadata.
+	Responses     map[string]*GetMetadataResponse `protobuf:"bytes,1,rep,name=responses,proto3" json:"responses,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetMetadataBatchResponse) Reset() {
+	*x = GetMetadataBatchResponse{}
+	mi := &file_plugin_proto_plugin_proto_msgTypes[7]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetMetadataBatchResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetMetadataBatchResponse) ProtoMessage() {}
+
+func (x *GetMetadataBatchResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_plugin_proto_plugin_proto_msgTypes[7]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetMetadataBatchResponse.ProtoReflect.Descriptor instead.
+func (*GetMetadataBatchResponse) Descriptor() ([]byte, []int) {
+	return file_plugin_proto_plugin_proto_rawDescGZIP(), []int{7}
+}
+
+func (x *GetMetadataBatchResponse) GetResponses() map[string]*GetMetadataResponse {
+	if x != nil {
+		return x.Responses
+	}
+	return nil
+}
+
+// ValidateRequest contains the domain entry to validate.
+// The entry has the values it will be written with.
+type ValidateRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// The domain object containing all domain information.
+	DomainEntry *DomainEntry `protobuf:"bytes,1,opt,name=domain_entry,json=domainEntry,proto3" json:"domain_entry,omitempty"`
+	// Dehydrated configuration for ACME client operation.
+	// This provides context for the plugin about the dehydrated environment.
+	DehydratedConfig *DehydratedConfig `protobuf:"bytes,2,opt,name=dehydrated_config,json=dehydratedConfig,proto3" json:"dehydrated_config,omitempty"`
+	unknownFields    protoimpl.UnknownFields
+	sizeCache        protoimpl.SizeCache
+}
+
+func (x *ValidateRequest) Reset() {
+	*x = ValidateRequest{}
+	mi := &file_plugin_proto_plugin_proto_msgTypes[8]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ValidateRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ValidateRequest) ProtoMessage() {}
+
+func (x *ValidateRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_plugin_proto_plugin_proto_msgTypes[8]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ValidateRequest.ProtoReflect.Descriptor instead.
+func (*ValidateRequest) Descriptor() ([]byte, []int) {
+	return file_plugin_proto_plugin_proto_rawDescGZIP(), []int{8}
+}
+
+func (x *ValidateRequest) GetDomainEntry() *DomainEntry {
+	if x != nil {
+		return x.DomainEntry
+	}
+	return nil
+}
+
+func (x *ValidateRequest) GetDehydratedConfig() *DehydratedConfig {
+	if x != nil {
+		return x.DehydratedConfig
+	}
+	return nil
+}
+
+// ValidateResponse contains the result of the validation.
+type ValidateResponse struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Whether the domain entry may be written.
+	Valid bool `protobuf:"varint,1,opt,name=valid,proto3" json:"valid,omitempty"`
+	// Reason why the domain entry is invalid, returned to the client.
+	Reason        string `protobuf:"bytes,2,opt,name=reason,proto3" json:"reason,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ValidateResponse) Reset() {
+	*x = ValidateResponse{}
+	mi := &file_plugin_proto_plugin_proto_msgTypes[9]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ValidateResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ValidateResponse) ProtoMessage() {}
+
+func (x *ValidateResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_plugin_proto_plugin_proto_msgTypes[9]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ValidateResponse.ProtoReflect.Descriptor instead.
+func (*ValidateResponse) Descriptor() ([]byte, []int) {
+	return file_plugin_proto_plugin_proto_rawDescGZIP(), []int{9}
+}
+
+func (x *ValidateResponse) GetValid() bool {
+	if x != nil {
+		return x.Valid
+	}
+	return false
+}
+
+func (x *ValidateResponse) GetReason() string {
+	if x != nil {
+		return x.Reason
+	}
+	return ""
+}
+
 // CloseRequest is empty as no data is needed.
 // The plugin should perform cleanup when receiving this request.
 type CloseRequest struct {
@@ -705,7 +919,7 @@ type CloseRequest struct {
 
 func (x *CloseRequest) Reset() {
 	*x = CloseRequest{}
-	mi := &file_plugin_proto_plugin_proto_msgTypes[6]
+	mi := &file_plugin_proto_plugin_proto_msgTypes[10]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -717,7 +931,7 @@ func (x *CloseRequest) String() string {
 func (*CloseRequest) ProtoMessage() {}
 
 func (x *CloseRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_plugin_proto_plugin_proto_msgTypes[6]
+	mi := &file_plugin_proto_plugin_proto_msgTypes[10]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -730,7 +944,7 @@ func (x *CloseRequest) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use CloseRequest.ProtoReflect.Descriptor instead.
 func (*CloseRequest) Descriptor() ([]byte, []int) {
-	return file_plugin_proto_plugin_proto_rawDescGZIP(), []int{6}
+	return file_plugin_proto_plugin_proto_rawDescGZIP(), []int{10}
 }
 
 // CloseResponse is empty as no data is needed.
@@ -743,7 +957,7 @@ type CloseResponse struct {
 
 func (x *CloseResponse) Reset() {
 	*x = CloseResponse{}
-	mi := &file_plugin_proto_plugin_proto_msgTypes[7]
+	mi := &file_plugin_proto_plugin_proto_msgTypes[11]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -755,7 +969,7 @@ func (x *CloseResponse) String() string {
 func (*CloseResponse) ProtoMessage() {}
 
 func (x *CloseResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_plugin_proto_plugin_proto_msgTypes[7]
+	mi := &file_plugin_proto_plugin_proto_msgTypes[11]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -768,7 +982,7 @@ func (x *CloseResponse) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use CloseResponse.ProtoReflect.Descriptor instead.
 func (*CloseResponse) Descriptor() ([]byte, []int) {
-	return file_plugin_proto_plugin_proto_rawDescGZIP(), []int{7}
+	return file_plugin_proto_plugin_proto_rawDescGZIP(), []int{11}
 }
 
 var File_plugin_proto_plugin_proto protoreflect.FileDescriptor
@@ -842,8 +1056,9 @@ const file_plugin_proto_plugin_proto_rawDesc = "" +
 	"\x11alternative_names\x18\x02 \x03(\tR\x10alternativeNames\x12\x14\n" +
 	"\x05alias\x18\x03 \x01(\tR\x05alias\x12\x18\n" +
 	"\aenabled\x18\x04 \x01(\bR\aenabled\x12\x18\n" +
-	"\acomment\x18\x05 \x01(\tR\acomment\"\x14\n" +
-	"\x12InitializeResponse\"\x93\x01\n" +
+	"\acomment\x18\x05 \x01(\tR\acomment\"8\n" +
+	"\x12InitializeResponse\x12\"\n" +
+	"\fcapabilities\x18\x01 \x03(\tR\fcapabilities\"\x93\x01\n" +
 	"\x12GetMetadataRequest\x126\n" +
 	"\fdomain_entry\x18\x01 \x01(\v2\x13.plugin.DomainEntryR\vdomainEntry\x12E\n" +
 	"\x11dehydrated_config\x18\x02 \x01(\v2\x18.plugin.DehydratedConfigR\x10dehydratedConfig\"\xc7\x01\n" +
@@ -852,13 +1067,28 @@ const file_plugin_proto_plugin_proto_rawDesc = "" +
 	"\x05error\x18\x02 \x01(\tR\x05error\x1aS\n" +
 	"\rMetadataEntry\x12\x10\n" +
 	"\x03key\x18\x01 \x01(\tR\x03key\x12,\n" +
-	"\x05value\x18\x02 \x01(\v2\x16.google.protobuf.ValueR\x05value:\x028\x01\"\x0e\n" +
+	"\x05value\x18\x02 \x01(\v2\x16.google.protobuf.ValueR\x05value:\x028\x01\"Q\n" +
+	"\x17GetMetadataBatchRequest\x126\n" +
+	"\brequests\x18\x01 \x03(\v2\x1a.plugin.GetMetadataRequestR\brequests\"\xc4\x01\n" +
+	"\x18GetMetadataBatchResponse\x12M\n" +
+	"\tresponses\x18\x01 \x03(\v2/.plugin.GetMetadataBatchResponse.ResponsesEntryR\tresponses\x1aY\n" +
+	"\x0eResponsesEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x121\n" +
+	"\x05value\x18\x02 \x01(\v2\x1b.plugin.GetMetadataResponseR\x05value:\x028\x01\"\x90\x01\n" +
+	"\x0fValidateRequest\x126\n" +
+	"\fdomain_entry\x18\x01 \x01(\v2\x13.plugin.DomainEntryR\vdomainEntry\x12E\n" +
+	"\x11dehydrated_config\x18\x02 \x01(\v2\x18.plugin.DehydratedConfigR\x10dehydratedConfig\"@\n" +
+	"\x10ValidateResponse\x12\x14\n" +
+	"\x05valid\x18\x01 \x01(\bR\x05valid\x12\x16\n" +
+	"\x06reason\x18\x02 \x01(\tR\x06reason\"\x0e\n" +
 	"\fCloseRequest\"\x0f\n" +
-	"\rCloseResponse2\xd1\x01\n" +
+	"\rCloseResponse2\xeb\x02\n" +
 	"\x06Plugin\x12E\n" +
 	"\n" +
 	"Initialize\x12\x19.plugin.InitializeRequest\x1a\x1a.plugin.InitializeResponse\"\x00\x12H\n" +
-	"\vGetMetadata\x12\x1a.plugin.GetMetadataRequest\x1a\x1b.plugin.GetMetadataResponse\"\x00\x126\n" +
+	"\vGetMetadata\x12\x1a.plugin.GetMetadataRequest\x1a\x1b.plugin.GetMetadataResponse\"\x00\x12W\n" +
+	"\x10GetMetadataBatch\x12\x1f.plugin.GetMetadataBatchRequest\x1a .plugin.GetMetadataBatchResponse\"\x00\x12?\n" +
+	"\bValidate\x12\x17.plugin.ValidateRequest\x1a\x18.plugin.ValidateResponse\"\x00\x126\n" +
 	"\x05Close\x12\x14.plugin.CloseRequest\x1a\x15.plugin.CloseResponse\"\x00B7Z5github.com/schumann-it/dehydrated-api-go/plugin/protob\x06proto3"
 
 var (
@@ -873,38 +1103,52 @@ func file_plugin_proto_plugin_proto_rawDescGZIP() []byte {
 	return file_plugin_proto_plugin_proto_rawDescData
 }
 
-var file_plugin_proto_plugin_proto_msgTypes = make([]protoimpl.MessageInfo, 10)
+var file_plugin_proto_plugin_proto_msgTypes = make([]protoimpl.MessageInfo, 15)
 var file_plugin_proto_plugin_proto_goTypes = []any{
-	(*DehydratedConfig)(nil),    // 0: plugin.DehydratedConfig
-	(*InitializeRequest)(nil),   // 1: plugin.InitializeRequest
-	(*DomainEntry)(nil),         // 2: plugin.DomainEntry
-	(*InitializeResponse)(nil),  // 3: plugin.InitializeResponse
-	(*GetMetadataRequest)(nil),  // 4: plugin.GetMetadataRequest
-	(*GetMetadataResponse)(nil), // 5: plugin.GetMetadataResponse
-	(*CloseRequest)(nil),        // 6: plugin.CloseRequest
-	(*CloseResponse)(nil),       // 7: plugin.CloseResponse
-	nil,                         // 8: plugin.InitializeRequest.ConfigEntry
-	nil,                         // 9: plugin.GetMetadataResponse.MetadataEntry
-	(*structpb.Value)(nil),      // 10: google.protobuf.Value
+	(*DehydratedConfig)(nil),         // 0: plugin.DehydratedConfig
+	(*InitializeRequest)(nil),        // 1: plugin.InitializeRequest
+	(*DomainEntry)(nil),              // 2: plugin.DomainEntry
+	(*InitializeResponse)(nil),       // 3: plugin.InitializeResponse
+	(*GetMetadataRequest)(nil),       // 4: plugin.GetMetadataRequest
+	(*GetMetadataResponse)(nil),      // 5: plugin.GetMetadataResponse
+	(*GetMetadataBatchRequest)(nil),  // 6: plugin.GetMetadataBatchRequest
+	(*GetMetadataBatchResponse)(nil), // 7: plugin.GetMetadataBatchResponse
+	(*ValidateRequest)(nil),          // 8: plugin.ValidateRequest
+	(*ValidateResponse)(nil),         // 9: plugin.ValidateResponse
+	(*CloseRequest)(nil),             // 10: plugin.CloseRequest
+	(*CloseResponse)(nil),            // 11: plugin.CloseResponse
+	nil,                              // 12: plugin.InitializeRequest.ConfigEntry
+	nil,                              // 13: plugin.GetMetadataResponse.MetadataEntry
+	nil,                              // 14: plugin.GetMetadataBatchResponse.ResponsesEntry
+	(*structpb.Value)(nil),           // 15: google.protobuf.Value
 }
 var file_plugin_proto_plugin_proto_depIdxs = []int32{
-	8,  // 0: plugin.InitializeRequest.config:type_name -> plugin.InitializeRequest.ConfigEntry
+	12, // 0: plugin.InitializeRequest.config:type_name -> plugin.InitializeRequest.ConfigEntry
 	2,  // 1: plugin.GetMetadataRequest.domain_entry:type_name -> plugin.DomainEntry
 	0,  // 2: plugin.GetMetadataRequest.dehydrated_config:type_name -> plugin.DehydratedConfig
-	9,  // 3: plugin.GetMetadataResponse.metadata:type_name -> plugin.GetMetadataResponse.MetadataEntry
-	10, // 4: plugin.InitializeRequest.ConfigEntry.value:type_name -> google.protobuf.Value
-	10, // 5: plugin.GetMetadataResponse.MetadataEntry.value:type_name -> google.protobuf.Value
-	1,  // 6: plugin.Plugin.Initialize:input_type -> plugin.InitializeRequest
-	4,  // 7: plugin.Plugin.GetMetadata:input_type -> plugin.GetMetadataRequest
-	6,  // 8: plugin.Plugin.Close:input_type -> plugin.CloseRequest
-	3,  // 9: plugin.Plugin.Initialize:output_type -> plugin.InitializeResponse
-	5,  // 10: plugin.Plugin.GetMetadata:output_type -> plugin.GetMetadataResponse
-	7,  // 11: plugin.Plugin.Close:output_type -> plugin.CloseResponse
-	9,  // [9:12] is the sub-list for method output_type
-	6,  // [6:9] is the sub-list for method input_type
-	6,  // [6:6] is the sub-list for extension type_name
-	6,  // [6:6] is the sub-list for extension extendee
-	0,  // [0:6] is the sub-list for field type_name
+	13, // 3: plugin.GetMetadataResponse.metadata:type_name -> plugin.GetMetadataResponse.MetadataEntry
+	4,  // 4: plugin.GetMetadataBatchRequest.requests:type_name -> plugin.GetMetadataRequest
+	14, // 5: plugin.GetMetadataBatchResponse.responses:type_name -> plugin.GetMetadataBatchResponse.ResponsesEntry
+	2,  // 6: plugin.ValidateRequest.domain_entry:type_name -> plugin.DomainEntry
+	0,  // 7: plugin.ValidateRequest.dehydrated_config:type_name -> plugin.DehydratedConfig
+	15, // 8: plugin.InitializeRequest.ConfigEntry.value:type_name -> google.protobuf.Value
+	15, // 9: plugin.GetMetadataResponse.MetadataEntry.value:type_name -> google.protobuf.Value
+	5,  // 10: plugin.GetMetadataBatchResponse.ResponsesEntry.value:type_name -> plugin.GetMetadataResponse
+	1,  // 11: plugin.Plugin.Initialize:input_type -> plugin.InitializeRequest
+	4,  // 12: plugin.Plugin.GetMetadata:input_type -> plugin.GetMetadataRequest
+	6,  // 13: plugin.Plugin.GetMetadataBatch:input_type -> plugin.GetMetadataBatchRequest
+	8,  // 14: plugin.Plugin.Validate:input_type -> plugin.ValidateRequest
+	10, // 15: plugin.Plugin.Close:input_type -> plugin.CloseRequest
+	3,  // 16: plugin.Plugin.Initialize:output_type -> plugin.InitializeResponse
+	5,  // 17: plugin.Plugin.GetMetadata:output_type -> plugin.GetMetadataResponse
+	7,  // 18: plugin.Plugin.GetMetadataBatch:output_type -> plugin.GetMetadataBatchResponse
+	9,  // 19: plugin.Plugin.Validate:output_type -> plugin.ValidateResponse
+	11, // 20: plugin.Plugin.Close:output_type -> plugin.CloseResponse
+	16, // [16:21] is the sub-list for method output_type
+	11, // [11:16] is the sub-list for method input_type
+	11, // [11:11] is the sub-list for extension type_name
+	11, // [11:11] is the sub-list for extension extendee
+	0,  // [0:11] is the sub-list for field type_name
 }
 
 func init() { file_plugin_proto_plugin_proto_init() }
@@ -918,7 +1162,7 @@ func file_plugin_proto_plugin_proto_init() {
 			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
 			RawDescriptor: unsafe.Slice(unsafe.StringData(file_plugin_proto_plugin_proto_rawDesc), len(file_plugin_proto_plugin_proto_rawDesc)),
 			NumEnums:      0,
-			NumMessages:   10,
+			NumMessages:   15,
 			NumExtensions: 0,
 			NumServices:   1,
 		},
@@ -19,9 +19,11 @@ import (
 const _ = grpc.SupportPackageIsVersion9
 
 const (
-	Plugin_Initialize_FullMethodName  = "/plugin.Plugin/Initialize"
-	Plugin_GetMetadata_FullMethodName = "/plugin.Plugin/GetMetadata"
-	Plugin_Close_FullMethodName       = "/plugin.Plugin/Close"
+	Plugin_Initialize_FullMethodName       = "/plugin.Plugin/Initialize"
+	Plugin_GetMetadata_FullMethodName      = "/plugin.Plugin/GetMetadata"
+	Plugin_GetMetadataBatch_FullMethodName = "/plugin.Plugin/GetMetadataBatch"
+	Plugin_Validate_FullMethodName         = "/plugin.Plugin/Validate"
+	Plugin_Close_FullMethodName            = "/plugin.Plugin/Close"
 )
 
 // PluginClient is the client API for Plugin service.
@@ -42,6 +44,16 @@ type PluginClient interface {
 	// based on its configuration and capabilities.
 	// The metadata returned will be merged with the existing metadata.
 	GetMetadata(ctx context.Context, in *GetMetadataRequest, opts ...grpc.CallOption) (*GetMetadataResponse, error)
+	// GetMetadataBatch returns metadata for many domain entries with a single call.
+	// It is only called for plugins advertising the "metadata_batch" capability
+	// in their InitializeResponse; other plugins are called with GetMetadata per entry.
+	// The responses are keyed by the domain of the entry, followed by "/" and the alias if set.
+	GetMetadataBatch(ctx context.Context, in *GetMetadataBatchRequest, opts ...grpc.CallOption) (*GetMetadataBatchResponse, error)
+	// Validate checks whether a domain entry may be created or changed.
+	// It is only called for plugins with validation enabled in their configuration,
+	// before the entry is written. If the plugin returns invalid, the change is
+	// rejected with the reason of the plugin.
+	Validate(ctx context.Context, in *ValidateRequest, opts ...grpc.CallOption) (*ValidateResponse, error)
 	// Close is called when the plugin is being unloaded.
 	// The plugin should perform any necessary cleanup and resource release.
 	// Returns an error if cleanup fails.
@@ -76,6 +88,26 @@ func (c *pluginClient) GetMetadata(ctx context.Context, in *GetMetadataRequest,
 	return out, nil
 }
 
+func (c *pluginClient) GetMetadataBatch(ctx context.Context, in *GetMetadataBatchRequest, opts ...grpc.CallOption) (*GetMetadataBatchResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(GetMetadataBatchResponse)
+	err := c.cc.Invoke(ctx, Plugin_GetMetadataBatch_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *pluginClient) Validate(ctx context.Context, in *ValidateRequest, opts ...grpc.CallOption) (*ValidateResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ValidateResponse)
+	err := c.cc.Invoke(ctx, Plugin_Validate_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 func (c *pluginClient) Close(ctx context.Context, in *CloseRequest, opts ...grpc.CallOption) (*CloseResponse, error) {
 	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
 	out := new(CloseResponse)
@@ -104,6 +136,16 @@ type PluginServer interface {
 	// based on its configuration and capabilities.
 	// The metadata returned will be merged with the existing metadata.
 	GetMetadata(context.Context, *GetMetadataRequest) (*GetMetadataResponse, error)
+	// GetMetadataBatch returns metadata for many domain entries with a single call.
+	// It is only called for plugins advertising the "metadata_batch" capability
+	// in their InitializeResponse; other plugins are called with GetMetadata per entry.
+	// The responses are keyed by the domain of the entry, followed by "/" and the alias if set.
+	GetMetadataBatch(context.Context, *GetMetadataBatchRequest) (*GetMetadataBatchResponse, error)
+	// Validate checks whether a domain entry may be created or changed.
+	// It is only called for plugins with validation enabled in their configuration,
+	// before the entry is written. If the plugin returns invalid, the change is
+	// rejected with the reason of the plugin.
+	Validate(context.Context, *ValidateRequest) (*ValidateResponse, error)
 	// Close is called when the plugin is being unloaded.
 	// The plugin should perform any necessary cleanup and resource release.
 	// Returns an error if cleanup fails.
@@ -124,6 +166,12 @@ func (UnimplementedPluginServer) Initialize(context.Context, *InitializeRequest)
 func (UnimplementedPluginServer) GetMetadata(context.Context, *GetMetadataRequest) (*GetMetadataResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method GetMetadata not implemented")
 }
+func (UnimplementedPluginServer) GetMetadataBatch(context.Context, *GetMetadataBatchRequest) (*GetMetadataBatchResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetMetadataBatch not implemented")
+}
+func (UnimplementedPluginServer) Validate(context.Context, *ValidateRequest) (*ValidateResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Validate not implemented")
+}
 func (UnimplementedPluginServer) Close(context.Context, *CloseRequest) (*CloseResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method Close not implemented")
 }
@@ -184,6 +232,42 @@ func _Plugin_GetMetadata_Handler(srv interface{}, ctx context.Context, dec func(
 	return interceptor(ctx, in, info, handler)
 }
 
+func _Plugin_GetMetadataBatch_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetMetadataBatchRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(PluginServer).GetMetadataBatch(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Plugin_GetMetadataBatch_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(PluginServer).GetMetadataBatch(ctx, req.(*GetMetadataBatchRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Plugin_Validate_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ValidateRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(PluginServer).Validate(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Plugin_Validate_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(PluginServer).Validate(ctx, req.(*ValidateRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 func _Plugin_Close_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
 	in := new(CloseRequest)
 	if err := dec(in); err != nil {
@@ -217,6 +301,14 @@ var Plugin_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "GetMetadata",
 			Handler:    _Plugin_GetMetadata_Handler,
 		},
+		{
+			MethodName: "GetMetadataBatch",
+			Handler:    _Plugin_GetMetadataBatch_Handler,
+		},
+		{
+			MethodName: "Validate",
+			Handler:    _Plugin_Validate_Handler,
+		},
 		{
 			MethodName: "Close",
 			Handler:    _Plugin_Close_Handler,
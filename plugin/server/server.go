@@ -3,6 +3,7 @@ package server
 import (
 	"context"
 	"fmt"
+	"net"
 	"net/rpc"
 
 	"github.com/hashicorp/go-plugin"
@@ -38,6 +39,25 @@ func (p *PluginServer) Serve() {
 	})
 }
 
+// ServeTCP serves the plugin over gRPC on a TCP address (host:port), for plugins running
+// separately from the API, e.g., in another container. The API connects to it with the
+// address plugin option. It blocks until the server stops.
+func (p *PluginServer) ServeTCP(address string, opts ...grpc.ServerOption) error {
+	lis, err := net.Listen("tcp", address)
+	if err != nil {
+		return fmt.Errorf("failed to listen on %s: %w", address, err)
+	}
+
+	return p.GRPCServer(opts...).Serve(lis)
+}
+
+// GRPCServer returns a gRPC server with the plugin registered, ready to serve on any listener.
+func (p *PluginServer) GRPCServer(opts ...grpc.ServerOption) *grpc.Server {
+	s := grpc.NewServer(opts...)
+	pb.RegisterPluginServer(s, p)
+	return s
+}
+
 // GRPCPlugin is the plugin implementation for go-plugin
 type GRPCPlugin struct {
 	plugin.NetRPCUnsupportedPlugin
@@ -75,6 +95,16 @@ func (p *PluginServer) GetMetadata(ctx context.Context, req *pb.GetMetadataReque
 	return p.impl.GetMetadata(ctx, req)
 }
 
+// GetMetadataBatch implements the plugin.Plugin interface
+func (p *PluginServer) GetMetadataBatch(ctx context.Context, req *pb.GetMetadataBatchRequest) (*pb.GetMetadataBatchResponse, error) {
+	return p.impl.GetMetadataBatch(ctx, req)
+}
+
+// Validate implements the plugin.Plugin interface
+func (p *PluginServer) Validate(ctx context.Context, req *pb.ValidateRequest) (*pb.ValidateResponse, error) {
+	return p.impl.Validate(ctx, req)
+}
+
 // Close implements the plugin.Plugin interface
 func (p *PluginServer) Close(ctx context.Context, req *pb.CloseRequest) (*pb.CloseResponse, error) {
 	return p.impl.Close(ctx, req)